@@ -0,0 +1,40 @@
+package main
+
+import (
+	"go-mycli/pkg/cli"
+
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Work with the ~/.go-myclirc config file",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Check the config file for unknown keys and bad values",
+	Long: `Checks ~/.go-myclirc, or the file go-mycli would read instead
+(GO_MYCLI_RC or ./.go-myclirc), or the file given, and prints each unknown
+section or key and each value go-mycli would ignore as file:line: message.
+Unknown keys get a suggestion when they look like a typo of a known one.
+The command exits with status 1 when there are problems.`,
+	Example: `  go-mycli config validate
+  go-mycli config validate ./team.go-myclirc`,
+	Args: cobra.MaximumNArgs(1),
+	// Runtime failures are reported once by main, without the usage text
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := ""
+		if len(args) > 0 {
+			path = args[0]
+		}
+		return cli.ValidateConfig(path)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go-mycli/pkg/cli"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	explainUseAI  bool
+	explainFormat string
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain [flags] \"SELECT ...\"",
+	Short: "EXPLAIN a query and report problems (for CI pipelines)",
+	Long: `Runs EXPLAIN FORMAT=JSON for the query, reports full scans, filesorts and
+temporary tables with a severity (ok, info, warning, critical), and with --ai
+adds the configured AI backend's analysis. The findings add up to a score from
+0 to 100 with a verdict of ok, warn or critical. The query is read from stdin
+when no argument is given.
+
+Exit status: 0 for ok, 2 for warn, 3 for critical and 1 when the query could
+not be explained.`,
+	Example: `  go-mycli explain --ai --format json -D sakila "SELECT * FROM actor WHERE first_name = 'PENELOPE'"
+  cat query.sql | go-mycli explain --format json`,
+	// Runtime failures are reported once by main, without the usage text
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		query := strings.Join(args, " ")
+		if query == "" {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("failed to read query from stdin: %w", err)
+			}
+			query = string(data)
+		}
+
+		return cli.Explain(host, port, user, password, database, socket, loginPath, configFile, zstdCompressionLevel, cli.ExplainOptions{
+			Query:         query,
+			Format:        explainFormat,
+			UseAI:         explainUseAI,
+			AIServerURL:   aiServerURL,
+			AIServerMode:  aiServerMode,
+			AICachePath:   aiCachePath,
+			AIMCPCommand:  aiMCPCommand,
+			AIDetailLevel: aiDetailLevel,
+		})
+	},
+}
+
+func init() {
+	explainCmd.Flags().BoolVar(&explainUseAI, "ai", false, "Include analysis from the configured AI backend")
+	explainCmd.Flags().StringVar(&explainFormat, "format", "text", "Output format: text|json")
+	rootCmd.AddCommand(explainCmd)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"go-mycli/pkg/cli"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportQuery string
+	exportOut   string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export --query \"SELECT ...\" --out FILE",
+	Short: "Stream a query's result set to a Parquet or compressed CSV file",
+	Long: `Runs the query and streams its rows to a file, so datasets can be pulled
+without a separate ETL tool. The format follows the extension of --out:
+.parquet (zstd-compressed, with MySQL types mapped to Parquet types), .csv,
+.csv.gz or .csv.zst. The query is read from stdin when --query is not given.`,
+	Example: `  go-mycli export -D sakila --query "SELECT * FROM payment" --out payments.parquet
+  go-mycli export -D sakila --query "SELECT * FROM rental" --out rentals.csv.zst
+  cat extract.sql | go-mycli export --out extract.csv.gz`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		query := exportQuery
+		if query == "" {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("failed to read query from stdin: %w", err)
+			}
+			query = string(data)
+		}
+		return cli.Export(host, port, user, password, database, socket, loginPath, configFile, zstdCompressionLevel, cli.ExportOptions{
+			Query: query,
+			Out:   exportOut,
+		})
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportQuery, "query", "", "Query whose result set is exported")
+	exportCmd.Flags().StringVar(&exportOut, "out", "", "Output file: .parquet, .csv, .csv.gz or .csv.zst")
+	_ = exportCmd.MarkFlagRequired("out")
+	rootCmd.AddCommand(exportCmd)
+}
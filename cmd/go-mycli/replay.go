@@ -0,0 +1,39 @@
+package main
+
+import (
+	"go-mycli/pkg/cli"
+
+	"github.com/spf13/cobra"
+)
+
+var replaySpeed float64
+
+var replayCmd = &cobra.Command{
+	Use:   "replay [flags] FILE",
+	Short: "Replay statements captured with \\record",
+	Long: `Runs the statements of a capture written by \record start against the server,
+one at a time on a single connection, switching databases as the recorded
+session did. Statements keep their recorded spacing, divided by --speed; with
+--speed 0 each runs as soon as the previous one finishes. At the end the
+slowest statements are listed with their replayed and recorded durations.
+
+Statements that failed when recorded are replayed too, but their failures
+are not counted. The exit status is 1 when any other statement failed.`,
+	Example: `  go-mycli replay --login-path staging -D shop checkout.jsonl
+  go-mycli replay --speed 4 --login-path staging checkout.jsonl`,
+	Args: cobra.ExactArgs(1),
+	// Runtime failures are reported once by main, without the usage text
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cli.Replay(host, port, user, password, database, socket, loginPath, configFile, zstdCompressionLevel, cli.ReplayOptions{
+			File:  args[0],
+			Speed: replaySpeed,
+		})
+	},
+}
+
+func init() {
+	replayCmd.Flags().Float64Var(&replaySpeed, "speed", 1, "Pace relative to the recording: 2 is twice as fast, 0 without pauses")
+	rootCmd.AddCommand(replayCmd)
+}
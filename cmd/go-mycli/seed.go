@@ -0,0 +1,45 @@
+package main
+
+import (
+	"go-mycli/pkg/cli"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	seedRows      int
+	seedBatchSize int
+	seedSeed      uint64
+)
+
+var seedCmd = &cobra.Command{
+	Use:   "seed [flags] TABLE",
+	Short: "Fill a table with generated test data",
+	Long: `Inspects the columns, unique keys and foreign keys of TABLE (db.table, or a
+table of --database) and inserts generated rows in batches: names, emails,
+phone numbers, addresses and dates chosen from the column names and types,
+values within each column's length and range, and foreign keys that reference
+existing rows. Auto-increment and generated columns are left to the server.
+The same --seed produces the same data.`,
+	Example: `  go-mycli seed -D sakila customer --rows 100000
+  go-mycli seed sakila.payment --rows 50000 --batch-size 2000 --seed 42`,
+	Args: cobra.ExactArgs(1),
+	// Runtime failures are reported once by main, without the usage text
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cli.Seed(host, port, user, password, database, socket, loginPath, configFile, zstdCompressionLevel, cli.SeedOptions{
+			Table:     args[0],
+			Rows:      seedRows,
+			BatchSize: seedBatchSize,
+			Seed:      seedSeed,
+		})
+	},
+}
+
+func init() {
+	seedCmd.Flags().IntVar(&seedRows, "rows", 1000, "Rows to insert")
+	seedCmd.Flags().IntVar(&seedBatchSize, "batch-size", 1000, "Rows per INSERT statement")
+	seedCmd.Flags().Uint64Var(&seedSeed, "seed", 0, "Random seed, for repeatable data (default: random)")
+	rootCmd.AddCommand(seedCmd)
+}
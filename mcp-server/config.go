@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// ServerConfig holds the bridge settings. They come from defaults, then the
+// INI config file, then MCP_SERVER_* environment variables, then explicit flags.
+type ServerConfig struct {
+	Listen             string
+	MCPCommand         string
+	DrainTimeout       time.Duration
+	KillTimeout        time.Duration
+	ReadTimeout        time.Duration
+	WriteTimeout       time.Duration
+	AuthToken          string
+	DefaultDetailLevel string
+	LogLevel           string
+	LogFile            string
+	CacheType          string
+	CachePath          string
+	CacheSize          int
+	CacheTTL           time.Duration
+}
+
+// DefaultServerConfig returns the built-in defaults
+func DefaultServerConfig() *ServerConfig {
+	return &ServerConfig{
+		Listen:             ":8800",
+		MCPCommand:         "./bin/sqlbot",
+		DrainTimeout:       30 * time.Second,
+		KillTimeout:        5 * time.Second,
+		ReadTimeout:        30 * time.Second,
+		WriteTimeout:       5 * time.Minute,
+		DefaultDetailLevel: "basic",
+		LogLevel:           "info",
+		CacheType:          "none",
+		CachePath:          "~/.go-mycli/mcp_server_cache.db",
+		CacheSize:          1000,
+		CacheTTL:           24 * time.Hour,
+	}
+}
+
+// configKey describes one setting: its INI section/key and how to store it
+type configKey struct {
+	section string
+	key     string
+	set     func(c *ServerConfig, v string) error
+}
+
+func durationSetter(field func(c *ServerConfig) *time.Duration) func(c *ServerConfig, v string) error {
+	return func(c *ServerConfig, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+// configKeys lists every supported setting. The environment variable for a
+// key is MCP_SERVER_<KEY>, e.g. MCP_SERVER_AUTH_TOKEN.
+var configKeys = []configKey{
+	{"server", "listen", func(c *ServerConfig, v string) error { c.Listen = v; return nil }},
+	{"server", "drain_timeout", durationSetter(func(c *ServerConfig) *time.Duration { return &c.DrainTimeout })},
+	{"server", "read_timeout", durationSetter(func(c *ServerConfig) *time.Duration { return &c.ReadTimeout })},
+	{"server", "write_timeout", durationSetter(func(c *ServerConfig) *time.Duration { return &c.WriteTimeout })},
+	{"backend", "command", func(c *ServerConfig, v string) error { c.MCPCommand = v; return nil }},
+	{"backend", "kill_timeout", durationSetter(func(c *ServerConfig) *time.Duration { return &c.KillTimeout })},
+	{"backend", "default_detail_level", func(c *ServerConfig, v string) error {
+		switch v {
+		case "basic", "detailed", "expert":
+			c.DefaultDetailLevel = v
+			return nil
+		}
+		return fmt.Errorf("must be basic, detailed or expert")
+	}},
+	{"auth", "token", func(c *ServerConfig, v string) error { c.AuthToken = v; return nil }},
+	{"log", "level", func(c *ServerConfig, v string) error {
+		switch v {
+		case "debug", "info":
+			c.LogLevel = v
+			return nil
+		}
+		return fmt.Errorf("must be debug or info")
+	}},
+	{"log", "file", func(c *ServerConfig, v string) error { c.LogFile = v; return nil }},
+	{"cache", "type", func(c *ServerConfig, v string) error {
+		switch v {
+		case "none", "memory", "bolt":
+			c.CacheType = v
+			return nil
+		}
+		return fmt.Errorf("must be none, memory or bolt")
+	}},
+	{"cache", "path", func(c *ServerConfig, v string) error { c.CachePath = v; return nil }},
+	{"cache", "size", func(c *ServerConfig, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("must be a positive integer")
+		}
+		c.CacheSize = n
+		return nil
+	}},
+	{"cache", "ttl", durationSetter(func(c *ServerConfig) *time.Duration { return &c.CacheTTL })},
+}
+
+// envName returns the environment variable overriding a key
+func (k configKey) envName() string {
+	name := k.key
+	switch k.section {
+	case "auth", "log", "cache":
+		name = k.section + "_" + k.key
+	case "backend":
+		if k.key == "command" {
+			name = "mcp_command"
+		}
+	}
+	return "MCP_SERVER_" + strings.ToUpper(name)
+}
+
+// defaultConfigPath returns the config file used when --config is not given
+func defaultConfigPath() string {
+	if p := os.Getenv("MCP_SERVER_CONFIG"); p != "" {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	p := filepath.Join(home, ".go-mycli", "mcp-server.ini")
+	if _, err := os.Stat(p); err != nil {
+		return ""
+	}
+	return p
+}
+
+// LoadServerConfig builds a config from defaults, the INI file at path (if
+// any) and the environment
+func LoadServerConfig(path string) (*ServerConfig, error) {
+	cfg := DefaultServerConfig()
+
+	if path != "" {
+		file, err := ini.LoadSources(ini.LoadOptions{IgnoreInlineComment: true}, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config %s: %w", path, err)
+		}
+		for _, k := range configKeys {
+			if !file.Section(k.section).HasKey(k.key) {
+				continue
+			}
+			v := strings.TrimSpace(file.Section(k.section).Key(k.key).String())
+			if err := k.set(cfg, v); err != nil {
+				return nil, fmt.Errorf("%s: [%s] %s: %w", path, k.section, k.key, err)
+			}
+		}
+	}
+
+	for _, k := range configKeys {
+		if v, ok := os.LookupEnv(k.envName()); ok {
+			if err := k.set(cfg, v); err != nil {
+				return nil, fmt.Errorf("%s: %w", k.envName(), err)
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+var (
+	configMu      sync.RWMutex
+	currentConfig = DefaultServerConfig()
+	logFile       *os.File
+)
+
+// config returns the active configuration
+func config() *ServerConfig {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return currentConfig
+}
+
+// setConfig installs cfg as the active configuration and applies logging settings
+func setConfig(cfg *ServerConfig) {
+	configMu.Lock()
+	currentConfig = cfg
+	configMu.Unlock()
+
+	if logFile != nil {
+		logFile.Close()
+		logFile = nil
+	}
+	log.SetOutput(os.Stderr)
+	if cfg.LogFile != "" {
+		f, err := os.OpenFile(cfg.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			log.Printf("Cannot open log file %s: %v", cfg.LogFile, err)
+			return
+		}
+		logFile = f
+		log.SetOutput(f)
+	}
+}
+
+// debugf logs only when log level is debug
+func debugf(format string, args ...interface{}) {
+	if config().LogLevel == "debug" {
+		log.Printf("[debug] "+format, args...)
+	}
+}
+
+// reloadConfig re-reads the config on SIGHUP. Only non-structural settings
+// (auth, logging, detail level, drain/kill timeouts) take effect; listen
+// address, child command, HTTP timeouts and the cache need a restart.
+func reloadConfig(path string, applyFlags func(*ServerConfig)) {
+	cfg, err := LoadServerConfig(path)
+	if err != nil {
+		log.Printf("Config reload failed, keeping previous settings: %v", err)
+		return
+	}
+	applyFlags(cfg)
+
+	old := config()
+	if cfg.Listen != old.Listen || cfg.MCPCommand != old.MCPCommand ||
+		cfg.ReadTimeout != old.ReadTimeout || cfg.WriteTimeout != old.WriteTimeout ||
+		cfg.CacheType != old.CacheType || cfg.CachePath != old.CachePath ||
+		cfg.CacheSize != old.CacheSize || cfg.CacheTTL != old.CacheTTL {
+		log.Printf("Config reload: listen, command, HTTP timeout and cache changes require a restart")
+		cfg.Listen = old.Listen
+		cfg.MCPCommand = old.MCPCommand
+		cfg.ReadTimeout = old.ReadTimeout
+		cfg.WriteTimeout = old.WriteTimeout
+		cfg.CacheType = old.CacheType
+		cfg.CachePath = old.CachePath
+		cfg.CacheSize = old.CacheSize
+		cfg.CacheTTL = old.CacheTTL
+	}
+
+	setConfig(cfg)
+	log.Printf("Config reloaded")
+}
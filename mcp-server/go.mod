@@ -2,4 +2,7 @@ module mcp-server
 
 go 1.24.0
 
-require github.com/gorilla/mux v1.8.0
+require (
+	github.com/gorilla/mux v1.8.0
+	gopkg.in/ini.v1 v1.67.0
+)
@@ -1,191 +1,151 @@
 package main
 
 import (
-	"bufio"
+	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
-	"os/exec"
+	"os"
+	"os/signal"
 	"strings"
-	"sync"
+	"syscall"
 	"time"
 
+	"go-mycli/pkg/ai"
+	"go-mycli/pkg/mcp"
+
 	"github.com/gorilla/mux"
 )
 
-// MCPRequest represents an MCP tool call request
-type MCPRequest struct {
-	Query  string `json:"query"`
-	Plan   string `json:"plan"`
-	Schema string `json:"schema"`
-}
-
-// MCPStdioClient manages communication with the MCP server process
-type MCPStdioClient struct {
-	cmd    *exec.Cmd
-	stdin  io.WriteCloser
-	stdout io.ReadCloser
-	stderr io.ReadCloser
-	mu     sync.Mutex
-	reqID  int
-}
-
-func NewMCPStdioClient(command string, args []string) (*MCPStdioClient, error) {
-	cmd := exec.Command(command, args...)
-
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		return nil, err
-	}
-
-	stdout, err := cmd.StdoutPipe()
+// startMCPClient spawns the MCP child, logs its stderr and performs the handshake
+func startMCPClient(command string, args []string) (*mcp.Client, error) {
+	client, err := mcp.StartProcess(command, args, func(line string) {
+		log.Printf("[MCP stderr] %s", line)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	stderr, err := cmd.StderrPipe()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	info, err := client.Initialize(ctx, "go-mycli-mcp-bridge", "1.0.0")
 	if err != nil {
-		return nil, err
-	}
-
-	if err := cmd.Start(); err != nil {
-		return nil, err
-	}
-
-	client := &MCPStdioClient{
-		cmd:    cmd,
-		stdin:  stdin,
-		stdout: stdout,
-		stderr: stderr,
-	}
-
-	// Log stderr in background
-	go func() {
-		scanner := bufio.NewScanner(stderr)
-		for scanner.Scan() {
-			log.Printf("[MCP stderr] %s", scanner.Text())
-		}
-	}()
-
-	// Initialize the MCP server
-	if err := client.initialize(); err != nil {
-		cmd.Process.Kill()
-		return nil, err
+		client.Shutdown(time.Second)
+		return nil, fmt.Errorf("initialize failed: %w", err)
 	}
+	log.Printf("[MCP] Initialized %s %s (protocol %s)", info.ServerInfo.Name, info.ServerInfo.Version, info.ProtocolVersion)
 
 	return client, nil
 }
 
-func (c *MCPStdioClient) initialize() error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	initReq := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"id":      1,
-		"method":  "initialize",
-		"params": map[string]interface{}{
-			"protocolVersion": "2024-11-05",
-			"capabilities":    map[string]interface{}{},
-			"clientInfo": map[string]interface{}{
-				"name":    "go-mycli-mcp-bridge",
-				"version": "1.0.0",
-			},
-		},
-	}
+var mcpClient *mcp.Client
 
-	reqJSON, _ := json.Marshal(initReq)
-	if _, err := c.stdin.Write(append(reqJSON, '\n')); err != nil {
-		return err
-	}
+// responseCache holds explain results shared by every client of the bridge (nil when disabled)
+var responseCache ai.Cache
 
-	// Read response
-	scanner := bufio.NewScanner(c.stdout)
-	if scanner.Scan() {
-		log.Printf("[MCP] Initialize response: %s", scanner.Text())
+// newResponseCache builds the cache selected in the config
+func newResponseCache(cfg *ServerConfig) ai.Cache {
+	switch cfg.CacheType {
+	case "memory":
+		log.Printf("Response cache: memory (size %d, ttl %s)", cfg.CacheSize, cfg.CacheTTL)
+		return ai.NewMemoryCache(cfg.CacheSize, cfg.CacheTTL)
+	case "bolt":
+		c, err := ai.OpenBoltCache(cfg.CachePath, cfg.CacheTTL)
+		if err != nil {
+			log.Printf("Response cache disabled: cannot open bolt at %s: %v", cfg.CachePath, err)
+			return nil
+		}
+		log.Printf("Response cache: bolt at %s (ttl %s)", cfg.CachePath, cfg.CacheTTL)
+		return c
+	default:
+		return nil
 	}
-
-	c.reqID = 2
-	return nil
 }
 
-func (c *MCPStdioClient) CallTool(name string, args map[string]interface{}) (string, error) {
-	c.mu.Lock()
-	reqID := c.reqID
-	c.reqID++
-	c.mu.Unlock()
-
-	toolReq := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"id":      reqID,
-		"method":  "tools/call",
-		"params": map[string]interface{}{
-			"name":      name,
-			"arguments": args,
-		},
+// callExplain runs the explain_mysql tool, or compare_mysql when args carry a
+// second plan, answering from the response cache when an identical request
+// was already analyzed
+func callExplain(ctx context.Context, args map[string]interface{}) (string, bool, error) {
+	var req mcp.ExplainRequest
+	req.Query, _ = args["query"].(string)
+	req.Plan, _ = args["plan"].(string)
+	req.Schema, _ = args["schema"].(string)
+	req.DetailLevel, _ = args["detail_level"].(string)
+	req.CompareQuery, _ = args["compare_query"].(string)
+	req.ComparePlan, _ = args["compare_plan"].(string)
+	query, plan := req.CacheKeyParts()
+
+	if responseCache != nil {
+		if v, ok := responseCache.Get(query, plan, req.Schema, req.DetailLevel); ok {
+			debugf("cache hit for %s", ai.CacheKey(query, plan, req.Schema, req.DetailLevel))
+			return v, true, nil
+		}
 	}
 
-	reqJSON, _ := json.Marshal(toolReq)
-
-	c.mu.Lock()
-	if _, err := c.stdin.Write(append(reqJSON, '\n')); err != nil {
-		c.mu.Unlock()
-		return "", err
+	result, err := mcpClient.CallTool(ctx, req.Tool(), args)
+	if err != nil {
+		return "", false, err
 	}
-	c.mu.Unlock()
-
-	// Read response
-	scanner := bufio.NewScanner(c.stdout)
-	if scanner.Scan() {
-		var resp struct {
-			Result struct {
-				Content []struct {
-					Text string `json:"text"`
-				} `json:"content"`
-			} `json:"result"`
-			Error *struct {
-				Message string `json:"message"`
-			} `json:"error"`
-		}
-
-		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
-			return "", fmt.Errorf("failed to parse response: %w", err)
-		}
-
-		if resp.Error != nil {
-			return "", fmt.Errorf("MCP error: %s", resp.Error.Message)
-		}
 
-		if len(resp.Result.Content) > 0 {
-			return resp.Result.Content[0].Text, nil
+	if responseCache != nil {
+		if err := responseCache.Put(query, plan, req.Schema, req.DetailLevel, result, "mcp-server"); err != nil {
+			log.Printf("Cache write failed: %v", err)
 		}
 	}
-
-	return "", fmt.Errorf("no response from MCP server")
-}
-
-func (c *MCPStdioClient) Close() error {
-	c.stdin.Close()
-	c.stdout.Close()
-	c.stderr.Close()
-	return c.cmd.Wait()
+	return result, false, nil
 }
 
-var mcpClient *MCPStdioClient
-
 func main() {
+	defaults := DefaultServerConfig()
+	var configPath string
 	var listen string
 	var mcpCommand string
-	flag.StringVar(&listen, "listen", ":8800", "listen address for HTTP server")
-	flag.StringVar(&mcpCommand, "mcp-command", "./bin/sqlbot", "command to run MCP server (use quotes for complex commands)")
+	var drainTimeout time.Duration
+	var killTimeout time.Duration
+	flag.StringVar(&configPath, "config", "", "path to INI config file (default $MCP_SERVER_CONFIG or ~/.go-mycli/mcp-server.ini)")
+	flag.StringVar(&listen, "listen", defaults.Listen, "listen address for HTTP server")
+	flag.StringVar(&mcpCommand, "mcp-command", defaults.MCPCommand, "command to run MCP server (use quotes for complex commands)")
+	flag.DurationVar(&drainTimeout, "drain-timeout", defaults.DrainTimeout, "how long to wait for in-flight requests on shutdown")
+	flag.DurationVar(&killTimeout, "kill-timeout", defaults.KillTimeout, "how long to wait for the MCP child to exit before killing it")
 	flag.Parse()
 
+	if configPath == "" {
+		configPath = defaultConfigPath()
+	}
+
+	// Flags given explicitly on the command line win over the config file and environment
+	applyFlags := func(cfg *ServerConfig) {
+		flag.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "listen":
+				cfg.Listen = listen
+			case "mcp-command":
+				cfg.MCPCommand = mcpCommand
+			case "drain-timeout":
+				cfg.DrainTimeout = drainTimeout
+			case "kill-timeout":
+				cfg.KillTimeout = killTimeout
+			}
+		})
+	}
+
+	cfg, err := LoadServerConfig(configPath)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	applyFlags(cfg)
+	setConfig(cfg)
+	if configPath != "" {
+		log.Printf("Loaded config from %s", configPath)
+	}
+
 	// Parse the command into executable and args
 	// If mcp-command contains spaces, split it properly
-	cmdParts := strings.Fields(mcpCommand)
+	cmdParts := strings.Fields(cfg.MCPCommand)
 	if len(cmdParts) == 0 {
 		log.Fatalf("Invalid mcp-command: empty")
 	}
@@ -198,29 +158,108 @@ func main() {
 
 	log.Printf("Starting MCP client with command: %s %v", executable, mcpArgs)
 
-	var err error
-	mcpClient, err = NewMCPStdioClient(executable, mcpArgs)
+	mcpClient, err = startMCPClient(executable, mcpArgs)
 	if err != nil {
 		log.Fatalf("Failed to start MCP client: %v", err)
 	}
-	defer mcpClient.Close()
 
 	log.Printf("MCP client initialized successfully")
 
+	responseCache = newResponseCache(cfg)
+
 	// Create HTTP router
 	r := mux.NewRouter()
 	r.HandleFunc("/mcp", handleMCPRequest).Methods("POST")
+	r.HandleFunc("/v1/chat/completions", handleChatCompletions).Methods("POST")
+	r.HandleFunc("/v1/models", handleListModels).Methods("GET")
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	}).Methods("GET")
+	r.Use(authMiddleware)
+
+	log.Printf("HTTP server listening on %s", cfg.Listen)
+	log.Printf("Send EXPLAIN requests to http://localhost%s/mcp", cfg.Listen)
+	log.Printf("OpenAI-compatible endpoint: http://localhost%s/v1/chat/completions", cfg.Listen)
+
+	srv := &http.Server{
+		Addr:         cfg.Listen,
+		Handler:      r,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+	}
+
+	// Stop accepting connections on SIGINT/SIGTERM and drain in-flight requests
+	// before shutting the MCP child down. SIGHUP reloads the config file.
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			reloadConfig(configPath, applyFlags)
+		}
+	}()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- srv.ListenAndServe()
+	}()
 
-	log.Printf("HTTP server listening on %s", listen)
-	log.Printf("Send EXPLAIN requests to http://localhost%s/mcp", listen)
+	select {
+	case err := <-serverErr:
+		if err != nil && err != http.ErrServerClosed {
+			mcpClient.Shutdown(config().KillTimeout)
+			log.Fatalf("server failed: %v", err)
+		}
+	case sig := <-stop:
+		drainTimeout := config().DrainTimeout
+		log.Printf("Received %s, draining in-flight requests (timeout %s)", sig, drainTimeout)
+		ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Printf("HTTP shutdown incomplete: %v", err)
+		}
+		cancel()
+	}
+
+	if err := mcpClient.Shutdown(config().KillTimeout); err != nil {
+		log.Printf("MCP child exited: %v", err)
+	}
+	if c, ok := responseCache.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			log.Printf("Closing response cache: %v", err)
+		}
+	}
+	log.Printf("Shutdown complete")
+}
 
-	if err := http.ListenAndServe(listen, r); err != nil {
-		log.Fatalf("server failed: %v", err)
+// cacheStatus returns the X-Cache header value
+func cacheStatus(hit bool) string {
+	if responseCache == nil {
+		return "DISABLED"
+	}
+	if hit {
+		return "HIT"
 	}
+	return "MISS"
+}
+
+// authMiddleware requires "Authorization: Bearer <token>" when an auth token
+// is configured. The health check stays open for container probes.
+func authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := config().AuthToken
+		if token != "" && r.URL.Path != "/health" {
+			auth := r.Header.Get("Authorization")
+			if subtle.ConstantTimeCompare([]byte(auth), []byte("Bearer "+token)) != 1 {
+				debugf("rejected unauthenticated %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		debugf("%s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+		next.ServeHTTP(w, r)
+	})
 }
 
 func handleMCPRequest(w http.ResponseWriter, r *http.Request) {
@@ -232,105 +271,30 @@ func handleMCPRequest(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Try to parse as direct MCP request (plan, query, schema, detail_level)
-	var directReq struct {
-		Plan        string `json:"plan"`
-		Query       string `json:"query"`
-		Schema      string `json:"schema"`
-		DetailLevel string `json:"detail_level"`
-	}
+	var directReq mcp.ExplainRequest
 
 	if err := json.Unmarshal(body, &directReq); err == nil && directReq.Plan != "" {
-		// Direct MCP format from go-mycli
-		args := map[string]interface{}{
-			"plan":   directReq.Plan,
-			"query":  directReq.Query,
-			"schema": directReq.Schema,
-		}
-
-		// Add detail_level if provided, default to "basic"
-		if directReq.DetailLevel != "" {
-			args["detail_level"] = directReq.DetailLevel
-		} else {
-			args["detail_level"] = "basic"
+		// Direct MCP format from go-mycli; default to the configured detail level
+		if directReq.DetailLevel == "" {
+			directReq.DetailLevel = config().DefaultDetailLevel
 		}
 
-		result, err := mcpClient.CallTool("explain_mysql", args)
+		result, hit, err := callExplain(r.Context(), directReq.Args())
 
 		if err != nil {
 			log.Printf("MCP call failed: %v", err)
 			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(map[string]string{
-				"error": err.Error(),
-			})
+			json.NewEncoder(w).Encode(mcp.ExplainResponse{Error: err.Error()})
 			return
 		}
 
 		// Return simple response
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{
-			"content": result,
-		})
-		return
-	}
-
-	// Fallback: try OpenAI-style request for backward compatibility
-	var openAIReq struct {
-		Model    string `json:"model"`
-		Messages []struct {
-			Role    string `json:"role"`
-			Content string `json:"content"`
-		} `json:"messages"`
-	}
-
-	if err := json.Unmarshal(body, &openAIReq); err != nil {
-		http.Error(w, "invalid request format", http.StatusBadRequest)
-		return
-	}
-
-	// Extract the prompt from messages
-	var userPrompt string
-	for _, msg := range openAIReq.Messages {
-		if msg.Role == "user" {
-			userPrompt = msg.Content
-			break
-		}
-	}
-
-	if userPrompt == "" {
-		http.Error(w, "no user message found", http.StatusBadRequest)
-		return
-	}
-
-	// Send prompt to MCP server
-	result, err := mcpClient.CallTool("explain_mysql", map[string]interface{}{
-		"query": userPrompt,
-		"plan":  "",
-	})
-
-	if err != nil {
-		log.Printf("MCP call failed: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		w.Header().Set("X-Cache", cacheStatus(hit))
+		json.NewEncoder(w).Encode(mcp.ExplainResponse{Content: result})
 		return
 	}
 
-	// Return OpenAI-style response
-	response := map[string]interface{}{
-		"id":      "mcp-response",
-		"object":  "chat.completion",
-		"created": time.Now().Unix(),
-		"model":   "github-copilot-mcp",
-		"choices": []map[string]interface{}{
-			{
-				"index": 0,
-				"message": map[string]string{
-					"role":    "assistant",
-					"content": result,
-				},
-				"finish_reason": "stop",
-			},
-		},
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	// Fallback: treat the body as an OpenAI chat completion request for backward compatibility
+	serveChatCompletion(r.Context(), w, body)
 }
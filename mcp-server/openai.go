@@ -0,0 +1,563 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// chatModel maps an OpenAI model name onto an MCP tool call
+type chatModel struct {
+	ID          string
+	Tool        string
+	DetailLevel string
+	Description string
+}
+
+// chatModels lists the models exposed on /v1/models, the first one is the default
+var chatModels = []chatModel{
+	{ID: "sqlbot-explain", Tool: "explain_mysql", DetailLevel: "basic", Description: "EXPLAIN analysis (basic)"},
+	{ID: "sqlbot-explain-detailed", Tool: "explain_mysql", DetailLevel: "detailed", Description: "EXPLAIN analysis (detailed)"},
+	{ID: "sqlbot-explain-expert", Tool: "explain_mysql", DetailLevel: "expert", Description: "EXPLAIN analysis (expert)"},
+	{ID: "sqlbot-sql", Tool: "execute_sql", Description: "Run a read-only SELECT"},
+	{ID: "sqlbot-schema", Tool: "get_schema", Description: "Describe the database schema"},
+}
+
+// resolveChatModel returns the model for name, falling back to the default so
+// unmodified OpenAI clients (model "gpt-4o" etc.) keep working
+func resolveChatModel(name string) chatModel {
+	for _, m := range chatModels {
+		if strings.EqualFold(m.ID, name) {
+			return m
+		}
+	}
+	return chatModels[0]
+}
+
+// ChatMessage is an OpenAI chat message. Content may be a string, an array of
+// content parts, or null (assistant messages carrying tool_calls).
+type ChatMessage struct {
+	Role       string          `json:"role"`
+	Content    json.RawMessage `json:"content,omitempty"`
+	Name       string          `json:"name,omitempty"`
+	ToolCalls  []ChatToolCall  `json:"tool_calls,omitempty"`
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+}
+
+// ChatToolCall is a function call issued by the assistant
+type ChatToolCall struct {
+	ID       string           `json:"id"`
+	Type     string           `json:"type"`
+	Function ChatFunctionCall `json:"function"`
+}
+
+// ChatFunctionCall names the function to call and its JSON encoded arguments
+type ChatFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// ChatTool is a function the client offers the model
+type ChatTool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string          `json:"name"`
+		Description string          `json:"description,omitempty"`
+		Parameters  json.RawMessage `json:"parameters,omitempty"`
+	} `json:"function"`
+}
+
+// ChatCompletionRequest is the subset of the OpenAI request body we honor
+type ChatCompletionRequest struct {
+	Model         string        `json:"model"`
+	Messages      []ChatMessage `json:"messages"`
+	Stream        bool          `json:"stream"`
+	StreamOptions *struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options,omitempty"`
+	Tools      []ChatTool      `json:"tools,omitempty"`
+	ToolChoice json.RawMessage `json:"tool_choice,omitempty"`
+}
+
+// planTool returns the client tool the explain models call for an EXPLAIN
+// plan they were not given: the function tool_choice names, else one whose
+// name or description mentions EXPLAIN, else with tool_choice "required" the
+// first function. tool_choice "none" offers no tool.
+func (r ChatCompletionRequest) planTool() (ChatTool, bool) {
+	var choice string
+	var named struct {
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+	if json.Unmarshal(r.ToolChoice, &choice) != nil {
+		json.Unmarshal(r.ToolChoice, &named)
+	}
+	if choice == "none" {
+		return ChatTool{}, false
+	}
+
+	var functions []ChatTool
+	for _, t := range r.Tools {
+		if (t.Type == "function" || t.Type == "") && t.Function.Name != "" {
+			functions = append(functions, t)
+		}
+	}
+	if named.Function.Name != "" {
+		for _, t := range functions {
+			if t.Function.Name == named.Function.Name {
+				return t, true
+			}
+		}
+		return ChatTool{}, false
+	}
+	for _, t := range functions {
+		if strings.Contains(strings.ToLower(t.Function.Name+" "+t.Function.Description), "explain") {
+			return t, true
+		}
+	}
+	if choice == "required" && len(functions) > 0 {
+		return functions[0], true
+	}
+	return ChatTool{}, false
+}
+
+// sqlArgument returns the parameter of t that takes the statement: sql,
+// query or statement when the schema has one, else its first required
+// parameter, else "query"
+func (t ChatTool) sqlArgument() string {
+	var schema struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Required   []string                   `json:"required"`
+	}
+	json.Unmarshal(t.Function.Parameters, &schema)
+	for _, name := range []string{"sql", "query", "statement"} {
+		if _, ok := schema.Properties[name]; ok {
+			return name
+		}
+	}
+	if len(schema.Required) > 0 {
+		return schema.Required[0]
+	}
+	return "query"
+}
+
+// planToolCall asks the client to run tool for the plan of query
+func planToolCall(tool ChatTool, query string) ChatToolCall {
+	args, _ := json.Marshal(map[string]string{tool.sqlArgument(): query})
+	return ChatToolCall{
+		ID:       fmt.Sprintf("call_%d", time.Now().UnixNano()),
+		Type:     "function",
+		Function: ChatFunctionCall{Name: tool.Function.Name, Arguments: string(args)},
+	}
+}
+
+// toolAnswered reports whether a tool result follows the last user message,
+// so the plan was already asked for and is not asked for again
+func toolAnswered(messages []ChatMessage) bool {
+	for i := len(messages) - 1; i >= 0; i-- {
+		switch messages[i].Role {
+		case "tool":
+			return true
+		case "user":
+			return false
+		}
+	}
+	return false
+}
+
+// ChatUsage reports approximate token counts (4 characters per token)
+type ChatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// text returns the textual content of a message
+func (m ChatMessage) text() string {
+	if len(m.Content) == 0 || string(m.Content) == "null" {
+		return ""
+	}
+
+	var s string
+	if err := json.Unmarshal(m.Content, &s); err == nil {
+		return s
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(m.Content, &parts); err == nil {
+		var b strings.Builder
+		for _, p := range parts {
+			if p.Type == "text" || p.Type == "" {
+				if b.Len() > 0 {
+					b.WriteString("\n")
+				}
+				b.WriteString(p.Text)
+			}
+		}
+		return b.String()
+	}
+
+	return string(m.Content)
+}
+
+// chatToolArgs builds MCP tool arguments from the conversation.
+// System messages are passed as schema context, tool results and user messages
+// are searched for an EXPLAIN JSON plan, and the last user message is the query.
+func chatToolArgs(model chatModel, messages []ChatMessage) (map[string]interface{}, error) {
+	var system []string
+	var userText, plan string
+
+	for _, msg := range messages {
+		text := msg.text()
+		switch msg.Role {
+		case "system", "developer":
+			if text != "" {
+				system = append(system, text)
+			}
+		case "user":
+			if text != "" {
+				userText = text
+			}
+			if p := findPlanJSON(text); p != "" {
+				plan = p
+			}
+		case "tool":
+			if p := findPlanJSON(text); p != "" {
+				plan = p
+			}
+		}
+	}
+
+	if userText == "" && model.Tool != "get_schema" {
+		return nil, fmt.Errorf("no user message found")
+	}
+
+	switch model.Tool {
+	case "explain_mysql":
+		query := strings.TrimSpace(strings.Replace(userText, plan, "", 1))
+		return map[string]interface{}{
+			"plan":         plan,
+			"query":        query,
+			"schema":       strings.Join(system, "\n\n"),
+			"detail_level": model.DetailLevel,
+		}, nil
+	case "execute_sql":
+		return map[string]interface{}{"sql": stripCodeFence(userText)}, nil
+	default:
+		return map[string]interface{}{}, nil
+	}
+}
+
+// findPlanJSON returns the first JSON object in text that looks like an EXPLAIN plan
+func findPlanJSON(text string) string {
+	for start := strings.Index(text, "{"); start != -1; {
+		depth := 0
+		inString := false
+		escaped := false
+		for i := start; i < len(text); i++ {
+			ch := text[i]
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\' && inString:
+				escaped = true
+			case ch == '"':
+				inString = !inString
+			case ch == '{' && !inString:
+				depth++
+			case ch == '}' && !inString:
+				depth--
+				if depth == 0 {
+					candidate := text[start : i+1]
+					if strings.Contains(candidate, "query_block") && json.Valid([]byte(candidate)) {
+						return candidate
+					}
+					i = len(text)
+				}
+			}
+		}
+
+		next := strings.Index(text[start+1:], "{")
+		if next == -1 {
+			break
+		}
+		start += next + 1
+	}
+	return ""
+}
+
+// stripCodeFence removes a surrounding ``` fence (optionally ```sql) from text
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	text = strings.TrimPrefix(text, "```")
+	if nl := strings.Index(text, "\n"); nl != -1 {
+		text = text[nl+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+}
+
+// statementText returns the SQL of a question: the first ``` fenced block
+// when there is one, else the whole text
+func statementText(text string) string {
+	start := strings.Index(text, "```")
+	if start == -1 {
+		return strings.TrimSpace(text)
+	}
+	block := text[start:]
+	if end := strings.Index(block[3:], "```"); end != -1 {
+		block = block[:end+6]
+	}
+	return stripCodeFence(block)
+}
+
+// estimateTokens approximates OpenAI token counts
+func estimateTokens(s string) int {
+	if s == "" {
+		return 0
+	}
+	return (len(s) + 3) / 4
+}
+
+func writeOpenAIError(w http.ResponseWriter, status int, errType, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"message": message,
+			"type":    errType,
+		},
+	})
+}
+
+// handleChatCompletions implements POST /v1/chat/completions
+func handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	serveChatCompletion(r.Context(), w, body)
+}
+
+// handleListModels implements GET /v1/models
+func handleListModels(w http.ResponseWriter, r *http.Request) {
+	var data []map[string]interface{}
+	for _, m := range chatModels {
+		data = append(data, map[string]interface{}{
+			"id":          m.ID,
+			"object":      "model",
+			"created":     0,
+			"owned_by":    "go-mycli",
+			"description": m.Description,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"object": "list",
+		"data":   data,
+	})
+}
+
+// serveChatCompletion answers an OpenAI chat completion request body using the MCP child
+func serveChatCompletion(ctx context.Context, w http.ResponseWriter, body []byte) {
+	var req ChatCompletionRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", "invalid request format")
+		return
+	}
+	if len(req.Messages) == 0 {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", "messages must not be empty")
+		return
+	}
+
+	model := resolveChatModel(req.Model)
+	args, err := chatToolArgs(model, req.Messages)
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	modelName := req.Model
+	if modelName == "" {
+		modelName = model.ID
+	}
+	id := fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano())
+	created := time.Now().Unix()
+
+	// Without a plan the explain models ask a client tool for one, and
+	// analyze the tool's reply on the next request
+	if plan, _ := args["plan"].(string); plan == "" && model.Tool == "explain_mysql" && !toolAnswered(req.Messages) {
+		if tool, ok := req.planTool(); ok {
+			query, _ := args["query"].(string)
+			call := planToolCall(tool, statementText(query))
+			writeChatCompletion(w, req, id, created, modelName, toolCallDeltas(call), map[string]interface{}{
+				"role":       "assistant",
+				"content":    nil,
+				"tool_calls": []ChatToolCall{call},
+			}, "tool_calls", call.Function.Arguments)
+			return
+		}
+	}
+
+	var result string
+	hit := false
+	if model.Tool == "explain_mysql" {
+		result, hit, err = callExplain(ctx, args)
+	} else {
+		result, err = mcpClient.CallTool(ctx, model.Tool, args)
+	}
+	if err != nil {
+		log.Printf("MCP call failed: %v", err)
+		writeOpenAIError(w, http.StatusBadGateway, "server_error", err.Error())
+		return
+	}
+
+	w.Header().Set("X-Cache", cacheStatus(hit))
+	writeChatCompletion(w, req, id, created, modelName, textDeltas(result), map[string]interface{}{
+		"role":    "assistant",
+		"content": result,
+	}, "stop", result)
+}
+
+// writeChatCompletion answers with message, or with deltas as server-sent
+// events when the request streams. completion is the text usage counts.
+func writeChatCompletion(w http.ResponseWriter, req ChatCompletionRequest, id string, created int64, model string, deltas []map[string]interface{}, message map[string]interface{}, finishReason, completion string) {
+	var promptChars strings.Builder
+	for _, msg := range req.Messages {
+		promptChars.WriteString(msg.text())
+	}
+	usage := ChatUsage{
+		PromptTokens:     estimateTokens(promptChars.String()),
+		CompletionTokens: estimateTokens(completion),
+	}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+
+	if req.Stream {
+		includeUsage := req.StreamOptions != nil && req.StreamOptions.IncludeUsage
+		streamChatCompletion(w, id, created, model, deltas, finishReason, usage, includeUsage)
+		return
+	}
+
+	response := map[string]interface{}{
+		"id":      id,
+		"object":  "chat.completion",
+		"created": created,
+		"model":   model,
+		"choices": []map[string]interface{}{
+			{
+				"index":         0,
+				"message":       message,
+				"finish_reason": finishReason,
+			},
+		},
+		"usage": usage,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// textDeltas splits result into line by line content deltas so clients
+// render progressively
+func textDeltas(result string) []map[string]interface{} {
+	deltas := []map[string]interface{}{{"role": "assistant", "content": ""}}
+	for _, line := range strings.SplitAfter(result, "\n") {
+		if line == "" {
+			continue
+		}
+		deltas = append(deltas, map[string]interface{}{"content": line})
+	}
+	return deltas
+}
+
+// toolCallDeltas streams call the way OpenAI does: the call's id and name
+// first, then its arguments
+func toolCallDeltas(call ChatToolCall) []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"role":    "assistant",
+			"content": nil,
+			"tool_calls": []map[string]interface{}{{
+				"index":    0,
+				"id":       call.ID,
+				"type":     call.Type,
+				"function": map[string]string{"name": call.Function.Name, "arguments": ""},
+			}},
+		},
+		{
+			"tool_calls": []map[string]interface{}{{
+				"index":    0,
+				"function": map[string]string{"arguments": call.Function.Arguments},
+			}},
+		},
+	}
+}
+
+// streamChatCompletion writes deltas as server-sent chat.completion.chunk events
+func streamChatCompletion(w http.ResponseWriter, id string, created int64, model string, deltas []map[string]interface{}, finishReason string, usage ChatUsage, includeUsage bool) {
+	flusher, _ := w.(http.Flusher)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	send := func(delta map[string]interface{}, finishReason interface{}, extra map[string]interface{}) {
+		chunk := map[string]interface{}{
+			"id":      id,
+			"object":  "chat.completion.chunk",
+			"created": created,
+			"model":   model,
+			"choices": []map[string]interface{}{
+				{
+					"index":         0,
+					"delta":         delta,
+					"finish_reason": finishReason,
+				},
+			},
+		}
+		for k, v := range extra {
+			chunk[k] = v
+		}
+		data, _ := json.Marshal(chunk)
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	for _, delta := range deltas {
+		send(delta, nil, nil)
+	}
+
+	send(map[string]interface{}{}, finishReason, nil)
+
+	if includeUsage {
+		chunk := map[string]interface{}{
+			"id":      id,
+			"object":  "chat.completion.chunk",
+			"created": created,
+			"model":   model,
+			"choices": []interface{}{},
+			"usage":   usage,
+		}
+		data, _ := json.Marshal(chunk)
+		fmt.Fprintf(w, "data: %s\n\n", data)
+	}
+
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
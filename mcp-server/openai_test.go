@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const explainTool = `{"type": "function", "function": {"name": "run_explain", "description": "Run EXPLAIN FORMAT=JSON", "parameters": {"type": "object", "properties": {"sql": {"type": "string"}}, "required": ["sql"]}}}`
+
+func TestChatCompletionToolCalls(t *testing.T) {
+	body := `{"model": "sqlbot-explain", "messages": [{"role": "user", "content": "Why is this slow?\n` + "```sql\\nSELECT * FROM t\\n```" + `"}], "tools": [` + explainTool + `]}`
+	rec := httptest.NewRecorder()
+	serveChatCompletion(context.Background(), rec, []byte(body))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Choices []struct {
+			Message struct {
+				Content   *string        `json:"content"`
+				ToolCalls []ChatToolCall `json:"tool_calls"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || len(resp.Choices) != 1 {
+		t.Fatalf("response %s", rec.Body)
+	}
+	choice := resp.Choices[0]
+	if choice.FinishReason != "tool_calls" || choice.Message.Content != nil || len(choice.Message.ToolCalls) != 1 {
+		t.Fatalf("choice = %+v", choice)
+	}
+	call := choice.Message.ToolCalls[0]
+	if call.ID == "" || call.Type != "function" || call.Function.Name != "run_explain" || call.Function.Arguments != `{"sql":"SELECT * FROM t"}` {
+		t.Errorf("tool call = %+v", call)
+	}
+}
+
+func TestChatCompletionStreamsToolCalls(t *testing.T) {
+	body := `{"messages": [{"role": "user", "content": "SELECT 1"}], "tools": [` + explainTool + `], "stream": true}`
+	rec := httptest.NewRecorder()
+	serveChatCompletion(context.Background(), rec, []byte(body))
+
+	var name, arguments, finish string
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok || data == "[DONE]" {
+			continue
+		}
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					ToolCalls []ChatToolCall `json:"tool_calls"`
+				} `json:"delta"`
+				FinishReason *string `json:"finish_reason"`
+			} `json:"choices"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("chunk %s: %v", data, err)
+		}
+		for _, c := range chunk.Choices {
+			for _, tc := range c.Delta.ToolCalls {
+				name += tc.Function.Name
+				arguments += tc.Function.Arguments
+			}
+			if c.FinishReason != nil {
+				finish = *c.FinishReason
+			}
+		}
+	}
+	if name != "run_explain" || arguments != `{"sql":"SELECT 1"}` || finish != "tool_calls" {
+		t.Errorf("streamed name %q arguments %q finish %q", name, arguments, finish)
+	}
+	if !strings.HasSuffix(rec.Body.String(), "data: [DONE]\n\n") {
+		t.Errorf("stream not terminated: %s", rec.Body)
+	}
+}
+
+func TestPlanTool(t *testing.T) {
+	weather := `{"type": "function", "function": {"name": "get_weather", "parameters": {}}}`
+	tests := []struct {
+		tools, choice, want string
+	}{
+		{"", "", ""},
+		{weather, "", ""},
+		{weather, `"auto"`, ""},
+		{weather, `"required"`, "get_weather"},
+		{weather + "," + explainTool, "", "run_explain"},
+		{weather + "," + explainTool, `"none"`, ""},
+		{weather + "," + explainTool, `{"type": "function", "function": {"name": "get_weather"}}`, "get_weather"},
+		{explainTool, `{"type": "function", "function": {"name": "missing"}}`, ""},
+	}
+	for _, tt := range tests {
+		var req ChatCompletionRequest
+		if err := json.Unmarshal([]byte(`{"tools": [`+tt.tools+`]}`), &req); err != nil {
+			t.Fatal(err)
+		}
+		req.ToolChoice = json.RawMessage(tt.choice)
+		tool, ok := req.planTool()
+		if got := tool.Function.Name; ok != (tt.want != "") || got != tt.want {
+			t.Errorf("planTool(tools %s, tool_choice %s) = %q, %v, want %q", tt.tools, tt.choice, got, ok, tt.want)
+		}
+	}
+}
+
+func TestToolAnswered(t *testing.T) {
+	user := ChatMessage{Role: "user", Content: json.RawMessage(`"SELECT 1"`)}
+	call := ChatMessage{Role: "assistant", ToolCalls: []ChatToolCall{{ID: "call_1", Type: "function"}}}
+	reply := ChatMessage{Role: "tool", ToolCallID: "call_1", Content: json.RawMessage(`"ERROR 1146"`)}
+	if toolAnswered([]ChatMessage{user}) {
+		t.Error("a lone question counts as answered")
+	}
+	if !toolAnswered([]ChatMessage{user, call, reply}) {
+		t.Error("the tool reply is not seen, the plan would be asked for again")
+	}
+	if toolAnswered([]ChatMessage{user, call, reply, user}) {
+		t.Error("a new question after the reply counts as answered")
+	}
+}
+
+func TestStatementText(t *testing.T) {
+	tests := map[string]string{
+		"SELECT 1": "SELECT 1",
+		"Why?\n```sql\nSELECT * FROM t\n```\nthanks": "SELECT * FROM t",
+		"```\nSELECT 2\n```":                         "SELECT 2",
+	}
+	for in, want := range tests {
+		if got := statementText(in); got != want {
+			t.Errorf("statementText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestChatToolArgsToolMessages(t *testing.T) {
+	// A plan returned by a tool earlier in the conversation is analyzed
+	plan := `{"query_block": {"select_id": 1}}`
+	args, err := chatToolArgs(resolveChatModel("sqlbot-explain"), []ChatMessage{
+		{Role: "user", Content: json.RawMessage(`"Why is this slow?"`)},
+		{Role: "assistant", ToolCalls: []ChatToolCall{{ID: "call_1", Type: "function"}}},
+		{Role: "tool", ToolCallID: "call_1", Content: json.RawMessage(`"` + strings.ReplaceAll(plan, `"`, `\"`) + `"`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args["plan"] != plan || args["query"] != "Why is this slow?" {
+		t.Errorf("args = %v", args)
+	}
+}
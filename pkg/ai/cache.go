@@ -0,0 +1,249 @@
+package ai
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Cache stores AI responses for a query/plan/schema/detail level combination.
+// It is shared by the go-mycli client and the mcp-server bridge so both key
+// entries the same way.
+type Cache interface {
+	Get(query, planJSON, schema, detailLevel string) (string, bool)
+	Put(query, planJSON, schema, detailLevel, result, model string) error
+}
+
+// CacheKey returns the hex SHA-256 key used for a cached response
+func CacheKey(query, planJSON, schema, detailLevel string) string {
+	h := sha256.New()
+	h.Write([]byte(query))
+	h.Write([]byte("\n"))
+	h.Write([]byte(planJSON))
+	h.Write([]byte("\n"))
+	h.Write([]byte(schema))
+	h.Write([]byte("\n"))
+	h.Write([]byte(detailLevel))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cachedResponse holds a cached result
+type cachedResponse struct {
+	Result   string    `json:"result"`
+	CachedAt time.Time `json:"cached_at"`
+	Model    string    `json:"model"`
+}
+
+// expired reports whether the response is older than ttl (0 means never)
+func (cr cachedResponse) expired(ttl time.Duration) bool {
+	return ttl > 0 && time.Since(cr.CachedAt) > ttl
+}
+
+// NewBoltCache returns a Cache persisted in a bolt database at path.
+// Entries older than ttl are treated as misses; a ttl of 0 keeps them forever.
+func NewBoltCache(path string, ttl time.Duration) Cache {
+	c := newBoltCache(path)
+	c.ttl = ttl
+	return c
+}
+
+// OpenBoltCache returns a Cache in a bolt database at path that is opened
+// once and kept open until Close, for the bridge: every request shares the
+// handle instead of queueing on the file's exclusive lock. The database stays
+// locked against other processes meanwhile.
+func OpenBoltCache(path string, ttl time.Duration) (Cache, error) {
+	c := newBoltCache(path)
+	c.ttl = ttl
+	if dir := filepath.Dir(c.path); dir != "" {
+		_ = os.MkdirAll(dir, 0700)
+	}
+	db, err := bolt.Open(c.path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	c.db = db
+	return c, nil
+}
+
+// basic bolt cache wrapper
+func newBoltCache(path string) *boltCache {
+	// Expand ~ to home dir
+	if strings.HasPrefix(path, "~") {
+		if h, err := os.UserHomeDir(); err == nil {
+			path = strings.Replace(path, "~", h, 1)
+		}
+	}
+	return &boltCache{path: path}
+}
+
+type boltCache struct {
+	path string
+	ttl  time.Duration
+	db   *bolt.DB // kept open by OpenBoltCache, nil when each call opens the file
+}
+
+// open returns the database and the function releasing it: the shared handle,
+// or the file opened for this call alone
+func (b *boltCache) open() (*bolt.DB, func(), error) {
+	if b.db != nil {
+		return b.db, func() {}, nil
+	}
+	db, err := bolt.Open(b.path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, func() { db.Close() }, nil
+}
+
+// Close closes the database kept open by OpenBoltCache
+func (b *boltCache) Close() error {
+	if b.db == nil {
+		return nil
+	}
+	return b.db.Close()
+}
+
+func (b *boltCache) key(query, planJSON, schema, detailLevel string) string {
+	return CacheKey(query, planJSON, schema, detailLevel)
+}
+
+func (b *boltCache) Get(query, planJSON, schema, detailLevel string) (string, bool) {
+	if b.path == "" {
+		return "", false
+	}
+	key := b.key(query, planJSON, schema, detailLevel)
+	db, release, err := b.open()
+	if err != nil {
+		return "", false
+	}
+	defer release()
+	var result string
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte("mcp_cache"))
+		if bucket == nil {
+			return nil
+		}
+		v := bucket.Get([]byte(key))
+		if v == nil {
+			return nil
+		}
+		var cr cachedResponse
+		if err := json.Unmarshal(v, &cr); err != nil {
+			return nil
+		}
+		if cr.expired(b.ttl) {
+			return nil
+		}
+		result = cr.Result
+		return nil
+	})
+	if err != nil {
+		return "", false
+	}
+	if result == "" {
+		return "", false
+	}
+	return result, true
+}
+
+func (b *boltCache) Put(query, planJSON, schema, detailLevel, result, model string) error {
+	if b.path == "" {
+		return nil
+	}
+	key := b.key(query, planJSON, schema, detailLevel)
+	if dir := filepath.Dir(b.path); dir != "" {
+		_ = os.MkdirAll(dir, 0700)
+	}
+	db, release, err := b.open()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	cr := cachedResponse{Result: result, CachedAt: time.Now(), Model: model}
+	v, _ := json.Marshal(cr)
+	return db.Update(func(tx *bolt.Tx) error {
+		bkt, err := tx.CreateBucketIfNotExists([]byte("mcp_cache"))
+		if err != nil {
+			return err
+		}
+		return bkt.Put([]byte(key), v)
+	})
+}
+
+// NewMemoryCache returns an in-memory LRU Cache holding at most size entries.
+// Entries older than ttl are treated as misses; a ttl of 0 keeps them until evicted.
+func NewMemoryCache(size int, ttl time.Duration) Cache {
+	if size <= 0 {
+		size = 1000
+	}
+	return &memoryCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+type memoryEntry struct {
+	key string
+	cachedResponse
+}
+
+type memoryCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List // front is most recently used
+	entries map[string]*list.Element
+}
+
+func (m *memoryCache) Get(query, planJSON, schema, detailLevel string) (string, bool) {
+	key := CacheKey(query, planJSON, schema, detailLevel)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.entries[key]
+	if !ok {
+		return "", false
+	}
+	entry := el.Value.(*memoryEntry)
+	if entry.expired(m.ttl) {
+		m.order.Remove(el)
+		delete(m.entries, key)
+		return "", false
+	}
+	m.order.MoveToFront(el)
+	return entry.Result, true
+}
+
+func (m *memoryCache) Put(query, planJSON, schema, detailLevel, result, model string) error {
+	key := CacheKey(query, planJSON, schema, detailLevel)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := &memoryEntry{key: key, cachedResponse: cachedResponse{Result: result, CachedAt: time.Now(), Model: model}}
+	if el, ok := m.entries[key]; ok {
+		el.Value = entry
+		m.order.MoveToFront(el)
+		return nil
+	}
+
+	m.entries[key] = m.order.PushFront(entry)
+	for m.order.Len() > m.size {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
@@ -1,8 +1,14 @@
 package ai
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestBoltCachePutGet(t *testing.T) {
@@ -21,3 +27,82 @@ func TestBoltCachePutGet(t *testing.T) {
 		t.Fatalf("unexpected value: %v %v", v, ok)
 	}
 }
+
+func TestOpenBoltCacheConcurrent(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "cache.db")
+	c, err := OpenBoltCache(p, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.(io.Closer).Close()
+
+	// Concurrent requests share the open database instead of waiting on its lock
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := range 32 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q := fmt.Sprintf("SELECT %d", i%8)
+			if err := c.Put(q, "{}", "", "basic", q, "m"); err != nil {
+				errs <- err
+				return
+			}
+			if v, ok := c.Get(q, "{}", "", "basic"); !ok || v != q {
+				errs <- fmt.Errorf("Get(%q) = %q, %v", q, v, ok)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewMemoryCache(2, 0)
+	_ = c.Put("q1", "{}", "", "basic", "r1", "m")
+	_ = c.Put("q2", "{}", "", "basic", "r2", "m")
+
+	// Touch q1 so q2 becomes the eviction candidate
+	if v, ok := c.Get("q1", "{}", "", "basic"); !ok || v != "r1" {
+		t.Fatalf("unexpected value: %v %v", v, ok)
+	}
+	_ = c.Put("q3", "{}", "", "basic", "r3", "m")
+
+	if _, ok := c.Get("q2", "{}", "", "basic"); ok {
+		t.Fatalf("expected q2 to be evicted")
+	}
+	if v, ok := c.Get("q3", "{}", "", "basic"); !ok || v != "r3" {
+		t.Fatalf("unexpected value: %v %v", v, ok)
+	}
+}
+
+func TestMemoryCacheTTL(t *testing.T) {
+	c := NewMemoryCache(10, time.Millisecond)
+	_ = c.Put("q", "{}", "", "basic", "r", "m")
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := c.Get("q", "{}", "", "basic"); ok {
+		t.Fatalf("expected expired entry to miss")
+	}
+}
+
+func TestCacheKeyDistinguishesDetailLevel(t *testing.T) {
+	if CacheKey("q", "{}", "", "basic") == CacheKey("q", "{}", "", "expert") {
+		t.Fatalf("expected different keys for different detail levels")
+	}
+}
+
+func TestMCPStdioClientReportsMissingCommand(t *testing.T) {
+	c, err := NewAIClient("mcp_stdio", "/nonexistent/sqlbot", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := c.ExplainPlan(context.Background(), "SELECT 1", "{}", "", "basic"); err == nil {
+		t.Fatalf("expected error starting a missing command")
+	}
+	if err := c.(io.Closer).Close(); err != nil {
+		t.Fatalf("close of unstarted client failed: %v", err)
+	}
+}
@@ -1,187 +1,102 @@
 package ai
 
 import (
-	"bytes"
-	"crypto/sha256"
-	"encoding/hex"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
 	"strings"
-	"time"
 
-	bolt "go.etcd.io/bbolt"
+	"go-mycli/pkg/mcp"
 )
 
-// AIClient defines the interface for asking LLMs to explain a plan
+// AIClient defines the interface for asking LLMs to explain a plan, or to
+// weigh the plans of two queries that should return the same rows. Canceling
+// ctx abandons the request.
 type AIClient interface {
-	ExplainPlan(query, planJSON, schema, detailLevel string) (string, error)
+	ExplainPlan(ctx context.Context, query, planJSON, schema, detailLevel string) (string, error)
+	ComparePlans(ctx context.Context, queryA, planA, queryB, planB, schema, detailLevel string) (string, error)
 }
 
-// NewAIClient returns an AIClient based on mode: copilot_mcp_http (default)
-func NewAIClient(mode, url, cachePath string) (AIClient, error) {
+// compareRequest is the request asking which of two queries is preferable
+func compareRequest(queryA, planA, queryB, planB, schema, detailLevel string) mcp.ExplainRequest {
+	return mcp.ExplainRequest{
+		Plan:         planA,
+		Query:        queryA,
+		Schema:       schema,
+		DetailLevel:  detailLevel,
+		CompareQuery: queryB,
+		ComparePlan:  planB,
+	}
+}
+
+// NewAIClient returns an AIClient based on mode: copilot_mcp_http (default) or
+// mcp_stdio. For copilot_mcp_http endpoint is the bridge URL; for mcp_stdio it is
+// the command line of the MCP server to spawn. Clients implementing io.Closer
+// should be closed when the session ends.
+func NewAIClient(mode, endpoint, cachePath string) (AIClient, error) {
 	// Default to copilot_mcp_http if not specified
 	if mode == "" {
 		mode = "copilot_mcp_http"
 	}
 
 	if strings.EqualFold(mode, "copilot_mcp_http") {
-		if url == "" {
+		if endpoint == "" {
 			// Use local proxy by default
-			url = "http://127.0.0.1:8800/mcp"
+			endpoint = "http://127.0.0.1:8800/mcp"
 		}
-		c := &mcpHTTPClient{url: url}
+		c := &mcpHTTPClient{http: &mcp.HTTPClient{URL: endpoint}}
 		if cachePath != "" {
-			c.cache = newBoltCache(cachePath)
+			c.cache = NewBoltCache(cachePath, 0)
 		}
 		return c, nil
 	}
 
-	return nil, fmt.Errorf("unknown ai client mode: %s (supported: copilot_mcp_http)", mode)
-}
-
-// cachedResponse holds a cached result
-type cachedResponse struct {
-	Result   string    `json:"result"`
-	CachedAt time.Time `json:"cached_at"`
-	Model    string    `json:"model"`
-}
-
-// basic bolt cache wrapper
-func newBoltCache(path string) *boltCache {
-	// Expand ~ to home dir
-	if strings.HasPrefix(path, "~") {
-		if h, err := os.UserHomeDir(); err == nil {
-			path = strings.Replace(path, "~", h, 1)
+	if strings.EqualFold(mode, "mcp_stdio") {
+		c := newMCPStdioClient(endpoint)
+		if cachePath != "" {
+			c.cache = NewBoltCache(cachePath, 0)
 		}
+		return c, nil
 	}
-	return &boltCache{path: path}
-}
 
-type boltCache struct {
-	path string
+	return nil, fmt.Errorf("unknown ai client mode: %s (supported: copilot_mcp_http, mcp_stdio)", mode)
 }
 
-func (b *boltCache) key(query, planJSON, schema, detailLevel string) string {
-	h := sha256.New()
-	h.Write([]byte(query))
-	h.Write([]byte("\n"))
-	h.Write([]byte(planJSON))
-	h.Write([]byte("\n"))
-	h.Write([]byte(schema))
-	h.Write([]byte("\n"))
-	h.Write([]byte(detailLevel))
-	return hex.EncodeToString(h.Sum(nil))
-}
-
-func (b *boltCache) Get(query, planJSON, schema, detailLevel string) (string, bool) {
-	if b.path == "" {
-		return "", false
-	}
-	key := b.key(query, planJSON, schema, detailLevel)
-	db, err := bolt.Open(b.path, 0600, &bolt.Options{Timeout: 1 * time.Second})
-	if err != nil {
-		return "", false
-	}
-	defer db.Close()
-	var result string
-	err = db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte("mcp_cache"))
-		if bucket == nil {
-			return nil
-		}
-		v := bucket.Get([]byte(key))
-		if v == nil {
-			return nil
-		}
-		var cr cachedResponse
-		if err := json.Unmarshal(v, &cr); err != nil {
-			return nil
-		}
-		result = cr.Result
-		return nil
-	})
-	if err != nil {
-		return "", false
-	}
-	if result == "" {
-		return "", false
-	}
-	return result, true
+// mcpHTTPClient calls a local MCP HTTP endpoint (Copilot or other)
+type mcpHTTPClient struct {
+	http  *mcp.HTTPClient
+	cache Cache
 }
 
-func (b *boltCache) Put(query, planJSON, schema, detailLevel, result, model string) error {
-	if b.path == "" {
-		return nil
-	}
-	key := b.key(query, planJSON, schema, detailLevel)
-	db, err := bolt.Open(b.path, 0600, &bolt.Options{Timeout: 1 * time.Second})
-	if err != nil {
-		return err
-	}
-	defer db.Close()
-
-	cr := cachedResponse{Result: result, CachedAt: time.Now(), Model: model}
-	v, _ := json.Marshal(cr)
-	return db.Update(func(tx *bolt.Tx) error {
-		bkt, err := tx.CreateBucketIfNotExists([]byte("mcp_cache"))
-		if err != nil {
-			return err
-		}
-		return bkt.Put([]byte(key), v)
+func (c *mcpHTTPClient) ExplainPlan(ctx context.Context, query, planJSON, schema, detailLevel string) (string, error) {
+	// MCP protocol: send plan, query, schema, and detail level
+	return c.explain(ctx, mcp.ExplainRequest{
+		Plan:        planJSON,
+		Query:       query,
+		Schema:      schema,
+		DetailLevel: detailLevel,
 	})
 }
 
-// mcpHTTPClient calls a local MCP HTTP endpoint (Copilot or other)
-type mcpHTTPClient struct {
-	url   string
-	cache *boltCache
+func (c *mcpHTTPClient) ComparePlans(ctx context.Context, queryA, planA, queryB, planB, schema, detailLevel string) (string, error) {
+	return c.explain(ctx, compareRequest(queryA, planA, queryB, planB, schema, detailLevel))
 }
 
-func (c *mcpHTTPClient) ExplainPlan(query, planJSON, schema, detailLevel string) (string, error) {
+// explain posts req to the bridge, answering from the cache when it can
+func (c *mcpHTTPClient) explain(ctx context.Context, req mcp.ExplainRequest) (string, error) {
+	query, plan := req.CacheKeyParts()
 	if c.cache != nil {
-		if v, ok := c.cache.Get(query, planJSON, schema, detailLevel); ok {
+		if v, ok := c.cache.Get(query, plan, req.Schema, req.DetailLevel); ok {
 			return v, nil
 		}
 	}
 
-	// MCP protocol: send plan, query, schema, and detail level
-	reqBody := map[string]interface{}{
-		"plan":         planJSON,
-		"query":        query,
-		"schema":       schema,
-		"detail_level": detailLevel,
-	}
-	buf, _ := json.Marshal(reqBody)
-	resp, err := http.Post(c.url, "application/json", bytes.NewReader(buf))
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	res, err := c.http.Explain(ctx, req)
 	if err != nil {
 		return "", err
 	}
 
-	// Parse MCP response
-	var mcpResp struct {
-		Error   string `json:"error"`
-		Content string `json:"content"`
-	}
-	if err := json.Unmarshal(body, &mcpResp); err != nil {
-		// If not MCP format, return raw response
-		return string(body), nil
-	}
-
-	if mcpResp.Error != "" {
-		return "", fmt.Errorf("MCP error: %s", mcpResp.Error)
-	}
-
-	res := mcpResp.Content
 	if c.cache != nil {
-		_ = c.cache.Put(query, planJSON, schema, detailLevel, res, "copilot_mcp_http")
+		_ = c.cache.Put(query, plan, req.Schema, req.DetailLevel, res, "copilot_mcp_http")
 	}
 	return res, nil
 }
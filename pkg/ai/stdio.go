@@ -0,0 +1,124 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-mycli/pkg/mcp"
+)
+
+// DefaultMCPCommand is the MCP server spawned in mcp_stdio mode when no command is configured
+const DefaultMCPCommand = "sqlbot"
+
+// mcpStdioClient runs an MCP server (sqlbot by default) as a child process and
+// calls its explain_mysql tool over stdio. The child is started on first use,
+// restarted if it dies, and stopped by Close.
+type mcpStdioClient struct {
+	command string
+	cache   Cache
+
+	mu         sync.Mutex
+	client     *mcp.Client
+	lastStderr atomic.Value // string
+}
+
+func newMCPStdioClient(command string) *mcpStdioClient {
+	if strings.TrimSpace(command) == "" {
+		command = DefaultMCPCommand
+	}
+	return &mcpStdioClient{command: command}
+}
+
+// conn returns a live connection to the child, starting it if needed
+func (c *mcpStdioClient) conn(ctx context.Context) (*mcp.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client != nil {
+		select {
+		case <-c.client.Done():
+			// Child exited; reap it and start a new one
+			c.client.Shutdown(time.Second)
+			c.client = nil
+		default:
+			return c.client, nil
+		}
+	}
+
+	parts := strings.Fields(c.command)
+	client, err := mcp.StartProcess(parts[0], parts[1:], func(line string) {
+		// Keep the child quiet on the terminal, but remember why it may have failed
+		c.lastStderr.Store(line)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start MCP server %q: %w", c.command, err)
+	}
+
+	initCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if _, err := client.Initialize(initCtx, "go-mycli", "0.1.0"); err != nil {
+		client.Shutdown(time.Second)
+		if line, _ := c.lastStderr.Load().(string); line != "" {
+			return nil, fmt.Errorf("MCP server %q failed to initialize: %w (%s)", c.command, err, line)
+		}
+		return nil, fmt.Errorf("MCP server %q failed to initialize: %w", c.command, err)
+	}
+
+	c.client = client
+	return client, nil
+}
+
+func (c *mcpStdioClient) ExplainPlan(ctx context.Context, query, planJSON, schema, detailLevel string) (string, error) {
+	return c.explain(ctx, mcp.ExplainRequest{
+		Plan:        planJSON,
+		Query:       query,
+		Schema:      schema,
+		DetailLevel: detailLevel,
+	})
+}
+
+func (c *mcpStdioClient) ComparePlans(ctx context.Context, queryA, planA, queryB, planB, schema, detailLevel string) (string, error) {
+	return c.explain(ctx, compareRequest(queryA, planA, queryB, planB, schema, detailLevel))
+}
+
+// explain calls the tool for req on the child, answering from the cache when
+// it can
+func (c *mcpStdioClient) explain(ctx context.Context, req mcp.ExplainRequest) (string, error) {
+	query, plan := req.CacheKeyParts()
+	if c.cache != nil {
+		if v, ok := c.cache.Get(query, plan, req.Schema, req.DetailLevel); ok {
+			return v, nil
+		}
+	}
+
+	client, err := c.conn(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := client.CallTool(ctx, req.Tool(), req.Args())
+	if err != nil {
+		return "", err
+	}
+
+	if c.cache != nil {
+		_ = c.cache.Put(query, plan, req.Schema, req.DetailLevel, res, "mcp_stdio")
+	}
+	return res, nil
+}
+
+// Close stops the child process, if running
+func (c *mcpStdioClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client == nil {
+		return nil
+	}
+	err := c.client.Shutdown(5 * time.Second)
+	c.client = nil
+	return err
+}
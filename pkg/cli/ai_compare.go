@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// planAccess is one table access of an EXPLAIN plan
+type planAccess struct {
+	Table  string
+	Access string
+	Key    string
+	Rows   float64
+}
+
+func (a planAccess) String() string {
+	s := a.Table + ": " + a.Access
+	if a.Key != "" {
+		s += " (" + a.Key + ")"
+	}
+	return s + fmt.Sprintf(", %.0f row%s", a.Rows, plural(int(a.Rows)))
+}
+
+// planOverview is what \ai compare shows of a plan side by side with another
+type planOverview struct {
+	Cost   float64
+	Rows   float64 // rows examined per scan, summed over the tables
+	Score  int
+	Tables []planAccess
+}
+
+// splitComparedQueries splits the argument of \ai compare at ;; into its two
+// queries, dropping the terminators
+func splitComparedQueries(arg string) (string, string, bool) {
+	a, b, ok := strings.Cut(arg, ";;")
+	if !ok {
+		return "", "", false
+	}
+	a = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(a), ";"))
+	b = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(b), ";"))
+	if a == "" || b == "" || strings.Contains(b, ";;") {
+		return "", "", false
+	}
+	return a, b, true
+}
+
+// overviewPlan reads the cost and the table accesses of an EXPLAIN
+// FORMAT=JSON plan, in the order analyzePlanHeuristics visits them
+func overviewPlan(planJSON string) (planOverview, error) {
+	findings, err := analyzePlanHeuristics(planJSON)
+	if err != nil {
+		return planOverview{}, err
+	}
+	var plan map[string]interface{}
+	if err := json.Unmarshal([]byte(planJSON), &plan); err != nil {
+		return planOverview{}, err
+	}
+
+	o := planOverview{Score: severityScore(findings)}
+	if block, ok := plan["query_block"].(map[string]interface{}); ok {
+		if cost, ok := block["cost_info"].(map[string]interface{}); ok {
+			o.Cost = planNumber(cost["query_cost"])
+		}
+	}
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			if name, ok := n["table_name"].(string); ok {
+				a := planAccess{Table: name, Rows: planNumber(n["rows_examined_per_scan"])}
+				a.Access, _ = n["access_type"].(string)
+				a.Key, _ = n["key"].(string)
+				o.Tables = append(o.Tables, a)
+				o.Rows += a.Rows
+			}
+			keys := make([]string, 0, len(n))
+			for k := range n {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(n[k])
+			}
+		case []interface{}:
+			for _, child := range n {
+				walk(child)
+			}
+		}
+	}
+	walk(plan)
+	return o, nil
+}
+
+// comparisonRows lays two plans out side by side: totals first, then the
+// table accesses in plan order
+func comparisonRows(a, b planOverview) [][]string {
+	rows := [][]string{
+		{"Cost", fmt.Sprintf("%.2f", a.Cost), fmt.Sprintf("%.2f", b.Cost)},
+		{"Rows examined", fmt.Sprintf("%.0f", a.Rows), fmt.Sprintf("%.0f", b.Rows)},
+		{"Score", fmt.Sprintf("%d (%s)", a.Score, scoreVerdict(a.Score)), fmt.Sprintf("%d (%s)", b.Score, scoreVerdict(b.Score))},
+	}
+	for i := 0; i < len(a.Tables) || i < len(b.Tables); i++ {
+		row := []string{fmt.Sprintf("Table %d", i+1), "", ""}
+		if i < len(a.Tables) {
+			row[1] = a.Tables[i].String()
+		}
+		if i < len(b.Tables) {
+			row[2] = b.Tables[i].String()
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// compareQueries implements \ai compare <queryA> ;; <queryB>: it EXPLAINs
+// both queries, shows their plans side by side and asks the AI backend which
+// one is preferable
+func (p *PromptExecutor) compareQueries(arg string) {
+	queryA, queryB, ok := splitComparedQueries(arg)
+	if !ok {
+		fmt.Println("Usage: \\ai compare <query A> ;; <query B>")
+		return
+	}
+
+	var plans [2]string
+	var overviews [2]planOverview
+	for i, query := range []string{queryA, queryB} {
+		plan, err := p.queryJSONPlan(query)
+		if err != nil {
+			fmt.Printf("EXPLAIN of query %c failed: %v\n", 'A'+i, err)
+			return
+		}
+		overview, err := overviewPlan(plan)
+		if err != nil {
+			fmt.Printf(tr("Error: %v\n"), err)
+			return
+		}
+		plans[i], overviews[i] = plan, overview
+	}
+	fmt.Print(formatMySQLTable([]string{"", "Query A", "Query B"}, comparisonRows(overviews[0], overviews[1])))
+
+	if p.aiServerMode == "" && p.aiServerURL == "" {
+		fmt.Println("AI comparison not configured. Set --ai-server-url and --ai-server-mode")
+		return
+	}
+	ctx, stop := p.statementContext()
+	defer stop()
+	schema, err := p.collectSchemaSnapshot(ctx)
+	if err != nil {
+		fmt.Printf("Failed to collect schema: %v\n", err)
+		return
+	}
+	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		fmt.Printf("Failed to marshal schema: %v\n", err)
+		return
+	}
+	client, err := p.aiBackend()
+	if err != nil {
+		fmt.Printf("Failed to create AI client: %v\n", err)
+		return
+	}
+	advice, err := client.ComparePlans(ctx, queryA, plans[0], queryB, plans[1], string(schemaJSON), p.aiDetailLevel)
+	if err != nil {
+		fmt.Printf("Failed to get AI advice: %v\n", err)
+		return
+	}
+
+	fmt.Println("\n🤖 AI Comparison:")
+	fmt.Println("=================")
+	fmt.Printf(tr("(AI backend: %s %s)\n"), p.aiServerMode, p.aiEndpoint())
+	fmt.Println(advice)
+	fmt.Println()
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitComparedQueries(t *testing.T) {
+	a, b, ok := splitComparedQueries(" SELECT 1; ;; SELECT 2 ;")
+	if !ok || a != "SELECT 1" || b != "SELECT 2" {
+		t.Fatalf("got %q %q %v", a, b, ok)
+	}
+	for _, arg := range []string{"", "SELECT 1", "SELECT 1 ;;", ";; SELECT 2", "SELECT 1 ;; SELECT 2 ;; SELECT 3"} {
+		if _, _, ok := splitComparedQueries(arg); ok {
+			t.Errorf("%q: expected a usage error", arg)
+		}
+	}
+}
+
+func TestComparisonRows(t *testing.T) {
+	scan := `{"query_block": {"cost_info": {"query_cost": "102.50"}, "table": {"table_name": "film", "access_type": "ALL", "rows_examined_per_scan": 1000}}}`
+	join := `{"query_block": {"cost_info": {"query_cost": "3.10"}, "nested_loop": [
+		{"table": {"table_name": "f", "access_type": "const", "key": "PRIMARY", "rows_examined_per_scan": 1}},
+		{"table": {"table_name": "i", "access_type": "ref", "key": "idx_fk_film_id", "rows_examined_per_scan": "4"}}]}}`
+
+	a, err := overviewPlan(scan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := overviewPlan(join)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"Cost", "102.50", "3.10"},
+		{"Rows examined", "1000", "5"},
+		{"Score", "30 (warn)", "0 (ok)"},
+		{"Table 1", "film: ALL, 1000 rows", "f: const (PRIMARY), 1 row"},
+		{"Table 2", "", "i: ref (idx_fk_film_id), 4 rows"},
+	}
+	if got := comparisonRows(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q", got)
+	}
+
+	if _, err := overviewPlan("not json"); err == nil {
+		t.Error("expected an error for an invalid plan")
+	}
+}
@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+)
+
+// maxAliasDepth stops aliases that expand to each other
+const maxAliasDepth = 8
+
+var (
+	aliasNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+	aliasArgPattern  = regexp.MustCompile(`\$(\d|\*)`)
+)
+
+func copyAliases(aliases map[string]string) map[string]string {
+	copied := make(map[string]string, len(aliases))
+	for name, text := range aliases {
+		copied[name] = text
+	}
+	return copied
+}
+
+func aliasNames(aliases map[string]string) []string {
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// defineAlias handles \alias: without arguments it lists the aliases, with
+// "name = text" it defines one for this session
+func (p *PromptExecutor) defineAlias(args string) {
+	args = strings.TrimSpace(args)
+	if args == "" {
+		if len(p.aliases) == 0 {
+			fmt.Println("No aliases. Define one with \\alias ll = SHOW FULL PROCESSLIST, or in the [aliases] section of ~/.go-myclirc")
+			return
+		}
+		rows := make([][]string, 0, len(p.aliases))
+		for _, name := range aliasNames(p.aliases) {
+			rows = append(rows, []string{"\\" + name, p.aliases[name]})
+		}
+		fmt.Print(formatMySQLTable([]string{"Alias", "Expands to"}, rows))
+		return
+	}
+
+	name, text, ok := strings.Cut(args, "=")
+	name = strings.TrimPrefix(strings.TrimSpace(name), "\\")
+	text = strings.TrimSpace(text)
+	if !ok {
+		if text, ok := p.aliases[name]; ok {
+			fmt.Printf("\\%s = %s\n", name, text)
+		} else {
+			fmt.Printf("No alias \\%s\n", name)
+		}
+		return
+	}
+	if !aliasNamePattern.MatchString(name) || text == "" {
+		fmt.Println("Usage: \\alias <name> = <statement or command>, e.g. \\alias ll = SHOW FULL PROCESSLIST")
+		return
+	}
+	if p.aliases == nil {
+		p.aliases = make(map[string]string)
+	}
+	p.aliases[name] = text
+	fmt.Printf("\\%s = %s (this session; add it to [aliases] in ~/.go-myclirc to keep it)\n", name, text)
+}
+
+// removeAlias handles \unalias <name>
+func (p *PromptExecutor) removeAlias(name string) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "\\")
+	if _, ok := p.aliases[name]; !ok {
+		fmt.Printf("No alias \\%s\n", name)
+		return
+	}
+	delete(p.aliases, name)
+	fmt.Printf("Removed \\%s\n", name)
+}
+
+// expandAlias returns the text an alias command such as "\top 10" runs.
+// $1 to $9 are replaced by the arguments and $* by all of them; arguments the
+// text does not refer to are appended.
+func expandAlias(text string, args []string) string {
+	used := false
+	expanded := aliasArgPattern.ReplaceAllStringFunc(text, func(ref string) string {
+		used = true
+		if ref == "$*" {
+			return strings.Join(args, " ")
+		}
+		n, _ := strconv.Atoi(ref[1:])
+		if n >= 1 && n <= len(args) {
+			return args[n-1]
+		}
+		return ""
+	})
+	if !used && len(args) > 0 {
+		expanded = strings.TrimSuffix(strings.TrimSpace(expanded), ";") + " " + strings.Join(args, " ")
+	}
+	return expanded
+}
+
+// runAlias runs the alias named by a backslash command. It returns false if
+// there is no such alias.
+func (p *PromptExecutor) runAlias(in string) bool {
+	parts := strings.Fields(strings.TrimPrefix(in, "\\"))
+	if len(parts) == 0 {
+		return false
+	}
+	text, ok := p.aliases[parts[0]]
+	if !ok {
+		return false
+	}
+	if p.aliasDepth >= maxAliasDepth {
+		fmt.Printf("Alias \\%s expands into itself; stopped\n", parts[0])
+		return true
+	}
+	p.aliasDepth++
+	defer func() { p.aliasDepth-- }()
+
+	expanded := expandAlias(text, parts[1:])
+	if strings.HasPrefix(strings.TrimSpace(expanded), "\\") {
+		p.Executor(expanded)
+		return true
+	}
+	stmts, ok := splitScript(expanded)
+	if !ok {
+		fmt.Printf("Alias \\%s mixes SQL and commands; use separate aliases\n", parts[0])
+		return true
+	}
+	ctx, stop := p.statementContext()
+	defer stop()
+	for _, stmt := range stmts {
+		if ctx.Err() != nil {
+			break
+		}
+		p.echoStatement(stmt.SQL)
+		p.ExecuteSQL(ctx, stmt.SQL, stmt.Vertical)
+	}
+	return true
+}
+
+// aliasSuggestions completes alias names while the first word of the line is
+// a backslash command
+func (p *PromptExecutor) aliasSuggestions(before string) ([]prompt.Suggest, bool) {
+	before = strings.TrimLeft(before, " ")
+	if !strings.HasPrefix(before, "\\") || strings.ContainsAny(before, " \t") {
+		return nil, false
+	}
+	var suggestions []prompt.Suggest
+	for _, name := range aliasNames(p.aliases) {
+		if strings.HasPrefix(name, before[1:]) {
+			suggestions = append(suggestions, prompt.Suggest{Text: "\\" + name, Description: truncateQuery(p.aliases[name], 50)})
+		}
+	}
+	return suggestions, true
+}
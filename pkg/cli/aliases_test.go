@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandAlias(t *testing.T) {
+	tests := []struct {
+		text string
+		args []string
+		want string
+	}{
+		{"SHOW FULL PROCESSLIST", nil, "SHOW FULL PROCESSLIST"},
+		{"SELECT * FROM $1 ORDER BY id DESC LIMIT 10", []string{"orders"}, "SELECT * FROM orders ORDER BY id DESC LIMIT 10"},
+		{"SELECT * FROM $1 LIMIT $2", []string{"t"}, "SELECT * FROM t LIMIT "},
+		{"CALL sys.ps_trace_statement_digest($*)", []string{"'abc',", "10"}, "CALL sys.ps_trace_statement_digest('abc', 10)"},
+		{"SHOW TABLES;", []string{"LIKE", "'a%'"}, "SHOW TABLES LIKE 'a%'"},
+	}
+	for _, tt := range tests {
+		if got := expandAlias(tt.text, tt.args); got != tt.want {
+			t.Errorf("expandAlias(%q, %q) = %q, want %q", tt.text, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestAliasCommands(t *testing.T) {
+	p := &PromptExecutor{}
+	p.Executor("\\alias ll = SHOW FULL PROCESSLIST")
+	p.Executor("\\alias on = \\json on")
+	if want := map[string]string{"ll": "SHOW FULL PROCESSLIST", "on": "\\json on"}; !reflect.DeepEqual(p.aliases, want) {
+		t.Fatalf("aliases = %v, want %v", p.aliases, want)
+	}
+
+	p.Executor("\\on")
+	if !p.enableJSONExport {
+		t.Error("\\on did not run its command")
+	}
+
+	p.Executor("\\alias loop = \\loop")
+	p.Executor("\\loop") // must stop rather than recurse forever
+
+	suggestions, ok := p.aliasSuggestions("\\l")
+	if !ok || len(suggestions) != 2 || suggestions[0].Text != "\\ll" {
+		t.Errorf("aliasSuggestions(\\l) = %v, %v", suggestions, ok)
+	}
+	if _, ok := p.aliasSuggestions("SELECT \\l"); ok {
+		t.Error("aliasSuggestions completed inside a statement")
+	}
+
+	p.Executor("\\unalias ll")
+	if _, ok := p.aliases["ll"]; ok {
+		t.Error("\\unalias did not remove the alias")
+	}
+}
+
+func TestLoadAliases(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), "myclirc")
+	content := "[main]\nstyle = monokai\n\n[aliases]\nll = SHOW FULL PROCESSLIST\n"
+	if err := os.WriteFile(rc, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GO_MYCLI_RC", rc)
+	cfg := LoadSyntaxConfig()
+	if got := cfg.Aliases["ll"]; got != "SHOW FULL PROCESSLIST" {
+		t.Errorf("Aliases[ll] = %q", got)
+	}
+}
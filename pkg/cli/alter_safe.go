@@ -0,0 +1,347 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// ER_ALTER_OPERATION_NOT_SUPPORTED and ER_ALTER_OPERATION_NOT_SUPPORTED_REASON,
+// returned when an ALTER cannot use the requested algorithm or lock
+const (
+	errAlterNotSupported       = 1845
+	errAlterNotSupportedReason = 1846
+)
+
+// alterRebuildRate is the rough copy speed used to estimate how long a
+// rebuilding ALTER runs, in bytes per second
+const alterRebuildRate = 100 << 20
+
+var (
+	alterTablePattern  = regexp.MustCompile("(?is)^ALTER\\s+TABLE\\s+((?:`[^`]+`|[\\w$]+)(?:\\s*\\.\\s*(?:`[^`]+`|[\\w$]+))?)\\s+(.+)$")
+	alterAlgorithm     = regexp.MustCompile(`(?i)\bALGORITHM\s*=?\s*(\w+)`)
+	alterLock          = regexp.MustCompile(`(?i)\bLOCK\s*=?\s*(\w+)`)
+	qualifiedTableName = regexp.MustCompile("^(?:(`[^`]+`|[\\w$]+)\\s*\\.\\s*)?(`[^`]+`|[\\w$]+)$")
+)
+
+// alterPlan is an algorithm and lock to try an ALTER with
+type alterPlan struct {
+	Algorithm string
+	Lock      string // empty leaves the lock to the server
+}
+
+func (a alterPlan) String() string {
+	s := "ALGORITHM=" + a.Algorithm
+	if a.Lock != "" {
+		s += ", LOCK=" + a.Lock
+	}
+	return s
+}
+
+// describe says what a plan means for the table while the ALTER runs
+func (a alterPlan) describe() string {
+	switch {
+	case a.Algorithm == "INSTANT":
+		return "metadata change only; the table is not copied"
+	case a.Algorithm == "INPLACE" && a.Lock == "NONE":
+		return "reads and writes continue while the table is changed in place (it may be rebuilt)"
+	case a.Algorithm == "INPLACE":
+		return "changed in place, but writes may be blocked while it runs"
+	}
+	return "the table is copied and writes are blocked while it runs"
+}
+
+// alterPlans are the plans tried, the least disruptive first
+var alterPlans = []alterPlan{
+	{Algorithm: "INSTANT"},
+	{Algorithm: "INPLACE", Lock: "NONE"},
+	{Algorithm: "INPLACE"},
+	{Algorithm: "COPY"},
+}
+
+// parseAlterTable splits an ALTER TABLE statement into the table name and the
+// alter specification
+func parseAlterTable(stmt string) (table, spec string, ok bool) {
+	m := alterTablePattern.FindStringSubmatch(strings.TrimSpace(stmt))
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], strings.TrimSpace(m[2]), true
+}
+
+// splitTableName splits "db.table", either part possibly quoted; database is
+// used when no database is given
+func splitTableName(name, database string) (string, string) {
+	m := qualifiedTableName.FindStringSubmatch(name)
+	if m == nil {
+		return database, name
+	}
+	if m[1] != "" {
+		database = strings.Trim(m[1], "`")
+	}
+	return database, strings.Trim(m[2], "`")
+}
+
+// requestedPlan returns the algorithm and lock the statement names itself
+func requestedPlan(spec string) (alterPlan, bool) {
+	m := alterAlgorithm.FindStringSubmatch(spec)
+	if m == nil {
+		return alterPlan{}, false
+	}
+	plan := alterPlan{Algorithm: strings.ToUpper(m[1])}
+	if l := alterLock.FindStringSubmatch(spec); l != nil {
+		plan.Lock = strings.ToUpper(l[1])
+	}
+	return plan, true
+}
+
+// alterWith returns the ALTER for table with a plan's clauses appended
+func alterWith(table, spec string, plan alterPlan) string {
+	if _, ok := requestedPlan(spec); ok {
+		return "ALTER TABLE " + table + " " + spec
+	}
+	return "ALTER TABLE " + table + " " + spec + ", " + plan.String()
+}
+
+// isUnsupportedAlter reports whether err is the server refusing a plan, as
+// opposed to the ALTER itself being wrong
+func isUnsupportedAlter(err error) bool {
+	var myErr *mysql.MySQLError
+	return errors.As(err, &myErr) && (myErr.Number == errAlterNotSupported || myErr.Number == errAlterNotSupportedReason)
+}
+
+// alterCheckTable names the empty copy plans are tried on
+func alterCheckTable(table string) string {
+	name := "_" + table + "_alter_check"
+	if len(name) > 64 {
+		name = name[:52] + "_alter_check"
+	}
+	return name
+}
+
+// findAlterPlan tries the plans on an empty copy of the table and returns the
+// first one the server accepts, with why the ones before it were refused.
+// The copy is kept out of the binary log when the account may do so.
+func (p *PromptExecutor) findAlterPlan(ctx context.Context, db, table, spec string, plans []alterPlan) (alterPlan, []string, error) {
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return alterPlan{}, nil, err
+	}
+	defer conn.Close()
+	_, _ = conn.ExecContext(ctx, "SET SESSION sql_log_bin = 0")
+
+	check := quoteIdentifier(db) + "." + quoteIdentifier(alterCheckTable(table))
+	source := quoteIdentifier(db) + "." + quoteIdentifier(table)
+	defer conn.ExecContext(ctx, "DROP TABLE IF EXISTS "+check)
+
+	var refused []string
+	for _, plan := range plans {
+		if _, err := conn.ExecContext(ctx, "DROP TABLE IF EXISTS "+check); err != nil {
+			return alterPlan{}, nil, err
+		}
+		if _, err := conn.ExecContext(ctx, "CREATE TABLE "+check+" LIKE "+source); err != nil {
+			return alterPlan{}, nil, fmt.Errorf("cannot create an empty copy to check the ALTER on: %w", err)
+		}
+		_, err := conn.ExecContext(ctx, alterWith(check, spec, plan))
+		if err == nil {
+			return plan, refused, nil
+		}
+		if !isUnsupportedAlter(err) {
+			return alterPlan{}, refused, err
+		}
+		refused = append(refused, fmt.Sprintf("%s: %v", plan, err))
+	}
+	return alterPlan{}, refused, fmt.Errorf("the server accepts none of the algorithms for this ALTER")
+}
+
+// alterSafe handles \alter-safe <ALTER TABLE ...>: it finds the least
+// disruptive algorithm the change supports, reports the table size and the
+// replication impact, asks before running it and shows its progress. It
+// reports whether the ALTER ran and succeeded.
+func (p *PromptExecutor) alterSafe(stmt string) bool {
+	stmt = strings.TrimSuffix(strings.TrimSpace(stmt), ";")
+	name, spec, ok := parseAlterTable(stmt)
+	if !ok {
+		fmt.Println("Usage: \\alter-safe ALTER TABLE <table> <changes>")
+		return false
+	}
+	db, table := splitTableName(name, p.database)
+	if db == "" {
+		fmt.Println("No database selected; use \\u <db> or a qualified table name")
+		return false
+	}
+	ctx, stop := p.statementContext()
+	defer stop()
+
+	var rows, size int64
+	err := p.db.QueryRowContext(ctx, `SELECT COALESCE(TABLE_ROWS, 0), COALESCE(DATA_LENGTH + INDEX_LENGTH, 0)
+		FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?`, db, table).Scan(&rows, &size)
+	if err == sql.ErrNoRows {
+		fmt.Printf("Table %s.%s does not exist\n", db, table)
+		return false
+	}
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return false
+	}
+
+	plans := alterPlans
+	if requested, ok := requestedPlan(spec); ok {
+		plans = []alterPlan{requested}
+	}
+	plan, refused, err := p.findAlterPlan(ctx, db, table, spec, plans)
+	for _, r := range refused {
+		fmt.Printf("  not supported: %s\n", r)
+	}
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return false
+	}
+
+	fmt.Printf("Table %s.%s: about %s rows, %s\n", db, table, formatRowCount(float64(rows)), formatBytes(size))
+	fmt.Printf("Plan: %s (%s)\n", plan, plan.describe())
+	estimate := time.Duration(float64(size) / alterRebuildRate * float64(time.Second)).Round(time.Second)
+	if plan.Algorithm != "INSTANT" {
+		fmt.Printf("A rebuild at ~100 MB/s would take about %s\n", max(estimate, time.Second))
+	}
+	var replicas int
+	_ = p.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM INFORMATION_SCHEMA.PROCESSLIST
+		WHERE COMMAND IN ('Binlog Dump', 'Binlog Dump GTID')`).Scan(&replicas)
+	switch {
+	case replicas == 0:
+		fmt.Println("Replication: no replicas connected")
+	case plan.Algorithm == "INSTANT":
+		fmt.Printf("Replication: %d replica%s connected; an INSTANT change adds no noticeable lag\n", replicas, plural(replicas))
+	default:
+		fmt.Printf("Replication: %d replica%s connected; each applies the ALTER after it finishes here and lags for about as long as it runs\n",
+			replicas, plural(replicas))
+	}
+
+	if !askYesNo("Run it? [y/N] ") {
+		fmt.Println("ALTER not run")
+		return false
+	}
+	if replicas > 0 && plan.Algorithm != "INSTANT" && p.lag.Max > 0 {
+		// The replicas start the ALTER only when it is done here; starting
+		// while they are already behind adds its run time to that lag
+		lag := p.newReplicaLagChecker(ctx, p.lag.Max)
+		err := lag.wait(ctx)
+		lag.close()
+		if err != nil {
+			fmt.Printf("ALTER not run: %v\n", canceled(ctx, err))
+			return false
+		}
+	}
+	return p.runAlter(ctx, db, alterWith(name, spec, plan), plan)
+}
+
+// runAlter runs the ALTER on a connection of its own and polls
+// performance_schema for the stage it is in until it finishes or ctx is
+// canceled, which stops it on the server. It reports whether the ALTER
+// succeeded.
+func (p *PromptExecutor) runAlter(ctx context.Context, db, stmt string, plan alterPlan) bool {
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return false
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "USE "+quoteIdentifier(db)); err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return false
+	}
+	id, err := connectionID(ctx, conn)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), canceled(ctx, err))
+		return false
+	}
+	monitored := p.alterStagesEnabled(ctx)
+	if !monitored && plan.Algorithm != "INSTANT" {
+		fmt.Println("Progress needs the stage/innodb/alter% instruments and the events_stages_current consumer:")
+		fmt.Println("  UPDATE performance_schema.setup_instruments SET ENABLED = 'YES' WHERE NAME LIKE 'stage/innodb/alter%';")
+		fmt.Println("  UPDATE performance_schema.setup_consumers SET ENABLED = 'YES' WHERE NAME LIKE 'events_stages_%';")
+	}
+
+	start := time.Now()
+	stopKill := p.killOnCancel(ctx, id)
+	defer stopKill()
+	done := make(chan error, 1)
+	go func() {
+		_, err := conn.ExecContext(ctx, stmt)
+		done <- err
+	}()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	width := 0
+	for {
+		select {
+		case <-ctx.Done():
+			if width > 0 {
+				fmt.Printf("\r%s\r", strings.Repeat(" ", width))
+			}
+			fmt.Printf("ALTER canceled after %s\n", time.Since(start).Round(time.Millisecond))
+			return false
+		case err := <-done:
+			if width > 0 {
+				fmt.Printf("\r%s\r", strings.Repeat(" ", width))
+			}
+			if err != nil {
+				fmt.Printf("Error after %s: %v\n", time.Since(start).Round(time.Millisecond), err)
+				return false
+			}
+			p.cacheTime = time.Time{}
+			fmt.Printf("ALTER completed in %s (%s)\n", time.Since(start).Round(time.Millisecond), plan)
+			return true
+		case <-ticker.C:
+			line := fmt.Sprintf("Running for %s", time.Since(start).Round(time.Second))
+			if monitored {
+				if stage := p.alterStage(ctx, id); stage != "" {
+					line += ": " + stage
+				}
+			}
+			fmt.Printf("\r%-*s", width, line)
+			width = max(width, len(line))
+		}
+	}
+}
+
+// alterStagesEnabled reports whether performance_schema records the InnoDB
+// ALTER stages
+func (p *PromptExecutor) alterStagesEnabled(ctx context.Context) bool {
+	var instruments, consumers int
+	err := p.db.QueryRowContext(ctx, `SELECT
+		(SELECT COUNT(*) FROM performance_schema.setup_instruments WHERE NAME LIKE 'stage/innodb/alter%' AND ENABLED = 'YES'),
+		(SELECT COUNT(*) FROM performance_schema.setup_consumers WHERE NAME = 'events_stages_current' AND ENABLED = 'YES')`).Scan(&instruments, &consumers)
+	return err == nil && instruments > 0 && consumers > 0
+}
+
+// alterStage returns the stage the ALTER on connection id is in, with its
+// progress when InnoDB estimates one
+func (p *PromptExecutor) alterStage(ctx context.Context, id int64) string {
+	var event string
+	var completed, estimated sql.NullInt64
+	err := p.db.QueryRowContext(ctx, `SELECT s.EVENT_NAME, s.WORK_COMPLETED, s.WORK_ESTIMATED
+		FROM performance_schema.events_stages_current s
+		JOIN performance_schema.threads t ON t.THREAD_ID = s.THREAD_ID
+		WHERE t.PROCESSLIST_ID = ?`, id).Scan(&event, &completed, &estimated)
+	if err != nil {
+		return ""
+	}
+	return formatAlterStage(event, completed.Int64, estimated.Int64)
+}
+
+// formatAlterStage shows a stage event such as "stage/innodb/alter table
+// (read PK and internal sort)" with its percentage done
+func formatAlterStage(event string, completed, estimated int64) string {
+	stage := strings.TrimPrefix(strings.TrimPrefix(event, "stage/innodb/"), "stage/sql/")
+	if estimated > 0 {
+		return fmt.Sprintf("%s %d%%", stage, min(100, completed*100/estimated))
+	}
+	return stage
+}
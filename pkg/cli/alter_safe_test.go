@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestParseAlterTable(t *testing.T) {
+	tests := []struct {
+		stmt      string
+		wantTable string
+		wantSpec  string
+		wantOK    bool
+	}{
+		{"ALTER TABLE film ADD COLUMN rating2 INT", "film", "ADD COLUMN rating2 INT", true},
+		{"alter table sakila.`order` drop index idx_x", "sakila.`order`", "drop index idx_x", true},
+		{"ALTER TABLE\n  payment\n  ADD INDEX (amount)", "payment", "ADD INDEX (amount)", true},
+		{"ALTER DATABASE sakila CHARACTER SET utf8mb4", "", "", false},
+		{"ALTER TABLE film", "", "", false},
+	}
+	for _, tt := range tests {
+		table, spec, ok := parseAlterTable(tt.stmt)
+		if table != tt.wantTable || spec != tt.wantSpec || ok != tt.wantOK {
+			t.Errorf("parseAlterTable(%q) = %q, %q, %v; want %q, %q, %v", tt.stmt, table, spec, ok, tt.wantTable, tt.wantSpec, tt.wantOK)
+		}
+	}
+}
+
+func TestSplitTableName(t *testing.T) {
+	tests := []struct {
+		name, wantDB, wantTable string
+	}{
+		{"film", "sakila", "film"},
+		{"other.film", "other", "film"},
+		{"`my.db`.`my table`", "my.db", "my table"},
+	}
+	for _, tt := range tests {
+		db, table := splitTableName(tt.name, "sakila")
+		if db != tt.wantDB || table != tt.wantTable {
+			t.Errorf("splitTableName(%q) = %q, %q; want %q, %q", tt.name, db, table, tt.wantDB, tt.wantTable)
+		}
+	}
+}
+
+func TestAlterWith(t *testing.T) {
+	tests := []struct {
+		spec string
+		plan alterPlan
+		want string
+	}{
+		{"ADD COLUMN x INT", alterPlan{Algorithm: "INSTANT"}, "ALTER TABLE film ADD COLUMN x INT, ALGORITHM=INSTANT"},
+		{"ADD INDEX (x)", alterPlan{Algorithm: "INPLACE", Lock: "NONE"}, "ALTER TABLE film ADD INDEX (x), ALGORITHM=INPLACE, LOCK=NONE"},
+		{"ADD INDEX (x), ALGORITHM=COPY", alterPlan{Algorithm: "INSTANT"}, "ALTER TABLE film ADD INDEX (x), ALGORITHM=COPY"},
+	}
+	for _, tt := range tests {
+		if got := alterWith("film", tt.spec, tt.plan); got != tt.want {
+			t.Errorf("alterWith(%q, %v) = %q, want %q", tt.spec, tt.plan, got, tt.want)
+		}
+	}
+}
+
+func TestRequestedPlan(t *testing.T) {
+	plan, ok := requestedPlan("ADD INDEX (x), algorithm inplace, LOCK = shared")
+	if !ok || plan != (alterPlan{Algorithm: "INPLACE", Lock: "SHARED"}) {
+		t.Errorf("requestedPlan = %+v, %v", plan, ok)
+	}
+	if _, ok := requestedPlan("ADD INDEX (x)"); ok {
+		t.Error("requestedPlan found a plan in a statement without one")
+	}
+}
+
+func TestIsUnsupportedAlter(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&mysql.MySQLError{Number: 1845, Message: "ALGORITHM=INSTANT is not supported for this operation"}, true},
+		{fmt.Errorf("check: %w", &mysql.MySQLError{Number: 1846}), true},
+		{&mysql.MySQLError{Number: 1060, Message: "Duplicate column name 'x'"}, false},
+		{fmt.Errorf("connection refused"), false},
+	}
+	for _, tt := range tests {
+		if got := isUnsupportedAlter(tt.err); got != tt.want {
+			t.Errorf("isUnsupportedAlter(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestFormatAlterStage(t *testing.T) {
+	tests := []struct {
+		event               string
+		completed, estimate int64
+		want                string
+	}{
+		{"stage/innodb/alter table (read PK and internal sort)", 450, 1000, "alter table (read PK and internal sort) 45%"},
+		{"stage/innodb/alter table (end)", 1200, 1000, "alter table (end) 100%"},
+		{"stage/sql/copy to tmp table", 0, 0, "copy to tmp table"},
+	}
+	for _, tt := range tests {
+		if got := formatAlterStage(tt.event, tt.completed, tt.estimate); got != tt.want {
+			t.Errorf("formatAlterStage(%q) = %q, want %q", tt.event, got, tt.want)
+		}
+	}
+}
+
+func TestAlterCheckTable(t *testing.T) {
+	if got := alterCheckTable("film"); got != "_film_alter_check" {
+		t.Errorf("alterCheckTable(film) = %q", got)
+	}
+	long := "a_table_name_that_is_exactly_sixty_four_characters_long_aaaaaaaa"
+	if got := alterCheckTable(long); len(got) > 64 {
+		t.Errorf("alterCheckTable(%d chars) = %q, longer than 64", len(long), got)
+	}
+}
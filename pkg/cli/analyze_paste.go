@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var (
+	treeTableScan = regexp.MustCompile(`Table scan on (\S+)`)
+	treeIndexScan = regexp.MustCompile(`Index scan on (\S+) using (\S+)`)
+	treeRows      = regexp.MustCompile(`rows=([0-9.e+]+)`)
+)
+
+// startPastedPlan implements \analyze-paste: the lines that follow, up to
+// one ending with ;, are a plan to analyze without the server it came from
+func (p *PromptExecutor) startPastedPlan() {
+	p.pastingPlan, p.pastedPlan = true, nil
+	fmt.Println("Paste the EXPLAIN output (JSON or TREE, bare or as a client printed it) and end it with ; (\\c cancels)")
+}
+
+// readPastedPlan takes one line of the plan, analyzing the plan once the
+// line ending with ; has been read
+func (p *PromptExecutor) readPastedPlan(line string) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "\\c" || trimmed == "\\clear" {
+		p.pastingPlan, p.pastedPlan = false, nil
+		return
+	}
+	if !strings.HasSuffix(trimmed, ";") {
+		p.pastedPlan = append(p.pastedPlan, line)
+		return
+	}
+	p.pastedPlan = append(p.pastedPlan, strings.TrimSuffix(strings.TrimRight(line, " \t"), ";"))
+	text := strings.Join(p.pastedPlan, "\n")
+	p.pastingPlan, p.pastedPlan = false, nil
+	p.analyzePastedPlan(os.Stdout, text)
+}
+
+// analyzePastedPlan scores a pasted plan and shows it as a tree, then asks
+// the AI backend about it when one is configured. With no server to ask,
+// the AI gets neither the query nor the schema.
+func (p *PromptExecutor) analyzePastedPlan(w io.Writer, text string) {
+	var plan string
+	var findings []ExplainFinding
+	if tree, ok := extractTreePlan(text); ok {
+		plan, findings = tree, treeFindings(tree)
+		fmt.Fprintf(w, "\nQuery Execution Plan:\n=====================\n\n%s\n", tree)
+	} else {
+		jsonPlan, err := p.extractJSONFromExplainOutput(text)
+		if err == nil {
+			findings, err = analyzePlanHeuristics(jsonPlan)
+		}
+		if err != nil {
+			fmt.Fprintln(w, "No JSON or TREE plan found in the pasted text")
+			return
+		}
+		plan = jsonPlan
+		if visual, err := p.visualExplain(jsonPlan); err == nil {
+			fmt.Fprint(w, "\n"+visual)
+		}
+	}
+	printPlanScore(w, findings)
+
+	if p.aiServerMode == "" && p.aiServerURL == "" {
+		return
+	}
+	client, err := p.aiBackend()
+	if err != nil {
+		fmt.Fprintf(w, "Failed to create AI client: %v\n", err)
+		return
+	}
+	ctx, stop := p.statementContext()
+	defer stop()
+	advice, err := client.ExplainPlan(ctx, "", plan, "", p.aiDetailLevel)
+	if err != nil {
+		fmt.Fprintf(w, tr("AI analysis failed: %v\n"), err)
+		return
+	}
+	fmt.Fprintln(w, "\n🤖 "+tr("AI Performance Analysis:"))
+	fmt.Fprintln(w, "==========================")
+	fmt.Fprintf(w, tr("(AI backend: %s %s)\n"), p.aiServerMode, p.aiEndpoint())
+	fmt.Fprintln(w, advice)
+}
+
+// extractTreePlan returns the EXPLAIN FORMAT=TREE (or EXPLAIN ANALYZE) plan
+// in text, which may still have the borders of a table or the EXPLAIN: label
+// of \G around it. Indentation is kept.
+func extractTreePlan(text string) (string, bool) {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t")
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "+-") || strings.HasPrefix(trimmed, "***") || strings.Contains(trimmed, " in set") {
+			continue
+		}
+		// The mysql client prints a multi-line cell between the borders of
+		// its first and last line. The spaces after the left border are the
+		// indentation.
+		if strings.HasPrefix(trimmed, "|") {
+			line = strings.TrimPrefix(strings.TrimPrefix(trimmed, "|"), " ")
+		}
+		if strings.HasSuffix(line, "|") {
+			line = strings.TrimRight(strings.TrimSuffix(line, "|"), " ")
+		}
+		trimmed = strings.TrimSpace(line)
+		if label, rest, ok := strings.Cut(line, "EXPLAIN: "); ok && strings.TrimSpace(label) == "" {
+			line, trimmed = rest, strings.TrimSpace(rest)
+		}
+		if len(lines) == 0 && !strings.HasPrefix(trimmed, "-> ") {
+			continue
+		}
+		if trimmed != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return "", false
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+// treeFindings spots in a TREE plan what analyzePlanHeuristics reports for
+// a JSON one: full table and index scans, sorts, temporary tables and, in
+// EXPLAIN ANALYZE output, row estimates that are far off
+func treeFindings(tree string) []ExplainFinding {
+	findings := []ExplainFinding{}
+	for _, line := range strings.Split(tree, "\n") {
+		// Scans of <temporary> read back a temporary table, reported below
+		if m := treeTableScan.FindStringSubmatch(line); m != nil && !strings.HasPrefix(m[1], "<") {
+			var rows float64
+			if r := treeRows.FindStringSubmatch(line); r != nil {
+				rows = planNumber(r[1])
+			}
+			severity := SeverityWarning
+			if rows >= largeScanRows {
+				severity = SeverityCritical
+			}
+			findings = append(findings, ExplainFinding{Severity: severity, Table: m[1], Message: fmt.Sprintf("full table scan (%.0f rows examined per scan)", rows)})
+		}
+		if m := treeIndexScan.FindStringSubmatch(line); m != nil {
+			findings = append(findings, ExplainFinding{Severity: SeverityInfo, Table: m[1], Message: fmt.Sprintf("full index scan on %s", m[2])})
+		}
+		trimmed := strings.TrimPrefix(strings.TrimSpace(line), "-> ")
+		if strings.HasPrefix(trimmed, "Sort") {
+			findings = append(findings, ExplainFinding{Severity: SeverityWarning, Message: "uses filesort"})
+		}
+		if strings.Contains(trimmed, "temporary table") || strings.HasPrefix(trimmed, "Temporary table") {
+			findings = append(findings, ExplainFinding{Severity: SeverityWarning, Message: "uses a temporary table"})
+		}
+	}
+	return append(findings, estimateFindings(treeEstimates(tree))...)
+}
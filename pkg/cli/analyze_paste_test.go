@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const pastedTree = `-> Nested loop inner join  (cost=2810 rows=5462)
+    -> Table scan on f  (cost=103 rows=1000)
+    -> Index lookup on i using idx_fk_film_id (film_id=f.film_id)  (cost=2.25 rows=5.46)`
+
+func TestExtractTreePlan(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"bare", pastedTree},
+		{"table", "+----------+\n| EXPLAIN  |\n+----------+\n| " + pastedTree + " |\n+----------+\n1 row in set (0.00 sec)"},
+		{"vertical", "*************************** 1. row ***************************\nEXPLAIN: " + pastedTree + "\n1 row in set (0.00 sec)"},
+	}
+	for _, test := range tests {
+		if got, ok := extractTreePlan(test.text); !ok || got != pastedTree {
+			t.Errorf("%s: got %q %v", test.name, got, ok)
+		}
+	}
+
+	if _, ok := extractTreePlan(`{"query_block": {"select_id": 1}}`); ok {
+		t.Error("a JSON plan is not a tree")
+	}
+}
+
+func TestTreeFindings(t *testing.T) {
+	tree := `-> Sort: f.title
+    -> Table scan on <temporary>
+        -> Aggregate using temporary table
+            -> Table scan on rental  (cost=1620 rows=160000)
+            -> Index scan on f using idx_title  (cost=103 rows=1000)`
+	want := []ExplainFinding{
+		{Severity: SeverityWarning, Message: "uses filesort"},
+		{Severity: SeverityWarning, Message: "uses a temporary table"},
+		{Severity: SeverityCritical, Table: "rental", Message: "full table scan (160000 rows examined per scan)"},
+		{Severity: SeverityInfo, Table: "f", Message: "full index scan on idx_title"},
+	}
+	got := treeFindings(tree)
+	if len(got) != len(want) {
+		t.Fatalf("got %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("finding %d: got %v, expected %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestReadPastedPlan(t *testing.T) {
+	p := &PromptExecutor{}
+	p.startPastedPlan()
+	p.readPastedPlan("-> Table scan on film  (cost=103 rows=1000)")
+	if !p.pastingPlan || len(p.pastedPlan) != 1 {
+		t.Fatalf("plan should still be read, got %v", p.pastedPlan)
+	}
+	p.readPastedPlan("\\c")
+	if p.pastingPlan || p.pastedPlan != nil {
+		t.Fatalf("\\c should cancel the paste")
+	}
+}
+
+func TestAnalyzePastedJSONPlan(t *testing.T) {
+	p := &PromptExecutor{}
+	var buf bytes.Buffer
+	p.analyzePastedPlan(&buf, `EXPLAIN: {
+  "query_block": {
+    "select_id": 1,
+    "table": {"table_name": "film", "access_type": "ALL", "rows_examined_per_scan": 1000}
+  }
+}`)
+	out := buf.String()
+	for _, want := range []string{"Query Execution Plan:", "film", "Plan score: 30/100 (warn)", "[warning] film: full table scan"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+
+	// The mysql client's table, with borders on the first and last line only
+	buf.Reset()
+	p.analyzePastedPlan(&buf, `+---------+
+| EXPLAIN |
++---------+
+| {
+  "query_block": {
+    "table": {"table_name": "film", "access_type": "ref", "key": "idx_title", "rows_examined_per_scan": 1}
+  }
+} |
++---------+`)
+	if !strings.Contains(buf.String(), "Plan score: 0/100 (ok)") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	buf.Reset()
+	p.analyzePastedPlan(&buf, "nothing to see here")
+	if !strings.Contains(buf.String(), "No JSON or TREE plan") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
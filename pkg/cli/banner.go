@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// printConnectionBanner prints what the server is and how it is reached:
+// flavor and version, TLS cipher, read_only and notable sql_mode settings.
+// Anything the server or a proxy in between refuses to report is left out.
+func printConnectionBanner(db *sql.DB, config *MySQLConfig) {
+	info, err := queryServerInfo(db)
+	if err != nil {
+		fmt.Printf("Failed to get server version: %v\n", err)
+		return
+	}
+	fmt.Printf("Server: %s (%s", info, info.Version)
+	if info.Comment != "" {
+		fmt.Printf(", %s", info.Comment)
+	}
+	fmt.Println(")")
+
+	version, cipher, ok := sessionTLS(db)
+	switch {
+	case !ok:
+	case cipher != "":
+		fmt.Printf("SSL: %s, cipher %s\n", version, cipher)
+	case config.Socket == "" && !isLoopbackHost(config.Host):
+		bannerWarning("connection to %s is not encrypted", config.Host)
+	default:
+		fmt.Println("SSL: not in use")
+	}
+
+	if readOnly, super := readOnlyStatus(db); super {
+		bannerWarning("server is super_read_only")
+	} else if readOnly {
+		bannerWarning("server is read_only")
+	}
+
+	var mode sql.NullString
+	if db.QueryRow("SELECT @@SESSION.sql_mode").Scan(&mode) == nil {
+		for _, note := range sqlModeNotes(mode.String) {
+			bannerWarning("%s", note)
+		}
+	}
+}
+
+// bannerWarning prints a "Warning: " line, in yellow when colors are on
+func bannerWarning(format string, args ...any) {
+	msg := "Warning: " + fmt.Sprintf(format, args...)
+	if colorEnabled() {
+		msg = ansiNegative + msg + ansiReset
+	}
+	fmt.Println(msg)
+}
+
+// sessionTLS returns the TLS version and cipher of the session, empty when
+// the connection is plaintext. ok is false if the status could not be read.
+func sessionTLS(db *sql.DB) (version, cipher string, ok bool) {
+	rows, err := db.Query("SHOW SESSION STATUS WHERE Variable_name IN ('Ssl_version', 'Ssl_cipher')")
+	if err != nil {
+		return "", "", false
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, value string
+		if rows.Scan(&name, &value) != nil {
+			return "", "", false
+		}
+		switch strings.ToLower(name) {
+		case "ssl_version":
+			version = value
+		case "ssl_cipher":
+			cipher = value
+		}
+	}
+	return version, cipher, rows.Err() == nil
+}
+
+// readOnlyStatus reports @@read_only and @@super_read_only. Servers without
+// super_read_only (MariaDB, TiDB) report only read_only.
+func readOnlyStatus(db *sql.DB) (readOnly, super bool) {
+	var value sql.NullString
+	if db.QueryRow("SELECT @@GLOBAL.read_only").Scan(&value) == nil {
+		readOnly = isOn(value.String)
+	}
+	if db.QueryRow("SELECT @@GLOBAL.super_read_only").Scan(&value) == nil {
+		super = isOn(value.String)
+	}
+	return readOnly, super
+}
+
+func isOn(value string) bool {
+	switch strings.ToUpper(value) {
+	case "1", "ON":
+		return true
+	}
+	return false
+}
+
+// isLoopbackHost reports whether host is this machine, where plaintext TCP
+// does not leave the host
+func isLoopbackHost(host string) bool {
+	switch strings.ToLower(host) {
+	case "", "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return strings.HasPrefix(host, "127.")
+}
+
+// sqlModeNotes returns the sql_mode settings worth knowing about before
+// typing a statement: no strict mode, and modes that change how strings and
+// quotes are read
+func sqlModeNotes(mode string) []string {
+	modes := make(map[string]bool)
+	for _, m := range strings.Split(strings.ToUpper(mode), ",") {
+		modes[strings.TrimSpace(m)] = true
+	}
+	var notes []string
+	if !modes["STRICT_TRANS_TABLES"] && !modes["STRICT_ALL_TABLES"] && !modes["TRADITIONAL"] {
+		notes = append(notes, "sql_mode is not strict; invalid values are adjusted with a warning instead of rejected")
+	}
+	if modes["ANSI_QUOTES"] || modes["ANSI"] {
+		notes = append(notes, "sql_mode has ANSI_QUOTES; double quotes delimit identifiers, not strings")
+	}
+	if modes["NO_BACKSLASH_ESCAPES"] {
+		notes = append(notes, "sql_mode has NO_BACKSLASH_ESCAPES; backslash is an ordinary character in strings")
+	}
+	if modes["PIPES_AS_CONCAT"] && !modes["ANSI"] {
+		notes = append(notes, "sql_mode has PIPES_AS_CONCAT; || concatenates strings")
+	}
+	return notes
+}
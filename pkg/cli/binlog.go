@@ -0,0 +1,349 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultBinlogLimit = 50
+	// binlogBatch is how many events one SHOW BINLOG EVENTS reads, and
+	// binlogScanLimit how many \binlog reads at most before it stops to let
+	// you continue or narrow the filters
+	binlogBatch     = 1000
+	binlogScanLimit = 200000
+)
+
+var (
+	// binlogTableMap matches the Info of a Table_map event: the table id and
+	// the table it stands for in the row events that follow
+	binlogTableMap = regexp.MustCompile("^table_id: (\\d+) \\(([^.]+)\\.(.+)\\)$")
+	binlogTableID  = regexp.MustCompile("^table_id: (\\d+)")
+	// binlogUse matches the Info of a Query event run with a default database
+	binlogUse = regexp.MustCompile("(?s)^use `((?:[^`]|``)+)`; (.*)$")
+	// binlogDDL finds statements that change the schema
+	binlogDDL = regexp.MustCompile(`(?i)^\s*(?:CREATE|ALTER|DROP|RENAME|TRUNCATE)\b`)
+)
+
+// binlogHidden are the event types left out unless --type asks for them:
+// bookkeeping that says nothing about what changed
+var binlogHidden = map[string]bool{
+	"Table_map":      true,
+	"Rows_query":     true,
+	"Format_desc":    true,
+	"Previous_gtids": true,
+	"Gtid":           true,
+	"Anonymous_Gtid": true,
+	"Xid":            true,
+	"Stop":           true,
+}
+
+// binlogOptions are the arguments of \binlog
+type binlogOptions struct {
+	File   string
+	From   uint64
+	Schema string
+	Table  string
+	Type   string
+	Limit  int
+	List   bool // --list: show the binary logs instead of events
+}
+
+// parseBinlogArgs reads [file] [--from pos] [--schema s] [--table t]
+// [--type t] [--limit n] [--list]; --table accepts schema.table
+func parseBinlogArgs(args string) (binlogOptions, error) {
+	opts := binlogOptions{Limit: defaultBinlogLimit}
+	fields := strings.Fields(args)
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		if !strings.HasPrefix(field, "--") {
+			if opts.File != "" {
+				return opts, fmt.Errorf("more than one file given: %s and %s", opts.File, field)
+			}
+			opts.File = field
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(field, "--"), "=")
+		switch name {
+		case "list":
+			opts.List = true
+			continue
+		case "since":
+			return opts, fmt.Errorf("SHOW BINLOG EVENTS does not return event times, so --since cannot pick events; " +
+				"use \\binlog --list to choose a file, or mysqlbinlog --start-datetime")
+		case "from", "schema", "table", "type", "limit":
+		default:
+			return opts, fmt.Errorf("unknown option --%s", name)
+		}
+		if !hasValue {
+			if i+1 == len(fields) {
+				return opts, fmt.Errorf("--%s needs a value", name)
+			}
+			i++
+			value = fields[i]
+		}
+		switch name {
+		case "from":
+			pos, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return opts, fmt.Errorf("--from must be a position, got %q", value)
+			}
+			opts.From = pos
+		case "schema":
+			opts.Schema = strings.Trim(value, "`")
+		case "table":
+			if schema, table := splitTableName(value, ""); schema != "" {
+				opts.Schema, opts.Table = schema, table
+			} else {
+				opts.Table = table
+			}
+		case "type":
+			opts.Type = strings.ToLower(value)
+		case "limit":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return opts, fmt.Errorf("--limit must be a positive number, got %q", value)
+			}
+			opts.Limit = n
+		}
+	}
+	return opts, nil
+}
+
+// binlogEvent is one row of SHOW BINLOG EVENTS
+type binlogEvent struct {
+	Pos    uint64
+	Type   string
+	EndPos uint64
+	Info   string
+}
+
+// binlogEntry is an event as \binlog shows it
+type binlogEntry struct {
+	Pos     uint64
+	Type    string // Event_type, with the _v1 and _v2 suffixes removed
+	Action  string // INSERT, UPDATE or DELETE for row events, the statement's verb for queries
+	Schema  string
+	Table   string
+	Summary string
+}
+
+// binlogDecoder follows the events of a binary log in order: row events only
+// name a table id, which the Table_map event before them resolves, and the
+// statement behind them is in a Rows_query event when the server logs it
+// (binlog_rows_query_log_events)
+type binlogDecoder struct {
+	tables    map[string][2]string // table id to schema and table
+	statement string               // the last Rows_query statement
+}
+
+func newBinlogDecoder() *binlogDecoder {
+	return &binlogDecoder{tables: make(map[string][2]string)}
+}
+
+// decode describes an event, keeping what later events need to know
+func (d *binlogDecoder) decode(ev binlogEvent) binlogEntry {
+	entry := binlogEntry{Pos: ev.Pos, Type: binlogType(ev.Type), Summary: ev.Info}
+	switch entry.Type {
+	case "Table_map":
+		if m := binlogTableMap.FindStringSubmatch(ev.Info); m != nil {
+			d.tables[m[1]] = [2]string{m[2], m[3]}
+			entry.Schema, entry.Table = m[2], m[3]
+		}
+	case "Rows_query":
+		d.statement = strings.TrimPrefix(ev.Info, "# ")
+	case "Write_rows", "Update_rows", "Delete_rows":
+		entry.Action = map[string]string{"Write_rows": "INSERT", "Update_rows": "UPDATE", "Delete_rows": "DELETE"}[entry.Type]
+		entry.Summary = "row images not shown"
+		if m := binlogTableID.FindStringSubmatch(ev.Info); m != nil {
+			if table, ok := d.tables[m[1]]; ok {
+				entry.Schema, entry.Table = table[0], table[1]
+			} else {
+				entry.Summary = "table_id " + m[1] + " (its Table_map is before the start position)"
+			}
+		}
+		if d.statement != "" {
+			entry.Summary = d.statement
+		}
+	case "Query":
+		statement := ev.Info
+		if m := binlogUse.FindStringSubmatch(ev.Info); m != nil {
+			entry.Schema, statement = strings.ReplaceAll(m[1], "``", "`"), m[2]
+		}
+		entry.Summary = statement
+		if fields := strings.Fields(statement); len(fields) > 0 {
+			entry.Action = strings.ToUpper(fields[0])
+		}
+	case "Xid":
+		// The transaction is over, and with it the statement of its rows
+		d.statement = ""
+	}
+	return entry
+}
+
+// binlogType strips the version from an event type: Write_rows_v1 and
+// Write_rows are the same thing to a reader
+func binlogType(t string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(t, "_v1"), "_v2")
+}
+
+// matches reports whether an entry passes the filters of opts
+func (opts binlogOptions) matches(e binlogEntry) bool {
+	switch opts.Type {
+	case "":
+		if binlogHidden[e.Type] || (e.Type == "Query" && (e.Action == "BEGIN" || e.Action == "COMMIT")) {
+			return false
+		}
+	case "insert", "update", "delete":
+		if e.Action != strings.ToUpper(opts.Type) || e.Type == "Query" {
+			return false
+		}
+	case "ddl":
+		if e.Type != "Query" || !binlogDDL.MatchString(e.Summary) {
+			return false
+		}
+	default:
+		if !strings.EqualFold(e.Type, opts.Type) {
+			return false
+		}
+	}
+	if opts.Schema != "" && !strings.EqualFold(e.Schema, opts.Schema) {
+		return false
+	}
+	if opts.Table == "" {
+		return true
+	}
+	if e.Table != "" {
+		return strings.EqualFold(e.Table, opts.Table)
+	}
+	// A statement names its tables in its text
+	return e.Type == "Query" && mentionsName(e.Summary, opts.Table)
+}
+
+// mentionsName reports whether text contains name as a whole identifier,
+// ignoring case
+func mentionsName(text, name string) bool {
+	lower, name := strings.ToLower(text), strings.ToLower(name)
+	isIdent := func(c byte) bool {
+		return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 0x80
+	}
+	for i := 0; ; {
+		j := strings.Index(lower[i:], name)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(name)
+		if (start == 0 || !isIdent(lower[start-1])) && (end == len(lower) || !isIdent(lower[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+// showBinlog implements \binlog: a page of the events of a binary log,
+// filtered by schema, table and event type, read with SHOW BINLOG EVENTS
+// so that no access to the server's files or mysqlbinlog is needed
+func (p *PromptExecutor) showBinlog(args string) {
+	opts, err := parseBinlogArgs(args)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	ctx, stop := p.statementContext()
+	defer stop()
+
+	_, logs, err := p.queryStringsContext(ctx, "SHOW BINARY LOGS")
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return
+	}
+	if len(logs) == 0 {
+		fmt.Println("Binary logging is off")
+		return
+	}
+	if opts.List {
+		rows := make([][]string, len(logs))
+		for i, log := range logs {
+			rows[i] = []string{log[0], log[1]}
+		}
+		fmt.Print(formatMySQLTable([]string{"Log_name", "File_size"}, rows))
+		return
+	}
+	if opts.File == "" {
+		// SHOW BINLOG EVENTS reads the oldest log; the newest is what changed last
+		opts.File = logs[len(logs)-1][0]
+	}
+
+	decoder := newBinlogDecoder()
+	var entries []binlogEntry
+	pos, scanned, done := opts.From, 0, false
+	for !done && len(entries) < opts.Limit && scanned < binlogScanLimit {
+		query := fmt.Sprintf("SHOW BINLOG EVENTS IN %s", sqlLiteral(&opts.File))
+		if pos > 0 {
+			query += fmt.Sprintf(" FROM %d", pos)
+		}
+		_, rows, err := p.queryStringsContext(ctx, query+fmt.Sprintf(" LIMIT %d", binlogBatch))
+		if err != nil {
+			fmt.Printf(tr("Error: %v\n"), canceled(ctx, err))
+			return
+		}
+		done = len(rows) < binlogBatch
+		for i, row := range rows {
+			ev := binlogEvent{Type: row[2], Info: row[5]}
+			ev.Pos, _ = strconv.ParseUint(row[1], 10, 64)
+			ev.EndPos, _ = strconv.ParseUint(row[4], 10, 64)
+			scanned++
+			pos = ev.EndPos
+			if entry := decoder.decode(ev); opts.matches(entry) {
+				entries = append(entries, entry)
+				if len(entries) == opts.Limit {
+					// The next page reads the rest of the batch again from pos
+					done = done && i == len(rows)-1
+					break
+				}
+			}
+		}
+	}
+
+	fmt.Printf("%s: %d event%s shown, %d read\n", opts.File, len(entries), plural(len(entries)), scanned)
+	if len(entries) > 0 {
+		rows := make([][]string, len(entries))
+		for i, e := range entries {
+			table := e.Table
+			if e.Schema != "" && e.Table != "" {
+				table = e.Schema + "." + e.Table
+			} else if e.Schema != "" {
+				table = e.Schema
+			}
+			what := e.Type
+			if e.Action != "" {
+				what = e.Action
+			}
+			rows[i] = []string{strconv.FormatUint(e.Pos, 10), what, table, truncateQuery(e.Summary, 80)}
+		}
+		fmt.Print(formatMySQLTable([]string{"Pos", "Event", "Table", "Statement"}, rows))
+	}
+	if !done {
+		fmt.Printf("More events follow: \\binlog %s --from %d%s\n", opts.File, pos, opts.filterArgs())
+	}
+}
+
+// filterArgs repeats the filters of opts for the next page
+func (opts binlogOptions) filterArgs() string {
+	var b strings.Builder
+	if opts.Schema != "" {
+		fmt.Fprintf(&b, " --schema %s", opts.Schema)
+	}
+	if opts.Table != "" {
+		fmt.Fprintf(&b, " --table %s", opts.Table)
+	}
+	if opts.Type != "" {
+		fmt.Fprintf(&b, " --type %s", opts.Type)
+	}
+	if opts.Limit != defaultBinlogLimit {
+		fmt.Fprintf(&b, " --limit %d", opts.Limit)
+	}
+	return b.String()
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBinlogArgs(t *testing.T) {
+	opts, err := parseBinlogArgs(" binlog.000042 --from 4 --table shop.orders --type=DELETE --limit 10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := binlogOptions{File: "binlog.000042", From: 4, Schema: "shop", Table: "orders", Type: "delete", Limit: 10}
+	if opts != want {
+		t.Errorf("got %+v", opts)
+	}
+	if got := opts.filterArgs(); got != " --schema shop --table orders --type delete --limit 10" {
+		t.Errorf("filter args: %q", got)
+	}
+
+	for _, args := range []string{"a b", "--from x", "--limit 0", "--table", "--since 2024-01-01", "--verbose"} {
+		if _, err := parseBinlogArgs(args); err == nil {
+			t.Errorf("%q: expected an error", args)
+		}
+	}
+}
+
+// sampleBinlog is part of a binary log with binlog_rows_query_log_events on
+var sampleBinlog = []binlogEvent{
+	{Pos: 4, Type: "Format_desc", Info: "Server ver: 8.0.36, Binlog ver: 4"},
+	{Pos: 126, Type: "Query", Info: "use `shop`; CREATE TABLE orders (id INT PRIMARY KEY)"},
+	{Pos: 300, Type: "Query", Info: "BEGIN"},
+	{Pos: 380, Type: "Rows_query", Info: "# DELETE FROM orders WHERE id = 1"},
+	{Pos: 440, Type: "Table_map", Info: "table_id: 92 (shop.orders)"},
+	{Pos: 500, Type: "Delete_rows", Info: "table_id: 92 flags: STMT_END_F"},
+	{Pos: 560, Type: "Xid", Info: "COMMIT /* xid=17 */"},
+	{Pos: 600, Type: "Table_map", Info: "table_id: 93 (shop.order_items)"},
+	{Pos: 660, Type: "Write_rows_v1", Info: "table_id: 93 flags: STMT_END_F"},
+	{Pos: 720, Type: "Update_rows", Info: "table_id: 99 flags: STMT_END_F"},
+}
+
+func filterBinlog(t *testing.T, args string) []binlogEntry {
+	t.Helper()
+	opts, err := parseBinlogArgs(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := newBinlogDecoder()
+	var entries []binlogEntry
+	for _, ev := range sampleBinlog {
+		if e := d.decode(ev); opts.matches(e) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+func TestBinlogDecoder(t *testing.T) {
+	all := filterBinlog(t, "")
+	var got []string
+	for _, e := range all {
+		got = append(got, e.Action+" "+e.Schema+"."+e.Table+": "+e.Summary)
+	}
+	want := []string{
+		"CREATE shop.: CREATE TABLE orders (id INT PRIMARY KEY)",
+		"DELETE shop.orders: DELETE FROM orders WHERE id = 1",
+		"INSERT shop.order_items: row images not shown",
+		"UPDATE .: table_id 99 (its Table_map is before the start position)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s", strings.Join(got, "\n"))
+	}
+
+	if orders := filterBinlog(t, "--table orders"); len(orders) != 2 || orders[0].Pos != 126 || orders[1].Pos != 500 {
+		t.Errorf("orders: %+v", orders)
+	}
+	if ddl := filterBinlog(t, "--schema shop --type ddl"); len(ddl) != 1 || ddl[0].Pos != 126 {
+		t.Errorf("ddl: %+v", ddl)
+	}
+	if maps := filterBinlog(t, "--type table_map"); len(maps) != 2 {
+		t.Errorf("table maps: %+v", maps)
+	}
+	if inserts := filterBinlog(t, "--type insert"); len(inserts) != 1 || inserts[0].Type != "Write_rows" {
+		t.Errorf("inserts: %+v", inserts)
+	}
+}
+
+func TestMentionsName(t *testing.T) {
+	if !mentionsName("ALTER TABLE `Orders` ADD x INT", "orders") || mentionsName("DROP TABLE orders_old", "orders") {
+		t.Error("expected a whole-word match")
+	}
+}
@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/ini.v1"
+)
+
+// broadcastServers are the profiles given with --servers; broadcastTargets
+// are their connections once Start has opened them
+var (
+	broadcastServers []string
+	broadcastTargets []broadcastTarget
+)
+
+// SetBroadcastServers sets the comma-separated profiles every statement is
+// run on (--servers)
+func SetBroadcastServers(list string) {
+	broadcastServers = nil
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			broadcastServers = append(broadcastServers, name)
+		}
+	}
+}
+
+// broadcastTarget is one server of a broadcast session
+type broadcastTarget struct {
+	Name string
+	DB   *sql.DB
+}
+
+// readServerProfile reads the option file group of a --servers entry on its
+// own, so its settings win over [client]. An entry without a group, or a
+// group without a host, names the host itself.
+func readServerProfile(name, configFile string) *MySQLConfig {
+	profile := &MySQLConfig{}
+	for _, file := range optionFiles(configFile) {
+		cfg, err := ini.Load(file)
+		if err != nil {
+			continue
+		}
+		section, err := cfg.GetSection(name)
+		if err != nil {
+			continue
+		}
+		for key, value := range map[string]*string{
+			"host":     &profile.Host,
+			"user":     &profile.User,
+			"password": &profile.Password,
+			"socket":   &profile.Socket,
+			"database": &profile.Database,
+		} {
+			if v := StripMatchingQuotes(section.Key(key).String()); v != "" {
+				*value = v
+			}
+		}
+		if port, err := section.Key("port").Int(); err == nil {
+			profile.Port = port
+		}
+	}
+	if profile.Host == "" && profile.Socket == "" {
+		profile.Host = name
+	}
+	return profile
+}
+
+// orDefault returns flag unless it is empty
+func orDefault(flag, fallback string) string {
+	if flag != "" {
+		return flag
+	}
+	return fallback
+}
+
+// connectServers connects to every --servers profile. Flags apply to all of
+// them; the first server is the one metadata and completion come from.
+func connectServers(port int, user, password, database, socket, configFile string, zstdCompressionLevel int) ([]broadcastTarget, *MySQLConfig, error) {
+	var targets []broadcastTarget
+	var primary *MySQLConfig
+	for _, name := range broadcastServers {
+		profile := readServerProfile(name, configFile)
+		profilePort := profile.Port
+		if port != 0 {
+			profilePort = port
+		}
+		db, merged, _, err := connect(profile.Host, profilePort, orDefault(user, profile.User), orDefault(password, profile.Password),
+			orDefault(database, profile.Database), orDefault(socket, profile.Socket), name, configFile, zstdCompressionLevel)
+		if err != nil {
+			for _, t := range targets {
+				t.DB.Close()
+			}
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if primary == nil {
+			primary = merged
+		}
+		targets = append(targets, broadcastTarget{Name: name, DB: db})
+		server := "unknown server"
+		if info, err := queryServerInfo(db); err == nil {
+			server = info.String()
+		}
+		fmt.Printf("Connected to %s (%s@%s:%d): %s\n", name, merged.User, merged.Host, merged.Port, server)
+	}
+	fmt.Printf("Broadcast mode: every statement runs on %d servers\n", len(targets))
+	return targets, primary, nil
+}
+
+// executeBroadcast runs a statement on every server at once. Result sets with
+// the same columns are merged into one table with a server column; anything
+// else is shown per server.
+func (p *PromptExecutor) executeBroadcast(ctx context.Context, stmt string, vertical bool) {
+	results := make([]*Result, len(p.broadcast))
+	errs := make([]error, len(p.broadcast))
+	var wg sync.WaitGroup
+	for i, t := range p.broadcast {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = runStatementOn(ctx, t.DB, stmt)
+			errs[i] = canceled(ctx, errs[i])
+		}()
+	}
+	wg.Wait()
+
+	names := make([]string, len(p.broadcast))
+	for i, t := range p.broadcast {
+		names[i] = t.Name
+	}
+	fmt.Print(p.formatBroadcast(names, results, errs, vertical))
+}
+
+// formatBroadcast renders the results of one statement on several servers,
+// errs[i] being the error of the statement on server i
+func (p *PromptExecutor) formatBroadcast(names []string, results []*Result, errs []error, vertical bool) string {
+	if !vertical && mergeable(results, errs) {
+		merged := &Result{
+			Statement: results[0].Statement,
+			Columns:   append([]string{"server"}, results[0].Columns...),
+			Types:     append([]string{"VARCHAR"}, results[0].Types...),
+		}
+		for i, r := range results {
+			for j, row := range r.Rows {
+				merged.Rows = append(merged.Rows, append([]string{names[i]}, row...))
+				merged.Values = append(merged.Values, append([]any{names[i]}, r.Values[j]...))
+			}
+			merged.Duration = max(merged.Duration, r.Duration)
+		}
+		if !isExplainQuery(merged.Statement) {
+			p.formatResult(merged.Types, merged.Rows)
+		}
+		output, _ := p.renderResult(merged, false)
+		return output + fmt.Sprintf("\n%d row%s from %d servers (slowest %.3fs)\n",
+			len(merged.Rows), plural(len(merged.Rows)), len(results), merged.Duration.Seconds())
+	}
+
+	var b strings.Builder
+	for i, r := range results {
+		fmt.Fprintf(&b, "[%s]\n", names[i])
+		if errs[i] != nil {
+			fmt.Fprintf(&b, tr("Error: %v\n"), errs[i])
+			continue
+		}
+		b.WriteString(p.resultOutput(r, vertical))
+	}
+	return b.String()
+}
+
+// mergeable reports whether every server returned a result set with the same
+// columns
+func mergeable(results []*Result, errs []error) bool {
+	for i, r := range results {
+		if errs[i] != nil || r.Columns == nil || !slices.Equal(r.Columns, results[0].Columns) {
+			return false
+		}
+	}
+	return len(results) > 0
+}
+
+// closeBroadcast closes the connections to the other servers; the first one
+// is the session's own connection
+func (p *PromptExecutor) closeBroadcast() {
+	for _, t := range p.broadcast[min(1, len(p.broadcast)):] {
+		t.DB.Close()
+	}
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSetBroadcastServers(t *testing.T) {
+	defer SetBroadcastServers("")
+	SetBroadcastServers(" prod-1, prod-2,,prod-3 ")
+	if want := []string{"prod-1", "prod-2", "prod-3"}; !reflect.DeepEqual(broadcastServers, want) {
+		t.Errorf("broadcastServers = %v, want %v", broadcastServers, want)
+	}
+}
+
+func TestReadServerProfile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cnf := filepath.Join(t.TempDir(), "my.cnf")
+	content := "[client]\nuser = app\nhost = default-host\n\n[prod-1]\nhost = 10.0.0.11\nuser = ops\n\n[prod-2]\nport = 3307\n"
+	if err := os.WriteFile(cnf, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		want MySQLConfig
+	}{
+		{"prod-1", MySQLConfig{Host: "10.0.0.11", User: "ops"}},
+		{"prod-2", MySQLConfig{Host: "prod-2", Port: 3307}},
+		{"db3.example.com", MySQLConfig{Host: "db3.example.com"}},
+	}
+	for _, tt := range tests {
+		if got := readServerProfile(tt.name, cnf); !reflect.DeepEqual(*got, tt.want) {
+			t.Errorf("readServerProfile(%q) = %+v, want %+v", tt.name, *got, tt.want)
+		}
+	}
+}
+
+func TestFormatBroadcast(t *testing.T) {
+	names := []string{"prod-1", "prod-2"}
+
+	p := &PromptExecutor{}
+	lag := func(v int64) *Result {
+		return &Result{Columns: []string{"lag"}, Types: []string{"BIGINT"}, Rows: [][]string{{strconv.FormatInt(v, 10)}}, Values: [][]any{{v}}}
+	}
+	merged := p.formatBroadcast(names, []*Result{lag(0), lag(12)}, []error{nil, nil}, false)
+	for _, want := range []string{"| server | lag |", "| prod-1 | 0   |", "| prod-2 | 12  |", "2 rows from 2 servers"} {
+		if !strings.Contains(merged, want) {
+			t.Errorf("merged output lacks %q:\n%s", want, merged)
+		}
+	}
+
+	labeled := p.formatBroadcast(names, []*Result{lag(0), nil}, []error{nil, errors.New("connection refused")}, false)
+	for _, want := range []string{"[prod-1]\n", "[prod-2]\nError: connection refused"} {
+		if !strings.Contains(labeled, want) {
+			t.Errorf("labeled output lacks %q:\n%s", want, labeled)
+		}
+	}
+
+	// The \output format applies, with the values keeping their types
+	p.outputFormat = "json"
+	if got := p.formatBroadcast(names, []*Result{lag(0), lag(12)}, []error{nil, nil}, false); !strings.Contains(got, `{"server": "prod-2", "lag": 12}`) {
+		t.Errorf("json output:\n%s", got)
+	}
+}
@@ -0,0 +1,387 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Browser levels, from the outermost
+const (
+	browseDatabases = iota
+	browseTables
+	browseColumns
+)
+
+// browseSampleRows is the number of rows shown in a table preview
+const browseSampleRows = 5
+
+var (
+	browseTitleStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "#0550AE", Dark: "#66D9EF"})
+	browseSelectedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "#116329", Dark: "#A6E22E"})
+	browseHelpStyle     = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#6E7781", Dark: "#75715E"})
+	browsePreviewStyle  = lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).BorderLeft(true).PaddingLeft(1)
+)
+
+// browseModel is the bubbletea model behind \browse
+type browseModel struct {
+	p        *PromptExecutor
+	level    int
+	database string
+	table    string
+	items    []string
+	filtered []string
+	cursor   int
+	filter   string
+	previews map[string]string
+	width    int
+	height   int
+	err      error
+
+	// selected is the identifier to insert into the prompt, set on Enter
+	selected string
+}
+
+func newBrowseModel(p *PromptExecutor) *browseModel {
+	m := &browseModel{p: p, previews: make(map[string]string), width: 100, height: 30}
+	if p.database != "" {
+		m.level = browseTables
+		m.database = p.database
+	}
+	m.load()
+	return m
+}
+
+// load fetches the items for the current level and resets the filter
+func (m *browseModel) load() {
+	var rows [][]string
+	var err error
+	switch m.level {
+	case browseDatabases:
+		_, rows, err = m.p.queryStrings("SELECT SCHEMA_NAME FROM INFORMATION_SCHEMA.SCHEMATA ORDER BY SCHEMA_NAME")
+	case browseTables:
+		_, rows, err = m.p.queryStrings("SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ? ORDER BY TABLE_NAME", m.database)
+	case browseColumns:
+		_, rows, err = m.p.queryStrings("SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION", m.database, m.table)
+	}
+	m.err = err
+	m.items = m.items[:0]
+	for _, row := range rows {
+		m.items = append(m.items, row[0])
+	}
+	m.filter = ""
+	m.applyFilter()
+}
+
+// applyFilter narrows the items with the same fuzzy ranking as completion
+func (m *browseModel) applyFilter() {
+	suggestions := make([]prompt.Suggest, len(m.items))
+	for i, item := range m.items {
+		suggestions[i] = prompt.Suggest{Text: item}
+	}
+	m.filtered = m.filtered[:0]
+	for _, s := range m.p.findMatches(m.filter, suggestions) {
+		m.filtered = append(m.filtered, s.Text)
+	}
+	m.cursor = 0
+}
+
+func (m *browseModel) current() string {
+	if m.cursor < len(m.filtered) {
+		return m.filtered[m.cursor]
+	}
+	return ""
+}
+
+func (m *browseModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *browseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			return m, tea.Quit
+		case tea.KeyEnter:
+			m.selected = m.identifier()
+			return m, tea.Quit
+		case tea.KeyUp, tea.KeyCtrlP:
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case tea.KeyDown, tea.KeyCtrlN:
+			if m.cursor < len(m.filtered)-1 {
+				m.cursor++
+			}
+		case tea.KeyRight, tea.KeyTab:
+			m.descend()
+		case tea.KeyLeft, tea.KeyShiftTab:
+			m.ascend()
+		case tea.KeyBackspace:
+			if m.filter == "" {
+				m.ascend()
+			} else {
+				m.filter = m.filter[:len(m.filter)-1]
+				m.applyFilter()
+			}
+		case tea.KeyRunes, tea.KeySpace:
+			m.filter += string(msg.Runes)
+			m.applyFilter()
+		}
+	}
+	return m, nil
+}
+
+// descend opens the selected database or table
+func (m *browseModel) descend() {
+	item := m.current()
+	if item == "" {
+		return
+	}
+	switch m.level {
+	case browseDatabases:
+		m.level, m.database = browseTables, item
+	case browseTables:
+		m.level, m.table = browseColumns, item
+	default:
+		return
+	}
+	m.load()
+}
+
+// ascend returns to the enclosing level, keeping the cursor on where we came from
+func (m *browseModel) ascend() {
+	var from string
+	switch m.level {
+	case browseTables:
+		m.level, from = browseDatabases, m.database
+	case browseColumns:
+		m.level, from = browseTables, m.table
+	default:
+		return
+	}
+	m.load()
+	for i, item := range m.filtered {
+		if item == from {
+			m.cursor = i
+		}
+	}
+}
+
+// identifier returns the selected name as it should be typed into a query
+func (m *browseModel) identifier() string {
+	item := m.current()
+	if item == "" {
+		return ""
+	}
+	if m.level == browseTables && m.database != m.p.database {
+		return quoteIdentifier(m.database) + "." + quoteIdentifier(item)
+	}
+	return quoteIdentifier(item)
+}
+
+// preview describes the selected item, caching the result
+func (m *browseModel) preview() string {
+	item := m.current()
+	if item == "" {
+		return ""
+	}
+	key := fmt.Sprintf("%d/%s/%s/%s", m.level, m.database, m.table, item)
+	if text, ok := m.previews[key]; ok {
+		return text
+	}
+
+	var text string
+	switch m.level {
+	case browseDatabases:
+		_, rows, err := m.p.queryStrings("SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ? ORDER BY TABLE_NAME", item)
+		if err != nil {
+			text = err.Error()
+			break
+		}
+		names := make([]string, len(rows))
+		for i, row := range rows {
+			names[i] = row[0]
+		}
+		text = fmt.Sprintf("%d table%s\n\n%s", len(names), plural(len(names)), strings.Join(names, "\n"))
+	case browseTables:
+		text = m.tablePreview(item)
+	case browseColumns:
+		cols, rows, err := m.p.queryStrings(`
+			SELECT COLUMN_TYPE AS Type, IS_NULLABLE AS `+"`Null`"+`, COALESCE(COLUMN_DEFAULT, 'NULL') AS `+"`Default`"+`,
+			       COLUMN_KEY AS `+"`Key`"+`, EXTRA AS Extra, COLUMN_COMMENT AS Comment
+			FROM INFORMATION_SCHEMA.COLUMNS
+			WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND COLUMN_NAME = ?`, m.database, m.table, item)
+		if err != nil {
+			text = err.Error()
+			break
+		}
+		text = formatVerticalTable(cols, rows)
+	}
+	m.previews[key] = text
+	return text
+}
+
+// tablePreview shows the DDL and a few sample rows of a table
+func (m *browseModel) tablePreview(table string) string {
+	name := quoteIdentifier(m.database) + "." + quoteIdentifier(table)
+
+	var b strings.Builder
+	var tableName, ddl string
+	if err := m.p.db.QueryRow("SHOW CREATE TABLE "+name).Scan(&tableName, &ddl); err != nil {
+		b.WriteString(err.Error())
+	} else {
+		b.WriteString(ddl)
+	}
+
+	cols, rows, err := m.p.queryStrings(fmt.Sprintf("SELECT * FROM %s LIMIT %d", name, browseSampleRows))
+	if err == nil && len(rows) > 0 {
+		b.WriteString("\n\nSample rows:\n")
+		b.WriteString(formatMySQLTable(cols, rows))
+	}
+	return b.String()
+}
+
+func (m *browseModel) View() string {
+	listWidth := m.width / 3
+	if listWidth < 20 {
+		listWidth = 20
+	}
+	bodyHeight := m.height - 4
+	if bodyHeight < 5 {
+		bodyHeight = 5
+	}
+
+	var title string
+	switch m.level {
+	case browseDatabases:
+		title = "Databases"
+	case browseTables:
+		title = "Tables in " + m.database
+	case browseColumns:
+		title = "Columns in " + m.database + "." + m.table
+	}
+
+	var list strings.Builder
+	if m.err != nil {
+		list.WriteString(m.err.Error())
+	}
+	// Scroll so the cursor stays visible
+	start := 0
+	if m.cursor >= bodyHeight {
+		start = m.cursor - bodyHeight + 1
+	}
+	for i := start; i < len(m.filtered) && i < start+bodyHeight; i++ {
+		line := truncateDisplay(m.filtered[i], listWidth-2)
+		if i == m.cursor {
+			list.WriteString(browseSelectedStyle.Render("> " + line))
+		} else {
+			list.WriteString("  " + line)
+		}
+		list.WriteString("\n")
+	}
+
+	preview := m.preview()
+	previewLines := strings.Split(preview, "\n")
+	if len(previewLines) > bodyHeight {
+		previewLines = previewLines[:bodyHeight]
+	}
+	for i, line := range previewLines {
+		previewLines[i] = truncateDisplay(line, m.width-listWidth-3)
+	}
+
+	body := lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().Width(listWidth).Render(list.String()),
+		browsePreviewStyle.Render(strings.Join(previewLines, "\n")),
+	)
+
+	return browseTitleStyle.Render(title) + "  filter: " + m.filter + "\n\n" + body + "\n" +
+		browseHelpStyle.Render("type to filter • ↑/↓ move • →/tab open • ←/backspace back • enter insert • esc quit")
+}
+
+// truncateDisplay shortens s to at most width runes
+func truncateDisplay(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	if width == 1 {
+		return "…"
+	}
+	return string(r[:width-1]) + "…"
+}
+
+var plainIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+// reservedWords are the MySQL reserved words, which need quoting as names
+var reservedWords = func() map[string]bool {
+	words := make(map[string]bool, len(MySQLKeywords))
+	for _, k := range MySQLKeywords {
+		words[k.Text] = true
+	}
+	return words
+}()
+
+// quoteIdentifier backtick-quotes name when it is not a plain identifier or is a keyword
+func quoteIdentifier(name string) string {
+	upper := strings.ToUpper(name)
+	if plainIdentifier.MatchString(name) && !isKeyword(upper) && !reservedWords[upper] {
+		return name
+	}
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// unquoteIdentifier undoes quoteIdentifier
+func unquoteIdentifier(name string) string {
+	if len(name) >= 2 && name[0] == '`' && name[len(name)-1] == '`' {
+		return strings.ReplaceAll(name[1:len(name)-1], "``", "`")
+	}
+	return name
+}
+
+// unqualified returns the last part of a possibly qualified and quoted name,
+// such as "a.b" for o.`a.b`
+func unqualified(name string) string {
+	if strings.HasSuffix(name, "`") {
+		// Find the backtick opening the last part, skipping doubled ones
+		i := len(name) - 2
+		for i > 0 && (name[i] != '`' || name[i-1] == '`') {
+			if name[i] == '`' {
+				i--
+			}
+			i--
+		}
+		return unquoteIdentifier(name[max(i, 0):])
+	}
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// browse runs the \browse TUI and types the chosen identifier into the prompt
+func (p *PromptExecutor) browse() {
+	if p.input == nil {
+		fmt.Println("\\browse requires an interactive terminal")
+		return
+	}
+
+	m := newBrowseModel(p)
+	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+		fmt.Printf("Browser failed: %v\n", err)
+		return
+	}
+	if m.selected != "" {
+		p.input.Inject(m.selected)
+	}
+}
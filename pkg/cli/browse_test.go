@@ -0,0 +1,68 @@
+package cli
+
+import "testing"
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"film", "film"},
+		{"film_actor", "film_actor"},
+		{"order", "`order`"},
+		{"my table", "`my table`"},
+		{"2fa", "`2fa`"},
+		{"we`ird", "`we``ird`"},
+		{"key", "`key`"},
+		{"usage", "`usage`"},
+	}
+	for _, tt := range tests {
+		if got := quoteIdentifier(tt.name); got != tt.want {
+			t.Errorf("quoteIdentifier(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if got := unquoteIdentifier(quoteIdentifier(tt.name)); got != tt.name {
+			t.Errorf("unquoteIdentifier(%q) = %q", quoteIdentifier(tt.name), got)
+		}
+	}
+}
+
+func TestUnqualified(t *testing.T) {
+	tests := []struct{ name, want string }{
+		{"id", "id"},
+		{"o.id", "id"},
+		{"`my table`.`order`", "order"},
+		{"o.`a.b`", "a.b"},
+		{"`we``ird`", "we`ird"},
+	}
+	for _, tt := range tests {
+		if got := unqualified(tt.name); got != tt.want {
+			t.Errorf("unqualified(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBrowseIdentifierQualifiesOtherDatabase(t *testing.T) {
+	m := &browseModel{
+		p:        &PromptExecutor{database: "sakila"},
+		level:    browseTables,
+		database: "world",
+		filtered: []string{"city"},
+	}
+	if got := m.identifier(); got != "world.city" {
+		t.Errorf("identifier() = %q, want world.city", got)
+	}
+
+	m.database = "sakila"
+	if got := m.identifier(); got != "city" {
+		t.Errorf("identifier() = %q, want city", got)
+	}
+}
+
+func TestTruncateDisplay(t *testing.T) {
+	if got := truncateDisplay("information_schema", 8); got != "informa…" {
+		t.Errorf("truncateDisplay = %q", got)
+	}
+	if got := truncateDisplay("film", 8); got != "film" {
+		t.Errorf("truncateDisplay = %q", got)
+	}
+}
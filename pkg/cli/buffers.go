@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultResultBuffers is how many recent results are kept for \buffers
+const defaultResultBuffers = 10
+
+// bookmarkName is what \bookmark accepts as a name; bufN is left to the
+// numbered buffers
+var bookmarkName = regexp.MustCompile(`^[A-Za-z_][\w-]*$`)
+
+// resultBuffer is a result kept for \show. Buffers are numbered buf1, buf2…
+// in the order the results arrived; a bookmarked buffer also has a name.
+type resultBuffer struct {
+	ID     int
+	Name   string // set by \bookmark, which keeps the buffer for the session
+	Saved  time.Time
+	Result *Result
+}
+
+// label is how messages refer to the buffer
+func (b *resultBuffer) label() string {
+	if b.Name != "" {
+		return fmt.Sprintf("buf%d (%s)", b.ID, b.Name)
+	}
+	return fmt.Sprintf("buf%d", b.ID)
+}
+
+// resultBuffers keeps the last results of the session in memory so they can
+// be shown, viewed or saved again without running their statements
+type resultBuffers struct {
+	limit   int // unnamed buffers kept, 0 to keep none
+	next    int
+	buffers []*resultBuffer // oldest first
+}
+
+// add keeps res as the newest buffer, dropping the oldest unnamed buffers
+// past the limit
+func (b *resultBuffers) add(res *Result) {
+	if b.limit <= 0 {
+		return
+	}
+	b.next++
+	b.buffers = append(b.buffers, &resultBuffer{ID: b.next, Saved: time.Now(), Result: res})
+
+	unnamed := 0
+	for _, buf := range b.buffers {
+		if buf.Name == "" {
+			unnamed++
+		}
+	}
+	kept := b.buffers[:0]
+	for _, buf := range b.buffers {
+		if buf.Name == "" && unnamed > b.limit {
+			unnamed--
+			continue
+		}
+		kept = append(kept, buf)
+	}
+	b.buffers = kept
+}
+
+// find returns the buffer ref names: bufN or N, a bookmark, or the newest
+// buffer when ref is empty
+func (b *resultBuffers) find(ref string) *resultBuffer {
+	if len(b.buffers) == 0 {
+		return nil
+	}
+	if ref == "" {
+		return b.buffers[len(b.buffers)-1]
+	}
+	if id, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(ref), "buf")); err == nil {
+		for _, buf := range b.buffers {
+			if buf.ID == id {
+				return buf
+			}
+		}
+		return nil
+	}
+	for _, buf := range b.buffers {
+		if strings.EqualFold(buf.Name, ref) {
+			return buf
+		}
+	}
+	return nil
+}
+
+// bookmark names the buffer ref, taking the name from any buffer that had
+// it. A named buffer is kept however many results follow.
+func (b *resultBuffers) bookmark(name, ref string) (*resultBuffer, error) {
+	if !bookmarkName.MatchString(name) {
+		return nil, fmt.Errorf("bookmark names start with a letter and hold letters, digits, _ and -")
+	}
+	if _, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(name), "buf")); err == nil {
+		return nil, fmt.Errorf("%s is the name of a numbered buffer", name)
+	}
+	buf := b.find(ref)
+	if buf == nil {
+		if ref == "" {
+			return nil, fmt.Errorf("no result to bookmark")
+		}
+		return nil, fmt.Errorf("no such buffer: %s (see \\buffers)", ref)
+	}
+	for _, other := range b.buffers {
+		if strings.EqualFold(other.Name, name) {
+			other.Name = ""
+		}
+	}
+	buf.Name = name
+	return buf, nil
+}
+
+// listBuffers implements \buffers
+func (p *PromptExecutor) listBuffers() {
+	if len(p.buffers.buffers) == 0 {
+		if p.buffers.limit <= 0 {
+			fmt.Println("Result buffers are off (result_buffers = 0)")
+		} else {
+			fmt.Println("No results yet")
+		}
+		return
+	}
+	rows := make([][]string, 0, len(p.buffers.buffers))
+	for _, buf := range p.buffers.buffers {
+		rows = append(rows, []string{
+			fmt.Sprintf("buf%d", buf.ID),
+			buf.Name,
+			strconv.Itoa(len(buf.Result.Rows)),
+			time.Since(buf.Saved).Round(time.Second).String(),
+			truncateQuery(buf.Result.Statement, 60),
+		})
+	}
+	fmt.Print(formatMySQLTable([]string{"Buffer", "Bookmark", "Rows", "Age", "Statement"}, rows))
+}
+
+// showBuffer implements \show [buffer] [> file]: print a kept result in the
+// current output format, or save it to a file in the format its extension
+// names (.csv, .json, otherwise a table)
+func (p *PromptExecutor) showBuffer(args string) {
+	ref, path, toFile := strings.Cut(args, ">")
+	ref, path = strings.TrimSpace(ref), strings.TrimSpace(path)
+	if strings.Contains(ref, " ") || (toFile && path == "") {
+		fmt.Println("Usage: \\show [buffer] [> file]")
+		return
+	}
+	buf := p.buffers.find(ref)
+	if buf == nil {
+		if ref == "" {
+			fmt.Println("No result to show")
+		} else {
+			fmt.Printf("No such buffer: %s (see \\buffers)\n", ref)
+		}
+		return
+	}
+	res := buf.Result
+
+	if toFile {
+		if err := writeBuffer(expandHome(path), res); err != nil {
+			fmt.Printf(tr("Error: %v\n"), err)
+			return
+		}
+		fmt.Printf("Wrote %d row%s of %s to %s\n", len(res.Rows), plural(len(res.Rows)), buf.label(), path)
+		return
+	}
+	fmt.Printf("%s, %s ago: %s\n", buf.label(), time.Since(buf.Saved).Round(time.Second), truncateQuery(res.Statement, 60))
+	output, _ := p.renderResult(res, false)
+	p.printPaged(output + "\n" + fmt.Sprintf(tr("%d row%s in set\n"), len(res.Rows), plural(len(res.Rows))))
+}
+
+// writeBuffer saves a result to path, as CSV or JSON for those extensions
+// and as a table otherwise
+func writeBuffer(path string, res *Result) error {
+	format := "table"
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		format = "csv"
+	case ".json":
+		format = "json"
+	}
+	var b strings.Builder
+	if err := renderers[format].Render(&b, res); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// bookmarkResult implements \bookmark <name> [buffer], the newest result
+// when no buffer is given
+func (p *PromptExecutor) bookmarkResult(args string) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 || len(fields) > 2 {
+		fmt.Println("Usage: \\bookmark <name> [buffer]")
+		return
+	}
+	ref := ""
+	if len(fields) == 2 {
+		ref = fields[1]
+	}
+	buf, err := p.buffers.bookmark(fields[0], ref)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("Bookmarked %s; \\show %s shows it again\n", buf.label(), buf.Name)
+}
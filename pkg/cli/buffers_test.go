@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResultBuffers(t *testing.T) {
+	b := resultBuffers{limit: 2}
+	for _, stmt := range []string{"SELECT 1", "SELECT 2", "SELECT 3"} {
+		b.add(&Result{Statement: stmt})
+	}
+	if b.find("buf1") != nil || b.find("2").Result.Statement != "SELECT 2" || b.find("").ID != 3 {
+		t.Fatalf("got %d buffers", len(b.buffers))
+	}
+
+	// A bookmarked buffer outlives the limit
+	if _, err := b.bookmark("before", "buf2"); err != nil {
+		t.Fatal(err)
+	}
+	b.add(&Result{Statement: "SELECT 4"})
+	b.add(&Result{Statement: "SELECT 5"})
+	if len(b.buffers) != 3 || b.find("BEFORE").ID != 2 || b.find("buf3") != nil {
+		t.Errorf("got %v", b.buffers)
+	}
+
+	// Bookmarking again moves the name, and the next result pushes out the
+	// buffer that lost it
+	if buf, err := b.bookmark("before", ""); err != nil || buf.ID != 5 || b.find("buf2").Name != "" {
+		t.Errorf("got %v, %v", buf, err)
+	}
+	b.add(&Result{Statement: "SELECT 6"})
+	if b.find("buf2") != nil || b.find("before").ID != 5 {
+		t.Errorf("got %v", b.buffers)
+	}
+
+	for _, name := range []string{"buf7", "7", "bad name", "-x"} {
+		if _, err := b.bookmark(name, ""); err == nil {
+			t.Errorf("%q: expected an error", name)
+		}
+	}
+	if _, err := b.bookmark("after", "buf9"); err == nil {
+		t.Error("expected an error for a missing buffer")
+	}
+
+	off := resultBuffers{}
+	off.add(&Result{})
+	if off.find("") != nil {
+		t.Error("a limit of 0 keeps nothing")
+	}
+}
+
+func TestWriteBuffer(t *testing.T) {
+	dir := t.TempDir()
+	for name, want := range map[string]string{
+		"out.csv": "id,name,price\n1,\"Widget, large\",9.99\n2,,\n",
+		"out.txt": formatMySQLTable(sampleResult.Columns, sampleResult.Rows),
+	} {
+		path := filepath.Join(dir, name)
+		if err := writeBuffer(path, sampleResult); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := os.ReadFile(path); string(got) != want {
+			t.Errorf("%s: got %q", name, got)
+		}
+	}
+}
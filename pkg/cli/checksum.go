@@ -0,0 +1,347 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChecksumOptions holds the settings of the checksum subcommand
+type ChecksumOptions struct {
+	Source      string // [profile:]db.table
+	Target      string
+	ChunkSize   int
+	Concurrency int
+}
+
+// checksumTable is one side of a comparison
+type checksumTable struct {
+	Profile  string // option file group to connect with; empty for the flags
+	Database string
+	Table    string
+	DB       *sql.DB
+}
+
+func (t checksumTable) String() string {
+	name := t.Database + "." + t.Table
+	if t.Profile != "" {
+		name = t.Profile + ":" + name
+	}
+	return name
+}
+
+func (t checksumTable) quoted() string {
+	return quoteIdentifier(t.Database) + "." + quoteIdentifier(t.Table)
+}
+
+// checksumChunk is a primary key range: above Lower (nil for the first chunk)
+// up to and including Upper (nil for the last)
+type checksumChunk struct {
+	Lower, Upper []string
+}
+
+// chunkSum is the row count and checksum of a chunk on one side
+type chunkSum struct {
+	Rows int64
+	Sum  uint64
+	Err  error
+}
+
+// parseTableRef parses "[profile:]db.table"; db defaults to database
+func parseTableRef(ref, database string) (checksumTable, error) {
+	var t checksumTable
+	if profile, rest, ok := strings.Cut(ref, ":"); ok {
+		t.Profile, ref = profile, rest
+	}
+	if db, table, ok := strings.Cut(ref, "."); ok {
+		t.Database, t.Table = db, table
+	} else {
+		t.Database, t.Table = database, ref
+	}
+	t.Database = strings.Trim(t.Database, "`")
+	t.Table = strings.Trim(t.Table, "`")
+	if t.Database == "" || t.Table == "" {
+		return t, fmt.Errorf("invalid table %q: use [profile:]db.table", ref)
+	}
+	return t, nil
+}
+
+// Checksum compares two tables chunk by chunk along the primary key and
+// reports the ranges whose rows differ. It is the entry point of the
+// checksum subcommand.
+func Checksum(host string, port int, user, password, database, socket, loginPath, configFile string, zstdCompressionLevel int, opts ChecksumOptions) error {
+	source, err := parseTableRef(opts.Source, database)
+	if err != nil {
+		return err
+	}
+	target, err := parseTableRef(opts.Target, database)
+	if err != nil {
+		return err
+	}
+	if opts.ChunkSize < 1 {
+		opts.ChunkSize = 1000
+	}
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+
+	cfg := LoadSyntaxConfig()
+	SetSessionVariables(cfg.Session)
+	// Tables on the same profile share a connection pool
+	pools := make(map[string]*sql.DB)
+	defer func() {
+		for _, db := range pools {
+			db.Close()
+		}
+	}()
+	for _, t := range []*checksumTable{&source, &target} {
+		if db, ok := pools[t.Profile]; ok {
+			t.DB = db
+			continue
+		}
+		var db *sql.DB
+		if t.Profile == "" {
+			db, _, _, err = connect(host, port, user, password, "", socket, loginPath, configFile, zstdCompressionLevel)
+		} else {
+			profile := readServerProfile(t.Profile, configFile)
+			profilePort := profile.Port
+			if port != 0 {
+				profilePort = port
+			}
+			db, _, _, err = connect(profile.Host, profilePort, orDefault(user, profile.User), orDefault(password, profile.Password),
+				"", orDefault(socket, profile.Socket), t.Profile, configFile, zstdCompressionLevel)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", t, err)
+		}
+		db.SetMaxOpenConns(opts.Concurrency)
+		pools[t.Profile], t.DB = db, db
+	}
+
+	ctx := context.Background()
+	key, err := primaryKeyColumns(ctx, source)
+	if err != nil {
+		return err
+	}
+	columns, err := tableColumns(ctx, source)
+	if err != nil {
+		return err
+	}
+	targetColumns, err := tableColumns(ctx, target)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(columns, targetColumns) {
+		return fmt.Errorf("%s and %s have different columns:\n  %s\n  %s", source, target,
+			strings.Join(columns, ", "), strings.Join(targetColumns, ", "))
+	}
+
+	start := time.Now()
+	chunks, err := chunkBoundaries(ctx, source, key, opts.ChunkSize)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Comparing %s with %s: %d chunk%s of up to %d rows along (%s), %d at a time\n",
+		source, target, len(chunks), plural(len(chunks)), opts.ChunkSize, strings.Join(key, ", "), opts.Concurrency)
+
+	sums := make([][2]chunkSum, len(chunks))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for range opts.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				for side, t := range []checksumTable{source, target} {
+					sums[i][side] = checksumChunkOn(ctx, t, key, columns, chunks[i])
+				}
+			}
+		}()
+	}
+	for i := range chunks {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	var rows [][]string
+	var sourceRows int64
+	for i, chunk := range chunks {
+		s, t := sums[i][0], sums[i][1]
+		for _, sum := range []chunkSum{s, t} {
+			if sum.Err != nil {
+				return fmt.Errorf("chunk %d %s: %w", i+1, formatChunkRange(chunk), sum.Err)
+			}
+		}
+		sourceRows += s.Rows
+		if s != t {
+			rows = append(rows, []string{strconv.Itoa(i + 1), formatChunkRange(chunk),
+				strconv.FormatInt(s.Rows, 10), strconv.FormatInt(t.Rows, 10)})
+		}
+	}
+	elapsed := time.Since(start).Seconds()
+	if len(rows) == 0 {
+		fmt.Printf("Tables match: %d row%s in %d chunk%s (%.2fs)\n", sourceRows, plural(int(sourceRows)), len(chunks), plural(len(chunks)), elapsed)
+		return nil
+	}
+	fmt.Print(formatMySQLTable([]string{"Chunk", "Range (" + strings.Join(key, ", ") + ")", "Source rows", "Target rows"}, rows))
+	return fmt.Errorf("%d of %d chunk%s differ (%.2fs)", len(rows), len(chunks), plural(len(chunks)), elapsed)
+}
+
+// primaryKeyColumns returns the primary key of a table in index order
+func primaryKeyColumns(ctx context.Context, t checksumTable) ([]string, error) {
+	key, err := queryColumnNames(ctx, t.DB, `SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.STATISTICS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND INDEX_NAME = 'PRIMARY' ORDER BY SEQ_IN_INDEX`, t.Database, t.Table)
+	if err == nil && len(key) == 0 {
+		err = fmt.Errorf("%s has no primary key to chunk by", t)
+	}
+	return key, err
+}
+
+// tableColumns returns the columns of a table in definition order
+func tableColumns(ctx context.Context, t checksumTable) ([]string, error) {
+	columns, err := queryColumnNames(ctx, t.DB, `SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION`, t.Database, t.Table)
+	if err == nil && len(columns) == 0 {
+		err = fmt.Errorf("table %s does not exist", t)
+	}
+	return columns, err
+}
+
+func queryColumnNames(ctx context.Context, db *sql.DB, query string, args ...any) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// chunkBoundaries walks the source's primary key, chunkSize rows at a time.
+// The first chunk has no lower bound and the last no upper bound, so rows the
+// target has beyond the source's key range are compared too.
+func chunkBoundaries(ctx context.Context, t checksumTable, key []string, chunkSize int) ([]checksumChunk, error) {
+	var chunks []checksumChunk
+	var lower []string
+	for {
+		upper, err := nextChunkBound(ctx, t, key, lower, chunkSize)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, checksumChunk{Lower: lower, Upper: upper})
+		if upper == nil {
+			return chunks, nil
+		}
+		lower = upper
+	}
+}
+
+// nextChunkBound returns the key of the chunkSize-th row above lower, nil
+// when fewer rows are left and the chunk above lower is the last
+func nextChunkBound(ctx context.Context, t checksumTable, key, lower []string, chunkSize int) ([]string, error) {
+	where, args := chunkPredicate(key, checksumChunk{Lower: lower})
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY %s LIMIT 1 OFFSET %d",
+		quoteColumns(key), t.quoted(), where, quoteColumns(key), chunkSize-1)
+	upper := make([]sql.NullString, len(key))
+	dest := make([]any, len(key))
+	for i := range upper {
+		dest[i] = &upper[i]
+	}
+	err := t.DB.QueryRowContext(ctx, query, args...).Scan(dest...)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	bound := make([]string, len(key))
+	for i, v := range upper {
+		bound[i] = v.String
+	}
+	return bound, nil
+}
+
+// chunkPredicate returns the WHERE condition selecting a chunk's rows. Row
+// constructors compare composite keys column by column.
+func chunkPredicate(key []string, chunk checksumChunk) (string, []any) {
+	columns := quoteColumns(key)
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(key)), ", ")
+	if len(key) > 1 {
+		columns, placeholders = "("+columns+")", "("+placeholders+")"
+	}
+	var conditions []string
+	var args []any
+	if chunk.Lower != nil {
+		conditions = append(conditions, columns+" > "+placeholders)
+		for _, v := range chunk.Lower {
+			args = append(args, v)
+		}
+	}
+	if chunk.Upper != nil {
+		conditions = append(conditions, columns+" <= "+placeholders)
+		for _, v := range chunk.Upper {
+			args = append(args, v)
+		}
+	}
+	if len(conditions) == 0 {
+		return "1 = 1", nil
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// checksumQuery returns the statement summing a chunk: the row count and the
+// XOR of each row's CRC32, with NULLs told apart from empty strings the way
+// pt-table-checksum does
+func checksumQuery(t checksumTable, columns []string, where string) string {
+	quoted := make([]string, len(columns))
+	nulls := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = quoteIdentifier(c)
+		nulls[i] = "ISNULL(" + quoted[i] + ")"
+	}
+	return fmt.Sprintf("SELECT COUNT(*), COALESCE(BIT_XOR(CRC32(CONCAT_WS('#', %s, CONCAT(%s)))), 0) FROM %s WHERE %s",
+		strings.Join(quoted, ", "), strings.Join(nulls, ", "), t.quoted(), where)
+}
+
+func checksumChunkOn(ctx context.Context, t checksumTable, key, columns []string, chunk checksumChunk) chunkSum {
+	where, args := chunkPredicate(key, chunk)
+	var sum chunkSum
+	sum.Err = t.DB.QueryRowContext(ctx, checksumQuery(t, columns, where), args...).Scan(&sum.Rows, &sum.Sum)
+	return sum
+}
+
+func quoteColumns(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = quoteIdentifier(c)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// formatChunkRange shows a chunk as a half-open interval of key values, e.g.
+// (1000, 2000]
+func formatChunkRange(chunk checksumChunk) string {
+	bound := func(values []string) string {
+		if values == nil {
+			return "-"
+		}
+		if len(values) == 1 {
+			return values[0]
+		}
+		return "(" + strings.Join(values, ", ") + ")"
+	}
+	return "(" + bound(chunk.Lower) + ", " + bound(chunk.Upper) + "]"
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTableRef(t *testing.T) {
+	tests := []struct {
+		ref      string
+		want     checksumTable
+		wantName string
+		wantErr  bool
+	}{
+		{"sakila.payment", checksumTable{Database: "sakila", Table: "payment"}, "sakila.payment", false},
+		{"payment", checksumTable{Database: "current", Table: "payment"}, "current.payment", false},
+		{"replica:sakila.`order`", checksumTable{Profile: "replica", Database: "sakila", Table: "order"}, "replica:sakila.order", false},
+		{"sakila.", checksumTable{}, "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseTableRef(tt.ref, "current")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTableRef(%q) error = %v, want error %v", tt.ref, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got != tt.want || got.String() != tt.wantName {
+			t.Errorf("parseTableRef(%q) = %+v (%s), want %+v (%s)", tt.ref, got, got, tt.want, tt.wantName)
+		}
+	}
+}
+
+func TestChunkPredicate(t *testing.T) {
+	tests := []struct {
+		key       []string
+		chunk     checksumChunk
+		wantWhere string
+		wantArgs  []any
+	}{
+		{[]string{"id"}, checksumChunk{}, "1 = 1", nil},
+		{[]string{"id"}, checksumChunk{Upper: []string{"1000"}}, "id <= ?", []any{"1000"}},
+		{[]string{"id"}, checksumChunk{Lower: []string{"1000"}, Upper: []string{"2000"}}, "id > ? AND id <= ?", []any{"1000", "2000"}},
+		{[]string{"a", "order"}, checksumChunk{Lower: []string{"1", "x"}}, "(a, `order`) > (?, ?)", []any{"1", "x"}},
+	}
+	for _, tt := range tests {
+		where, args := chunkPredicate(tt.key, tt.chunk)
+		if where != tt.wantWhere || !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("chunkPredicate(%v, %+v) = %q, %v; want %q, %v", tt.key, tt.chunk, where, args, tt.wantWhere, tt.wantArgs)
+		}
+	}
+}
+
+func TestChecksumQuery(t *testing.T) {
+	table := checksumTable{Database: "sakila", Table: "payment"}
+	got := checksumQuery(table, []string{"id", "amount"}, "id <= ?")
+	want := "SELECT COUNT(*), COALESCE(BIT_XOR(CRC32(CONCAT_WS('#', id, amount, CONCAT(ISNULL(id), ISNULL(amount))))), 0) FROM sakila.payment WHERE id <= ?"
+	if got != want {
+		t.Errorf("checksumQuery =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatChunkRange(t *testing.T) {
+	tests := []struct {
+		chunk checksumChunk
+		want  string
+	}{
+		{checksumChunk{Upper: []string{"1000"}}, "(-, 1000]"},
+		{checksumChunk{Lower: []string{"1000"}, Upper: []string{"2000"}}, "(1000, 2000]"},
+		{checksumChunk{Lower: []string{"1", "x"}}, "((1, x), -]"},
+	}
+	for _, tt := range tests {
+		if got := formatChunkRange(tt.chunk); got != tt.want {
+			t.Errorf("formatChunkRange(%+v) = %q, want %q", tt.chunk, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultChunkedBatch is the rows per chunk of \chunked
+const defaultChunkedBatch = 1000
+
+var (
+	// chunkedOption matches one --name value option at the end of \chunked
+	chunkedOption = regexp.MustCompile(`\s+--(batch|sleep|max-lag)(?:\s+|=)(\S+)\s*$`)
+	chunkedDelete = regexp.MustCompile("(?is)^DELETE\\s+FROM\\s+((?:(?:`[^`]+`|[\\w$]+)\\s*\\.\\s*)?(?:`[^`]+`|[\\w$]+))(?:\\s+WHERE\\s+(.+))?$")
+	chunkedUpdate = regexp.MustCompile("(?is)^UPDATE\\s+((?:(?:`[^`]+`|[\\w$]+)\\s*\\.\\s*)?(?:`[^`]+`|[\\w$]+))\\s+SET\\s+(.+?)(?:\\s+WHERE\\s+(.+))?$")
+	// chunkedUnsupported finds clauses a chunked statement cannot keep
+	chunkedUnsupported = regexp.MustCompile(`(?i)\b(ORDER\s+BY|LIMIT|JOIN|USING)\b`)
+)
+
+// chunkedOptions are the options of \chunked
+type chunkedOptions struct {
+	Batch  int
+	Sleep  time.Duration
+	MaxLag time.Duration // 0 leaves replica lag unchecked
+}
+
+// parseChunkedArgs splits \chunked's arguments into the statement and the
+// options that follow it; --max-lag defaults to maxLag
+func parseChunkedArgs(args string, maxLag time.Duration) (string, chunkedOptions, error) {
+	opts := chunkedOptions{Batch: defaultChunkedBatch, MaxLag: maxLag}
+	stmt := strings.TrimSpace(args)
+	for {
+		m := chunkedOption.FindStringSubmatchIndex(" " + stmt)
+		if m == nil {
+			break
+		}
+		name, value := (" " + stmt)[m[2]:m[3]], (" " + stmt)[m[4]:m[5]]
+		stmt = strings.TrimSpace((" " + stmt)[:m[0]])
+		switch name {
+		case "batch":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return "", opts, fmt.Errorf("--batch must be a positive number, got %q", value)
+			}
+			opts.Batch = n
+		case "sleep", "max-lag":
+			d, err := time.ParseDuration(value)
+			if err != nil || d < 0 {
+				return "", opts, fmt.Errorf("--%s must be a duration such as 100ms or 5s, got %q", name, value)
+			}
+			if name == "sleep" {
+				opts.Sleep = d
+			} else {
+				opts.MaxLag = d
+			}
+		}
+	}
+	return strings.TrimSpace(strings.TrimSuffix(stmt, ";")), opts, nil
+}
+
+// chunkedDML is a single-table DELETE or UPDATE taken apart so that a key
+// range can be added to its condition
+type chunkedDML struct {
+	Verb  string // DELETE or UPDATE
+	Table string
+	Set   string // the assignments of an UPDATE
+	Where string // empty for every row
+}
+
+// parseChunkedDML accepts DELETE FROM t [WHERE ...] and UPDATE t SET ...
+// [WHERE ...]; ORDER BY, LIMIT and joins would change meaning once chunked
+func parseChunkedDML(stmt string) (chunkedDML, error) {
+	var d chunkedDML
+	if m := chunkedDelete.FindStringSubmatch(stmt); m != nil {
+		d = chunkedDML{Verb: "DELETE", Table: m[1], Where: strings.TrimSpace(m[2])}
+	} else if m := chunkedUpdate.FindStringSubmatch(stmt); m != nil {
+		d = chunkedDML{Verb: "UPDATE", Table: m[1], Set: strings.TrimSpace(m[2]), Where: strings.TrimSpace(m[3])}
+	} else {
+		return d, fmt.Errorf("\\chunked takes a single-table DELETE FROM ... or UPDATE ... SET")
+	}
+	if m := chunkedUnsupported.FindString(maskLiterals(d.Set + " " + d.Where)); m != "" {
+		return d, fmt.Errorf("%s cannot be chunked; the chunks supply the order and size", strings.ToUpper(m))
+	}
+	return d, nil
+}
+
+// setsColumn reports whether an UPDATE assigns column, which chunking by a
+// key the statement changes would skip or revisit rows
+func (d chunkedDML) setsColumn(column string) bool {
+	target := regexp.MustCompile("(?i)(?:^|,)\\s*(?:`?[\\w$]+`?\\s*\\.\\s*)?`?" + regexp.QuoteMeta(column) + "`?\\s*=")
+	return d.Verb == "UPDATE" && target.MatchString(maskLiterals(d.Set))
+}
+
+// statement returns the statement limited to the rows of a key range
+func (d chunkedDML) statement(table, keyRange string) string {
+	where := keyRange
+	if d.Where != "" {
+		where += " AND (" + d.Where + ")"
+	}
+	if d.Verb == "DELETE" {
+		return "DELETE FROM " + table + " WHERE " + where
+	}
+	return "UPDATE " + table + " SET " + d.Set + " WHERE " + where
+}
+
+// chunkRange returns chunkPredicate's condition with the bounds written in,
+// so the statement can be shown and run as it is. numeric tells which key
+// columns are numbers; their bounds are written as numbers, which keeps the
+// comparison on the index and the precision of DECIMAL and BIGINT UNSIGNED
+// keys.
+func chunkRange(key []string, numeric []bool, chunk checksumChunk) string {
+	where, args := chunkPredicate(key, chunk)
+	var b strings.Builder
+	for n, arg := range args {
+		i := strings.IndexByte(where, '?')
+		b.WriteString(where[:i] + keyLiteral(fmt.Sprint(arg), numeric[n%len(key)]))
+		where = where[i+1:]
+	}
+	return b.String() + where
+}
+
+// numberLiteral matches the values of numeric columns as the server sends
+// them
+var numberLiteral = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?(e[-+]?[0-9]+)?$`)
+
+// keyLiteral writes a key value as SQL: a number as it is, anything else as a
+// quoted string
+func keyLiteral(value string, numeric bool) string {
+	if numeric && numberLiteral.MatchString(value) {
+		return value
+	}
+	return sqlLiteral(&value)
+}
+
+// numericKeyColumns reports which columns of the key are numbers
+func numericKeyColumns(ctx context.Context, t checksumTable, key []string) ([]bool, error) {
+	rows, err := t.DB.QueryContext(ctx, `SELECT COLUMN_NAME, DATA_TYPE FROM INFORMATION_SCHEMA.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?`, t.Database, t.Table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	types := make(map[string]string)
+	for rows.Next() {
+		var name, dataType string
+		if err := rows.Scan(&name, &dataType); err != nil {
+			return nil, err
+		}
+		types[strings.ToLower(name)] = strings.ToUpper(dataType)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	numeric := make([]bool, len(key))
+	for i, column := range key {
+		numeric[i] = isNumericType(types[strings.ToLower(column)])
+	}
+	return numeric, nil
+}
+
+// runChunked implements \chunked <DELETE|UPDATE> [--batch n] [--sleep d]
+// [--max-lag d]: the statement runs as one statement per primary key range
+// of --batch rows, each committed on its own, pausing --sleep between them
+// and while a replica lags more than --max-lag
+func (p *PromptExecutor) runChunked(args string) {
+	stmt, opts, err := parseChunkedArgs(args, p.lag.Max)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if stmt == "" {
+		fmt.Printf("Usage: \\chunked <DELETE|UPDATE statement> [--batch %d] [--sleep 100ms] [--max-lag %s]\n", defaultChunkedBatch, p.lag.Max)
+		return
+	}
+	dml, err := parseChunkedDML(stmt)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	database, table := splitTableName(dml.Table, p.database)
+	if database == "" {
+		fmt.Println("No database selected; use \\u <db> or a qualified table name")
+		return
+	}
+	ctx, stop := p.statementContext()
+	defer stop()
+
+	t := checksumTable{Database: database, Table: table, DB: p.db}
+	key, err := primaryKeyColumns(ctx, t)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return
+	}
+	for _, column := range key {
+		if dml.setsColumn(column) {
+			fmt.Printf("The UPDATE changes %s, the key it would be chunked by\n", column)
+			return
+		}
+	}
+	numeric, err := numericKeyColumns(ctx, t, key)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), canceled(ctx, err))
+		return
+	}
+	var rows sql.NullFloat64
+	_ = p.db.QueryRowContext(ctx, `SELECT TABLE_ROWS FROM INFORMATION_SCHEMA.TABLES
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?`, database, table).Scan(&rows)
+	chunks := int(math.Max(1, math.Ceil(rows.Float64/float64(opts.Batch))))
+
+	firstUpper, err := nextChunkBound(ctx, t, key, nil, opts.Batch)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), canceled(ctx, err))
+		return
+	}
+	fmt.Printf("%s on %s in chunks of %d rows by (%s): ~%s rows, ~%d chunk%s\n",
+		dml.Verb, t, opts.Batch, strings.Join(key, ", "), formatRowCount(rows.Float64), chunks, plural(chunks))
+	fmt.Printf("First chunk: %s\n", dml.statement(t.quoted(), chunkRange(key, numeric, checksumChunk{Upper: firstUpper})))
+	if p.canPrompt() && !askYesNo("Run it? [y/N] ") {
+		fmt.Printf("%s not run\n", dml.Verb)
+		return
+	}
+
+	var lag *replicaLagChecker
+	if opts.MaxLag > 0 {
+		lag = p.newReplicaLagChecker(ctx, opts.MaxLag)
+		defer lag.close()
+	}
+
+	start := time.Now()
+	var changed int64
+	var lower []string
+	upper := firstUpper
+	for chunk := 1; ; chunk++ {
+		if chunk > 1 {
+			if upper, err = nextChunkBound(ctx, t, key, lower, opts.Batch); err != nil {
+				break
+			}
+		}
+		var res *Result
+		where := chunkRange(key, numeric, checksumChunk{Lower: lower, Upper: upper})
+		if res, err = p.runStatement(ctx, dml.statement(t.quoted(), where)); err != nil {
+			break
+		}
+		changed += res.RowsAffected
+		fmt.Printf("\rChunk %d/~%d: %d row%s changed, %s", chunk, max(chunks, chunk), changed, plural(int(changed)), time.Since(start).Round(time.Second))
+		if upper == nil {
+			fmt.Printf("\n%s done: %d row%s changed in %d chunk%s (%s)\n", dml.Verb, changed, plural(int(changed)), chunk, plural(chunk),
+				time.Since(start).Round(time.Millisecond))
+			return
+		}
+		lower = upper
+		if opts.Sleep > 0 {
+			select {
+			case <-time.After(opts.Sleep):
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+		}
+		if err == nil {
+			err = lag.wait(ctx)
+		}
+		if err != nil {
+			break
+		}
+	}
+	fmt.Printf("\n"+tr("Error: %v\n"), canceled(ctx, err))
+	if lower == nil {
+		fmt.Println("Nothing was changed")
+		return
+	}
+	fmt.Printf("Stopped after %d row%s changed; the rows with (%s) up to (%s) are done\n",
+		changed, plural(int(changed)), strings.Join(key, ", "), strings.Join(lower, ", "))
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseChunkedArgs(t *testing.T) {
+	stmt, opts, err := parseChunkedArgs(" DELETE FROM logs WHERE created < '2020-01-01'; --batch 5000 --sleep=100ms --max-lag 0", defaultMaxReplicaLag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stmt != "DELETE FROM logs WHERE created < '2020-01-01'" || opts.Batch != 5000 || opts.Sleep != 100*time.Millisecond || opts.MaxLag != 0 {
+		t.Errorf("got %q %+v", stmt, opts)
+	}
+	if _, opts, _ := parseChunkedArgs("DELETE FROM logs", 10*time.Second); opts.Batch != defaultChunkedBatch || opts.MaxLag != 10*time.Second {
+		t.Errorf("defaults = %+v", opts)
+	}
+	for _, bad := range []string{"DELETE FROM t --batch 0", "DELETE FROM t --sleep soon"} {
+		if _, _, err := parseChunkedArgs(bad, 0); err == nil {
+			t.Errorf("parseChunkedArgs(%q) should fail", bad)
+		}
+	}
+}
+
+func TestParseChunkedDML(t *testing.T) {
+	d, err := parseChunkedDML("UPDATE sakila.film SET rental_rate = rental_rate * 1.1, note = 'ORDER BY' WHERE rating = 'PG'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Verb != "UPDATE" || d.Table != "sakila.film" || d.Set != "rental_rate = rental_rate * 1.1, note = 'ORDER BY'" || d.Where != "rating = 'PG'" {
+		t.Errorf("got %+v", d)
+	}
+	if !d.setsColumn("note") || d.setsColumn("film_id") || d.setsColumn("rating") {
+		t.Error("setsColumn")
+	}
+	got := d.statement("sakila.film", chunkRange([]string{"film_id"}, []bool{true}, checksumChunk{Lower: []string{"100"}, Upper: []string{"200"}}))
+	want := "UPDATE sakila.film SET rental_rate = rental_rate * 1.1, note = 'ORDER BY' WHERE film_id > 100 AND film_id <= 200 AND (rating = 'PG')"
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	d, err = parseChunkedDML("DELETE FROM logs")
+	if err != nil || d.Verb != "DELETE" || d.Where != "" || d.statement("logs", "id <= '5'") != "DELETE FROM logs WHERE id <= '5'" {
+		t.Errorf("got %+v, %v", d, err)
+	}
+
+	for _, bad := range []string{
+		"DELETE FROM logs WHERE id < 10 ORDER BY id LIMIT 5",
+		"DELETE l FROM logs l JOIN users u ON u.id = l.user_id",
+		"INSERT INTO logs VALUES (1)",
+	} {
+		if _, err := parseChunkedDML(bad); err == nil {
+			t.Errorf("parseChunkedDML(%q) should fail", bad)
+		}
+	}
+}
+
+func TestChunkRange(t *testing.T) {
+	tests := []struct {
+		key     []string
+		numeric []bool
+		chunk   checksumChunk
+		want    string
+	}{
+		{[]string{"id"}, []bool{true}, checksumChunk{Upper: []string{"18446744073709551615"}}, "id <= 18446744073709551615"},
+		{[]string{"price"}, []bool{true}, checksumChunk{Lower: []string{"-12.50"}}, "price > -12.50"},
+		// A string key keeps its quotes even when the value looks like a number
+		{[]string{"code"}, []bool{false}, checksumChunk{Upper: []string{"0123"}}, "code <= '0123'"},
+		{[]string{"tenant", "id"}, []bool{false, true}, checksumChunk{Lower: []string{"acme", "7"}, Upper: []string{"acme", "1007"}},
+			"(tenant, id) > ('acme', 7) AND (tenant, id) <= ('acme', 1007)"},
+		{[]string{"id"}, []bool{true}, checksumChunk{}, "1 = 1"},
+	}
+	for _, tt := range tests {
+		if got := chunkRange(tt.key, tt.numeric, tt.chunk); got != tt.want {
+			t.Errorf("chunkRange(%v, %+v) = %s, want %s", tt.key, tt.chunk, got, tt.want)
+		}
+	}
+}
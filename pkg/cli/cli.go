@@ -1,16 +1,98 @@
 package cli
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
+	"os"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 )
 
 // Start initializes the CLI with database connection and starts the interactive prompt
-func Start(host string, port int, user, password, database, socket, loginPath, configFile, execute string, zstdCompressionLevel int, aiServerURL, aiServerMode, aiCachePath, aiDetailLevel string) error {
+func Start(ctx context.Context, host string, port int, user, password, database, socket, loginPath, configFile, execute string, zstdCompressionLevel int, aiServerURL, aiServerMode, aiCachePath, aiMCPCommand, aiDetailLevel string) error {
+	if err := loadResumedSession(); err != nil {
+		return err
+	}
+	vars := LoadSyntaxConfig().Session
+	if resumed != nil {
+		vars = resumed.mergeVariables(vars)
+		if database == "" {
+			database = resumed.Database
+		}
+	}
+	SetSessionVariables(vars)
+
+	if kubeForward.Service != "" {
+		if host != "" || socket != "" || len(broadcastServers) > 0 {
+			return fmt.Errorf("--k8s-service cannot be combined with --host, --socket or --servers")
+		}
+		forward, err := startPortForward(port)
+		if err != nil {
+			return err
+		}
+		activeForward = forward
+		defer forward.Close()
+		fmt.Printf("Forwarding 127.0.0.1:%d to %s\n", forward.Port, forward.Resource)
+		host, port = "127.0.0.1", forward.Port
+	}
+
+	if cloudSQL.Instance != "" {
+		if host != "" || socket != "" || kubeForward.Service != "" || len(broadcastServers) > 0 {
+			return fmt.Errorf("--cloudsql-instance cannot be combined with --host, --socket, --k8s-service or --servers")
+		}
+		var err error
+		if user, err = setupCloudSQL(ctx, user); err != nil {
+			return err
+		}
+		host = cloudSQL.Instance
+	}
+
+	var db *sql.DB
+	var mergedConfig *MySQLConfig
+	if len(broadcastServers) > 0 {
+		targets, primary, err := connectServers(port, user, password, database, socket, configFile, zstdCompressionLevel)
+		if err != nil {
+			return err
+		}
+		broadcastTargets = targets
+		db, mergedConfig = targets[0].DB, primary
+	} else {
+		var compressed bool
+		var err error
+		db, mergedConfig, compressed, err = connect(host, port, user, password, database, socket, loginPath, configFile, zstdCompressionLevel)
+		if err != nil {
+			return err
+		}
+
+		if compressed {
+			fmt.Println("Connected to MySQL (with zstd compression)")
+		} else if zstdCompressionLevel > 0 {
+			log.Printf("Connected to MySQL (compression not supported by server)")
+		} else {
+			fmt.Println("Connected to MySQL")
+		}
+
+		printConnectionBanner(db, mergedConfig)
+	}
+
+	// If execute flag is provided, execute the SQL and exit
+	if execute != "" {
+		return executeSQLAndExit(ctx, db, mergedConfig.User, mergedConfig.Host, mergedConfig.Port, mergedConfig.Database, execute, aiServerURL, aiServerMode, aiCachePath, aiMCPCommand, aiDetailLevel)
+	}
+
+	// Start the interactive prompt. Pass AI server settings for client overrides.
+	return StartPrompt(ctx, db, mergedConfig.User, mergedConfig.Host, mergedConfig.Port, mergedConfig.Database, zstdCompressionLevel, aiServerURL, aiServerMode, aiCachePath, aiMCPCommand, aiDetailLevel)
+}
+
+// connect merges option files with CLI arguments and opens a verified
+// connection. If zstd compression was requested but the server rejects it, it
+// retries uncompressed; compressed reports whether compression is in use. On
+// a terminal, missing settings and a rejected empty password are asked for
+// instead of failing.
+func connect(host string, port int, user, password, database, socket, loginPath, configFile string, zstdCompressionLevel int) (db *sql.DB, mergedConfig *MySQLConfig, compressed bool, err error) {
 	// Read MySQL config from files
 	config, err := ReadMySQLConfig(loginPath, configFile)
 	if err != nil {
@@ -19,70 +101,100 @@ func Start(host string, port int, user, password, database, socket, loginPath, c
 	}
 
 	// Merge config with CLI arguments (CLI takes precedence)
-	mergedConfig := MergeConfig(config, user, password, host, port, socket, database)
+	mergedConfig = MergeConfig(config, user, password, host, port, socket, database)
+
+	interactive := stdinIsTerminal()
+	prompted := false
+	if interactive && connectionUnresolved(config, user, host, socket) {
+		if err := promptConnection(mergedConfig, os.Stdin); err != nil {
+			return nil, nil, false, err
+		}
+		prompted = true
+	}
+
+	db, compressed, err = openConnection(mergedConfig, zstdCompressionLevel)
+	if err != nil && interactive && mergedConfig.Password == "" && isAccessDenied(err) {
+		fmt.Println("Access denied without a password.")
+		if mergedConfig.Password, err = readPassword(); err != nil {
+			return nil, nil, false, err
+		}
+		db, compressed, err = openConnection(mergedConfig, zstdCompressionLevel)
+	}
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	// Settings were typed in, so offer the server's databases too
+	if prompted && mergedConfig.Database == "" {
+		if name := chooseDatabase(db); name != "" {
+			db.Close()
+			mergedConfig.Database = name
+			if db, compressed, err = openConnection(mergedConfig, zstdCompressionLevel); err != nil {
+				return nil, nil, false, err
+			}
+		}
+	}
+	return db, mergedConfig, compressed, nil
+}
 
+// openConnection opens and pings a connection, retrying without compression
+// if the server rejects zstd
+func openConnection(mergedConfig *MySQLConfig, zstdCompressionLevel int) (db *sql.DB, compressed bool, err error) {
 	// Build DSN with compression if requested
-	dsn := BuildDSN(mergedConfig.User, mergedConfig.Password, mergedConfig.Host, mergedConfig.Port, mergedConfig.Database, mergedConfig.Socket, zstdCompressionLevel)
+	dsn := withConnectionParams(BuildDSN(mergedConfig.User, mergedConfig.Password, mergedConfig.Host, mergedConfig.Port, mergedConfig.Database, mergedConfig.Socket, zstdCompressionLevel))
 
 	// Connect to database
-	db, err := sql.Open("mysql", dsn)
+	db, err = sql.Open("mysql", dsn)
 	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
+		return nil, false, fmt.Errorf("failed to open database: %w", err)
 	}
 
 	// Test connection
 	if err := db.Ping(); err != nil {
 		// If compression was requested and connection failed, try without compression
-		if zstdCompressionLevel > 0 {
-			log.Printf("Warning: zstd compression (level %d) failed, retrying without compression...", zstdCompressionLevel)
-
-			// Close the failed connection
+		if zstdCompressionLevel <= 0 {
 			db.Close()
+			return nil, false, fmt.Errorf("failed to ping database: %w", err)
+		}
+		log.Printf("Warning: zstd compression (level %d) failed, retrying without compression...", zstdCompressionLevel)
 
-			// Build DSN without compression
-			dsnNoCompress := BuildDSN(mergedConfig.User, mergedConfig.Password, mergedConfig.Host, mergedConfig.Port, mergedConfig.Database, mergedConfig.Socket, 0)
-
-			// Try connecting without compression
-			db, err = sql.Open("mysql", dsnNoCompress)
-			if err != nil {
-				return fmt.Errorf("failed to open database: %w", err)
-			}
+		// Close the failed connection
+		db.Close()
 
-			if err := db.Ping(); err != nil {
-				return fmt.Errorf("failed to ping database (even without compression): %w", err)
-			}
+		// Build DSN without compression
+		dsnNoCompress := withConnectionParams(BuildDSN(mergedConfig.User, mergedConfig.Password, mergedConfig.Host, mergedConfig.Port, mergedConfig.Database, mergedConfig.Socket, 0))
 
-			log.Printf("Connected to MySQL (compression not supported by server)")
-		} else {
-			return fmt.Errorf("failed to ping database: %w", err)
+		// Try connecting without compression
+		db, err = sql.Open("mysql", dsnNoCompress)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to open database: %w", err)
 		}
-	} else {
-		if zstdCompressionLevel > 0 {
-			fmt.Println("Connected to MySQL (with zstd compression)")
-		} else {
-			fmt.Println("Connected to MySQL")
+
+		if err := db.Ping(); err != nil {
+			db.Close()
+			return nil, false, fmt.Errorf("failed to ping database (even without compression): %w", err)
 		}
+		return db, false, nil
 	}
 
-	// Print server version
-	var version string
-	if err := db.QueryRow("SELECT VERSION()").Scan(&version); err != nil {
-		log.Printf("Failed to get version: %v", err)
-	} else {
-		fmt.Printf("MySQL version: %s\n", version)
-	}
+	return db, zstdCompressionLevel > 0, nil
+}
 
-	// If execute flag is provided, execute the SQL and exit
-	if execute != "" {
-		return executeSQLAndExit(db, mergedConfig.User, mergedConfig.Host, mergedConfig.Port, mergedConfig.Database, execute, aiServerURL, aiServerMode, aiCachePath, aiDetailLevel)
-	}
+// executeAnalysis is what --ai, --visual and --json ask -e to show of an
+// EXPLAIN
+var executeAnalysis struct {
+	ai, visual, json bool
+}
 
-	// Start the interactive prompt. Pass AI server settings for client overrides.
-	return StartPrompt(db, mergedConfig.User, mergedConfig.Host, mergedConfig.Port, mergedConfig.Database, zstdCompressionLevel, aiServerURL, aiServerMode, aiCachePath, aiDetailLevel)
+// SetExecuteAnalysis turns on the AI analysis (--ai) and visual explain
+// (--visual) of an EXPLAIN run with -e whatever the config says, and with
+// jsonOutput (--json) prints the analysis as one JSON document instead
+func SetExecuteAnalysis(ai, visual, jsonOutput bool) {
+	executeAnalysis.ai, executeAnalysis.visual, executeAnalysis.json = ai, visual, jsonOutput
 }
 
 // executeSQLAndExit executes a SQL command and exits
-func executeSQLAndExit(db *sql.DB, user, host string, port int, database, sql string, aiServerURL, aiServerMode, aiCachePath, aiDetailLevel string) error {
+func executeSQLAndExit(ctx context.Context, db *sql.DB, user, host string, port int, database, sql string, aiServerURL, aiServerMode, aiCachePath, aiMCPCommand, aiDetailLevel string) error {
 	cfg := LoadSyntaxConfig()
 	if aiServerURL == "" {
 		aiServerURL = cfg.AiServerURL
@@ -93,7 +205,13 @@ func executeSQLAndExit(db *sql.DB, user, host string, port int, database, sql st
 	if aiCachePath == "" {
 		aiCachePath = cfg.AiCachePath
 	}
+	if aiMCPCommand == "" {
+		aiMCPCommand = cfg.AiMCPCommand
+	}
+	ctx, cancel := context.WithCancel(ctx)
 	executor := &PromptExecutor{
+		ctx:                  ctx,
+		cancel:               cancel,
 		db:                   db,
 		user:                 user,
 		host:                 host,
@@ -109,23 +227,60 @@ func executeSQLAndExit(db *sql.DB, user, host string, port int, database, sql st
 		enableAIAnalysis:     cfg.EnableAIAnalysis,
 		enableJSONExport:     cfg.EnableJSONExport,
 		enableVisualExplain:  cfg.EnableVisualExplain,
+		autoView:             cfg.AutoView,
+		maxFieldWidth:        cfg.MaxFieldWidth,
+		buffers:              resultBuffers{limit: cfg.ResultBuffers},
+		showCost:             cfg.QueryCost,
+		lag:                  lagSettings{Max: cfg.MaxReplicaLag, Replicas: cfg.LagReplicas, Heartbeat: cfg.HeartbeatTable, Wait: cfg.ReplicaLagWait},
+		colorResults:         cfg.ColorResults,
+		liveHighlight:        cfg.LiveHighlight,
+		format:               cfg.Format,
+		broadcast:            broadcastTargets,
 		zstdCompressionLevel: 0, // Not used in non-interactive mode
 		aiServerURL:          aiServerURL,
 		aiServerMode:         aiServerMode,
 		aiCachePath:          aiCachePath,
+		aiMCPCommand:         aiMCPCommand,
 		aiDetailLevel:        aiDetailLevel,
 	}
 
+	defer executor.closeAIClient()
+	defer executor.shutdown()
+	executor.enableAIAnalysis = executor.enableAIAnalysis || executeAnalysis.ai
+	executor.enableVisualExplain = executor.enableVisualExplain || executeAnalysis.visual
+	if executeAnalysis.json && isExplainQuery(sql) {
+		return executor.printExplainJSON(sql)
+	}
+
 	// Execute the SQL command
-	executor.ExecuteSQL(sql, false)
+	stmtCtx, stop := executor.statementContext()
+	defer stop()
+	executor.ExecuteSQL(stmtCtx, sql, false)
+	if executor.interrupted {
+		return errCanceled
+	}
 	return nil
 }
 
+// printExplainJSON writes the report of `go-mycli explain` for an EXPLAIN
+// given to -e --json, with the visual explain when it is on
+func (p *PromptExecutor) printExplainJSON(explainStmt string) error {
+	ctx, stop := p.statementContext()
+	defer stop()
+	res, err := (&Executor{p: p}).Execute(ctx, explainStmt)
+	if err != nil {
+		return err
+	}
+	return writeExplainReport(os.Stdout, res.Explain, "json")
+}
+
 // BuildDSN builds the MySQL DSN string
 func BuildDSN(user, password, host string, port int, database, socket string, zstdCompressionLevel int) string {
 	dsn := ""
 	if socket != "" {
-		dsn = fmt.Sprintf("%s:%s@unix(%s)/%s", user, password, socket, database)
+		dsn = fmt.Sprintf("%s:%s@%s(%s)/%s", user, password, socketNetwork, socket, database)
+	} else if cloudSQL.Instance != "" && host == cloudSQL.Instance {
+		dsn = fmt.Sprintf("%s:%s@%s(%s)/%s", user, password, cloudSQLNetwork, host, database)
 	} else {
 		dsn = fmt.Sprintf("%s:%s@tcp(%s:%d)/%s", user, password, host, port, database)
 	}
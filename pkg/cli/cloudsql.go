@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"cloud.google.com/go/cloudsqlconn"
+	"github.com/go-sql-driver/mysql"
+)
+
+// cloudSQLNetwork is the go-sql-driver network the Cloud SQL dialer is
+// registered as
+const cloudSQLNetwork = "cloudsql"
+
+// cloudSQL is the instance named with --cloudsql-instance, and whether to
+// log in with IAM (--cloudsql-iam) and dial its private IP
+// (--cloudsql-private-ip)
+var cloudSQL struct {
+	Instance  string
+	IAM       bool
+	PrivateIP bool
+}
+
+// SetCloudSQL sets the Cloud SQL instance to connect to through the
+// connector (--cloudsql-instance project:region:instance)
+func SetCloudSQL(instance string, iam, privateIP bool) {
+	cloudSQL.Instance, cloudSQL.IAM, cloudSQL.PrivateIP = strings.TrimSpace(instance), iam, privateIP
+}
+
+// cloudSQLInstance is an instance connection name taken apart. Projects of
+// a Google Workspace domain carry it: example.com:project:region:instance.
+type cloudSQLInstance struct {
+	Project, Region, Name string
+}
+
+func parseCloudSQLInstance(name string) (cloudSQLInstance, error) {
+	parts := strings.Split(name, ":")
+	if len(parts) == 4 {
+		parts = []string{parts[0] + ":" + parts[1], parts[2], parts[3]}
+	}
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return cloudSQLInstance{}, fmt.Errorf("--cloudsql-instance wants project:region:instance, got %q", name)
+	}
+	return cloudSQLInstance{Project: parts[0], Region: parts[1], Name: parts[2]}, nil
+}
+
+func (i cloudSQLInstance) String() string {
+	return i.Project + ":" + i.Region + ":" + i.Name
+}
+
+// setupCloudSQL registers the Cloud SQL connector's dialer with the driver
+// and returns the user to log in as. The connector gets short-lived client
+// certificates with Application Default Credentials and renews them, so no
+// proxy runs and the instance needs no authorized networks. With
+// --cloudsql-iam the certificates carry the IAM login, which the connector
+// keeps fresh, so no password is needed.
+func setupCloudSQL(ctx context.Context, user string) (string, error) {
+	if _, err := parseCloudSQLInstance(cloudSQL.Instance); err != nil {
+		return "", err
+	}
+	if cloudSQL.IAM {
+		if user == "" {
+			return "", fmt.Errorf("--cloudsql-iam needs the IAM database user (-u), the account's email")
+		}
+		user = iamDatabaseUser(user)
+	}
+
+	var opts []cloudsqlconn.Option
+	if cloudSQL.IAM {
+		opts = append(opts, cloudsqlconn.WithIAMAuthN())
+	}
+	if cloudSQL.PrivateIP {
+		opts = append(opts, cloudsqlconn.WithDefaultDialOptions(cloudsqlconn.WithPrivateIP()))
+	}
+	d, err := cloudsqlconn.NewDialer(ctx, opts...)
+	if err != nil {
+		return "", fmt.Errorf("cloud SQL connector: %w", err)
+	}
+	// The address is the instance connection name BuildDSN puts in the DSN
+	mysql.RegisterDialContext(cloudSQLNetwork, func(ctx context.Context, addr string) (net.Conn, error) {
+		return d.Dial(ctx, addr)
+	})
+	return user, nil
+}
+
+// iamDatabaseUser is the MySQL user of an IAM account, which Cloud SQL for
+// MySQL names after the email up to the @
+func iamDatabaseUser(account string) string {
+	name, _, _ := strings.Cut(account, "@")
+	return name
+}
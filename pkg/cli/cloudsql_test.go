@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseCloudSQLInstance(t *testing.T) {
+	tests := map[string]cloudSQLInstance{
+		"shop-prod:us-central1:orders":             {Project: "shop-prod", Region: "us-central1", Name: "orders"},
+		"example.com:shop-prod:europe-west1:users": {Project: "example.com:shop-prod", Region: "europe-west1", Name: "users"},
+	}
+	for name, want := range tests {
+		got, err := parseCloudSQLInstance(name)
+		if err != nil || got != want {
+			t.Errorf("parseCloudSQLInstance(%q) = %+v, %v, want %+v", name, got, err, want)
+		}
+		if got.String() != name {
+			t.Errorf("String() = %q, want %q", got.String(), name)
+		}
+	}
+	for _, bad := range []string{"", "orders", "shop-prod:orders", "shop-prod::orders", "a:b:c:d:e"} {
+		if _, err := parseCloudSQLInstance(bad); err == nil {
+			t.Errorf("parseCloudSQLInstance(%q) should fail", bad)
+		}
+	}
+}
+
+func TestIAMDatabaseUser(t *testing.T) {
+	tests := map[string]string{
+		"dana@example.com": "dana",
+		"reporting@shop-prod.iam.gserviceaccount.com": "reporting",
+	}
+	for account, want := range tests {
+		if got := iamDatabaseUser(account); got != want {
+			t.Errorf("iamDatabaseUser(%q) = %q, want %q", account, got, want)
+		}
+	}
+}
+
+func TestSetupCloudSQLIAMNeedsUser(t *testing.T) {
+	defer SetCloudSQL("", false, false)
+	SetCloudSQL("shop-prod:us-central1:orders", true, false)
+	if _, err := setupCloudSQL(context.Background(), ""); err == nil {
+		t.Error("IAM login without a user should fail before dialing")
+	}
+	SetCloudSQL("orders", false, false)
+	if _, err := setupCloudSQL(context.Background(), "app"); err == nil {
+		t.Error("an instance name without project and region should fail")
+	}
+}
+
+func TestBuildDSNCloudSQL(t *testing.T) {
+	defer SetCloudSQL("", false, false)
+	SetCloudSQL("shop-prod:us-central1:orders", false, false)
+	got := BuildDSN("app", "secret", "shop-prod:us-central1:orders", 3306, "shop", "", 0)
+	if want := "app:secret@cloudsql(shop-prod:us-central1:orders)/shop"; got != want {
+		t.Errorf("BuildDSN = %q, want %q", got, want)
+	}
+	if got := BuildDSN("app", "secret", "replica1", 3306, "shop", "", 0); got != "app:secret@tcp(replica1:3306)/shop" {
+		t.Errorf("BuildDSN of another host = %q", got)
+	}
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// noColor disables all ANSI colors: syntax highlighting, result coloring and
+// the full-screen browsers. See https://no-color.org.
+var noColor = os.Getenv("NO_COLOR") != ""
+
+func init() {
+	if noColor {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+// SetNoColor turns colored output off (--no-color). NO_COLOR in the
+// environment always wins, so passing false never re-enables colors.
+func SetNoColor(disable bool) {
+	if !disable {
+		return
+	}
+	noColor = true
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
+
+// treeGlyphs draw the branches of plan and replication trees
+var treeGlyphs = struct{ Branch, Last, Line string }{"├── ", "└── ", "│   "}
+
+// legacyConsole degrades output for a console that shows neither ANSI
+// colors nor box drawing, such as Windows consoles before Windows 10
+func legacyConsole() {
+	noColor = true
+	lipgloss.SetColorProfile(termenv.Ascii)
+	treeGlyphs.Branch, treeGlyphs.Last, treeGlyphs.Line = "|-- ", "`-- ", "|   "
+}
+
+// colorEnabled reports whether output may contain ANSI colors
+func colorEnabled() bool {
+	return !noColor
+}
+
+// lightBackground resolves the background setting. "auto" uses COLORFGBG,
+// which many terminals (rxvt, Konsole, iTerm2, ...) export; without it the
+// background is assumed to be dark.
+func lightBackground(setting string) bool {
+	switch setting {
+	case "light":
+		return true
+	case "dark":
+		return false
+	}
+	light, _ := colorfgbgIsLight(os.Getenv("COLORFGBG"))
+	return light
+}
+
+// colorfgbgIsLight parses COLORFGBG ("fg;bg" or "fg;default;bg"). Background
+// colors 7 (white) and 9-15 except 8 are light.
+func colorfgbgIsLight(value string) (light, ok bool) {
+	parts := strings.Split(value, ";")
+	if len(parts) < 2 {
+		return false, false
+	}
+	bg, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return false, false
+	}
+	return bg == 7 || (bg > 8 && bg <= 15), true
+}
+
+// adaptToBackground switches an untouched dark default configuration to the
+// built-in light style and colors when the terminal background is light
+func adaptToBackground(config *SyntaxConfig) {
+	light := lightBackground(config.Background)
+	lipgloss.SetHasDarkBackground(!light)
+	if !light {
+		return
+	}
+	if config.Style == "" || strings.EqualFold(config.Style, "monokai") {
+		config.Style = "light"
+	}
+	if reflect.DeepEqual(config.Colors, DefaultColors()) {
+		config.Colors = DefaultLightColors()
+	}
+}
+
+// promptColorOptions returns go-prompt options for the current color mode
+func promptColorOptions() []prompt.Option {
+	if colorEnabled() {
+		return nil
+	}
+	return []prompt.Option{
+		prompt.OptionPrefixTextColor(prompt.DefaultColor),
+		prompt.OptionInputTextColor(prompt.DefaultColor),
+		prompt.OptionPreviewSuggestionTextColor(prompt.DefaultColor),
+		prompt.OptionSuggestionTextColor(prompt.DefaultColor),
+		prompt.OptionSuggestionBGColor(prompt.DefaultColor),
+		prompt.OptionSelectedSuggestionTextColor(prompt.DefaultColor),
+		prompt.OptionSelectedSuggestionBGColor(prompt.DefaultColor),
+		prompt.OptionDescriptionTextColor(prompt.DefaultColor),
+		prompt.OptionDescriptionBGColor(prompt.DefaultColor),
+		prompt.OptionSelectedDescriptionTextColor(prompt.DefaultColor),
+		prompt.OptionSelectedDescriptionBGColor(prompt.DefaultColor),
+		prompt.OptionScrollbarThumbColor(prompt.DefaultColor),
+		prompt.OptionScrollbarBGColor(prompt.DefaultColor),
+	}
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestColorfgbgIsLight(t *testing.T) {
+	tests := []struct {
+		value     string
+		light, ok bool
+	}{
+		{"15;0", false, true},
+		{"0;15", true, true},
+		{"0;default;7", true, true},
+		{"7;8", false, true},
+		{"", false, false},
+		{"garbage", false, false},
+	}
+	for _, tt := range tests {
+		light, ok := colorfgbgIsLight(tt.value)
+		if light != tt.light || ok != tt.ok {
+			t.Errorf("colorfgbgIsLight(%q) = %v, %v; want %v, %v", tt.value, light, ok, tt.light, tt.ok)
+		}
+	}
+}
+
+func TestAdaptToBackground(t *testing.T) {
+	cfg := DefaultSyntaxConfig()
+	cfg.Background = "light"
+	adaptToBackground(cfg)
+	if cfg.Style != "light" || !reflect.DeepEqual(cfg.Colors, DefaultLightColors()) {
+		t.Errorf("default config not switched to light: style=%s", cfg.Style)
+	}
+
+	// An explicit style and custom colors are kept
+	cfg = DefaultSyntaxConfig()
+	cfg.Background = "light"
+	cfg.Style = "dracula"
+	cfg.Colors["keyword"] = "#123456"
+	adaptToBackground(cfg)
+	if cfg.Style != "dracula" || cfg.Colors["keyword"] != "#123456" {
+		t.Errorf("user choices overridden: style=%s keyword=%s", cfg.Style, cfg.Colors["keyword"])
+	}
+
+	t.Setenv("COLORFGBG", "0;15")
+	cfg = DefaultSyntaxConfig()
+	cfg.Background = "dark"
+	adaptToBackground(cfg)
+	if cfg.Style != "monokai" {
+		t.Errorf("background=dark should ignore COLORFGBG, got style %s", cfg.Style)
+	}
+}
+
+func TestHighlightSQLHonorsNoColor(t *testing.T) {
+	saved := noColor
+	defer func() { noColor = saved }()
+
+	noColor = true
+	sql := "SELECT 1 FROM dual"
+	if got := NewSyntaxHighlighter().HighlightSQL(sql); got != sql {
+		t.Errorf("HighlightSQL with colors off = %q", got)
+	}
+	if opts := promptColorOptions(); len(opts) == 0 {
+		t.Errorf("expected go-prompt color overrides when colors are off")
+	}
+}
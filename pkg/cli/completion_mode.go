@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Completion modes (the completion setting). They control how much schema
+// metadata is fetched from the server for completion.
+const (
+	completionAuto     = "auto"     // full, or off when the server is slow to answer
+	completionFull     = "full"     // databases, tables and the columns of every table
+	completionMetadata = "metadata" // databases and tables only, no per-table DESCRIBE
+	completionOff      = "off"      // keywords only, no queries at all
+)
+
+// Completion matching (the completion_match setting): how the word typed is
+// matched against suggestions
+const (
+	matchFuzzy     = "fuzzy"     // its letters in order, so "usid" finds user_id
+	matchSubstring = "substring" // the word anywhere in the suggestion
+	matchPrefix    = "prefix"    // suggestions starting with the word
+)
+
+// defaultCompletionLatency is the round trip above which auto mode stops
+// fetching metadata
+const defaultCompletionLatency = 150 * time.Millisecond
+
+// parseCompletionMode normalizes a completion setting, accepting
+// "metadata-only" for metadata
+func parseCompletionMode(value string) (string, bool) {
+	mode := strings.ToLower(strings.TrimSpace(value))
+	switch mode {
+	case completionAuto, completionFull, completionMetadata, completionOff:
+		return mode, true
+	case "metadata-only":
+		return completionMetadata, true
+	}
+	return "", false
+}
+
+// parseCompletionMatch normalizes a completion_match setting
+func parseCompletionMatch(value string) (string, bool) {
+	match := strings.ToLower(strings.TrimSpace(value))
+	switch match {
+	case matchFuzzy, matchSubstring, matchPrefix:
+		return match, true
+	}
+	return "", false
+}
+
+// activeCompletion returns the completion mode in effect, resolving auto
+// with the last latency measurement
+func (p *PromptExecutor) activeCompletion() string {
+	switch p.completionMode {
+	case completionAuto:
+		if p.slowLink {
+			return completionOff
+		}
+		return completionFull
+	case "":
+		return completionFull
+	}
+	return p.completionMode
+}
+
+// measureLatency times a trivial query and, in auto mode, records whether
+// the link is too slow for metadata completion. It returns the round trip,
+// or 0 if the query failed.
+func (p *PromptExecutor) measureLatency() time.Duration {
+	start := time.Now()
+	var one int
+	if err := p.db.QueryRow("SELECT 1").Scan(&one); err != nil {
+		return 0
+	}
+	rtt := time.Since(start)
+	p.lastLatency = rtt
+	if p.completionMode == completionAuto {
+		p.slowLink = rtt >= p.latencyLimit()
+	}
+	return rtt
+}
+
+func (p *PromptExecutor) latencyLimit() time.Duration {
+	if p.completionLatency > 0 {
+		return p.completionLatency
+	}
+	return defaultCompletionLatency
+}
+
+// checkLatency runs at startup so a slow link is reported once, before the
+// first completion would stall on the DESCRIBE of every table
+func (p *PromptExecutor) checkLatency() {
+	if p.completionMode != completionAuto {
+		return
+	}
+	if rtt := p.measureLatency(); p.slowLink {
+		fmt.Printf("Server round trip is %s: completing keywords only. Use \\completion full to load the schema anyway.\n",
+			rtt.Round(time.Millisecond))
+	}
+}
+
+// setCompletion handles \completion [auto|full|metadata|off]
+func (p *PromptExecutor) setCompletion(args string) {
+	if strings.TrimSpace(args) == "" {
+		fmt.Printf("Completion: %s (active: %s)\n", p.completionModeName(), p.activeCompletion())
+		if p.lastLatency > 0 {
+			fmt.Printf("Last round trip: %s (auto limit %s)\n", p.lastLatency.Round(time.Millisecond), p.latencyLimit())
+		}
+		return
+	}
+	mode, ok := parseCompletionMode(args)
+	if !ok {
+		fmt.Printf("Unknown completion mode: %s (use auto, full, metadata or off)\n", strings.TrimSpace(args))
+		return
+	}
+
+	p.completionMode = mode
+	p.slowLink = false
+	// Reload the cache for the new mode on the next completion
+	p.cacheTime = time.Time{}
+	p.tables, p.columns, p.databases = nil, make(map[string][]string), nil
+	fmt.Printf("Completion now %s\n", mode)
+
+	// Persist change to user config file
+	saveSetting("completion", mode)
+}
+
+func (p *PromptExecutor) completionModeName() string {
+	if p.completionMode == "" {
+		return completionFull
+	}
+	return p.completionMode
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c-bata/go-prompt"
+)
+
+func TestActiveCompletion(t *testing.T) {
+	tests := []struct {
+		mode     string
+		slowLink bool
+		want     string
+	}{
+		{"", false, completionFull},
+		{completionAuto, false, completionFull},
+		{completionAuto, true, completionOff},
+		{completionMetadata, true, completionMetadata},
+		{completionOff, false, completionOff},
+	}
+	for _, tt := range tests {
+		p := &PromptExecutor{completionMode: tt.mode, slowLink: tt.slowLink}
+		if got := p.activeCompletion(); got != tt.want {
+			t.Errorf("activeCompletion(%q, slow=%v) = %q, want %q", tt.mode, tt.slowLink, got, tt.want)
+		}
+	}
+}
+
+func TestCompletionOffSuggestsKeywordsOnly(t *testing.T) {
+	// No database is needed: off mode never queries the server
+	p := &PromptExecutor{completionMode: completionOff, columns: map[string][]string{}}
+
+	suggestions := p.Completer(*prompt.NewBuffer().Document())
+	if len(suggestions) != 0 {
+		t.Errorf("empty line should have no suggestions, got %d", len(suggestions))
+	}
+
+	buf := prompt.NewBuffer()
+	buf.InsertText("SELECT * FROM ", false, true)
+	if got := p.Completer(*buf.Document()); len(got) != 0 {
+		t.Errorf("off mode should not suggest tables after FROM, got %v", got)
+	}
+
+	buf.InsertText("WHE", false, true)
+	found := false
+	for _, s := range p.Completer(*buf.Document()) {
+		if !strings.HasSuffix(s.Description, "keyword") {
+			t.Errorf("unexpected non-keyword suggestion %+v", s)
+		}
+		found = found || s.Text == "WHERE"
+	}
+	if !found {
+		t.Errorf("expected keyword WHERE to be suggested")
+	}
+}
+
+func TestLoadSyntaxConfigCompletionMode(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), ".go-myclirc")
+	content := "[main]\ncompletion = Metadata-Only\ncompletion_latency = 80ms\n"
+	if err := os.WriteFile(rc, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GO_MYCLI_RC", rc)
+
+	cfg := LoadSyntaxConfig()
+	if cfg.CompletionMode != completionMetadata || cfg.CompletionLatency != 80*time.Millisecond {
+		t.Errorf("completion = %q, completion_latency = %s", cfg.CompletionMode, cfg.CompletionLatency)
+	}
+
+	if err := os.WriteFile(rc, []byte("[main]\ncompletion = sometimes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if cfg := LoadSyntaxConfig(); cfg.CompletionMode != completionAuto {
+		t.Errorf("unknown completion mode should fall back to auto, got %q", cfg.CompletionMode)
+	}
+}
+
+func TestCompletionMatch(t *testing.T) {
+	suggestions := []prompt.Suggest{{Text: "user_id"}, {Text: "users"}, {Text: "last_user"}, {Text: "status_id"}}
+	texts := func(p *PromptExecutor, word string) string {
+		var got []string
+		for _, s := range p.findMatches(word, suggestions) {
+			got = append(got, s.Text)
+		}
+		return strings.Join(got, ",")
+	}
+	tests := []struct {
+		match    string
+		minScore int
+		word     string
+		want     string
+	}{
+		{matchFuzzy, 0, "usid", "user_id,status_id"},
+		{matchFuzzy, 985, "usid", "user_id"},
+		{"", 0, "user", "users,user_id,last_user"},
+		{matchSubstring, 0, "user", "users,user_id,last_user"},
+		{matchSubstring, 0, "usid", ""},
+		{matchPrefix, 0, "user", "users,user_id"},
+		{matchPrefix, 0, "USER", "users,user_id"},
+	}
+	for _, tt := range tests {
+		p := &PromptExecutor{completionMatch: tt.match, completionMinScore: tt.minScore}
+		if got := texts(p, tt.word); got != tt.want {
+			t.Errorf("%s (min %d) %q = %q, want %q", tt.match, tt.minScore, tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestLoadSyntaxConfigCompletionMatch(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), ".go-myclirc")
+	if err := os.WriteFile(rc, []byte("[main]\ncompletion_match = Prefix\ncompletion_min_score = 900\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GO_MYCLI_RC", rc)
+
+	cfg := LoadSyntaxConfig()
+	if cfg.CompletionMatch != matchPrefix || cfg.CompletionMinScore != 900 {
+		t.Errorf("completion_match = %q, completion_min_score = %d", cfg.CompletionMatch, cfg.CompletionMinScore)
+	}
+
+	if err := os.WriteFile(rc, []byte("[main]\ncompletion_match = loose\ncompletion_min_score = -5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if cfg := LoadSyntaxConfig(); cfg.CompletionMatch != matchFuzzy || cfg.CompletionMinScore != 0 {
+		t.Errorf("invalid settings should keep the defaults, got %q, %d", cfg.CompletionMatch, cfg.CompletionMinScore)
+	}
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 
@@ -25,21 +26,8 @@ func ReadMySQLConfig(loginPath, configFilePath string) (*MySQLConfig, error) {
 		Port: 0, // 0 means not set, will default to 3306 later
 	}
 
-	// Config files to read in order (later ones override earlier ones)
-	configFiles := []string{
-		"/etc/my.cnf",
-		"/etc/mysql/my.cnf",
-		"/usr/local/etc/my.cnf",
-		filepath.Join(os.Getenv("HOME"), ".my.cnf"),
-	}
-
-	// Add custom config file if specified (highest priority)
-	if configFilePath != "" {
-		configFiles = append(configFiles, configFilePath)
-	}
-
 	// Read each config file
-	for _, configFile := range configFiles {
+	for _, configFile := range optionFiles(configFilePath) {
 		if err := readConfigFile(configFile, config, loginPath); err != nil {
 			// Skip files that don't exist or can't be read
 			continue
@@ -49,6 +37,33 @@ func ReadMySQLConfig(loginPath, configFilePath string) (*MySQLConfig, error) {
 	return config, nil
 }
 
+// optionFiles lists the config files to read in order (later ones override
+// earlier ones), with the custom config file, if any, last
+func optionFiles(configFilePath string) []string {
+	configFiles := []string{
+		"/etc/my.cnf",
+		"/etc/mysql/my.cnf",
+		"/usr/local/etc/my.cnf",
+	}
+	if runtime.GOOS == "windows" {
+		// Where the mysql client looks on Windows
+		windir := os.Getenv("WINDIR")
+		configFiles = []string{
+			filepath.Join(windir, "my.ini"),
+			filepath.Join(windir, "my.cnf"),
+			`C:\my.ini`,
+			`C:\my.cnf`,
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		configFiles = append(configFiles, filepath.Join(home, ".my.cnf"))
+	}
+	if configFilePath != "" {
+		configFiles = append(configFiles, configFilePath)
+	}
+	return configFiles
+}
+
 // readConfigFile reads a single MySQL config file
 func readConfigFile(filename string, config *MySQLConfig, loginPath string) error {
 	// Check if file exists
@@ -137,8 +152,45 @@ func StripMatchingQuotes(s string) string {
 	return s
 }
 
-// MergeConfig merges config values with command line arguments
-// Command line arguments take precedence over config file values
+// Environment variables for connection settings, in order of precedence.
+// GO_MYCLI_* override the variables the mysql client also reads.
+var (
+	envHost     = []string{"GO_MYCLI_HOST", "MYSQL_HOST"}
+	envPort     = []string{"GO_MYCLI_PORT", "MYSQL_TCP_PORT"}
+	envUser     = []string{"GO_MYCLI_USER", "MYSQL_USER"}
+	envPassword = []string{"GO_MYCLI_PASSWORD", "MYSQL_PWD"}
+	envSocket   = []string{"GO_MYCLI_SOCKET", "MYSQL_UNIX_PORT"}
+	envDatabase = []string{"GO_MYCLI_DATABASE", "MYSQL_DATABASE"}
+)
+
+// lookupEnv returns the first of names that is set to a non-empty value
+func lookupEnv(names []string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// EnvConfig reads connection settings from the environment
+func EnvConfig() *MySQLConfig {
+	config := &MySQLConfig{
+		User:     lookupEnv(envUser),
+		Password: lookupEnv(envPassword),
+		Host:     lookupEnv(envHost),
+		Socket:   lookupEnv(envSocket),
+		Database: lookupEnv(envDatabase),
+	}
+	if port, err := strconv.Atoi(lookupEnv(envPort)); err == nil && port > 0 {
+		config.Port = port
+	}
+	return config
+}
+
+// MergeConfig merges config values with environment variables and command
+// line arguments. Command line arguments take precedence over the
+// environment, which takes precedence over config file values.
 func MergeConfig(config *MySQLConfig, cliUser, cliPassword, cliHost string, cliPort int, cliSocket, cliDatabase string) *MySQLConfig {
 	merged := &MySQLConfig{
 		User:     config.User,
@@ -149,6 +201,27 @@ func MergeConfig(config *MySQLConfig, cliUser, cliPassword, cliHost string, cliP
 		Database: config.Database,
 	}
 
+	// Override with environment variables if set
+	env := EnvConfig()
+	if env.User != "" {
+		merged.User = env.User
+	}
+	if env.Password != "" {
+		merged.Password = env.Password
+	}
+	if env.Host != "" {
+		merged.Host = env.Host
+	}
+	if env.Port != 0 {
+		merged.Port = env.Port
+	}
+	if env.Socket != "" {
+		merged.Socket = env.Socket
+	}
+	if env.Database != "" {
+		merged.Database = env.Database
+	}
+
 	// Override with CLI values if provided
 	if cliUser != "" {
 		merged.User = cliUser
@@ -173,6 +246,10 @@ func MergeConfig(config *MySQLConfig, cliUser, cliPassword, cliHost string, cliP
 	if merged.User == "" {
 		merged.User = os.Getenv("USER")
 	}
+	if merged.User == "" {
+		// Windows
+		merged.User = os.Getenv("USERNAME")
+	}
 	if merged.Host == "" && merged.Socket == "" {
 		merged.Host = "localhost"
 	}
@@ -182,7 +259,7 @@ func MergeConfig(config *MySQLConfig, cliUser, cliPassword, cliHost string, cliP
 
 	// If both host and socket are configured, prefer TCP connection
 	// Only clear socket if host was explicitly set (not defaulted to localhost)
-	if cliHost != "" || config.Host != "" {
+	if cliHost != "" || env.Host != "" || config.Host != "" {
 		if merged.Socket != "" {
 			merged.Socket = ""
 		}
@@ -0,0 +1,551 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// configProblem is a key of ~/.go-myclirc that is ignored or falls back to
+// its default, with the line it is on
+type configProblem struct {
+	Line    int
+	Message string
+}
+
+// configCheck validates the value of one key, returning why it is not used
+type configCheck func(value string) string
+
+// freeForm marks a section whose key names are the user's own, such as
+// [aliases]; the function, when there is one, checks a key and its value
+type freeForm func(key, value string) string
+
+var (
+	checkBool configCheck = func(v string) string {
+		switch strings.ToLower(v) {
+		case "1", "t", "true", "yes", "y", "on", "0", "f", "false", "no", "n", "off":
+			return ""
+		}
+		return fmt.Sprintf("%q is not true or false", v)
+	}
+	checkCount configCheck = func(v string) string {
+		if n, err := strconv.ParseInt(v, 10, 64); err != nil || n < 0 {
+			return fmt.Sprintf("%q is not a whole number of 0 or more", v)
+		}
+		return ""
+	}
+	checkDuration configCheck = func(v string) string {
+		if d, err := time.ParseDuration(v); err != nil || d < 0 {
+			return fmt.Sprintf("%q is not a duration such as 500ms, 30s or 5m", v)
+		}
+		return ""
+	}
+	checkAny configCheck = func(string) string { return "" }
+)
+
+// checkChoice accepts what parse accepts, listing the choices otherwise
+func checkChoice(parse func(string) (string, bool), choices ...string) configCheck {
+	return func(v string) string {
+		if _, ok := parse(v); ok {
+			return ""
+		}
+		return fmt.Sprintf("%q is not one of %s", v, strings.Join(choices, ", "))
+	}
+}
+
+// oneOf is a parse function for a fixed list of values, ignoring case
+func oneOf(values ...string) func(string) (string, bool) {
+	return func(v string) (string, bool) {
+		for _, value := range values {
+			if strings.EqualFold(strings.TrimSpace(v), value) {
+				return value, true
+			}
+		}
+		return "", false
+	}
+}
+
+func checkStyle(v string) string {
+	if strings.EqualFold(v, "smooth") || strings.EqualFold(v, "light") || styles.Get(v) != styles.Fallback || strings.EqualFold(v, styles.Fallback.Name) {
+		return ""
+	}
+	return fmt.Sprintf("unknown style %q (see https://github.com/alecthomas/chroma#styles)", v)
+}
+
+func checkOutputFormat(v string) string {
+	if _, err := NewRenderer(strings.ToLower(v)); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func checkURL(v string) string {
+	if u, err := url.Parse(v); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Sprintf("%q is not an http:// or https:// URL", v)
+	}
+	return ""
+}
+
+func checkOptionalURL(v string) string {
+	if v == "" {
+		return ""
+	}
+	return checkURL(v)
+}
+
+func checkTimezone(v string) string {
+	if v == "" {
+		return ""
+	}
+	if _, err := time.LoadLocation(v); err != nil {
+		return fmt.Sprintf("unknown time zone %q", v)
+	}
+	return ""
+}
+
+func checkPopupColor(v string) string {
+	if v == "" {
+		return ""
+	}
+	if _, ok := parsePopupColor(v); !ok {
+		return fmt.Sprintf("unknown color %q", v)
+	}
+	return ""
+}
+
+func checkTokenColor(v string) string {
+	v = sanitizeColorValue(v)
+	if v == "" {
+		return ""
+	}
+	if _, err := chroma.ParseStyleEntry(v); err != nil {
+		return fmt.Sprintf("%q is not a color such as #66D9EF or bold #F92672", v)
+	}
+	return ""
+}
+
+// configSchema is every key ~/.go-myclirc reads, by section
+var configSchema = map[string]map[string]configCheck{
+	"main": {
+		"syntax_style":         checkStyle,
+		"background":           checkChoice(oneOf("auto", "dark", "light"), "auto", "dark", "light"),
+		"use_custom_colors":    checkBool,
+		"suggestions":          checkBool,
+		"ai_analysis":          checkBool,
+		"json_export":          checkBool,
+		"visual_explain":       checkBool,
+		"auto_view":            checkBool,
+		"max_field_width":      checkCount,
+		"result_buffers":       checkCount,
+		"query_cost":           checkBool,
+		"show_warnings":        checkBool,
+		"output_format":        checkOutputFormat,
+		"pager":                checkAny,
+		"enable_pager":         checkBool,
+		"language":             checkChoice(oneOf(languageAuto, languageEnglish, languageSpanish), languageAuto, languageEnglish, languageSpanish),
+		"color_results":        checkBool,
+		"live_highlighting":    checkBool,
+		"rank_completions":     checkBool,
+		"completion":           checkChoice(parseCompletionMode, completionAuto, completionFull, completionMetadata, completionOff),
+		"completion_latency":   positiveDuration,
+		"completion_match":     checkChoice(parseCompletionMatch, matchFuzzy, matchSubstring, matchPrefix),
+		"completion_min_score": checkCount,
+		"completion_values":    checkBool,
+		"keepalive":            checkDuration,
+		"row_estimate_warning": checkCount,
+		"live_timer":           checkBool,
+		"long_query_alert":     checkDuration,
+		"long_query_notify":    checkChoice(parseNotify, notifyBell, notifyDesktop, notifyBoth),
+		"syntax_check":         checkBool,
+		"max_replica_lag":      checkDuration,
+		"lag_replicas":         checkAny,
+		"heartbeat_table":      checkAny,
+		"replica_lag_wait":     checkBool,
+		"ai_server_url":        checkURL,
+		"ai_server_mode":       checkChoice(oneOf("copilot_mcp_http", "openai", "mcp_stdio"), "copilot_mcp_http", "openai", "mcp_stdio"),
+		"ai_cache_path":        checkAny,
+		"ai_mcp_command":       checkAny,
+	},
+	"hooks": {
+		"pre_query":    checkAny,
+		"post_query":   checkAny,
+		"webhook_url":  checkOptionalURL,
+		"min_duration": checkDuration,
+		"timeout":      checkDuration,
+	},
+	"format": {
+		"timezone":        checkTimezone,
+		"datetime_format": checkAny,
+		"numbers":         checkChoice(oneOf("", "thousands", "si"), "thousands", "si"),
+	},
+	"completion": {
+		"max_rows":     checkCount,
+		"descriptions": checkBool,
+	},
+	"colors": {
+		"keyword":     checkTokenColor,
+		"name":        checkTokenColor,
+		"builtin":     checkTokenColor,
+		"string":      checkTokenColor,
+		"number":      checkTokenColor,
+		"operator":    checkTokenColor,
+		"comment":     checkTokenColor,
+		"punctuation": checkTokenColor,
+	},
+}
+
+// freeFormSections are the sections whose key names are the user's own
+var freeFormSections = map[string]freeForm{
+	"session":  nil,
+	"aliases":  nil,
+	"runbooks": nil,
+	"prompt": func(key, value string) string {
+		if _, err := parsePromptRule(key, value); err != nil {
+			return err.Error()
+		}
+		return ""
+	},
+}
+
+func init() {
+	for _, k := range (&PopupConfig{}).colorSettings() {
+		configSchema["completion"][k.key] = checkPopupColor
+	}
+}
+
+func positiveDuration(v string) string {
+	if d, err := time.ParseDuration(v); err != nil || d <= 0 {
+		return fmt.Sprintf("%q is not a duration above 0 such as 150ms", v)
+	}
+	return ""
+}
+
+// validateConfig reads ~/.go-myclirc the way LoadSyntaxConfig does and
+// reports, by line, the sections and keys it does not know, the values it
+// ignores, and keys set twice, of which the last one is used
+func validateConfig(data []byte) []configProblem {
+	var problems []configProblem
+	add := func(line int, format string, args ...any) {
+		problems = append(problems, configProblem{Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+	section := ""
+	seen := map[string]int{} // section.key -> line
+	for i, raw := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		line := strings.TrimSpace(raw)
+		n := i + 1
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				add(n, "section header %s has no closing ]", line)
+				section = ""
+				continue
+			}
+			section = strings.TrimSpace(line[1:end])
+			if _, ok := configSchema[section]; !ok {
+				if _, ok := freeFormSections[section]; !ok {
+					add(n, "unknown section [%s]; its keys are ignored%s", section, suggestion(section, configSections()))
+				}
+			}
+			continue
+		}
+
+		i := strings.IndexAny(line, "=:")
+		if i < 0 {
+			add(n, "%q is not a key = value line", line)
+			continue
+		}
+		key, value := strings.TrimSpace(line[:i]), iniValue(line[i+1:])
+		if section == "" {
+			add(n, "%s is outside any section and is ignored", key)
+			continue
+		}
+		if first, ok := seen[section+"."+key]; ok {
+			add(n, "%s is also set on line %d; this line is the one used", key, first)
+		}
+		seen[section+"."+key] = n
+
+		if check, ok := freeFormSections[section]; ok {
+			if check != nil {
+				if msg := check(key, value); msg != "" {
+					add(n, "%s; the rule is ignored", msg)
+				}
+			}
+			continue
+		}
+		keys, ok := configSchema[section]
+		if !ok {
+			continue
+		}
+		if section == "colors" {
+			// Token names are matched ignoring case
+			key = strings.ToLower(key)
+		}
+		check, ok := keys[key]
+		if !ok {
+			add(n, "unknown key %s in [%s]%s", key, section, suggestion(key, schemaKeys(keys)))
+			continue
+		}
+		if msg := check(value); msg != "" {
+			add(n, "%s: %s; the default is used", key, msg)
+		}
+	}
+	return problems
+}
+
+// iniValue removes the quotes ini allows around a value: backquotes, which
+// the config writers use for colors, and single or double quotes
+func iniValue(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '`' && s[len(s)-1] == '`' {
+		return s[1 : len(s)-1]
+	}
+	return StripMatchingQuotes(s)
+}
+
+// configSections lists the known sections, sorted
+func configSections() []string {
+	var names []string
+	for name := range configSchema {
+		names = append(names, name)
+	}
+	for name := range freeFormSections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// schemaKeys lists the keys of a section, sorted
+func schemaKeys(m map[string]configCheck) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// suggestion returns ", did you mean x?" for the known name closest to name,
+// when one is close enough to be a typo
+func suggestion(name string, known []string) string {
+	best, bestDistance := "", len(name)/2+1
+	for _, k := range known {
+		if d := editDistance(strings.ToLower(name), k); d < bestDistance {
+			best, bestDistance = k, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean %s?", best)
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// checkConfigFile validates the config file at path; a file that does not
+// exist has no problems
+func checkConfigFile(path string) ([]configProblem, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return validateConfig(data), nil
+}
+
+// printConfigProblems prints the problems of the config file at path as
+// path:line: message, reporting whether there were any
+func printConfigProblems(path string, problems []configProblem) bool {
+	for _, c := range problems {
+		fmt.Printf("%s:%d: %s\n", path, c.Line, c.Message)
+	}
+	return len(problems) > 0
+}
+
+// ValidateConfig implements go-mycli config validate: it checks the config
+// file at path, or the one go-mycli reads when path is empty, and returns
+// an ExitStatus of 1 when it has problems
+func ValidateConfig(path string) error {
+	if path == "" {
+		if path = syntaxConfigPath(); path == "" {
+			return fmt.Errorf("no home directory to find ~/.go-myclirc in; give the file to check")
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			fmt.Printf("%s does not exist; go-mycli uses the defaults\n", path)
+			return nil
+		}
+	}
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	problems, err := checkConfigFile(path)
+	if err != nil {
+		return err
+	}
+	if printConfigProblems(path, problems) {
+		return &ExitStatus{Code: 1}
+	}
+	fmt.Printf("%s: ok\n", path)
+	return nil
+}
+
+// editConfig implements \config edit: it opens the config file in $VISUAL
+// or $EDITOR (vi when neither is set), creating it with the defaults first
+// when it is the one in the home directory, and reloads it afterwards
+func (p *PromptExecutor) editConfig() {
+	configPath := syntaxConfigPath()
+	if configPath == "" {
+		fmt.Println("No home directory to keep ~/.go-myclirc in")
+		return
+	}
+	if configPath == homeConfigPath() {
+		if err := SaveDefaultSyntaxConfig(); err != nil {
+			fmt.Printf("Error creating %s: %v\n", configPath, err)
+			return
+		}
+	}
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = defaultEditor
+	}
+	// The editor may carry arguments, as in EDITOR="code --wait"
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], configPath)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("Error running %s: %v\n", editor, err)
+		return
+	}
+	p.reloadConfig()
+}
+
+// reloadConfig implements \config reload: it reports the problems of the
+// config file and applies the settings that can change while connected
+func (p *PromptExecutor) reloadConfig() {
+	configPath := syntaxConfigPath()
+	problems, err := checkConfigFile(configPath)
+	if err != nil {
+		fmt.Printf("Error reading %s: %v\n", configPath, err)
+		return
+	}
+	printConfigProblems(configPath, problems)
+	cfg := LoadSyntaxConfig()
+	restart := p.applyConfig(cfg)
+	fmt.Printf("Reloaded %s", configPath)
+	if len(problems) > 0 {
+		fmt.Printf(" (%d problem%s above)", len(problems), plural(len(problems)))
+	}
+	fmt.Println()
+	if len(restart) > 0 {
+		fmt.Printf("%s take effect when go-mycli is restarted\n", strings.Join(restart, ", "))
+	}
+}
+
+// applyConfig puts the settings of cfg into the session, as startGoPrompt
+// does. The AI server settings are only replaced when they came from the
+// config file rather than the command line. It returns the changed settings
+// that only a restart applies.
+func (p *PromptExecutor) applyConfig(cfg *SyntaxConfig) []string {
+	var restart []string
+	if p.rc != nil {
+		if cfg.LiveHighlight != p.rc.LiveHighlight {
+			restart = append(restart, "live_highlighting")
+		}
+		if cfg.RankCompletions != p.rc.RankCompletions {
+			restart = append(restart, "rank_completions")
+		}
+		if cfg.Popup != p.rc.Popup {
+			restart = append(restart, "[completion]")
+		}
+		if len(cfg.PromptRules) > 0 && len(p.rc.PromptRules) == 0 {
+			restart = append(restart, "[prompt]")
+		}
+		if p.aiServerURL == p.rc.AiServerURL {
+			p.aiServerURL = cfg.AiServerURL
+		}
+		if p.aiServerMode == p.rc.AiServerMode {
+			p.aiServerMode = cfg.AiServerMode
+		}
+		if p.aiCachePath == p.rc.AiCachePath {
+			p.aiCachePath = cfg.AiCachePath
+		}
+		if p.aiMCPCommand == p.rc.AiMCPCommand {
+			p.aiMCPCommand = cfg.AiMCPCommand
+		}
+		p.closeAIClient()
+	}
+	p.rc = cfg
+
+	p.enableSuggestions = cfg.EnableSuggestions
+	p.enableAIAnalysis = cfg.EnableAIAnalysis
+	p.enableJSONExport = cfg.EnableJSONExport
+	p.enableVisualExplain = cfg.EnableVisualExplain
+	p.autoView = cfg.AutoView
+	p.maxFieldWidth = cfg.MaxFieldWidth
+	p.buffers.limit = cfg.ResultBuffers
+	p.showCost = cfg.QueryCost
+	p.showWarnings = cfg.ShowWarnings
+	p.outputFormat = cfg.OutputFormat
+	setLanguage(cfg.Language)
+	p.pager, p.pagerEnabled = cfg.Pager, cfg.EnablePager
+	p.colorResults = cfg.ColorResults
+	p.hooks = cfg.Hooks
+	p.format = cfg.Format
+	p.completionMode = cfg.CompletionMode
+	p.completionMatch = cfg.CompletionMatch
+	p.completionMinScore = cfg.CompletionMinScore
+	p.completionValues = cfg.CompletionValues
+	p.completionLatency = cfg.CompletionLatency
+	p.rowEstimateWarning = cfg.RowEstimateWarning
+	p.syntaxCheck = cfg.SyntaxCheck
+	p.guard.timer, p.guard.alert, p.guard.notify = cfg.LiveTimer, cfg.LongQueryAlert, cfg.LongQueryNotify
+	p.aliases = copyAliases(cfg.Aliases)
+	p.runbooks = cfg.Runbooks
+	p.promptRules = cfg.PromptRules
+
+	p.lagChecker.close()
+	p.lagChecker = nil
+	p.lag = lagSettings{Max: cfg.MaxReplicaLag, Replicas: cfg.LagReplicas, Heartbeat: cfg.HeartbeatTable, Wait: cfg.ReplicaLagWait}
+
+	p.stopKeepalive()
+	p.startKeepalive(cfg.Keepalive)
+	if p.highlighter != nil {
+		*p.highlighter = *NewSyntaxHighlighter()
+	}
+	return restart
+}
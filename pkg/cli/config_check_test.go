@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateConfig(t *testing.T) {
+	rc := `# comment
+[main]
+syntax_style = dracula
+suggestion = true
+keepalive = 5
+completion = "metadata-only"
+keepalive = 30s
+live_timer = maybe
+
+[colors]
+Keyword = #66D9EF
+number = bright
+
+[prompt]
+prod-* = red [PROD]
+stage-* = redd
+
+[aliases]
+anything = SELECT 1
+
+[mian]
+stray = 1
+`
+	want := map[int]string{
+		4:  "unknown key suggestion in [main], did you mean suggestions?",
+		5:  `keepalive: "5" is not a duration`,
+		7:  "keepalive is also set on line 5",
+		8:  `live_timer: "maybe" is not true or false`,
+		12: `number: "bright" is not a color`,
+		16: `stage-*: unknown color "redd"`,
+		21: "unknown section [mian]; its keys are ignored, did you mean main?",
+	}
+	problems := validateConfig([]byte(rc))
+	got := map[int]string{}
+	for _, c := range problems {
+		got[c.Line] = c.Message
+	}
+	for line, msg := range want {
+		if !strings.Contains(got[line], msg) {
+			t.Errorf("line %d: got %q, want %q", line, got[line], msg)
+		}
+	}
+	if len(problems) != len(want) {
+		t.Errorf("got %d problems, want %d: %v", len(problems), len(want), problems)
+	}
+}
+
+func TestValidateConfigDefaults(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GO_MYCLI_RC", "")
+	if err := SaveDefaultSyntaxConfig(); err != nil {
+		t.Fatal(err)
+	}
+	problems, err := checkConfigFile(syntaxConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) > 0 {
+		t.Errorf("the default config has problems: %v", problems)
+	}
+}
+
+func TestSuggestion(t *testing.T) {
+	known := []string{"keepalive", "live_timer", "syntax_check"}
+	if got := suggestion("keepalvie", known); got != ", did you mean keepalive?" {
+		t.Errorf("suggestion(keepalvie) = %q", got)
+	}
+	if got := suggestion("wholly_unrelated", known); got != "" {
+		t.Errorf("suggestion(wholly_unrelated) = %q, want none", got)
+	}
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// configSetting is one key of ~/.go-myclirc to write
+type configSetting struct {
+	Section string
+	Key     string
+	Value   string
+}
+
+// setConfigValue returns the config file text with key set to value in
+// section. The line that sets the key (the last one, which is the one read)
+// is rewritten in place; a missing key is added after the section's last
+// key and a missing section at the end. Comments, blank lines and keys
+// go-mycli does not know are left as they are.
+func setConfigValue(text, section, key, value string) string {
+	lines := strings.Split(text, "\n")
+	current, found := "", false
+	keyLine, sectionEnd := -1, -1
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+			continue
+		case line[0] == '[':
+			current = ""
+			if end := strings.IndexByte(line, ']'); end > 0 {
+				current = strings.TrimSpace(line[1:end])
+			}
+			if current == section {
+				found, sectionEnd = true, i
+			}
+			continue
+		}
+		if current != section {
+			continue
+		}
+		sectionEnd = i
+		if j := strings.IndexAny(line, "=:"); j >= 0 && strings.TrimSpace(line[:j]) == key {
+			keyLine = i
+		}
+	}
+
+	set := key + " = " + iniQuote(value)
+	switch {
+	case keyLine >= 0:
+		raw := lines[keyLine]
+		indent := raw[:len(raw)-len(strings.TrimLeft(raw, " \t"))]
+		if strings.HasSuffix(raw, "\r") {
+			set += "\r"
+		}
+		lines[keyLine] = indent + set
+	case found:
+		lines = append(lines[:sectionEnd+1], append([]string{set}, lines[sectionEnd+1:]...)...)
+	default:
+		text = strings.TrimRight(text, "\r\n")
+		if text != "" {
+			text += "\n\n"
+		}
+		return text + "[" + section + "]\n" + set + "\n"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// iniQuote quotes a value whose spaces or quotes would otherwise be lost
+// when it is read back
+func iniQuote(value string) string {
+	if value != strings.TrimSpace(value) || strings.ContainsAny(value[:min(len(value), 1)], "\"'`") {
+		if !strings.Contains(value, "`") {
+			return "`" + value + "`"
+		}
+		return `"` + value + `"`
+	}
+	return value
+}
+
+// saveSettings writes settings into the config file at path, creating it
+// when it does not exist, and changing nothing else in it
+func saveSettings(path string, settings []configSetting) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	text := string(data)
+	for _, s := range settings {
+		text = setConfigValue(text, s.Section, s.Key, s.Value)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(text), 0o644)
+}
+
+// saveSetting keeps a setting changed at the prompt, such as \ai on, in the
+// [main] section of the config file go-mycli reads. A file that cannot be
+// written is reported; the setting still applies to the session.
+func saveSetting(key, value string) {
+	path := syntaxConfigPath()
+	if path == "" {
+		return
+	}
+	if err := saveSettings(path, []configSetting{{Section: "main", Key: key, Value: value}}); err != nil {
+		fmt.Printf("Could not save %s to %s: %v\n", key, path, err)
+	}
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetConfigValue(t *testing.T) {
+	rc := `# my settings
+[main]
+  ai_analysis = false ; was on
+ai_analysis = true
+my_own_key = kept
+
+[colors]
+keyword = #66D9EF
+`
+	tests := []struct {
+		section, key, value string
+		want                string
+	}{
+		// The last line setting the key is the one read, so it is the one changed
+		{"main", "ai_analysis", "false", "  ai_analysis = false ; was on\nai_analysis = false\nmy_own_key = kept\n"},
+		{"main", "query_cost", "true", "my_own_key = kept\nquery_cost = true\n\n[colors]"},
+		{"hooks", "timeout", "5s", "keyword = #66D9EF\n\n[hooks]\ntimeout = 5s\n"},
+		{"main", "output_format", " padded", "output_format = ` padded`"},
+	}
+	for _, tt := range tests {
+		got := setConfigValue(rc, tt.section, tt.key, tt.value)
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("set %s.%s:\n%s\nwant it to contain:\n%s", tt.section, tt.key, got, tt.want)
+		}
+		if !strings.HasPrefix(got, "# my settings\n[main]\n") {
+			t.Errorf("set %s.%s lost the comment:\n%s", tt.section, tt.key, got)
+		}
+	}
+	if got := setConfigValue("", "main", "live_timer", "false"); got != "[main]\nlive_timer = false\n" {
+		t.Errorf("set in an empty file = %q", got)
+	}
+	if got := setConfigValue("[main]\r\nkeepalive = 1m\r\n", "main", "keepalive", "30s"); got != "[main]\r\nkeepalive = 30s\r\n" {
+		t.Errorf("set with CRLF line ends = %q", got)
+	}
+}
+
+func TestSaveSyntaxConfigKeepsComments(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), ".go-myclirc")
+	content := "# team defaults\n[main]\nsyntax_style = dracula\n\n[plugins]\nunknown = 1\n"
+	if err := os.WriteFile(rc, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GO_MYCLI_RC", rc)
+
+	cfg := LoadSyntaxConfig()
+	cfg.EnableAIAnalysis = true
+	if err := SaveSyntaxConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# team defaults\n[main]\nsyntax_style = dracula\n", "[plugins]\nunknown = 1\n", "ai_analysis = true"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("saved config lost %q:\n%s", want, data)
+		}
+	}
+	if !LoadSyntaxConfig().EnableAIAnalysis {
+		t.Error("ai_analysis is still off after the save")
+	}
+}
+
+func TestSaveSetting(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), ".go-myclirc")
+	t.Setenv("GO_MYCLI_RC", rc)
+	saveSetting("output_format", "vertical")
+	saveSetting("show_warnings", "true")
+	cfg := LoadSyntaxConfig()
+	if cfg.OutputFormat != "vertical" || !cfg.ShowWarnings {
+		t.Errorf("output_format = %q, show_warnings = %v after saving them", cfg.OutputFormat, cfg.ShowWarnings)
+	}
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/go-sql-driver/mysql"
+	"golang.org/x/term"
+)
+
+// errAccessDenied is ER_ACCESS_DENIED_ERROR
+const errAccessDenied = 1045
+
+// stdinIsTerminal reports whether the user can be asked for input
+func stdinIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// connectionUnresolved reports whether neither a user nor a server was given
+// anywhere: on the command line, in the environment or in option files. The
+// user and host would otherwise fall back to $USER@localhost, which rarely is
+// what someone starting go-mycli without arguments meant.
+func connectionUnresolved(config *MySQLConfig, cliUser, cliHost, cliSocket string) bool {
+	env := EnvConfig()
+	return cliUser == "" && env.User == "" && config.User == "" &&
+		cliHost == "" && env.Host == "" && config.Host == "" &&
+		cliSocket == "" && env.Socket == "" && config.Socket == ""
+}
+
+// promptConnection asks for the host, port, user and password, offering the
+// merged values as defaults
+func promptConnection(merged *MySQLConfig, in io.Reader) error {
+	fmt.Println("No connection settings found; enter them below (Enter keeps the default).")
+	r := bufio.NewReader(in)
+
+	host, err := promptLine(r, "Host", merged.Host)
+	if err != nil {
+		return err
+	}
+	merged.Host = host
+	if strings.HasPrefix(host, "/") || strings.HasPrefix(host, `\\.\pipe\`) {
+		// A path is a Unix socket, or a named pipe on Windows
+		merged.Host, merged.Socket = "", host
+	} else {
+		merged.Socket = ""
+		portText, err := promptLine(r, "Port", strconv.Itoa(merged.Port))
+		if err != nil {
+			return err
+		}
+		port, err := strconv.Atoi(portText)
+		if err != nil || port <= 0 || port > 65535 {
+			return fmt.Errorf("invalid port %q", portText)
+		}
+		merged.Port = port
+	}
+
+	if merged.User, err = promptLine(r, "User", merged.User); err != nil {
+		return err
+	}
+	if merged.Password == "" {
+		if merged.Password, err = readPassword(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// promptLine prints "label [def]: " and returns the trimmed answer, or def
+// when it is empty
+func promptLine(r *bufio.Reader, label, def string) (string, error) {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", label, def)
+	} else {
+		fmt.Printf("%s: ", label)
+	}
+	line, err := r.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("reading %s: %w", strings.ToLower(label), err)
+	}
+	if line = strings.TrimSpace(line); line != "" {
+		return line, nil
+	}
+	return def, nil
+}
+
+// readPassword reads a password from the terminal without echoing it
+func readPassword() (string, error) {
+	fmt.Print("Password: ")
+	b, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("reading password: %w", err)
+	}
+	return string(b), nil
+}
+
+// isAccessDenied reports whether err is the server rejecting the credentials
+func isAccessDenied(err error) bool {
+	var myErr *mysql.MySQLError
+	return errors.As(err, &myErr) && myErr.Number == errAccessDenied
+}
+
+// chooseDatabase asks which database to use, completing names from
+// SHOW DATABASES. It returns "" when none is chosen.
+func chooseDatabase(db *sql.DB) string {
+	rows, err := db.Query("SHOW DATABASES")
+	if err != nil {
+		return ""
+	}
+	var suggestions []prompt.Suggest
+	for rows.Next() {
+		var name string
+		if rows.Scan(&name) == nil {
+			suggestions = append(suggestions, prompt.Suggest{Text: name})
+		}
+	}
+	rows.Close()
+	if len(suggestions) == 0 {
+		return ""
+	}
+
+	completer := func(d prompt.Document) []prompt.Suggest {
+		return prompt.FilterHasPrefix(suggestions, d.GetWordBeforeCursor(), true)
+	}
+	options := append([]prompt.Option{prompt.OptionShowCompletionAtStart()}, promptColorOptions()...)
+	return strings.TrimSpace(prompt.Input("Database (Tab completes, Enter for none): ", completer, options...))
+}
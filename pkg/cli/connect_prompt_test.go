@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func clearConnectionEnv(t *testing.T) {
+	for _, names := range [][]string{envHost, envPort, envUser, envPassword, envSocket, envDatabase} {
+		for _, name := range names {
+			t.Setenv(name, "")
+		}
+	}
+}
+
+func TestConnectionUnresolved(t *testing.T) {
+	clearConnectionEnv(t)
+
+	if !connectionUnresolved(&MySQLConfig{}, "", "", "") {
+		t.Errorf("nothing configured should be unresolved")
+	}
+	if connectionUnresolved(&MySQLConfig{User: "app"}, "", "", "") {
+		t.Errorf("a user from option files resolves the connection")
+	}
+	if connectionUnresolved(&MySQLConfig{}, "", "db.example.com", "") {
+		t.Errorf("a host on the command line resolves the connection")
+	}
+	t.Setenv("MYSQL_HOST", "db")
+	if connectionUnresolved(&MySQLConfig{}, "", "", "") {
+		t.Errorf("a host in the environment resolves the connection")
+	}
+}
+
+func TestPromptConnection(t *testing.T) {
+	merged := &MySQLConfig{Host: "localhost", Port: 3306, User: "me", Password: "given"}
+	in := strings.NewReader("db.example.com\n3307\n\n")
+	if err := promptConnection(merged, in); err != nil {
+		t.Fatal(err)
+	}
+	want := MySQLConfig{Host: "db.example.com", Port: 3307, User: "me", Password: "given"}
+	if *merged != want {
+		t.Errorf("promptConnection = %+v, want %+v", *merged, want)
+	}
+
+	merged = &MySQLConfig{Host: "localhost", Port: 3306, User: "me", Password: "given"}
+	if err := promptConnection(merged, strings.NewReader("/tmp/mysql.sock\nroot\n")); err != nil {
+		t.Fatal(err)
+	}
+	if merged.Socket != "/tmp/mysql.sock" || merged.Host != "" || merged.User != "root" {
+		t.Errorf("socket path: %+v", *merged)
+	}
+
+	merged = &MySQLConfig{Host: "localhost", Port: 3306, Password: "given"}
+	if err := promptConnection(merged, strings.NewReader("\nhttp\n")); err == nil {
+		t.Errorf("an invalid port should fail")
+	}
+}
+
+func TestIsAccessDenied(t *testing.T) {
+	denied := fmt.Errorf("failed to ping database: %w", &mysql.MySQLError{Number: 1045, Message: "Access denied"})
+	if !isAccessDenied(denied) {
+		t.Errorf("wrapped 1045 should be access denied")
+	}
+	if isAccessDenied(&mysql.MySQLError{Number: 1049}) {
+		t.Errorf("unknown database is not access denied")
+	}
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// cacheRefreshTimeout bounds the metadata queries of a completion cache
+// refresh, which runs while the user types
+const cacheRefreshTimeout = 5 * time.Second
+
+// errCanceled is reported for a statement stopped by Ctrl-C or shutdown
+var errCanceled = errors.New("statement canceled")
+
+// killTimeout bounds the KILL QUERY sent for a canceled statement, so that a
+// server that does not answer cannot hang the prompt
+const killTimeout = 5 * time.Second
+
+// sessionContext is the context all work of the session derives from. It is
+// canceled at shutdown.
+func (p *PromptExecutor) sessionContext() context.Context {
+	if p.ctx == nil {
+		return context.Background()
+	}
+	return p.ctx
+}
+
+// statementContext returns the context of one statement or command started
+// from the prompt or a script. Ctrl-C cancels it, stopping the statement on
+// the server instead of killing the client, and so does shutdown. stop must
+// be called once the statement is done, which hands Ctrl-C back.
+func (p *PromptExecutor) statementContext() (ctx context.Context, stop context.CancelFunc) {
+	return signal.NotifyContext(p.sessionContext(), os.Interrupt)
+}
+
+// shutdown cancels the session's context and with it everything in flight:
+// the running statement, background jobs and keepalive pings. It also stops
+// the --k8s-service port-forward, which \q would otherwise leave running.
+func (p *PromptExecutor) shutdown() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	activeForward.Close()
+}
+
+// connectionID returns the server's id of conn, the one KILL QUERY takes
+func connectionID(ctx context.Context, conn *sql.Conn) (int64, error) {
+	var id int64
+	err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&id)
+	return id, err
+}
+
+// killOnCancel stops the statement running on connection id if ctx is
+// canceled while it runs. The driver only drops the connection on cancel,
+// and the server would go on running the statement until it tried to send
+// the result. The returned function ends the watch and must be called once
+// the statement has returned.
+func (p *PromptExecutor) killOnCancel(ctx context.Context, id int64) (done func()) {
+	finished := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-finished:
+			return
+		case <-ctx.Done():
+		}
+		if id == 0 {
+			return
+		}
+		// Kill from a connection of its own, since ctx is already canceled,
+		// and give up on a server that does not answer
+		killCtx, cancel := context.WithTimeout(context.Background(), killTimeout)
+		defer cancel()
+		_, _ = p.db.ExecContext(killCtx, fmt.Sprintf("KILL QUERY %d", id))
+	}()
+	return func() {
+		close(finished)
+		<-stopped
+	}
+}
+
+// canceled returns errCanceled in place of the driver's error for a
+// statement whose ctx was canceled
+func canceled(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return errCanceled
+	}
+	return err
+}
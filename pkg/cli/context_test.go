@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestShutdownCancelsStatements(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &PromptExecutor{ctx: ctx, cancel: cancel}
+	stmtCtx, stop := p.statementContext()
+	defer stop()
+	if stmtCtx.Err() != nil {
+		t.Fatal("statement canceled before shutdown")
+	}
+	p.shutdown()
+	if stmtCtx.Err() == nil {
+		t.Error("shutdown should cancel the running statement")
+	}
+	if err := canceled(stmtCtx, errors.New("invalid connection")); !errors.Is(err, errCanceled) {
+		t.Errorf("got %v", err)
+	}
+	if err := canceled(context.Background(), io.EOF); err != io.EOF {
+		t.Errorf("errors of statements that were not canceled are kept, got %v", err)
+	}
+
+	// Without a session, as in tests and tools, statements are never canceled
+	if (&PromptExecutor{}).sessionContext().Done() != nil {
+		t.Error("no session context should never be canceled")
+	}
+}
+
+func TestKillOnCancelUnknownConnection(t *testing.T) {
+	// Without a connection id there is nothing to kill, and no other
+	// session's statement may be picked in its place
+	ctx, cancel := context.WithCancel(context.Background())
+	done := (&PromptExecutor{}).killOnCancel(ctx, 0)
+	cancel()
+	done()
+}
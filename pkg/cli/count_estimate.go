@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// countArgs matches the arguments of \count~: a table, optionally qualified,
+// and an optional WHERE clause
+var countArgs = regexp.MustCompile("(?is)^((?:(?:`[^`]+`|[\\w$]+)\\s*\\.\\s*)?(?:`[^`]+`|[\\w$]+))(?:\\s+(WHERE\\s+.+))?$")
+
+// rowCountEstimate is the answer of \count~ with where it came from
+type rowCountEstimate struct {
+	Rows   float64
+	Source string // how the number was obtained
+	Note   string // how far off it may be
+}
+
+// tableRowsEstimate describes TABLE_ROWS from INFORMATION_SCHEMA.TABLES,
+// which InnoDB computes from a sample of index pages
+func tableRowsEstimate(rows float64, engine string) rowCountEstimate {
+	e := rowCountEstimate{Rows: rows, Source: "table statistics (INFORMATION_SCHEMA.TABLES)"}
+	switch strings.ToUpper(engine) {
+	case "INNODB":
+		e.Note = "InnoDB samples a few index pages, so this can be off by 40-50%; ANALYZE TABLE refreshes it"
+	case "MYISAM", "ARIA", "MEMORY":
+		e.Note = fmt.Sprintf("%s keeps an exact count, so this is the row count", engine)
+	default:
+		e.Note = fmt.Sprintf("%s reports an estimate; how close it is depends on the engine", engine)
+	}
+	return e
+}
+
+// planEstimate turns the EXPLAIN of SELECT * FROM table WHERE ... into an
+// estimate: the rows the optimizer expects to read, reduced by the share it
+// expects the remaining conditions to keep
+func planEstimate(step planStep) rowCountEstimate {
+	e := rowCountEstimate{Rows: math.Round(step.Rows * step.Filtered / 100)}
+	switch strings.ToLower(step.Access) {
+	case "const", "eq_ref", "system":
+		e.Source = fmt.Sprintf("EXPLAIN, a unique lookup on %s", step.Key)
+		e.Note = "at most one row matches"
+	case "range":
+		e.Source = fmt.Sprintf("EXPLAIN, a range on %s", step.Key)
+		e.Note = "index dives make ranges close, usually within a few percent"
+	case "ref", "ref_or_null":
+		e.Source = fmt.Sprintf("EXPLAIN, a lookup on %s", step.Key)
+		e.Note = "uses the average rows per value of the index, so skewed values can be far off"
+	default:
+		e.Source = "EXPLAIN, a full scan"
+		if step.Key != "" {
+			e.Source = fmt.Sprintf("EXPLAIN, a scan of %s", step.Key)
+		}
+		e.Note = "the table size comes from statistics that can be off by 40-50%"
+	}
+	if step.Filtered < 100 {
+		e.Note += fmt.Sprintf("; the optimizer guessed that %s%% of those rows match the other conditions, "+
+			"which without a histogram can be off by orders of magnitude", strconv.FormatFloat(step.Filtered, 'f', -1, 64))
+	}
+	return e
+}
+
+// countEstimate implements \count~ <table> [WHERE ...]: a row count taken
+// from table statistics or the optimizer's estimate instead of a COUNT(*)
+// that reads every row, with the exact count run on request
+func (p *PromptExecutor) countEstimate(args string) {
+	m := countArgs.FindStringSubmatch(strings.TrimSuffix(strings.TrimSpace(args), ";"))
+	if m == nil {
+		fmt.Println("Usage: \\count~ <table> [WHERE ...]")
+		return
+	}
+	database, table := splitTableName(m[1], p.database)
+	if database == "" {
+		fmt.Println("No database selected")
+		return
+	}
+	from := quoteIdentifier(database) + "." + quoteIdentifier(table)
+	where := ""
+	if m[2] != "" {
+		where = " " + m[2]
+	}
+
+	var estimate rowCountEstimate
+	if where == "" {
+		var rows sql.NullFloat64
+		var engine sql.NullString
+		err := p.db.QueryRow(`SELECT TABLE_ROWS, ENGINE FROM INFORMATION_SCHEMA.TABLES
+			WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?`, database, table).Scan(&rows, &engine)
+		if err == sql.ErrNoRows {
+			fmt.Printf("Table %s.%s not found\n", database, table)
+			return
+		}
+		if err != nil {
+			fmt.Printf(tr("Error: %v\n"), err)
+			return
+		}
+		if !rows.Valid {
+			fmt.Printf("%s.%s has no row statistics (a view?)\n", database, table)
+			return
+		}
+		estimate = tableRowsEstimate(rows.Float64, engine.String)
+	} else {
+		steps, err := p.explainSteps("SELECT * FROM " + from + where)
+		if err != nil {
+			fmt.Printf(tr("Error: %v\n"), err)
+			return
+		}
+		if len(steps) == 0 {
+			fmt.Println("EXPLAIN returned no plan")
+			return
+		}
+		estimate = planEstimate(steps[0])
+	}
+
+	fmt.Printf("~%s rows (%s)\n", formatRowCount(estimate.Rows), estimate.Source)
+	fmt.Printf("Note: %s\n", estimate.Note)
+	if !p.canPrompt() || !askYesNo("Run the exact COUNT(*), which reads every matching row? [y/N] ") {
+		return
+	}
+
+	ctx, stop := p.statementContext()
+	defer stop()
+	res, err := p.runStatement(ctx, "SELECT COUNT(*) FROM "+from+where)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return
+	}
+	exact, _ := strconv.ParseFloat(res.Rows[0][0], 64)
+	fmt.Printf("%s rows (%.3fs)%s\n", res.Rows[0][0], res.Duration.Seconds(), estimateError(estimate.Rows, exact))
+}
+
+// estimateError describes how far an estimate was from the exact count
+func estimateError(estimate, exact float64) string {
+	if exact == 0 {
+		return ""
+	}
+	off := (estimate - exact) / exact * 100
+	if math.Abs(off) < 0.5 {
+		return "; the estimate was right"
+	}
+	return fmt.Sprintf("; the estimate was off by %+.0f%%", off)
+}
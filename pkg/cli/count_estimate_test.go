@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCountArgs(t *testing.T) {
+	tests := []struct {
+		args, table, where string
+	}{
+		{"orders", "orders", ""},
+		{"shop.`order items` where qty > 1", "shop.`order items`", "where qty > 1"},
+		{"orders WHERE status = 'new'\n  AND total > 10", "orders", "WHERE status = 'new'\n  AND total > 10"},
+	}
+	for _, test := range tests {
+		m := countArgs.FindStringSubmatch(test.args)
+		if m == nil || m[1] != test.table || m[2] != test.where {
+			t.Errorf("%q: got %q", test.args, m)
+		}
+	}
+	for _, args := range []string{"", "orders LIMIT 5", "orders o JOIN items i"} {
+		if countArgs.MatchString(args) {
+			t.Errorf("%q should not match", args)
+		}
+	}
+}
+
+func TestPlanEstimate(t *testing.T) {
+	e := planEstimate(planStep{Rows: 1200, Filtered: 100, Access: "range", Key: "idx_created"})
+	if e.Rows != 1200 || !strings.Contains(e.Source, "range on idx_created") || strings.Contains(e.Note, "guessed") {
+		t.Errorf("range: %+v", e)
+	}
+	e = planEstimate(planStep{Rows: 50000, Filtered: 33.33, Access: "ALL"})
+	if e.Rows != 16665 || e.Source != "EXPLAIN, a full scan" || !strings.Contains(e.Note, "33.33% of those rows") {
+		t.Errorf("full scan: %+v", e)
+	}
+	if e := tableRowsEstimate(10, "MyISAM"); !strings.Contains(e.Note, "exact count") {
+		t.Errorf("MyISAM: %+v", e)
+	}
+}
+
+func TestEstimateError(t *testing.T) {
+	tests := []struct {
+		estimate, exact float64
+		want            string
+	}{
+		{1200000, 1187342, "; the estimate was off by +1%"},
+		{500, 1000, "; the estimate was off by -50%"},
+		{1000, 1000, "; the estimate was right"},
+		{10, 0, ""},
+	}
+	for _, test := range tests {
+		if got := estimateError(test.estimate, test.exact); got != test.want {
+			t.Errorf("%v/%v: got %q", test.estimate, test.exact, got)
+		}
+	}
+}
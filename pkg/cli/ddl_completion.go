@@ -0,0 +1,132 @@
+package cli
+
+import "github.com/c-bata/go-prompt"
+
+// columnTypes are the data types offered after a column name in CREATE TABLE
+var columnTypes = []prompt.Suggest{
+	{Text: "INT", Description: "Integer, 4 bytes"},
+	{Text: "BIGINT", Description: "Integer, 8 bytes"},
+	{Text: "TINYINT", Description: "Integer, 1 byte"},
+	{Text: "SMALLINT", Description: "Integer, 2 bytes"},
+	{Text: "MEDIUMINT", Description: "Integer, 3 bytes"},
+	{Text: "DECIMAL(", Description: "Exact number, DECIMAL(precision, scale)"},
+	{Text: "FLOAT", Description: "Approximate number, 4 bytes"},
+	{Text: "DOUBLE", Description: "Approximate number, 8 bytes"},
+	{Text: "BIT(", Description: "Bit field, BIT(bits)"},
+	{Text: "BOOLEAN", Description: "Alias for TINYINT(1)"},
+	{Text: "VARCHAR(", Description: "Variable-length string, VARCHAR(length)"},
+	{Text: "CHAR(", Description: "Fixed-length string, CHAR(length)"},
+	{Text: "TEXT", Description: "String up to 64 KB"},
+	{Text: "TINYTEXT", Description: "String up to 255 bytes"},
+	{Text: "MEDIUMTEXT", Description: "String up to 16 MB"},
+	{Text: "LONGTEXT", Description: "String up to 4 GB"},
+	{Text: "VARBINARY(", Description: "Variable-length bytes, VARBINARY(length)"},
+	{Text: "BINARY(", Description: "Fixed-length bytes, BINARY(length)"},
+	{Text: "BLOB", Description: "Bytes up to 64 KB"},
+	{Text: "MEDIUMBLOB", Description: "Bytes up to 16 MB"},
+	{Text: "LONGBLOB", Description: "Bytes up to 4 GB"},
+	{Text: "DATE", Description: "Date"},
+	{Text: "DATETIME", Description: "Date and time"},
+	{Text: "TIMESTAMP", Description: "Date and time in UTC, 1970 to 2038"},
+	{Text: "TIME", Description: "Time of day or duration"},
+	{Text: "YEAR", Description: "Year"},
+	{Text: "JSON", Description: "JSON document"},
+	{Text: "ENUM(", Description: "One of a list of strings"},
+	{Text: "SET(", Description: "Any of a list of strings"},
+	{Text: "GEOMETRY", Description: "Spatial value"},
+	{Text: "POINT", Description: "Spatial point"},
+}
+
+// columnAttributes follow a column's type
+var columnAttributes = []prompt.Suggest{
+	{Text: "NOT NULL", Description: "Reject NULL"},
+	{Text: "NULL", Description: "Allow NULL"},
+	{Text: "DEFAULT", Description: "Default value"},
+	{Text: "AUTO_INCREMENT", Description: "Generate the next number"},
+	{Text: "PRIMARY KEY", Description: "Make this the primary key"},
+	{Text: "UNIQUE", Description: "Reject duplicates"},
+	{Text: "UNSIGNED", Description: "No negative numbers"},
+	{Text: "DEFAULT CURRENT_TIMESTAMP", Description: "Default to the insert time"},
+	{Text: "ON UPDATE CURRENT_TIMESTAMP", Description: "Set to the update time"},
+	{Text: "CHARACTER SET", Description: "Character set of the column"},
+	{Text: "COLLATE", Description: "Collation of the column"},
+	{Text: "COMMENT", Description: "Column comment"},
+	{Text: "GENERATED ALWAYS AS (", Description: "Generated column"},
+	{Text: "INVISIBLE", Description: "Hide from SELECT *"},
+	{Text: "REFERENCES", Description: "Foreign key to another table"},
+	{Text: "CHECK (", Description: "Check constraint"},
+}
+
+// tableElements start a definition in CREATE TABLE t (...) other than a
+// column, whose name is the user's to choose
+var tableElements = []prompt.Suggest{
+	{Text: "PRIMARY KEY (", Description: "Primary key"},
+	{Text: "KEY", Description: "Index"},
+	{Text: "INDEX", Description: "Index"},
+	{Text: "UNIQUE KEY", Description: "Unique index"},
+	{Text: "FULLTEXT KEY", Description: "Full-text index"},
+	{Text: "SPATIAL KEY", Description: "Spatial index"},
+	{Text: "CONSTRAINT", Description: "Named constraint"},
+	{Text: "FOREIGN KEY (", Description: "Foreign key"},
+	{Text: "CHECK (", Description: "Check constraint"},
+}
+
+// tableOptions follow CREATE TABLE t (...)
+var tableOptions = []prompt.Suggest{
+	{Text: "ENGINE=", Description: "Storage engine"},
+	{Text: "DEFAULT CHARSET=", Description: "Default character set"},
+	{Text: "COLLATE=", Description: "Default collation"},
+	{Text: "AUTO_INCREMENT=", Description: "First AUTO_INCREMENT value"},
+	{Text: "ROW_FORMAT=", Description: "Row storage format"},
+	{Text: "COMMENT=", Description: "Table comment"},
+	{Text: "PARTITION BY", Description: "Partitioning"},
+}
+
+// tableOptionValues are the values offered after option=, except ENGINE's,
+// which come from the server
+var tableOptionValues = map[string][]prompt.Suggest{
+	"CHARSET": {
+		{Text: "utf8mb4", Description: "UTF-8, up to 4 bytes per character"},
+		{Text: "latin1", Description: "West European"},
+		{Text: "ascii", Description: "US ASCII"},
+		{Text: "binary", Description: "Bytes"},
+		{Text: "utf8mb3", Description: "UTF-8, up to 3 bytes per character (deprecated)"},
+	},
+	"COLLATE": {
+		{Text: "utf8mb4_0900_ai_ci", Description: "Accent and case insensitive (MySQL 8.0 default)"},
+		{Text: "utf8mb4_unicode_ci", Description: "Case insensitive"},
+		{Text: "utf8mb4_general_ci", Description: "Case insensitive, faster and less exact"},
+		{Text: "utf8mb4_bin", Description: "Binary comparison"},
+		{Text: "latin1_swedish_ci", Description: "latin1 default"},
+	},
+	"ROW_FORMAT": {
+		{Text: "DYNAMIC", Description: "Long values stored off-page (InnoDB default)"},
+		{Text: "COMPACT", Description: "Compact rows"},
+		{Text: "COMPRESSED", Description: "Compressed pages"},
+		{Text: "REDUNDANT", Description: "Old row format"},
+	},
+}
+
+// fallbackEngines are offered after ENGINE= when the server cannot be asked
+var fallbackEngines = []prompt.Suggest{
+	{Text: "InnoDB", Description: "Transactions, row locks and foreign keys"},
+	{Text: "MyISAM", Description: "Table locks, no transactions"},
+	{Text: "MEMORY", Description: "Kept in memory, lost on restart"},
+}
+
+// getTableOptionSuggestions returns the values for the option before "=",
+// or the table options
+func (p *PromptExecutor) getTableOptionSuggestions(ctx *SQLParseResult) []prompt.Suggest {
+	switch ctx.TableOption {
+	case "":
+		return tableOptions
+	case "ENGINE":
+		if engines := p.showNames(showEnginesQuery); len(engines) > 0 {
+			return engines
+		}
+		return fallbackEngines
+	case "SET": // CHARACTER SET=
+		return tableOptionValues["CHARSET"]
+	}
+	return tableOptionValues[ctx.TableOption]
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/c-bata/go-prompt"
+)
+
+func TestParseSQLContext_CreateTable(t *testing.T) {
+	tests := []struct {
+		sql    string
+		want   SQLContext
+		option string
+	}{
+		{"CREATE TABLE t (", ContextTableElement, ""},
+		{"CREATE TABLE IF NOT EXISTS t (id ", ContextColumnType, ""},
+		{"CREATE TABLE t (id BIG", ContextColumnType, ""},
+		{"CREATE TABLE t (id INT ", ContextColumnAttribute, ""},
+		{"CREATE TABLE t (id DECIMAL(10, 2) NOT NULL, ", ContextTableElement, ""},
+		{"CREATE TEMPORARY TABLE t (id INT, PRIMARY KEY ", ContextKeyword, ""},
+		{"CREATE TABLE t (id INT, PRIMARY KEY (id)) ", ContextTableOption, ""},
+		{"CREATE TABLE t (id INT) ENGINE=", ContextTableOption, "ENGINE"},
+		{"CREATE TABLE t (id INT) ENGINE = Inno", ContextTableOption, "ENGINE"},
+		{"CREATE TABLE t (id INT) ENGINE=InnoDB ", ContextTableOption, ""},
+	}
+	for _, tt := range tests {
+		result := ParseSQLContext(tt.sql, len(tt.sql))
+		if result.Context != tt.want || result.TableOption != tt.option {
+			t.Errorf("%q: got %v (option %q), want %v (option %q)", tt.sql, result.Context, result.TableOption, tt.want, tt.option)
+		}
+		if len(result.Aliases) != 0 {
+			t.Errorf("%q: unexpected aliases %v", tt.sql, result.Aliases)
+		}
+	}
+}
+
+func TestCreateTableSuggestions(t *testing.T) {
+	p := &PromptExecutor{tables: []string{"users"}, columns: map[string][]string{}, cacheTime: time.Now(), completionMode: completionFull}
+	first := func(line string) string {
+		buf := prompt.NewBuffer()
+		buf.InsertText(line, false, true)
+		if got := p.Completer(*buf.Document()); len(got) > 0 {
+			return got[0].Text
+		}
+		return ""
+	}
+	tests := []struct {
+		line, want string
+	}{
+		{"CREATE TABLE t (id BIGI", "BIGINT"},
+		{"CREATE TABLE t (id INT ", "NOT NULL"},
+		{"CREATE TABLE t (id INT AUTO", "AUTO_INCREMENT"},
+		{"CREATE TABLE t (id INT, ", "PRIMARY KEY ("},
+		{"CREATE TABLE t (id INT) ", "ENGINE="},
+		{"CREATE TABLE t (id INT) ENGINE=", "InnoDB"},
+		{"CREATE TABLE t (id INT) DEFAULT CHARSET=utf8", "utf8mb4"},
+		{"CREATE TABLE t (id INT) CHARSET=latin1 COLLATE=", "utf8mb4_0900_ai_ci"},
+	}
+	for _, tt := range tests {
+		if got := first(tt.line); got != tt.want {
+			t.Errorf("%q: first suggestion %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
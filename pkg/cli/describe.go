@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// queryStrings runs query and returns its columns and rows as display
+// strings. Ctrl-C cancels it.
+func (p *PromptExecutor) queryStrings(query string, args ...interface{}) ([]string, [][]string, error) {
+	ctx, stop := p.statementContext()
+	defer stop()
+	columns, rows, err := p.queryStringsContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, canceled(ctx, err)
+	}
+	return columns, rows, nil
+}
+
+// queryStringsContext is queryStrings for work that ctx can cancel
+func (p *PromptExecutor) queryStringsContext(ctx context.Context, query string, args ...interface{}) ([]string, [][]string, error) {
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	return readStringRows(rows)
+}
+
+// readStringRows reads the columns and rows of a result as display strings,
+// with NULL for NULL values
+func readStringRows(rows *sql.Rows) ([]string, [][]string, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var result [][]string
+	values := make([]interface{}, len(columns))
+	scanArgs := make([]interface{}, len(values))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return nil, nil, err
+		}
+		row := make([]string, len(columns))
+		for i, val := range values {
+			switch v := val.(type) {
+			case nil:
+				row[i] = "NULL"
+			case []byte:
+				row[i] = string(v)
+			default:
+				row[i] = fmt.Sprintf("%v", v)
+			}
+		}
+		result = append(result, row)
+	}
+	return columns, result, rows.Err()
+}
+
+// likePattern converts a psql-style pattern (* and ?) into a LIKE pattern.
+// An empty pattern matches everything.
+func likePattern(pattern string) string {
+	if pattern == "" {
+		return "%"
+	}
+	replacer := strings.NewReplacer("_", "\\_", "%", "\\%", "*", "%", "?", "_")
+	return replacer.Replace(pattern)
+}
+
+// formatBytes renders a byte count as a short human readable size
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// printRows prints rows as a table followed by the usual row count line
+func printRows(columns []string, rows [][]string) {
+	fmt.Print(formatMySQLTable(columns, rows))
+	fmt.Printf(tr("%d row%s in set\n"), len(rows), plural(len(rows)))
+}
+
+// listDatabases implements \l: every database with its table count and size
+func (p *PromptExecutor) listDatabases() {
+	_, rows, err := p.queryStrings(`
+		SELECT s.SCHEMA_NAME, s.DEFAULT_CHARACTER_SET_NAME, COUNT(t.TABLE_NAME),
+		       COALESCE(SUM(t.DATA_LENGTH + t.INDEX_LENGTH), 0)
+		FROM INFORMATION_SCHEMA.SCHEMATA s
+		LEFT JOIN INFORMATION_SCHEMA.TABLES t ON t.TABLE_SCHEMA = s.SCHEMA_NAME
+		GROUP BY s.SCHEMA_NAME, s.DEFAULT_CHARACTER_SET_NAME
+		ORDER BY s.SCHEMA_NAME`)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return
+	}
+	for _, row := range rows {
+		var size int64
+		fmt.Sscanf(row[3], "%d", &size)
+		row[3] = formatBytes(size)
+	}
+	printRows([]string{"Database", "Charset", "Tables", "Size"}, rows)
+}
+
+// listTables implements \dt [pattern]. The pattern may be qualified with a
+// database ("sakila.f*"); otherwise the current database is listed.
+func (p *PromptExecutor) listTables(pattern string) {
+	schemaExpr, schemaArgs := "DATABASE()", []interface{}{}
+	if i := strings.Index(pattern, "."); i >= 0 {
+		schemaExpr, schemaArgs = "?", []interface{}{pattern[:i]}
+		pattern = pattern[i+1:]
+	}
+
+	args := append(schemaArgs, likePattern(pattern))
+	_, rows, err := p.queryStrings(`
+		SELECT TABLE_NAME, TABLE_TYPE, COALESCE(ENGINE, ''), COALESCE(TABLE_ROWS, 0),
+		       COALESCE(DATA_LENGTH + INDEX_LENGTH, 0)
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE TABLE_SCHEMA = `+schemaExpr+` AND TABLE_NAME LIKE ?
+		ORDER BY TABLE_NAME`, args...)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return
+	}
+	if len(rows) == 0 {
+		fmt.Println("No matching tables")
+		return
+	}
+	for _, row := range rows {
+		var size int64
+		fmt.Sscanf(row[4], "%d", &size)
+		row[4] = formatBytes(size)
+	}
+	printRows([]string{"Table", "Type", "Engine", "Rows (est.)", "Size"}, rows)
+}
+
+// describeTable implements \d <table>: columns, indexes and foreign keys in one view
+func (p *PromptExecutor) describeTable(name string) {
+	schemaExpr, table := "DATABASE()", strings.Trim(name, "`")
+	args := []interface{}{}
+	if i := strings.Index(table, "."); i >= 0 {
+		schemaExpr = "?"
+		args = append(args, strings.Trim(table[:i], "`"))
+		table = strings.Trim(table[i+1:], "`")
+	}
+	args = append(args, table)
+	where := "TABLE_SCHEMA = " + schemaExpr + " AND TABLE_NAME = ?"
+	fkWhere := "k.TABLE_SCHEMA = " + schemaExpr + " AND k.TABLE_NAME = ?"
+
+	_, columns, err := p.queryStrings(`
+		SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COALESCE(COLUMN_DEFAULT, 'NULL'), COLUMN_KEY, EXTRA
+		FROM INFORMATION_SCHEMA.COLUMNS
+		WHERE `+where+`
+		ORDER BY ORDINAL_POSITION`, args...)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return
+	}
+	if len(columns) == 0 {
+		fmt.Printf("Table %s not found\n", name)
+		return
+	}
+	fmt.Printf("Table %s\n", name)
+	fmt.Print(formatMySQLTable([]string{"Column", "Type", "Null", "Default", "Key", "Extra"}, columns))
+
+	_, indexes, err := p.queryStrings(`
+		SELECT INDEX_NAME, IF(NON_UNIQUE = 0, 'UNIQUE', ''), INDEX_TYPE,
+		       GROUP_CONCAT(COLUMN_NAME ORDER BY SEQ_IN_INDEX SEPARATOR ', ')
+		FROM INFORMATION_SCHEMA.STATISTICS
+		WHERE `+where+`
+		GROUP BY INDEX_NAME, NON_UNIQUE, INDEX_TYPE
+		ORDER BY INDEX_NAME = 'PRIMARY' DESC, INDEX_NAME`, args...)
+	if err != nil {
+		fmt.Printf("Error reading indexes: %v\n", err)
+	} else if len(indexes) > 0 {
+		fmt.Println("Indexes:")
+		fmt.Print(formatMySQLTable([]string{"Index", "Unique", "Type", "Columns"}, indexes))
+	}
+
+	_, foreignKeys, err := p.queryStrings(`
+		SELECT k.CONSTRAINT_NAME,
+		       GROUP_CONCAT(k.COLUMN_NAME ORDER BY k.ORDINAL_POSITION SEPARATOR ', '),
+		       CONCAT(k.REFERENCED_TABLE_SCHEMA, '.', k.REFERENCED_TABLE_NAME, '(',
+		              GROUP_CONCAT(k.REFERENCED_COLUMN_NAME ORDER BY k.ORDINAL_POSITION SEPARATOR ', '), ')'),
+		       r.UPDATE_RULE, r.DELETE_RULE
+		FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE k
+		JOIN INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS r
+		  ON r.CONSTRAINT_SCHEMA = k.TABLE_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+		WHERE `+fkWhere+`
+		  AND k.REFERENCED_TABLE_NAME IS NOT NULL
+		GROUP BY k.CONSTRAINT_NAME, k.REFERENCED_TABLE_SCHEMA, k.REFERENCED_TABLE_NAME, r.UPDATE_RULE, r.DELETE_RULE
+		ORDER BY k.CONSTRAINT_NAME`, args...)
+	if err != nil {
+		fmt.Printf("Error reading foreign keys: %v\n", err)
+	} else if len(foreignKeys) > 0 {
+		fmt.Println("Foreign keys:")
+		fmt.Print(formatMySQLTable([]string{"Constraint", "Columns", "References", "On update", "On delete"}, foreignKeys))
+	}
+}
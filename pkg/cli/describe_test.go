@@ -0,0 +1,42 @@
+package cli
+
+import "testing"
+
+func TestLikePattern(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", "%"},
+		{"film*", "film%"},
+		{"f?lm", "f_lm"},
+		{"film_text", "film\\_text"},
+		{"100%", "100\\%"},
+	}
+
+	for _, test := range tests {
+		if got := likePattern(test.input); got != test.expected {
+			t.Errorf("likePattern(%q) = %q, expected %q", test.input, got, test.expected)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{16384, "16.0 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+		{3 * 1024 * 1024 * 1024, "3.0 GiB"},
+	}
+
+	for _, test := range tests {
+		if got := formatBytes(test.input); got != test.expected {
+			t.Errorf("formatBytes(%d) = %q, expected %q", test.input, got, test.expected)
+		}
+	}
+}
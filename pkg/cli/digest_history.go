@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+)
+
+// DigestStats is what performance_schema has recorded for the statements
+// sharing a query's digest, so EXPLAIN advice can weigh how often a query
+// really runs and how long it takes
+type DigestStats struct {
+	Digest          string  `json:"digest"`
+	Executions      int64   `json:"executions"`
+	TotalLatencyMs  float64 `json:"total_latency_ms"`
+	AvgLatencyMs    float64 `json:"avg_latency_ms"`
+	MaxLatencyMs    float64 `json:"max_latency_ms"`
+	AvgRowsExamined float64 `json:"avg_rows_examined"`
+	AvgRowsSent     float64 `json:"avg_rows_sent"`
+	NoIndexUsed     int64   `json:"no_index_used"` // executions that did a full scan
+	FirstSeen       string  `json:"first_seen"`
+	LastSeen        string  `json:"last_seen"`
+}
+
+// digestStats looks up query in events_statements_summary_by_digest,
+// preferring the row for the current database. It returns nil without an
+// error when the statement has not run since the summary was reset.
+// STATEMENT_DIGEST() needs MySQL 8.0.4 or later.
+func (p *PromptExecutor) digestStats(query string) (*DigestStats, error) {
+	var digest string
+	if err := p.db.QueryRow("SELECT STATEMENT_DIGEST(?)", query).Scan(&digest); err != nil {
+		return nil, err
+	}
+	var s DigestStats
+	var total, avg, maxWait, examined, sent int64
+	err := p.db.QueryRow(`
+		SELECT DIGEST, COUNT_STAR, SUM_TIMER_WAIT, AVG_TIMER_WAIT, MAX_TIMER_WAIT,
+		       SUM_ROWS_EXAMINED, SUM_ROWS_SENT, SUM_NO_INDEX_USED, FIRST_SEEN, LAST_SEEN
+		FROM performance_schema.events_statements_summary_by_digest
+		WHERE DIGEST = ?
+		ORDER BY SCHEMA_NAME <=> DATABASE() DESC, COUNT_STAR DESC
+		LIMIT 1`, digest).Scan(&s.Digest, &s.Executions, &total, &avg, &maxWait, &examined, &sent, &s.NoIndexUsed, &s.FirstSeen, &s.LastSeen)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Timers are in picoseconds
+	s.TotalLatencyMs = float64(total) / 1e9
+	s.AvgLatencyMs = float64(avg) / 1e9
+	s.MaxLatencyMs = float64(maxWait) / 1e9
+	if s.Executions > 0 {
+		s.AvgRowsExamined = float64(examined) / float64(s.Executions)
+		s.AvgRowsSent = float64(sent) / float64(s.Executions)
+	}
+	return &s, nil
+}
+
+// printDigestStats writes the execution history of a query under its plan
+func printDigestStats(w io.Writer, s *DigestStats) {
+	fmt.Fprintf(w, "\nExecution history (performance_schema, %s to %s):\n", s.FirstSeen, s.LastSeen)
+	fmt.Fprintf(w, "  %d execution%s, avg %s, max %s, total %s\n",
+		s.Executions, plural(int(s.Executions)), formatMillis(s.AvgLatencyMs), formatMillis(s.MaxLatencyMs), formatMillis(s.TotalLatencyMs))
+	fmt.Fprintf(w, "  %.0f rows examined and %.0f sent per execution", s.AvgRowsExamined, s.AvgRowsSent)
+	if s.NoIndexUsed > 0 {
+		fmt.Fprintf(w, ", %d without an index", s.NoIndexUsed)
+	}
+	fmt.Fprintln(w)
+}
+
+// formatMillis renders a latency in milliseconds, or in seconds from one
+// second up
+func formatMillis(ms float64) string {
+	if ms >= 1000 {
+		return fmt.Sprintf("%.2f s", ms/1000)
+	}
+	return fmt.Sprintf("%.3f ms", ms)
+}
@@ -0,0 +1,571 @@
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// doctorTimeout bounds each network step of go-mycli doctor
+const doctorTimeout = 5 * time.Second
+
+// Outcomes of a doctor check
+const (
+	doctorOK   = "ok"
+	doctorWarn = "warn"
+	doctorFail = "FAIL"
+	doctorSkip = "skip"
+)
+
+// Capability flags of the MySQL handshake the doctor reads or sends
+const (
+	clientProtocol41 = 0x200
+	clientSSL        = 0x800
+	clientSecureConn = 0x8000
+	clientPluginAuth = 0x80000
+)
+
+// supportedAuthPlugins are the authentication plugins go-sql-driver speaks,
+// with what they need, if anything
+var supportedAuthPlugins = map[string]string{
+	"caching_sha2_password": "",
+	"mysql_native_password": "",
+	"sha256_password":       "",
+	"mysql_clear_password":  "allowCleartextPasswords=true in the connection string, over TLS",
+	"mysql_old_password":    "allowOldPasswords=true in the connection string",
+}
+
+// doctorCheck is one line of the doctor's report
+type doctorCheck struct {
+	Name   string
+	Status string
+	Detail string
+	Hint   string // what to do about a warning or failure
+}
+
+// doctorReport collects the checks in the order they ran
+type doctorReport struct {
+	checks []doctorCheck
+}
+
+func (r *doctorReport) add(name, status, detail, hint string) {
+	r.checks = append(r.checks, doctorCheck{Name: name, Status: status, Detail: detail, Hint: hint})
+}
+
+// failed reports whether a check failed
+func (r *doctorReport) failed() bool {
+	for _, c := range r.checks {
+		if c.Status == doctorFail {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *doctorReport) print(w io.Writer) {
+	for _, c := range r.checks {
+		fmt.Fprintf(w, "%-5s %-12s %s\n", c.Status, c.Name, c.Detail)
+		if c.Hint != "" {
+			fmt.Fprintf(w, "%-5s %-12s -> %s\n", "", "", c.Hint)
+		}
+	}
+}
+
+// serverGreeting is the handshake a MySQL server sends first, or the error
+// it sends instead when it turns the client away before any login
+type serverGreeting struct {
+	Version      string
+	ConnectionID uint32
+	Capabilities uint32
+	AuthPlugin   string
+	ErrCode      uint16
+	ErrMessage   string
+}
+
+// readPacket reads one MySQL protocol packet
+func readPacket(r io.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	payload := make([]byte, int(header[0])|int(header[1])<<8|int(header[2])<<16)
+	_, err := io.ReadFull(r, payload)
+	return payload, err
+}
+
+// parseGreeting reads the initial handshake packet (protocol version 10)
+func parseGreeting(payload []byte) (*serverGreeting, error) {
+	if len(payload) == 0 {
+		return nil, fmt.Errorf("empty handshake")
+	}
+	if payload[0] == 0xff {
+		if len(payload) < 3 {
+			return nil, fmt.Errorf("short error packet")
+		}
+		g := &serverGreeting{ErrCode: binary.LittleEndian.Uint16(payload[1:3])}
+		msg := payload[3:]
+		if len(msg) >= 6 && msg[0] == '#' {
+			msg = msg[6:]
+		}
+		g.ErrMessage = string(msg)
+		return g, nil
+	}
+	if payload[0] != 10 {
+		return nil, fmt.Errorf("not a MySQL server (handshake protocol %d)", payload[0])
+	}
+	end := strings.IndexByte(string(payload[1:]), 0)
+	if end < 0 || len(payload) < 1+end+1+4+8+1+2 {
+		return nil, fmt.Errorf("truncated handshake")
+	}
+	g := &serverGreeting{Version: string(payload[1 : 1+end])}
+	pos := 1 + end + 1
+	g.ConnectionID = binary.LittleEndian.Uint32(payload[pos:])
+	pos += 4 + 8 + 1 // auth data part 1 and a filler
+	g.Capabilities = uint32(binary.LittleEndian.Uint16(payload[pos:]))
+	pos += 2
+	if len(payload) < pos+1+2+2+1+10 {
+		return g, nil
+	}
+	pos += 1 + 2 // character set and status flags
+	g.Capabilities |= uint32(binary.LittleEndian.Uint16(payload[pos:])) << 16
+	pos += 2
+	authLen := int(payload[pos])
+	pos += 1 + 10
+	if g.Capabilities&clientSecureConn != 0 {
+		pos += max(13, authLen-8)
+	}
+	if g.Capabilities&clientPluginAuth != 0 && pos < len(payload) {
+		name := payload[pos:]
+		if i := strings.IndexByte(string(name), 0); i >= 0 {
+			name = name[:i]
+		}
+		g.AuthPlugin = string(name)
+	}
+	return g, nil
+}
+
+// sslRequest is the packet that asks the server to switch to TLS before
+// any credentials are sent
+func sslRequest() []byte {
+	packet := make([]byte, 4+32)
+	packet[0], packet[3] = 32, 1 // payload length, sequence 1
+	binary.LittleEndian.PutUint32(packet[4:], clientProtocol41|clientSSL|clientSecureConn|clientPluginAuth)
+	binary.LittleEndian.PutUint32(packet[8:], 1<<24)
+	packet[12] = 45 // utf8mb4_general_ci
+	return packet
+}
+
+// Doctor checks each step of connecting in turn (name resolution, TCP, the
+// server's greeting, TLS, login) and then what the account can do, printing
+// what failed and what usually causes it instead of the driver's error. It
+// is the entry point of the doctor subcommand and returns an *ExitStatus
+// of 1 when a check failed.
+func Doctor(host string, port int, user, password, database, socket, loginPath, configFile string) error {
+	config, err := ReadMySQLConfig(loginPath, configFile)
+	if err != nil {
+		config = &MySQLConfig{}
+	}
+	m := MergeConfig(config, user, password, host, port, socket, database)
+	ctx := context.Background()
+	r := &doctorReport{}
+
+	var conn net.Conn
+	if m.Socket != "" {
+		fmt.Printf("Checking %s through socket %s\n\n", m.User, m.Socket)
+		conn = r.checkSocket(m.Socket)
+	} else {
+		fmt.Printf("Checking %s at %s:%d\n\n", m.User, m.Host, m.Port)
+		if r.checkDNS(ctx, m.Host) {
+			conn = r.checkTCP(ctx, m.Host, m.Port)
+		}
+	}
+	var greeting *serverGreeting
+	if conn != nil {
+		greeting = r.checkGreeting(conn, m)
+		if greeting != nil && greeting.ErrCode == 0 && m.Socket == "" {
+			r.checkTLS(conn, greeting, m.Host)
+		}
+		conn.Close()
+	}
+	if greeting == nil || greeting.ErrCode != 0 {
+		r.add("login", doctorSkip, "not tried: the server cannot be reached", "")
+		return r.finish()
+	}
+	r.checkAuthPlugin(greeting)
+
+	db, err := sql.Open("mysql", withConnectionParams(BuildDSN(m.User, m.Password, m.Host, m.Port, "", m.Socket, 0)))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	pingCtx, cancel := context.WithTimeout(ctx, 2*doctorTimeout)
+	err = db.PingContext(pingCtx)
+	cancel()
+	if err != nil {
+		detail, hint := diagnoseLoginError(err, m, greeting.AuthPlugin)
+		r.add("login", doctorFail, detail, hint)
+		return r.finish()
+	}
+	r.checkAccount(ctx, db, m.User)
+	if m.Database != "" {
+		r.checkDatabase(ctx, db, m.Database)
+	}
+	r.checkTimeZones(ctx, db)
+	r.checkPrivileges(ctx, db)
+	return r.finish()
+}
+
+// finish prints the report and turns a failed check into exit status 1
+func (r *doctorReport) finish() error {
+	r.print(os.Stdout)
+	if r.failed() {
+		return &ExitStatus{Code: 1}
+	}
+	warnings := 0
+	for _, c := range r.checks {
+		if c.Status == doctorWarn {
+			warnings++
+		}
+	}
+	if warnings > 0 {
+		fmt.Printf("\nConnecting works; %d warning%s above\n", warnings, plural(warnings))
+	} else {
+		fmt.Println("\nNo problems found")
+	}
+	return nil
+}
+
+func (r *doctorReport) checkSocket(path string) net.Conn {
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+	if socketNetwork == "pipe" {
+		conn, err := dialSocket(ctx, path)
+		if err != nil {
+			r.add("named pipe", doctorFail, err.Error(),
+				"the server is not running, or does not accept named pipes: see SELECT @@named_pipe, @@socket, or connect with --host 127.0.0.1")
+			return nil
+		}
+		r.add("named pipe", doctorOK, path, "")
+		return conn
+	}
+
+	info, err := os.Stat(path)
+	switch {
+	case err != nil:
+		r.add("socket", doctorFail, err.Error(),
+			"the server is not running, or uses another socket: see SELECT @@socket, or connect with --host 127.0.0.1")
+		return nil
+	case info.Mode()&os.ModeSocket == 0:
+		r.add("socket", doctorFail, path+" is not a socket", "")
+		return nil
+	}
+	conn, err := dialSocket(ctx, path)
+	if err != nil {
+		hint := "the server that created it is gone; start it again or remove the stale file"
+		if errors.Is(err, os.ErrPermission) {
+			hint = "your user may not open the socket; check the permissions of its directory"
+		}
+		r.add("socket", doctorFail, err.Error(), hint)
+		return nil
+	}
+	r.add("socket", doctorOK, path, "")
+	return conn
+}
+
+// checkDNS resolves host, reporting every address it has
+func (r *doctorReport) checkDNS(ctx context.Context, host string) bool {
+	if net.ParseIP(host) != nil {
+		r.add("dns", doctorOK, host+" is an address", "")
+		return true
+	}
+	ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		hint := "check the host name for typos; names inside a VPN or cluster only resolve when connected to it"
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsTimeout {
+			hint = "the DNS server did not answer; check the network and /etc/resolv.conf"
+		}
+		r.add("dns", doctorFail, err.Error(), hint)
+		return false
+	}
+	r.add("dns", doctorOK, fmt.Sprintf("%s resolves to %s", host, strings.Join(addrs, ", ")), "")
+	return true
+}
+
+// checkTCP connects to the server's port
+func (r *doctorReport) checkTCP(ctx context.Context, host string, port int) net.Conn {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	dialer := &net.Dialer{Timeout: doctorTimeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		r.add("tcp", doctorFail, err.Error(), tcpHint(err, port))
+		return nil
+	}
+	r.add("tcp", doctorOK, fmt.Sprintf("connected to %s in %s", conn.RemoteAddr(), formatLatency(time.Since(start))), "")
+	return conn
+}
+
+// tcpHint explains a failed TCP connection
+func tcpHint(err error, port int) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return fmt.Sprintf("nothing listens on port %d there: the server is down, listens on another port, or has bind_address = 127.0.0.1", port)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "no answer at all: a firewall or security group drops the packets, or the address is not routable from here"
+	case errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH):
+		return "no route to the host: check the VPN, the network and the address"
+	}
+	return ""
+}
+
+// checkGreeting reads what the server sends first. A server that refuses
+// the client outright says why here, before any login.
+func (r *doctorReport) checkGreeting(conn net.Conn, m *MySQLConfig) *serverGreeting {
+	_ = conn.SetDeadline(time.Now().Add(doctorTimeout))
+	payload, err := readPacket(conn)
+	if err != nil {
+		r.add("server", doctorFail, "no MySQL greeting: "+err.Error(),
+			"something else listens on this port, or a proxy or load balancer in between has no healthy server")
+		return nil
+	}
+	g, err := parseGreeting(payload)
+	if err != nil {
+		r.add("server", doctorFail, err.Error(), "something else listens on this port")
+		return nil
+	}
+	if g.ErrCode != 0 {
+		detail := fmt.Sprintf("refused before login: %s (error %d)", g.ErrMessage, g.ErrCode)
+		_, hint := diagnoseLoginError(&mysql.MySQLError{Number: g.ErrCode, Message: g.ErrMessage}, m, "")
+		r.add("server", doctorFail, detail, hint)
+		return g
+	}
+	r.add("server", doctorOK, fmt.Sprintf("MySQL %s, connection %d", g.Version, g.ConnectionID), "")
+	return g
+}
+
+// checkTLS asks the server for TLS and reports whether its certificate
+// would pass verification (ssl-mode=VERIFY_IDENTITY)
+func (r *doctorReport) checkTLS(conn net.Conn, g *serverGreeting, host string) {
+	if g.Capabilities&clientSSL == 0 {
+		r.add("tls", doctorWarn, "the server does not offer TLS; the connection is unencrypted",
+			"set ssl_cert and ssl_key on the server, or keep to trusted networks")
+		return
+	}
+	if _, err := conn.Write(sslRequest()); err != nil {
+		r.add("tls", doctorFail, err.Error(), "")
+		return
+	}
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: host, InsecureSkipVerify: true})
+	if err := tlsConn.Handshake(); err != nil {
+		r.add("tls", doctorFail, "handshake failed: "+err.Error(), "the server's TLS setup is broken; check its error log and tls_version")
+		return
+	}
+	state := tlsConn.ConnectionState()
+	version := strings.Replace(tls.VersionName(state.Version), "TLS 1", "TLSv1", 1)
+	cert := state.PeerCertificates[0]
+	intermediates := x509.NewCertPool()
+	for _, c := range state.PeerCertificates[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err := cert.Verify(x509.VerifyOptions{DNSName: host, Intermediates: intermediates})
+	if err != nil {
+		r.add("tls", doctorWarn, fmt.Sprintf("%s, certificate of %q not verified: %v", version, cert.Subject.CommonName, err),
+			"encryption works but the server is not authenticated (MySQL's generated certificate is self-signed); ssl-mode=REQUIRED connects, VERIFY_IDENTITY needs a certificate for this host")
+		return
+	}
+	r.add("tls", doctorOK, fmt.Sprintf("%s, certificate verified for %s", version, host), "")
+}
+
+// checkAuthPlugin reports whether the driver speaks the server's default
+// authentication plugin. An account on another plugin switches to it
+// during login, which the login check then covers.
+func (r *doctorReport) checkAuthPlugin(g *serverGreeting) {
+	if g.AuthPlugin == "" {
+		return
+	}
+	need, ok := supportedAuthPlugins[g.AuthPlugin]
+	switch {
+	case !ok:
+		r.add("auth", doctorWarn, "default plugin "+g.AuthPlugin+" is not supported",
+			"accounts on it cannot log in with go-mycli; use caching_sha2_password or mysql_native_password")
+	case need != "":
+		r.add("auth", doctorWarn, "default plugin "+g.AuthPlugin, "needs "+need)
+	default:
+		r.add("auth", doctorOK, "default plugin "+g.AuthPlugin, "")
+	}
+}
+
+// diagnoseLoginError explains an error of the login, plugin being the
+// server's default authentication plugin
+func diagnoseLoginError(err error, m *MySQLConfig, plugin string) (detail, hint string) {
+	detail = err.Error()
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		switch myErr.Number {
+		case errAccessDenied:
+			if m.Password == "" {
+				return detail, "no password was given: use -p, MYSQL_PWD or password in an option file"
+			}
+			return detail, fmt.Sprintf("wrong password, or no account matches %s from this client's address: accounts are user@host pairs (SELECT user, host, plugin FROM mysql.user WHERE user = '%s')", m.User, m.User)
+		case 1044:
+			return detail, "the account has no privileges on this database; connect without -D or GRANT access to it"
+		case 1049:
+			return detail, "the database does not exist; connect without -D to see SHOW DATABASES"
+		case 1130:
+			return detail, fmt.Sprintf("no account for %s allows this client's host; CREATE USER '%s'@'<your address or %%>'", m.User, m.User)
+		case 1129:
+			return detail, "too many failed connections from this host: FLUSH HOSTS (or TRUNCATE performance_schema.host_cache) and raise max_connect_errors"
+		case 1040:
+			return detail, "max_connections is reached; an account with CONNECTION_ADMIN or SUPER can still use the reserved connection"
+		case 3159:
+			return detail, "the server requires TLS: add ?ssl-mode=REQUIRED to the connection string"
+		case 1251:
+			return detail, "the account's authentication plugin is one the driver does not speak"
+		case 3118:
+			return detail, "the account is locked: ALTER USER ... ACCOUNT UNLOCK"
+		case 1862, 1820:
+			return detail, "the password has expired: set a new one with the mysql client (ALTER USER ... IDENTIFIED BY)"
+		}
+		return detail, ""
+	}
+	switch {
+	case errors.Is(err, mysql.ErrUnknownPlugin):
+		return detail, fmt.Sprintf("the account uses a plugin the driver does not speak (the server default is %s); auth_socket, PAM and LDAP accounts need the mysql client", plugin)
+	case errors.Is(err, mysql.ErrCleartextPassword):
+		return detail, "the account uses mysql_clear_password (PAM or LDAP): add ?allowCleartextPasswords=true, over TLS"
+	case errors.Is(err, mysql.ErrNativePassword):
+		return detail, "add ?allowNativePasswords=true to the connection string"
+	case errors.Is(err, context.DeadlineExceeded):
+		return detail, "the server accepted the connection but did not finish the login; it may be overloaded or stuck (check its error log)"
+	}
+	var certErr x509.UnknownAuthorityError
+	if errors.As(err, &certErr) {
+		return detail, "the server's certificate is not trusted: use ssl-mode=REQUIRED, or trust the CA that signed it"
+	}
+	return detail, ""
+}
+
+// checkAccount reports which account the server matched the login to and
+// its authentication plugin, when mysql.user may be read
+func (r *doctorReport) checkAccount(ctx context.Context, db *sql.DB, user string) {
+	var login, account string
+	if err := db.QueryRowContext(ctx, "SELECT USER(), CURRENT_USER()").Scan(&login, &account); err != nil {
+		r.add("login", doctorFail, err.Error(), "")
+		return
+	}
+	detail := "logged in as " + login
+	if !strings.EqualFold(login, account) {
+		detail += ", matched to account " + account
+	}
+	var plugin string
+	if err := db.QueryRowContext(ctx, `SELECT plugin FROM mysql.user
+		WHERE CONCAT(user, '@', host) = CURRENT_USER()`).Scan(&plugin); err == nil {
+		detail += " (" + plugin + ")"
+	}
+	hint := ""
+	if strings.HasPrefix(account, "@") {
+		hint = fmt.Sprintf("the anonymous account matched before %s's; DROP USER ''@'localhost' is the usual fix", user)
+	}
+	r.add("login", doctorOK, detail, hint)
+}
+
+func (r *doctorReport) checkDatabase(ctx context.Context, db *sql.DB, database string) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		r.add("database", doctorFail, err.Error(), "")
+		return
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "USE "+quoteIdentifier(database)); err != nil {
+		_, hint := diagnoseLoginError(err, &MySQLConfig{}, "")
+		r.add("database", doctorFail, err.Error(), hint)
+		return
+	}
+	r.add("database", doctorOK, database, "")
+}
+
+// checkTimeZones reports whether named time zones work, which needs the
+// mysql.time_zone tables loaded
+func (r *doctorReport) checkTimeZones(ctx context.Context, db *sql.DB) {
+	var zone, system string
+	var converted sql.NullString
+	err := db.QueryRowContext(ctx, "SELECT @@time_zone, @@system_time_zone, CONVERT_TZ('2024-01-01 12:00:00', 'UTC', 'Europe/Berlin')").
+		Scan(&zone, &system, &converted)
+	if err != nil {
+		r.add("time zones", doctorWarn, err.Error(), "")
+		return
+	}
+	detail := fmt.Sprintf("time_zone %s (system %s)", zone, system)
+	if !converted.Valid {
+		r.add("time zones", doctorWarn, detail+"; named zones are not loaded",
+			"CONVERT_TZ and SET time_zone = 'Europe/Berlin' fail: mysql_tzinfo_to_sql /usr/share/zoneinfo | mysql -u root mysql")
+		return
+	}
+	r.add("time zones", doctorOK, detail+"; named zones loaded", "")
+}
+
+// doctorPrivileges are the global privileges features of go-mycli rely on
+var doctorPrivileges = []struct {
+	Privilege string
+	Used      string
+}{
+	{"PROCESS", "other connections in SHOW PROCESSLIST, and the metadata locks \\drop-safe reports"},
+	{"REPLICATION CLIENT", "SHOW REPLICA STATUS for \\topology and \\replica-lag"},
+}
+
+// checkPrivileges reports the privileges the account lacks for go-mycli's
+// diagnostics, and whether performance_schema can be read
+func (r *doctorReport) checkPrivileges(ctx context.Context, db *sql.DB) {
+	grants, err := queryColumnNames(ctx, db, "SHOW GRANTS")
+	if err != nil {
+		r.add("privileges", doctorWarn, err.Error(), "")
+	} else {
+		for _, p := range doctorPrivileges {
+			if grantsInclude(grants, p.Privilege) {
+				r.add("privileges", doctorOK, p.Privilege, "")
+			} else {
+				r.add("privileges", doctorWarn, "no "+p.Privilege, "needed for "+p.Used)
+			}
+		}
+	}
+	var one int
+	if err := db.QueryRowContext(ctx, "SELECT 1 FROM performance_schema.threads LIMIT 1").Scan(&one); err != nil {
+		r.add("perf schema", doctorWarn, err.Error(), "\\profile, \\cost statistics and table activity need SELECT on performance_schema and performance_schema = ON")
+		return
+	}
+	r.add("perf schema", doctorOK, "readable", "")
+}
+
+// grantsInclude reports whether SHOW GRANTS lines give privilege on *.*
+func grantsInclude(grants []string, privilege string) bool {
+	for _, grant := range grants {
+		upper := strings.ToUpper(grant)
+		list, rest, ok := strings.Cut(strings.TrimPrefix(upper, "GRANT "), " ON ")
+		if !ok || !strings.HasPrefix(rest, "*.* TO ") {
+			continue
+		}
+		for _, p := range strings.Split(list, ",") {
+			if p = strings.TrimSpace(p); p == privilege || p == "ALL PRIVILEGES" || p == "ALL" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// greetingPacket builds the handshake a MySQL 8.0 server sends
+func greetingPacket(version, plugin string, caps uint32) []byte {
+	var p bytes.Buffer
+	p.WriteByte(10)
+	p.WriteString(version)
+	p.WriteByte(0)
+	binary.Write(&p, binary.LittleEndian, uint32(42))
+	p.WriteString("abcdefgh")
+	p.WriteByte(0)
+	binary.Write(&p, binary.LittleEndian, uint16(caps))
+	p.WriteByte(255)
+	binary.Write(&p, binary.LittleEndian, uint16(2))
+	binary.Write(&p, binary.LittleEndian, uint16(caps>>16))
+	p.WriteByte(21)
+	p.Write(make([]byte, 10))
+	p.WriteString("ijklmnopqrst")
+	p.WriteByte(0)
+	p.WriteString(plugin)
+	p.WriteByte(0)
+	payload := p.Bytes()
+	return append([]byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), 0}, payload...)
+}
+
+func TestParseGreeting(t *testing.T) {
+	caps := uint32(clientProtocol41 | clientSSL | clientSecureConn | clientPluginAuth)
+	payload, err := readPacket(bytes.NewReader(greetingPacket("8.0.36", "caching_sha2_password", caps)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := parseGreeting(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Version != "8.0.36" || g.ConnectionID != 42 || g.AuthPlugin != "caching_sha2_password" || g.Capabilities != caps {
+		t.Errorf("parseGreeting = %+v", g)
+	}
+
+	refused := append([]byte{0xff, 0x6a, 0x04}, "Host '10.0.0.9' is not allowed to connect to this MySQL server"...)
+	g, err = parseGreeting(refused)
+	if err != nil || g.ErrCode != 1130 || !strings.HasPrefix(g.ErrMessage, "Host '10.0.0.9'") {
+		t.Errorf("parseGreeting(error packet) = %+v, %v", g, err)
+	}
+	for _, bad := range [][]byte{nil, {9, 'x'}, {10, '8', '.', '0'}} {
+		if _, err := parseGreeting(bad); err == nil {
+			t.Errorf("parseGreeting(%q) should fail", bad)
+		}
+	}
+}
+
+func TestCheckGreeting(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	go func() {
+		server.Write(greetingPacket("8.4.0", "mysql_native_password", clientProtocol41|clientSecureConn|clientPluginAuth))
+		server.Close()
+	}()
+	r := &doctorReport{}
+	g := r.checkGreeting(client, &MySQLConfig{User: "app"})
+	if g == nil || g.Version != "8.4.0" || r.failed() {
+		t.Fatalf("checkGreeting = %+v, report %+v", g, r.checks)
+	}
+	r.checkAuthPlugin(g)
+	if last := r.checks[len(r.checks)-1]; last.Status != doctorOK {
+		t.Errorf("mysql_native_password should be supported: %+v", last)
+	}
+	r.checkAuthPlugin(&serverGreeting{AuthPlugin: "auth_pam"})
+	if last := r.checks[len(r.checks)-1]; last.Status != doctorWarn {
+		t.Errorf("auth_pam should be a warning: %+v", last)
+	}
+}
+
+func TestSSLRequest(t *testing.T) {
+	packet := sslRequest()
+	if len(packet) != 36 || packet[0] != 32 || packet[3] != 1 {
+		t.Fatalf("header = %v", packet[:4])
+	}
+	if caps := binary.LittleEndian.Uint32(packet[4:]); caps&clientSSL == 0 || caps&clientProtocol41 == 0 {
+		t.Errorf("capabilities = %#x", caps)
+	}
+}
+
+func TestCheckTCPRefused(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+	r := &doctorReport{}
+	if conn := r.checkTCP(t.Context(), "127.0.0.1", port); conn != nil {
+		conn.Close()
+		t.Skip("port was reused")
+	}
+	if !r.failed() || !strings.Contains(r.checks[0].Hint, "nothing listens") {
+		t.Errorf("refused connection: %+v", r.checks)
+	}
+}
+
+func TestDiagnoseLoginError(t *testing.T) {
+	m := &MySQLConfig{User: "app", Password: "secret"}
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&mysql.MySQLError{Number: 1045, Message: "Access denied"}, "user@host pairs"},
+		{&mysql.MySQLError{Number: 1049, Message: "Unknown database"}, "does not exist"},
+		{&mysql.MySQLError{Number: 1130, Message: "Host not allowed"}, "CREATE USER 'app'"},
+		{&mysql.MySQLError{Number: 3159, Message: "secure transport"}, "ssl-mode=REQUIRED"},
+		{mysql.ErrUnknownPlugin, "auth_pam"},
+		{mysql.ErrCleartextPassword, "allowCleartextPasswords"},
+	}
+	for _, tt := range tests {
+		if _, hint := diagnoseLoginError(tt.err, m, "auth_pam"); !strings.Contains(hint, tt.want) {
+			t.Errorf("diagnoseLoginError(%v) hint = %q, want it to mention %q", tt.err, hint, tt.want)
+		}
+	}
+	if _, hint := diagnoseLoginError(&mysql.MySQLError{Number: 1045}, &MySQLConfig{User: "app"}, ""); !strings.Contains(hint, "no password") {
+		t.Errorf("access denied without a password: %q", hint)
+	}
+	if _, hint := diagnoseLoginError(errors.New("bad connection"), m, ""); hint != "" {
+		t.Errorf("unknown error got hint %q", hint)
+	}
+}
+
+func TestGrantsInclude(t *testing.T) {
+	grants := []string{
+		"GRANT PROCESS, REPLICATION CLIENT ON *.* TO `app`@`%`",
+		"GRANT SELECT, INSERT ON `shop`.* TO `app`@`%`",
+	}
+	for privilege, want := range map[string]bool{"PROCESS": true, "REPLICATION CLIENT": true, "SELECT": false, "SUPER": false} {
+		if got := grantsInclude(grants, privilege); got != want {
+			t.Errorf("grantsInclude(%s) = %v, want %v", privilege, got, want)
+		}
+	}
+	if !grantsInclude([]string{"GRANT ALL PRIVILEGES ON *.* TO `root`@`localhost` WITH GRANT OPTION"}, "PROCESS") {
+		t.Error("ALL PRIVILEGES should include PROCESS")
+	}
+}
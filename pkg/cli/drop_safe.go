@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// tableActivitySample is how long \drop-safe and \truncate-safe watch the
+// table's I/O counters for current activity
+const tableActivitySample = 2 * time.Second
+
+// tableDependencies is what \drop-safe and \truncate-safe look at before
+// removing a table or its rows
+type tableDependencies struct {
+	Rows        int64
+	Size        int64
+	ForeignKeys []string // other tables' foreign keys that reference the table
+	Triggers    []string
+	Views       []string // views that select from the table (MySQL 8.0.13+)
+	// Activity is from performance_schema, when it is on: reads and writes
+	// since the server started, those during the sample, and the connections
+	// holding metadata locks on the table, which the statement waits for
+	Activity     bool
+	Reads        int64
+	Writes       int64
+	RecentReads  int64
+	RecentWrites int64
+	Lockers      []string
+}
+
+// findings describes what removing the table (DROP) or its rows (TRUNCATE)
+// would run into, most serious first
+func (d *tableDependencies) findings(action string) []string {
+	var lines []string
+	if len(d.ForeignKeys) > 0 {
+		what := "TRUNCATE a table other tables reference"
+		if action == "DROP" {
+			what = "DROP a table other tables reference"
+		}
+		lines = append(lines, fmt.Sprintf("Referenced by foreign key%s: %s. MySQL refuses to %s unless foreign_key_checks = 0, which leaves their rows pointing at nothing",
+			plural(len(d.ForeignKeys)), strings.Join(d.ForeignKeys, ", "), what))
+	}
+	if len(d.Lockers) > 0 {
+		lines = append(lines, fmt.Sprintf("In use by connection%s %s (metadata locks); the %s waits for them and blocks every new query on the table meanwhile",
+			plural(len(d.Lockers)), strings.Join(d.Lockers, ", "), action))
+	}
+	if d.RecentReads+d.RecentWrites > 0 {
+		lines = append(lines, fmt.Sprintf("Active: %d read%s and %d write%s in the last %s",
+			d.RecentReads, plural(int(d.RecentReads)), d.RecentWrites, plural(int(d.RecentWrites)), tableActivitySample))
+	}
+	if len(d.Triggers) > 0 {
+		if action == "DROP" {
+			lines = append(lines, fmt.Sprintf("Trigger%s dropped with it: %s", plural(len(d.Triggers)), strings.Join(d.Triggers, ", ")))
+		} else {
+			lines = append(lines, fmt.Sprintf("Trigger%s not fired by TRUNCATE: %s", plural(len(d.Triggers)), strings.Join(d.Triggers, ", ")))
+		}
+	}
+	if len(d.Views) > 0 && action == "DROP" {
+		lines = append(lines, fmt.Sprintf("View%s that stop working: %s", plural(len(d.Views)), strings.Join(d.Views, ", ")))
+	}
+	if d.Activity {
+		lines = append(lines, fmt.Sprintf("%d read%s and %d write%s since the server started",
+			d.Reads, plural(int(d.Reads)), d.Writes, plural(int(d.Writes))))
+	} else {
+		lines = append(lines, "Activity unknown: performance_schema is off")
+	}
+	return lines
+}
+
+// tableDependencies reads what \drop-safe and \truncate-safe report about
+// db.table. It returns sql.ErrNoRows when the table does not exist.
+func (p *PromptExecutor) tableDependencies(ctx context.Context, db, table string) (*tableDependencies, error) {
+	d := &tableDependencies{}
+	err := p.db.QueryRowContext(ctx, `SELECT COALESCE(TABLE_ROWS, 0), COALESCE(DATA_LENGTH + INDEX_LENGTH, 0)
+		FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND TABLE_TYPE = 'BASE TABLE'`, db, table).Scan(&d.Rows, &d.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := []struct {
+		into  *[]string
+		query string
+	}{
+		{&d.ForeignKeys, `SELECT DISTINCT CONCAT(TABLE_SCHEMA, '.', TABLE_NAME, ' (', CONSTRAINT_NAME, ')')
+			FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE
+			WHERE REFERENCED_TABLE_SCHEMA = ? AND REFERENCED_TABLE_NAME = ?
+			AND NOT (TABLE_SCHEMA = REFERENCED_TABLE_SCHEMA AND TABLE_NAME = REFERENCED_TABLE_NAME)`},
+		{&d.Triggers, `SELECT CONCAT(TRIGGER_NAME, ' (', ACTION_TIMING, ' ', EVENT_MANIPULATION, ')')
+			FROM INFORMATION_SCHEMA.TRIGGERS WHERE EVENT_OBJECT_SCHEMA = ? AND EVENT_OBJECT_TABLE = ?`},
+	}
+	for _, list := range lists {
+		if *list.into, err = p.queryColumn(ctx, list.query, db, table); err != nil {
+			return nil, err
+		}
+	}
+	// VIEW_TABLE_USAGE is missing before MySQL 8.0.13, which leaves views unchecked
+	d.Views, _ = p.queryColumn(ctx, `SELECT CONCAT(VIEW_SCHEMA, '.', VIEW_NAME) FROM INFORMATION_SCHEMA.VIEW_TABLE_USAGE
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?`, db, table)
+
+	reads, writes, err := p.tableIO(ctx, db, table)
+	if err != nil {
+		return d, nil
+	}
+	d.Activity, d.Reads, d.Writes = true, reads, writes
+	select {
+	case <-time.After(tableActivitySample):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if r, w, err := p.tableIO(ctx, db, table); err == nil {
+		d.Reads, d.Writes = r, w
+		d.RecentReads, d.RecentWrites = r-reads, w-writes
+	}
+	d.Lockers, _ = p.queryColumn(ctx, `SELECT DISTINCT CAST(t.PROCESSLIST_ID AS CHAR)
+		FROM performance_schema.metadata_locks l
+		JOIN performance_schema.threads t ON t.THREAD_ID = l.OWNER_THREAD_ID
+		WHERE l.OBJECT_TYPE = 'TABLE' AND l.OBJECT_SCHEMA = ? AND l.OBJECT_NAME = ?
+		AND t.PROCESSLIST_ID IS NOT NULL AND t.PROCESSLIST_ID <> CONNECTION_ID()`, db, table)
+	return d, nil
+}
+
+// tableIO returns the reads and writes performance_schema counted on a table
+func (p *PromptExecutor) tableIO(ctx context.Context, db, table string) (reads, writes int64, err error) {
+	err = p.db.QueryRowContext(ctx, `SELECT COALESCE(SUM(COUNT_READ), 0), COALESCE(SUM(COUNT_WRITE), 0)
+		FROM performance_schema.table_io_waits_summary_by_table
+		WHERE OBJECT_TYPE = 'TABLE' AND OBJECT_SCHEMA = ? AND OBJECT_NAME = ?`, db, table).Scan(&reads, &writes)
+	return reads, writes, err
+}
+
+// queryColumn returns the first column of every row of query
+func (p *PromptExecutor) queryColumn(ctx context.Context, query string, args ...interface{}) ([]string, error) {
+	_, rows, err := p.queryStringsContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, len(rows))
+	for i, row := range rows {
+		values[i] = row[0]
+	}
+	return values, nil
+}
+
+// removeTableSafely implements \drop-safe and \truncate-safe: it reports the
+// foreign keys, triggers, views and current activity of the table, then runs
+// DROP TABLE or TRUNCATE TABLE only once the table's name is typed back
+func (p *PromptExecutor) removeTableSafely(action, args string) {
+	name := strings.TrimSuffix(strings.TrimSpace(args), ";")
+	if !qualifiedTableName.MatchString(name) {
+		fmt.Printf("Usage: \\%s-safe <table>\n", strings.ToLower(action))
+		return
+	}
+	db, table := splitTableName(name, p.database)
+	if db == "" {
+		fmt.Println("No database selected; use \\u <db> or a qualified table name")
+		return
+	}
+	if !p.canPrompt() {
+		fmt.Printf("\\%s-safe asks for the table name and needs an interactive session\n", strings.ToLower(action))
+		return
+	}
+	ctx, stop := p.statementContext()
+	defer stop()
+
+	fmt.Printf("Checking %s.%s...\n", db, table)
+	d, err := p.tableDependencies(ctx, db, table)
+	if err == sql.ErrNoRows {
+		fmt.Printf("Table %s.%s does not exist\n", db, table)
+		return
+	}
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), canceled(ctx, err))
+		return
+	}
+	fmt.Printf("Table %s.%s: about %s rows, %s\n", db, table, formatRowCount(float64(d.Rows)), formatBytes(d.Size))
+	for _, line := range d.findings(action) {
+		fmt.Printf("  %s\n", line)
+	}
+
+	stmt := action + " TABLE " + quoteIdentifier(db) + "." + quoteIdentifier(table)
+	if !confirmTableName(fmt.Sprintf("Type %s to run %s: ", table, stmt), db, table) {
+		fmt.Printf("%s not run\n", action)
+		return
+	}
+	res, err := p.runStatement(ctx, stmt)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return
+	}
+	p.cacheTime = time.Time{}
+	fmt.Printf("%s TABLE done (%.3fs)\n", action, res.Duration.Seconds())
+}
+
+// confirmTableName asks for the table's name, with or without its
+// database; anything else is a no
+func confirmTableName(question, db, table string) bool {
+	fmt.Print(question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return tableNameMatches(strings.TrimSpace(answer), db, table)
+}
+
+// tableNameMatches reports whether answer names db.table exactly
+func tableNameMatches(answer, db, table string) bool {
+	if answer == "" || !qualifiedTableName.MatchString(answer) {
+		return false
+	}
+	gotDB, gotTable := splitTableName(answer, db)
+	return gotDB == db && gotTable == table
+}
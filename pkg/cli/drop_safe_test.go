@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTableDependencyFindings(t *testing.T) {
+	d := &tableDependencies{
+		ForeignKeys:  []string{"sakila.inventory (fk_inventory_film)"},
+		Triggers:     []string{"del_film (AFTER DELETE)"},
+		Views:        []string{"sakila.film_list"},
+		Activity:     true,
+		Reads:        5043,
+		RecentReads:  12,
+		RecentWrites: 1,
+		Lockers:      []string{"42"},
+	}
+	drop := strings.Join(d.findings("DROP"), "\n")
+	for _, want := range []string{
+		"Referenced by foreign key: sakila.inventory (fk_inventory_film). MySQL refuses to DROP",
+		"In use by connection 42 (metadata locks); the DROP waits",
+		"Active: 12 reads and 1 write in the last 2s",
+		"Trigger dropped with it: del_film (AFTER DELETE)",
+		"View that stop working: sakila.film_list",
+		"5043 reads and 0 writes since the server started",
+	} {
+		if !strings.Contains(drop, want) {
+			t.Errorf("DROP findings lack %q:\n%s", want, drop)
+		}
+	}
+
+	truncate := strings.Join(d.findings("TRUNCATE"), "\n")
+	if !strings.Contains(truncate, "Trigger not fired by TRUNCATE") || strings.Contains(truncate, "film_list") {
+		t.Errorf("TRUNCATE findings:\n%s", truncate)
+	}
+
+	quiet := (&tableDependencies{}).findings("DROP")
+	if len(quiet) != 1 || quiet[0] != "Activity unknown: performance_schema is off" {
+		t.Errorf("findings = %q", quiet)
+	}
+}
+
+func TestTableNameMatches(t *testing.T) {
+	tests := []struct {
+		answer string
+		want   bool
+	}{
+		{"film", true},
+		{"sakila.film", true},
+		{"`sakila`.`film`", true},
+		{"Film", false},
+		{"other.film", false},
+		{"yes", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		if got := tableNameMatches(test.answer, "sakila", "film"); got != test.want {
+			t.Errorf("tableNameMatches(%q) = %v, expected %v", test.answer, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,429 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var (
+	// editableSelect matches a SELECT from one table, optionally aliased,
+	// filtered, ordered and limited: select list, table and alias
+	editableSelect = regexp.MustCompile("(?is)^SELECT\\s+(.+?)\\s+FROM\\s+((?:(?:`[^`]+`|[\\w$]+)\\s*\\.\\s*)?(?:`[^`]+`|[\\w$]+))" +
+		"(?:\\s+(?:AS\\s+)?(`[^`]+`|[\\w$]+))?(?:\\s+(?:WHERE|ORDER\\s+BY|LIMIT)\\b.*)?$")
+	// editableColumn is an entry of the select list that names a column as
+	// it is, or *
+	editableColumn = regexp.MustCompile("^(?:(?:`[^`]+`|[\\w$]+)\\s*\\.\\s*)?(?:`[^`]+`|[\\w$]+|\\*)$")
+	// uneditableClause finds what makes the rows of a result no longer the
+	// rows of the table
+	uneditableClause = regexp.MustCompile(`(?i)\b(?:GROUP\s+BY|HAVING|UNION|DISTINCT|INTO|FOR\s+UPDATE|LOCK\s+IN)\b`)
+)
+
+// editableTable returns the table a SELECT reads when its rows can be
+// edited: one table, and only plain columns in the select list
+func editableTable(query string) (database, table string, ok bool) {
+	query = strings.TrimSuffix(strings.TrimSpace(stripLeadingComments(query)), ";")
+	m := editableSelect.FindStringSubmatch(query)
+	if m == nil || uneditableClause.MatchString(query) {
+		return "", "", false
+	}
+	switch strings.ToUpper(m[3]) {
+	case "WHERE", "ORDER", "LIMIT", "JOIN", "STRAIGHT_JOIN":
+		return "", "", false
+	}
+	for _, col := range strings.Split(m[1], ",") {
+		if !editableColumn.MatchString(strings.TrimSpace(col)) {
+			return "", "", false
+		}
+	}
+	database, table = splitTableName(m[2], "")
+	return database, table, true
+}
+
+// tableColumn is a column of the table being edited
+type tableColumn struct {
+	Name      string
+	Key       bool // part of the primary key
+	Generated bool
+}
+
+// editTarget maps the columns of a result to the table it is edited in
+type editTarget struct {
+	Database string
+	Table    string
+	Key      []int  // result columns holding the primary key, in key order
+	Writable []bool // result columns that may be edited
+}
+
+// newEditTarget checks that a result holds the whole primary key of the
+// table and finds the columns that may be changed: those of the table that
+// are neither part of the key nor generated
+func newEditTarget(database, table string, columns []string, tableCols []tableColumn) (*editTarget, error) {
+	t := &editTarget{Database: database, Table: table, Writable: make([]bool, len(columns))}
+	var missing []string
+	for _, tc := range tableCols {
+		found := -1
+		for i, name := range columns {
+			if strings.EqualFold(name, tc.Name) {
+				t.Writable[i] = !tc.Key && !tc.Generated
+				if found < 0 {
+					found = i
+				}
+			}
+		}
+		switch {
+		case !tc.Key:
+		case found < 0:
+			missing = append(missing, tc.Name)
+		default:
+			t.Key = append(t.Key, found)
+		}
+	}
+	switch {
+	case len(tableCols) == 0:
+		return nil, fmt.Errorf("table %s.%s not found", database, table)
+	case len(t.Key) == 0 && len(missing) == 0:
+		return nil, fmt.Errorf("%s has no primary key, so its rows cannot be told apart", table)
+	case len(missing) > 0:
+		return nil, fmt.Errorf("the result must include the primary key of %s (%s)", table, strings.Join(missing, ", "))
+	}
+	return t, nil
+}
+
+// rowEdit holds the changes to one row: the values of its primary key and
+// the new value of each changed column, nil for NULL
+type rowEdit struct {
+	Key []string
+	Set map[int]*string
+	Old map[int]string // values as shown before the change, for undo
+}
+
+// rowEditor is \view with cells that can be changed; the changes are kept
+// per row, identified by its primary key, so sorting does not lose them
+type rowEditor struct {
+	*resultViewer
+	target  *editTarget
+	edits   map[string]*rowEdit
+	order   []string // row keys in the order they were first changed
+	editing bool
+	value   []rune
+	discard bool
+}
+
+func newRowEditor(columns []string, rows [][]string, target *editTarget) *rowEditor {
+	e := &rowEditor{resultViewer: newResultViewer(columns, rows), target: target, edits: make(map[string]*rowEdit)}
+	// The viewer's rows are changed as cells are edited
+	for i, row := range e.rows {
+		e.rows[i] = append([]string(nil), row...)
+	}
+	e.help = "e edit • u undo • s sort • / search • q done • esc discard"
+	e.marked = e.changed
+	return e
+}
+
+// changed reports whether a cell of row has been changed, possibly to NULL
+func (e *rowEditor) changed(row []string, col int) bool {
+	edit := e.edits[e.rowKey(row)]
+	if edit == nil {
+		return false
+	}
+	_, ok := edit.Set[col]
+	return ok
+}
+
+// rowKey identifies a row by its primary key values
+func (e *rowEditor) rowKey(row []string) string {
+	parts := make([]string, len(e.target.Key))
+	for i, col := range e.target.Key {
+		parts[i] = row[col]
+	}
+	return strings.Join(parts, "\x00")
+}
+
+func (e *rowEditor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		if e.editing {
+			e.updateInput(key)
+			return e, nil
+		}
+		switch key.String() {
+		case "enter", "e":
+			e.startEdit()
+			return e, nil
+		case "u":
+			e.undo()
+			return e, nil
+		case "q":
+			return e, tea.Quit
+		case "esc", "ctrl+c":
+			e.discard = true
+			return e, tea.Quit
+		}
+	}
+	e.resultViewer.Update(msg)
+	return e, nil
+}
+
+// startEdit opens the value of the cell under the cursor for editing
+func (e *rowEditor) startEdit() {
+	if len(e.rows) == 0 {
+		return
+	}
+	if !e.target.Writable[e.col] {
+		e.status = fmt.Sprintf("%s cannot be changed here (primary key, generated or not a column of %s)", e.columns[e.col], e.target.Table)
+		return
+	}
+	row := e.rows[e.row]
+	e.editing, e.value = true, []rune(row[e.col])
+	// A NULL starts out empty, unless it is the text NULL typed in
+	if row[e.col] == "NULL" {
+		if edit := e.edits[e.rowKey(row)]; edit == nil || edit.Set[e.col] == nil {
+			e.value = nil
+		}
+	}
+}
+
+// updateInput handles keys while a value is being typed: enter keeps it,
+// ctrl+n sets NULL and esc leaves the cell as it was
+func (e *rowEditor) updateInput(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		e.editing = false
+	case tea.KeyEnter:
+		value := string(e.value)
+		e.set(&value)
+		e.editing = false
+	case tea.KeyCtrlN:
+		e.set(nil)
+		e.editing = false
+	case tea.KeyBackspace:
+		if len(e.value) > 0 {
+			e.value = e.value[:len(e.value)-1]
+		}
+	case tea.KeyCtrlU:
+		e.value = nil
+	case tea.KeyRunes, tea.KeySpace:
+		e.value = append(e.value, msg.Runes...)
+	}
+	e.status = ""
+}
+
+// set changes the cell under the cursor, nil setting it to NULL
+func (e *rowEditor) set(value *string) {
+	row := e.rows[e.row]
+	key := e.rowKey(row)
+	edit := e.edits[key]
+	if edit == nil {
+		keyValues := make([]string, len(e.target.Key))
+		for i, col := range e.target.Key {
+			keyValues[i] = row[col]
+		}
+		edit = &rowEdit{Key: keyValues, Set: make(map[int]*string), Old: make(map[int]string)}
+		e.edits[key] = edit
+		e.order = append(e.order, key)
+	}
+	if _, ok := edit.Old[e.col]; !ok {
+		edit.Old[e.col] = row[e.col]
+	}
+	edit.Set[e.col] = value
+	if value == nil {
+		row[e.col] = "NULL"
+	} else {
+		row[e.col] = *value
+	}
+}
+
+// undo puts back the value the cell under the cursor had before it was
+// changed
+func (e *rowEditor) undo() {
+	if len(e.rows) == 0 {
+		return
+	}
+	row := e.rows[e.row]
+	if !e.changed(row, e.col) {
+		return
+	}
+	edit := e.edits[e.rowKey(row)]
+	row[e.col] = edit.Old[e.col]
+	delete(edit.Set, e.col)
+	delete(edit.Old, e.col)
+}
+
+func (e *rowEditor) View() string {
+	if e.editing {
+		e.status = fmt.Sprintf("%s = %s█  enter save • ctrl+n NULL • ctrl+u clear • esc cancel", e.columns[e.col], string(e.value))
+	}
+	return e.resultViewer.View()
+}
+
+// changes returns the edited rows in the order they were first changed,
+// leaving out rows whose changes were all undone
+func (e *rowEditor) changes() []*rowEdit {
+	var edits []*rowEdit
+	for _, key := range e.order {
+		if edit := e.edits[key]; len(edit.Set) > 0 {
+			edits = append(edits, edit)
+		}
+	}
+	return edits
+}
+
+// editStatement is one UPDATE of \edit-rows, with placeholders for the
+// values, and as shown in the preview
+type editStatement struct {
+	SQL     string
+	Args    []interface{}
+	Preview string
+}
+
+// editStatements builds an UPDATE for each changed row, setting its changed
+// columns where its primary key matches
+func editStatements(target *editTarget, columns []string, edits []*rowEdit) []editStatement {
+	table := quoteIdentifier(target.Table)
+	if target.Database != "" {
+		table = quoteIdentifier(target.Database) + "." + table
+	}
+	var statements []editStatement
+	for _, edit := range edits {
+		var sets, previewSets, where, previewWhere []string
+		var args []interface{}
+		for col := range columns {
+			value, ok := edit.Set[col]
+			if !ok {
+				continue
+			}
+			sets = append(sets, quoteIdentifier(columns[col])+" = ?")
+			previewSets = append(previewSets, quoteIdentifier(columns[col])+" = "+sqlLiteral(value))
+			if value == nil {
+				args = append(args, nil)
+			} else {
+				args = append(args, *value)
+			}
+		}
+		for i, col := range target.Key {
+			where = append(where, quoteIdentifier(columns[col])+" = ?")
+			previewWhere = append(previewWhere, quoteIdentifier(columns[col])+" = "+sqlLiteral(&edit.Key[i]))
+			args = append(args, edit.Key[i])
+		}
+		statements = append(statements, editStatement{
+			SQL:     "UPDATE " + table + " SET " + strings.Join(sets, ", ") + " WHERE " + strings.Join(where, " AND "),
+			Args:    args,
+			Preview: "UPDATE " + table + " SET " + strings.Join(previewSets, ", ") + " WHERE " + strings.Join(previewWhere, " AND ") + ";",
+		})
+	}
+	return statements
+}
+
+// sqlLiteral quotes a value as a string literal, nil being NULL
+func sqlLiteral(value *string) string {
+	if value == nil {
+		return "NULL"
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", "''").Replace(*value) + "'"
+}
+
+// editRows implements \edit-rows: the last result, if it is a SELECT from
+// one table that includes the primary key, opens in the viewer with cells
+// that can be changed. The UPDATE statements for the changes are shown and,
+// once confirmed, run in one transaction.
+func (p *PromptExecutor) editRows() {
+	if p.input == nil {
+		fmt.Println("\\edit-rows requires an interactive terminal")
+		return
+	}
+	res := p.lastResult
+	if res == nil || len(res.Rows) == 0 {
+		fmt.Println("No result to edit; run a SELECT on one table first")
+		return
+	}
+	database, table, ok := editableTable(res.Statement)
+	if !ok {
+		fmt.Println("\\edit-rows needs a SELECT from one table with plain columns in the select list (no joins, expressions or GROUP BY)")
+		return
+	}
+	if database == "" {
+		database = p.database
+	}
+	if database == "" {
+		fmt.Println("No database selected")
+		return
+	}
+
+	_, rows, err := p.queryStrings(`SELECT COLUMN_NAME, COLUMN_KEY, EXTRA FROM INFORMATION_SCHEMA.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION`, database, table)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return
+	}
+	tableCols := make([]tableColumn, len(rows))
+	for i, row := range rows {
+		tableCols[i] = tableColumn{Name: row[0], Key: row[1] == "PRI", Generated: strings.Contains(strings.ToUpper(row[2]), "GENERATED")}
+	}
+	target, err := newEditTarget(database, table, res.Columns, tableCols)
+	if err != nil {
+		fmt.Printf("Cannot edit these rows: %v\n", err)
+		return
+	}
+
+	// The values as the server sent them, not as [format] displays them
+	values := make([][]string, len(res.Values))
+	for i, row := range res.Values {
+		values[i] = make([]string, len(row))
+		for j, v := range row {
+			values[i][j] = "NULL"
+			if v != nil {
+				values[i][j] = fmt.Sprint(v)
+			}
+		}
+	}
+	editor := newRowEditor(res.Columns, values, target)
+	if _, err := tea.NewProgram(editor, tea.WithAltScreen()).Run(); err != nil {
+		fmt.Printf("Editor failed: %v\n", err)
+		return
+	}
+	edits := editor.changes()
+	if editor.discard || len(edits) == 0 {
+		fmt.Println(tr("No changes made"))
+		return
+	}
+
+	statements := editStatements(target, res.Columns, edits)
+	for _, stmt := range statements {
+		fmt.Println(stmt.Preview)
+	}
+	if !askYesNo(fmt.Sprintf("Run %d UPDATE statement%s? [y/N] ", len(statements), plural(len(statements)))) {
+		fmt.Println(tr("No changes made"))
+		return
+	}
+	ctx, stop := p.statementContext()
+	defer stop()
+	changed, err := p.applyEdits(ctx, statements)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n")+tr("No changes made")+"\n", err)
+		return
+	}
+	fmt.Printf("%d row%s changed; run the SELECT again to see the stored values\n", changed, plural(int(changed)))
+}
+
+// applyEdits runs the statements of \edit-rows in one transaction and
+// returns how many rows they changed
+func (p *PromptExecutor) applyEdits(ctx context.Context, statements []editStatement) (int64, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	var changed int64
+	for _, stmt := range statements {
+		r, err := tx.ExecContext(ctx, stmt.SQL, stmt.Args...)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		n, _ := r.RowsAffected()
+		changed += n
+	}
+	return changed, tx.Commit()
+}
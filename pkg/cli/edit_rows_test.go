@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestEditableTable(t *testing.T) {
+	tests := []struct {
+		query    string
+		database string
+		table    string
+		ok       bool
+	}{
+		{"SELECT * FROM film", "", "film", true},
+		{"select film_id, title from sakila.film where rating = 'PG' order by title limit 10;", "sakila", "film", true},
+		{"SELECT f.film_id, f.`title` FROM `film` AS f WHERE f.film_id < 10", "", "film", true},
+		{"/* list */ SELECT * FROM film f LIMIT 5", "", "film", true},
+		{"SELECT * FROM film f JOIN language l USING (language_id)", "", "", false},
+		{"SELECT * FROM film, language", "", "", false},
+		{"SELECT film_id, UPPER(title) FROM film", "", "", false},
+		{"SELECT film_id, title AS name FROM film", "", "", false},
+		{"SELECT DISTINCT rating FROM film", "", "", false},
+		{"SELECT rating, film_id FROM film GROUP BY rating, film_id", "", "", false},
+		{"SELECT film_id FROM film UNION SELECT film_id FROM inventory", "", "", false},
+		{"SHOW TABLES", "", "", false},
+	}
+	for _, test := range tests {
+		database, table, ok := editableTable(test.query)
+		if ok != test.ok || database != test.database || table != test.table {
+			t.Errorf("%s: got %q %q %v", test.query, database, table, ok)
+		}
+	}
+}
+
+var filmColumns = []tableColumn{
+	{Name: "film_id", Key: true},
+	{Name: "title"},
+	{Name: "rental_rate"},
+	{Name: "title_length", Generated: true},
+}
+
+func TestNewEditTarget(t *testing.T) {
+	target, err := newEditTarget("sakila", "film", []string{"TITLE", "film_id", "title_length", "note"}, filmColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(target.Key, []int{1}) || !reflect.DeepEqual(target.Writable, []bool{true, false, false, false}) {
+		t.Errorf("got key %v, writable %v", target.Key, target.Writable)
+	}
+
+	if _, err := newEditTarget("sakila", "film", []string{"title"}, filmColumns); err == nil || !strings.Contains(err.Error(), "(film_id)") {
+		t.Errorf("got %v", err)
+	}
+	if _, err := newEditTarget("sakila", "log", []string{"msg"}, []tableColumn{{Name: "msg"}}); err == nil || !strings.Contains(err.Error(), "no primary key") {
+		t.Errorf("got %v", err)
+	}
+	if _, err := newEditTarget("sakila", "nope", []string{"id"}, nil); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("got %v", err)
+	}
+}
+
+func keys(m tea.Model, keys ...tea.KeyMsg) {
+	for _, k := range keys {
+		m.Update(k)
+	}
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestRowEditor(t *testing.T) {
+	columns := []string{"film_id", "title", "rental_rate"}
+	target, _ := newEditTarget("sakila", "film", columns, filmColumns)
+	rows := [][]string{{"2", "ACE GOLDFINGER", "4.99"}, {"1", "ACADEMY DINOSAUR", "0.99"}}
+	e := newRowEditor(columns, rows, target)
+
+	// The key cannot be edited
+	keys(e, runes("e"))
+	if e.editing || !strings.Contains(e.status, "film_id cannot be changed") {
+		t.Errorf("editing %v, status %q", e.editing, e.status)
+	}
+
+	// Change a title, then sort by id so the row moves, and set its rate to NULL
+	keys(e, runes("l"), runes("e"), tea.KeyMsg{Type: tea.KeyCtrlU}, runes("ACE"), tea.KeyMsg{Type: tea.KeyEnter})
+	keys(e, runes("h"), runes("s"), runes("j"), runes("l"), runes("l"), runes("e"), tea.KeyMsg{Type: tea.KeyCtrlN})
+	// Change and undo the other row's title
+	keys(e, runes("k"), runes("h"), tea.KeyMsg{Type: tea.KeyEnter}, runes("!"), tea.KeyMsg{Type: tea.KeyEnter}, runes("u"))
+
+	if rows[0][1] != "ACE GOLDFINGER" {
+		t.Error("the caller's rows should not change")
+	}
+	if !e.changed(e.rows[1], 1) || !e.changed(e.rows[1], 2) || e.changed(e.rows[0], 1) {
+		t.Errorf("changed cells: %v", e.edits)
+	}
+
+	statements := editStatements(target, columns, e.changes())
+	if len(statements) != 1 {
+		t.Fatalf("got %d statements", len(statements))
+	}
+	s := statements[0]
+	if s.SQL != "UPDATE sakila.film SET title = ?, rental_rate = ? WHERE film_id = ?" {
+		t.Errorf("SQL: %s", s.SQL)
+	}
+	if !reflect.DeepEqual(s.Args, []interface{}{"ACE", nil, "2"}) {
+		t.Errorf("args: %#v", s.Args)
+	}
+	if s.Preview != "UPDATE sakila.film SET title = 'ACE', rental_rate = NULL WHERE film_id = '2';" {
+		t.Errorf("preview: %s", s.Preview)
+	}
+
+	keys(e, tea.KeyMsg{Type: tea.KeyEsc})
+	if !e.discard {
+		t.Error("esc should discard the changes")
+	}
+}
+
+func TestSQLLiteral(t *testing.T) {
+	value := `it's C:\temp`
+	if got := sqlLiteral(&value); got != `'it''s C:\\temp'` {
+		t.Errorf("got %s", got)
+	}
+	if got := sqlLiteral(nil); got != "NULL" {
+		t.Errorf("got %s", got)
+	}
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// planStep is one row of a traditional EXPLAIN, reduced to what the row
+// estimate needs
+type planStep struct {
+	ID       int64
+	Rows     float64
+	Filtered float64 // percent, 100 when the server does not report it
+	Access   string  // the type column: ALL, index, range, ref...
+	Key      string  // the index used, "" for none
+}
+
+// confirmRowEstimate runs EXPLAIN before an interactive SELECT and, when the
+// optimizer expects to examine more rows than row_estimate_warning, asks
+// before running it. It returns false if the user declined.
+func (p *PromptExecutor) confirmRowEstimate(stmt string) bool {
+	if p.rowEstimateWarning <= 0 || p.input == nil || p.sourceFileMode || !isSelectStatement(stmt) {
+		return true
+	}
+	steps, err := p.explainSteps(stmt)
+	if err != nil {
+		// The statement will report its own error
+		return true
+	}
+	rows := estimateExaminedRows(steps)
+	if rows < float64(p.rowEstimateWarning) {
+		return true
+	}
+	return askYesNo(fmt.Sprintf("This will examine ~%s rows. Continue? [y/N] ", formatRowCount(rows)))
+}
+
+// isSelectStatement reports whether stmt is a plain read (SELECT or WITH)
+func isSelectStatement(stmt string) bool {
+	upper := strings.ToUpper(strings.TrimLeft(stmt, " \t\n("))
+	return strings.HasPrefix(upper, "SELECT") || strings.HasPrefix(upper, "WITH")
+}
+
+// explainSteps runs a traditional EXPLAIN and returns its id, rows,
+// filtered, type and key columns
+func (p *PromptExecutor) explainSteps(stmt string) ([]planStep, error) {
+	rows, err := p.db.Query("EXPLAIN " + stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var steps []planStep
+	values := make([]sql.NullString, len(cols))
+	dest := make([]any, len(cols))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		step := planStep{Filtered: 100}
+		for i, col := range cols {
+			v := values[i]
+			if !v.Valid {
+				continue
+			}
+			switch strings.ToLower(col) {
+			case "id":
+				step.ID, _ = strconv.ParseInt(v.String, 10, 64)
+			case "rows":
+				step.Rows, _ = strconv.ParseFloat(v.String, 64)
+			case "type":
+				step.Access = v.String
+			case "key":
+				step.Key = v.String
+			case "filtered":
+				if f, err := strconv.ParseFloat(v.String, 64); err == nil {
+					step.Filtered = f
+				}
+			}
+		}
+		steps = append(steps, step)
+	}
+	return steps, rows.Err()
+}
+
+// estimateExaminedRows adds up the rows the optimizer expects to read. Within
+// one SELECT (same id) tables are joined in nested loops, so each table is
+// read once per row that survives the tables before it.
+func estimateExaminedRows(steps []planStep) float64 {
+	var total float64
+	prefix := make(map[int64]float64)
+	for _, s := range steps {
+		rowsIn, ok := prefix[s.ID]
+		if !ok {
+			rowsIn = 1
+		}
+		total += rowsIn * s.Rows
+		prefix[s.ID] = rowsIn * s.Rows * s.Filtered / 100
+	}
+	return total
+}
+
+// formatRowCount renders a row count as e.g. 950, 12K, 40M or 1.2B
+func formatRowCount(n float64) string {
+	switch {
+	case n >= 1e9:
+		return trimZero(fmt.Sprintf("%.1f", n/1e9)) + "B"
+	case n >= 1e6:
+		return trimZero(fmt.Sprintf("%.1f", n/1e6)) + "M"
+	case n >= 1e3:
+		return trimZero(fmt.Sprintf("%.1f", n/1e3)) + "K"
+	}
+	return strconv.FormatFloat(n, 'f', 0, 64)
+}
+
+func trimZero(s string) string {
+	return strings.TrimSuffix(s, ".0")
+}
+
+// askYesNo asks a question on the terminal; only y or yes counts as yes
+func askYesNo(question string) bool {
+	fmt.Print(question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package cli
+
+import "testing"
+
+func TestEstimateExaminedRows(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []planStep
+		want  float64
+	}{
+		{"single table", []planStep{{ID: 1, Rows: 5000, Filtered: 10}}, 5000},
+		{"nested loop join", []planStep{
+			{ID: 1, Rows: 1000, Filtered: 10},
+			{ID: 1, Rows: 50, Filtered: 100},
+		}, 1000 + 100*50},
+		{"subquery counted separately", []planStep{
+			{ID: 1, Rows: 200, Filtered: 100},
+			{ID: 2, Rows: 300, Filtered: 100},
+		}, 500},
+		{"no rows", nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estimateExaminedRows(tt.steps); got != tt.want {
+				t.Errorf("estimateExaminedRows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatRowCount(t *testing.T) {
+	tests := []struct {
+		n    float64
+		want string
+	}{
+		{950, "950"},
+		{12000, "12K"},
+		{40000000, "40M"},
+		{1250000, "1.2M"},
+		{1200000000, "1.2B"},
+	}
+	for _, tt := range tests {
+		if got := formatRowCount(tt.n); got != tt.want {
+			t.Errorf("formatRowCount(%v) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestIsSelectStatement(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want bool
+	}{
+		{"SELECT * FROM t", true},
+		{"  with x as (select 1) select * from x", true},
+		{"(SELECT 1) UNION (SELECT 2)", true},
+		{"SHOW TABLES", false},
+		{"UPDATE t SET a = 1", false},
+	}
+	for _, tt := range tests {
+		if got := isSelectStatement(tt.stmt); got != tt.want {
+			t.Errorf("isSelectStatement(%q) = %v, want %v", tt.stmt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Options configures an Executor
+type Options struct {
+	// DB is the connection statements run on. The Executor does not close it.
+	DB *sql.DB
+	// Database is the default database of DB, which the analysis of an
+	// EXPLAIN uses for tables the query does not qualify
+	Database string
+	// Format is applied to result values as the [format] config section is
+	Format FormatConfig
+	// Warnings reads the server's warnings about each statement into
+	// Result.Warnings, at the cost of a round trip
+	Warnings bool
+	// VisualExplain adds the plan tree to the report of each EXPLAIN
+	VisualExplain bool
+	// AIAnalysis asks the AI backend about the plan of each EXPLAIN; it
+	// needs AIServerMode or AIServerURL
+	AIAnalysis    bool
+	AIServerURL   string
+	AIServerMode  string // copilot_mcp_http, openai or mcp_stdio
+	AICachePath   string
+	AIMCPCommand  string
+	AIDetailLevel string // basic, detailed or expert
+}
+
+// Executor runs statements for Go programs that embed go-mycli instead of
+// spawning the binary. Results are returned rather than printed, and an
+// EXPLAIN comes with the score, findings and analysis the client shows.
+// An Executor is not safe for concurrent use.
+type Executor struct {
+	p *PromptExecutor
+}
+
+// NewExecutor returns an Executor that runs statements on opts.DB
+func NewExecutor(opts Options) (*Executor, error) {
+	if opts.DB == nil {
+		return nil, errors.New("cli: Options.DB is required")
+	}
+	if opts.AIAnalysis && opts.AIServerMode == "" && opts.AIServerURL == "" {
+		return nil, errors.New("cli: AI analysis needs Options.AIServerMode or AIServerURL")
+	}
+	return &Executor{p: &PromptExecutor{
+		db:                  opts.DB,
+		database:            opts.Database,
+		columns:             make(map[string][]string),
+		format:              opts.Format,
+		showWarnings:        opts.Warnings,
+		nonInteractive:      true,
+		enableAIAnalysis:    opts.AIAnalysis,
+		enableVisualExplain: opts.VisualExplain,
+		aiServerURL:         opts.AIServerURL,
+		aiServerMode:        opts.AIServerMode,
+		aiCachePath:         opts.AICachePath,
+		aiMCPCommand:        opts.AIMCPCommand,
+		aiDetailLevel:       opts.AIDetailLevel,
+	}}, nil
+}
+
+// Execute runs one statement and returns its result. Statements are adapted
+// to the server's flavor as in the client, so EXPLAIN ANALYZE also works on
+// MariaDB. For an EXPLAIN the plan is scored and, as configured, drawn as a
+// tree and sent to the AI backend; a failure of the AI backend is an error.
+func (e *Executor) Execute(ctx context.Context, stmt string) (*Result, error) {
+	stmt = strings.TrimSuffix(strings.TrimSpace(stmt), ";")
+	if stmt == "" {
+		return nil, errors.New("cli: empty statement")
+	}
+	p := e.p
+	stmt = p.adaptStatement(stmt)
+	res, err := p.runStatement(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	if !isExplainQuery(stmt) {
+		p.formatResult(res.Types, res.Rows)
+		return res, nil
+	}
+
+	query, _, err := extractQueryFromExplain(stmt)
+	if err != nil {
+		return nil, err
+	}
+	planJSON, err := p.explainJSONPlan(stmt, res.Columns, res.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("could not obtain the JSON plan: %w", err)
+	}
+	res.Explain, err = p.reportPlan(ctx, query, planJSON, planTree(res.Columns, res.Rows), p.enableAIAnalysis)
+	if err != nil {
+		return nil, err
+	}
+	if p.enableVisualExplain {
+		res.Explain.Visual, _ = p.visualExplain(planJSON)
+	}
+	return res, nil
+}
+
+// Close releases the AI backend, stopping an mcp_stdio child process. The
+// DB is left open.
+func (e *Executor) Close() error {
+	e.p.closeAIClient()
+	return nil
+}
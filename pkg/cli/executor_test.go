@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+func TestNewExecutorValidatesOptions(t *testing.T) {
+	if _, err := NewExecutor(Options{}); err == nil {
+		t.Error("an executor needs a DB")
+	}
+
+	// sql.Open does not connect, so no server is needed here
+	db, err := sql.Open("mysql", "user:pass@tcp(127.0.0.1:1)/sakila")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := NewExecutor(Options{DB: db, AIAnalysis: true}); err == nil {
+		t.Error("AI analysis needs a backend")
+	}
+
+	exec, err := NewExecutor(Options{DB: db, Database: "sakila", AIAnalysis: true, AIServerMode: "mcp_stdio"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exec.Close()
+	if exec.p.database != "sakila" || !exec.p.enableAIAnalysis || !exec.p.nonInteractive {
+		t.Errorf("options not applied: %+v", exec.p)
+	}
+	if _, err := exec.Execute(context.Background(), " ; "); err == nil {
+		t.Error("an empty statement should be rejected")
+	}
+}
+
+func TestReturnsRows(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want bool
+	}{
+		{"SELECT 1", true},
+		{"/* report */ SELECT * FROM film", true},
+		{"SHOW TABLES", true},
+		{"DESCRIBE film", true},
+		{"EXPLAIN SELECT 1", true},
+		{"UPDATE film SET rating = 'G' WHERE film_id = 1", false},
+		{"CREATE TABLE t (id INT)", false},
+	}
+	for _, test := range tests {
+		if got := returnsRows(test.sql); got != test.want {
+			t.Errorf("%q: got %v", test.sql, got)
+		}
+	}
+}
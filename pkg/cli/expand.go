@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// truncateFields returns rows with every cell cut to at most width characters.
+// The original rows are not modified; they are returned as is when width is 0
+// or nothing needed truncating.
+func truncateFields(rows [][]string, width int) ([][]string, bool) {
+	if width <= 0 {
+		return rows, false
+	}
+	out, truncated := rows, false
+	for i, row := range rows {
+		var copied []string
+		for j, cell := range row {
+			if len([]rune(cell)) <= width {
+				continue
+			}
+			if !truncated {
+				out, truncated = append([][]string(nil), rows...), true
+			}
+			if copied == nil {
+				copied = append([]string(nil), row...)
+				out[i] = copied
+			}
+			copied[j] = truncateDisplay(strings.ReplaceAll(cell, "\n", " "), width)
+		}
+	}
+	return out, truncated
+}
+
+// expandCell implements \expand <row> <column>: print one cell of the last
+// result in full. Rows are numbered from 1; the column is a name or a number.
+func (p *PromptExecutor) expandCell(args string) {
+	fields := strings.Fields(args)
+	if len(fields) != 2 {
+		fmt.Println("Usage: \\expand <row> <column>")
+		return
+	}
+	if p.lastResult == nil || len(p.lastResult.Rows) == 0 {
+		fmt.Println("No result to expand")
+		return
+	}
+	last := p.lastResult
+
+	row, err := strconv.Atoi(fields[0])
+	if err != nil || row < 1 || row > len(last.Rows) {
+		fmt.Printf("Row must be between 1 and %d\n", len(last.Rows))
+		return
+	}
+	col := resultColumnIndex(last.Columns, fields[1])
+	if col < 0 {
+		fmt.Printf("Unknown column %s\n", fields[1])
+		return
+	}
+
+	fmt.Printf("%s: %s\n", last.Columns[col], last.Rows[row-1][col])
+}
+
+// resultColumnIndex finds a column by 1-based number or by name, ignoring case
+func resultColumnIndex(columns []string, name string) int {
+	if n, err := strconv.Atoi(name); err == nil {
+		if n >= 1 && n <= len(columns) {
+			return n - 1
+		}
+		return -1
+	}
+	for i, col := range columns {
+		if strings.EqualFold(col, strings.Trim(name, "`")) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTruncateFields(t *testing.T) {
+	rows := [][]string{
+		{"1", "short"},
+		{"2", strings.Repeat("x", 20)},
+		{"3", "line one\nline two"},
+	}
+
+	got, truncated := truncateFields(rows, 10)
+	if !truncated {
+		t.Fatalf("expected truncation")
+	}
+	want := [][]string{
+		{"1", "short"},
+		{"2", "xxxxxxxxx…"},
+		{"3", "line one …"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("truncateFields = %q, want %q", got, want)
+	}
+	if rows[1][1] != strings.Repeat("x", 20) {
+		t.Errorf("original rows were modified: %q", rows[1][1])
+	}
+
+	if _, truncated := truncateFields(rows, 0); truncated {
+		t.Errorf("width 0 should not truncate")
+	}
+	if _, truncated := truncateFields(rows, 100); truncated {
+		t.Errorf("nothing should be truncated at width 100")
+	}
+}
+
+func TestResultColumnIndex(t *testing.T) {
+	columns := []string{"film_id", "title", "description"}
+	tests := map[string]int{
+		"1":            0,
+		"3":            2,
+		"4":            -1,
+		"0":            -1,
+		"Description":  2,
+		"`title`":      1,
+		"release_year": -1,
+	}
+	for name, want := range tests {
+		if got := resultColumnIndex(columns, name); got != want {
+			t.Errorf("resultColumnIndex(%q) = %d, want %d", name, got, want)
+		}
+	}
+}
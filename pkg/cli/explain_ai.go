@@ -1,8 +1,10 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -21,12 +23,23 @@ type ExplainAnalysis struct {
 type SchemaInfo struct {
 	Tables  []TableInfo `json:"tables"`
 	Indexes []IndexInfo `json:"indexes"`
+	// Workload is the execution history of the query being explained,
+	// when performance_schema has one
+	Workload *DigestStats `json:"workload,omitempty"`
+	// Statistics are the ANALYZE TABLE runs the plan calls for
+	Statistics []StatisticsSuggestion `json:"statistics_suggestions,omitempty"`
 }
 
 // TableInfo represents table column information
 type TableInfo struct {
-	TableName string       `json:"table_name"`
-	Columns   []ColumnInfo `json:"columns"`
+	TableName        string                `json:"table_name"`
+	TableRows        int64                 `json:"table_rows"` // InnoDB's estimate
+	AvgRowLength     int64                 `json:"avg_row_length"`
+	StatsNote        string                `json:"stats_note,omitempty"` // set when the statistics are old
+	Columns          []ColumnInfo          `json:"columns"`
+	Partitioning     *PartitionInfo        `json:"partitioning,omitempty"`
+	CheckConstraints []CheckConstraintInfo `json:"check_constraints,omitempty"`
+	Histograms       []HistogramInfo       `json:"histograms,omitempty"`
 }
 
 // ColumnInfo represents column metadata
@@ -36,30 +49,62 @@ type ColumnInfo struct {
 	ColumnType string `json:"column_type"`
 	IsNullable string `json:"is_nullable"`
 	ColumnKey  string `json:"column_key"`
-	Extra      string `json:"extra"`
+	Extra      string `json:"extra"` // VIRTUAL GENERATED or STORED GENERATED for generated columns
+	// GenerationExpression is the expression of a generated column
+	GenerationExpression string `json:"generation_expression,omitempty"`
+}
+
+// PartitionInfo describes how a partitioned table is split
+type PartitionInfo struct {
+	Method          string   `json:"method"` // RANGE, LIST, HASH, KEY, ...
+	Expression      string   `json:"expression"`
+	SubMethod       string   `json:"subpartition_method,omitempty"`
+	SubExpression   string   `json:"subpartition_expression,omitempty"`
+	Partitions      []string `json:"partitions"`
+	PartitionBounds []string `json:"partition_bounds,omitempty"` // VALUES LESS THAN / IN of each partition
+}
+
+// HistogramInfo describes a column histogram (MySQL 8.0 and later)
+type HistogramInfo struct {
+	ColumnName  string `json:"column_name"`
+	Type        string `json:"type"` // singleton or equi-height
+	Buckets     int    `json:"buckets"`
+	LastUpdated string `json:"last_updated"`
+}
+
+// CheckConstraintInfo represents a CHECK constraint
+type CheckConstraintInfo struct {
+	Name   string `json:"name"`
+	Clause string `json:"clause"`
 }
 
 // IndexInfo represents index metadata
 type IndexInfo struct {
-	TableName  string `json:"table_name"`
-	NonUnique  int    `json:"non_unique"`
-	IndexName  string `json:"index_name"`
-	SeqInIndex int    `json:"seq_in_index"`
-	ColumnName string `json:"column_name"`
+	TableName   string `json:"table_name"`
+	NonUnique   int    `json:"non_unique"`
+	IndexName   string `json:"index_name"`
+	SeqInIndex  int    `json:"seq_in_index"`
+	ColumnName  string `json:"column_name"`
+	Cardinality int64  `json:"cardinality"` // estimated distinct values of the index prefix up to this column
 }
 
+// staleStatsDays is the age in days of table statistics that the snapshot
+// warns about
+const staleStatsDays = 7
+
 // collectSchemaSnapshot collects table and index metadata for the current database
-func (p *PromptExecutor) collectSchemaSnapshot() (*SchemaInfo, error) {
+func (p *PromptExecutor) collectSchemaSnapshot(ctx context.Context) (*SchemaInfo, error) {
 	schema := &SchemaInfo{}
 
 	// Collect table column information
 	columnsQuery := `
-		SELECT TABLE_NAME, COLUMN_NAME, ORDINAL_POSITION, COLUMN_TYPE, IS_NULLABLE, COLUMN_KEY, EXTRA
+		SELECT TABLE_NAME, COLUMN_NAME, ORDINAL_POSITION, COLUMN_TYPE, IS_NULLABLE, COLUMN_KEY, EXTRA,
+		       COALESCE(GENERATION_EXPRESSION, '')
 		FROM INFORMATION_SCHEMA.COLUMNS
 		WHERE TABLE_SCHEMA = DATABASE()
 		ORDER BY TABLE_NAME, ORDINAL_POSITION`
 
-	rows, err := p.db.Query(columnsQuery)
+	rows, err := p.db.QueryContext(ctx, columnsQuery)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query columns: %w", err)
 	}
@@ -69,7 +114,7 @@ func (p *PromptExecutor) collectSchemaSnapshot() (*SchemaInfo, error) {
 	for rows.Next() {
 		var col ColumnInfo
 		var tableName string
-		err := rows.Scan(&tableName, &col.ColumnName, &col.OrdinalPos, &col.ColumnType, &col.IsNullable, &col.ColumnKey, &col.Extra)
+		err := rows.Scan(&tableName, &col.ColumnName, &col.OrdinalPos, &col.ColumnType, &col.IsNullable, &col.ColumnKey, &col.Extra, &col.GenerationExpression)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan column row: %w", err)
 		}
@@ -80,18 +125,92 @@ func (p *PromptExecutor) collectSchemaSnapshot() (*SchemaInfo, error) {
 		tableMap[tableName].Columns = append(tableMap[tableName].Columns, col)
 	}
 
+	// Sizes let the advice weigh selectivity. The statistics date is in
+	// mysql.innodb_table_stats, which not every user can read.
+	_, sizes, _ := p.queryStringsContext(ctx, `
+		SELECT TABLE_NAME, COALESCE(TABLE_ROWS, 0), COALESCE(AVG_ROW_LENGTH, 0)
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE TABLE_SCHEMA = DATABASE()`)
+	for _, row := range sizes {
+		if table := tableMap[row[0]]; table != nil {
+			table.TableRows, _ = strconv.ParseInt(row[1], 10, 64)
+			table.AvgRowLength, _ = strconv.ParseInt(row[2], 10, 64)
+		}
+	}
+	_, ages, _ := p.queryStringsContext(ctx, `
+		SELECT table_name, TIMESTAMPDIFF(DAY, last_update, NOW())
+		FROM mysql.innodb_table_stats
+		WHERE database_name = DATABASE()`)
+	for _, row := range ages {
+		if table := tableMap[row[0]]; table != nil {
+			if days, err := strconv.Atoi(row[1]); err == nil {
+				table.StatsNote = staleStatsNote(days)
+			}
+		}
+	}
+
+	// Partitioning, CHECK constraints and histograms are left out where the
+	// server has no such tables (CHECK_CONSTRAINTS is MySQL 8.0.16 and later,
+	// COLUMN_STATISTICS 8.0)
+	_, partitions, _ := p.queryStringsContext(ctx, `
+		SELECT TABLE_NAME, PARTITION_NAME, PARTITION_METHOD, COALESCE(PARTITION_EXPRESSION, ''),
+		       COALESCE(SUBPARTITION_METHOD, ''), COALESCE(SUBPARTITION_EXPRESSION, ''), COALESCE(PARTITION_DESCRIPTION, '')
+		FROM INFORMATION_SCHEMA.PARTITIONS
+		WHERE TABLE_SCHEMA = DATABASE() AND PARTITION_NAME IS NOT NULL
+		  AND (SUBPARTITION_ORDINAL_POSITION IS NULL OR SUBPARTITION_ORDINAL_POSITION = 1)
+		ORDER BY TABLE_NAME, PARTITION_ORDINAL_POSITION`)
+	for _, row := range partitions {
+		table := tableMap[row[0]]
+		if table == nil {
+			continue
+		}
+		if table.Partitioning == nil {
+			table.Partitioning = &PartitionInfo{Method: row[2], Expression: row[3], SubMethod: row[4], SubExpression: row[5]}
+		}
+		table.Partitioning.Partitions = append(table.Partitioning.Partitions, row[1])
+		if row[6] != "" {
+			table.Partitioning.PartitionBounds = append(table.Partitioning.PartitionBounds, row[6])
+		}
+	}
+
+	_, checks, _ := p.queryStringsContext(ctx, `
+		SELECT t.TABLE_NAME, c.CONSTRAINT_NAME, c.CHECK_CLAUSE
+		FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS t
+		JOIN INFORMATION_SCHEMA.CHECK_CONSTRAINTS c
+		  ON c.CONSTRAINT_SCHEMA = t.CONSTRAINT_SCHEMA AND c.CONSTRAINT_NAME = t.CONSTRAINT_NAME
+		WHERE t.TABLE_SCHEMA = DATABASE() AND t.CONSTRAINT_TYPE = 'CHECK'
+		ORDER BY t.TABLE_NAME, c.CONSTRAINT_NAME`)
+	for _, row := range checks {
+		if table := tableMap[row[0]]; table != nil {
+			table.CheckConstraints = append(table.CheckConstraints, CheckConstraintInfo{Name: row[1], Clause: row[2]})
+		}
+	}
+
+	_, histograms, _ := p.queryStringsContext(ctx, `
+		SELECT TABLE_NAME, COLUMN_NAME, HISTOGRAM->>'$."histogram-type"',
+		       JSON_LENGTH(HISTOGRAM->'$.buckets'), HISTOGRAM->>'$."last-updated"'
+		FROM INFORMATION_SCHEMA.COLUMN_STATISTICS
+		WHERE SCHEMA_NAME = DATABASE()
+		ORDER BY TABLE_NAME, COLUMN_NAME`)
+	for _, row := range histograms {
+		if table := tableMap[row[0]]; table != nil {
+			buckets, _ := strconv.Atoi(row[3])
+			table.Histograms = append(table.Histograms, HistogramInfo{ColumnName: row[1], Type: row[2], Buckets: buckets, LastUpdated: row[4]})
+		}
+	}
+
 	for _, table := range tableMap {
 		schema.Tables = append(schema.Tables, *table)
 	}
 
 	// Collect index information
 	indexesQuery := `
-		SELECT TABLE_NAME, NON_UNIQUE, INDEX_NAME, SEQ_IN_INDEX, COLUMN_NAME
+		SELECT TABLE_NAME, NON_UNIQUE, INDEX_NAME, SEQ_IN_INDEX, COALESCE(COLUMN_NAME, ''), COALESCE(CARDINALITY, 0)
 		FROM INFORMATION_SCHEMA.STATISTICS
 		WHERE TABLE_SCHEMA = DATABASE()
 		ORDER BY TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX`
 
-	rows, err = p.db.Query(indexesQuery)
+	rows, err = p.db.QueryContext(ctx, indexesQuery)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query indexes: %w", err)
 	}
@@ -99,7 +218,7 @@ func (p *PromptExecutor) collectSchemaSnapshot() (*SchemaInfo, error) {
 
 	for rows.Next() {
 		var idx IndexInfo
-		err := rows.Scan(&idx.TableName, &idx.NonUnique, &idx.IndexName, &idx.SeqInIndex, &idx.ColumnName)
+		err := rows.Scan(&idx.TableName, &idx.NonUnique, &idx.IndexName, &idx.SeqInIndex, &idx.ColumnName, &idx.Cardinality)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan index row: %w", err)
 		}
@@ -109,6 +228,15 @@ func (p *PromptExecutor) collectSchemaSnapshot() (*SchemaInfo, error) {
 	return schema, nil
 }
 
+// staleStatsNote returns a note for statistics days old, or "" while they
+// are recent
+func staleStatsNote(days int) string {
+	if days < staleStatsDays {
+		return ""
+	}
+	return fmt.Sprintf("statistics are %d days old, so table_rows and cardinality may be off; ANALYZE TABLE refreshes them", days)
+}
+
 // extractQueryFromExplain extracts the original query from an EXPLAIN statement
 func extractQueryFromExplain(explainStmt string) (string, string, error) {
 	// Remove EXPLAIN prefix and any FORMAT clauses
@@ -135,55 +263,43 @@ func extractQueryFromExplain(explainStmt string) (string, string, error) {
 		}
 	}
 
-	// Handle ANALYZE
+	// Handle ANALYZE, which may carry its own FORMAT clause (EXPLAIN ANALYZE
+	// FORMAT=TREE, MariaDB's ANALYZE FORMAT=JSON)
 	if strings.HasPrefix(strings.ToUpper(query), "ANALYZE ") {
 		format = "ANALYZE"
 		query = strings.TrimSpace(query[8:])
+		if strings.HasPrefix(strings.ToUpper(query), "FORMAT=") {
+			if i := strings.IndexAny(query, " \t\n"); i > 0 {
+				query = strings.TrimSpace(query[i:])
+			}
+		}
 	}
 
 	return query, format, nil
 }
 
-// analyzeExplainWithAI sends EXPLAIN data to AI for performance analysis
-func (p *PromptExecutor) analyzeExplainWithAI(explainStmt string, explainOutput string) error {
-	// Extract the original query and format
-	originalQuery, format, err := extractQueryFromExplain(explainStmt)
+// analyzeExplainWithAI sends the JSON plan of an EXPLAIN to AI for
+// performance analysis with the statistics refreshes it calls for, and
+// prints and returns the advice
+func (p *PromptExecutor) analyzeExplainWithAI(ctx context.Context, explainStmt string, jsonPlan string, statistics []StatisticsSuggestion) (string, error) {
+	// Extract the original query
+	originalQuery, _, err := extractQueryFromExplain(explainStmt)
 	if err != nil {
-		return fmt.Errorf("failed to extract query from EXPLAIN: %w", err)
-	}
-
-	var jsonPlan string
-
-	// Use MySQL 8.4+ JSON capture feature if available
-	if p.isMySQL84Plus() && format != "ANALYZE" {
-		jsonPlan, err = p.executeExplainWithJSONCapture(explainStmt)
-		if err != nil {
-			// Fall back to parsing the output if JSON capture fails
-			fmt.Printf("JSON capture failed, falling back to output parsing: %v\n", err)
-			// Extract clean JSON from the output
-			jsonPlan, err = p.extractJSONFromExplainOutput(explainOutput)
-			if err != nil {
-				return fmt.Errorf("failed to extract JSON from EXPLAIN output: %w", err)
-			}
-		}
-	} else {
-		// Extract clean JSON from the output for older MySQL versions or ANALYZE format
-		jsonPlan, err = p.extractJSONFromExplainOutput(explainOutput)
-		if err != nil {
-			return fmt.Errorf("failed to extract JSON from EXPLAIN output: %w", err)
-		}
+		return "", fmt.Errorf("failed to extract query from EXPLAIN: %w", err)
 	}
 
 	// Collect schema snapshot
-	schema, err := p.collectSchemaSnapshot()
+	schema, err := p.collectSchemaSnapshot(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to collect schema: %w", err)
+		return "", fmt.Errorf("failed to collect schema: %w", err)
 	}
+	schema.Workload, _ = p.digestStats(originalQuery)
+	schema.Statistics = statistics
 
 	// Prepare schema JSON
 	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal schema: %w", err)
+		return "", fmt.Errorf("failed to marshal schema: %w", err)
 	}
 
 	// Prepare analysis payload
@@ -196,55 +312,92 @@ func (p *PromptExecutor) analyzeExplainWithAI(explainStmt string, explainOutput
 	// Get AI advice using configured MCP server
 	var advice string
 	if p.aiServerMode != "" || p.aiServerURL != "" {
-		client, err := ai.NewAIClient(p.aiServerMode, p.aiServerURL, p.aiCachePath)
+		client, err := p.aiBackend()
 		if err != nil {
-			return fmt.Errorf("failed to create AI client: %w", err)
+			return "", fmt.Errorf("failed to create AI client: %w", err)
 		}
-		advice, err = client.ExplainPlan(analysis.Query, analysis.ExplainJSON, analysis.Schema, p.aiDetailLevel)
+		advice, err = client.ExplainPlan(ctx, analysis.Query, analysis.ExplainJSON, analysis.Schema, p.aiDetailLevel)
 		if err != nil {
-			return fmt.Errorf("failed to get AI advice: %w", err)
+			return "", fmt.Errorf("failed to get AI advice: %w", err)
 		}
 	} else {
-		return fmt.Errorf("AI analysis not configured. Set --ai-server-url and --ai-server-mode")
+		return "", fmt.Errorf("AI analysis not configured. Set --ai-server-url and --ai-server-mode")
 	}
 
 	// Display the advice
-	fmt.Println("\n🤖 AI Performance Analysis:")
+	fmt.Println("\n🤖 " + tr("AI Performance Analysis:"))
 	fmt.Println("==========================")
-	if p.aiServerMode != "" || p.aiServerURL != "" {
-		fmt.Printf("(AI backend: %s %s)\n", p.aiServerMode, p.aiServerURL)
-	}
+	fmt.Printf(tr("(AI backend: %s %s)\n"), p.aiServerMode, p.aiEndpoint())
 	fmt.Println(advice)
 	fmt.Println()
 
-	return nil
+	return advice, nil
+}
+
+// aiEndpoint returns the bridge URL, or the spawned command in mcp_stdio mode
+func (p *PromptExecutor) aiEndpoint() string {
+	if strings.EqualFold(p.aiServerMode, "mcp_stdio") {
+		if p.aiMCPCommand == "" {
+			return ai.DefaultMCPCommand
+		}
+		return p.aiMCPCommand
+	}
+	return p.aiServerURL
 }
 
-// isMySQL84Plus checks if the MySQL server version is 8.4 or higher
-func (p *PromptExecutor) isMySQL84Plus() bool {
-	var version string
-	err := p.db.QueryRow("SELECT VERSION()").Scan(&version)
+// aiBackend returns the session's AI client, creating it on first use so an
+// mcp_stdio child is started once and reused for the whole session
+func (p *PromptExecutor) aiBackend() (ai.AIClient, error) {
+	if p.aiClient != nil {
+		return p.aiClient, nil
+	}
+	client, err := ai.NewAIClient(p.aiServerMode, p.aiEndpoint(), p.aiCachePath)
 	if err != nil {
-		return false
+		return nil, err
 	}
+	p.aiClient = client
+	return client, nil
+}
 
-	// Parse version string (e.g., "8.4.0" or "8.4.0-commercial")
-	version = strings.Split(version, "-")[0] // Remove suffix if present
-	parts := strings.Split(version, ".")
-	if len(parts) < 2 {
-		return false
+// closeAIClient releases the session's AI client, stopping any MCP child process
+func (p *PromptExecutor) closeAIClient() {
+	if closer, ok := p.aiClient.(io.Closer); ok {
+		closer.Close()
 	}
+	p.aiClient = nil
+}
 
-	major, err := strconv.Atoi(parts[0])
-	if err != nil {
-		return false
+// explainJSONPlan returns the JSON plan of an EXPLAIN whose result is
+// columns and rows. A FORMAT=JSON result (or MariaDB's ANALYZE FORMAT=JSON)
+// carries the plan in its single cell; for other formats the plan is asked
+// for again, through EXPLAIN ... INTO on MySQL 8.4 and later.
+func (p *PromptExecutor) explainJSONPlan(explainStmt string, columns []string, rows [][]string) (string, error) {
+	if plan, ok := planFromRows(columns, rows); ok {
+		return plan, nil
 	}
-	minor, err := strconv.Atoi(parts[1])
+	originalQuery, format, err := extractQueryFromExplain(explainStmt)
 	if err != nil {
-		return false
+		return "", err
 	}
+	if p.serverInfo().supportsExplainInto() && format != "ANALYZE" {
+		if plan, err := p.executeExplainWithJSONCapture(explainStmt); err == nil {
+			return plan, nil
+		}
+	}
+	return p.queryJSONPlan(originalQuery)
+}
 
-	return major > 8 || (major == 8 && minor >= 4)
+// planFromRows returns the plan of a FORMAT=JSON result: one row with a
+// single cell holding a JSON object
+func planFromRows(columns []string, rows [][]string) (string, bool) {
+	if len(columns) != 1 || len(rows) != 1 || len(rows[0]) != 1 {
+		return "", false
+	}
+	plan := strings.TrimSpace(rows[0][0])
+	if !strings.HasPrefix(plan, "{") || !json.Valid([]byte(plan)) {
+		return "", false
+	}
+	return plan, true
 }
 
 // executeExplainWithJSONCapture executes EXPLAIN using MySQL 8.4+ JSON capture feature
@@ -280,7 +433,17 @@ func (p *PromptExecutor) executeExplainWithJSONCapture(explainStmt string) (stri
 	return jsonPlan, nil
 }
 
-// isExplainQuery checks if a query is an EXPLAIN statement
+// isExplainQuery checks if a query is an EXPLAIN statement, or MariaDB's
+// ANALYZE statement (but not ANALYZE TABLE)
 func isExplainQuery(query string) bool {
-	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(query)), "EXPLAIN")
+	upper := strings.ToUpper(strings.TrimSpace(query))
+	if strings.HasPrefix(upper, "EXPLAIN") {
+		return true
+	}
+	rest, ok := strings.CutPrefix(upper, "ANALYZE ")
+	if !ok {
+		return false
+	}
+	rest = strings.TrimSpace(rest)
+	return !strings.HasPrefix(rest, "TABLE") && !strings.HasPrefix(rest, "NO_WRITE_TO_BINLOG") && !strings.HasPrefix(rest, "LOCAL")
 }
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -14,6 +15,8 @@ func TestExtractQueryFromExplain(t *testing.T) {
 		{"EXPLAIN FORMAT=JSON SELECT * FROM users", "SELECT * FROM users", "JSON"},
 		{"EXPLAIN FORMAT=TREE SELECT * FROM users WHERE id = 1", "SELECT * FROM users WHERE id = 1", "TREE"},
 		{"EXPLAIN ANALYZE SELECT * FROM users", "SELECT * FROM users", "ANALYZE"},
+		{"EXPLAIN ANALYZE FORMAT=TREE SELECT * FROM users", "SELECT * FROM users", "ANALYZE"},
+		{"ANALYZE FORMAT=JSON SELECT * FROM users", "SELECT * FROM users", "ANALYZE"},
 	}
 
 	for _, test := range tests {
@@ -41,6 +44,9 @@ func TestIsExplainQuery(t *testing.T) {
 		{"explain select * from users", true},
 		{"SELECT * FROM users", false},
 		{"SHOW TABLES", false},
+		{"ANALYZE FORMAT=JSON SELECT * FROM users", true},
+		{"ANALYZE TABLE users", false},
+		{"ANALYZE NO_WRITE_TO_BINLOG TABLE users", false},
 		{"", false},
 	}
 
@@ -51,3 +57,35 @@ func TestIsExplainQuery(t *testing.T) {
 		}
 	}
 }
+
+func TestStaleStatsNote(t *testing.T) {
+	if note := staleStatsNote(staleStatsDays - 1); note != "" {
+		t.Errorf("recent statistics got a note: %q", note)
+	}
+	if note := staleStatsNote(40); !strings.Contains(note, "40 days old") {
+		t.Errorf("staleStatsNote(40) = %q", note)
+	}
+}
+
+func TestPlanFromRows(t *testing.T) {
+	plan := "{\n  \"query_block\": {\n    \"select_id\": 1\n  }\n}"
+	if got, ok := planFromRows([]string{"EXPLAIN"}, [][]string{{plan}}); !ok || got != plan {
+		t.Errorf("got %q %v", got, ok)
+	}
+
+	tests := []struct {
+		name    string
+		columns []string
+		rows    [][]string
+	}{
+		{"traditional format", []string{"id", "select_type", "table"}, [][]string{{"1", "SIMPLE", "film"}}},
+		{"tree format", []string{"EXPLAIN"}, [][]string{{"-> Table scan on film  (cost=103 rows=1000)"}}},
+		{"no rows", []string{"EXPLAIN"}, nil},
+		{"truncated JSON", []string{"EXPLAIN"}, [][]string{{`{"query_block": {`}}},
+	}
+	for _, test := range tests {
+		if _, ok := planFromRows(test.columns, test.rows); ok {
+			t.Errorf("%s: expected no plan", test.name)
+		}
+	}
+}
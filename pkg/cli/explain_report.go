@@ -0,0 +1,387 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Severity levels for EXPLAIN findings, in increasing order
+const (
+	SeverityOK       = "ok"
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// severityRank orders severities so the worst finding can be picked
+var severityRank = map[string]int{
+	SeverityOK:       0,
+	SeverityInfo:     1,
+	SeverityWarning:  2,
+	SeverityCritical: 3,
+}
+
+// Verdicts derived from a plan's score, in increasing order
+const (
+	VerdictOK       = "ok"
+	VerdictWarn     = "warn"
+	VerdictCritical = "critical"
+)
+
+// severityWeight is what each finding of a severity adds to a plan's score.
+// A single critical finding makes the verdict critical and a single warning
+// makes it warn; info findings only do so when several add up.
+var severityWeight = map[string]int{
+	SeverityInfo:     10,
+	SeverityWarning:  30,
+	SeverityCritical: 70,
+}
+
+// Scores from which the verdict is warn and critical
+const (
+	warnScore     = 30
+	criticalScore = 70
+)
+
+// verdictExitCodes are the exit statuses of `go-mycli explain`; 1 is left
+// for errors
+var verdictExitCodes = map[string]int{
+	VerdictOK:       0,
+	VerdictWarn:     2,
+	VerdictCritical: 3,
+}
+
+// ExitStatus is returned by a command whose outcome is an exit status rather
+// than an error to report, such as a critical plan in `go-mycli explain`
+type ExitStatus struct {
+	Code int
+}
+
+func (e *ExitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// largeScanRows is the number of examined rows above which a full scan is critical
+const largeScanRows = 100000
+
+// ExplainFinding is a single problem spotted in an EXPLAIN plan
+type ExplainFinding struct {
+	Severity string `json:"severity"`
+	Table    string `json:"table,omitempty"`
+	Message  string `json:"message"`
+}
+
+// ExplainReport is the machine-readable result of `go-mycli explain`
+type ExplainReport struct {
+	Query    string           `json:"query"`
+	Severity string           `json:"severity"`
+	Score    int              `json:"score"`   // 0 (no findings) to 100
+	Verdict  string           `json:"verdict"` // ok, warn or critical
+	Findings []ExplainFinding `json:"findings"`
+	// Statistics are the ANALYZE TABLE runs that would improve the estimates
+	Statistics []StatisticsSuggestion `json:"statistics,omitempty"`
+	Analysis   string                 `json:"analysis,omitempty"`
+	Backend    string                 `json:"backend,omitempty"`
+	Workload   *DigestStats           `json:"workload,omitempty"` // execution history from performance_schema
+	Visual     string                 `json:"visual,omitempty"`   // the plan tree, with -e --visual
+	Plan       json.RawMessage        `json:"plan"`
+}
+
+// ExplainOptions configures a non-interactive EXPLAIN run
+type ExplainOptions struct {
+	Query         string
+	Format        string // text or json
+	UseAI         bool
+	AIServerURL   string
+	AIServerMode  string
+	AICachePath   string
+	AIMCPCommand  string
+	AIDetailLevel string
+}
+
+// Explain connects, runs EXPLAIN FORMAT=JSON for opts.Query and writes a
+// report with heuristic findings (and AI analysis when opts.UseAI is set) to
+// stdout. Nothing but the report is written to stdout so the JSON format can
+// be piped into CI tooling. A warn or critical verdict is returned as an
+// *ExitStatus so CI can gate on it.
+func Explain(host string, port int, user, password, database, socket, loginPath, configFile string, zstdCompressionLevel int, opts ExplainOptions) error {
+	if opts.Format != "text" && opts.Format != "json" {
+		return fmt.Errorf("unknown format %q (supported: text, json)", opts.Format)
+	}
+	query, _, _ := extractQueryFromExplain(strings.TrimSuffix(strings.TrimSpace(opts.Query), ";"))
+	if query == "" {
+		return fmt.Errorf("no query given")
+	}
+
+	cfg := LoadSyntaxConfig()
+	SetSessionVariables(cfg.Session)
+	db, mergedConfig, _, err := connect(host, port, user, password, database, socket, loginPath, configFile, zstdCompressionLevel)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if opts.AIServerURL == "" {
+		opts.AIServerURL = cfg.AiServerURL
+	}
+	if opts.AIServerMode == "" {
+		opts.AIServerMode = cfg.AiServerMode
+	}
+	if opts.AICachePath == "" {
+		opts.AICachePath = cfg.AiCachePath
+	}
+	if opts.AIMCPCommand == "" {
+		opts.AIMCPCommand = cfg.AiMCPCommand
+	}
+	executor := &PromptExecutor{
+		db:             db,
+		user:           mergedConfig.User,
+		host:           mergedConfig.Host,
+		port:           mergedConfig.Port,
+		database:       mergedConfig.Database,
+		columns:        make(map[string][]string),
+		nonInteractive: true,
+		aiServerURL:    opts.AIServerURL,
+		aiServerMode:   opts.AIServerMode,
+		aiCachePath:    opts.AICachePath,
+		aiMCPCommand:   opts.AIMCPCommand,
+		aiDetailLevel:  opts.AIDetailLevel,
+	}
+	defer executor.closeAIClient()
+
+	report, err := executor.buildExplainReport(context.Background(), query, opts.UseAI)
+	if err != nil {
+		return err
+	}
+	if err := writeExplainReport(os.Stdout, report, opts.Format); err != nil {
+		return err
+	}
+	if code := verdictExitCodes[report.Verdict]; code != 0 {
+		return &ExitStatus{Code: code}
+	}
+	return nil
+}
+
+// buildExplainReport runs EXPLAIN for query and analyzes the plan
+func (p *PromptExecutor) buildExplainReport(ctx context.Context, query string, useAI bool) (*ExplainReport, error) {
+	planJSON, err := p.queryJSONPlan(query)
+	if err != nil {
+		return nil, fmt.Errorf("EXPLAIN failed: %w", err)
+	}
+	return p.reportPlan(ctx, query, planJSON, "", useAI)
+}
+
+// reportPlan analyzes the JSON plan of query and, with useAI, asks the AI
+// backend about it. tree is the EXPLAIN ANALYZE tree of the query, if any.
+func (p *PromptExecutor) reportPlan(ctx context.Context, query, planJSON, tree string, useAI bool) (*ExplainReport, error) {
+	findings, err := analyzePlanHeuristics(planJSON)
+	if err != nil {
+		return nil, err
+	}
+	findings = append(findings, estimateFindings(treeEstimates(tree))...)
+	statsFindings, suggestions := p.statisticsAdvice(query, planJSON, tree)
+	findings = append(findings, statsFindings...)
+
+	score := severityScore(findings)
+	report := &ExplainReport{
+		Query:      query,
+		Severity:   worstSeverity(findings),
+		Score:      score,
+		Verdict:    scoreVerdict(score),
+		Findings:   findings,
+		Statistics: suggestions,
+		Plan:       json.RawMessage(planJSON),
+	}
+	report.Workload, _ = p.digestStats(query)
+
+	if useAI {
+		schema, err := p.collectSchemaSnapshot(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect schema: %w", err)
+		}
+		schema.Workload = report.Workload
+		schema.Statistics = report.Statistics
+		schemaJSON, err := json.Marshal(schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal schema: %w", err)
+		}
+		client, err := p.aiBackend()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create AI client: %w", err)
+		}
+		report.Analysis, err = client.ExplainPlan(ctx, query, planJSON, string(schemaJSON), p.aiDetailLevel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get AI advice: %w", err)
+		}
+		report.Backend = p.aiServerMode + " " + p.aiEndpoint()
+	}
+
+	return report, nil
+}
+
+// writeExplainReport renders report as indented JSON or as plain text
+func writeExplainReport(w io.Writer, report *ExplainReport, format string) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	fmt.Fprintf(w, tr("Query: %s\n"), report.Query)
+	fmt.Fprintf(w, tr("Severity: %s\n"), report.Severity)
+	fmt.Fprintf(w, tr("Score: %d/100 (%s)\n"), report.Score, report.Verdict)
+	if len(report.Findings) == 0 {
+		fmt.Fprintln(w, tr("No problems found"))
+	}
+	printFindings(w, report.Findings)
+	for _, s := range report.Statistics {
+		fmt.Fprintf(w, tr("Suggested for %s (%s): %s;\n"), s.Table, s.Reason, s.Statement)
+	}
+	if report.Workload != nil {
+		printDigestStats(w, report.Workload)
+	}
+	if report.Visual != "" {
+		fmt.Fprint(w, "\n"+report.Visual)
+	}
+	if report.Analysis != "" {
+		fmt.Fprintf(w, tr("\nAI analysis (%s):\n%s\n"), report.Backend, report.Analysis)
+	}
+	return nil
+}
+
+// printFindings lists findings one per line with their severity
+func printFindings(w io.Writer, findings []ExplainFinding) {
+	for _, f := range findings {
+		if f.Table != "" {
+			fmt.Fprintf(w, "  [%s] %s: %s\n", f.Severity, f.Table, f.Message)
+		} else {
+			fmt.Fprintf(w, "  [%s] %s\n", f.Severity, f.Message)
+		}
+	}
+}
+
+// printPlanScore writes the score and findings of a plan under its EXPLAIN
+// output. A plan without findings gets a single line.
+func printPlanScore(w io.Writer, findings []ExplainFinding) {
+	score := severityScore(findings)
+	fmt.Fprintf(w, tr("\nPlan score: %d/100 (%s)\n"), score, scoreVerdict(score))
+	printFindings(w, findings)
+}
+
+// severityScore adds up the weights of findings into a score from 0 to 100
+func severityScore(findings []ExplainFinding) int {
+	score := 0
+	for _, f := range findings {
+		score += severityWeight[f.Severity]
+	}
+	return min(score, 100)
+}
+
+// scoreVerdict returns the verdict for a score
+func scoreVerdict(score int) string {
+	switch {
+	case score >= criticalScore:
+		return VerdictCritical
+	case score >= warnScore:
+		return VerdictWarn
+	}
+	return VerdictOK
+}
+
+// worstSeverity returns the highest severity among findings, or ok
+func worstSeverity(findings []ExplainFinding) string {
+	worst := SeverityOK
+	for _, f := range findings {
+		if severityRank[f.Severity] > severityRank[worst] {
+			worst = f.Severity
+		}
+	}
+	return worst
+}
+
+// analyzePlanHeuristics walks an EXPLAIN FORMAT=JSON plan and reports full
+// scans, unused indexes, filesorts and temporary tables, and for an analyzed
+// plan the row estimates that are far off
+func analyzePlanHeuristics(planJSON string) ([]ExplainFinding, error) {
+	var plan interface{}
+	if err := json.Unmarshal([]byte(planJSON), &plan); err != nil {
+		return nil, fmt.Errorf("invalid EXPLAIN JSON: %w", err)
+	}
+
+	findings := []ExplainFinding{}
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			if _, ok := n["table_name"]; ok {
+				findings = append(findings, tableFindings(n)...)
+			}
+			if v, ok := n["using_filesort"].(bool); ok && v {
+				findings = append(findings, ExplainFinding{Severity: SeverityWarning, Message: "uses filesort"})
+			}
+			if v, ok := n["using_temporary_table"].(bool); ok && v {
+				findings = append(findings, ExplainFinding{Severity: SeverityWarning, Message: "uses a temporary table"})
+			}
+			// Visit children in key order so findings are reported deterministically
+			keys := make([]string, 0, len(n))
+			for k := range n {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(n[k])
+			}
+		case []interface{}:
+			for _, child := range n {
+				walk(child)
+			}
+		}
+	}
+	walk(plan)
+	findings = append(findings, estimateFindings(planEstimates(planJSON))...)
+	return findings, nil
+}
+
+// tableFindings inspects a single table access in the plan
+func tableFindings(table map[string]interface{}) []ExplainFinding {
+	var findings []ExplainFinding
+	name, _ := table["table_name"].(string)
+	access, _ := table["access_type"].(string)
+	key, _ := table["key"].(string)
+	rows := planNumber(table["rows_examined_per_scan"])
+
+	switch access {
+	case "ALL":
+		severity := SeverityWarning
+		if rows >= largeScanRows {
+			severity = SeverityCritical
+		}
+		findings = append(findings, ExplainFinding{Severity: severity, Table: name, Message: fmt.Sprintf("full table scan (%.0f rows examined per scan)", rows)})
+	case "index":
+		findings = append(findings, ExplainFinding{Severity: SeverityInfo, Table: name, Message: fmt.Sprintf("full index scan on %s", key)})
+	}
+
+	if possible, ok := table["possible_keys"].([]interface{}); ok && len(possible) > 0 && key == "" {
+		findings = append(findings, ExplainFinding{Severity: SeverityInfo, Table: name, Message: "possible indexes exist but none is used"})
+	}
+
+	return findings
+}
+
+// planNumber reads a numeric plan value, which MySQL emits as a number or a string
+func planNumber(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case string:
+		var f float64
+		fmt.Sscanf(n, "%f", &f)
+		return f
+	}
+	return 0
+}
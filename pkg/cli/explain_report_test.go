@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzePlanHeuristics(t *testing.T) {
+	tests := []struct {
+		name     string
+		plan     string
+		severity string
+		count    int
+	}{
+		{"ref lookup", `{"query_block":{"table":{"table_name":"actor","access_type":"ref","key":"idx_first_name","rows_examined_per_scan":4}}}`, SeverityOK, 0},
+		{"small full scan", `{"query_block":{"table":{"table_name":"actor","access_type":"ALL","rows_examined_per_scan":200}}}`, SeverityWarning, 1},
+		{"large full scan", `{"query_block":{"table":{"table_name":"rental","access_type":"ALL","rows_examined_per_scan":"160000"}}}`, SeverityCritical, 1},
+		{"unused index", `{"query_block":{"table":{"table_name":"film","access_type":"range","possible_keys":["idx_title"]}}}`, SeverityInfo, 1},
+		{"filesort in join", `{"query_block":{"ordering_operation":{"using_filesort":true,"nested_loop":[{"table":{"table_name":"a","access_type":"index","key":"PRIMARY"}},{"table":{"table_name":"b","access_type":"eq_ref","key":"PRIMARY"}}]}}}`, SeverityWarning, 2},
+	}
+
+	for _, test := range tests {
+		findings, err := analyzePlanHeuristics(test.plan)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if len(findings) != test.count {
+			t.Errorf("%s: got %d findings %v, expected %d", test.name, len(findings), findings, test.count)
+		}
+		if got := worstSeverity(findings); got != test.severity {
+			t.Errorf("%s: severity = %q, expected %q", test.name, got, test.severity)
+		}
+	}
+}
+
+func TestAnalyzePlanHeuristicsRejectsInvalidJSON(t *testing.T) {
+	if _, err := analyzePlanHeuristics("not json"); err == nil {
+		t.Errorf("expected error for invalid plan")
+	}
+}
+
+func TestWriteExplainReportJSON(t *testing.T) {
+	report := &ExplainReport{
+		Query:    "SELECT 1",
+		Severity: SeverityOK,
+		Findings: []ExplainFinding{},
+		Plan:     json.RawMessage(`{"query_block":{}}`),
+	}
+
+	var buf bytes.Buffer
+	if err := writeExplainReport(&buf, report, "json"); err != nil {
+		t.Fatalf("writeExplainReport returned error: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if decoded["severity"] != SeverityOK {
+		t.Errorf("severity = %v, expected %q", decoded["severity"], SeverityOK)
+	}
+	if _, ok := decoded["plan"].(map[string]interface{}); !ok {
+		t.Errorf("plan should be embedded as JSON, got %T", decoded["plan"])
+	}
+	if _, ok := decoded["visual"]; ok {
+		t.Errorf("visual should be left out unless asked for")
+	}
+
+	// -e --json --visual adds the plan tree
+	report.Visual = "Query Execution Plan:\n└── film (ALL)\n"
+	buf.Reset()
+	if err := writeExplainReport(&buf, report, "json"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"visual": "Query Execution Plan:\n└── film (ALL)\n"`) {
+		t.Errorf("JSON report lacks the visual explain:\n%s", buf.String())
+	}
+}
+
+func TestWriteExplainReportWorkload(t *testing.T) {
+	report := &ExplainReport{
+		Query:    "SELECT * FROM orders WHERE status = ?",
+		Severity: SeverityOK,
+		Workload: &DigestStats{
+			Executions:      1200,
+			TotalLatencyMs:  54000,
+			AvgLatencyMs:    45,
+			MaxLatencyMs:    1250.5,
+			AvgRowsExamined: 98000,
+			AvgRowsSent:     12,
+			NoIndexUsed:     1200,
+			FirstSeen:       "2026-10-01 08:00:00",
+			LastSeen:        "2026-10-14 17:30:00",
+		},
+	}
+	var buf bytes.Buffer
+	if err := writeExplainReport(&buf, report, "text"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Execution history (performance_schema, 2026-10-01 08:00:00 to 2026-10-14 17:30:00):",
+		"1200 executions, avg 45.000 ms, max 1.25 s, total 54.00 s",
+		"98000 rows examined and 12 sent per execution, 1200 without an index",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("report lacks %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := writeExplainReport(&buf, report, "json"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"executions": 1200`) {
+		t.Errorf("JSON report lacks the workload:\n%s", buf.String())
+	}
+}
+
+func TestSeverityScore(t *testing.T) {
+	finding := func(severity string) ExplainFinding { return ExplainFinding{Severity: severity} }
+	tests := []struct {
+		findings []ExplainFinding
+		score    int
+		verdict  string
+	}{
+		{nil, 0, VerdictOK},
+		{[]ExplainFinding{finding(SeverityInfo), finding(SeverityInfo)}, 20, VerdictOK},
+		{[]ExplainFinding{finding(SeverityInfo), finding(SeverityInfo), finding(SeverityInfo)}, 30, VerdictWarn},
+		{[]ExplainFinding{finding(SeverityWarning)}, 30, VerdictWarn},
+		{[]ExplainFinding{finding(SeverityWarning), finding(SeverityWarning), finding(SeverityInfo)}, 70, VerdictCritical},
+		{[]ExplainFinding{finding(SeverityCritical)}, 70, VerdictCritical},
+		{[]ExplainFinding{finding(SeverityCritical), finding(SeverityCritical)}, 100, VerdictCritical},
+	}
+	for _, test := range tests {
+		score := severityScore(test.findings)
+		if score != test.score || scoreVerdict(score) != test.verdict {
+			t.Errorf("%v: got %d (%s), expected %d (%s)", test.findings, score, scoreVerdict(score), test.score, test.verdict)
+		}
+	}
+}
+
+func TestPrintPlanScore(t *testing.T) {
+	var buf bytes.Buffer
+	printPlanScore(&buf, []ExplainFinding{{Severity: SeverityWarning, Table: "film", Message: "full table scan (1000 rows examined per scan)"}})
+	want := "\nPlan score: 30/100 (warn)\n  [warning] film: full table scan (1000 rows examined per scan)\n"
+	if buf.String() != want {
+		t.Errorf("got %q, expected %q", buf.String(), want)
+	}
+}
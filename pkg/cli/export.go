@@ -0,0 +1,425 @@
+package cli
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+	"github.com/parquet-go/parquet-go/encoding"
+)
+
+// Export file formats, chosen by the extension of --out
+const (
+	exportParquet = "parquet"
+	exportCSV     = "csv"
+	exportCSVGzip = "csv.gz"
+	exportCSVZstd = "csv.zst"
+)
+
+// exportBatchRows is how many rows are buffered before they are handed to the
+// Parquet writer; exportRowGroupRows bounds the memory a row group takes
+const (
+	exportBatchRows    = 1024
+	exportRowGroupRows = 128 * 1024
+)
+
+// ExportOptions holds the settings of the export subcommand
+type ExportOptions struct {
+	Query string
+	Out   string
+}
+
+// exportColumn describes one column of the exported result set
+type exportColumn struct {
+	Name      string
+	Type      string // DatabaseTypeName, e.g. "VARCHAR" or "UNSIGNED BIGINT"
+	Precision int64
+	Scale     int64
+}
+
+// exportWriter writes rows as the driver returns them, nil meaning NULL
+type exportWriter interface {
+	WriteRow(values []sql.RawBytes) error
+	Close() error
+}
+
+// exportFormat returns the format of an output file from its extension
+func exportFormat(path string) (string, error) {
+	lower := strings.ToLower(path)
+	for _, format := range []string{exportParquet, exportCSVGzip, exportCSVZstd, exportCSV} {
+		if strings.HasSuffix(lower, "."+format) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("cannot tell the format of %q (use .parquet, .csv, .csv.gz or .csv.zst)", path)
+}
+
+// Export runs a query and streams its result set to a Parquet or CSV file.
+// It is the entry point of the export subcommand.
+func Export(host string, port int, user, password, database, socket, loginPath, configFile string, zstdCompressionLevel int, opts ExportOptions) error {
+	format, err := exportFormat(opts.Out)
+	if err != nil {
+		return err
+	}
+	query := strings.TrimSuffix(strings.TrimSpace(opts.Query), ";")
+	if query == "" {
+		return fmt.Errorf("no query given")
+	}
+
+	cfg := LoadSyntaxConfig()
+	SetSessionVariables(cfg.Session)
+	db, _, _, err := connect(host, port, user, password, database, socket, loginPath, configFile, zstdCompressionLevel)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// TIMESTAMP values are read in UTC so they can be written as instants
+	if _, err := conn.ExecContext(ctx, "SET time_zone = '+00:00'"); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	columns, err := exportColumns(rows)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(opts.Out)
+	if err != nil {
+		return err
+	}
+	n, err := exportRows(file, format, columns, rows)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(opts.Out)
+		return fmt.Errorf("export failed after %d rows: %w", n, err)
+	}
+
+	size := ""
+	if info, err := os.Stat(opts.Out); err == nil {
+		size = fmt.Sprintf(", %s", formatBytes(info.Size()))
+	}
+	fmt.Printf("Exported %d row%s (%d column%s) to %s%s in %.2fs\n", n, plural(int(n)), len(columns), plural(len(columns)),
+		opts.Out, size, time.Since(start).Seconds())
+	return nil
+}
+
+// exportColumns reads the column types of a result set
+func exportColumns(rows *sql.Rows) ([]exportColumn, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]exportColumn, len(types))
+	for i, t := range types {
+		columns[i] = exportColumn{Name: t.Name(), Type: t.DatabaseTypeName()}
+		if precision, scale, ok := t.DecimalSize(); ok {
+			columns[i].Precision, columns[i].Scale = precision, scale
+		}
+	}
+	return columns, nil
+}
+
+// exportRows writes every row of rows to w and returns how many it wrote
+func exportRows(w io.Writer, format string, columns []exportColumn, rows *sql.Rows) (int64, error) {
+	out, err := newExportWriter(w, format, columns)
+	if err != nil {
+		return 0, err
+	}
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	var n int64
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			out.Close()
+			return n, err
+		}
+		if err := out.WriteRow(values); err != nil {
+			out.Close()
+			return n, err
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		out.Close()
+		return n, err
+	}
+	return n, out.Close()
+}
+
+func newExportWriter(w io.Writer, format string, columns []exportColumn) (exportWriter, error) {
+	switch format {
+	case exportParquet:
+		return newParquetExport(w, columns), nil
+	case exportCSVGzip:
+		return newCSVExport(gzip.NewWriter(w), columns)
+	case exportCSVZstd:
+		zw, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		return newCSVExport(zw, columns)
+	case exportCSV:
+		return newCSVExport(nopWriteCloser{w}, columns)
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// csvExport writes a header line and one line per row. NULL is written as an
+// empty field.
+type csvExport struct {
+	out    io.WriteCloser // the compressor, if any
+	csv    *csv.Writer
+	record []string
+}
+
+func newCSVExport(out io.WriteCloser, columns []exportColumn) (*csvExport, error) {
+	e := &csvExport{out: out, csv: csv.NewWriter(out), record: make([]string, len(columns))}
+	for i, c := range columns {
+		e.record[i] = c.Name
+	}
+	if err := e.csv.Write(e.record); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *csvExport) WriteRow(values []sql.RawBytes) error {
+	for i, v := range values {
+		e.record[i] = string(v)
+	}
+	return e.csv.Write(e.record)
+}
+
+func (e *csvExport) Close() error {
+	e.csv.Flush()
+	err := e.csv.Error()
+	if closeErr := e.out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// parquetConverter turns the text of a non-NULL value into a Parquet value
+type parquetConverter func(text []byte) (parquet.Value, error)
+
+// parquetExport writes rows to a zstd-compressed Parquet file, every column
+// optional so NULLs survive
+type parquetExport struct {
+	writer     *parquet.Writer
+	converters []parquetConverter
+	batch      []parquet.Row
+	rows       int64
+}
+
+func newParquetExport(w io.Writer, columns []exportColumn) *parquetExport {
+	e := &parquetExport{converters: make([]parquetConverter, len(columns))}
+	fields := make([]parquet.Field, len(columns))
+	for i, c := range columns {
+		node, convert := parquetColumn(c)
+		fields[i] = exportField{Node: parquet.Optional(node), name: c.Name}
+		e.converters[i] = convert
+	}
+	schema := parquet.NewSchema("export", exportSchema(fields))
+	e.writer = parquet.NewWriter(w, schema, parquet.Compression(&parquet.Zstd),
+		parquet.MaxRowsPerRowGroup(exportRowGroupRows), parquet.CreatedBy("go-mycli", "", ""))
+	return e
+}
+
+func (e *parquetExport) WriteRow(values []sql.RawBytes) error {
+	row := make(parquet.Row, len(values))
+	for i, v := range values {
+		if v == nil {
+			row[i] = parquet.Value{}.Level(0, 0, i)
+			continue
+		}
+		value, err := e.converters[i](v)
+		if err != nil {
+			return fmt.Errorf("row %d, column %d: %w", e.rows+1, i+1, err)
+		}
+		if value.IsNull() {
+			row[i] = value.Level(0, 0, i)
+		} else {
+			row[i] = value.Level(0, 1, i)
+		}
+	}
+	e.rows++
+	e.batch = append(e.batch, row)
+	if len(e.batch) >= exportBatchRows {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *parquetExport) flush() error {
+	_, err := e.writer.WriteRows(e.batch)
+	e.batch = e.batch[:0]
+	return err
+}
+
+func (e *parquetExport) Close() error {
+	err := e.flush()
+	if closeErr := e.writer.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// parquetColumn maps a MySQL column type to a Parquet column and the
+// conversion of its values. Types without a Parquet equivalent, such as TIME
+// (which can exceed 24 hours) and DECIMALs too wide for 64 bits, are kept as
+// strings; zero dates are written as NULL.
+func parquetColumn(c exportColumn) (parquet.Node, parquetConverter) {
+	switch c.Type {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT",
+		"UNSIGNED TINYINT", "UNSIGNED SMALLINT", "UNSIGNED MEDIUMINT", "UNSIGNED INT":
+		return parquet.Int(64), func(text []byte) (parquet.Value, error) {
+			n, err := strconv.ParseInt(string(text), 10, 64)
+			return parquet.Int64Value(n), err
+		}
+	case "UNSIGNED BIGINT":
+		return parquet.Uint(64), func(text []byte) (parquet.Value, error) {
+			n, err := strconv.ParseUint(string(text), 10, 64)
+			return parquet.Int64Value(int64(n)), err
+		}
+	case "YEAR":
+		return parquet.Int(32), func(text []byte) (parquet.Value, error) {
+			n, err := strconv.ParseInt(string(text), 10, 32)
+			return parquet.Int32Value(int32(n)), err
+		}
+	case "BIT":
+		return parquet.Uint(64), func(text []byte) (parquet.Value, error) {
+			var n uint64
+			for _, b := range text {
+				n = n<<8 | uint64(b)
+			}
+			return parquet.Int64Value(int64(n)), nil
+		}
+	case "FLOAT":
+		return parquet.Leaf(parquet.FloatType), func(text []byte) (parquet.Value, error) {
+			f, err := strconv.ParseFloat(string(text), 32)
+			return parquet.FloatValue(float32(f)), err
+		}
+	case "DOUBLE":
+		return parquet.Leaf(parquet.DoubleType), func(text []byte) (parquet.Value, error) {
+			f, err := strconv.ParseFloat(string(text), 64)
+			return parquet.DoubleValue(f), err
+		}
+	case "DECIMAL":
+		if c.Precision > 0 && c.Precision <= 18 {
+			return parquet.Decimal(int(c.Scale), int(c.Precision), parquet.Int64Type), func(text []byte) (parquet.Value, error) {
+				n, err := unscaledDecimal(string(text), int(c.Scale))
+				return parquet.Int64Value(n), err
+			}
+		}
+		return parquet.String(), stringValue
+	case "DATE":
+		return parquet.Date(), func(text []byte) (parquet.Value, error) {
+			if isZeroDate(text) {
+				return parquet.Value{}, nil
+			}
+			t, err := time.Parse(time.DateOnly, string(text))
+			return parquet.Int32Value(int32(t.Unix() / 86400)), err
+		}
+	case "DATETIME", "TIMESTAMP":
+		// DATETIME is a wall-clock time; TIMESTAMP was read in UTC
+		node := parquet.TimestampAdjusted(parquet.Microsecond, c.Type == "TIMESTAMP")
+		return node, func(text []byte) (parquet.Value, error) {
+			if isZeroDate(text) {
+				return parquet.Value{}, nil
+			}
+			t, err := time.Parse("2006-01-02 15:04:05.999999", string(text))
+			return parquet.Int64Value(t.UnixMicro()), err
+		}
+	case "JSON":
+		return parquet.JSON(), stringValue
+	case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB", "GEOMETRY":
+		return parquet.Leaf(parquet.ByteArrayType), stringValue
+	}
+	return parquet.String(), stringValue
+}
+
+// stringValue copies the text, since the driver reuses its buffer for the
+// next row
+func stringValue(text []byte) (parquet.Value, error) {
+	return parquet.ByteArrayValue(bytes.Clone(text)), nil
+}
+
+func isZeroDate(text []byte) bool {
+	return bytes.HasPrefix(text, []byte("0000-00-00"))
+}
+
+// unscaledDecimal returns a decimal such as "-12.5" as an integer with scale
+// fractional digits: -1250 for scale 3
+func unscaledDecimal(text string, scale int) (int64, error) {
+	whole, frac, _ := strings.Cut(text, ".")
+	if len(frac) > scale {
+		return 0, fmt.Errorf("decimal %s has more than %d fractional digits", text, scale)
+	}
+	return strconv.ParseInt(whole+frac+strings.Repeat("0", scale-len(frac)), 10, 64)
+}
+
+// exportSchema is the root of an export's Parquet schema. parquet.Group sorts
+// its fields by name; this keeps the columns in the order of the query.
+type exportSchema []parquet.Field
+
+func (s exportSchema) ID() int { return 0 }
+func (s exportSchema) String() string {
+	var b strings.Builder
+	parquet.PrintSchema(&b, "export", s)
+	return b.String()
+}
+func (s exportSchema) Type() parquet.Type          { return parquet.Group{}.Type() }
+func (s exportSchema) Optional() bool              { return false }
+func (s exportSchema) Repeated() bool              { return false }
+func (s exportSchema) Required() bool              { return true }
+func (s exportSchema) Leaf() bool                  { return false }
+func (s exportSchema) Fields() []parquet.Field     { return s }
+func (s exportSchema) Encoding() encoding.Encoding { return nil }
+func (s exportSchema) Compression() compress.Codec { return nil }
+func (s exportSchema) GoType() reflect.Type        { return reflect.TypeOf(map[string]any{}) }
+
+type exportField struct {
+	parquet.Node
+	name string
+}
+
+func (f exportField) Name() string { return f.name }
+
+// Value is only used when writing Go values; the export writes rows
+func (f exportField) Value(base reflect.Value) reflect.Value {
+	return base.MapIndex(reflect.ValueOf(f.name))
+}
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestExportFormat(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"data.parquet", exportParquet, false},
+		{"out/DATA.CSV", exportCSV, false},
+		{"data.csv.gz", exportCSVGzip, false},
+		{"data.csv.zst", exportCSVZstd, false},
+		{"data.json", "", true},
+		{"data", "", true},
+	}
+	for _, tt := range tests {
+		got, err := exportFormat(tt.path)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("exportFormat(%q) = %q, %v; want %q (error %v)", tt.path, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestUnscaledDecimal(t *testing.T) {
+	tests := []struct {
+		text    string
+		scale   int
+		want    int64
+		wantErr bool
+	}{
+		{"12.50", 2, 1250, false},
+		{"-12.5", 3, -12500, false},
+		{"7", 2, 700, false},
+		{"0.001", 3, 1, false},
+		{"1.2345", 2, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := unscaledDecimal(tt.text, tt.scale)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("unscaledDecimal(%q, %d) = %d, %v; want %d (error %v)", tt.text, tt.scale, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParquetColumn(t *testing.T) {
+	tests := []struct {
+		column exportColumn
+		text   string
+		kind   parquet.Kind
+		want   any
+	}{
+		{exportColumn{Type: "INT"}, "-42", parquet.Int64, int64(-42)},
+		{exportColumn{Type: "UNSIGNED MEDIUMINT"}, "16777215", parquet.Int64, int64(16777215)},
+		{exportColumn{Type: "UNSIGNED BIGINT"}, "18446744073709551615", parquet.Int64, int64(-1)},
+		{exportColumn{Type: "YEAR"}, "2024", parquet.Int32, int32(2024)},
+		{exportColumn{Type: "DOUBLE"}, "1.5", parquet.Double, 1.5},
+		{exportColumn{Type: "DECIMAL", Precision: 10, Scale: 2}, "19.99", parquet.Int64, int64(1999)},
+		{exportColumn{Type: "DECIMAL", Precision: 30, Scale: 2}, "19.99", parquet.ByteArray, "19.99"},
+		{exportColumn{Type: "DATE"}, "1970-01-11", parquet.Int32, int32(10)},
+		{exportColumn{Type: "DATETIME"}, "1970-01-01 00:00:01.5", parquet.Int64, int64(1500000)},
+		{exportColumn{Type: "TIME"}, "838:59:59", parquet.ByteArray, "838:59:59"},
+		{exportColumn{Type: "VARCHAR"}, "héllo", parquet.ByteArray, "héllo"},
+	}
+	for _, tt := range tests {
+		node, convert := parquetColumn(tt.column)
+		if kind := node.Type().Kind(); kind != tt.kind {
+			t.Errorf("%s column kind = %v, want %v", tt.column.Type, kind, tt.kind)
+			continue
+		}
+		value, err := convert([]byte(tt.text))
+		if err != nil {
+			t.Errorf("%s %q: %v", tt.column.Type, tt.text, err)
+			continue
+		}
+		var got any
+		switch tt.kind {
+		case parquet.Int32:
+			got = value.Int32()
+		case parquet.Int64:
+			got = value.Int64()
+		case parquet.Double:
+			got = value.Double()
+		default:
+			got = string(value.ByteArray())
+		}
+		if got != tt.want {
+			t.Errorf("%s %q = %v, want %v", tt.column.Type, tt.text, got, tt.want)
+		}
+	}
+
+	_, convert := parquetColumn(exportColumn{Type: "DATETIME"})
+	if value, err := convert([]byte("0000-00-00 00:00:00")); err != nil || !value.IsNull() {
+		t.Errorf("zero DATETIME = %v, %v; want NULL", value, err)
+	}
+}
+
+var exportTestColumns = []exportColumn{
+	{Name: "id", Type: "BIGINT"},
+	{Name: "name", Type: "VARCHAR"},
+	{Name: "amount", Type: "DECIMAL", Precision: 8, Scale: 2},
+}
+
+var exportTestRows = [][]sql.RawBytes{
+	{sql.RawBytes("1"), sql.RawBytes("alice, \"al\""), sql.RawBytes("10.50")},
+	{sql.RawBytes("2"), nil, nil},
+}
+
+func writeTestExport(t *testing.T, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	out, err := newExportWriter(&buf, format, exportTestColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range exportTestRows {
+		if err := out.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSVExportZstd(t *testing.T) {
+	data := writeTestExport(t, exportCSVZstd)
+	zr, err := zstd.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	records, err := csv.NewReader(zr).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"id", "name", "amount"},
+		{"1", "alice, \"al\"", "10.50"},
+		{"2", "", ""},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %q, want %q", records, want)
+	}
+}
+
+func TestParquetExport(t *testing.T) {
+	data := writeTestExport(t, exportParquet)
+	file, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range file.Schema().Fields() {
+		names = append(names, f.Name())
+		if !f.Optional() {
+			t.Errorf("column %s is not optional", f.Name())
+		}
+	}
+	if want := []string{"id", "name", "amount"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("columns = %v, want %v (query order)", names, want)
+	}
+	if file.NumRows() != 2 {
+		t.Fatalf("NumRows = %d, want 2", file.NumRows())
+	}
+
+	reader := parquet.NewReader(file)
+	defer reader.Close()
+	rows := make([]parquet.Row, 2)
+	if n, err := reader.ReadRows(rows); n != 2 || (err != nil && err != io.EOF) {
+		t.Fatalf("ReadRows = %d, %v", n, err)
+	}
+	if got := rows[0][0].Int64(); got != 1 {
+		t.Errorf("id = %d, want 1", got)
+	}
+	if got := string(rows[0][1].ByteArray()); got != "alice, \"al\"" {
+		t.Errorf("name = %q", got)
+	}
+	if got := rows[0][2].Int64(); got != 1050 {
+		t.Errorf("amount = %d, want 1050 (unscaled)", got)
+	}
+	if !rows[1][1].IsNull() || !rows[1][2].IsNull() {
+		t.Errorf("second row = %v, want NULL name and amount", rows[1])
+	}
+}
@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+)
+
+// serverInfo returns the connected server's flavor and version, detected on
+// first use after connecting. If detection fails the zero value is returned,
+// which enables every feature gate as before.
+func (p *PromptExecutor) serverInfo() serverInfo {
+	if p.server != nil {
+		return *p.server
+	}
+	if p.db == nil {
+		return serverInfo{}
+	}
+	info, err := queryServerInfo(p.db)
+	if err != nil {
+		return serverInfo{}
+	}
+	p.server = &info
+	return info
+}
+
+// supportsExplainInto reports whether EXPLAIN FORMAT=JSON INTO @var works,
+// which only MySQL (and Percona Server) 8.4 and later support. Through a
+// gateway the user variable may be set on one backend and read on another.
+func (s serverInfo) supportsExplainInto() bool {
+	return (s.Flavor == flavorMySQL || s.Flavor == flavorPercona) && s.atLeast(8, 4, 0) && s.Gateway == ""
+}
+
+// jsonPlanStatement returns the statement that produces a MySQL-style JSON
+// plan for query. TiDB's JSON plans (FORMAT='tidb_json') have a different
+// shape, so no statement is offered there.
+func (s serverInfo) jsonPlanStatement(query string) (string, error) {
+	if s.Flavor == flavorTiDB {
+		return "", fmt.Errorf("JSON plans are not available on TiDB; use EXPLAIN FORMAT='tidb_json'")
+	}
+	return "EXPLAIN FORMAT=JSON " + query, nil
+}
+
+// queryJSONPlan runs EXPLAIN FORMAT=JSON for query and returns the plan
+func (p *PromptExecutor) queryJSONPlan(query string) (string, error) {
+	stmt, err := p.serverInfo().jsonPlanStatement(query)
+	if err != nil {
+		return "", err
+	}
+	var plan string
+	if err := p.db.QueryRow(stmt).Scan(&plan); err != nil {
+		return "", err
+	}
+	return plan, nil
+}
+
+var explainAnalyze = regexp.MustCompile(`(?is)^EXPLAIN\s+ANALYZE\s+(?:FORMAT\s*=\s*\w+\s+)?`)
+
+// adaptAnalyze rewrites MySQL's EXPLAIN ANALYZE into MariaDB's
+// ANALYZE FORMAT=JSON, which MariaDB runs instead. Other servers get the
+// statement back unchanged.
+func (s serverInfo) adaptAnalyze(sql string) (string, bool) {
+	if s.Flavor != flavorMariaDB {
+		return sql, false
+	}
+	loc := explainAnalyze.FindStringIndex(sql)
+	if loc == nil {
+		return sql, false
+	}
+	return "ANALYZE FORMAT=JSON " + sql[loc[1]:], true
+}
+
+// replicationStatements groups the spellings of each replication statement:
+// the original one first, then MySQL's and MariaDB's replacements
+var replicationStatements = [][]string{
+	{"SHOW SLAVE STATUS", "SHOW REPLICA STATUS"},
+	{"START SLAVE", "START REPLICA"},
+	{"STOP SLAVE", "STOP REPLICA"},
+	{"SHOW SLAVE HOSTS", "SHOW REPLICAS", "SHOW REPLICA HOSTS"},
+	{"SHOW MASTER STATUS", "SHOW BINARY LOG STATUS", "SHOW BINLOG STATUS"},
+}
+
+// supportsStatement reports whether the server accepts one spelling from
+// replicationStatements. MySQL 8.0.22 introduced the REPLICA spellings and
+// 8.4 removed the SLAVE and MASTER ones; MariaDB 10.5 added its own and kept
+// the old ones. Unknown servers are assumed to accept everything.
+func (s serverInfo) supportsStatement(stmt string) bool {
+	switch s.Flavor {
+	case flavorMySQL, flavorPercona:
+		switch stmt {
+		case "SHOW REPLICA HOSTS", "SHOW BINLOG STATUS":
+			return false
+		case "SHOW BINARY LOG STATUS":
+			return s.atLeast(8, 2, 0)
+		}
+		if strings.Contains(stmt, "SLAVE") || strings.Contains(stmt, "MASTER") {
+			return !s.atLeast(8, 4, 0)
+		}
+		return s.atLeast(8, 0, 22)
+	case flavorMariaDB:
+		switch stmt {
+		case "SHOW REPLICAS", "SHOW BINARY LOG STATUS":
+			return false
+		case "SHOW BINLOG STATUS":
+			return s.atLeast(10, 5, 2)
+		}
+		if strings.Contains(stmt, "SLAVE") || strings.Contains(stmt, "MASTER") {
+			return true
+		}
+		return s.atLeast(10, 5, 1)
+	}
+	return true
+}
+
+// adaptReplication rewrites a replication statement the server does not
+// accept into a spelling it does, e.g. SHOW SLAVE STATUS on MySQL 8.4 or
+// SHOW REPLICA STATUS on MariaDB 10.4. TiDB has no replication statements.
+func (s serverInfo) adaptReplication(sql string) (string, bool) {
+	if s.Flavor == "" || s.Flavor == flavorTiDB {
+		return sql, false
+	}
+	for _, group := range replicationStatements {
+		for _, stmt := range group {
+			loc := statementPattern(stmt).FindStringIndex(sql)
+			if loc == nil {
+				continue
+			}
+			if s.supportsStatement(stmt) {
+				return sql, false
+			}
+			for _, alt := range group {
+				if s.supportsStatement(alt) {
+					return alt + sql[loc[1]:], true
+				}
+			}
+			return sql, false
+		}
+	}
+	return sql, false
+}
+
+// statementPattern matches stmt at the start of a statement, allowing any
+// case and whitespace between its words
+func statementPattern(stmt string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^` + strings.Join(strings.Fields(stmt), `\s+`) + `\b`)
+}
+
+// adaptStatement applies the flavor rewrites to a statement about to run,
+// noting any change so the user knows what was sent
+func (p *PromptExecutor) adaptStatement(sql string) string {
+	info := p.serverInfo()
+	adapted, ok := info.adaptReplication(sql)
+	if !ok {
+		adapted, ok = info.adaptAnalyze(sql)
+	}
+	if ok {
+		fmt.Printf("(running %s on %s)\n", firstLine(adapted), info)
+	}
+	return adapted
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// filterShowItems drops the SHOW replication items the server does not
+// accept
+func (s serverInfo) filterShowItems(items []prompt.Suggest) []prompt.Suggest {
+	replication := make(map[string]bool)
+	for _, group := range replicationStatements {
+		for _, stmt := range group {
+			replication[stmt] = true
+		}
+	}
+	var kept []prompt.Suggest
+	for _, item := range items {
+		stmt := "SHOW " + item.Text
+		if replication[stmt] && !s.supportsStatement(stmt) {
+			continue
+		}
+		kept = append(kept, item)
+	}
+	return kept
+}
+
+// flavorKeywords are keywords completed only on the flavor or gateway that
+// has them
+var flavorKeywords = map[string][]prompt.Suggest{
+	flavorMariaDB: {
+		{Text: "RETURNING", Description: "RETURNING keyword (MariaDB)"},
+		{Text: "SEQUENCE", Description: "SEQUENCE keyword (MariaDB)"},
+		{Text: "NEXTVAL", Description: "NEXTVAL function (MariaDB)"},
+		{Text: "LASTVAL", Description: "LASTVAL function (MariaDB)"},
+		{Text: "SYSTEM VERSIONING", Description: "SYSTEM VERSIONING keyword (MariaDB)"},
+		{Text: "ANALYZE FORMAT=JSON", Description: "Run and explain a query (MariaDB)"},
+	},
+	flavorTiDB: {
+		{Text: "AUTO_RANDOM", Description: "AUTO_RANDOM keyword (TiDB)"},
+		{Text: "SHARD_ROW_ID_BITS", Description: "SHARD_ROW_ID_BITS keyword (TiDB)"},
+		{Text: "SPLIT TABLE", Description: "Split table regions (TiDB)"},
+		{Text: "FLASHBACK TABLE", Description: "Restore a dropped table (TiDB)"},
+		{Text: "PLACEMENT POLICY", Description: "PLACEMENT POLICY keyword (TiDB)"},
+		{Text: "TRACE", Description: "Trace a statement (TiDB)"},
+	},
+	gatewayVitess: {
+		{Text: "FORMAT=vitess", Description: "EXPLAIN the vtgate plan (Vitess)"},
+		{Text: "VEXPLAIN", Description: "Explain across shards (Vitess)"},
+		{Text: "VITESS_TARGET", Description: "SHOW the current target (Vitess)"},
+		{Text: "VITESS_SHARDS", Description: "SHOW the shards (Vitess)"},
+	},
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/c-bata/go-prompt"
+)
+
+func TestAdaptReplication(t *testing.T) {
+	mysql80 := parseServerInfo("8.0.36", "")
+	mysql84 := parseServerInfo("8.4.2", "")
+	mysql57 := parseServerInfo("5.7.44-log", "")
+	maria104 := parseServerInfo("10.4.32-MariaDB", "")
+	maria1011 := parseServerInfo("10.11.6-MariaDB", "")
+	tidb := parseServerInfo("8.0.11-TiDB-v7.5.0", "")
+
+	tests := []struct {
+		server serverInfo
+		in     string
+		want   string
+	}{
+		{mysql84, "SHOW SLAVE STATUS", "SHOW REPLICA STATUS"},
+		{mysql84, "show  slave\tstatus FOR CHANNEL 'a'", "SHOW REPLICA STATUS FOR CHANNEL 'a'"},
+		{mysql84, "SHOW MASTER STATUS", "SHOW BINARY LOG STATUS"},
+		{mysql84, "START SLAVE IO_THREAD", "START REPLICA IO_THREAD"},
+		{mysql84, "SHOW SLAVE HOSTS", "SHOW REPLICAS"},
+		{mysql80, "SHOW SLAVE STATUS", "SHOW SLAVE STATUS"},
+		{mysql80, "SHOW BINARY LOG STATUS", "SHOW MASTER STATUS"},
+		{mysql57, "SHOW REPLICA STATUS", "SHOW SLAVE STATUS"},
+		{mysql57, "STOP REPLICA", "STOP SLAVE"},
+		{maria104, "SHOW REPLICA STATUS", "SHOW SLAVE STATUS"},
+		{maria1011, "SHOW REPLICA STATUS", "SHOW REPLICA STATUS"},
+		{maria1011, "SHOW REPLICAS", "SHOW SLAVE HOSTS"},
+		{maria1011, "SHOW BINARY LOG STATUS", "SHOW MASTER STATUS"},
+		{tidb, "SHOW SLAVE STATUS", "SHOW SLAVE STATUS"},
+		{mysql84, "SHOW REPLICAS", "SHOW REPLICAS"},
+		{mysql84, "SELECT 'SHOW SLAVE STATUS'", "SELECT 'SHOW SLAVE STATUS'"},
+	}
+	for _, tt := range tests {
+		got, _ := tt.server.adaptReplication(tt.in)
+		if got != tt.want {
+			t.Errorf("%s adaptReplication(%q) = %q, want %q", tt.server, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAdaptAnalyze(t *testing.T) {
+	maria := parseServerInfo("10.11.6-MariaDB", "")
+	mysql := parseServerInfo("8.4.2", "")
+	tests := []struct {
+		server serverInfo
+		in     string
+		want   string
+	}{
+		{maria, "EXPLAIN ANALYZE SELECT 1", "ANALYZE FORMAT=JSON SELECT 1"},
+		{maria, "explain analyze format=tree SELECT 1", "ANALYZE FORMAT=JSON SELECT 1"},
+		{maria, "EXPLAIN SELECT 1", "EXPLAIN SELECT 1"},
+		{mysql, "EXPLAIN ANALYZE SELECT 1", "EXPLAIN ANALYZE SELECT 1"},
+	}
+	for _, tt := range tests {
+		if got, _ := tt.server.adaptAnalyze(tt.in); got != tt.want {
+			t.Errorf("%s adaptAnalyze(%q) = %q, want %q", tt.server, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFeatureGates(t *testing.T) {
+	tests := []struct {
+		version, comment string
+		explainInto      bool
+		jsonPlan         bool
+	}{
+		{"8.4.2", "MySQL Community Server - GPL", true, true},
+		{"8.0.36-28", "Percona Server (GPL)", false, true},
+		{"8.4.0-1", "Percona Server (GPL)", true, true},
+		{"11.4.2-MariaDB", "", false, true},
+		{"8.0.11-TiDB-v7.5.0", "", false, false},
+	}
+	for _, tt := range tests {
+		info := parseServerInfo(tt.version, tt.comment)
+		if got := info.supportsExplainInto(); got != tt.explainInto {
+			t.Errorf("%s supportsExplainInto = %v, want %v", info, got, tt.explainInto)
+		}
+		if _, err := info.jsonPlanStatement("SELECT 1"); (err == nil) != tt.jsonPlan {
+			t.Errorf("%s jsonPlanStatement err = %v", info, err)
+		}
+	}
+}
+
+func TestFilterShowItems(t *testing.T) {
+	items := []prompt.Suggest{{Text: "TABLES"}, {Text: "SLAVE STATUS"}, {Text: "REPLICA STATUS"}, {Text: "BINLOG STATUS"}}
+	got := parseServerInfo("8.4.2", "").filterShowItems(items)
+	if len(got) != 2 || got[0].Text != "TABLES" || got[1].Text != "REPLICA STATUS" {
+		t.Errorf("filterShowItems on MySQL 8.4 = %v", got)
+	}
+	if got := (serverInfo{}).filterShowItems(items); len(got) != len(items) {
+		t.Errorf("filterShowItems on an unknown server dropped items: %v", got)
+	}
+}
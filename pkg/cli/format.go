@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatConfig holds the [format] section of ~/.go-myclirc. It controls how
+// result values are displayed; the data sent to and read from the server is
+// unchanged.
+type FormatConfig struct {
+	Timezone       string // convert DATETIME/TIMESTAMP values to this zone ("Local", "UTC", "Europe/Berlin")
+	DatetimeFormat string // DATE_FORMAT-style layout for DATETIME/TIMESTAMP values, e.g. "%d/%m/%Y %H:%i"
+	Numbers        string // "thousands" for 1,234,567 or "si" for 1.2M
+}
+
+// Enabled reports whether any formatting option is set
+func (f FormatConfig) Enabled() bool {
+	return f.Timezone != "" || f.DatetimeFormat != "" || f.Numbers != ""
+}
+
+// mysqlDatetimeLayout is how MySQL returns DATETIME and TIMESTAMP values
+const mysqlDatetimeLayout = "2006-01-02 15:04:05.999999999"
+
+// dateFormatSpecifiers maps MySQL DATE_FORMAT specifiers to Go layout elements
+var dateFormatSpecifiers = map[byte]string{
+	'Y': "2006", 'y': "06",
+	'm': "01", 'c': "1", 'M': "January", 'b': "Jan",
+	'd': "02", 'e': "2",
+	'H': "15", 'h': "03", 'I': "03", 'l': "3",
+	'i': "04", 's': "05", 'S': "05", 'f': "000000",
+	'p': "PM", 'W': "Monday", 'a': "Mon",
+	'T': "15:04:05", 'r': "03:04:05 PM",
+}
+
+// goLayout converts a DATE_FORMAT-style format into a Go time layout
+func goLayout(format string) string {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i == len(format)-1 {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		if layout, ok := dateFormatSpecifiers[format[i]]; ok {
+			b.WriteString(layout)
+		} else {
+			// %% and unknown specifiers print the character itself, as in MySQL
+			b.WriteByte(format[i])
+		}
+	}
+	return b.String()
+}
+
+// valueFormatter rewrites result cells according to a FormatConfig
+type valueFormatter struct {
+	cfg    FormatConfig
+	from   *time.Location // zone the server returned datetimes in
+	to     *time.Location
+	layout string
+}
+
+// formatResult applies the [format] options to rows in place, using the
+// column types to decide which cells are datetimes or numbers
+func (p *PromptExecutor) formatResult(types []string, rows [][]string) {
+	if !p.format.Enabled() || len(rows) == 0 || len(types) == 0 {
+		return
+	}
+
+	f := &valueFormatter{cfg: p.format, layout: "2006-01-02 15:04:05.999999"}
+	if p.format.DatetimeFormat != "" {
+		f.layout = goLayout(p.format.DatetimeFormat)
+	}
+	hasDatetime := false
+	for _, typeName := range types {
+		if isDatetimeType(typeName) {
+			hasDatetime = true
+		}
+	}
+	if hasDatetime && p.format.Timezone != "" {
+		to, err := time.LoadLocation(p.format.Timezone)
+		if err != nil {
+			fmt.Printf("Warning: unknown timezone %q in [format]: %v\n", p.format.Timezone, err)
+		} else {
+			f.from, f.to = p.sessionLocation(), to
+		}
+	}
+
+	for _, row := range rows {
+		for i, typeName := range types {
+			row[i] = f.format(typeName, row[i])
+		}
+	}
+}
+
+// format renders one cell of a column with the given database type name
+func (f *valueFormatter) format(typeName, value string) string {
+	if value == "NULL" || value == "" {
+		return value
+	}
+	switch {
+	case isDatetimeType(typeName):
+		if f.to == nil && f.cfg.DatetimeFormat == "" {
+			return value
+		}
+		t, err := time.ParseInLocation(mysqlDatetimeLayout, value, f.locationOrUTC())
+		if err != nil {
+			// Zero dates and other values Go cannot represent are shown as is
+			return value
+		}
+		if f.to != nil {
+			t = t.In(f.to)
+		}
+		return t.Format(f.layout)
+	case isNumericType(typeName):
+		switch f.cfg.Numbers {
+		case "thousands":
+			return groupThousands(value)
+		case "si":
+			return siSuffix(value)
+		}
+	}
+	return value
+}
+
+func (f *valueFormatter) locationOrUTC() *time.Location {
+	if f.from != nil {
+		return f.from
+	}
+	return time.UTC
+}
+
+// sessionLocation returns the session time zone, which is the zone MySQL
+// returns TIMESTAMP values in. DATETIME values are assumed to use it too.
+func (p *PromptExecutor) sessionLocation() *time.Location {
+	var sessionZone, systemZone string
+	if err := p.db.QueryRow("SELECT @@session.time_zone, @@system_time_zone").Scan(&sessionZone, &systemZone); err != nil {
+		return time.UTC
+	}
+	zone := sessionZone
+	if zone == "SYSTEM" {
+		zone = systemZone
+	}
+	return parseMySQLTimezone(zone)
+}
+
+// parseMySQLTimezone understands the forms @@time_zone takes: "+05:30",
+// named zones and abbreviations such as UTC
+func parseMySQLTimezone(zone string) *time.Location {
+	if len(zone) == 6 && (zone[0] == '+' || zone[0] == '-') && zone[3] == ':' {
+		hours, errH := strconv.Atoi(zone[1:3])
+		minutes, errM := strconv.Atoi(zone[4:])
+		if errH == nil && errM == nil {
+			offset := hours*3600 + minutes*60
+			if zone[0] == '-' {
+				offset = -offset
+			}
+			return time.FixedZone(zone, offset)
+		}
+	}
+	if loc, err := time.LoadLocation(zone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+func isDatetimeType(typeName string) bool {
+	return typeName == "DATETIME" || typeName == "TIMESTAMP"
+}
+
+func isNumericType(typeName string) bool {
+	typeName = strings.TrimPrefix(typeName, "UNSIGNED ")
+	switch typeName {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT", "DECIMAL", "FLOAT", "DOUBLE":
+		return true
+	}
+	return false
+}
+
+// groupThousands inserts separators into the integer part of a number:
+// 1234567.891 becomes 1,234,567.891
+func groupThousands(value string) string {
+	sign := ""
+	if strings.HasPrefix(value, "-") {
+		sign, value = "-", value[1:]
+	}
+	intPart, frac, hasFrac := strings.Cut(value, ".")
+	if intPart == "" || strings.Trim(intPart, "0123456789") != "" {
+		return sign + value
+	}
+
+	var b strings.Builder
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	if hasFrac {
+		return sign + b.String() + "." + frac
+	}
+	return sign + b.String()
+}
+
+// siSuffix abbreviates a number with k, M, G, T, P or E: 1234567 becomes 1.2M.
+// Values below 1000 are left alone.
+func siSuffix(value string) string {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.Abs(n) < 1000 {
+		return value
+	}
+	const suffixes = "kMGTPE"
+	exp := -1
+	for math.Abs(n) >= 1000 && exp < len(suffixes)-1 {
+		n /= 1000
+		exp++
+	}
+	s := strconv.FormatFloat(n, 'f', 1, 64)
+	s = strings.TrimSuffix(s, ".0")
+	return s + string(suffixes[exp])
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGoLayout(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%Y-%m-%d %H:%i:%s", "2006-01-02 15:04:05"},
+		{"%d/%m/%Y %h:%i %p", "02/01/2006 03:04 PM"},
+		{"%W %e %M", "Monday 2 January"},
+		{"100%%", "100%"},
+	}
+	for _, tt := range tests {
+		if got := goLayout(tt.format); got != tt.want {
+			t.Errorf("goLayout(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestGroupThousands(t *testing.T) {
+	tests := map[string]string{
+		"0":                        "0",
+		"999":                      "999",
+		"1000":                     "1,000",
+		"1234567.891":              "1,234,567.891",
+		"-98765":                   "-98,765",
+		"123456789012345678901234": "123,456,789,012,345,678,901,234",
+		"1e+06":                    "1e+06",
+	}
+	for in, want := range tests {
+		if got := groupThousands(in); got != want {
+			t.Errorf("groupThousands(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSISuffix(t *testing.T) {
+	tests := map[string]string{
+		"999":         "999",
+		"1000":        "1k",
+		"1234567":     "1.2M",
+		"-2500000000": "-2.5G",
+		"abc":         "abc",
+	}
+	for in, want := range tests {
+		if got := siSuffix(in); got != want {
+			t.Errorf("siSuffix(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestValueFormatterDatetime(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone database not available")
+	}
+	f := &valueFormatter{
+		cfg:    FormatConfig{Timezone: "America/New_York", DatetimeFormat: "%d/%m/%Y %H:%i"},
+		from:   time.UTC,
+		to:     ny,
+		layout: goLayout("%d/%m/%Y %H:%i"),
+	}
+
+	tests := []struct {
+		typeName, value, want string
+	}{
+		{"TIMESTAMP", "2024-07-01 16:30:00", "01/07/2024 12:30"},
+		{"DATETIME", "2024-01-15 05:00:00.250000", "15/01/2024 00:00"},
+		{"DATETIME", "0000-00-00 00:00:00", "0000-00-00 00:00:00"},
+		{"DATETIME", "NULL", "NULL"},
+		{"VARCHAR", "2024-07-01 16:30:00", "2024-07-01 16:30:00"},
+	}
+	for _, tt := range tests {
+		if got := f.format(tt.typeName, tt.value); got != tt.want {
+			t.Errorf("format(%s, %q) = %q, want %q", tt.typeName, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestValueFormatterNumbersOnlyForNumericColumns(t *testing.T) {
+	f := &valueFormatter{cfg: FormatConfig{Numbers: "thousands"}}
+	if got := f.format("UNSIGNED BIGINT", "1234567"); got != "1,234,567" {
+		t.Errorf("BIGINT formatted as %q", got)
+	}
+	if got := f.format("VARCHAR", "1234567"); got != "1234567" {
+		t.Errorf("VARCHAR formatted as %q", got)
+	}
+}
+
+func TestParseMySQLTimezone(t *testing.T) {
+	loc := parseMySQLTimezone("+05:30")
+	if _, offset := time.Date(2024, 1, 1, 0, 0, 0, 0, loc).Zone(); offset != 5*3600+30*60 {
+		t.Errorf("+05:30 offset = %d", offset)
+	}
+	loc = parseMySQLTimezone("-08:00")
+	if _, offset := time.Date(2024, 1, 1, 0, 0, 0, 0, loc).Zone(); offset != -8*3600 {
+		t.Errorf("-08:00 offset = %d", offset)
+	}
+	if parseMySQLTimezone("not-a-zone") != time.UTC {
+		t.Errorf("unknown zone should fall back to UTC")
+	}
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Gateways that sit between go-mycli and the server
+const (
+	gatewayProxySQL = "ProxySQL"
+	gatewayVitess   = "Vitess"
+)
+
+// behindProxySQL reports whether the connection goes through ProxySQL, which
+// answers this exact query itself instead of forwarding it to a backend
+func behindProxySQL(db *sql.DB) bool {
+	var comment sql.NullString
+	if db.QueryRow("select @@version_comment limit 1").Scan(&comment) != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(comment.String), "proxysql")
+}
+
+// showGatewayStatus prints the gateway lines of \s: the ProxySQL hostgroup or
+// the Vitess target the session is routed to
+func (p *PromptExecutor) showGatewayStatus() {
+	info := p.serverInfo()
+	switch info.Gateway {
+	case gatewayProxySQL:
+		fmt.Println("Gateway:\t\tProxySQL")
+		if hostgroup, ok := proxySQLHostgroup(p.db); ok {
+			fmt.Printf("Hostgroup:\t\t%d\n", hostgroup)
+		}
+	case gatewayVitess:
+		fmt.Println("Gateway:\t\tVitess (vtgate)")
+		var target sql.NullString
+		if p.db.QueryRow("SHOW VITESS_TARGET").Scan(&target) == nil {
+			if target.String == "" {
+				target.String = "(default)"
+			}
+			fmt.Printf("Vitess target:\t\t%s\n", target.String)
+		}
+	}
+}
+
+// proxySQLHostgroup reads the session's default hostgroup from
+// PROXYSQL INTERNAL SESSION, which ProxySQL answers with a JSON document
+func proxySQLHostgroup(db *sql.DB) (int, bool) {
+	var doc string
+	if db.QueryRow("PROXYSQL INTERNAL SESSION").Scan(&doc) != nil {
+		return 0, false
+	}
+	return parseProxySQLHostgroup(doc)
+}
+
+func parseProxySQLHostgroup(doc string) (int, bool) {
+	var session struct {
+		DefaultHostgroup *int `json:"default_hostgroup"`
+	}
+	if json.Unmarshal([]byte(doc), &session) != nil || session.DefaultHostgroup == nil {
+		return 0, false
+	}
+	return *session.DefaultHostgroup, true
+}
+
+// gatewayExplainTip suggests the gateway's own EXPLAIN when a MySQL JSON plan
+// could not be obtained, or returns "" when there is nothing to suggest
+func (s serverInfo) gatewayExplainTip() string {
+	if s.Gateway == gatewayVitess {
+		return "On Vitess, EXPLAIN FORMAT=vitess <your query> shows how vtgate routes it"
+	}
+	return ""
+}
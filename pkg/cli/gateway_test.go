@@ -0,0 +1,46 @@
+package cli
+
+import "testing"
+
+func TestVitessDetection(t *testing.T) {
+	info := parseServerInfo("8.0.30-Vitess", "")
+	if info.Gateway != gatewayVitess || info.Flavor != flavorMySQL {
+		t.Errorf("parseServerInfo(8.0.30-Vitess) = %+v", info)
+	}
+	if info.supportsExplainInto() {
+		t.Error("EXPLAIN INTO should be off through Vitess")
+	}
+	if info.gatewayExplainTip() == "" {
+		t.Error("expected an EXPLAIN FORMAT=vitess tip")
+	}
+	if got := info.String(); got != "MySQL 8.0.30 via Vitess" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestExplainIntoThroughProxySQL(t *testing.T) {
+	info := parseServerInfo("8.4.2", "")
+	info.Gateway = gatewayProxySQL
+	if info.supportsExplainInto() {
+		t.Error("EXPLAIN INTO should be off through ProxySQL")
+	}
+}
+
+func TestParseProxySQLHostgroup(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want int
+		ok   bool
+	}{
+		{`{"default_hostgroup":10,"transaction_persistent":true}`, 10, true},
+		{`{"default_hostgroup":0}`, 0, true},
+		{`{"backends":[]}`, 0, false},
+		{`not json`, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseProxySQLHostgroup(tt.doc)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseProxySQLHostgroup(%q) = %d, %v, want %d, %v", tt.doc, got, ok, tt.want, tt.ok)
+		}
+	}
+}
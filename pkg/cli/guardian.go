@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Ways long_query_notify can announce a long statement
+const (
+	notifyBell    = "bell"
+	notifyDesktop = "desktop"
+	notifyBoth    = "both"
+)
+
+// timerDelay is how long a statement runs before the live timer appears, so
+// quick statements do not flicker
+const timerDelay = time.Second
+
+// timerTick is how often the live timer is redrawn
+const timerTick = 100 * time.Millisecond
+
+// queryGuard holds the live timer and long statement alert settings and the
+// watch of the running statement
+type queryGuard struct {
+	timer  bool          // show the elapsed time while a statement runs
+	alert  time.Duration // announce statements running longer, 0 for never
+	notify string        // bell, desktop or both
+	watch  *statementWatch
+}
+
+// statementWatch draws the timer of one statement on its own goroutine
+type statementWatch struct {
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+	conn atomic.Int64 // connection running the statement, 0 until known
+}
+
+func parseNotify(value string) (string, bool) {
+	switch v := strings.ToLower(strings.TrimSpace(value)); v {
+	case notifyBell, notifyDesktop, notifyBoth:
+		return v, true
+	}
+	return "", false
+}
+
+func alertName(after time.Duration, notify string) string {
+	if after <= 0 {
+		return "off"
+	}
+	return fmt.Sprintf("after %s (%s)", after, notify)
+}
+
+// setTiming implements \timing [on|off], which shows or hides the live
+// timer of running statements and saves the choice as live_timer
+func (p *PromptExecutor) setTiming(args string) {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "":
+		p.guard.timer = !p.guard.timer
+	case "on":
+		p.guard.timer = true
+	case "off":
+		p.guard.timer = false
+	default:
+		fmt.Println("Usage: \\timing [on|off]")
+		return
+	}
+	if p.guard.timer {
+		fmt.Println("Live timer on: the elapsed time is shown while a statement runs")
+	} else {
+		fmt.Println("Live timer off")
+	}
+	saveSetting("live_timer", strconv.FormatBool(p.guard.timer))
+}
+
+// watchStatement starts the live timer and alert for a statement typed at the
+// prompt. Scripts and non-terminal output are left alone.
+func (p *PromptExecutor) watchStatement() {
+	g := &p.guard
+	if (!g.timer && g.alert <= 0) || p.input == nil || p.sourceFileMode || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return
+	}
+	w := &statementWatch{stop: make(chan struct{}), done: make(chan struct{})}
+	g.watch = w
+	start := time.Now()
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(timerTick)
+		defer ticker.Stop()
+		width := 0
+		alerted := false
+		for {
+			select {
+			case <-w.stop:
+				if width > 0 {
+					fmt.Printf("\r%s\r", strings.Repeat(" ", width))
+				}
+				return
+			case <-ticker.C:
+			}
+			elapsed := time.Since(start)
+			if g.alert > 0 && !alerted && elapsed >= g.alert {
+				alerted = true
+				if width > 0 {
+					fmt.Printf("\r%s\r", strings.Repeat(" ", width))
+					width = 0
+				}
+				fmt.Println(longQueryMessage(g.alert, w.conn.Load()))
+				announceLongQuery(g.notify, g.alert)
+			}
+			if g.timer && elapsed >= timerDelay {
+				line := "Running " + elapsed.Round(timerTick).String()
+				fmt.Printf("\r%-*s", width, line)
+				width = max(width, len(line))
+			}
+		}
+	}()
+}
+
+// stopWatch stops the timer and clears its line before the statement's
+// output is printed. It may be called more than once.
+func (p *PromptExecutor) stopWatch() {
+	w := p.guard.watch
+	if w == nil {
+		return
+	}
+	w.once.Do(func() {
+		close(w.stop)
+		<-w.done
+	})
+	p.guard.watch = nil
+}
+
+// watchConnection records the connection the watched statement runs on,
+// which runStatement learns once it has one from the pool
+func (g *queryGuard) watchConnection(id int64) {
+	if g.watch != nil {
+		g.watch.conn.Store(id)
+	}
+}
+
+// longQueryMessage tells how to stop a statement running on connection id
+func longQueryMessage(after time.Duration, id int64) string {
+	if id == 0 {
+		return fmt.Sprintf("Statement still running after %s", after)
+	}
+	return fmt.Sprintf("Statement still running after %s on connection %d; stop it from another session with: KILL QUERY %d", after, id, id)
+}
+
+// announceLongQuery rings the terminal bell and/or shows a desktop
+// notification
+func announceLongQuery(notify string, after time.Duration) {
+	if notify == notifyBell || notify == notifyBoth {
+		fmt.Print("\a")
+	}
+	if notify == notifyDesktop || notify == notifyBoth {
+		if cmd := notificationCommand("go-mycli", fmt.Sprintf("Statement still running after %s", after)); cmd != nil {
+			_ = cmd.Start()
+			go cmd.Wait()
+		}
+	}
+}
+
+// notificationCommand returns the command showing a desktop notification on
+// this system, or nil when there is none
+func notificationCommand(title, message string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", message, title)
+		return exec.Command("osascript", "-e", script)
+	case "linux", "freebsd", "openbsd":
+		if path, err := exec.LookPath("notify-send"); err == nil {
+			return exec.Command(path, title, message)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGuardianConfig(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), ".go-myclirc")
+	t.Setenv("GO_MYCLI_RC", rc)
+
+	cfg := LoadSyntaxConfig()
+	if !cfg.LiveTimer || cfg.LongQueryAlert != 0 || cfg.LongQueryNotify != notifyBell {
+		t.Errorf("defaults = %v, %s, %q; want true, 0s, bell", cfg.LiveTimer, cfg.LongQueryAlert, cfg.LongQueryNotify)
+	}
+	content := "[main]\nlive_timer = false\nlong_query_alert = 30s\nlong_query_notify = Desktop\n"
+	if err := os.WriteFile(rc, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg = LoadSyntaxConfig()
+	if cfg.LiveTimer || cfg.LongQueryAlert != 30*time.Second || cfg.LongQueryNotify != notifyDesktop {
+		t.Errorf("loaded = %v, %s, %q; want false, 30s, desktop", cfg.LiveTimer, cfg.LongQueryAlert, cfg.LongQueryNotify)
+	}
+	if err := os.WriteFile(rc, []byte("[main]\nlong_query_notify = siren\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if cfg = LoadSyntaxConfig(); cfg.LongQueryNotify != notifyBell {
+		t.Errorf("unknown long_query_notify gave %q, want the bell default", cfg.LongQueryNotify)
+	}
+}
+
+func TestAlertName(t *testing.T) {
+	if got := alertName(0, notifyBell); got != "off" {
+		t.Errorf("alertName(0) = %q, want off", got)
+	}
+	if got := alertName(time.Minute, notifyBoth); got != "after 1m0s (both)" {
+		t.Errorf("alertName(1m, both) = %q", got)
+	}
+}
+
+func TestLongQueryMessage(t *testing.T) {
+	tests := []struct {
+		id   int64
+		want string
+	}{
+		{0, "Statement still running after 30s"},
+		{4211, "Statement still running after 30s on connection 4211; stop it from another session with: KILL QUERY 4211"},
+	}
+	for _, tt := range tests {
+		if got := longQueryMessage(30*time.Second, tt.id); got != tt.want {
+			t.Errorf("longQueryMessage(%d) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestStopWatchWithoutTimer(t *testing.T) {
+	// Outside a terminal no watch starts, and stopping is a no-op
+	p := &PromptExecutor{input: &inputParser{}, guard: queryGuard{timer: true}}
+	p.watchStatement()
+	p.stopWatch()
+	p.stopWatch()
+	if p.guard.watch != nil {
+		t.Error("watch left running")
+	}
+}
+
+func TestWatchConnection(t *testing.T) {
+	var g queryGuard
+	g.watchConnection(42) // no statement watched
+	g.watch = &statementWatch{}
+	g.watchConnection(42)
+	if id := g.watch.conn.Load(); id != 42 {
+		t.Errorf("watched connection = %d, want the statement's own 42", id)
+	}
+}
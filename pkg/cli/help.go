@@ -0,0 +1,307 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// helpCommands are the lines of \h: a command with its arguments, and what
+// it does
+var helpCommands = []struct{ Command, Description string }{
+	{"\\c, \\clear", "Clear the current input statement"},
+	{"\\colors", "Test syntax highlighting with examples"},
+	{"\\theme [list|preview <name>|set <name>|next|prev]", "Browse the syntax themes and switch to one"},
+	{"\\config", "Show current syntax highlighting configuration"},
+	{"\\config edit", "Open ~/.go-myclirc in $EDITOR, then reload it"},
+	{"\\config reload", "Re-read ~/.go-myclirc, reporting unknown keys and bad values"},
+	{"\\g, \\go", "Send command to mysql server"},
+	{"\\h, \\help", "Display this help"},
+	{"\\help <topic>", "Show the server's help for a statement or function (e.g. \\help SELECT)"},
+	{"\\p, \\print", "Print current command"},
+	{"\\q, \\quit", "Exit mysql"},
+	{"\\r, \\connect", "Reconnect to the server"},
+	{"\\s", "Display server status"},
+	{"\\profile [on|detail|off]", "Show a stage timing breakdown after each statement; detail adds memory and temporary tables"},
+	{"\\ping [n]", "Measure round-trip latency to the server over n queries (default 5)"},
+	{"\\bg <query>", "Run a statement on its own connection in the background"},
+	{"\\jobs", "List background jobs"},
+	{"\\alter-safe <ALTER TABLE ...>", "Check the least disruptive algorithm, confirm, then run with progress"},
+	{"\\chunked <DELETE|UPDATE> [--batch n] [--sleep d] [--max-lag d]", "Run a DELETE or UPDATE in primary key chunks, pausing for replica lag"},
+	{"\\replica-lag [on|off|<max>]", "Show replica lag; on makes writes wait while it is over max_replica_lag"},
+	{"\\drop-safe <table>, \\truncate-safe <table>", "Report foreign keys, triggers and activity, then DROP or TRUNCATE once the name is typed"},
+	{"\\save-session <name>", "Save database, SET variables, toggles and history; restore with --resume <name>"},
+	{"\\result [id]", "Show the output of a background job (default: the latest)"},
+	{"\\l", "List databases with table counts and sizes"},
+	{"\\dt [pattern]", "List tables, optionally filtered (e.g. \\dt film*, \\dt sakila.*)"},
+	{"\\d <table>", "Describe a table: columns, indexes and foreign keys"},
+	{"\\browse", "Browse databases, tables and columns; Enter inserts the selected name"},
+	{"\\view [buffer]", "Open the last result, or a kept one, in the scrollable viewer (sort, search)"},
+	{"\\buffers", "List the recent results kept in memory (buf1, buf2, ... and bookmarks)"},
+	{"\\show [buffer] [> file]", "Print a kept result again, or save it as .csv, .json or a table"},
+	{"\\bookmark <name> [buffer]", "Name a kept result so it is not dropped"},
+	{"\\expand <row> <col>", "Show the full value of one cell of the last result"},
+	{"\\topology", "Draw the replication tree below this server with lag and GTID gaps per replica"},
+	{"\\binlog [file] [--from pos] [--schema s] [--table t] [--type insert|update|delete|ddl|<event>] [--limit n] [--list]", "Browse binary log events"},
+	{"\\count~ <table> [WHERE ...]", "Estimate the rows from statistics or EXPLAIN, then optionally count exactly"},
+	{"\\edit-rows", "Edit the cells of the last result (a SELECT from one table with its primary key) and run the UPDATEs"},
+	{"\\output [table|vertical|csv|json]", "Show or set the format query results are printed in"},
+	{"\\W, \\w", "Show or stop showing the server's warnings after every statement"},
+	{"\\cost [on|off]", "Show the query cost and rows read for rows returned after each SELECT"},
+	{"\\timing [on|off]", "Show or hide the elapsed time while a statement runs"},
+	{"\\pager [command]", "Page results taller than the terminal through command ($PAGER or less by default)"},
+	{"\\nopager", "Print results without the pager"},
+	{"\\u <db>", "Use another database. Takes database name as argument"},
+	{"\\. <file>", "Execute an SQL script file. Takes a file name as an argument. Supports zstd compressed files"},
+	{"\\! <cmd>", "Run a command with the system shell in this terminal, reporting a non-zero exit status"},
+	{"\\suggestions", "Toggle suggestions: \"on\" or \"off\""},
+	{"\\completion [auto|full|metadata|off]", "Show or set how much schema completion loads"},
+	{"\\ai", "Toggle AI EXPLAIN analysis: \"on\" or \"off\""},
+	{"\\ai compare <query A> ;; <query B>", "Compare the plans of two queries and ask the AI which is preferable"},
+	{"\\analyze-paste", "Analyze an EXPLAIN plan (JSON or TREE) pasted from elsewhere, ended with ;"},
+	{"\\report <file.md>", "Save the last EXPLAIN with its findings, AI analysis and table definitions as Markdown"},
+	{"\\record start <file> | stop", "Capture every statement run, with timing, for go-mycli replay"},
+	{"\\review-export <file.sql|file.json>", "Save the session's statements by fingerprint for pt-query-digest review tables"},
+	{"\\json", "Toggle JSON export for external tools: \"on\" or \"off\""},
+	{"\\visual", "Toggle built-in visual explain: \"on\" or \"off\""},
+	{"\\visual export <file.html|svg|dot>", "Save the last EXPLAIN plan as a collapsible, cost-colored page or a graphviz image"},
+	{"\\plugins", "List plugin commands from ~/.go-mycli/plugins"},
+	{"\\alias [name = text]", "List aliases or define one for this session; \\unalias <name> removes it"},
+	{"\\runbook [name [param=value ...]]", "List runbooks, or fill one in, preview its statements and run them"},
+}
+
+// printHelp prints \h in the session's language, then the plugin commands
+func printHelp() {
+	fmt.Println(tr("MySQL commands:"))
+	for _, c := range helpCommands {
+		fmt.Printf("%-13s %s\n", c.Command, tr(c.Description))
+	}
+	if plugins := discoverPlugins(pluginDir()); len(plugins) > 0 {
+		fmt.Println("\n" + tr("Plugin commands:"))
+		for _, name := range pluginNames(plugins) {
+			fmt.Printf("\\%s\n", name)
+		}
+	}
+}
+
+// helpTopic is one row of the server's HELP statement
+type helpTopic struct {
+	Name        string
+	Description string
+	Example     string
+	Category    bool
+}
+
+// showStatementHelp handles \help <topic>: the server's help for a statement,
+// function or category, like the mysql client's help command. When the
+// server has no help tables loaded, a short built-in summary is shown.
+func (p *PromptExecutor) showStatementHelp(topic string) {
+	topic = strings.Trim(strings.TrimSpace(topic), "'\";")
+	if topic == "" {
+		fmt.Println("Usage: \\help <statement or function>, e.g. \\help SELECT or \\help CREATE TABLE")
+		return
+	}
+
+	topics, err := serverHelp(p.db, topic)
+	if err == nil && len(topics) == 1 && !topics[0].Category {
+		printHelpTopic(topics[0])
+		if related := p.relatedTopics(topics[0].Name); len(related) > 0 {
+			fmt.Printf("\nRelated topics: %s\n", strings.Join(related, ", "))
+		}
+		return
+	}
+	if err == nil && len(topics) > 0 {
+		fmt.Printf("Many help items for your request exist.\nTo make a more specific request, please type '\\help <item>',\nwhere <item> is one of the following:\n")
+		printHelpList(topics)
+		return
+	}
+
+	if text, ok := offlineHelp(topic); ok {
+		fmt.Println("(server help not available; built-in summary)")
+		fmt.Println(text)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Server help failed: %v\n", err)
+	}
+	fmt.Printf("Nothing found for %q. Try \\help contents.\n", topic)
+}
+
+// serverHelp runs HELP 'topic'. The server answers with name, description
+// and example for a single match, or with name and is_it_category for a
+// list of matches.
+func serverHelp(db *sql.DB, topic string) ([]helpTopic, error) {
+	if db == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	rows, err := db.Query("HELP '" + strings.ReplaceAll(topic, "'", "''") + "'")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Categories and topics come as separate result sets; read them all
+	var topics []helpTopic
+	for {
+		cols, err := rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var t helpTopic
+			switch len(cols) {
+			case 3:
+				err = rows.Scan(&t.Name, &t.Description, &t.Example)
+			case 2:
+				var isCategory string
+				err = rows.Scan(&t.Name, &isCategory)
+				t.Category = strings.EqualFold(isCategory, "Y")
+			default:
+				return nil, fmt.Errorf("unexpected HELP result with %d columns", len(cols))
+			}
+			if err != nil {
+				return nil, err
+			}
+			topics = append(topics, t)
+		}
+		if !rows.NextResultSet() {
+			break
+		}
+	}
+	return topics, rows.Err()
+}
+
+func printHelpTopic(t helpTopic) {
+	fmt.Printf("Name: '%s'\n", t.Name)
+	fmt.Printf("Description:\n%s\n", strings.TrimRight(t.Description, "\n"))
+	if example := strings.TrimSpace(t.Example); example != "" {
+		fmt.Printf("Examples:\n%s\n", example)
+	}
+}
+
+func printHelpList(topics []helpTopic) {
+	for _, t := range topics {
+		if t.Category {
+			fmt.Printf("   %s (category)\n", t.Name)
+		} else {
+			fmt.Printf("   %s\n", t.Name)
+		}
+	}
+}
+
+// relatedTopics lists the other help topics that start with the same word as
+// name, e.g. SHOW CREATE TABLE and SHOW INDEX for SHOW COLUMNS
+func (p *PromptExecutor) relatedTopics(name string) []string {
+	word, _, _ := strings.Cut(name, " ")
+	topics, err := serverHelp(p.db, word+" %")
+	if err != nil {
+		return nil
+	}
+	var related []string
+	for _, t := range topics {
+		if !t.Category && !strings.EqualFold(t.Name, name) {
+			related = append(related, t.Name)
+		}
+	}
+	sort.Strings(related)
+	if len(related) > 12 {
+		related = append(related[:12], "...")
+	}
+	return related
+}
+
+// offlineHelp returns the built-in summary for a topic, matching the longest
+// statement name the topic starts with
+func offlineHelp(topic string) (string, bool) {
+	topic = strings.Join(strings.Fields(strings.ToUpper(topic)), " ")
+	best := ""
+	for name := range offlineHelpTopics {
+		if (topic == name || strings.HasPrefix(topic, name+" ")) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return "", false
+	}
+	return offlineHelpTopics[best], true
+}
+
+// offlineHelpTopics are syntax summaries of the most common statements
+var offlineHelpTopics = map[string]string{
+	"SELECT": `SELECT [DISTINCT] select_expr [, select_expr] ...
+    [FROM table_references]
+    [WHERE where_condition]
+    [GROUP BY {col_name | expr | position}, ... [WITH ROLLUP]]
+    [HAVING where_condition]
+    [WINDOW window_name AS (window_spec), ...]
+    [ORDER BY {col_name | expr | position} [ASC | DESC], ...]
+    [LIMIT {[offset,] row_count | row_count OFFSET offset}]
+    [FOR {UPDATE | SHARE} [NOWAIT | SKIP LOCKED]]`,
+	"INSERT": `INSERT [IGNORE] [INTO] tbl_name [(col_name [, col_name] ...)]
+    {VALUES | VALUE} (value_list) [, (value_list)] ...
+    [ON DUPLICATE KEY UPDATE assignment_list]
+
+INSERT [IGNORE] [INTO] tbl_name [(col_name [, col_name] ...)]
+    SELECT ...`,
+	"REPLACE": `REPLACE [INTO] tbl_name [(col_name [, col_name] ...)]
+    {VALUES | VALUE} (value_list) [, (value_list)] ...`,
+	"UPDATE": `UPDATE [LOW_PRIORITY] [IGNORE] table_reference
+    SET assignment_list
+    [WHERE where_condition]
+    [ORDER BY ...]
+    [LIMIT row_count]`,
+	"DELETE": `DELETE [LOW_PRIORITY] [QUICK] [IGNORE] FROM tbl_name
+    [WHERE where_condition]
+    [ORDER BY ...]
+    [LIMIT row_count]`,
+	"CREATE TABLE": `CREATE [TEMPORARY] TABLE [IF NOT EXISTS] tbl_name
+    (create_definition, ...)
+    [table_options]
+    [partition_options]
+
+create_definition: col_name column_definition
+  | {INDEX | KEY} [index_name] (key_part, ...)
+  | [CONSTRAINT [symbol]] PRIMARY KEY (key_part, ...)
+  | [CONSTRAINT [symbol]] UNIQUE [INDEX | KEY] [index_name] (key_part, ...)
+  | [CONSTRAINT [symbol]] FOREIGN KEY [index_name] (col_name, ...) reference_definition
+  | [CONSTRAINT [symbol]] CHECK (expr)`,
+	"ALTER TABLE": `ALTER TABLE tbl_name
+    [alter_option [, alter_option] ...]
+
+alter_option: ADD [COLUMN] col_name column_definition [FIRST | AFTER col_name]
+  | ADD {INDEX | KEY} [index_name] (key_part, ...)
+  | DROP [COLUMN] col_name
+  | DROP {INDEX | KEY} index_name
+  | MODIFY [COLUMN] col_name column_definition
+  | CHANGE [COLUMN] old_col_name new_col_name column_definition
+  | RENAME [TO | AS] new_tbl_name
+  | ALGORITHM [=] {DEFAULT | INSTANT | INPLACE | COPY}
+  | LOCK [=] {DEFAULT | NONE | SHARED | EXCLUSIVE}`,
+	"DROP TABLE": `DROP [TEMPORARY] TABLE [IF EXISTS] tbl_name [, tbl_name] ...`,
+	"TRUNCATE":   `TRUNCATE [TABLE] tbl_name`,
+	"CREATE INDEX": `CREATE [UNIQUE | FULLTEXT | SPATIAL] INDEX index_name
+    ON tbl_name (key_part, ...)
+    [ALGORITHM [=] {DEFAULT | INPLACE | COPY}]
+    [LOCK [=] {DEFAULT | NONE | SHARED | EXCLUSIVE}]`,
+	"EXPLAIN": `EXPLAIN [FORMAT = {TRADITIONAL | JSON | TREE}] explainable_stmt
+EXPLAIN ANALYZE [FORMAT = TREE] select_statement
+{EXPLAIN | DESCRIBE | DESC} tbl_name [col_name | wild]`,
+	"SHOW": `SHOW {DATABASES | TABLES | COLUMNS FROM tbl | INDEX FROM tbl | CREATE TABLE tbl
+    | PROCESSLIST | [GLOBAL | SESSION] VARIABLES | [GLOBAL | SESSION] STATUS
+    | GRANTS [FOR user] | WARNINGS | ERRORS | ENGINE INNODB STATUS | ...}
+    [LIKE 'pattern' | WHERE expr]`,
+	"SET": `SET [GLOBAL | SESSION | PERSIST] var_name = expr [, var_name = expr] ...
+SET @user_var = expr
+SET NAMES charset_name [COLLATE collation_name]
+SET TRANSACTION ISOLATION LEVEL {READ UNCOMMITTED | READ COMMITTED | REPEATABLE READ | SERIALIZABLE}`,
+	"USE": `USE db_name`,
+	"GRANT": `GRANT priv_type [(column_list)] [, priv_type [(column_list)]] ...
+    ON [object_type] priv_level
+    TO user_or_role [, user_or_role] ...
+    [WITH GRANT OPTION]`,
+	"START TRANSACTION": `START TRANSACTION [READ WRITE | READ ONLY | WITH CONSISTENT SNAPSHOT]
+BEGIN [WORK]
+COMMIT [WORK]
+ROLLBACK [WORK] [TO [SAVEPOINT] identifier]`,
+}
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOfflineHelp(t *testing.T) {
+	tests := []struct {
+		topic  string
+		prefix string
+	}{
+		{"select", "SELECT [DISTINCT]"},
+		{"CREATE  table", "CREATE [TEMPORARY] TABLE"},
+		{"ALTER TABLE ADD COLUMN", "ALTER TABLE tbl_name"},
+		{"show", "SHOW {DATABASES"},
+		{"SHOW CREATE TABLE", "SHOW {DATABASES"},
+	}
+	for _, tt := range tests {
+		text, ok := offlineHelp(tt.topic)
+		if !ok || !strings.HasPrefix(text, tt.prefix) {
+			t.Errorf("offlineHelp(%q) = %q, %v; want text starting %q", tt.topic, text, ok, tt.prefix)
+		}
+	}
+	for _, topic := range []string{"CREATE", "SELECTED", "JSON_EXTRACT"} {
+		if _, ok := offlineHelp(topic); ok {
+			t.Errorf("offlineHelp(%q) matched", topic)
+		}
+	}
+}
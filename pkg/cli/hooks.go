@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// HookConfig holds the [hooks] section of ~/.go-myclirc
+type HookConfig struct {
+	PreQuery    string        // shell command run before each statement
+	PostQuery   string        // shell command run after each statement
+	WebhookURL  string        // URL receiving a JSON POST after each statement
+	MinDuration time.Duration // post hooks only fire for statements at least this slow
+	Timeout     time.Duration // limit for each hook invocation
+}
+
+// Enabled reports whether any hook is configured
+func (h HookConfig) Enabled() bool {
+	return h.PreQuery != "" || h.PostQuery != "" || h.WebhookURL != ""
+}
+
+// HookEvent is the payload passed to hooks, as JSON on stdin / in the webhook
+// body and as GO_MYCLI_* environment variables for shell hooks
+type HookEvent struct {
+	Event      string `json:"event"` // pre_query or post_query
+	SQL        string `json:"sql"`
+	Database   string `json:"database"`
+	User       string `json:"user"`
+	Host       string `json:"host"`
+	Port       int    `json:"port"`
+	DurationMS int64  `json:"duration_ms,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// env returns the event as environment variables for shell hooks
+func (e HookEvent) env() []string {
+	return []string{
+		"GO_MYCLI_HOOK_EVENT=" + e.Event,
+		"GO_MYCLI_SQL=" + e.SQL,
+		"GO_MYCLI_DATABASE=" + e.Database,
+		"GO_MYCLI_USER=" + e.User,
+		"GO_MYCLI_HOST=" + e.Host,
+		"GO_MYCLI_PORT=" + strconv.Itoa(e.Port),
+		"GO_MYCLI_DURATION_MS=" + strconv.FormatInt(e.DurationMS, 10),
+		"GO_MYCLI_ERROR=" + e.Error,
+	}
+}
+
+// newHookEvent describes a statement in the current session
+func (p *PromptExecutor) newHookEvent(event, sql string) HookEvent {
+	return HookEvent{
+		Event:    event,
+		SQL:      sql,
+		Database: p.database,
+		User:     p.user,
+		Host:     p.host,
+		Port:     p.port,
+	}
+}
+
+// runPreQueryHooks fires the pre_query hook for sql
+func (p *PromptExecutor) runPreQueryHooks(sql string) {
+	if p.hooks.PreQuery == "" {
+		return
+	}
+	p.runShellHook(p.hooks.PreQuery, p.newHookEvent("pre_query", sql))
+}
+
+// runPostQueryHooks fires the post_query hook and webhook once sql has finished
+func (p *PromptExecutor) runPostQueryHooks(sql string, elapsed time.Duration, queryErr error) {
+	if p.hooks.PostQuery == "" && p.hooks.WebhookURL == "" {
+		return
+	}
+	if elapsed < p.hooks.MinDuration {
+		return
+	}
+
+	event := p.newHookEvent("post_query", sql)
+	event.DurationMS = elapsed.Milliseconds()
+	if queryErr != nil {
+		event.Error = queryErr.Error()
+	}
+
+	if p.hooks.PostQuery != "" {
+		p.runShellHook(p.hooks.PostQuery, event)
+	}
+	if p.hooks.WebhookURL != "" {
+		if err := p.postWebhook(event); err != nil {
+			fmt.Fprintf(os.Stderr, "Hook webhook failed: %v\n", err)
+		}
+	}
+}
+
+// runShellHook runs command with the event in its environment and on stdin.
+// Hook output goes to stderr so it never mixes with query results.
+func (p *PromptExecutor) runShellHook(command string, event HookEvent) {
+	payload, _ := json.Marshal(event)
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.hookTimeout())
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+	cmd.Env = append(os.Environ(), event.env()...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Hook %s failed: %v\n", event.Event, err)
+	}
+}
+
+// postWebhook sends the event as JSON to the configured webhook URL
+func (p *PromptExecutor) postWebhook(event HookEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.hookTimeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.hooks.WebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (p *PromptExecutor) hookTimeout() time.Duration {
+	if p.hooks.Timeout > 0 {
+		return p.hooks.Timeout
+	}
+	return 5 * time.Second
+}
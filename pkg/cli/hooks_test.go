@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPostQueryShellHook(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "hook.out")
+	p := &PromptExecutor{
+		user:     "root",
+		database: "sakila",
+		hooks: HookConfig{
+			PostQuery: `echo "$GO_MYCLI_HOOK_EVENT|$GO_MYCLI_SQL|$GO_MYCLI_ERROR" > ` + out,
+		},
+	}
+
+	p.runPostQueryHooks("SELECT 1", 1500*time.Millisecond, errors.New("boom"))
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "post_query|SELECT 1|boom" {
+		t.Errorf("hook saw %q", got)
+	}
+}
+
+func TestPostQueryHookMinDuration(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "hook.out")
+	p := &PromptExecutor{
+		hooks: HookConfig{
+			PostQuery:   "touch " + out,
+			MinDuration: time.Second,
+		},
+	}
+
+	p.runPostQueryHooks("SELECT 1", 10*time.Millisecond, nil)
+
+	if _, err := os.Stat(out); err == nil {
+		t.Errorf("hook ran for a query faster than min_duration")
+	}
+}
+
+func TestPostQueryWebhook(t *testing.T) {
+	events := make(chan HookEvent, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e HookEvent
+		json.NewDecoder(r.Body).Decode(&e)
+		events <- e
+	}))
+	defer srv.Close()
+
+	p := &PromptExecutor{database: "sakila", hooks: HookConfig{WebhookURL: srv.URL}}
+	p.runPostQueryHooks("UPDATE t SET a = 1", 2*time.Second, nil)
+
+	select {
+	case e := <-events:
+		if e.Event != "post_query" || e.SQL != "UPDATE t SET a = 1" || e.DurationMS != 2000 || e.Database != "sakila" {
+			t.Errorf("unexpected webhook payload: %+v", e)
+		}
+	default:
+		t.Fatalf("webhook was not called")
+	}
+}
+
+func TestLoadSyntaxConfigHooks(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), ".go-myclirc")
+	content := "[hooks]\npre_query = logger -t go-mycli\nwebhook_url = http://example.invalid/hook\nmin_duration = 2s\n"
+	if err := os.WriteFile(rc, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GO_MYCLI_RC", rc)
+
+	cfg := LoadSyntaxConfig()
+	if cfg.Hooks.PreQuery != "logger -t go-mycli" || cfg.Hooks.WebhookURL != "http://example.invalid/hook" || cfg.Hooks.MinDuration != 2*time.Second {
+		t.Errorf("unexpected hooks config: %+v", cfg.Hooks)
+	}
+}
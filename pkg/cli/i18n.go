@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// Messages are looked up by their English text, so a message a catalog does
+// not have yet is shown in English. Only what people read is translated:
+// SQL, JSON output and anything another program parses stay in English.
+
+const (
+	languageAuto    = "auto"
+	languageEnglish = "en"
+	languageSpanish = "es"
+)
+
+// catalogs are the translations of each language but English
+var catalogs = map[string]map[string]string{
+	languageSpanish: spanishMessages,
+}
+
+var (
+	languageOnce sync.Once
+	language     = languageEnglish
+)
+
+// tr returns the message in the session's language
+func tr(msg string) string {
+	languageOnce.Do(func() { setLanguage(LoadSyntaxConfig().Language) })
+	if t, ok := catalogs[language][msg]; ok {
+		return t
+	}
+	return msg
+}
+
+// setLanguage switches the messages to the language of the language setting:
+// en, es, or auto for the one of the environment
+func setLanguage(setting string) {
+	languageOnce.Do(func() {})
+	language = resolveLanguage(setting, os.Getenv)
+}
+
+// resolveLanguage maps the language setting to a catalog; auto follows
+// LC_ALL, LC_MESSAGES and LANG as gettext does. Languages without a catalog
+// are English.
+func resolveLanguage(setting string, getenv func(string) string) string {
+	lang := strings.ToLower(strings.TrimSpace(setting))
+	if lang == "" || lang == languageAuto {
+		lang = ""
+		for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+			if v := getenv(name); v != "" {
+				lang = strings.ToLower(v)
+				break
+			}
+		}
+	}
+	// es_ES.UTF-8, es-MX and es@euro are all Spanish
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := catalogs[lang]; ok {
+		return lang
+	}
+	return languageEnglish
+}
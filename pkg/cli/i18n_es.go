@@ -0,0 +1,89 @@
+package cli
+
+// spanishMessages is the Spanish catalog. Formats keep the verbs of the
+// English message in the same order, or number them.
+var spanishMessages = map[string]string{
+	// Results and errors
+	"Error: %v\n":                                "Error: %v\n",
+	"\n%d row%s in set (%.3fs)\n":                "\n%d fila%s en el conjunto (%.3fs)\n",
+	"%d row%s in set\n":                          "%d fila%s en el conjunto\n",
+	"Query OK, %d row%s affected\nTime: %.3fs\n": "Consulta OK, %[1]d fila%[2]s afectada%[2]s\nTiempo: %.3fs\n",
+	"Values longer than %d characters were truncated; use \\expand <row> <column> to see one in full\n": "Los valores de más de %d caracteres se han recortado; \\expand <fila> <columna> muestra uno completo\n",
+	"No changes made": "No se ha hecho ningún cambio",
+	"Bye":             "Adiós",
+
+	// Plan analysis
+	"AI Performance Analysis:":     "Análisis de rendimiento con IA:",
+	"(AI backend: %s %s)\n":        "(servicio de IA: %s %s)\n",
+	"AI analysis failed: %v\n":     "El análisis con IA ha fallado: %v\n",
+	"Query: %s\n":                  "Consulta: %s\n",
+	"Severity: %s\n":               "Gravedad: %s\n",
+	"Score: %d/100 (%s)\n":         "Puntuación: %d/100 (%s)\n",
+	"\nPlan score: %d/100 (%s)\n":  "\nPuntuación del plan: %d/100 (%s)\n",
+	"No problems found":            "No se han encontrado problemas",
+	"Suggested for %s (%s): %s;\n": "Sugerido para %s (%s): %s;\n",
+	"\nAI analysis (%s):\n%s\n":    "\nAnálisis con IA (%s):\n%s\n",
+
+	// Help
+	"MySQL commands:":                                                         "Comandos de MySQL:",
+	"Plugin commands:":                                                        "Comandos de plugins:",
+	"Clear the current input statement":                                       "Borra la sentencia que se está escribiendo",
+	"Test syntax highlighting with examples":                                  "Prueba el resaltado de sintaxis con ejemplos",
+	"Browse the syntax themes and switch to one":                              "Recorre los temas de sintaxis y cambia a uno",
+	"Show current syntax highlighting configuration":                          "Muestra la configuración actual",
+	"Open ~/.go-myclirc in $EDITOR, then reload it":                           "Abre ~/.go-myclirc en $EDITOR y después lo vuelve a cargar",
+	"Re-read ~/.go-myclirc, reporting unknown keys and bad values":            "Vuelve a leer ~/.go-myclirc, avisando de claves desconocidas y valores erróneos",
+	"Send command to mysql server":                                            "Envía la sentencia al servidor mysql",
+	"Display this help":                                                       "Muestra esta ayuda",
+	"Show the server's help for a statement or function (e.g. \\help SELECT)": "Muestra la ayuda del servidor sobre una sentencia o función (p. ej. \\help SELECT)",
+	"Print current command":                                                   "Imprime la sentencia actual",
+	"Exit mysql":                                                              "Sale de mysql",
+	"Reconnect to the server":                                                 "Vuelve a conectar con el servidor",
+	"Display server status":                                                   "Muestra el estado del servidor",
+	"Show a stage timing breakdown after each statement; detail adds memory and temporary tables": "Desglosa el tiempo por etapas tras cada sentencia; detail añade memoria y tablas temporales",
+	"Measure round-trip latency to the server over n queries (default 5)":                         "Mide la latencia con el servidor en n consultas (5 por defecto)",
+	"Run a statement on its own connection in the background":                                     "Ejecuta una sentencia en segundo plano con su propia conexión",
+	"List background jobs": "Lista las tareas en segundo plano",
+	"Check the least disruptive algorithm, confirm, then run with progress":                                "Comprueba el algoritmo menos disruptivo, pide confirmación y ejecuta mostrando el progreso",
+	"Run a DELETE or UPDATE in primary key chunks, pausing for replica lag":                                "Ejecuta un DELETE o UPDATE por tramos de clave primaria, esperando al retraso de las réplicas",
+	"Show replica lag; on makes writes wait while it is over max_replica_lag":                              "Muestra el retraso de las réplicas; on hace esperar a las escrituras mientras supere max_replica_lag",
+	"Report foreign keys, triggers and activity, then DROP or TRUNCATE once the name is typed":             "Informa de claves foráneas, triggers y actividad, y hace DROP o TRUNCATE al escribir el nombre",
+	"Save database, SET variables, toggles and history; restore with --resume <name>":                      "Guarda base de datos, variables SET, opciones e historial; se restaura con --resume <nombre>",
+	"Show the output of a background job (default: the latest)":                                            "Muestra la salida de una tarea en segundo plano (por defecto, la última)",
+	"List databases with table counts and sizes":                                                           "Lista las bases de datos con su número de tablas y tamaño",
+	"List tables, optionally filtered (e.g. \\dt film*, \\dt sakila.*)":                                    "Lista las tablas, con filtro opcional (p. ej. \\dt film*, \\dt sakila.*)",
+	"Describe a table: columns, indexes and foreign keys":                                                  "Describe una tabla: columnas, índices y claves foráneas",
+	"Browse databases, tables and columns; Enter inserts the selected name":                                "Recorre bases de datos, tablas y columnas; Intro inserta el nombre elegido",
+	"Open the last result, or a kept one, in the scrollable viewer (sort, search)":                         "Abre el último resultado, o uno guardado, en el visor (ordenar, buscar)",
+	"List the recent results kept in memory (buf1, buf2, ... and bookmarks)":                               "Lista los resultados recientes guardados en memoria (buf1, buf2, ... y marcadores)",
+	"Print a kept result again, or save it as .csv, .json or a table":                                      "Vuelve a imprimir un resultado guardado, o lo guarda como .csv, .json o tabla",
+	"Name a kept result so it is not dropped":                                                              "Da nombre a un resultado guardado para que no se descarte",
+	"Show the full value of one cell of the last result":                                                   "Muestra completo el valor de una celda del último resultado",
+	"Draw the replication tree below this server with lag and GTID gaps per replica":                       "Dibuja el árbol de replicación bajo este servidor con el retraso y los huecos GTID de cada réplica",
+	"Browse binary log events":                                                                             "Recorre los eventos del binary log",
+	"Estimate the rows from statistics or EXPLAIN, then optionally count exactly":                          "Estima las filas con estadísticas o EXPLAIN y, si se quiere, las cuenta exactamente",
+	"Edit the cells of the last result (a SELECT from one table with its primary key) and run the UPDATEs": "Edita las celdas del último resultado (un SELECT de una tabla con su clave primaria) y ejecuta los UPDATE",
+	"Show or set the format query results are printed in":                                                  "Muestra o cambia el formato en que se imprimen los resultados",
+	"Show or stop showing the server's warnings after every statement":                                     "Muestra o deja de mostrar los avisos del servidor tras cada sentencia",
+	"Show the query cost and rows read for rows returned after each SELECT":                                "Muestra el coste de la consulta y las filas leídas por fila devuelta tras cada SELECT",
+	"Show or hide the elapsed time while a statement runs":                                                 "Muestra u oculta el tiempo transcurrido mientras se ejecuta una sentencia",
+	"Page results taller than the terminal through command ($PAGER or less by default)":                    "Pagina con la orden los resultados más altos que el terminal ($PAGER o less por defecto)",
+	"Print results without the pager":                                                                      "Imprime los resultados sin paginador",
+	"Use another database. Takes database name as argument":                                                "Usa otra base de datos, cuyo nombre se pasa como argumento",
+	"Execute an SQL script file. Takes a file name as an argument. Supports zstd compressed files":         "Ejecuta un fichero de SQL, cuyo nombre se pasa como argumento. Admite ficheros comprimidos con zstd",
+	"Run a command with the system shell in this terminal, reporting a non-zero exit status":               "Ejecuta una orden con el shell del sistema en este terminal, avisando si termina con error",
+	"Toggle suggestions: \"on\" or \"off\"":                                                                "Activa o desactiva las sugerencias: \"on\" u \"off\"",
+	"Show or set how much schema completion loads":                                                         "Muestra o cambia cuánto esquema carga el autocompletado",
+	"Toggle AI EXPLAIN analysis: \"on\" or \"off\"":                                                        "Activa o desactiva el análisis de EXPLAIN con IA: \"on\" u \"off\"",
+	"Compare the plans of two queries and ask the AI which is preferable":                                  "Compara los planes de dos consultas y pregunta a la IA cuál es preferible",
+	"Analyze an EXPLAIN plan (JSON or TREE) pasted from elsewhere, ended with ;":                           "Analiza un plan de EXPLAIN (JSON o TREE) pegado de otro sitio, terminado en ;",
+	"Save the last EXPLAIN with its findings, AI analysis and table definitions as Markdown":               "Guarda el último EXPLAIN con sus hallazgos, el análisis con IA y las definiciones de tablas en Markdown",
+	"Capture every statement run, with timing, for go-mycli replay":                                        "Graba cada sentencia ejecutada, con su tiempo, para go-mycli replay",
+	"Save the session's statements by fingerprint for pt-query-digest review tables":                       "Guarda las sentencias de la sesión por huella para las tablas de revisión de pt-query-digest",
+	"Toggle JSON export for external tools: \"on\" or \"off\"":                                             "Activa o desactiva la exportación JSON para herramientas externas: \"on\" u \"off\"",
+	"Toggle built-in visual explain: \"on\" or \"off\"":                                                    "Activa o desactiva el explain visual integrado: \"on\" u \"off\"",
+	"Save the last EXPLAIN plan as a collapsible, cost-colored page or a graphviz image":                   "Guarda el último plan de EXPLAIN como página plegable coloreada por coste o como imagen de graphviz",
+	"List plugin commands from ~/.go-mycli/plugins":                                                        "Lista los comandos de plugins de ~/.go-mycli/plugins",
+	"List aliases or define one for this session; \\unalias <name> removes it":                             "Lista los alias o define uno para esta sesión; \\unalias <nombre> lo quita",
+	"List runbooks, or fill one in, preview its statements and run them":                                   "Lista los runbooks, o rellena uno, muestra sus sentencias y las ejecuta",
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"testing"
+)
+
+// The tests compare English output, whatever the locale they run in
+func init() {
+	setLanguage(languageEnglish)
+}
+
+func TestResolveLanguage(t *testing.T) {
+	tests := []struct {
+		setting string
+		env     map[string]string
+		want    string
+	}{
+		{"es", nil, languageSpanish},
+		{"EN", map[string]string{"LANG": "es_ES.UTF-8"}, languageEnglish},
+		{"auto", map[string]string{"LANG": "es_ES.UTF-8"}, languageSpanish},
+		{"", map[string]string{"LANG": "es-MX"}, languageSpanish},
+		{"auto", map[string]string{"LC_ALL": "C", "LANG": "es_ES"}, languageEnglish},
+		{"auto", map[string]string{"LC_MESSAGES": "es@euro", "LANG": "en_US"}, languageSpanish},
+		{"auto", map[string]string{"LANG": "fr_FR.UTF-8"}, languageEnglish},
+		{"auto", nil, languageEnglish},
+	}
+	for _, tt := range tests {
+		got := resolveLanguage(tt.setting, func(name string) string { return tt.env[name] })
+		if got != tt.want {
+			t.Errorf("resolveLanguage(%q, %v) = %s, want %s", tt.setting, tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	defer setLanguage(languageEnglish)
+	setLanguage(languageSpanish)
+	if got := tr("Bye"); got != "Adiós" {
+		t.Errorf("tr(Bye) in Spanish = %q", got)
+	}
+	if got := tr("a message without a translation"); got != "a message without a translation" {
+		t.Errorf("an untranslated message = %q, want the English", got)
+	}
+	setLanguage(languageEnglish)
+	if got := tr("Bye"); got != "Bye" {
+		t.Errorf("tr(Bye) in English = %q", got)
+	}
+}
+
+// formatVerbs are the fmt verbs of a message, numbered ones by position
+var formatVerbs = regexp.MustCompile(`%(\[\d+\])?[-+# 0]*\d*(\.\d+)?[a-zA-Z%]`)
+
+func TestCatalogFormats(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for msg, translated := range catalog {
+			want := verbKinds(msg)
+			if got := verbKinds(translated); got != want {
+				t.Errorf("%s: %q has verbs %s, the English %q has %s", lang, translated, got, msg, want)
+			}
+		}
+	}
+}
+
+// verbKinds lists the kinds of verbs in a format, ignoring their order and
+// repetition, so that numbered verbs compare equal to the English ones
+func verbKinds(format string) string {
+	seen := map[string]bool{}
+	for _, v := range formatVerbs.FindAllString(format, -1) {
+		seen[v[len(v)-1:]] = true
+	}
+	var kinds []string
+	for k := range seen {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return fmt.Sprint(kinds)
+}
+
+func TestHelpTranslated(t *testing.T) {
+	for _, c := range helpCommands {
+		if _, ok := spanishMessages[c.Description]; !ok {
+			t.Errorf("no Spanish for the help of %s: %q", c.Command, c.Description)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxIndexColumns caps the columns of an index derived from a condition
+const maxIndexColumns = 3
+
+var (
+	adviceCreateIndex = regexp.MustCompile("(?i)\\bCREATE\\s+(UNIQUE\\s+)?INDEX\\s+(`[^`]+`|[\\w$]+)\\s+ON\\s+((?:`[^`]+`|[\\w$]+)(?:\\.(?:`[^`]+`|[\\w$]+))?)\\s*(\\([^;]*?\\))")
+	adviceAddIndex    = regexp.MustCompile("(?i)\\bALTER\\s+TABLE\\s+((?:`[^`]+`|[\\w$]+)(?:\\.(?:`[^`]+`|[\\w$]+))?)\\s+(ADD\\s+(?:UNIQUE\\s+)?(?:INDEX|KEY)\\b[^;]*?\\))")
+	conditionEquality = regexp.MustCompile("`([^`]+)`\\.`([^`]+)`\\s*=\\s*[^=]")
+	conditionRange    = regexp.MustCompile("`([^`]+)`\\.`([^`]+)`\\s*(?:[<>]=?\\s*[^\\s<>=]|(?i:between|like)\\s)")
+)
+
+// indexRecommendation is an index the AI analysis or the plan calls for, as
+// the ALTER TABLE that \alter-safe runs
+type indexRecommendation struct {
+	Table     string
+	Statement string
+	Source    string // "the AI analysis" or "the plan"
+}
+
+// recommendIndexes collects the indexes to offer after an EXPLAIN: those the
+// advice spells out as CREATE INDEX or ALTER TABLE ... ADD INDEX, then one for
+// each table the plan scans in full under a condition. query resolves the
+// plan's aliases to tables.
+func recommendIndexes(advice, planJSON, query string) []indexRecommendation {
+	var recs []indexRecommendation
+	seen := make(map[string]bool)
+	add := func(rec indexRecommendation) {
+		key := strings.ToLower(strings.Join(strings.Fields(rec.Statement), " "))
+		if !seen[key] {
+			seen[key] = true
+			recs = append(recs, rec)
+		}
+	}
+
+	for _, m := range adviceCreateIndex.FindAllStringSubmatch(advice, -1) {
+		kind := "INDEX"
+		if m[1] != "" {
+			kind = "UNIQUE INDEX"
+		}
+		add(indexRecommendation{Table: m[3], Statement: fmt.Sprintf("ALTER TABLE %s ADD %s %s %s", m[3], kind, m[2], m[4]), Source: "the AI analysis"})
+	}
+	for _, m := range adviceAddIndex.FindAllStringSubmatch(advice, -1) {
+		add(indexRecommendation{Table: m[1], Statement: "ALTER TABLE " + m[1] + " " + m[2], Source: "the AI analysis"})
+	}
+
+	ctx := ParseSQLContext(query, len(query))
+	for _, scan := range fullScans(planJSON) {
+		table := ctx.ResolveAlias(scan.table)
+		cols := conditionColumns(scan.table, scan.condition)
+		if len(cols) == 0 {
+			continue
+		}
+		quoted := make([]string, len(cols))
+		for i, col := range cols {
+			quoted[i] = quoteIdentifier(col)
+		}
+		name := "idx_" + strings.Join(cols, "_")
+		if len(name) > 64 {
+			name = name[:64]
+		}
+		add(indexRecommendation{
+			Table:     table,
+			Statement: fmt.Sprintf("ALTER TABLE %s ADD INDEX %s (%s)", quoteIdentifier(table), quoteIdentifier(name), strings.Join(quoted, ", ")),
+			Source:    "the plan",
+		})
+	}
+	return recs
+}
+
+// scannedTable is a table access of a plan with the condition it filters on
+type scannedTable struct {
+	table     string // as the plan names it, which is the alias if there is one
+	access    string
+	condition string
+}
+
+// fullScans returns the tables a JSON plan reads in full with an attached
+// condition, in the order analyzePlanHeuristics reports them
+func fullScans(planJSON string) []scannedTable {
+	var scans []scannedTable
+	for _, scan := range planConditions(planJSON) {
+		if scan.access == "ALL" {
+			scans = append(scans, scan)
+		}
+	}
+	return scans
+}
+
+// planConditions returns the table accesses of a JSON plan that have an
+// attached condition
+func planConditions(planJSON string) []scannedTable {
+	var plan interface{}
+	if json.Unmarshal([]byte(planJSON), &plan) != nil {
+		return nil
+	}
+	var scans []scannedTable
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			name, _ := n["table_name"].(string)
+			access, _ := n["access_type"].(string)
+			cond, _ := n["attached_condition"].(string)
+			if name != "" && cond != "" {
+				scans = append(scans, scannedTable{table: name, access: access, condition: cond})
+			}
+			keys := make([]string, 0, len(n))
+			for k := range n {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(n[k])
+			}
+		case []interface{}:
+			for _, child := range n {
+				walk(child)
+			}
+		}
+	}
+	walk(plan)
+	return scans
+}
+
+// conditionColumns returns the columns of table an index should lead with
+// for an attached condition such as (`f`.`rating` = 'PG'): the columns
+// compared for equality, then one compared by range
+func conditionColumns(table, condition string) []string {
+	var cols []string
+	has := func(col string) bool {
+		for _, c := range cols {
+			if strings.EqualFold(c, col) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, m := range conditionEquality.FindAllStringSubmatch(condition, -1) {
+		if m[1] == table && !has(m[2]) && len(cols) < maxIndexColumns {
+			cols = append(cols, m[2])
+		}
+	}
+	for _, m := range conditionRange.FindAllStringSubmatch(condition, -1) {
+		if m[1] == table && !has(m[2]) && len(cols) < maxIndexColumns {
+			cols = append(cols, m[2])
+			break
+		}
+	}
+	return cols
+}
+
+// offerIndexes follows an EXPLAIN's AI analysis: for each index it calls
+// for it asks whether to create it, leaves the checks and the run to
+// \alter-safe and then compares the plan before and after
+func (p *PromptExecutor) offerIndexes(explainStmt, planJSON, advice string) {
+	if !p.canPrompt() {
+		return
+	}
+	query, _, err := extractQueryFromExplain(explainStmt)
+	if err != nil {
+		return
+	}
+	for _, rec := range recommendIndexes(advice, planJSON, query) {
+		fmt.Printf("\nIndex recommended by %s:\n  %s;\n", rec.Source, rec.Statement)
+		if !askYesNo("Check it with \\alter-safe? [y/N] ") {
+			continue
+		}
+		if !p.alterSafe(rec.Statement) {
+			continue
+		}
+		after, err := p.queryJSONPlan(query)
+		if err != nil {
+			fmt.Printf("EXPLAIN after the change failed: %v\n", err)
+			return
+		}
+		fmt.Print(formatPlanDelta(planJSON, after))
+		planJSON = after
+	}
+}
+
+// canPrompt reports whether the session can stop to ask the user a question
+func (p *PromptExecutor) canPrompt() bool {
+	return p.input != nil && !p.sourceFileMode && !p.nonInteractive
+}
+
+// formatPlanDelta compares the cost and score of a plan before and after
+// a change
+func formatPlanDelta(before, after string) string {
+	a, errA := overviewPlan(before)
+	b, errB := overviewPlan(after)
+	if errA != nil || errB != nil {
+		return ""
+	}
+	delta := ""
+	if a.Cost > 0 {
+		delta = fmt.Sprintf(" (%+.1f%%)", (b.Cost-a.Cost)/a.Cost*100)
+	}
+	return fmt.Sprintf("Cost: %.2f -> %.2f%s\nRows examined: %.0f -> %.0f\nScore: %d (%s) -> %d (%s)\n",
+		a.Cost, b.Cost, delta, a.Rows, b.Rows, a.Score, scoreVerdict(a.Score), b.Score, scoreVerdict(b.Score))
+}
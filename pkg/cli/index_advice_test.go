@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecommendIndexes(t *testing.T) {
+	advice := "Create an index on rating:\n\n```sql\nCREATE INDEX idx_rating ON film (rating);\nALTER TABLE sakila.inventory ADD INDEX idx_store (store_id, film_id);\n```"
+	plan := `{"query_block": {"nested_loop": [
+		{"table": {"table_name": "f", "access_type": "ALL", "attached_condition": "((` + "`f`.`rating` = 'PG') and (`f`.`length` > 90) and (`f`.`language_id` = 1)" + `)"}},
+		{"table": {"table_name": "i", "access_type": "ref", "key": "idx_fk_film_id"}}
+	]}}`
+	query := "SELECT * FROM film f JOIN inventory i USING (film_id) WHERE f.rating = 'PG' AND f.length > 90 AND f.language_id = 1"
+
+	want := []indexRecommendation{
+		{Table: "film", Statement: "ALTER TABLE film ADD INDEX idx_rating (rating)", Source: "the AI analysis"},
+		{Table: "sakila.inventory", Statement: "ALTER TABLE sakila.inventory ADD INDEX idx_store (store_id, film_id)", Source: "the AI analysis"},
+		{Table: "film", Statement: "ALTER TABLE film ADD INDEX idx_rating_language_id_length (rating, language_id, length)", Source: "the plan"},
+	}
+	got := recommendIndexes(advice, plan, query)
+	if len(got) != len(want) {
+		t.Fatalf("got %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("recommendation %d: got %+v, expected %+v", i, got[i], want[i])
+		}
+	}
+
+	// The same index named twice is offered once, and tables read through an
+	// index call for none
+	got = recommendIndexes("CREATE INDEX idx_a ON t (a); create index idx_a on t (a);", `{"query_block": {"table": {"table_name": "t", "access_type": "ref"}}}`, "SELECT * FROM t")
+	if len(got) != 1 {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestConditionColumns(t *testing.T) {
+	tests := []struct {
+		condition string
+		want      string
+	}{
+		{"(`f`.`rating` = 'PG')", "rating"},
+		{"((`f`.`length` between 60 and 90) and (`f`.`rating` = 'PG'))", "rating,length"},
+		{"((`f`.`length` >= 60) and (`f`.`rental_rate` < 3))", "length"},
+		{"(`f`.`film_id` = `i`.`film_id`)", "film_id"},
+		{"(`i`.`store_id` = 1)", ""},
+		{"(`f`.`title` <> 'ACE')", ""},
+	}
+	for _, test := range tests {
+		if got := strings.Join(conditionColumns("f", test.condition), ","); got != test.want {
+			t.Errorf("%s: got %q, expected %q", test.condition, got, test.want)
+		}
+	}
+}
+
+func TestFormatPlanDelta(t *testing.T) {
+	before := `{"query_block": {"cost_info": {"query_cost": "200.00"}, "table": {"table_name": "film", "access_type": "ALL", "rows_examined_per_scan": 1000}}}`
+	after := `{"query_block": {"cost_info": {"query_cost": "50.00"}, "table": {"table_name": "film", "access_type": "ref", "key": "idx_rating", "rows_examined_per_scan": 194}}}`
+	got := formatPlanDelta(before, after)
+	want := "Cost: 200.00 -> 50.00 (-75.0%)\nRows examined: 1000 -> 194\nScore: 30 (warn) -> 0 (ok)\n"
+	if got != want {
+		t.Errorf("got %q, expected %q", got, want)
+	}
+}
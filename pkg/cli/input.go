@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/c-bata/go-prompt"
+)
+
+// Keys used to replace the input line. keyClearLine is no sequence a terminal
+// sends: the line editor binds it to empty the line without putting the text
+// in the kill ring, as Ctrl-U would (see line_editor.go).
+var (
+	keyClearLine  = []byte("\x1b[9999~")
+	keyIgnoreRead = []byte{0} // dropped by go-prompt's reader
+)
+
+// inputParser wraps go-prompt's terminal reader so commands can type text into
+// the next prompt line (e.g. \browse inserting the chosen identifier), so
+// Up/Down recall whole statements rather than go-prompt's per-line history,
+// and so pastes are inserted verbatim (see paste.go)
+type inputParser struct {
+	prompt.ConsoleParser
+
+	mu         sync.Mutex
+	pending    [][]byte
+	history    statementHistory
+	current    string // the line being edited, as last seen by the completer
+	completing bool   // a completion is selected, so Up/Down move through it
+
+	inPaste    bool   // between the bracketed paste markers
+	pasteBuf   []byte // paste received so far
+	paste      string // last paste, with its line breaks
+	pasteShown string // last paste as inserted into the one-line buffer
+	justPasted bool   // nothing has been typed since the paste
+}
+
+func newInputParser() *inputParser {
+	return &inputParser{ConsoleParser: prompt.NewStandardInputParser()}
+}
+
+// Inject queues text to appear in the input buffer as if it had been typed
+func (in *inputParser) Inject(text string) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.pending = append(in.pending, []byte(text))
+}
+
+// AddHistory records a complete statement for Up-arrow recall
+func (in *inputParser) AddHistory(statement string) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.history.Add(statement)
+}
+
+// History returns the recorded statements, oldest first
+func (in *inputParser) History() []string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return append([]string(nil), in.history.entries...)
+}
+
+// SetCurrentLine records the text being edited so it survives history browsing
+func (in *inputParser) SetCurrentLine(text string) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.current = text
+}
+
+// Read returns injected text before reading from the terminal
+func (in *inputParser) Read() ([]byte, error) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if len(in.pending) > 0 {
+		b := in.pending[0]
+		in.pending = in.pending[1:]
+		return b, nil
+	}
+
+	b, err := in.ConsoleParser.Read()
+	if err != nil || len(b) == 0 {
+		return b, err
+	}
+	if in.inPaste || bytes.Contains(b, pasteStart) {
+		return in.collectPaste(b), nil
+	}
+	return in.handleKey(b), nil
+}
+
+// handleKey intercepts history keys; everything else passes through.
+// Called with in.mu held.
+func (in *inputParser) handleKey(b []byte) []byte {
+	in.justPasted = false
+	key := prompt.GetKey(b)
+	switch key {
+	case prompt.Tab, prompt.BackTab:
+		in.completing = true
+		return b
+	case prompt.Up, prompt.ControlP:
+		if in.completing {
+			return b
+		}
+		if text, ok := in.history.Older(in.current); ok {
+			return in.replaceLine(text)
+		}
+		return keyIgnoreRead
+	case prompt.Down, prompt.ControlN:
+		if in.completing {
+			return b
+		}
+		if text, ok := in.history.Newer(in.current); ok {
+			return in.replaceLine(text)
+		}
+		return keyIgnoreRead
+	case prompt.Enter, prompt.ControlJ, prompt.ControlM, prompt.ControlC:
+		in.history.Reset()
+	}
+	in.completing = false
+	return b
+}
+
+// replaceLine returns the first key of a sequence that replaces the input
+// line with text, queueing the rest. Called with in.mu held.
+func (in *inputParser) replaceLine(text string) []byte {
+	if text != "" {
+		in.pending = append(in.pending, []byte(text))
+	}
+	in.current = text
+	return keyClearLine
+}
+
+// statementHistory is the Up/Down history of complete statements. Like
+// go-prompt's own history, edits made while browsing are kept until the next
+// statement is entered.
+type statementHistory struct {
+	entries  []string
+	tmp      []string
+	selected int
+}
+
+// Add appends a statement and returns to the newest (empty) entry
+func (h *statementHistory) Add(statement string) {
+	if statement == "" {
+		return
+	}
+	if n := len(h.entries); n == 0 || h.entries[n-1] != statement {
+		h.entries = append(h.entries, statement)
+	}
+	h.Reset()
+}
+
+// Reset discards edits and returns to the newest entry
+func (h *statementHistory) Reset() {
+	h.tmp = append(append([]string(nil), h.entries...), "")
+	h.selected = len(h.tmp) - 1
+}
+
+// Older saves current and returns the previous statement
+func (h *statementHistory) Older(current string) (string, bool) {
+	if h.tmp == nil {
+		h.Reset()
+	}
+	if h.selected == 0 {
+		return "", false
+	}
+	h.tmp[h.selected] = current
+	h.selected--
+	return h.tmp[h.selected], true
+}
+
+// Newer saves current and returns the next statement
+func (h *statementHistory) Newer(current string) (string, bool) {
+	if h.tmp == nil {
+		h.Reset()
+	}
+	if h.selected >= len(h.tmp)-1 {
+		return "", false
+	}
+	h.tmp[h.selected] = current
+	h.selected++
+	return h.tmp[h.selected], true
+}
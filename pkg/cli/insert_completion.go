@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+)
+
+// columnDetail is what DESCRIBE says about a column
+type columnDetail struct {
+	Type     string
+	Nullable bool
+	Default  sql.NullString
+	Extra    string // auto_increment, DEFAULT_GENERATED, ...
+}
+
+// describe returns the type of the column as a suggestion shows it
+func (d columnDetail) describe() string {
+	desc := d.Type
+	if d.Extra != "" {
+		desc += " " + d.Extra
+	}
+	return desc
+}
+
+// generated reports whether the column is a generated column, whose value
+// cannot be given. DEFAULT_GENERATED marks a default expression instead.
+func (d columnDetail) generated() bool {
+	extra := strings.ToUpper(d.Extra)
+	return strings.Contains(extra, "VIRTUAL GENERATED") || strings.Contains(extra, "STORED GENERATED") || strings.Contains(extra, "PERSISTENT GENERATED")
+}
+
+// tableColumns returns the cached name, columns and column details of a
+// table. Unless the server has case-sensitive table names
+// (lower_case_table_names=0), the name matches in any case.
+func (p *PromptExecutor) tableColumns(table string) (string, []string, map[string]columnDetail) {
+	if cols, ok := p.columns[table]; ok {
+		return table, cols, p.columnDetails[table]
+	}
+	if p.serverInfo().CaseSensitiveTables {
+		return table, nil, nil
+	}
+	for name, cols := range p.columns {
+		if strings.EqualFold(name, table) {
+			return name, cols, p.columnDetails[name]
+		}
+	}
+	return table, nil, nil
+}
+
+// getInsertColumnSuggestions returns the columns of the INSERT's table that
+// its column list does not have yet, in table order. Generated columns are
+// left out.
+func (p *PromptExecutor) getInsertColumnSuggestions(ctx *SQLParseResult) []prompt.Suggest {
+	_, cols, details := p.tableColumns(ctx.InsertTable)
+	var suggestions []prompt.Suggest
+	for _, col := range cols {
+		if details[col].generated() || slices.ContainsFunc(ctx.InsertColumns, func(c string) bool { return strings.EqualFold(c, col) }) {
+			continue
+		}
+		suggestions = append(suggestions, prompt.Suggest{Text: quoteIdentifier(col), Description: details[col].describe()})
+	}
+	return suggestions
+}
+
+// getInsertValueSuggestions returns the values that fit the column at the
+// cursor's position in a VALUES tuple. Each one is described with the column,
+// its type and the position, so the popup says what the value is for.
+func (p *PromptExecutor) getInsertValueSuggestions(ctx *SQLParseResult) []prompt.Suggest {
+	_, cols, details := p.tableColumns(ctx.InsertTable)
+	if len(ctx.InsertColumns) > 0 {
+		cols = ctx.InsertColumns
+	}
+	if ctx.ValueIndex >= len(cols) {
+		return nil
+	}
+	col := cols[ctx.ValueIndex]
+	detail := details[col]
+	for name, d := range details {
+		if strings.EqualFold(name, col) {
+			detail = d
+		}
+	}
+	hint := fmt.Sprintf("%s %s (%d/%d)", col, detail.Type, ctx.ValueIndex+1, len(cols))
+
+	var values []string
+	typ := strings.ToLower(detail.Type)
+	switch {
+	case detail.generated():
+		// Only DEFAULT is accepted
+		return []prompt.Suggest{{Text: "DEFAULT", Description: hint}}
+	case strings.HasPrefix(typ, "enum("), strings.HasPrefix(typ, "set("):
+		values = enumValues.FindAllString(detail.Type, -1)
+	case strings.HasPrefix(typ, "datetime"), strings.HasPrefix(typ, "timestamp"):
+		values = append(values, "NOW()", "CURRENT_TIMESTAMP")
+	case typ == "date":
+		values = append(values, "CURDATE()")
+	case strings.HasPrefix(typ, "time"):
+		values = append(values, "CURTIME()")
+	}
+	if detail.Nullable {
+		values = append(values, "NULL")
+	}
+	values = append(values, "DEFAULT")
+
+	suggestions := make([]prompt.Suggest, 0, len(values))
+	for _, v := range values {
+		suggestions = append(suggestions, prompt.Suggest{Text: v, Description: hint})
+	}
+	return suggestions
+}
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Background job states
+const (
+	jobRunning = "running"
+	jobDone    = "done"
+	jobFailed  = "failed"
+)
+
+// backgroundJob is a statement started with \bg
+type backgroundJob struct {
+	ID           int
+	Query        string
+	ConnectionID int64
+	Started      time.Time
+	Finished     time.Time
+	State        string
+	Output       string
+	reported     bool // the prompt has announced that it finished
+}
+
+// jobTable holds the background jobs of a session. Jobs finish on their own
+// goroutines, so every access goes through mu.
+type jobTable struct {
+	mu   sync.Mutex
+	jobs []*backgroundJob
+}
+
+// startBackground handles \bg <query>: it runs the statement on a connection
+// of its own and returns to the prompt at once
+func (p *PromptExecutor) startBackground(query string) {
+	query = strings.TrimSpace(query)
+	vertical := strings.HasSuffix(query, "\\G")
+	query = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(query, "\\G"), ";"))
+	if query == "" {
+		fmt.Println("Usage: \\bg <statement>")
+		return
+	}
+
+	// Jobs are canceled when the session shuts down
+	ctx := p.sessionContext()
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return
+	}
+	if p.database != "" {
+		if _, err := conn.ExecContext(ctx, "USE "+quoteIdentifier(p.database)); err != nil {
+			conn.Close()
+			fmt.Printf(tr("Error: %v\n"), err)
+			return
+		}
+	}
+	job := &backgroundJob{Query: query, Started: time.Now(), State: jobRunning}
+	_ = conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&job.ConnectionID)
+
+	p.jobs.mu.Lock()
+	job.ID = len(p.jobs.jobs) + 1
+	p.jobs.jobs = append(p.jobs.jobs, job)
+	p.jobs.mu.Unlock()
+
+	go func() {
+		defer conn.Close()
+		res, err := runStatementOn(ctx, conn, query)
+		output := ""
+		if err != nil {
+			output = fmt.Sprintf(tr("Error: %v\n"), canceled(ctx, err))
+		} else {
+			output = p.resultOutput(res, vertical)
+		}
+		p.jobs.mu.Lock()
+		defer p.jobs.mu.Unlock()
+		job.Output = output
+		job.Finished = time.Now()
+		job.State = jobDone
+		if err != nil {
+			job.State = jobFailed
+		}
+	}()
+	fmt.Printf("[%d] started on connection %d: %s\n", job.ID, job.ConnectionID, truncateQuery(query, 60))
+}
+
+// reportFinishedJobs announces jobs that finished since the last prompt, the
+// way a shell reports finished background jobs
+func (p *PromptExecutor) reportFinishedJobs() {
+	p.jobs.mu.Lock()
+	defer p.jobs.mu.Unlock()
+	for _, job := range p.jobs.jobs {
+		if job.State != jobRunning && !job.reported {
+			job.reported = true
+			fmt.Printf("[%d] %s after %s: %s (\\result %d)\n", job.ID, job.State,
+				job.Finished.Sub(job.Started).Round(time.Millisecond), truncateQuery(job.Query, 60), job.ID)
+		}
+	}
+}
+
+// listJobs handles \jobs
+func (p *PromptExecutor) listJobs() {
+	p.jobs.mu.Lock()
+	defer p.jobs.mu.Unlock()
+	if len(p.jobs.jobs) == 0 {
+		fmt.Println("No background jobs")
+		return
+	}
+	rows := make([][]string, 0, len(p.jobs.jobs))
+	for _, job := range p.jobs.jobs {
+		rows = append(rows, []string{
+			strconv.Itoa(job.ID),
+			job.State,
+			job.elapsed().Round(time.Second).String(),
+			strconv.FormatInt(job.ConnectionID, 10),
+			truncateQuery(job.Query, 60),
+		})
+	}
+	fmt.Print(formatMySQLTable([]string{"Id", "State", "Time", "Connection", "Query"}, rows))
+}
+
+// showJobResult handles \result [id], the latest job when no id is given
+func (p *PromptExecutor) showJobResult(args string) {
+	p.jobs.mu.Lock()
+	defer p.jobs.mu.Unlock()
+	if len(p.jobs.jobs) == 0 {
+		fmt.Println("No background jobs")
+		return
+	}
+	job := p.jobs.jobs[len(p.jobs.jobs)-1]
+	if args = strings.TrimSpace(args); args != "" {
+		id, err := strconv.Atoi(strings.TrimPrefix(args, "%"))
+		if err != nil || id < 1 || id > len(p.jobs.jobs) {
+			fmt.Printf("No such job: %s (see \\jobs)\n", args)
+			return
+		}
+		job = p.jobs.jobs[id-1]
+	}
+	if job.State == jobRunning {
+		fmt.Printf("[%d] still running after %s on connection %d\n", job.ID, job.elapsed().Round(time.Second), job.ConnectionID)
+		return
+	}
+	job.reported = true
+	fmt.Printf("[%d] %s\n", job.ID, job.Query)
+	fmt.Print(job.Output)
+}
+
+// runningJobs counts the jobs that have not finished
+func (p *PromptExecutor) runningJobs() int {
+	p.jobs.mu.Lock()
+	defer p.jobs.mu.Unlock()
+	n := 0
+	for _, job := range p.jobs.jobs {
+		if job.State == jobRunning {
+			n++
+		}
+	}
+	return n
+}
+
+// warnRunningJobs is called on exit: closing their connections does not
+// necessarily stop statements the server is already executing
+func (p *PromptExecutor) warnRunningJobs() {
+	if n := p.runningJobs(); n > 0 {
+		fmt.Printf("Warning: %d background job%s still running; the server may finish or roll back the statement%s after the connection closes\n", n, plural(n), plural(n))
+	}
+}
+
+func (j *backgroundJob) elapsed() time.Duration {
+	if j.State == jobRunning {
+		return time.Since(j.Started)
+	}
+	return j.Finished.Sub(j.Started)
+}
+
+// truncateQuery shortens a statement to one line of at most n characters
+func truncateQuery(query string, n int) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len([]rune(query)) <= n {
+		return query
+	}
+	return string([]rune(query)[:n-3]) + "..."
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTruncateQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		n     int
+		want  string
+	}{
+		{"SELECT 1", 20, "SELECT 1"},
+		{"ALTER TABLE t\n  ADD COLUMN c INT", 40, "ALTER TABLE t ADD COLUMN c INT"},
+		{"ALTER TABLE orders ADD INDEX idx_created (created_at)", 20, "ALTER TABLE order..."},
+	}
+	for _, tt := range tests {
+		if got := truncateQuery(tt.query, tt.n); got != tt.want {
+			t.Errorf("truncateQuery(%q, %d) = %q, want %q", tt.query, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestRunningJobs(t *testing.T) {
+	start := time.Now().Add(-time.Minute)
+	p := &PromptExecutor{}
+	p.jobs.jobs = []*backgroundJob{
+		{ID: 1, State: jobDone, Started: start, Finished: start.Add(3 * time.Second)},
+		{ID: 2, State: jobRunning, Started: start},
+		{ID: 3, State: jobFailed, Started: start, Finished: start.Add(time.Second)},
+	}
+	if got := p.runningJobs(); got != 1 {
+		t.Errorf("runningJobs() = %d, want 1", got)
+	}
+	if got := p.jobs.jobs[0].elapsed(); got != 3*time.Second {
+		t.Errorf("elapsed() of a finished job = %s, want 3s", got)
+	}
+	if got := p.jobs.jobs[1].elapsed(); got < time.Minute {
+		t.Errorf("elapsed() of a running job = %s, want at least 1m", got)
+	}
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+)
+
+// foreignKey is a foreign key of the current database, with the columns
+// of a composite key in order
+type foreignKey struct {
+	Name       string
+	Table      string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+}
+
+// foreignKeys returns the foreign keys between tables of the current
+// database, reading them from information_schema the first time and keeping
+// them until the connection changes. A failed query is not retried.
+func (p *PromptExecutor) foreignKeys() []foreignKey {
+	if keys, ok := p.foreignKeyCache[p.database]; ok {
+		return keys
+	}
+	if p.db == nil {
+		return nil
+	}
+	_, rows, _ := p.queryStrings(`SELECT CONSTRAINT_NAME, TABLE_NAME, COLUMN_NAME, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME
+		FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = DATABASE() AND REFERENCED_TABLE_SCHEMA = TABLE_SCHEMA AND REFERENCED_TABLE_NAME IS NOT NULL
+		ORDER BY TABLE_NAME, CONSTRAINT_NAME, ORDINAL_POSITION`)
+	var keys []foreignKey
+	for _, row := range rows {
+		if len(row) < 5 {
+			continue
+		}
+		if n := len(keys); n > 0 && keys[n-1].Table == row[1] && keys[n-1].Name == row[0] {
+			keys[n-1].Columns = append(keys[n-1].Columns, row[2])
+			keys[n-1].RefColumns = append(keys[n-1].RefColumns, row[4])
+			continue
+		}
+		keys = append(keys, foreignKey{Name: row[0], Table: row[1], Columns: []string{row[2]}, RefTable: row[3], RefColumns: []string{row[4]}})
+	}
+	if p.foreignKeyCache == nil {
+		p.foreignKeyCache = make(map[string][]foreignKey)
+	}
+	p.foreignKeyCache[p.database] = keys
+	return keys
+}
+
+// getJoinPredicateSuggestions returns the complete conditions that join the
+// table of JOIN ... ON to the tables before it along their foreign keys,
+// such as o.customer_id = c.id
+func (p *PromptExecutor) getJoinPredicateSuggestions(ctx *SQLParseResult) []prompt.Suggest {
+	if ctx.JoinTable == "" {
+		return nil
+	}
+	joined := ctx.JoinTable
+	if ctx.JoinAlias != "" {
+		joined = ctx.JoinAlias
+	}
+	var suggestions []prompt.Suggest
+	for _, table := range ctx.Tables[:lastIndex(ctx.Tables, ctx.JoinTable)] {
+		other := table
+		for _, alias := range ctx.Aliases {
+			if alias.TableName == table {
+				other = alias.Alias
+				break
+			}
+		}
+		for _, fk := range p.foreignKeys() {
+			// A self-referencing key joins either way, as with a manager_id
+			// that goes up or down the hierarchy
+			if p.sameTable(fk.Table, ctx.JoinTable) && p.sameTable(fk.RefTable, table) {
+				suggestions = append(suggestions, joinPredicate(fk, joined, fk.Columns, other, fk.RefColumns))
+			}
+			if p.sameTable(fk.Table, table) && p.sameTable(fk.RefTable, ctx.JoinTable) {
+				suggestions = append(suggestions, joinPredicate(fk, joined, fk.RefColumns, other, fk.Columns))
+			}
+		}
+	}
+	return suggestions
+}
+
+// joinPredicate returns the condition a.x = b.x AND a.y = b.y pairing the
+// columns of a with those of b, with the names quoted where needed
+func joinPredicate(fk foreignKey, a string, aColumns []string, b string, bColumns []string) prompt.Suggest {
+	conds := make([]string, len(aColumns))
+	for i := range aColumns {
+		conds[i] = quoteIdentifier(a) + "." + quoteIdentifier(aColumns[i]) + " = " + quoteIdentifier(b) + "." + quoteIdentifier(bColumns[i])
+	}
+	return prompt.Suggest{Text: strings.Join(conds, " AND "), Description: fmt.Sprintf("Foreign key %s", fk.Name)}
+}
+
+// sameTable reports whether a name from information_schema is the table a
+// query names, which is case-insensitive unless lower_case_table_names=0
+func (p *PromptExecutor) sameTable(name, table string) bool {
+	if p.serverInfo().CaseSensitiveTables {
+		return name == table
+	}
+	return strings.EqualFold(name, table)
+}
+
+// lastIndex returns the index of the last s in list, or 0
+func lastIndex(list []string, s string) int {
+	for i := len(list) - 1; i >= 0; i-- {
+		if list[i] == s {
+			return i
+		}
+	}
+	return 0
+}
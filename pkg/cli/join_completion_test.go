@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c-bata/go-prompt"
+)
+
+func TestJoinPredicateSuggestions(t *testing.T) {
+	p := &PromptExecutor{
+		tables: []string{"customers", "orders", "order_items", "employees"},
+		columns: map[string][]string{
+			"customers":   {"id", "name"},
+			"orders":      {"id", "customer_id"},
+			"order_items": {"order_id", "line", "sku"},
+			"employees":   {"id", "manager_id"},
+			"shipments":   {"order_id", "line"},
+		},
+		foreignKeyCache: map[string][]foreignKey{"": {
+			{Name: "fk_orders_customer", Table: "orders", Columns: []string{"customer_id"}, RefTable: "customers", RefColumns: []string{"id"}},
+			{Name: "fk_items_order", Table: "order_items", Columns: []string{"order_id"}, RefTable: "orders", RefColumns: []string{"id"}},
+			{Name: "fk_shipment_item", Table: "shipments", Columns: []string{"order_id", "line"}, RefTable: "order_items", RefColumns: []string{"order_id", "line"}},
+			{Name: "fk_manager", Table: "employees", Columns: []string{"manager_id"}, RefTable: "employees", RefColumns: []string{"id"}},
+		}},
+		cacheTime:      time.Now(),
+		completionMode: completionFull,
+	}
+	predicates := func(line string) []string {
+		buf := prompt.NewBuffer()
+		buf.InsertText(line, false, true)
+		var got []string
+		for _, s := range p.Completer(*buf.Document()) {
+			if strings.HasPrefix(s.Description, "Foreign key") {
+				got = append(got, s.Text)
+			}
+		}
+		return got
+	}
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"SELECT * FROM customers c JOIN orders o ON ", []string{"o.customer_id = c.id"}},
+		{"SELECT * FROM orders JOIN customers ON ", []string{"customers.id = orders.customer_id"}},
+		{"SELECT * FROM customers c JOIN orders o ON o.customer_id = c.id JOIN order_items i ON ", []string{"i.order_id = o.id"}},
+		{"SELECT * FROM employees e JOIN employees m ON ", []string{"m.manager_id = e.id", "m.id = e.manager_id"}},
+		{"SELECT * FROM order_items i JOIN shipments s ON ", []string{"s.order_id = i.order_id AND s.line = i.line"}},
+		{"SELECT * FROM customers JOIN order_items ON ", nil},
+	}
+	for _, tt := range tests {
+		if got := predicates(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
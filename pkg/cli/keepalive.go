@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultKeepalive is how often an idle interactive session pings the server
+// so NAT gateways and firewalls do not drop the connection
+const defaultKeepalive = time.Minute
+
+// idleCheckAfter is the idle time after which the connection is verified
+// before running a statement
+const idleCheckAfter = 30 * time.Second
+
+// pingTimeout bounds keepalive and idle check pings
+const pingTimeout = 5 * time.Second
+
+// keepalive holds the state shared with the background ping goroutine. The
+// connection is only replaced on the prompt goroutine, under mu.
+type keepalive struct {
+	mu           sync.Mutex
+	lastActivity atomic.Int64 // UnixNano of the last statement or ping
+	stop         chan struct{}
+}
+
+// touch records activity on the connection
+func (k *keepalive) touch() {
+	k.lastActivity.Store(time.Now().UnixNano())
+}
+
+// idle returns how long the connection has not been used
+func (k *keepalive) idle() time.Duration {
+	last := k.lastActivity.Load()
+	if last == 0 {
+		return 0
+	}
+	return time.Since(time.Unix(0, last))
+}
+
+// startKeepalive pings the server every interval while the session is idle
+func (p *PromptExecutor) startKeepalive(interval time.Duration) {
+	p.alive.touch()
+	if interval <= 0 {
+		return
+	}
+	p.alive.stop = make(chan struct{})
+	go func(stop <-chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if p.alive.idle() < interval {
+					continue
+				}
+				p.alive.mu.Lock()
+				db := p.db
+				p.alive.mu.Unlock()
+				if pingDB(db) == nil {
+					p.alive.touch()
+				}
+			}
+		}
+	}(p.alive.stop)
+}
+
+// stopKeepalive ends the background pings
+func (p *PromptExecutor) stopKeepalive() {
+	if p.alive.stop != nil {
+		close(p.alive.stop)
+		p.alive.stop = nil
+	}
+}
+
+// setDB replaces the connection, keeping the keepalive goroutine in step
+func (p *PromptExecutor) setDB(db *sql.DB) {
+	p.alive.mu.Lock()
+	p.db = db
+	p.alive.mu.Unlock()
+	p.alive.touch()
+}
+
+// ensureConnection verifies a connection that has been idle for a while and
+// reconnects if the server or something in between dropped it, so the first
+// statement after a break does not fail with "invalid connection"
+func (p *PromptExecutor) ensureConnection() {
+	if p.db == nil || p.alive.idle() < idleCheckAfter {
+		p.alive.touch()
+		return
+	}
+	if err := pingDB(p.db); err != nil {
+		fmt.Printf("Connection lost after %s idle (%v); reconnecting\n", p.alive.idle().Round(time.Second), err)
+		p.reconnect()
+	}
+	p.alive.touch()
+}
+
+// keepaliveName describes the keepalive setting for \config
+func keepaliveName(interval time.Duration) string {
+	if interval <= 0 {
+		return "off"
+	}
+	return "every " + interval.String()
+}
+
+func pingDB(db *sql.DB) error {
+	if db == nil {
+		return sql.ErrConnDone
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	return db.PingContext(ctx)
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeepaliveIdle(t *testing.T) {
+	var k keepalive
+	if k.idle() != 0 {
+		t.Errorf("idle before any activity = %s, want 0", k.idle())
+	}
+	k.lastActivity.Store(time.Now().Add(-time.Minute).UnixNano())
+	if k.idle() < time.Minute {
+		t.Errorf("idle = %s, want at least 1m", k.idle())
+	}
+	k.touch()
+	if k.idle() >= time.Second {
+		t.Errorf("idle after touch = %s", k.idle())
+	}
+}
+
+func TestKeepaliveConfig(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), ".go-myclirc")
+	t.Setenv("GO_MYCLI_RC", rc)
+
+	if cfg := LoadSyntaxConfig(); cfg.Keepalive != defaultKeepalive {
+		t.Errorf("default keepalive = %s, want %s", cfg.Keepalive, defaultKeepalive)
+	}
+	if err := os.WriteFile(rc, []byte("[main]\nkeepalive = 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := LoadSyntaxConfig()
+	if cfg.Keepalive != 0 || keepaliveName(cfg.Keepalive) != "off" {
+		t.Errorf("keepalive = 0 gave %s (%s)", cfg.Keepalive, keepaliveName(cfg.Keepalive))
+	}
+}
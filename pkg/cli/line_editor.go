@@ -0,0 +1,341 @@
+package cli
+
+import (
+	"unicode"
+
+	"github.com/c-bata/go-prompt"
+)
+
+// killRingSize is how many cut texts Alt-y can cycle through
+const killRingSize = 16
+
+// undoLimit is how many edits of the input line Ctrl-_ can undo
+const undoLimit = 200
+
+// editAction is the kind of the last editing command, so consecutive kills
+// add to one kill ring entry and Alt-y only follows a yank
+type editAction int
+
+const (
+	editOther editAction = iota
+	editKill
+	editYank
+)
+
+// lineEditor provides readline-style editing keys for the prompt. go-prompt's
+// emacs bindings have no word movement and throw away what Ctrl-W, Ctrl-K and
+// Ctrl-U cut, so the prompt uses go-prompt's common bindings plus these.
+type lineEditor struct {
+	ring       []string // cut text, most recent last
+	yanked     int      // ring index of the text last yanked
+	yankLen    int      // runes inserted by the last yank
+	last       editAction
+	lastText   string // the buffer as the last kill or yank left it
+	lastCursor int
+	undo       []editState // input line states Ctrl-_ goes back to, oldest first
+	redo       []editState // states undone, for Alt-_
+	seen       editState   // the input line as the last key binding left it
+	clear      func()      // clears the screen for Ctrl-L
+}
+
+// editState is the input line and the cursor position in runes
+type editState struct {
+	text   string
+	cursor int
+}
+
+// newLineEditor returns a line editor drawing on w
+func newLineEditor(w prompt.ConsoleWriter) *lineEditor {
+	return &lineEditor{clear: func() {
+		w.EraseScreen()
+		w.CursorGoTo(0, 0)
+		_ = w.Flush()
+	}}
+}
+
+// promptOptions returns the go-prompt options installing the editing keys
+func (e *lineEditor) promptOptions() []prompt.Option {
+	return []prompt.Option{
+		prompt.OptionSwitchKeyBindMode(prompt.CommonKeyBind),
+		prompt.OptionAddKeyBind(e.keyBindings()...),
+		prompt.OptionAddASCIICodeBind(e.asciiCodeBindings()...),
+	}
+}
+
+func (e *lineEditor) keyBindings() []prompt.KeyBind {
+	bindings := []prompt.KeyBind{
+		{Key: prompt.ControlA, Fn: e.move(func([]rune, int) int { return 0 })},
+		{Key: prompt.ControlE, Fn: e.move(func(text []rune, _ int) int { return len(text) })},
+		{Key: prompt.ControlB, Fn: e.move(func(_ []rune, pos int) int { return max(pos-1, 0) })},
+		{Key: prompt.ControlF, Fn: e.move(func(text []rune, pos int) int { return min(pos+1, len(text)) })},
+		{Key: prompt.ControlLeft, Fn: e.move(wordBackward)},
+		{Key: prompt.ControlRight, Fn: e.move(wordForward)},
+		{Key: prompt.ControlD, Fn: e.edit(func(buf *prompt.Buffer) {
+			if text, pos := bufferRunes(buf); pos < len(text) {
+				buf.CursorRight(1)
+				buf.DeleteBeforeCursor(1)
+			}
+		})},
+		{Key: prompt.ControlH, Fn: e.edit(func(buf *prompt.Buffer) { buf.DeleteBeforeCursor(1) })},
+		{Key: prompt.ControlK, Fn: e.killTo(func(text []rune, _ int) int { return len(text) })},
+		{Key: prompt.ControlU, Fn: e.killTo(func([]rune, int) int { return 0 })},
+		{Key: prompt.ControlW, Fn: e.killTo(spaceWordBackward)},
+		{Key: prompt.ControlY, Fn: e.yank},
+		{Key: prompt.ControlL, Fn: func(*prompt.Buffer) { e.clear() }},
+	}
+	for i := range bindings {
+		bindings[i].Fn = e.recorded(bindings[i].Fn)
+	}
+	// go-prompt replaces the buffer on Enter and Ctrl-C before running these
+	return append(bindings,
+		prompt.KeyBind{Key: prompt.ControlUnderscore, Fn: e.undoEdit},
+		prompt.KeyBind{Key: prompt.ControlCircumflex, Fn: e.redoEdit},
+		prompt.KeyBind{Key: prompt.Enter, Fn: e.reset},
+		prompt.KeyBind{Key: prompt.ControlJ, Fn: e.reset},
+		prompt.KeyBind{Key: prompt.ControlM, Fn: e.reset},
+		prompt.KeyBind{Key: prompt.ControlC, Fn: e.reset},
+	)
+}
+
+// asciiCodeBindings binds the Alt keys, which terminals send as Escape
+// followed by the key, and Alt-Left/Alt-Right
+func (e *lineEditor) asciiCodeBindings() []prompt.ASCIICodeBind {
+	bindings := []prompt.ASCIICodeBind{
+		{ASCIICode: []byte{0x1b, 'b'}, Fn: e.move(wordBackward)},
+		{ASCIICode: []byte{0x1b, 'f'}, Fn: e.move(wordForward)},
+		{ASCIICode: []byte("\x1b[1;3D"), Fn: e.move(wordBackward)},
+		{ASCIICode: []byte("\x1b[1;3C"), Fn: e.move(wordForward)},
+		{ASCIICode: []byte{0x1b, 'd'}, Fn: e.killTo(wordForward)},
+		{ASCIICode: []byte{0x1b, 0x7f}, Fn: e.killTo(wordBackward)},
+		{ASCIICode: []byte{0x1b, 0x08}, Fn: e.killTo(wordBackward)},
+		{ASCIICode: []byte{0x1b, 'y'}, Fn: e.yankPop},
+		{ASCIICode: keyClearLine, Fn: e.edit(clearLine)},
+	}
+	for i := range bindings {
+		bindings[i].Fn = e.recorded(bindings[i].Fn)
+	}
+	return append(bindings, prompt.ASCIICodeBind{ASCIICode: []byte{0x1b, '_'}, Fn: e.redoEdit})
+}
+
+// recorded wraps a binding so the edit it makes can be undone. Text typed
+// between bindings is not seen key by key, so it is undone as one edit.
+func (e *lineEditor) recorded(fn func(*prompt.Buffer)) func(*prompt.Buffer) {
+	return func(buf *prompt.Buffer) {
+		before := currentEditState(buf)
+		e.sync(before)
+		fn(buf)
+		e.seen = currentEditState(buf)
+		if e.seen.text != before.text {
+			e.pushUndo(before)
+			e.redo = nil
+		}
+	}
+}
+
+// sync records text typed since the last binding as an edit of its own
+func (e *lineEditor) sync(now editState) {
+	if now.text != e.seen.text {
+		e.pushUndo(e.seen)
+		e.redo = nil
+	}
+	e.seen = now
+}
+
+func (e *lineEditor) pushUndo(state editState) {
+	e.undo = append(e.undo, state)
+	if len(e.undo) > undoLimit {
+		e.undo = e.undo[1:]
+	}
+}
+
+// undoEdit puts the input line back as it was before the last edit
+func (e *lineEditor) undoEdit(buf *prompt.Buffer) {
+	now := currentEditState(buf)
+	e.sync(now)
+	if len(e.undo) == 0 {
+		return
+	}
+	target := e.undo[len(e.undo)-1]
+	e.undo = e.undo[:len(e.undo)-1]
+	e.redo = append(e.redo, now)
+	e.restore(buf, target)
+}
+
+// redoEdit makes the edit undone last again. Typing after an undo discards
+// what could be redone.
+func (e *lineEditor) redoEdit(buf *prompt.Buffer) {
+	now := currentEditState(buf)
+	e.sync(now)
+	if len(e.redo) == 0 {
+		return
+	}
+	target := e.redo[len(e.redo)-1]
+	e.redo = e.redo[:len(e.redo)-1]
+	e.pushUndo(now)
+	e.restore(buf, target)
+}
+
+// restore replaces the input line with state
+func (e *lineEditor) restore(buf *prompt.Buffer, state editState) {
+	clearLine(buf)
+	buf.InsertText(state.text, false, true)
+	buf.CursorLeft(len([]rune(state.text)) - state.cursor)
+	e.last = editOther
+	e.seen = state
+}
+
+// reset forgets the edits of a line that was entered or cancelled
+func (e *lineEditor) reset(*prompt.Buffer) {
+	e.undo, e.redo = nil, nil
+	e.seen = editState{}
+	e.last = editOther
+}
+
+// move returns a binding moving the cursor to the position target returns
+func (e *lineEditor) move(target func(text []rune, pos int) int) func(*prompt.Buffer) {
+	return func(buf *prompt.Buffer) {
+		e.last = editOther
+		text, pos := bufferRunes(buf)
+		if to := target(text, pos); to < pos {
+			buf.CursorLeft(pos - to)
+		} else {
+			buf.CursorRight(to - pos)
+		}
+	}
+}
+
+// edit returns a binding that changes the buffer without the kill ring
+func (e *lineEditor) edit(fn func(*prompt.Buffer)) func(*prompt.Buffer) {
+	return func(buf *prompt.Buffer) {
+		e.last = editOther
+		fn(buf)
+	}
+}
+
+// killTo returns a binding cutting the text between the cursor and the
+// position target returns into the kill ring
+func (e *lineEditor) killTo(target func(text []rune, pos int) int) func(*prompt.Buffer) {
+	return func(buf *prompt.Buffer) {
+		text, pos := bufferRunes(buf)
+		to := target(text, pos)
+		if to == pos {
+			return
+		}
+		appending := e.continues(buf, editKill) && len(e.ring) > 0
+		var cut string
+		if to > pos {
+			buf.CursorRight(to - pos)
+			cut = buf.DeleteBeforeCursor(to - pos)
+		} else {
+			cut = buf.DeleteBeforeCursor(pos - to)
+		}
+		switch {
+		case !appending:
+			e.ring = append(e.ring, cut)
+			if len(e.ring) > killRingSize {
+				e.ring = e.ring[1:]
+			}
+		case to > pos:
+			e.ring[len(e.ring)-1] += cut
+		default:
+			e.ring[len(e.ring)-1] = cut + e.ring[len(e.ring)-1]
+		}
+		e.done(buf, editKill)
+	}
+}
+
+// yank pastes the most recently cut text
+func (e *lineEditor) yank(buf *prompt.Buffer) {
+	if len(e.ring) == 0 {
+		return
+	}
+	e.yanked = len(e.ring) - 1
+	e.insertYank(buf)
+}
+
+// yankPop replaces the text just yanked with the one cut before it
+func (e *lineEditor) yankPop(buf *prompt.Buffer) {
+	if !e.continues(buf, editYank) || len(e.ring) < 2 {
+		return
+	}
+	buf.DeleteBeforeCursor(e.yankLen)
+	e.yanked = (e.yanked + len(e.ring) - 1) % len(e.ring)
+	e.insertYank(buf)
+}
+
+func (e *lineEditor) insertYank(buf *prompt.Buffer) {
+	text := e.ring[e.yanked]
+	buf.InsertText(text, false, true)
+	e.yankLen = len([]rune(text))
+	e.done(buf, editYank)
+}
+
+// continues reports whether the buffer is as the last command of the given
+// kind left it
+func (e *lineEditor) continues(buf *prompt.Buffer, action editAction) bool {
+	text, pos := bufferRunes(buf)
+	return e.last == action && string(text) == e.lastText && pos == e.lastCursor
+}
+
+func (e *lineEditor) done(buf *prompt.Buffer, action editAction) {
+	text, pos := bufferRunes(buf)
+	e.last, e.lastText, e.lastCursor = action, string(text), pos
+}
+
+// clearLine empties the input line, for history recall to replace it
+func clearLine(buf *prompt.Buffer) {
+	text, pos := bufferRunes(buf)
+	buf.CursorRight(len(text) - pos)
+	buf.DeleteBeforeCursor(len(text))
+}
+
+func currentEditState(buf *prompt.Buffer) editState {
+	text, pos := bufferRunes(buf)
+	return editState{text: string(text), cursor: pos}
+}
+
+// bufferRunes returns the buffer's text and the cursor position in runes
+func bufferRunes(buf *prompt.Buffer) ([]rune, int) {
+	doc := buf.Document()
+	return []rune(doc.Text), len([]rune(doc.TextBeforeCursor()))
+}
+
+// isWordRune reports whether r is part of a word for Alt-b, Alt-f and Alt-d:
+// identifiers stop at dots, quotes and operators
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$'
+}
+
+// wordBackward returns the start of the word before pos
+func wordBackward(text []rune, pos int) int {
+	for pos > 0 && !isWordRune(text[pos-1]) {
+		pos--
+	}
+	for pos > 0 && isWordRune(text[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+// wordForward returns the end of the word after pos
+func wordForward(text []rune, pos int) int {
+	for pos < len(text) && !isWordRune(text[pos]) {
+		pos++
+	}
+	for pos < len(text) && isWordRune(text[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// spaceWordBackward returns the start of the whitespace-separated word before
+// pos, as Ctrl-W cuts it
+func spaceWordBackward(text []rune, pos int) int {
+	for pos > 0 && unicode.IsSpace(text[pos-1]) {
+		pos--
+	}
+	for pos > 0 && !unicode.IsSpace(text[pos-1]) {
+		pos--
+	}
+	return pos
+}
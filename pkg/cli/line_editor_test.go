@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/c-bata/go-prompt"
+)
+
+func TestWordMotion(t *testing.T) {
+	text := []rune("SELECT o.customer_id FROM orders")
+	tests := []struct {
+		name string
+		fn   func([]rune, int) int
+		pos  int
+		want int
+	}{
+		{"backward from end", wordBackward, len(text), 26},
+		{"backward stops at dot", wordBackward, 20, 9},
+		{"backward over dot", wordBackward, 9, 7},
+		{"forward from start", wordForward, 0, 6},
+		{"forward to identifier end", wordForward, 8, 20},
+		{"space word", spaceWordBackward, 20, 7},
+		{"space word at start", spaceWordBackward, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.fn(text, tt.pos); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLineEditorKillAndYank(t *testing.T) {
+	e := &lineEditor{}
+	buf := prompt.NewBuffer()
+	buf.InsertText("SELECT name FROM users", false, true)
+
+	// Two Ctrl-W in a row build up one kill ring entry
+	kill := e.killTo(spaceWordBackward)
+	kill(buf)
+	kill(buf)
+	if buf.Text() != "SELECT name " || len(e.ring) != 1 || e.ring[0] != "FROM users" {
+		t.Fatalf("after Ctrl-W twice: text %q, ring %q", buf.Text(), e.ring)
+	}
+
+	// Moving the cursor starts a new entry
+	e.move(func([]rune, int) int { return 0 })(buf)
+	e.killTo(wordForward)(buf)
+	if buf.Text() != " name " || len(e.ring) != 2 || e.ring[1] != "SELECT" {
+		t.Fatalf("after Alt-d: text %q, ring %q", buf.Text(), e.ring)
+	}
+
+	e.yank(buf)
+	if buf.Text() != "SELECT name " {
+		t.Errorf("after Ctrl-Y: text %q", buf.Text())
+	}
+	e.yankPop(buf)
+	if buf.Text() != "FROM users name " {
+		t.Errorf("after Alt-y: text %q", buf.Text())
+	}
+}
+
+func TestLineEditorYankPopNeedsYank(t *testing.T) {
+	e := &lineEditor{ring: []string{"a", "b"}}
+	buf := prompt.NewBuffer()
+	buf.InsertText("x", false, true)
+	e.yankPop(buf)
+	if buf.Text() != "x" {
+		t.Errorf("Alt-y without a yank changed the buffer to %q", buf.Text())
+	}
+}
+
+func TestLineEditorUndoRedo(t *testing.T) {
+	e := &lineEditor{}
+	buf := prompt.NewBuffer()
+	kill := e.recorded(e.killTo(func([]rune, int) int { return 0 }))
+	buf.InsertText("FROM t", false, true)
+	kill(buf)
+	if buf.Text() != "" {
+		t.Fatalf("after Ctrl-U: %q", buf.Text())
+	}
+
+	e.undoEdit(buf)
+	if got := currentEditState(buf); got != (editState{"FROM t", 6}) {
+		t.Errorf("after undoing Ctrl-U: %+v", got)
+	}
+	// The typing before Ctrl-U is an edit of its own
+	e.undoEdit(buf)
+	if buf.Text() != "" {
+		t.Errorf("after undoing the typing: %q", buf.Text())
+	}
+	e.redoEdit(buf)
+	e.redoEdit(buf)
+	if buf.Text() != "" {
+		t.Errorf("after redoing both: %q", buf.Text())
+	}
+	e.undoEdit(buf)
+	if buf.Text() != "FROM t" {
+		t.Errorf("after undoing again: %q", buf.Text())
+	}
+
+	// Typing after an undo drops the redo history
+	buf.InsertText("!", false, true)
+	e.redoEdit(buf)
+	if buf.Text() != "FROM t!" {
+		t.Errorf("redo after typing changed the line to %q", buf.Text())
+	}
+
+	e.reset(buf)
+	if len(e.undo) != 0 || len(e.redo) != 0 {
+		t.Errorf("reset kept undo %v, redo %v", e.undo, e.redo)
+	}
+}
+
+func TestLineEditorClearLineKeepsKillRing(t *testing.T) {
+	e := &lineEditor{}
+	buf := prompt.NewBuffer()
+	buf.InsertText("SELECT 1", false, true)
+	buf.CursorLeft(3)
+	for _, b := range e.asciiCodeBindings() {
+		if string(b.ASCIICode) == string(keyClearLine) {
+			b.Fn(buf)
+		}
+	}
+	if buf.Text() != "" || len(e.ring) != 0 {
+		t.Errorf("clear line left %q, ring %q", buf.Text(), e.ring)
+	}
+	// History recall can be undone
+	e.undoEdit(buf)
+	if buf.Text() != "SELECT 1" {
+		t.Errorf("undo after clear line = %q", buf.Text())
+	}
+}
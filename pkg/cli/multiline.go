@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"strings"
+)
+
+// sqlState is where a piece of SQL text leaves the lexer
+type sqlState struct {
+	quote        byte // open ', " or ` quote, 0 if none
+	blockComment bool // inside /* ... */
+}
+
+// advance consumes the token starting at s[i] and returns the index after it.
+// It follows the server's rules: backslash escapes and doubled quotes inside
+// strings, and -- and # line comments outside them. A line comment stops
+// before its newline and is reported with lineComment.
+func (st *sqlState) advance(s string, i int) (next int, lineComment bool) {
+	c := s[i]
+	switch {
+	case st.blockComment:
+		if c == '*' && i+1 < len(s) && s[i+1] == '/' {
+			st.blockComment = false
+			return i + 2, false
+		}
+	case st.quote != 0:
+		if c == '\\' && st.quote != '`' && i+1 < len(s) {
+			return i + 2, false
+		}
+		if c == st.quote {
+			if i+1 < len(s) && s[i+1] == st.quote {
+				return i + 2, false // doubled quote
+			}
+			st.quote = 0
+		}
+	case c == '\'' || c == '"' || c == '`':
+		st.quote = c
+	case c == '/' && i+1 < len(s) && s[i+1] == '*':
+		st.blockComment = true
+		return i + 2, false
+	case isLineComment(s, i):
+		end := strings.IndexByte(s[i:], '\n')
+		if end < 0 {
+			return len(s), true
+		}
+		return i + end, true
+	}
+	return i + 1, false
+}
+
+// scanSQL returns the state at the end of s
+func scanSQL(s string) sqlState {
+	var st sqlState
+	for i := 0; i < len(s); {
+		i, _ = st.advance(s, i)
+	}
+	return st
+}
+
+// isLineComment reports whether a # or "-- " comment starts at s[i]
+func isLineComment(s string, i int) bool {
+	if s[i] == '#' {
+		return true
+	}
+	return strings.HasPrefix(s[i:], "--") && (i+2 == len(s) || s[i+2] == ' ' || s[i+2] == '\t' || s[i+2] == '\n')
+}
+
+// continuationPrompt returns the prompt for the next line of an unfinished
+// statement, right-aligned to width like mysql: -> normally, '> "> or `> inside
+// an open quote and /*> inside a comment
+func continuationPrompt(buffer string, width int) string {
+	marker := "->"
+	st := scanSQL(buffer)
+	switch {
+	case st.quote != 0:
+		marker = string(st.quote) + ">"
+	case st.blockComment:
+		marker = "/*>"
+	}
+	if pad := width - len(marker) - 1; pad > 0 {
+		return strings.Repeat(" ", pad) + marker + " "
+	}
+	return marker + " "
+}
+
+// flattenStatement joins the lines of a multi-line statement into one line for
+// history recall. Line comments are dropped so they cannot swallow the lines
+// that followed them, and newlines inside strings are kept.
+func flattenStatement(lines []string) string {
+	s := strings.Join(lines, "\n")
+	var b strings.Builder
+	var st sqlState
+	for i := 0; i < len(s); {
+		if s[i] == '\n' && st.quote == 0 {
+			b.WriteByte(' ')
+			i++
+			continue
+		}
+		next, comment := st.advance(s, i)
+		if !comment {
+			b.WriteString(s[i:next])
+		}
+		i = next
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// recordHistory adds an entered line to the whole-statement history. Lines of
+// an unfinished statement are collected and recorded as one entry once the
+// statement has run; backslash commands are recorded on their own.
+func (p *PromptExecutor) recordHistory(line string) {
+	if p.input == nil || line == "" {
+		return
+	}
+	if strings.HasPrefix(line, "\\") {
+		if len(p.historyLines) > 0 && strings.TrimSpace(p.buffer) == "" {
+			// \g ran the pending statement, \c discarded it
+			if line == "\\g" || line == "\\go" {
+				p.input.AddHistory(flattenStatement(p.historyLines) + ";")
+			}
+			p.historyLines = nil
+			return
+		}
+		if len(p.historyLines) == 0 {
+			p.input.AddHistory(line)
+		}
+		return
+	}
+	p.historyLines = append(p.historyLines, line)
+	if strings.TrimSpace(p.buffer) == "" {
+		p.input.AddHistory(flattenStatement(p.historyLines))
+		p.historyLines = nil
+	}
+}
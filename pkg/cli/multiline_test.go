@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestScanSQL(t *testing.T) {
+	tests := []struct {
+		sql          string
+		quote        byte
+		blockComment bool
+	}{
+		{"SELECT 1", 0, false},
+		{"SELECT 'abc", '\'', false},
+		{"SELECT 'it''s", '\'', false},
+		{"SELECT 'it''s'", 0, false},
+		{`SELECT 'a\'b`, '\'', false},
+		{`SELECT "x`, '"', false},
+		{"SELECT `col", '`', false},
+		{"SELECT 1 /* note", 0, true},
+		{"SELECT 1 /* note */ ", 0, false},
+		{"SELECT 1 -- it's fine", 0, false},
+		{"SELECT 1 # don't", 0, false},
+		{"SELECT 5--1 'x", '\'', false},
+	}
+	for _, tt := range tests {
+		st := scanSQL(tt.sql)
+		if st.quote != tt.quote || st.blockComment != tt.blockComment {
+			t.Errorf("scanSQL(%q) = %+v, want quote %q blockComment %v", tt.sql, st, tt.quote, tt.blockComment)
+		}
+	}
+}
+
+func TestContinuationPrompt(t *testing.T) {
+	tests := []struct {
+		buffer string
+		width  int
+		want   string
+	}{
+		{"SELECT *\n", 10, "       -> "},
+		{"SELECT 'abc\n", 10, "       '> "},
+		{"SELECT \"abc\n", 6, "   \"> "},
+		{"SELECT `a\n", 6, "   `> "},
+		{"SELECT /* x\n", 10, "      /*> "},
+		{"SELECT *\n", 2, "-> "},
+	}
+	for _, tt := range tests {
+		if got := continuationPrompt(tt.buffer, tt.width); got != tt.want {
+			t.Errorf("continuationPrompt(%q, %d) = %q, want %q", tt.buffer, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestFlattenStatement(t *testing.T) {
+	tests := []struct {
+		lines []string
+		want  string
+	}{
+		{[]string{"SELECT *", "FROM t", "WHERE id = 1;"}, "SELECT * FROM t WHERE id = 1;"},
+		{[]string{"SELECT a -- first", "FROM t;"}, "SELECT a  FROM t;"},
+		{[]string{"SELECT 'line one", "line two';"}, "SELECT 'line one\nline two';"},
+		{[]string{"SELECT /* a", "b */ 1;"}, "SELECT /* a b */ 1;"},
+	}
+	for _, tt := range tests {
+		if got := flattenStatement(tt.lines); got != tt.want {
+			t.Errorf("flattenStatement(%q) = %q, want %q", tt.lines, got, tt.want)
+		}
+	}
+}
+
+func TestStatementHistory(t *testing.T) {
+	var h statementHistory
+	if _, ok := h.Older("typed"); ok {
+		t.Fatalf("empty history should have nothing older")
+	}
+
+	h.Add("SELECT 1;")
+	h.Add("SELECT 2;")
+	h.Add("SELECT 2;") // consecutive duplicates are kept once
+
+	if got, _ := h.Older("draft"); got != "SELECT 2;" {
+		t.Errorf("Older = %q, want SELECT 2;", got)
+	}
+	if got, _ := h.Older("SELECT 2; -- edited"); got != "SELECT 1;" {
+		t.Errorf("Older = %q, want SELECT 1;", got)
+	}
+	if _, ok := h.Older("SELECT 1;"); ok {
+		t.Errorf("Older past the first entry should fail")
+	}
+	if got, _ := h.Newer("SELECT 1;"); got != "SELECT 2; -- edited" {
+		t.Errorf("Newer = %q, want the edited entry", got)
+	}
+	if got, _ := h.Newer("SELECT 2;"); got != "draft" {
+		t.Errorf("Newer = %q, want the draft", got)
+	}
+	if _, ok := h.Newer("draft"); ok {
+		t.Errorf("Newer past the draft should fail")
+	}
+}
+
+func TestInputParserRecallsStatements(t *testing.T) {
+	in := &inputParser{}
+	in.AddHistory("SELECT *\nFROM t;")
+
+	got := in.handleKey([]byte{0x1b, 0x5b, 0x41}) // Up
+	if string(got) != string(keyClearLine) {
+		t.Fatalf("Up returned %q, want the clear line key", got)
+	}
+	if len(in.pending) != 1 || string(in.pending[0]) != "SELECT *\nFROM t;" {
+		t.Errorf("pending = %q", in.pending)
+	}
+
+	in.pending = nil
+	in.completing = true
+	if got := in.handleKey([]byte{0x1b, 0x5b, 0x42}); string(got) != "\x1b[B" {
+		t.Errorf("Down while completing should pass through, got %q", got)
+	}
+}
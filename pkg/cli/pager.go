@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// pagerCommand is the command results are paged with: the one set with
+// \pager or in the config file, else $PAGER, else defaultPager
+func (p *PromptExecutor) pagerCommand() string {
+	if p.pager != "" {
+		return p.pager
+	}
+	if pager := os.Getenv("PAGER"); pager != "" {
+		return pager
+	}
+	return defaultPager
+}
+
+// pagerName is the pager \s reports, stdout when paging is off
+func (p *PromptExecutor) pagerName() string {
+	if !p.pagerEnabled {
+		return "stdout"
+	}
+	return p.pagerCommand()
+}
+
+// setPager implements \pager [command]: results taller than the terminal
+// are piped through the command, or the default pager without one
+func (p *PromptExecutor) setPager(args string) {
+	if command := strings.TrimSpace(args); command != "" {
+		p.pager = command
+		saveSetting("pager", command)
+	}
+	p.pagerEnabled = true
+	saveSetting("enable_pager", "true")
+	fmt.Printf("PAGER set to '%s'\n", p.pagerCommand())
+}
+
+// setNoPager implements \nopager: results are printed as they are
+func (p *PromptExecutor) setNoPager() {
+	p.pagerEnabled = false
+	saveSetting("enable_pager", "false")
+	fmt.Println("PAGER set to stdout")
+}
+
+// needsPager reports whether output should go through the pager: paging is
+// on, the session is at a terminal and the output has more lines than it
+func (p *PromptExecutor) needsPager(output string) bool {
+	if !p.pagerEnabled || p.input == nil {
+		return false
+	}
+	size := p.input.GetWinSize()
+	if size == nil || size.Row == 0 {
+		return false
+	}
+	return strings.Count(output, "\n") >= int(size.Row)
+}
+
+// printPaged prints query output, through the pager when it does not fit
+// the terminal. A pager that cannot be found is reported once and paging
+// is turned off for the session, with the output printed as it is.
+func (p *PromptExecutor) printPaged(output string) {
+	if !p.needsPager(output) {
+		fmt.Print(output)
+		return
+	}
+	command := p.pagerCommand()
+	if fields := strings.Fields(command); len(fields) == 0 || !commandExists(fields[0]) {
+		fmt.Print(output)
+		fmt.Printf("Pager %q not found, so paging is off for this session; \\pager <command> picks another\n", command)
+		p.pagerEnabled = false
+		return
+	}
+
+	// The pager handles Ctrl-C itself; it must not end the session
+	_, stop := p.statementContext()
+	defer stop()
+	cmd := shellCommand(context.Background(), command)
+	cmd.Stdin = strings.NewReader(output)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if os.Getenv("LESS") == "" {
+		// Colors, long lines unwrapped, and no paging for what fits after all
+		cmd.Env = append(os.Environ(), "LESS=-RSFX")
+	}
+	_ = cmd.Run()
+}
+
+// commandExists reports whether name is a program on PATH, or a path to one
+func commandExists(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
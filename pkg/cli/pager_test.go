@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/c-bata/go-prompt"
+)
+
+// terminalOfRows is a terminal with a fixed number of rows
+type terminalOfRows struct {
+	prompt.ConsoleParser
+	rows uint16
+}
+
+func (t terminalOfRows) GetWinSize() *prompt.WinSize {
+	return &prompt.WinSize{Row: t.rows, Col: 80}
+}
+
+func TestPrintPaged(t *testing.T) {
+	t.Setenv("GO_MYCLI_RC", filepath.Join(t.TempDir(), ".go-myclirc"))
+	out := filepath.Join(t.TempDir(), "paged")
+	p := &PromptExecutor{input: &inputParser{ConsoleParser: terminalOfRows{rows: 5}}}
+	p.setPager("cat > " + out)
+
+	short, long := "1\n2\n3\n", strings.Repeat("row\n", 10)
+	if p.needsPager(short) || !p.needsPager(long) {
+		t.Errorf("needsPager = %v for 3 lines, %v for 10 lines on 5 rows", p.needsPager(short), p.needsPager(long))
+	}
+	p.printPaged(long)
+	if data, err := os.ReadFile(out); err != nil || string(data) != long {
+		t.Errorf("the pager got %q, %v", data, err)
+	}
+
+	p.setNoPager()
+	if p.needsPager(long) || p.pagerName() != "stdout" {
+		t.Errorf("paging is still on after \\nopager (pager %s)", p.pagerName())
+	}
+	cfg := LoadSyntaxConfig()
+	if cfg.Pager != "cat > "+out || cfg.EnablePager {
+		t.Errorf("saved pager = %q, enabled %v", cfg.Pager, cfg.EnablePager)
+	}
+}
+
+func TestPagerCommand(t *testing.T) {
+	t.Setenv("PAGER", "")
+	p := &PromptExecutor{pagerEnabled: true}
+	if got := p.pagerCommand(); got != defaultPager {
+		t.Errorf("pager = %s, want %s", got, defaultPager)
+	}
+	t.Setenv("PAGER", "most")
+	if got := p.pagerCommand(); got != "most" {
+		t.Errorf("pager = %s, want $PAGER", got)
+	}
+	p.pager = "less -S"
+	if got := p.pagerName(); got != "less -S" {
+		t.Errorf("pager = %s, want the one set with \\pager", got)
+	}
+}
+
+func TestPrintPagedMissingPager(t *testing.T) {
+	p := &PromptExecutor{input: &inputParser{ConsoleParser: terminalOfRows{rows: 2}}, pager: "no-such-pager-go-mycli", pagerEnabled: true}
+	p.printPaged("a\nb\nc\n")
+	if p.pagerEnabled {
+		t.Error("paging is still on with a pager that does not exist")
+	}
+}
@@ -0,0 +1,579 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scriptParallelism is the number of connections scripts run on (--parallel);
+// 1 or less runs them statement by statement
+var scriptParallelism int
+
+// SetScriptParallelism sets how many connections \. and piped scripts may use
+func SetScriptParallelism(n int) {
+	scriptParallelism = n
+}
+
+// scriptStatement is one statement of a script
+type scriptStatement struct {
+	SQL      string
+	Vertical bool // terminated with \G
+}
+
+// scriptUnit is what runs on one connection as a whole: a single statement,
+// or a transaction or LOCK TABLES block that must stay on its connection
+type scriptUnit struct {
+	stmts   []scriptStatement
+	tables  map[string]bool // schema-qualified, lower case
+	write   bool
+	session bool // SET or USE: run on every connection
+	barrier bool // cannot be analyzed: run alone
+}
+
+// parsedScript is a script split into units, plus the tables that are tied
+// together by foreign keys or views and so must be treated as one
+type parsedScript struct {
+	units []scriptUnit
+	links map[string]string
+}
+
+var (
+	scriptIdent     = "(?:`[^`]+`|[A-Za-z0-9_$]+)"
+	scriptName      = scriptIdent + `(?:\s*\.\s*` + scriptIdent + `)?`
+	scriptNameList  = scriptName + `(?:\s*,\s*` + scriptName + `)*`
+	scriptModifiers = `(?:(?:LOW_PRIORITY|HIGH_PRIORITY|DELAYED|IGNORE|QUICK|TEMPORARY|INTO|IF\s+NOT\s+EXISTS|IF\s+EXISTS)\s+)*`
+	scriptTableRefs = regexp.MustCompile(`(?i)\b(?:FROM|JOIN|INTO|UPDATE|INSERT|REPLACE|TABLE|TABLES|TRUNCATE|REFERENCES|VIEW|DESCRIBE|INDEX\s+` +
+		scriptIdent + `\s+ON)\s+` + scriptModifiers + `(` + scriptNameList + `)`)
+	scriptNameRE   = regexp.MustCompile(scriptName)
+	scriptRename   = regexp.MustCompile(`(?i)\bRENAME\b`)
+	versionComment = regexp.MustCompile(`^/\*!\d*\s*([\s\S]*?)\s*\*/$`)
+)
+
+// splitScript splits a script into statements at ; and \G outside quotes and
+// comments. It reports false for scripts that need the interactive executor:
+// backslash commands, source or DELIMITER.
+func splitScript(text string) ([]scriptStatement, bool) {
+	var stmts []scriptStatement
+	var st sqlState
+	start := 0
+	atStart := true // nothing but space and comments since the last terminator
+	add := func(end int, vertical bool) {
+		if stmt := strings.TrimSpace(text[start:end]); stripLeadingComments(stmt) != "" {
+			stmts = append(stmts, scriptStatement{SQL: stmt, Vertical: vertical})
+		}
+	}
+	for i := 0; i < len(text); {
+		if st.quote == 0 && !st.blockComment {
+			if atStart && startsClientCommand(text[i:]) {
+				return nil, false
+			}
+			terminator := 0
+			switch {
+			case text[i] == ';':
+				terminator = 1
+			case strings.HasPrefix(text[i:], "\\G"):
+				terminator = 2
+			}
+			if terminator > 0 {
+				add(i, terminator == 2)
+				i += terminator
+				start, atStart = i, true
+				continue
+			}
+		}
+		before := st
+		next, lineComment := st.advance(text, i)
+		if !lineComment && !before.blockComment && !st.blockComment && !strings.ContainsRune(" \t\r\n", rune(text[i])) {
+			atStart = false
+		}
+		i = next
+	}
+	add(len(text), false)
+	return stmts, true
+}
+
+// startsClientCommand reports whether s begins with a command the client
+// rather than the server handles
+func startsClientCommand(s string) bool {
+	lower := strings.ToLower(s)
+	return strings.HasPrefix(s, "\\") && !strings.HasPrefix(s, "\\G") ||
+		strings.HasPrefix(lower, "source ") || strings.HasPrefix(lower, "delimiter ")
+}
+
+// stripLeadingComments removes comments in front of a statement, keeping
+// /*! ... */ version comments, which the server executes
+func stripLeadingComments(s string) string {
+	for {
+		s = strings.TrimSpace(s)
+		switch {
+		case strings.HasPrefix(s, "/*") && !strings.HasPrefix(s, "/*!"):
+			end := strings.Index(s, "*/")
+			if end < 0 {
+				return ""
+			}
+			s = s[end+2:]
+		case s != "" && isLineComment(s, 0):
+			end := strings.IndexByte(s, '\n')
+			if end < 0 {
+				return ""
+			}
+			s = s[end+1:]
+		default:
+			return s
+		}
+	}
+}
+
+// statementBody returns a statement without leading comments and with a
+// mysqldump-style /*!40101 ... */ wrapper removed
+func statementBody(stmt string) string {
+	body := stripLeadingComments(stmt)
+	if m := versionComment.FindStringSubmatch(body); m != nil {
+		body = m[1]
+	}
+	return body
+}
+
+// firstWord returns the upper-cased first keyword of a statement body
+func firstWord(body string) string {
+	body = strings.TrimLeft(body, "( \t\r\n")
+	end := strings.IndexFunc(body, func(r rune) bool {
+		return !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r == '_')
+	})
+	if end < 0 {
+		end = len(body)
+	}
+	return strings.ToUpper(body[:end])
+}
+
+// maskLiterals blanks out strings and comments so table names are only found
+// in SQL text
+func maskLiterals(s string) string {
+	var b strings.Builder
+	var st sqlState
+	for i := 0; i < len(s); {
+		before := st
+		next, lineComment := st.advance(s, i)
+		masked := lineComment || before.blockComment || st.blockComment ||
+			before.quote == '\'' || before.quote == '"' || st.quote == '\'' || st.quote == '"'
+		if masked {
+			b.WriteString(strings.Repeat(" ", next-i))
+		} else {
+			b.WriteString(s[i:next])
+		}
+		i = next
+	}
+	return b.String()
+}
+
+// statementTables returns the tables a statement names, qualified with db
+// when it names none
+func statementTables(body, db string) []string {
+	var tables []string
+	for _, m := range scriptTableRefs.FindAllStringSubmatch(maskLiterals(body), -1) {
+		for _, name := range scriptNameRE.FindAllString(m[1], -1) {
+			if !strings.Contains(name, "`") && isKeyword(strings.ToUpper(name)) {
+				continue
+			}
+			tables = append(tables, normalizeTableName(name, db))
+		}
+	}
+	return tables
+}
+
+// normalizeTableName turns `Db`.`T` or t into db.t
+func normalizeTableName(name, db string) string {
+	var parts []string
+	for _, part := range strings.Split(name, ".") {
+		parts = append(parts, strings.ToLower(strings.Trim(strings.TrimSpace(part), "`")))
+	}
+	if len(parts) == 1 && db != "" {
+		parts = append([]string{strings.ToLower(db)}, parts...)
+	}
+	return strings.Join(parts, ".")
+}
+
+// readStatements are the statements that only read and return rows
+var readStatements = map[string]bool{
+	"SELECT": true, "WITH": true, "SHOW": true, "DESCRIBE": true, "DESC": true,
+	"EXPLAIN": true, "TABLE": true, "VALUES": true,
+}
+
+// barrierStatements are run alone because what they touch cannot be read off
+// their text or is server-wide
+var barrierStatements = map[string]bool{
+	"CALL": true, "DO": true, "FLUSH": true, "GRANT": true, "REVOKE": true,
+	"RESET": true, "PURGE": true, "KILL": true, "HANDLER": true, "LOAD": true,
+	"XA": true, "PREPARE": true, "EXECUTE": true, "DEALLOCATE": true,
+}
+
+// parseScript splits a script into units, tracking the current database for
+// unqualified table names
+func parseScript(text, db string) (*parsedScript, bool) {
+	stmts, ok := splitScript(text)
+	if !ok {
+		return nil, false
+	}
+	script := &parsedScript{links: make(map[string]string)}
+	var block *scriptUnit // open transaction or LOCK TABLES block
+	for _, stmt := range stmts {
+		body := statementBody(stmt.SQL)
+		word := firstWord(body)
+		upper := strings.ToUpper(body)
+
+		unit := scriptUnit{stmts: []scriptStatement{stmt}, tables: make(map[string]bool), write: !readStatements[word]}
+		switch {
+		case word == "USE":
+			db = strings.Trim(strings.TrimSpace(body[3:]), "`")
+			unit.session = true
+		case word == "SET" && !strings.HasPrefix(upper, "SET PASSWORD"):
+			unit.session = true
+		case barrierStatements[word], scriptRename.MatchString(upper),
+			word == "CREATE" && !strings.Contains(upper, "TABLE") && !strings.Contains(upper, "INDEX") && !strings.Contains(upper, "VIEW"),
+			word == "DROP" && (strings.Contains(upper, "DATABASE") || strings.Contains(upper, "SCHEMA")):
+			unit.barrier = true
+		default:
+			tables := statementTables(body, db)
+			for _, t := range tables {
+				unit.tables[t] = true
+			}
+			if word == "CREATE" {
+				// Foreign keys and views tie their tables together
+				for _, t := range tables[1:] {
+					script.link(tables[0], t)
+				}
+			}
+			if len(tables) == 0 && word != "BEGIN" && word != "START" && word != "COMMIT" &&
+				word != "ROLLBACK" && word != "LOCK" && word != "UNLOCK" {
+				unit.barrier = true
+			}
+		}
+
+		opens := word == "BEGIN" || word == "START" || word == "LOCK"
+		closes := word == "COMMIT" || word == "ROLLBACK" || word == "UNLOCK"
+		switch {
+		case block != nil:
+			block.stmts = append(block.stmts, stmt)
+			for t := range unit.tables {
+				block.tables[t] = true
+			}
+			block.write = block.write || unit.write
+			block.barrier = block.barrier || unit.barrier || (unit.session && word == "USE")
+			if closes {
+				script.units = append(script.units, *block)
+				block = nil
+			}
+		case opens:
+			unit.write = true
+			block = &unit
+		default:
+			script.units = append(script.units, unit)
+		}
+	}
+	if block != nil {
+		script.units = append(script.units, *block)
+	}
+	return script, true
+}
+
+// link records that two tables must be treated as one
+func (s *parsedScript) link(a, b string) {
+	ra, rb := s.root(a), s.root(b)
+	if ra != rb {
+		s.links[ra] = rb
+	}
+}
+
+func (s *parsedScript) root(t string) string {
+	for {
+		next, ok := s.links[t]
+		if !ok {
+			return t
+		}
+		t = next
+	}
+}
+
+// tables returns the qualified tables the script names
+func (s *parsedScript) tables() []string {
+	seen := make(map[string]bool)
+	var tables []string
+	for _, u := range s.units {
+		for t := range u.tables {
+			if strings.Contains(t, ".") && !seen[t] {
+				seen[t] = true
+				tables = append(tables, t)
+			}
+		}
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// serverRelations returns the pairs of tables tied together on the server:
+// a foreign key from or to one of tables, or a trigger on one of them and
+// the tables its body names. Statements on either table of a pair must not
+// run at the same time even when the script itself never relates them.
+func serverRelations(ctx context.Context, db sqlRunner, tables []string) ([][2]string, error) {
+	if len(tables) == 0 {
+		return nil, nil
+	}
+	in := strings.TrimSuffix(strings.Repeat("?, ", len(tables)), ", ")
+	args := make([]any, 0, 2*len(tables))
+	for _, t := range tables {
+		args = append(args, t)
+	}
+
+	var pairs [][2]string
+	rows, err := db.QueryContext(ctx, "SELECT TABLE_SCHEMA, TABLE_NAME, REFERENCED_TABLE_SCHEMA, REFERENCED_TABLE_NAME"+
+		" FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE WHERE REFERENCED_TABLE_NAME IS NOT NULL"+
+		" AND (LOWER(CONCAT(TABLE_SCHEMA, '.', TABLE_NAME)) IN ("+in+")"+
+		" OR LOWER(CONCAT(REFERENCED_TABLE_SCHEMA, '.', REFERENCED_TABLE_NAME)) IN ("+in+"))",
+		append(args, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var schema, table, refSchema, refTable string
+		if err := rows.Scan(&schema, &table, &refSchema, &refTable); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, [2]string{normalizeTableName(table, schema), normalizeTableName(refTable, refSchema)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	triggers, err := db.QueryContext(ctx, "SELECT EVENT_OBJECT_SCHEMA, EVENT_OBJECT_TABLE, ACTION_STATEMENT"+
+		" FROM INFORMATION_SCHEMA.TRIGGERS WHERE LOWER(CONCAT(EVENT_OBJECT_SCHEMA, '.', EVENT_OBJECT_TABLE)) IN ("+in+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer triggers.Close()
+	for triggers.Next() {
+		var schema, table, body string
+		if err := triggers.Scan(&schema, &table, &body); err != nil {
+			return nil, err
+		}
+		for _, t := range statementTables(body, schema) {
+			pairs = append(pairs, [2]string{normalizeTableName(table, schema), t})
+		}
+	}
+	return pairs, triggers.Err()
+}
+
+// conflicts reports whether two units touch a common table and at least one
+// of them writes it
+func (s *parsedScript) conflicts(a, b *scriptUnit) bool {
+	if !a.write && !b.write {
+		return false
+	}
+	roots := make(map[string]bool, len(a.tables))
+	for t := range a.tables {
+		roots[s.root(t)] = true
+	}
+	for t := range b.tables {
+		if roots[s.root(t)] {
+			return true
+		}
+	}
+	return false
+}
+
+// waves orders the units into waves that run one after another. A unit goes
+// into the first wave after every earlier unit it conflicts with; session and
+// barrier units get a wave of their own, so nothing moves across them.
+func (s *parsedScript) waves() [][]int {
+	level := make([]int, len(s.units))
+	floor, top := 0, -1
+	for i := range s.units {
+		u := &s.units[i]
+		if u.session || u.barrier {
+			level[i] = top + 1
+			floor = level[i] + 1
+			top = level[i]
+			continue
+		}
+		level[i] = floor
+		for j := range i {
+			if level[j] >= level[i] && s.conflicts(&s.units[j], u) {
+				level[i] = level[j] + 1
+			}
+		}
+		top = max(top, level[i])
+	}
+	waves := make([][]int, top+1)
+	for i, l := range level {
+		waves[l] = append(waves[l], i)
+	}
+	return waves
+}
+
+// runParallel runs a script over up to scriptParallelism connections,
+// printing each statement's output in script order. It reports false, having
+// run nothing, for scripts it cannot analyze; those run sequentially.
+func (p *PromptExecutor) runParallel(text string, echo bool) bool {
+	script, ok := parseScript(text, p.database)
+	if !ok {
+		fmt.Println("(script uses client commands or DELIMITER; running it sequentially)")
+		return false
+	}
+
+	ctx, stop := p.statementContext()
+	defer stop()
+	relations, err := serverRelations(ctx, p.db, script.tables())
+	if err != nil {
+		fmt.Printf("(could not read foreign keys and triggers: %v; running the script sequentially)\n", err)
+		return false
+	}
+	for _, r := range relations {
+		script.link(r[0], r[1])
+	}
+	waves := script.waves()
+	width := 1
+	for _, w := range waves {
+		width = max(width, len(w))
+	}
+	workers := min(scriptParallelism, width)
+
+	conns := make([]*sql.Conn, 0, workers)
+	defer func() {
+		// The connections may carry the script's SET and USE; discard them
+		// instead of handing them back to the shared pool
+		for _, conn := range conns {
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+			conn.Close()
+		}
+	}()
+	for range workers {
+		conn, err := p.db.Conn(ctx)
+		if err != nil {
+			if len(conns) == 0 {
+				fmt.Printf(tr("Error: %v\n"), err)
+				return true
+			}
+			break
+		}
+		conns = append(conns, conn)
+		// Ctrl-C stops what each connection is running on the server
+		if id, err := connectionID(ctx, conn); err == nil {
+			defer p.killOnCancel(ctx, id)()
+		}
+	}
+
+	start := time.Now()
+	outputs := make([]chan string, len(script.units))
+	for i := range outputs {
+		outputs[i] = make(chan string, 1)
+	}
+	go func() {
+		for _, wave := range waves {
+			if u := &script.units[wave[0]]; u.session {
+				// Every connection needs the setting; report it once
+				for _, conn := range conns[1:] {
+					p.runUnit(ctx, conn, u, false)
+				}
+				outputs[wave[0]] <- p.runUnit(ctx, conns[0], u, echo)
+				continue
+			}
+			jobs := make(chan int)
+			var wg sync.WaitGroup
+			for _, conn := range conns {
+				wg.Add(1)
+				go func(conn *sql.Conn) {
+					defer wg.Done()
+					for i := range jobs {
+						outputs[i] <- p.runUnit(ctx, conn, &script.units[i], echo)
+					}
+				}(conn)
+			}
+			for _, i := range wave {
+				jobs <- i
+			}
+			close(jobs)
+			wg.Wait()
+		}
+	}()
+
+	statements := 0
+	for i, out := range outputs {
+		fmt.Print(<-out)
+		statements += len(script.units[i].stmts)
+	}
+	if ctx.Err() != nil {
+		fmt.Printf(tr("Error: %v\n"), errCanceled)
+		p.interrupted = true
+		return true
+	}
+	fmt.Printf("Ran %d statement%s in %d wave%s on %d connection%s (%.3fs)\n",
+		statements, plural(statements), len(waves), plural(len(waves)), len(conns), plural(len(conns)), time.Since(start).Seconds())
+	return true
+}
+
+// runUnit runs a unit's statements on one connection and returns their
+// output
+func (p *PromptExecutor) runUnit(ctx context.Context, conn *sql.Conn, u *scriptUnit, echo bool) string {
+	var b strings.Builder
+	for _, stmt := range u.stmts {
+		if ctx.Err() != nil {
+			// The script was canceled; the rest of it is not run
+			break
+		}
+		if echo {
+			fmt.Fprintf(&b, "--------------\n%s\n--------------\n\n", stmt.SQL)
+		}
+		b.WriteString(p.runScriptStatement(ctx, conn, stmt))
+	}
+	return b.String()
+}
+
+// runScriptStatement runs one statement and formats its result the way
+// ExecuteSQL prints it
+func (p *PromptExecutor) runScriptStatement(ctx context.Context, conn *sql.Conn, stmt scriptStatement) string {
+	res, err := runStatementOn(ctx, conn, stmt.SQL)
+	if err != nil {
+		return fmt.Sprintf(tr("Error: %v\n"), canceled(ctx, err))
+	}
+	return p.resultOutput(res, stmt.Vertical)
+}
+
+// sqlRunner is what runStatementOn needs: a *sql.DB or a pinned *sql.Conn
+type sqlRunner interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// runStatementOn runs a statement on db, reading read statements as result
+// sets. Other statements leave the result without columns.
+func runStatementOn(ctx context.Context, db sqlRunner, stmt string) (*Result, error) {
+	res := &Result{Statement: stmt}
+	start := time.Now()
+	if readStatements[firstWord(statementBody(stmt))] {
+		rows, err := db.QueryContext(ctx, stmt)
+		if err != nil {
+			return nil, err
+		}
+		err = readResult(rows, res)
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		r, err := db.ExecContext(ctx, stmt)
+		if err != nil {
+			return nil, err
+		}
+		res.RowsAffected, _ = r.RowsAffected()
+		res.LastInsertID, _ = r.LastInsertId()
+	}
+	res.Duration = time.Since(start)
+	return res, nil
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSplitScript(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []scriptStatement
+		ok     bool
+	}{
+		{"terminators", "SELECT 1;\nSELECT 2\\G\nSELECT 3", []scriptStatement{
+			{SQL: "SELECT 1"}, {SQL: "SELECT 2", Vertical: true}, {SQL: "SELECT 3"},
+		}, true},
+		{"semicolon in string and comment", "INSERT INTO t VALUES ('a;b'); -- c;d\n/* e; */ SELECT 1;", []scriptStatement{
+			{SQL: "INSERT INTO t VALUES ('a;b')"}, {SQL: "-- c;d\n/* e; */ SELECT 1"},
+		}, true},
+		{"comment only", "SELECT 1;\n-- done\n", []scriptStatement{{SQL: "SELECT 1"}}, true},
+		{"backslash command", "SELECT 1;\n\\u other\nSELECT 2;", nil, false},
+		{"delimiter", "DELIMITER //\nCREATE PROCEDURE p() BEGIN SELECT 1; END//", nil, false},
+		{"source", "source other.sql\n", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := splitScript(tt.script)
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitScript() = %#v, %v; want %#v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestStatementTables(t *testing.T) {
+	tests := []struct {
+		body string
+		want []string
+	}{
+		{"INSERT INTO orders VALUES (1, 'from users')", []string{"shop.orders"}},
+		{"UPDATE IGNORE `Shop`.`Orders` SET a = 1", []string{"shop.orders"}},
+		{"CREATE INDEX idx_a ON items (a)", []string{"shop.items"}},
+		{"DROP TABLE IF EXISTS a, b", []string{"shop.a", "shop.b"}},
+		{"SELECT * FROM a JOIN other.b ON a.id = b.id", []string{"shop.a", "other.b"}},
+		{"CREATE TABLE c (id INT, p INT, FOREIGN KEY (p) REFERENCES parent (id))", []string{"shop.c", "shop.parent"}},
+		{"SELECT 1", nil},
+	}
+	for _, tt := range tests {
+		if got := statementTables(tt.body, "shop"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("statementTables(%q) = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestScriptWaves(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		related [][2]string // relations on the server
+		want    [][]int
+	}{
+		{"independent tables", "INSERT INTO a VALUES (1); INSERT INTO b VALUES (1); CREATE INDEX i ON c (x);",
+			nil, [][]int{{0, 1, 2}}},
+		{"same table keeps order", "INSERT INTO a VALUES (1); INSERT INTO b VALUES (1); INSERT INTO a VALUES (2);",
+			nil, [][]int{{0, 1}, {2}}},
+		{"reads share", "SELECT * FROM a; SELECT * FROM a; UPDATE a SET x = 1;",
+			nil, [][]int{{0, 1}, {2}}},
+		{"session statements are barriers", "INSERT INTO a VALUES (1); SET foreign_key_checks = 0; INSERT INTO b VALUES (1);",
+			nil, [][]int{{0}, {1}, {2}}},
+		{"foreign keys tie tables", "CREATE TABLE c (p INT, FOREIGN KEY (p) REFERENCES p (id)); INSERT INTO p VALUES (1); INSERT INTO c VALUES (1);",
+			nil, [][]int{{0}, {1}, {2}}},
+		{"server foreign keys tie tables", "INSERT INTO orders VALUES (1); INSERT INTO order_items VALUES (1, 1); INSERT INTO b VALUES (1);",
+			[][2]string{{"shop.order_items", "shop.orders"}}, [][]int{{0, 2}, {1}}},
+		{"triggers tie the tables they write", "INSERT INTO orders VALUES (1); SELECT * FROM audit; SELECT * FROM b;",
+			[][2]string{{"shop.orders", "shop.audit"}}, [][]int{{0, 2}, {1}}},
+		{"lock block stays together", "LOCK TABLES a WRITE; INSERT INTO a VALUES (1); UNLOCK TABLES; LOCK TABLES b WRITE; INSERT INTO b VALUES (1); UNLOCK TABLES;",
+			nil, [][]int{{0, 1}}},
+		{"mysqldump version comments", "/*!40101 SET NAMES utf8mb4 */; /*!40000 ALTER TABLE `a` DISABLE KEYS */; INSERT INTO `b` VALUES (1);",
+			nil, [][]int{{0}, {1, 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, ok := parseScript(tt.script, "shop")
+			if !ok {
+				t.Fatal("parseScript() rejected the script")
+			}
+			for _, r := range tt.related {
+				script.link(r[0], r[1])
+			}
+			if got := script.waves(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("waves() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunUnitCanceled(t *testing.T) {
+	// After Ctrl-C the rest of the script is not run
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	u := &scriptUnit{stmts: []scriptStatement{{SQL: "SELECT 1"}, {SQL: "SELECT 2"}}}
+	if out := (&PromptExecutor{}).runUnit(ctx, nil, u, true); out != "" {
+		t.Errorf("a canceled unit printed %q", out)
+	}
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+)
+
+// Bracketed paste mode: the terminal wraps pasted text in these markers, so a
+// paste can be told apart from typing
+var (
+	pasteStart = []byte("\x1b[200~")
+	pasteEnd   = []byte("\x1b[201~")
+)
+
+const (
+	enableBracketedPaste  = "\x1b[?2004h"
+	disableBracketedPaste = "\x1b[?2004l"
+)
+
+// Setup enables bracketed paste along with raw mode. go-prompt tears the
+// terminal down while a statement runs, so \browse, \view and \! see a
+// normal terminal.
+func (in *inputParser) Setup() error {
+	if err := in.ConsoleParser.Setup(); err != nil {
+		return err
+	}
+	_, _ = os.Stdout.WriteString(enableBracketedPaste)
+	return nil
+}
+
+// TearDown disables bracketed paste and restores the terminal
+func (in *inputParser) TearDown() error {
+	_, _ = os.Stdout.WriteString(disableBracketedPaste)
+	return in.ConsoleParser.TearDown()
+}
+
+// collectPaste accumulates a paste that may arrive over several reads. It
+// returns the text to insert once the paste is complete, and an ignored key
+// until then. Called with in.mu held.
+func (in *inputParser) collectPaste(b []byte) []byte {
+	if !in.inPaste {
+		i := bytes.Index(b, pasteStart)
+		in.inPaste = true
+		in.pasteBuf = append(in.pasteBuf[:0], b[:i]...)
+		b = b[i+len(pasteStart):]
+	}
+	in.pasteBuf = append(in.pasteBuf, b...)
+	end := bytes.Index(in.pasteBuf, pasteEnd)
+	if end < 0 {
+		return keyIgnoreRead
+	}
+	if rest := in.pasteBuf[end+len(pasteEnd):]; len(rest) > 0 {
+		in.pending = append(in.pending, append([]byte(nil), rest...))
+	}
+	text := normalizePaste(string(in.pasteBuf[:end]))
+	in.inPaste = false
+	in.pasteBuf = in.pasteBuf[:0]
+
+	display := pasteDisplay(text)
+	if display == "" {
+		return keyIgnoreRead
+	}
+	in.paste, in.pasteShown, in.justPasted = text, display, true
+	return []byte(display)
+}
+
+// JustPasted reports whether the last input was a paste, so the completer can
+// stay quiet instead of popping up for the pasted text
+func (in *inputParser) JustPasted() bool {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.justPasted
+}
+
+// TakePaste swaps the one-line form of the last paste in line for the
+// original text and forgets the paste. ok is false if line does not contain
+// the paste, e.g. because it was edited.
+func (in *inputParser) TakePaste(line string) (text string, ok bool) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	paste, shown := in.paste, in.pasteShown
+	in.paste, in.pasteShown = "", ""
+	if shown == "" || !strings.Contains(line, shown) {
+		return line, false
+	}
+	return strings.Replace(line, shown, paste, 1), true
+}
+
+// normalizePaste converts the terminal's line endings to \n and drops
+// trailing blank lines, so a paste never runs before Enter is pressed
+func normalizePaste(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	return strings.TrimRight(text, "\n")
+}
+
+// pasteDisplay is how a paste is shown in the one-line input buffer
+func pasteDisplay(text string) string {
+	return strings.ReplaceAll(text, "\n", " ")
+}
+
+// runPasted executes pasted text line by line, exactly as if each line had
+// been typed and entered
+func (p *PromptExecutor) runPasted(text string) {
+	for _, line := range strings.Split(text, "\n") {
+		p.Executor(line)
+	}
+}
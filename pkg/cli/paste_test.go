@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestCollectPaste(t *testing.T) {
+	in := &inputParser{}
+
+	// The paste arrives over two reads, with the end marker split between them
+	if got := in.collectPaste([]byte("\x1b[200~SELECT 1;\rSELECT\r\n  2;\r\x1b[20")); string(got) != string(keyIgnoreRead) {
+		t.Fatalf("partial paste returned %q", got)
+	}
+	got := in.collectPaste([]byte("1~x"))
+	if want := "SELECT 1; SELECT   2;"; string(got) != want {
+		t.Errorf("collectPaste = %q, want %q", got, want)
+	}
+	if in.inPaste || !in.justPasted {
+		t.Errorf("inPaste = %v, justPasted = %v", in.inPaste, in.justPasted)
+	}
+	if len(in.pending) != 1 || string(in.pending[0]) != "x" {
+		t.Errorf("text after the paste should be queued, pending = %q", in.pending)
+	}
+
+	text, ok := in.TakePaste("EXPLAIN SELECT 1; SELECT   2;")
+	if !ok || text != "EXPLAIN SELECT 1;\nSELECT\n  2;" {
+		t.Errorf("TakePaste = %q, %v", text, ok)
+	}
+	if _, ok := in.TakePaste("SELECT 1; SELECT   2;"); ok {
+		t.Errorf("a paste should only be taken once")
+	}
+}
+
+func TestTakePasteEdited(t *testing.T) {
+	in := &inputParser{paste: "SELECT 1;\nSELECT 2;", pasteShown: "SELECT 1; SELECT 2;"}
+	if text, ok := in.TakePaste("SELECT 1; SELECT 3;"); ok || text != "SELECT 1; SELECT 3;" {
+		t.Errorf("edited paste: TakePaste = %q, %v", text, ok)
+	}
+}
+
+func TestNormalizePaste(t *testing.T) {
+	tests := map[string]string{
+		"SELECT 1;\r\n":        "SELECT 1;",
+		"a\rb\r\r":             "a\nb",
+		"SELECT 'x\r\ny';\n\n": "SELECT 'x\ny';",
+	}
+	for in, want := range tests {
+		if got := normalizePaste(in); got != want {
+			t.Errorf("normalizePaste(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
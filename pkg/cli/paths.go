@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// dataDir is where go-mycli keeps its own files: sessions, plugins,
+// metadata.db and rc.sql
+func dataDir() string {
+	return userPath(".go-mycli", "go-mycli")
+}
+
+// homeConfigPath is the config file read when there is no ./.go-myclirc
+func homeConfigPath() string {
+	return userPath(".go-myclirc", filepath.Join("go-mycli", "go-myclirc"))
+}
+
+// userPath returns ~/name, or on Windows %APPDATA%\windowsName unless
+// ~/name is already there from an earlier go-mycli. It is "" when there is
+// no home directory.
+func userPath(name, windowsName string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	path := filepath.Join(home, name)
+	if appData := os.Getenv("APPDATA"); runtime.GOOS == "windows" && appData != "" {
+		if _, err := os.Stat(path); err != nil {
+			return filepath.Join(appData, windowsName)
+		}
+	}
+	return path
+}
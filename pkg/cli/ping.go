@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPingCount = 5
+	maxPingCount     = 100
+	pingInterval     = 200 * time.Millisecond
+)
+
+// ping handles \ping [n]: it times n round trips of a trivial query on one
+// connection and prints min/avg/max and jitter, like ping(8)
+func (p *PromptExecutor) ping(args string) {
+	count := defaultPingCount
+	if args = strings.TrimSpace(args); args != "" {
+		n, err := strconv.Atoi(args)
+		if err != nil || n < 1 || n > maxPingCount {
+			fmt.Printf("Usage: \\ping [n] with n between 1 and %d\n", maxPingCount)
+			return
+		}
+		count = n
+	}
+
+	ctx, stop := p.statementContext()
+	defer stop()
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return
+	}
+	defer conn.Close()
+
+	fmt.Printf("PING %s with SELECT 1, %d time%s\n", p.host, count, plural(count))
+	var samples []time.Duration
+	sent := 0
+	for seq := 1; seq <= count; seq++ {
+		if seq > 1 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(pingInterval):
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		sent++
+		qctx, cancel := context.WithTimeout(ctx, pingTimeout)
+		start := time.Now()
+		var one int
+		err := conn.QueryRowContext(qctx, "SELECT 1").Scan(&one)
+		rtt := time.Since(start)
+		cancel()
+		if err != nil {
+			fmt.Printf("seq=%d error: %v\n", seq, err)
+			continue
+		}
+		samples = append(samples, rtt)
+		fmt.Printf("seq=%d time=%s\n", seq, formatLatency(rtt))
+	}
+
+	if sent == 0 {
+		return
+	}
+	lost := sent - len(samples)
+	fmt.Printf("--- %d sent, %d received, %.0f%% lost\n", sent, len(samples), float64(lost)*100/float64(sent))
+	if len(samples) == 0 {
+		return
+	}
+	stats := pingStats(samples)
+	fmt.Printf("rtt min/avg/max/jitter = %s/%s/%s/%s\n",
+		formatLatency(stats.min), formatLatency(stats.avg), formatLatency(stats.max), formatLatency(stats.jitter))
+	p.lastLatency = stats.avg
+}
+
+type latencyStats struct {
+	min, avg, max time.Duration
+	jitter        time.Duration // mean difference between consecutive round trips
+}
+
+func pingStats(samples []time.Duration) latencyStats {
+	s := latencyStats{min: samples[0], max: samples[0]}
+	var total, diffs time.Duration
+	for i, rtt := range samples {
+		total += rtt
+		s.min = min(s.min, rtt)
+		s.max = max(s.max, rtt)
+		if i > 0 {
+			d := rtt - samples[i-1]
+			if d < 0 {
+				d = -d
+			}
+			diffs += d
+		}
+	}
+	s.avg = total / time.Duration(len(samples))
+	if len(samples) > 1 {
+		s.jitter = diffs / time.Duration(len(samples)-1)
+	}
+	return s
+}
+
+// formatLatency renders a round trip in milliseconds with three decimals
+func formatLatency(d time.Duration) string {
+	return fmt.Sprintf("%.3f ms", float64(d)/float64(time.Millisecond))
+}
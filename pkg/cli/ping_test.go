@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPingStats(t *testing.T) {
+	ms := time.Millisecond
+	s := pingStats([]time.Duration{10 * ms, 14 * ms, 12 * ms, 20 * ms})
+	if s.min != 10*ms || s.max != 20*ms || s.avg != 14*ms {
+		t.Errorf("min/avg/max = %s/%s/%s", s.min, s.avg, s.max)
+	}
+	// |14-10| + |12-14| + |20-12| = 14ms over 3 gaps
+	if want := 14 * ms / 3; s.jitter != want {
+		t.Errorf("jitter = %s, want %s", s.jitter, want)
+	}
+	if one := pingStats([]time.Duration{5 * ms}); one.jitter != 0 || one.avg != 5*ms {
+		t.Errorf("single sample = %+v", one)
+	}
+}
+
+func TestFormatLatency(t *testing.T) {
+	if got := formatLatency(1234567 * time.Nanosecond); got != "1.235 ms" {
+		t.Errorf("formatLatency = %q", got)
+	}
+}
@@ -0,0 +1,30 @@
+//go:build !windows
+
+package cli
+
+import (
+	"context"
+	"net"
+	"os/exec"
+)
+
+// socketNetwork is the driver network --socket connects over: a Unix socket
+// here, a named pipe on Windows
+const socketNetwork = "unix"
+
+// defaultEditor edits the config file when neither VISUAL nor EDITOR is set
+const defaultEditor = "vi"
+
+// defaultPager pages results when neither pager nor PAGER is set
+const defaultPager = "less"
+
+// dialSocket connects to the server's Unix socket
+func dialSocket(ctx context.Context, path string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "unix", path)
+}
+
+// shellCommand runs command with sh, as \! and the shell hook do
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,109 @@
+//go:build windows
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"golang.org/x/sys/windows"
+)
+
+// socketNetwork is the driver network --socket connects over. On Windows,
+// as with the mysql client, --socket names the server's named pipe.
+const socketNetwork = "pipe"
+
+// defaultEditor edits the config file when neither VISUAL nor EDITOR is set
+const defaultEditor = "notepad"
+
+// defaultPager pages results when neither pager nor PAGER is set
+const defaultPager = "more"
+
+func init() {
+	mysql.RegisterDialContext(socketNetwork, func(ctx context.Context, addr string) (net.Conn, error) {
+		return dialSocket(ctx, addr)
+	})
+	enableVirtualTerminal()
+}
+
+// enableVirtualTerminal turns on ANSI sequences in the console. Consoles
+// older than Windows 10 have no such mode, so output degrades to no colors
+// and ASCII trees there. Output that is not a console is left alone.
+func enableVirtualTerminal() {
+	out := windows.Handle(os.Stdout.Fd())
+	var mode uint32
+	if windows.GetConsoleMode(out, &mode) != nil {
+		return
+	}
+	if windows.SetConsoleMode(out, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) != nil {
+		legacyConsole()
+	}
+}
+
+// pipePath returns the path of a named pipe: MySQL is \\.\pipe\MySQL, and a
+// full path is kept
+func pipePath(name string) string {
+	if strings.HasPrefix(name, `\\`) {
+		return name
+	}
+	return `\\.\pipe\` + name
+}
+
+// dialSocket opens the server's named pipe, waiting while every instance of
+// it is busy with another client
+func dialSocket(ctx context.Context, name string) (net.Conn, error) {
+	path := pipePath(name)
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err == nil {
+			return &pipeConn{File: f, addr: pipeAddr(path)}, nil
+		}
+		if !errors.Is(err, windows.ERROR_PIPE_BUSY) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// pipeConn is a named pipe as the net.Conn the driver talks over. The pipe
+// is opened for blocking I/O, so deadlines are not supported and ignored.
+type pipeConn struct {
+	*os.File
+	addr pipeAddr
+}
+
+func (c *pipeConn) LocalAddr() net.Addr                { return c.addr }
+func (c *pipeConn) RemoteAddr() net.Addr               { return c.addr }
+func (c *pipeConn) SetDeadline(t time.Time) error      { return nil }
+func (c *pipeConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *pipeConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// pipeAddr is the address of a named pipe
+type pipeAddr string
+
+func (a pipeAddr) Network() string { return socketNetwork }
+func (a pipeAddr) String() string  { return string(a) }
+
+// shellCommand runs command with cmd.exe (%ComSpec%), as \! and the shell
+// hook do. The command line is passed as typed, since cmd does not parse
+// the quoting Go would add.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	shell := os.Getenv("ComSpec")
+	if shell == "" {
+		shell = "cmd.exe"
+	}
+	cmd := exec.CommandContext(ctx, shell)
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: syscall.EscapeArg(shell) + " /S /C \"" + command + "\""}
+	return cmd
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pluginDir returns the directory scanned for plugin executables:
+// $GO_MYCLI_PLUGIN_DIR or ~/.go-mycli/plugins
+func pluginDir() string {
+	if dir := os.Getenv("GO_MYCLI_PLUGIN_DIR"); dir != "" {
+		return dir
+	}
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "plugins")
+}
+
+// discoverPlugins maps command names to executables in dir. A file named
+// "ticket" or "ticket.sh" becomes the \ticket command.
+func discoverPlugins(dir string) map[string]string {
+	plugins := make(map[string]string)
+	if dir == "" {
+		return plugins
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return plugins
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Mode()&0111 == 0 {
+			continue // not executable
+		}
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if _, exists := plugins[name]; !exists {
+			plugins[name] = filepath.Join(dir, entry.Name())
+		}
+	}
+	return plugins
+}
+
+// pluginNames returns the sorted plugin command names
+func pluginNames(plugins map[string]string) []string {
+	names := make([]string, 0, len(plugins))
+	for name := range plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// runPluginCommand runs the plugin for a backslash command such as
+// "\ticket 1234". It returns false if no plugin provides the command.
+func (p *PromptExecutor) runPluginCommand(in string) bool {
+	parts := strings.Fields(strings.TrimPrefix(in, "\\"))
+	if len(parts) == 0 {
+		return false
+	}
+	path, ok := discoverPlugins(pluginDir())[parts[0]]
+	if !ok {
+		return false
+	}
+
+	// Plugins get the terminal and learn about the session from the environment
+	cmd := exec.Command(path, parts[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"GO_MYCLI_HOST="+p.host,
+		"GO_MYCLI_PORT="+strconv.Itoa(p.port),
+		"GO_MYCLI_USER="+p.user,
+		"GO_MYCLI_DATABASE="+p.database,
+		"GO_MYCLI_BUFFER="+p.buffer,
+	)
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("Plugin \\%s failed: %v\n", parts[0], err)
+	}
+	return true
+}
+
+// listPlugins prints the plugin commands available in the plugin directory
+func (p *PromptExecutor) listPlugins() {
+	dir := pluginDir()
+	plugins := discoverPlugins(dir)
+	if len(plugins) == 0 {
+		fmt.Printf("No plugins found in %s\n", dir)
+		return
+	}
+	fmt.Printf("Plugins in %s:\n", dir)
+	for _, name := range pluginNames(plugins) {
+		fmt.Printf("  \\%-12s %s\n", name, plugins[name])
+	}
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscoverPlugins(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]os.FileMode{
+		"ticket.sh":  0755,
+		"runbook":    0755,
+		"notes.txt":  0644,
+		".hidden.sh": 0755,
+	}
+	for name, mode := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "subdir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins := discoverPlugins(dir)
+	names := pluginNames(plugins)
+	if len(names) != 2 || names[0] != "runbook" || names[1] != "ticket" {
+		t.Fatalf("discoverPlugins found %v, expected [runbook ticket]", names)
+	}
+	if plugins["ticket"] != filepath.Join(dir, "ticket.sh") {
+		t.Errorf("ticket plugin path = %q", plugins["ticket"])
+	}
+}
+
+func TestDiscoverPluginsMissingDir(t *testing.T) {
+	if plugins := discoverPlugins(filepath.Join(t.TempDir(), "missing")); len(plugins) != 0 {
+		t.Errorf("expected no plugins, got %v", plugins)
+	}
+}
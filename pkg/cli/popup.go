@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+)
+
+// PopupConfig holds the [completion] section of ~/.go-myclirc: the size and
+// colors of the completion popup. Empty colors keep go-prompt's defaults.
+type PopupConfig struct {
+	MaxRows      int  // suggestions shown at once, 0 for go-prompt's default of 6
+	Descriptions bool // show the description column ("Table", "Column from orders", ...)
+
+	SuggestionText          string
+	SuggestionBG            string
+	SelectedText            string
+	SelectedBG              string
+	DescriptionText         string
+	DescriptionBG           string
+	SelectedDescriptionText string
+	SelectedDescriptionBG   string
+	Scrollbar               string
+	ScrollbarBG             string
+}
+
+// Enabled reports whether anything differs from the defaults
+func (c PopupConfig) Enabled() bool {
+	return c.MaxRows != 0 || !c.Descriptions || len(c.colorKeys()) > 0
+}
+
+// popupColorKey ties a [completion] key to its go-prompt option
+type popupColorKey struct {
+	key    string
+	value  *string
+	option func(prompt.Color) prompt.Option
+}
+
+// colorSettings lists every color setting, with pointers into c
+func (c *PopupConfig) colorSettings() []popupColorKey {
+	return []popupColorKey{
+		{"suggestion_text", &c.SuggestionText, prompt.OptionSuggestionTextColor},
+		{"suggestion_bg", &c.SuggestionBG, prompt.OptionSuggestionBGColor},
+		{"selected_text", &c.SelectedText, prompt.OptionSelectedSuggestionTextColor},
+		{"selected_bg", &c.SelectedBG, prompt.OptionSelectedSuggestionBGColor},
+		{"description_text", &c.DescriptionText, prompt.OptionDescriptionTextColor},
+		{"description_bg", &c.DescriptionBG, prompt.OptionDescriptionBGColor},
+		{"selected_description_text", &c.SelectedDescriptionText, prompt.OptionSelectedDescriptionTextColor},
+		{"selected_description_bg", &c.SelectedDescriptionBG, prompt.OptionSelectedDescriptionBGColor},
+		{"scrollbar", &c.Scrollbar, prompt.OptionScrollbarThumbColor},
+		{"scrollbar_bg", &c.ScrollbarBG, prompt.OptionScrollbarBGColor},
+	}
+}
+
+// colorKeys lists the color settings that are set
+func (c *PopupConfig) colorKeys() []popupColorKey {
+	var set []popupColorKey
+	for _, k := range c.colorSettings() {
+		if *k.value != "" {
+			set = append(set, k)
+		}
+	}
+	return set
+}
+
+// popupColors maps the color names accepted in [completion] to go-prompt's
+// 16 terminal colors
+var popupColors = map[string]prompt.Color{
+	"default":   prompt.DefaultColor,
+	"black":     prompt.Black,
+	"darkred":   prompt.DarkRed,
+	"darkgreen": prompt.DarkGreen,
+	"brown":     prompt.Brown,
+	"darkblue":  prompt.DarkBlue,
+	"purple":    prompt.Purple,
+	"cyan":      prompt.Cyan,
+	"lightgray": prompt.LightGray,
+	"darkgray":  prompt.DarkGray,
+	"red":       prompt.Red,
+	"green":     prompt.Green,
+	"yellow":    prompt.Yellow,
+	"blue":      prompt.Blue,
+	"fuchsia":   prompt.Fuchsia,
+	"turquoise": prompt.Turquoise,
+	"white":     prompt.White,
+}
+
+// parsePopupColor looks up a color name, ignoring case, "-", "_" and spaces
+func parsePopupColor(name string) (prompt.Color, bool) {
+	name = strings.ToLower(strings.NewReplacer("-", "", "_", "", " ", "").Replace(name))
+	c, ok := popupColors[name]
+	return c, ok
+}
+
+// promptOptions returns the go-prompt options for the popup. Unknown color
+// names are reported and skipped.
+func (c PopupConfig) promptOptions() []prompt.Option {
+	var options []prompt.Option
+	if c.MaxRows > 0 {
+		options = append(options, prompt.OptionMaxSuggestion(uint16(c.MaxRows)))
+	}
+	for _, k := range c.colorKeys() {
+		color, ok := parsePopupColor(*k.value)
+		if !ok {
+			fmt.Printf("Warning: unknown color %q for %s in [completion]\n", *k.value, k.key)
+			continue
+		}
+		options = append(options, k.option(color))
+	}
+	return options
+}
+
+// withoutDescriptions wraps a completer so the popup shows names only
+func withoutDescriptions(completer prompt.Completer) prompt.Completer {
+	return func(d prompt.Document) []prompt.Suggest {
+		suggestions := completer(d)
+		for i := range suggestions {
+			suggestions[i].Description = ""
+		}
+		return suggestions
+	}
+}
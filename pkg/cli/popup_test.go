@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/c-bata/go-prompt"
+)
+
+func TestParsePopupColor(t *testing.T) {
+	tests := map[string]prompt.Color{
+		"white":      prompt.White,
+		"DarkBlue":   prompt.DarkBlue,
+		"light-gray": prompt.LightGray,
+		"dark_gray":  prompt.DarkGray,
+		"default":    prompt.DefaultColor,
+	}
+	for name, want := range tests {
+		if got, ok := parsePopupColor(name); !ok || got != want {
+			t.Errorf("parsePopupColor(%q) = %v, %v, want %v", name, got, ok, want)
+		}
+	}
+	if _, ok := parsePopupColor("#ff0000"); ok {
+		t.Errorf("hex colors are not supported by go-prompt")
+	}
+}
+
+func TestLoadSyntaxConfigCompletion(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), ".go-myclirc")
+	content := "[completion]\nmax_rows = 4\ndescriptions = false\nselected_bg = darkblue\nselected_text = white\n"
+	if err := os.WriteFile(rc, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GO_MYCLI_RC", rc)
+
+	cfg := LoadSyntaxConfig()
+	if cfg.Popup.MaxRows != 4 || cfg.Popup.Descriptions || cfg.Popup.SelectedBG != "darkblue" || cfg.Popup.SelectedText != "white" {
+		t.Errorf("unexpected completion config: %+v", cfg.Popup)
+	}
+	if !cfg.Popup.Enabled() {
+		t.Errorf("completion config should be enabled")
+	}
+	// max_rows plus two colors
+	if got := len(cfg.Popup.promptOptions()); got != 3 {
+		t.Errorf("promptOptions returned %d options, want 3", got)
+	}
+
+	if DefaultSyntaxConfig().Popup.Enabled() {
+		t.Errorf("default completion config should not be enabled")
+	}
+}
+
+func TestWithoutDescriptions(t *testing.T) {
+	completer := withoutDescriptions(func(prompt.Document) []prompt.Suggest {
+		return []prompt.Suggest{{Text: "orders", Description: "Table"}}
+	})
+	if got := completer(prompt.Document{}); got[0].Description != "" || got[0].Text != "orders" {
+		t.Errorf("withoutDescriptions = %+v", got)
+	}
+}
@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// portForwardTimeout bounds how long kubectl port-forward may take to start
+// listening
+const portForwardTimeout = 20 * time.Second
+
+// kubeForward is the service named with --k8s-service, reached through
+// kubectl port-forward in the --k8s-context and --k8s-namespace given
+var kubeForward struct {
+	Context   string
+	Namespace string
+	Service   string
+}
+
+// activeForward is the port-forward Start opened for --k8s-service
+var activeForward *portForward
+
+// SetKubernetesForward sets the service to connect through kubectl
+// port-forward (--k8s-service), and the kubeconfig context and namespace it
+// is in (--k8s-context, --k8s-namespace); empty ones are kubectl's current
+func SetKubernetesForward(context, namespace, service string) {
+	kubeForward.Context, kubeForward.Namespace, kubeForward.Service = context, namespace, service
+}
+
+// kubeTarget returns the resource and port kubectl forwards to. A bare name
+// is a service; pod/name, deployment/name and statefulset/name are taken as
+// they are. A :port suffix wins over port, which defaults to 3306.
+func kubeTarget(service string, port int) (string, int, error) {
+	resource, portText, hasPort := strings.Cut(strings.TrimSpace(service), ":")
+	if hasPort {
+		p, err := strconv.Atoi(portText)
+		if err != nil || p <= 0 || p > 65535 {
+			return "", 0, fmt.Errorf("--k8s-service: bad port %q", portText)
+		}
+		port = p
+	}
+	if resource == "" {
+		return "", 0, fmt.Errorf("--k8s-service: no service name")
+	}
+	if !strings.Contains(resource, "/") {
+		resource = "svc/" + resource
+	}
+	if port == 0 {
+		port = 3306
+	}
+	return resource, port, nil
+}
+
+// kubectlArgs are the arguments of the kubectl port-forward that listens on
+// localPort of 127.0.0.1 only
+func kubectlArgs(context, namespace, resource string, localPort, remotePort int) []string {
+	var args []string
+	if context != "" {
+		args = append(args, "--context", context)
+	}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+	return append(args, "port-forward", "--address", "127.0.0.1", resource, fmt.Sprintf("%d:%d", localPort, remotePort))
+}
+
+// portForward is a running kubectl port-forward
+type portForward struct {
+	Resource string
+	Port     int // the local port on 127.0.0.1
+	cmd      *exec.Cmd
+	exited   chan struct{}
+	closing  chan struct{}
+}
+
+// freeLocalPort returns a port of 127.0.0.1 nothing listens on
+func freeLocalPort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// startPortForward runs kubectl port-forward to the --k8s-service and
+// returns once it listens. kubectl runs in a process group of its own, so
+// the Ctrl-C that cancels a statement does not end the forward; if the
+// forward ends on its own (the pod went away) that is reported.
+func startPortForward(remotePort int) (*portForward, error) {
+	kubectl, err := exec.LookPath("kubectl")
+	if err != nil {
+		return nil, fmt.Errorf("--k8s-service needs kubectl in PATH: %w", err)
+	}
+	resource, remotePort, err := kubeTarget(kubeForward.Service, remotePort)
+	if err != nil {
+		return nil, err
+	}
+	localPort, err := freeLocalPort()
+	if err != nil {
+		return nil, fmt.Errorf("no free local port for the port-forward: %w", err)
+	}
+
+	f := &portForward{Resource: resource, Port: localPort, exited: make(chan struct{}), closing: make(chan struct{})}
+	f.cmd = exec.Command(kubectl, kubectlArgs(kubeForward.Context, kubeForward.Namespace, resource, localPort, remotePort)...)
+	var stderr bytes.Buffer
+	listening := &forwardOutput{ready: make(chan struct{})}
+	ready := listening.ready
+	f.cmd.Stdout, f.cmd.Stderr = listening, &stderr
+	detachProcessGroup(f.cmd)
+	if err := f.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("kubectl port-forward: %w", err)
+	}
+
+	go func() {
+		err := f.cmd.Wait()
+		close(f.exited)
+		select {
+		case <-f.closing:
+			return
+		default:
+		}
+		select {
+		case <-ready:
+			fmt.Fprintf(os.Stderr, "\nPort-forward to %s ended (%v); new connections fail until go-mycli is restarted\n", resource, err)
+		default:
+		}
+	}()
+
+	select {
+	case <-ready:
+		return f, nil
+	case <-f.exited:
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = "kubectl exited"
+		}
+		return nil, fmt.Errorf("kubectl port-forward %s: %s", resource, msg)
+	case <-time.After(portForwardTimeout):
+		f.Close()
+		return nil, fmt.Errorf("kubectl port-forward %s: not listening after %s", resource, portForwardTimeout)
+	}
+}
+
+// forwardOutput reads kubectl's output for the line it prints once it
+// listens
+type forwardOutput struct {
+	once  sync.Once
+	ready chan struct{}
+}
+
+func (o *forwardOutput) Write(b []byte) (int, error) {
+	if bytes.Contains(b, []byte("Forwarding from")) {
+		o.once.Do(func() { close(o.ready) })
+	}
+	return len(b), nil
+}
+
+// Close stops the port-forward
+func (f *portForward) Close() {
+	if f == nil {
+		return
+	}
+	select {
+	case <-f.closing:
+		return
+	default:
+		close(f.closing)
+	}
+	_ = f.cmd.Process.Kill()
+	<-f.exited
+}
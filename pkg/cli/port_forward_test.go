@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKubeTarget(t *testing.T) {
+	tests := []struct {
+		service  string
+		port     int
+		resource string
+		want     int
+	}{
+		{"mysql", 0, "svc/mysql", 3306},
+		{"mysql", 3307, "svc/mysql", 3307},
+		{"mysql:3308", 3307, "svc/mysql", 3308},
+		{"pod/mysql-0", 0, "pod/mysql-0", 3306},
+		{"statefulset/mysql:33060", 0, "statefulset/mysql", 33060},
+	}
+	for _, tt := range tests {
+		resource, port, err := kubeTarget(tt.service, tt.port)
+		if err != nil {
+			t.Errorf("kubeTarget(%q) error: %v", tt.service, err)
+			continue
+		}
+		if resource != tt.resource || port != tt.want {
+			t.Errorf("kubeTarget(%q, %d) = %s, %d, want %s, %d", tt.service, tt.port, resource, port, tt.resource, tt.want)
+		}
+	}
+	for _, bad := range []string{"", ":3306", "mysql:", "mysql:http", "mysql:70000"} {
+		if _, _, err := kubeTarget(bad, 0); err == nil {
+			t.Errorf("kubeTarget(%q) should fail", bad)
+		}
+	}
+}
+
+func TestKubectlArgs(t *testing.T) {
+	got := kubectlArgs("prod", "db", "svc/mysql", 40001, 3306)
+	want := []string{"--context", "prod", "--namespace", "db", "port-forward", "--address", "127.0.0.1", "svc/mysql", "40001:3306"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("kubectlArgs = %q, want %q", got, want)
+	}
+	got = kubectlArgs("", "", "pod/mysql-0", 40001, 3306)
+	want = []string{"port-forward", "--address", "127.0.0.1", "pod/mysql-0", "40001:3306"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("kubectlArgs without context = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// syntaxProblem is something the pre-flight check found in a statement
+type syntaxProblem struct {
+	Offset  int // byte offset in the statement
+	Message string
+}
+
+// sqlWord is a keyword or identifier outside strings and comments
+type sqlWord struct {
+	Upper  string
+	Offset int
+	Depth  int // parentheses open around it
+}
+
+// clauseKeywords start a clause, so a comma or a condition keyword right
+// before them means something is missing
+var clauseKeywords = map[string]bool{
+	"FROM": true, "WHERE": true, "GROUP": true, "ORDER": true, "HAVING": true, "LIMIT": true,
+}
+
+// conditionKeywords must be followed by an expression
+var conditionKeywords = map[string]bool{
+	"WHERE": true, "AND": true, "OR": true, "ON": true, "SET": true, "HAVING": true,
+}
+
+// checkSyntax looks for mistakes that are certain without asking the server:
+// unbalanced parentheses and quotes, a comma or condition keyword with nothing
+// after it, repeated keywords and statements missing the clause they need.
+// It is a hint, not a parser: anything it does not recognize passes.
+func checkSyntax(stmt string) []syntaxProblem {
+	var problems []syntaxProblem
+	var st sqlState
+	var open []int // offsets of unclosed parentheses
+	var words []sqlWord
+	comma := -1 // a comma with nothing but whitespace after it so far
+	quoteStart, commentStart := -1, -1
+	for i := 0; i < len(stmt); {
+		c := stmt[i]
+		inText := st.quote != 0 || st.blockComment
+		next, lineComment := st.advance(stmt, i)
+		switch {
+		case inText || lineComment:
+		case st.quote != 0:
+			quoteStart, comma = i, -1
+		case st.blockComment:
+			commentStart = i
+		case c == '(':
+			open = append(open, i)
+			comma = -1
+		case c == ')':
+			if comma >= 0 {
+				problems = append(problems, syntaxProblem{comma, "comma before )"})
+				comma = -1
+			}
+			if len(open) == 0 {
+				problems = append(problems, syntaxProblem{i, "unmatched )"})
+			} else {
+				open = open[:len(open)-1]
+			}
+		case c == ',':
+			comma = i
+		case isWordByte(c):
+			for next < len(stmt) && isWordByte(stmt[next]) {
+				next++
+			}
+			w := sqlWord{Upper: strings.ToUpper(stmt[i:next]), Offset: i, Depth: len(open)}
+			if comma >= 0 && clauseKeywords[w.Upper] {
+				problems = append(problems, syntaxProblem{comma, "comma before " + w.Upper})
+			}
+			comma = -1
+			words = append(words, w)
+		case c != ' ' && c != '\t' && c != '\n' && c != '\r':
+			comma = -1
+		}
+		i = next
+	}
+	if comma >= 0 {
+		problems = append(problems, syntaxProblem{comma, "comma at the end of the statement"})
+	}
+	switch {
+	case st.quote != 0:
+		problems = append(problems, syntaxProblem{quoteStart, fmt.Sprintf("unclosed %c quote", st.quote)})
+	case st.blockComment:
+		problems = append(problems, syntaxProblem{commentStart, "unclosed /* comment"})
+	}
+	for _, offset := range open {
+		problems = append(problems, syntaxProblem{offset, "unclosed ("})
+	}
+	problems = append(problems, checkKeywords(stmt, words)...)
+	slices.SortStableFunc(problems, func(a, b syntaxProblem) int { return cmp.Compare(a.Offset, b.Offset) })
+	return problems
+}
+
+// isWordByte reports whether c can be part of a keyword or identifier; bytes
+// of multi-byte characters count as letters
+func isWordByte(c byte) bool {
+	return c >= 0x80 || c == '_' || c == '$' || (c >= '0' && c <= '9') || (c|0x20 >= 'a' && c|0x20 <= 'z')
+}
+
+// checkKeywords looks at the words of a statement for missing clauses
+func checkKeywords(stmt string, words []sqlWord) []syntaxProblem {
+	var problems []syntaxProblem
+	for i, w := range words {
+		var next *sqlWord
+		if i+1 < len(words) {
+			next = &words[i+1]
+		}
+		if next != nil && next.Upper == w.Upper && (clauseKeywords[w.Upper] || conditionKeywords[w.Upper] || w.Upper == "SELECT") {
+			problems = append(problems, syntaxProblem{next.Offset, "repeated " + w.Upper})
+			continue
+		}
+		// A condition keyword followed by a clause, a closing parenthesis or
+		// the end has nothing to test
+		between := strings.Trim(stmtBetween(stmt, w, next), " \t\r\n)")
+		if conditionKeywords[w.Upper] && between == "" && (next == nil || next.Depth < w.Depth || clauseKeywords[next.Upper]) {
+			problems = append(problems, syntaxProblem{w.Offset, "nothing after " + w.Upper})
+		}
+	}
+
+	var top []sqlWord
+	for _, w := range words {
+		if w.Depth == 0 {
+			top = append(top, w)
+		}
+	}
+	if len(top) == 0 {
+		return problems
+	}
+	has := func(in []sqlWord, keywords ...string) bool {
+		for _, w := range in[1:] {
+			for _, k := range keywords {
+				if w.Upper == k {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	first := top[0]
+	switch first.Upper {
+	case "SELECT":
+		if strings.HasPrefix(strings.TrimSpace(stmt[first.Offset+len(first.Upper):]), "*") && !has(top, "FROM") {
+			problems = append(problems, syntaxProblem{first.Offset, "SELECT * without FROM"})
+		}
+	case "UPDATE":
+		if !has(top, "SET") {
+			problems = append(problems, syntaxProblem{first.Offset, "UPDATE without SET"})
+		}
+	case "DELETE":
+		if !has(top, "FROM") {
+			problems = append(problems, syntaxProblem{first.Offset, "DELETE without FROM"})
+		}
+	case "INSERT", "REPLACE":
+		// VALUES or SELECT may come inside parentheses
+		if !has(words, "VALUES", "VALUE", "SELECT", "SET", "TABLE", "WITH") {
+			problems = append(problems, syntaxProblem{first.Offset, first.Upper + " without VALUES or SELECT"})
+		}
+	}
+	return problems
+}
+
+// stmtBetween returns the text after word w up to the next word, or to the
+// end of the statement
+func stmtBetween(stmt string, w sqlWord, next *sqlWord) string {
+	end := len(stmt)
+	if next != nil {
+		end = next.Offset
+	}
+	return stmt[w.Offset+len(w.Upper) : end]
+}
+
+// formatSyntaxProblems shows each problem under its line of the statement,
+// with the line number in the margin and a caret at the problem
+func formatSyntaxProblems(stmt string, problems []syntaxProblem) string {
+	lines := strings.Split(stmt, "\n")
+	width := len(fmt.Sprint(len(lines)))
+	var b strings.Builder
+	for _, pr := range problems {
+		row := strings.Count(stmt[:pr.Offset], "\n")
+		col := pr.Offset - (strings.LastIndexByte(stmt[:pr.Offset], '\n') + 1)
+		line := lines[row]
+		if colorEnabled() && col < len(line) {
+			line = line[:col] + "\033[1;31m" + line[col:col+1] + "\033[0m" + line[col+1:]
+		}
+		fmt.Fprintf(&b, "%*d | %s\n", width, row+1, line)
+		fmt.Fprintf(&b, "%*s | %s^ %s\n", width, "", strings.Repeat(" ", len([]rune(lines[row][:col]))), pr.Message)
+	}
+	return b.String()
+}
+
+// confirmSyntax runs the pre-flight check on a statement typed at the prompt
+// and, if it finds something, shows it and asks before sending. It returns
+// false if the user declined.
+func (p *PromptExecutor) confirmSyntax(stmt string) bool {
+	if !p.syntaxCheck || p.input == nil || p.sourceFileMode {
+		return true
+	}
+	problems := checkSyntax(stmt)
+	if len(problems) == 0 {
+		return true
+	}
+	fmt.Print(formatSyntaxProblems(stmt, problems))
+	return askYesNo("Send anyway? [y/N] ")
+}
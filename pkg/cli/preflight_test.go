@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckSyntax(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want []syntaxProblem
+	}{
+		{"SELECT id, name FROM users WHERE id IN (1, 2)", nil},
+		{"SELECT ')' FROM t -- (", nil},
+		{"SELECT COUNT(* FROM t", []syntaxProblem{{12, "unclosed ("}}},
+		{"SELECT a) FROM t", []syntaxProblem{{8, "unmatched )"}}},
+		{"SELECT 'abc FROM t", []syntaxProblem{{7, "unclosed ' quote"}}},
+		{"SELECT a, FROM t", []syntaxProblem{{8, "comma before FROM"}}},
+		{"SELECT a,\n  b,\nFROM t", []syntaxProblem{{13, "comma before FROM"}}},
+		{"SELECT * FROM t WHERE", []syntaxProblem{{16, "nothing after WHERE"}}},
+		{"SELECT * FROM t WHERE a = 1 AND ORDER BY a", []syntaxProblem{{28, "nothing after AND"}}},
+		{"SELECT * FROM FROM t", []syntaxProblem{{14, "repeated FROM"}}},
+		{"SELECT * WHERE id = 1", []syntaxProblem{{0, "SELECT * without FROM"}}},
+		{"SELECT 1 + 1", nil},
+		{"UPDATE t WHERE id = 1", []syntaxProblem{{0, "UPDATE without SET"}}},
+		{"DELETE t WHERE id IN (SELECT id FROM u)", []syntaxProblem{{0, "DELETE without FROM"}}},
+		{"DELETE t FROM t JOIN u USING (id)", nil},
+		{"INSERT INTO t (a, b)", []syntaxProblem{{0, "INSERT without VALUES or SELECT"}}},
+		{"INSERT INTO t (a) (SELECT a FROM u)", nil},
+		{"UPDATE t SET a = 1 WHERE b IN (1, 2,)", []syntaxProblem{{35, "comma before )"}}},
+	}
+	for _, tt := range tests {
+		if got := checkSyntax(tt.stmt); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("checkSyntax(%q) = %v, want %v", tt.stmt, got, tt.want)
+		}
+	}
+}
+
+func TestFormatSyntaxProblems(t *testing.T) {
+	saved := noColor
+	defer func() { noColor = saved }()
+	noColor = true
+	stmt := "SELECT a,\nFROM t"
+	got := formatSyntaxProblems(stmt, checkSyntax(stmt))
+	want := "1 | SELECT a,\n  |         ^ comma before FROM\n"
+	if got != want {
+		t.Errorf("formatSyntaxProblems =\n%s\nwant\n%s", got, want)
+	}
+}
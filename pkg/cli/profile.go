@@ -0,0 +1,338 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Profiling sources for \profile
+const (
+	profilePerfSchema  = "performance_schema"
+	profileShowProfile = "SHOW PROFILE"
+)
+
+// stageTiming is the time a statement spent in one execution stage
+type stageTiming struct {
+	Stage    string
+	Duration time.Duration
+}
+
+// profileState remembers what \profile on changed so \profile off can put
+// it back
+type profileState struct {
+	source      string   // profilePerfSchema, profileShowProfile or "" when off
+	consumers   []string // performance_schema consumers that were enabled for profiling
+	instruments bool     // whether stage instruments were enabled for profiling
+	detail      bool     // \profile detail: also show memory and temporary tables
+	memory      bool     // whether statement events record MAX_TOTAL_MEMORY (MySQL 8.0.31+)
+}
+
+// statementUsage is what a statement used besides time, from its row in
+// events_statements_history_long
+type statementUsage struct {
+	TmpTables       int64
+	TmpDiskTables   int64
+	SortMergePasses int64
+	MaxMemory       int64 // peak memory of the statement, -1 when the server does not record it
+}
+
+// setProfile handles \profile [on|detail|off]
+func (p *PromptExecutor) setProfile(args string) {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "":
+		switch {
+		case p.profile.source == "":
+			fmt.Println("Profiling is off")
+		case p.profile.detail:
+			fmt.Printf("Profiling is on (%s, with memory and temporary tables)\n", p.profile.source)
+		default:
+			fmt.Printf("Profiling is on (%s)\n", p.profile.source)
+		}
+	case "on":
+		if p.profile.detail {
+			p.profile.detail = false
+			fmt.Println("Profile detail off; stage timings are still shown")
+			return
+		}
+		if p.profile.source != "" {
+			fmt.Printf("Profiling is already on (%s)\n", p.profile.source)
+			return
+		}
+		if err := p.enablePerfSchemaProfiling(); err == nil {
+			p.profile.source = profilePerfSchema
+		} else if _, err2 := p.db.Exec("SET profiling = 1"); err2 == nil {
+			fmt.Printf("performance_schema stages unavailable (%v); using SHOW PROFILE\n", err)
+			p.profile.source = profileShowProfile
+		} else {
+			fmt.Printf("Profiling unavailable: %v\n", err)
+			return
+		}
+		fmt.Printf("Profiling on (%s): stage timings are shown after each statement\n", p.profile.source)
+	case "detail":
+		p.enableProfileDetail()
+	case "off":
+		p.disableProfiling()
+		fmt.Println("Profiling off")
+	default:
+		fmt.Println("Usage: \\profile [on|detail|off]")
+	}
+}
+
+// enableProfileDetail handles \profile detail. Memory and temporary table
+// counts are kept with the statement events of performance_schema; SHOW
+// PROFILE has neither.
+func (p *PromptExecutor) enableProfileDetail() {
+	switch p.profile.source {
+	case profileShowProfile:
+		fmt.Println("Profile detail needs performance_schema, which is unavailable here; SHOW PROFILE has no temporary table counts")
+		return
+	case "":
+		if err := p.enablePerfSchemaProfiling(); err != nil {
+			fmt.Printf("Profile detail unavailable: %v\n", err)
+			return
+		}
+		p.profile.source = profilePerfSchema
+	}
+	var columns int
+	_ = p.db.QueryRow(`SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLUMNS
+		WHERE TABLE_SCHEMA = 'performance_schema' AND TABLE_NAME = 'events_statements_history_long'
+		AND COLUMN_NAME = 'MAX_TOTAL_MEMORY'`).Scan(&columns)
+	p.profile.memory = columns > 0
+	p.profile.detail = true
+	fmt.Println("Profiling on (performance_schema): stage timings, temporary tables and memory are shown after each statement")
+	if !p.profile.memory {
+		fmt.Println("This server does not record memory per statement (MySQL 8.0.31 and later do); only temporary tables are shown")
+	}
+}
+
+// enablePerfSchemaProfiling makes sure statement and stage events are kept in
+// the history tables, enabling the consumers and stage instruments if needed.
+// These settings are server-wide, so what was changed is remembered and
+// restored by \profile off.
+func (p *PromptExecutor) enablePerfSchemaProfiling() error {
+	var enabled sql.NullString
+	if err := p.db.QueryRow("SELECT @@performance_schema").Scan(&enabled); err != nil {
+		return err
+	}
+	if !isOn(enabled.String) {
+		return fmt.Errorf("performance_schema is disabled")
+	}
+
+	for _, consumer := range []string{"events_statements_history_long", "events_stages_history_long"} {
+		var state string
+		err := p.db.QueryRow("SELECT ENABLED FROM performance_schema.setup_consumers WHERE NAME = ?", consumer).Scan(&state)
+		if err != nil {
+			return err
+		}
+		if state == "YES" {
+			continue
+		}
+		if _, err := p.db.Exec("UPDATE performance_schema.setup_consumers SET ENABLED = 'YES' WHERE NAME = ?", consumer); err != nil {
+			p.restorePerfSchema()
+			return fmt.Errorf("cannot enable consumer %s: %w", consumer, err)
+		}
+		p.profile.consumers = append(p.profile.consumers, consumer)
+	}
+
+	var disabled int
+	err := p.db.QueryRow("SELECT COUNT(*) FROM performance_schema.setup_instruments WHERE NAME LIKE 'stage/sql/%' AND (ENABLED = 'NO' OR TIMED = 'NO')").Scan(&disabled)
+	if err != nil {
+		p.restorePerfSchema()
+		return err
+	}
+	if disabled > 0 {
+		if _, err := p.db.Exec("UPDATE performance_schema.setup_instruments SET ENABLED = 'YES', TIMED = 'YES' WHERE NAME LIKE 'stage/sql/%'"); err != nil {
+			p.restorePerfSchema()
+			return fmt.Errorf("cannot enable stage instruments: %w", err)
+		}
+		p.profile.instruments = true
+	}
+	if len(p.profile.consumers) > 0 || p.profile.instruments {
+		fmt.Println("Enabled performance_schema stage instrumentation (server-wide until \\profile off)")
+	}
+	return nil
+}
+
+// restorePerfSchema turns off what enablePerfSchemaProfiling turned on
+func (p *PromptExecutor) restorePerfSchema() {
+	for _, consumer := range p.profile.consumers {
+		_, _ = p.db.Exec("UPDATE performance_schema.setup_consumers SET ENABLED = 'NO' WHERE NAME = ?", consumer)
+	}
+	if p.profile.instruments {
+		_, _ = p.db.Exec("UPDATE performance_schema.setup_instruments SET ENABLED = 'NO', TIMED = 'NO' WHERE NAME LIKE 'stage/sql/%'")
+	}
+	p.profile.consumers, p.profile.instruments = nil, false
+}
+
+func (p *PromptExecutor) disableProfiling() {
+	switch p.profile.source {
+	case profilePerfSchema:
+		p.restorePerfSchema()
+	case profileShowProfile:
+		_, _ = p.db.Exec("SET profiling = 0")
+	}
+	p.profile.source, p.profile.detail = "", false
+}
+
+// showProfile prints the stage breakdown of the statement that just ran
+func (p *PromptExecutor) showProfile(stmt string) {
+	var stages []stageTiming
+	var err error
+	switch p.profile.source {
+	case profilePerfSchema:
+		stages, err = p.perfSchemaStages(stmt)
+	case profileShowProfile:
+		stages, err = p.showProfileStages()
+	default:
+		return
+	}
+	if err != nil {
+		fmt.Printf("Profile unavailable: %v\n", err)
+		return
+	}
+	if len(stages) == 0 {
+		fmt.Println("Profile: no stages recorded for this statement")
+	} else {
+		fmt.Print(formatProfile(aggregateStages(stages)))
+	}
+	if !p.profile.detail {
+		return
+	}
+	usage, err := p.perfSchemaUsage(stmt)
+	if err != nil {
+		fmt.Printf("Memory and temporary tables unavailable: %v\n", err)
+		return
+	}
+	fmt.Print(formatUsage(usage))
+}
+
+// perfSchemaUsage reads the temporary tables, sort merge passes and peak
+// memory of the latest execution of stmt, matched by text as the stages are
+func (p *PromptExecutor) perfSchemaUsage(stmt string) (statementUsage, error) {
+	memory := "-1"
+	if p.profile.memory {
+		memory = "MAX_TOTAL_MEMORY"
+	}
+	u := statementUsage{}
+	err := p.db.QueryRow(`SELECT CREATED_TMP_TABLES, CREATED_TMP_DISK_TABLES, SORT_MERGE_PASSES, `+memory+`
+FROM performance_schema.events_statements_history_long
+WHERE SQL_TEXT = ? ORDER BY TIMER_START DESC LIMIT 1`, stmt).Scan(&u.TmpTables, &u.TmpDiskTables, &u.SortMergePasses, &u.MaxMemory)
+	if err == sql.ErrNoRows {
+		return u, fmt.Errorf("the statement is not in events_statements_history_long")
+	}
+	return u, err
+}
+
+// formatUsage describes a statement's temporary tables and memory, warning
+// about temporary tables that went to disk
+func formatUsage(u statementUsage) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Temporary tables: %d", u.TmpTables)
+	if u.TmpTables > 0 {
+		fmt.Fprintf(&b, " (%d on disk)", u.TmpDiskTables)
+	}
+	if u.SortMergePasses > 0 {
+		fmt.Fprintf(&b, ", sort merge passes: %d", u.SortMergePasses)
+	}
+	if u.MaxMemory >= 0 {
+		fmt.Fprintf(&b, ", peak memory: %s", formatBytes(u.MaxMemory))
+	}
+	b.WriteString("\n")
+	if u.TmpDiskTables > 0 {
+		fmt.Fprintf(&b, "Warning: %d temporary table%s went to disk. It outgrew tmp_table_size (temptable_max_ram for the TempTable engine) "+
+			"or held columns the in-memory engine cannot; an index that serves the GROUP BY, ORDER BY or DISTINCT avoids it\n",
+			u.TmpDiskTables, plural(int(u.TmpDiskTables)))
+	}
+	return b.String()
+}
+
+// perfSchemaStages reads the stages of the latest execution of stmt. The
+// statement is matched by its text rather than by thread, since it may have
+// run on any connection of the pool.
+func (p *PromptExecutor) perfSchemaStages(stmt string) ([]stageTiming, error) {
+	rows, err := p.db.Query(`SELECT st.EVENT_NAME, st.TIMER_WAIT
+FROM performance_schema.events_stages_history_long st
+JOIN (SELECT THREAD_ID, EVENT_ID FROM performance_schema.events_statements_history_long
+      WHERE SQL_TEXT = ? ORDER BY TIMER_START DESC LIMIT 1) s
+  ON st.THREAD_ID = s.THREAD_ID AND st.NESTING_EVENT_ID = s.EVENT_ID
+ORDER BY st.EVENT_ID`, stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stages []stageTiming
+	for rows.Next() {
+		var name string
+		var picoseconds sql.NullInt64
+		if err := rows.Scan(&name, &picoseconds); err != nil {
+			return nil, err
+		}
+		stages = append(stages, stageTiming{
+			Stage:    strings.TrimPrefix(strings.TrimPrefix(name, "stage/sql/"), "stage/"),
+			Duration: time.Duration(picoseconds.Int64 / 1000),
+		})
+	}
+	return stages, rows.Err()
+}
+
+// showProfileStages reads SHOW PROFILE for the session's latest statement
+func (p *PromptExecutor) showProfileStages() ([]stageTiming, error) {
+	rows, err := p.db.Query("SHOW PROFILE")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stages []stageTiming
+	for rows.Next() {
+		var status, seconds string
+		if err := rows.Scan(&status, &seconds); err != nil {
+			return nil, err
+		}
+		secs, err := strconv.ParseFloat(seconds, 64)
+		if err != nil {
+			continue
+		}
+		stages = append(stages, stageTiming{Stage: status, Duration: time.Duration(secs * float64(time.Second))})
+	}
+	return stages, rows.Err()
+}
+
+// aggregateStages adds up stages that occur more than once, keeping the
+// order in which each stage first appeared
+func aggregateStages(stages []stageTiming) []stageTiming {
+	index := make(map[string]int)
+	var merged []stageTiming
+	for _, s := range stages {
+		if i, ok := index[s.Stage]; ok {
+			merged[i].Duration += s.Duration
+			continue
+		}
+		index[s.Stage] = len(merged)
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// formatProfile renders the stages as a table with each stage's share of the
+// total
+func formatProfile(stages []stageTiming) string {
+	var total time.Duration
+	for _, s := range stages {
+		total += s.Duration
+	}
+	rows := make([][]string, 0, len(stages)+1)
+	for _, s := range stages {
+		share := 0.0
+		if total > 0 {
+			share = float64(s.Duration) * 100 / float64(total)
+		}
+		rows = append(rows, []string{s.Stage, formatLatency(s.Duration), fmt.Sprintf("%.1f%%", share)})
+	}
+	rows = append(rows, []string{"total", formatLatency(total), "100.0%"})
+	return formatMySQLTable([]string{"Stage", "Duration", "Share"}, rows)
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAggregateStages(t *testing.T) {
+	ms := time.Millisecond
+	got := aggregateStages([]stageTiming{
+		{"starting", 1 * ms}, {"executing", 5 * ms}, {"Sending data", 3 * ms}, {"executing", 2 * ms},
+	})
+	if len(got) != 3 || got[1].Stage != "executing" || got[1].Duration != 7*ms {
+		t.Errorf("aggregateStages = %+v", got)
+	}
+}
+
+func TestFormatProfile(t *testing.T) {
+	out := formatProfile([]stageTiming{{"executing", 3 * time.Millisecond}, {"Sorting result", time.Millisecond}})
+	for _, want := range []string{"executing", "75.0%", "Sorting result", "25.0%", "total", "4.000 ms"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("formatProfile output lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestFormatUsage(t *testing.T) {
+	out := formatUsage(statementUsage{TmpTables: 2, TmpDiskTables: 1, MaxMemory: 4404019})
+	for _, want := range []string{"Temporary tables: 2 (1 on disk)", "peak memory: 4.2 MiB", "Warning: 1 temporary table went to disk"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("formatUsage output lacks %q:\n%s", want, out)
+		}
+	}
+	if out := formatUsage(statementUsage{MaxMemory: -1}); out != "Temporary tables: 0\n" {
+		t.Errorf("formatUsage = %q", out)
+	}
+}
@@ -2,17 +2,25 @@ package cli
 
 import (
 	"bufio"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"go-mycli/pkg/ai"
+
 	"github.com/c-bata/go-prompt"
 	"github.com/klauspost/compress/zstd"
+	"github.com/mattn/go-runewidth"
 )
 
 type PromptExecutor struct {
@@ -23,8 +31,13 @@ type PromptExecutor struct {
 	database             string
 	buffer               string
 	tables               []string
-	columns              map[string][]string // table -> columns
+	columns              map[string][]string                // table -> columns
+	columnDetails        map[string]map[string]columnDetail // table -> column -> DESCRIBE row
 	databases            []string
+	showNameCache        map[string][]prompt.Suggest // SHOW statement -> names it lists
+	valueCache           map[string][]prompt.Suggest // database.table.column -> common values
+	foreignKeyCache      map[string][]foreignKey     // database -> its foreign keys, for JOIN ... ON
+	showTemplates        bool                        // Tab on an empty line asked for the query templates
 	cacheTime            time.Time
 	highlighter          *SyntaxHighlighter
 	zstdCompressionLevel int
@@ -34,10 +47,61 @@ type PromptExecutor struct {
 	enableAIAnalysis     bool // enable AI-powered EXPLAIN analysis
 	enableJSONExport     bool // enable JSON export for external tools
 	enableVisualExplain  bool // enable built-in visual explain
+	autoView             bool // open wide results in the result viewer
+	maxFieldWidth        int  // truncate table cells longer than this, 0 for no limit
+	colorResults         bool // color NULLs, numbers and status values in result tables
+	liveHighlight        bool // highlight the input line while typing
 	aiServerURL          string
 	aiServerMode         string
 	aiCachePath          string
+	aiMCPCommand         string
 	aiDetailLevel        string
+	aiClient             ai.AIClient // created on first use, closed by closeAIClient
+	hooks                HookConfig
+	format               FormatConfig
+	input                *inputParser    // interactive terminal reader, nil when not on a TTY
+	lastResult           *Result         // the last result set, for \view and \expand
+	buffers              resultBuffers   // recent results kept for \show (see buffers.go)
+	review               queryReview     // statements by fingerprint for \review-export
+	outputFormat         string          // renderer of query results set with \output, "" for table
+	showWarnings         bool            // print the server's warnings after each statement (\W)
+	showCost             bool            // print Last_query_cost and handler reads after each SELECT (\cost)
+	pager                string          // command set with \pager, "" for $PAGER or less (see pager.go)
+	pagerEnabled         bool            // page results taller than the terminal, off with \nopager
+	ctx                  context.Context // canceled at shutdown (see context.go)
+	cancel               context.CancelFunc
+	interrupted          bool          // the last statement was canceled, which stops a running script
+	historyLines         []string      // lines of the unfinished statement, for whole-statement history
+	usage                *usageStore   // completion usage counters, nil when ranking is off
+	completionMode       string        // auto, full, metadata or off (see completion_mode.go)
+	completionLatency    time.Duration // round trip above which auto mode stops loading metadata
+	slowLink             bool          // auto mode measured a round trip above completionLatency
+	completionMatch      string        // fuzzy, substring or prefix ("" is fuzzy)
+	completionMinScore   int           // drop matches scoring lower
+	completionValues     bool          // complete strings compared with a column from its common values
+	lastLatency          time.Duration
+	server               *serverInfo // flavor and version, detected on first use (see flavor.go)
+	alive                keepalive   // background pings and idle detection (see keepalive.go)
+	profile              profileState
+	rowEstimateWarning   int64      // ask before a SELECT expected to examine more rows, 0 to never ask
+	guard                queryGuard // live timer and long statement alert (see guardian.go)
+	syntaxCheck          bool       // pre-flight syntax hints before sending a statement (see preflight.go)
+	jobs                 jobTable
+	sessionOverrides     map[string]string  // session variables changed with SET, for \save-session
+	aliases              map[string]string  // \name shortcuts from [aliases] and \alias (see aliases.go)
+	aliasDepth           int                // aliases being expanded, to stop alias loops
+	runbooks             map[string]string  // [runbooks] templates for \runbook, besides the built-in ones
+	broadcast            []broadcastTarget  // --servers: every statement runs on all of them
+	statementErrors      int                // statements that failed, so scripts can report them
+	promptRules          []PromptRule       // [prompt] colors and markers by host (see prompt_color.go)
+	prefix               string             // the prompt last returned by livePrefix
+	pastingPlan          bool               // \analyze-paste is reading a plan (see analyze_paste.go)
+	pastedPlan           []string           // the lines of the plan read so far
+	lastExplain          *explainRecord     // the last EXPLAIN run, for \report
+	recording            *workloadRecording // \record capture in progress (see record.go)
+	lag                  lagSettings        // replica lag that heavy writes wait for (see replica_lag.go)
+	lagChecker           *replicaLagChecker // replicas watched for \replica-lag on, connected on first use
+	rc                   *SyntaxConfig      // the config file as last loaded, for \config reload (see config_check.go)
 }
 
 // ExplainNode represents a node in the query execution plan
@@ -64,14 +128,26 @@ type ExplainPlan struct {
 	QueryBlock *ExplainNode `json:"query_block"`
 }
 
-func (p *PromptExecutor) refreshCache() {
+// refreshCache reloads the databases, tables and columns completion offers.
+// What ctx cuts short stays missing until the next refresh.
+func (p *PromptExecutor) refreshCache(ctx context.Context) {
 	// Only refresh cache every 30 seconds to avoid too many queries
 	if time.Since(p.cacheTime) < 30*time.Second {
 		return
 	}
 
+	// Over a slow link auto mode skips metadata so typing stays responsive
+	if p.completionMode == completionAuto {
+		p.measureLatency()
+	}
+	mode := p.activeCompletion()
+	if mode == completionOff {
+		p.cacheTime = time.Now()
+		return
+	}
+
 	// Get databases
-	if rows, err := p.db.Query("SHOW DATABASES"); err == nil {
+	if rows, err := p.db.QueryContext(ctx, "SHOW DATABASES"); err == nil {
 		p.databases = nil
 		for rows.Next() {
 			var db string
@@ -83,7 +159,7 @@ func (p *PromptExecutor) refreshCache() {
 	}
 
 	// Get tables from current database
-	if rows, err := p.db.Query("SHOW TABLES"); err == nil {
+	if rows, err := p.db.QueryContext(ctx, "SHOW TABLES"); err == nil {
 		p.tables = nil
 		for rows.Next() {
 			var table string
@@ -96,18 +172,26 @@ func (p *PromptExecutor) refreshCache() {
 
 	// Get columns for each table
 	p.columns = make(map[string][]string)
+	p.columnDetails = make(map[string]map[string]columnDetail)
+	if mode == completionMetadata {
+		p.cacheTime = time.Now()
+		return
+	}
 	for _, table := range p.tables {
-		if rows, err := p.db.Query(fmt.Sprintf("DESCRIBE `%s`", table)); err == nil {
+		if rows, err := p.db.QueryContext(ctx, "DESCRIBE "+quoteIdentifier(table)); err == nil {
 			var columns []string
+			details := make(map[string]columnDetail)
 			for rows.Next() {
 				var field sql.NullString
 				var typ, null, key sql.NullString
 				var def, extra sql.NullString
 				if rows.Scan(&field, &typ, &null, &key, &def, &extra) == nil && field.Valid {
 					columns = append(columns, field.String)
+					details[field.String] = columnDetail{Type: typ.String, Nullable: null.String == "YES", Default: def, Extra: extra.String}
 				}
 			}
 			p.columns[table] = columns
+			p.columnDetails[table] = details
 			rows.Close()
 		}
 	}
@@ -120,65 +204,108 @@ type scoredSuggestion struct {
 	suggestion prompt.Suggest
 	score      int
 	matchPos   int
+	index      int // position among the suggestions, for a stable order
 }
 
 // findMatches implements fuzzy matching with quality scoring
 func (p *PromptExecutor) findMatches(word string, suggestions []prompt.Suggest) []prompt.Suggest {
+	return p.findRankedMatches(word, suggestions, nil)
+}
+
+// findRankedMatches is findMatches with boost (if not nil) added to each
+// match's score. The minimum score applies before the boost, so usage does
+// not keep a poor match.
+func (p *PromptExecutor) findRankedMatches(word string, suggestions []prompt.Suggest, boost func(prompt.Suggest) int) []prompt.Suggest {
 	if word == "" {
 		return suggestions
 	}
 
+	wordLower := strings.ToLower(word)
+	match := p.matcher(word)
 	var scored []scoredSuggestion
-	wordUpper := strings.ToUpper(word)
+	for i, suggestion := range suggestions {
+		start, end, ok := match(suggestion.Text)
+		if !ok {
+			continue
+		}
+		// Calculate match score (higher is better)
+		score := p.calculateMatchScore(wordLower, suggestion.Text, start, end)
+		if score < p.completionMinScore {
+			continue
+		}
+		if boost != nil {
+			score += boost(suggestion)
+		}
+		scored = append(scored, scoredSuggestion{
+			suggestion: suggestion,
+			score:      score,
+			matchPos:   start,
+			index:      i,
+		})
+	}
+
+	// Sort by score (descending), then by match position (ascending)
+	sort.Slice(scored, func(i, j int) bool {
+		a, b := scored[i], scored[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if a.matchPos != b.matchPos {
+			return a.matchPos < b.matchPos
+		}
+		return a.index < b.index
+	})
+
+	// Extract suggestions
+	matches := make([]prompt.Suggest, len(scored))
+	for i, s := range scored {
+		matches[i] = s.suggestion
+	}
+
+	return matches
+}
+
+// matcher returns a function finding word in a suggestion the way the
+// completion_match setting says, returning where the match starts and ends
+func (p *PromptExecutor) matcher(word string) func(text string) (int, int, bool) {
 	wordLower := strings.ToLower(word)
+	prefix := func(text string) (int, int, bool) {
+		if strings.HasPrefix(strings.ToLower(text), wordLower) {
+			return 0, len(wordLower), true
+		}
+		return 0, 0, false
+	}
+	switch p.completionMatch {
+	case matchPrefix:
+		return prefix
+	case matchSubstring:
+		return func(text string) (int, int, bool) {
+			if i := strings.Index(strings.ToLower(text), wordLower); i >= 0 {
+				return i, i + len(wordLower), true
+			}
+			return 0, 0, false
+		}
+	}
 
 	// Create fuzzy regex pattern: "k.*?e.*?y" for "key"
 	var pattern strings.Builder
-	for i, char := range wordUpper {
+	for i, char := range strings.ToUpper(word) {
 		if i > 0 {
 			pattern.WriteString(".*?")
 		}
 		pattern.WriteString(regexp.QuoteMeta(string(char)))
 	}
-
 	regex, err := regexp.Compile("(?i)" + pattern.String())
 	if err != nil {
 		// If regex fails, fall back to simple prefix matching
-		var matches []prompt.Suggest
-		for _, suggestion := range suggestions {
-			if strings.HasPrefix(strings.ToUpper(suggestion.Text), wordUpper) {
-				matches = append(matches, suggestion)
-			}
-		}
-		return matches
+		return prefix
 	}
-
-	// Find matches using fuzzy regex with scoring
-	for _, suggestion := range suggestions {
-		suggUpper := strings.ToUpper(suggestion.Text)
-
-		if match := regex.FindStringIndex(suggUpper); match != nil {
-			// Calculate match score (higher is better)
-			score := p.calculateMatchScore(wordLower, suggestion.Text, match[0], match[1])
-
-			scored = append(scored, scoredSuggestion{
-				suggestion: suggestion,
-				score:      score,
-				matchPos:   match[0],
-			})
+	return func(text string) (int, int, bool) {
+		if m := regex.FindStringIndex(strings.ToUpper(text)); m != nil {
+			return m[0], m[1], true
 		}
+		return 0, 0, false
 	}
-
-	// Sort by score (descending), then by match position (ascending)
-	p.sortScoredSuggestions(scored)
-
-	// Extract suggestions
-	matches := make([]prompt.Suggest, len(scored))
-	for i, s := range scored {
-		matches[i] = s.suggestion
-	}
-
-	return matches
 }
 
 // calculateMatchScore computes a quality score for a match
@@ -212,24 +339,12 @@ func (p *PromptExecutor) calculateMatchScore(word, suggestion string, matchStart
 	return score
 }
 
-// sortScoredSuggestions sorts suggestions by score and position
-func (p *PromptExecutor) sortScoredSuggestions(scored []scoredSuggestion) {
-	for i := 0; i < len(scored); i++ {
-		for j := i + 1; j < len(scored); j++ {
-			// Sort by score (descending), then position (ascending)
-			if scored[i].score < scored[j].score ||
-				(scored[i].score == scored[j].score && scored[i].matchPos > scored[j].matchPos) {
-				scored[i], scored[j] = scored[j], scored[i]
-			}
-		}
-	}
-}
-
-func (p *PromptExecutor) ExecuteSQL(sql string, useVertical bool) {
+func (p *PromptExecutor) ExecuteSQL(ctx context.Context, sql string, useVertical bool) {
 	sql = strings.TrimSpace(sql)
 	if sql == "" {
 		return
 	}
+	p.ensureConnection()
 
 	// Handle \G vertical output format (must be done before DESC conversion)
 	// Note: useVertical is now passed from the caller, but we still check for \G in case it's embedded
@@ -245,134 +360,151 @@ func (p *PromptExecutor) ExecuteSQL(sql string, useVertical bool) {
 		sql = "DESCRIBE " + remaining
 	}
 
-	// Check if it's a query (returns rows) or statement (affects rows)
-	// Use Contains instead of HasPrefix to handle comments before the actual SQL
+	if !p.confirmSyntax(sql) {
+		fmt.Println("Query not run")
+		return
+	}
+
+	if len(p.broadcast) > 0 {
+		p.executeBroadcast(ctx, sql, useVertical)
+		return
+	}
+
+	sql = p.adaptStatement(sql)
+	if !p.confirmRowEstimate(sql) {
+		fmt.Println("Query not run")
+		return
+	}
+	if !p.waitForReplicas(ctx, sql) {
+		fmt.Println("Query not run")
+		return
+	}
+
+	p.runPreQueryHooks(sql)
+	p.watchStatement()
+	start := time.Now()
+
+	var err error
+	if returnsRows(sql) {
+		err = p.executeQuery(ctx, sql, useVertical)
+	} else {
+		err = p.executeStatement(ctx, sql)
+	}
+	p.stopWatch()
+
+	p.interrupted = errors.Is(err, errCanceled)
+	p.runPostQueryHooks(sql, time.Since(start), err)
+	p.recordStatement(sql, start, time.Since(start), err)
+	if err != nil {
+		p.statementErrors++
+	} else {
+		p.recordUsage(sql)
+		p.review.add(sql, p.database, start, time.Since(start))
+		p.trackSessionSet(sql)
+		p.showProfile(sql)
+	}
+}
+
+// returnsRows reports whether a statement is a query, which returns rows,
+// rather than a statement that affects rows. Contains rather than HasPrefix
+// handles comments before the actual SQL.
+func returnsRows(sql string) bool {
 	sqlUpper := strings.ToUpper(sql)
-	if strings.Contains(sqlUpper, "SELECT") ||
+	return strings.Contains(sqlUpper, "SELECT") ||
 		strings.HasPrefix(sqlUpper, "DESCRIBE") ||
 		strings.HasPrefix(sqlUpper, "DESC") ||
 		strings.Contains(sqlUpper, "SHOW") ||
-		strings.Contains(sqlUpper, "EXPLAIN") {
-		p.executeQuery(sql, useVertical)
-	} else {
-		p.executeStatement(sql)
-	}
+		strings.Contains(sqlUpper, "EXPLAIN")
 }
 
-func (p *PromptExecutor) executeQuery(query string, useVertical bool) {
-	start := time.Now()
-	rows, err := p.db.Query(query)
-	elapsed := time.Since(start)
+// executeQuery runs a row-returning statement and prints the result. The
+// returned error has already been reported to the user.
+func (p *PromptExecutor) executeQuery(ctx context.Context, query string, useVertical bool) error {
+	res, err := p.runStatement(ctx, query)
+	p.stopWatch()
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
+		fmt.Printf(tr("Error: %v\n"), err)
 		p.maybeSuggestFixedSQL(query, err)
-		return
+		return err
 	}
-	defer rows.Close()
+	columns, allRows := res.Columns, res.Rows
 
-	columns, err := rows.Columns()
-	if err != nil {
-		fmt.Printf("Error getting columns: %v\n", err)
-		return
+	// Plans are left untouched so AI and visual explain see the raw numbers
+	if !isExplainQuery(query) {
+		p.formatResult(res.Types, allRows)
 	}
+	p.lastResult = res
+	p.buffers.add(res)
 
-	// Read all rows
-	var allRows [][]string
-	values := make([]interface{}, len(columns))
-	scanArgs := make([]interface{}, len(values))
-	for i := range values {
-		scanArgs[i] = &values[i]
+	result, truncated := p.renderResult(res, useVertical)
+	summary := fmt.Sprintf(tr("\n%d row%s in set (%.3fs)\n"), len(allRows), plural(len(allRows)), res.Duration.Seconds())
+	if truncated {
+		summary += fmt.Sprintf(tr("Values longer than %d characters were truncated; use \\expand <row> <column> to see one in full\n"), p.maxFieldWidth)
+	}
+	if !useVertical && p.outputFormatName() == "table" && !isExplainQuery(query) && p.shouldAutoView(result) {
+		p.viewResult(columns, allRows)
+		fmt.Print(strings.TrimPrefix(summary, "\n"))
+	} else {
+		p.printPaged(result + summary)
+	}
+	printWarnings(os.Stdout, res.Warnings)
+	if res.Cost != nil {
+		fmt.Print(formatCost(res.Cost, len(allRows)))
 	}
 
-	for rows.Next() {
-		err := rows.Scan(scanArgs...)
-		if err != nil {
-			fmt.Printf("Error scanning row: %v\n", err)
-			return
-		}
+	if !isExplainQuery(query) {
+		return nil
+	}
 
-		row := make([]string, len(columns))
-		for i, val := range values {
-			if val == nil {
-				row[i] = "NULL"
-			} else {
-				// Handle different types properly
-				switch v := val.(type) {
-				case []byte:
-					row[i] = string(v)
-				default:
-					row[i] = fmt.Sprintf("%v", v)
-				}
-			}
+	// Everything shown about the plan is kept for \report
+	explained := &explainRecord{Statement: query, Output: result, Database: p.database, At: time.Now()}
+	p.lastExplain = explained
+
+	// Read the plan from the rows as the server sent them, or ask for it as
+	// JSON when the EXPLAIN was in another format
+	jsonPlan, errJSON := p.explainJSONPlan(query, columns, allRows)
+	originalQuery, _, _ := extractQueryFromExplain(query)
+	if errJSON == nil {
+		explained.Plan = jsonPlan
+		explained.Visual, _ = p.visualExplain(jsonPlan)
+		if findings, err := analyzePlanHeuristics(jsonPlan); err == nil {
+			// EXPLAIN ANALYZE shows the rows read only in its tree
+			tree := planTree(columns, allRows)
+			findings = append(findings, estimateFindings(treeEstimates(tree))...)
+			statsFindings, suggestions := p.statisticsAdvice(originalQuery, jsonPlan, tree)
+			explained.Findings = append(findings, statsFindings...)
+			explained.Statistics = suggestions
+			printPlanScore(os.Stdout, explained.Findings)
 		}
-		allRows = append(allRows, row)
 	}
 
-	if err := rows.Err(); err != nil {
-		fmt.Printf("Error iterating rows: %v\n", err)
-		return
+	// Show how the explained query has really been running
+	if stats, err := p.digestStats(originalQuery); err == nil && stats != nil {
+		explained.Workload = stats
+		printDigestStats(os.Stdout, stats)
 	}
 
-	// Format output based on \G flag
-	var result string
-	if useVertical {
-		result = formatVerticalTable(columns, allRows)
-	} else {
-		result = formatMySQLTable(columns, allRows)
-	}
-	result += fmt.Sprintf("\n%d row%s in set (%.3fs)\n", len(allRows), plural(len(allRows)), elapsed.Seconds())
-	fmt.Print(result)
-
-	// Check if this was an EXPLAIN query and AI analysis is enabled
-	if p.enableAIAnalysis && isExplainQuery(query) {
-		// Capture the EXPLAIN output for AI analysis
-		explainOutput := result
-		// Note: AI analysis runs synchronously for now to avoid database connection issues
-		// In a production version, this could be made asynchronous with proper connection handling
-		if err := p.analyzeExplainWithAI(query, explainOutput); err != nil {
+	// Note: AI analysis runs synchronously for now to avoid database connection issues
+	if p.enableAIAnalysis {
+		if errJSON != nil {
+			fmt.Printf("AI analysis failed: could not obtain JSON plan: %v\n", errJSON)
+		} else if advice, err := p.analyzeExplainWithAI(ctx, query, jsonPlan, explained.Statistics); err != nil {
 			fmt.Printf("AI analysis failed: %v\n", err)
+		} else {
+			explained.Analysis, explained.Backend = advice, p.aiServerMode+" "+p.aiEndpoint()
 		}
 	}
 
 	// Check if user wants to export JSON for external tools or show visual explain
-	if (p.enableJSONExport || p.enableVisualExplain) && isExplainQuery(query) {
-		var jsonPlan string
-		var errJSON error
-
-		// If the user already requested FORMAT=JSON in the query, try to extract it from the current output
-		if strings.Contains(strings.ToUpper(query), "FORMAT=JSON") {
-			jsonPlan, errJSON = p.extractJSONFromExplainOutput(result)
-		} else {
-			// User didn't ask for JSON - try to obtain it automatically
-			// Prefer the MySQL 8.4+ JSON capture if available
-			if p.isMySQL84Plus() {
-				jsonPlan, errJSON = p.executeExplainWithJSONCapture(query)
-				if errJSON != nil {
-					// Fall back to explicit EXPLAIN FORMAT=JSON
-					originalQuery, _, _ := extractQueryFromExplain(query)
-					var v string
-					row := p.db.QueryRow("EXPLAIN FORMAT=JSON " + originalQuery)
-					errJSON = row.Scan(&v)
-					if errJSON == nil {
-						jsonPlan = v
-					}
-				}
-			} else {
-				// For older MySQL versions, run EXPLAIN FORMAT=JSON explicitly and read the JSON from the first column
-				originalQuery, _, _ := extractQueryFromExplain(query)
-				var v string
-				row := p.db.QueryRow("EXPLAIN FORMAT=JSON " + originalQuery)
-				errJSON = row.Scan(&v)
-				if errJSON == nil {
-					jsonPlan = v
-				}
-			}
-		}
-
+	if p.enableJSONExport || p.enableVisualExplain {
 		// If we did not obtain JSON, show a helpful warning
-		if errJSON != nil || jsonPlan == "" {
+		if errJSON != nil {
 			fmt.Printf("⚠️  Could not obtain JSON plan for EXPLAIN: %v\n", errJSON)
-			fmt.Println("   Tip: Try running EXPLAIN FORMAT=JSON <your query> or enable JSON export in ~/.go-myclirc (json_export=true)")
+			if tip := p.serverInfo().gatewayExplainTip(); tip != "" {
+				fmt.Println("   Tip: " + tip)
+			} else {
+				fmt.Println("   Tip: Try running EXPLAIN FORMAT=JSON <your query> or enable JSON export in ~/.go-myclirc (json_export=true)")
+			}
 		} else {
 			if p.enableJSONExport {
 				fmt.Println("\n📤 JSON Export for External Tools:")
@@ -383,73 +515,258 @@ func (p *PromptExecutor) executeQuery(query string, useVertical bool) {
 			}
 
 			// Show our built-in visual explain if enabled
-			if p.enableVisualExplain {
-				if visualPlan, err := p.visualExplain(jsonPlan); err == nil {
-					fmt.Println("\n🌳 Built-in Visual Explain:")
-					fmt.Println("===========================")
-					fmt.Print(visualPlan)
-				}
+			if p.enableVisualExplain && explained.Visual != "" {
+				fmt.Println("\n🌳 Built-in Visual Explain:")
+				fmt.Println("===========================")
+				fmt.Print(explained.Visual)
 			}
 		}
 	}
+
+	// Last, once everything about the plan is on screen, offer the
+	// statistics refreshes and then the indexes the analysis calls for
+	if len(explained.Statistics) > 0 {
+		jsonPlan = p.offerStatistics(query, jsonPlan, explained.Statistics)
+	}
+	if explained.Analysis != "" {
+		p.offerIndexes(query, jsonPlan, explained.Analysis)
+	}
+	return nil
 }
 
-func (p *PromptExecutor) executeStatement(stmt string) {
-	start := time.Now()
-	result, err := p.db.Exec(stmt)
-	elapsed := time.Since(start)
+// executeStatement runs a statement that returns no rows. The returned error
+// has already been reported to the user.
+func (p *PromptExecutor) executeStatement(ctx context.Context, stmt string) error {
+	res, err := p.runStatement(ctx, stmt)
+	p.stopWatch()
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
+		fmt.Printf(tr("Error: %v\n"), err)
 		p.maybeSuggestFixedSQL(stmt, err)
-		return
+		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		fmt.Printf("Query OK\nTime: %.3fs\n", elapsed.Seconds())
-		return
+	fmt.Printf(tr("Query OK, %d row%s affected\nTime: %.3fs\n"), res.RowsAffected, plural(int(res.RowsAffected)), res.Duration.Seconds())
+	printWarnings(os.Stdout, res.Warnings)
+	return nil
+}
+
+// renderResult renders a result set for the terminal in the \output format,
+// or vertically for \G. In a table, values are cut to maxFieldWidth, which
+// truncated reports; plans are shown in full and without colors.
+func (p *PromptExecutor) renderResult(res *Result, useVertical bool) (output string, truncated bool) {
+	explain := isExplainQuery(res.Statement)
+	var color func(int, string) string
+	if !explain {
+		color = p.resultColorizer(res.Types)
+	}
+	display := res
+	var r Renderer
+	switch {
+	case useVertical:
+		r = verticalRenderer{color: color}
+	case p.outputFormatName() == "table":
+		if !explain {
+			cut := *res
+			cut.Rows, truncated = truncateFields(res.Rows, p.maxFieldWidth)
+			display = &cut
+		}
+		r = tableRenderer{color: color}
+	default:
+		r = renderers[p.outputFormat]
 	}
+	var b strings.Builder
+	if err := r.Render(&b, display); err != nil {
+		return fmt.Sprintf("Error: %v\n", err), false
+	}
+	return b.String(), truncated
+}
 
-	fmt.Printf("Query OK, %d row%s affected\nTime: %.3fs\n", rowsAffected, plural(int(rowsAffected)), elapsed.Seconds())
+// resultOutput renders the result of a statement run away from the prompt,
+// by \bg, --parallel, broadcast or a runbook, as ExecuteSQL prints it: in
+// the \output format with [format] applied, and followed by the row count
+func (p *PromptExecutor) resultOutput(res *Result, useVertical bool) string {
+	if res.Columns == nil {
+		return fmt.Sprintf(tr("Query OK, %d row%s affected\nTime: %.3fs\n"), res.RowsAffected, plural(int(res.RowsAffected)), res.Duration.Seconds())
+	}
+	if !isExplainQuery(res.Statement) {
+		p.formatResult(res.Types, res.Rows)
+	}
+	output, truncated := p.renderResult(res, useVertical)
+	output += fmt.Sprintf(tr("\n%d row%s in set (%.3fs)\n"), len(res.Rows), plural(len(res.Rows)), res.Duration.Seconds())
+	if truncated {
+		output += fmt.Sprintf(tr("Values longer than %d characters were truncated; use \\expand <row> <column> to see one in full\n"), p.maxFieldWidth)
+	}
+	return output
 }
 
 func (p *PromptExecutor) Executor(in string) {
-	// Handle backslash commands immediately
+	line := in
 	in = strings.TrimSpace(in)
+	if p.input != nil {
+		// A multi-line paste runs line by line, as if it had been typed
+		if text, ok := p.input.TakePaste(in); ok && strings.Contains(text, "\n") {
+			p.runPasted(text)
+			return
+		}
+	}
+	if p.pastingPlan {
+		// Keep the indentation, which is the structure of a TREE plan
+		p.readPastedPlan(line)
+		return
+	}
+	defer p.recordHistory(in)
+	p.reportFinishedJobs()
+
+	// Handle backslash commands immediately
 	if strings.HasPrefix(in, "\\") {
 		switch {
 		case in == "\\q", in == "\\quit":
-			fmt.Println("Bye")
+			p.warnRunningJobs()
+			fmt.Println(tr("Bye"))
+			p.disableProfiling()
+			p.stopRecording()
+			p.closeAIClient()
+			p.shutdown()
 			os.Exit(0)
 		case in == "\\c", in == "\\clear":
 			// Clear the buffer and reset
 			p.buffer = ""
 			return
 		case in == "\\h", in == "\\help":
-			fmt.Println("MySQL commands:")
-			fmt.Println("\\c, \\clear    Clear the current input statement")
-			fmt.Println("\\colors       Test syntax highlighting with examples")
-			fmt.Println("\\config       Show current syntax highlighting configuration")
-			fmt.Println("\\g, \\go       Send command to mysql server")
-			fmt.Println("\\h, \\help     Display this help")
-			fmt.Println("\\p, \\print    Print current command")
-			fmt.Println("\\q, \\quit     Exit mysql")
-			fmt.Println("\\r, \\connect  Reconnect to the server")
-			fmt.Println("\\s            Display server status")
-			fmt.Println("\\u <db>       Use another database. Takes database name as argument")
-			fmt.Println("\\. <file>     Execute an SQL script file. Takes a file name as an argument. Supports zstd compressed files")
-			fmt.Println("\\! <cmd>      Execute a system shell command")
-			fmt.Println("\\suggestions  Toggle suggestions: \"on\" or \"off\"")
-			fmt.Println("\\ai           Toggle AI EXPLAIN analysis: \"on\" or \"off\"")
-			fmt.Println("\\json         Toggle JSON export for external tools: \"on\" or \"off\"")
-			fmt.Println("\\visual       Toggle built-in visual explain: \"on\" or \"off\"")
+			printHelp()
+			return
+		case strings.HasPrefix(in, "\\h "), strings.HasPrefix(in, "\\help "):
+			_, topic, _ := strings.Cut(in, " ")
+			p.showStatementHelp(topic)
+			return
+		case in == "\\plugins":
+			p.listPlugins()
 			return
 		case in == "\\s":
 			p.showServerStatus()
 			return
+		case in == "\\profile", strings.HasPrefix(in, "\\profile "):
+			p.setProfile(strings.TrimPrefix(in, "\\profile"))
+			return
+		case strings.HasPrefix(in, "\\bg "):
+			p.startBackground(strings.TrimPrefix(in, "\\bg "))
+			return
+		case in == "\\replica-lag", strings.HasPrefix(in, "\\replica-lag "):
+			p.replicaLagCommand(strings.TrimPrefix(in, "\\replica-lag"))
+			return
+		case in == "\\chunked", strings.HasPrefix(in, "\\chunked "):
+			p.runChunked(strings.TrimPrefix(in, "\\chunked"))
+			return
+		case in == "\\drop-safe", strings.HasPrefix(in, "\\drop-safe "):
+			p.removeTableSafely("DROP", strings.TrimPrefix(in, "\\drop-safe"))
+			return
+		case in == "\\truncate-safe", strings.HasPrefix(in, "\\truncate-safe "):
+			p.removeTableSafely("TRUNCATE", strings.TrimPrefix(in, "\\truncate-safe"))
+			return
+		case in == "\\alter-safe", strings.HasPrefix(in, "\\alter-safe "):
+			p.alterSafe(strings.TrimPrefix(in, "\\alter-safe"))
+			return
+		case in == "\\save-session", strings.HasPrefix(in, "\\save-session "):
+			p.saveSession(strings.TrimPrefix(in, "\\save-session"))
+			return
+		case in == "\\jobs":
+			p.listJobs()
+			return
+		case in == "\\result", strings.HasPrefix(in, "\\result "):
+			p.showJobResult(strings.TrimPrefix(in, "\\result"))
+			return
+		case in == "\\ping", strings.HasPrefix(in, "\\ping "):
+			p.ping(strings.TrimPrefix(in, "\\ping"))
+			return
+		case in == "\\l":
+			p.listDatabases()
+			return
+		case in == "\\dt", strings.HasPrefix(in, "\\dt "):
+			p.listTables(strings.TrimSpace(strings.TrimPrefix(in, "\\dt")))
+			return
+		case in == "\\d":
+			p.listTables("")
+			return
+		case strings.HasPrefix(in, "\\d "):
+			p.describeTable(strings.TrimSpace(in[3:]))
+			return
+		case in == "\\browse":
+			p.browse()
+			return
+		case in == "\\view":
+			if p.lastResult != nil {
+				p.viewResult(p.lastResult.Columns, p.lastResult.Rows)
+			} else {
+				p.viewResult(nil, nil)
+			}
+			return
+		case strings.HasPrefix(in, "\\view "):
+			if buf := p.buffers.find(strings.TrimSpace(strings.TrimPrefix(in, "\\view "))); buf != nil {
+				p.viewResult(buf.Result.Columns, buf.Result.Rows)
+			} else {
+				fmt.Println("No such buffer (see \\buffers)")
+			}
+			return
+		case in == "\\buffers":
+			p.listBuffers()
+			return
+		case in == "\\show", strings.HasPrefix(in, "\\show "):
+			p.showBuffer(strings.TrimPrefix(in, "\\show"))
+			return
+		case in == "\\bookmark", strings.HasPrefix(in, "\\bookmark "):
+			p.bookmarkResult(strings.TrimPrefix(in, "\\bookmark"))
+			return
+		case in == "\\expand", strings.HasPrefix(in, "\\expand "):
+			p.expandCell(strings.TrimPrefix(in, "\\expand"))
+			return
+		case in == "\\review-export", strings.HasPrefix(in, "\\review-export "):
+			p.exportReview(strings.TrimPrefix(in, "\\review-export"))
+			return
+		case in == "\\topology":
+			p.showTopology()
+			return
+		case in == "\\binlog", strings.HasPrefix(in, "\\binlog "):
+			p.showBinlog(strings.TrimPrefix(in, "\\binlog"))
+			return
+		case strings.HasPrefix(in, "\\count~"):
+			p.countEstimate(strings.TrimPrefix(in, "\\count~"))
+			return
+		case in == "\\edit-rows":
+			p.editRows()
+			return
+		case in == "\\output", strings.HasPrefix(in, "\\output "):
+			p.setOutput(strings.TrimPrefix(in, "\\output"))
+			return
+		case in == "\\pager", strings.HasPrefix(in, "\\pager "):
+			p.setPager(strings.TrimPrefix(in, "\\pager"))
+			return
+		case in == "\\nopager":
+			p.setNoPager()
+			return
+		case in == "\\timing", strings.HasPrefix(in, "\\timing "):
+			p.setTiming(strings.TrimPrefix(in, "\\timing"))
+			return
+		case in == "\\cost", strings.HasPrefix(in, "\\cost "):
+			p.setCost(strings.TrimPrefix(in, "\\cost"))
+			return
+		case in == "\\W", in == "\\warnings":
+			p.setWarnings(true)
+			return
+		case in == "\\w", in == "\\nowarning":
+			p.setWarnings(false)
+			return
 		case in == "\\config":
 			p.showConfig()
 			return
+		case in == "\\config edit":
+			p.editConfig()
+			return
+		case in == "\\config reload":
+			p.reloadConfig()
+			return
+		case in == "\\theme", strings.HasPrefix(in, "\\theme "):
+			p.themeCommand(strings.TrimPrefix(in, "\\theme"))
+			return
 		case in == "\\colors", in == "\\test-colors":
 			p.testSyntaxHighlighting()
 			return
@@ -478,11 +795,22 @@ func (p *PromptExecutor) Executor(in string) {
 			}
 
 			// Persist change to user config file
-			cfg := LoadSyntaxConfig()
-			if cfg != nil {
-				cfg.EnableSuggestions = p.enableSuggestions
-				_ = SaveSyntaxConfig(cfg)
-			}
+			saveSetting("suggestions", strconv.FormatBool(p.enableSuggestions))
+			return
+		case in == "\\completion", strings.HasPrefix(in, "\\completion "):
+			p.setCompletion(strings.TrimPrefix(in, "\\completion"))
+			return
+		case in == "\\report", strings.HasPrefix(in, "\\report "):
+			p.saveReport(strings.TrimSpace(strings.TrimPrefix(in, "\\report")))
+			return
+		case in == "\\record", strings.HasPrefix(in, "\\record "):
+			p.record(strings.TrimPrefix(in, "\\record"))
+			return
+		case in == "\\analyze-paste":
+			p.startPastedPlan()
+			return
+		case in == "\\ai compare", strings.HasPrefix(in, "\\ai compare "):
+			p.compareQueries(strings.TrimPrefix(in, "\\ai compare"))
 			return
 		case strings.HasPrefix(in, "\\ai"):
 			// Syntax: \ai [on|off|toggle]
@@ -509,11 +837,7 @@ func (p *PromptExecutor) Executor(in string) {
 			}
 
 			// Persist change to user config file
-			cfg := LoadSyntaxConfig()
-			if cfg != nil {
-				cfg.EnableAIAnalysis = p.enableAIAnalysis
-				_ = SaveSyntaxConfig(cfg)
-			}
+			saveSetting("ai_analysis", strconv.FormatBool(p.enableAIAnalysis))
 			return
 		case strings.HasPrefix(in, "\\json"):
 			// Syntax: \json [on|off|toggle]
@@ -540,11 +864,10 @@ func (p *PromptExecutor) Executor(in string) {
 			}
 
 			// Persist change to user config file
-			cfg := LoadSyntaxConfig()
-			if cfg != nil {
-				cfg.EnableJSONExport = p.enableJSONExport
-				_ = SaveSyntaxConfig(cfg)
-			}
+			saveSetting("json_export", strconv.FormatBool(p.enableJSONExport))
+			return
+		case in == "\\visual export", strings.HasPrefix(in, "\\visual export "):
+			p.exportVisual(strings.TrimSpace(strings.TrimPrefix(in, "\\visual export")))
 			return
 		case strings.HasPrefix(in, "\\visual"):
 			// Syntax: \visual [on|off|toggle]
@@ -571,11 +894,7 @@ func (p *PromptExecutor) Executor(in string) {
 			}
 
 			// Persist change to user config file
-			cfg := LoadSyntaxConfig()
-			if cfg != nil {
-				cfg.EnableVisualExplain = p.enableVisualExplain
-				_ = SaveSyntaxConfig(cfg)
-			}
+			saveSetting("visual_explain", strconv.FormatBool(p.enableVisualExplain))
 			return
 		case strings.HasPrefix(in, "\\u "):
 			// Extract database name after \u
@@ -606,7 +925,9 @@ func (p *PromptExecutor) Executor(in string) {
 			if p.buffer != "" {
 				sql := strings.TrimSpace(p.buffer)
 				if sql != "" {
-					p.ExecuteSQL(sql, false)
+					ctx, stop := p.statementContext()
+					p.ExecuteSQL(ctx, sql, false)
+					stop()
 					p.buffer = ""
 				}
 			}
@@ -614,7 +935,24 @@ func (p *PromptExecutor) Executor(in string) {
 		case in == "\\r", in == "\\connect":
 			p.reconnect()
 			return
+		case in == "\\runbook", strings.HasPrefix(in, "\\runbook "):
+			p.runRunbook(strings.TrimPrefix(in, "\\runbook"))
+			return
+		case in == "\\alias", strings.HasPrefix(in, "\\alias "):
+			p.defineAlias(strings.TrimPrefix(in, "\\alias"))
+			return
+		case strings.HasPrefix(in, "\\unalias "):
+			p.removeAlias(strings.TrimPrefix(in, "\\unalias "))
+			return
 		default:
+			// Built-in commands take precedence over aliases, and aliases
+			// over plugins of the same name
+			if p.runAlias(in) {
+				return
+			}
+			if p.runPluginCommand(in) {
+				return
+			}
 			fmt.Printf("Unknown command: %s\n", in)
 			return
 		}
@@ -622,7 +960,12 @@ func (p *PromptExecutor) Executor(in string) {
 
 	// Handle regular exit commands
 	if in == "exit" || in == "quit" || in == "bye" {
-		fmt.Println("Bye")
+		p.warnRunningJobs()
+		fmt.Println(tr("Bye"))
+		p.disableProfiling()
+		p.stopRecording()
+		p.closeAIClient()
+		p.shutdown()
 		os.Exit(0)
 	}
 
@@ -638,18 +981,28 @@ func (p *PromptExecutor) Executor(in string) {
 	// Add input to buffer
 	p.buffer += in + "\n"
 
-	// Check if buffer contains a complete SQL statement (ends with semicolon or \G)
-	statementTerminated := false
-	useVertical := false
-
-	if strings.Contains(p.buffer, ";") {
-		statementTerminated = true
-	} else if strings.Contains(p.buffer, "\\G") {
-		statementTerminated = true
-		useVertical = true
+	// A terminator inside an open quote or comment does not end the statement
+	if st := scanSQL(p.buffer); st.quote != 0 || st.blockComment {
+		return
 	}
 
-	if statementTerminated {
+	// Run every complete SQL statement in the buffer (ending with a semicolon or
+	// \G), so a line or paste holding several statements runs them all
+	for {
+		statementTerminated := false
+		useVertical := false
+
+		if strings.Contains(p.buffer, ";") {
+			statementTerminated = true
+		} else if strings.Contains(p.buffer, "\\G") {
+			statementTerminated = true
+			useVertical = true
+		}
+
+		if !statementTerminated {
+			return
+		}
+
 		// Extract the SQL statement up to the terminator
 		var sql string
 		var remaining string
@@ -665,35 +1018,76 @@ func (p *PromptExecutor) Executor(in string) {
 		}
 
 		if sql != "" {
-			// In non-interactive mode (piped input), print the SQL statement before executing (like mysql -vvv)
-			// But NOT when executing from source/\. commands (sourceFileMode)
-			if p.nonInteractive && !p.sourceFileMode {
-				fmt.Println("--------------")
-				fmt.Println(sql)
-				fmt.Println("--------------")
-				fmt.Println()
-			} else if !p.nonInteractive && !p.sourceFileMode {
-				p.printHighlightedSQL(sql)
-			}
-			p.ExecuteSQL(sql, useVertical)
-		} // Keep any remaining content after the terminator
+			p.echoStatement(sql)
+			ctx, stop := p.statementContext()
+			p.ExecuteSQL(ctx, sql, useVertical)
+			stop()
+		}
+		// Keep any remaining content after the terminator
 		p.buffer = remaining
+		if remaining != "" {
+			p.buffer += "\n"
+		}
+		if st := scanSQL(p.buffer); st.quote != 0 || st.blockComment {
+			return
+		}
 	}
 }
 
+// echoStatement shows a statement about to run: framed like mysql -vvv for
+// piped input, highlighted at the prompt, and not at all from \. scripts
+func (p *PromptExecutor) echoStatement(sql string) {
+	if p.nonInteractive && !p.sourceFileMode {
+		fmt.Println("--------------")
+		fmt.Println(sql)
+		fmt.Println("--------------")
+		fmt.Println()
+	} else if !p.nonInteractive && !p.sourceFileMode && !p.liveHighlighting() {
+		p.printHighlightedSQL(sql)
+	}
+}
+
+// completionWordSeparator ends the word being completed, so completion
+// starts fresh right after "(", ",", "=" or "table."
+const completionWordSeparator = " \t(),.="
+
 func (p *PromptExecutor) Completer(in prompt.Document) []prompt.Suggest {
+	if p.input != nil {
+		// The completer runs after every edit, so this tracks the input line
+		p.input.SetCurrentLine(in.Text)
+		if p.input.JustPasted() {
+			return nil
+		}
+	}
+	if suggestions, ok := p.aliasSuggestions(in.TextBeforeCursor()); ok {
+		return suggestions
+	}
+
 	// Refresh cache if needed - force refresh if cache is empty
 	if time.Since(p.cacheTime) > 30*time.Second || len(p.tables) == 0 {
-		p.refreshCache()
+		ctx, cancel := context.WithTimeout(p.sessionContext(), cacheRefreshTimeout)
+		p.refreshCache(ctx)
+		cancel()
 	}
 
 	// Get the current line and word
 	line := in.CurrentLine()
-	word := in.GetWordBeforeCursor()
+	word := in.GetWordBeforeCursorUntilSeparator(completionWordSeparator)
 	cursorPos := in.CursorPositionCol()
 
-	// Parse SQL context using the smart parser
-	ctx := ParseSQLContext(line, cursorPos)
+	// Tab on an empty line shows the query templates; after one is chosen,
+	// completion stays out of the way of Tab between its placeholders
+	if in.Text == "" && p.buffer == "" && p.showTemplates {
+		return p.templateSuggestions()
+	}
+	p.showTemplates = false
+	if strings.Contains(word, "${") || (word == "" && strings.Contains(line, "${")) {
+		return nil
+	}
+
+	// Parse SQL context using the smart parser, over the earlier lines of the
+	// statement too so their tables and aliases are known
+	ctx := ParseSQLContext(p.buffer+line, len(p.buffer)+cursorPos)
 
 	// Only show suggestions when user is actually typing or has specific SQL context
 	lineTrimmed := strings.TrimSpace(line)
@@ -707,17 +1101,59 @@ func (p *PromptExecutor) Completer(in prompt.Document) []prompt.Suggest {
 		return nil
 	}
 
-	// Build suggestions based on parsed context
-	suggestions := p.buildContextAwareSuggestions(ctx)
+	// Build suggestions based on parsed context; without metadata only
+	// keywords can be completed
+	var suggestions []prompt.Suggest
+	if p.activeCompletion() == completionOff {
+		if word == "" {
+			return nil
+		}
+		suggestions = p.getKeywordSuggestions()
+	} else {
+		suggestions = p.buildContextAwareSuggestions(ctx)
+	}
+	if p.buffer == "" && strings.TrimSpace(in.TextBeforeCursor()) == word {
+		// The first word of a statement may start a template
+		suggestions = append(suggestions, p.templateSuggestions()...)
+	}
 
-	// Filter suggestions based on current word being typed
+	// Inside `order items` the word may be only part of the name
+	suggestions = completeInsideBackticks(in.TextBeforeCursor(), word, suggestions)
+
+	// Filter suggestions based on current word being typed, ranking the
+	// tables and columns you use most first
+	boost := p.completionBoost()
 	if word != "" {
-		suggestions = p.findMatches(strings.ToLower(word), suggestions)
+		suggestions = p.findRankedMatches(strings.ToLower(word), suggestions, boost)
+	} else {
+		rankByUsage(suggestions, boost)
 	}
 
 	return suggestions
 }
 
+// completeInsideBackticks handles a quoted name typed up to a separator,
+// such as "`order it": the popup replaces only the word after the space, so
+// only names starting with "`order " are kept, each cut to what follows
+func completeInsideBackticks(before, word string, suggestions []prompt.Suggest) []prompt.Suggest {
+	open := strings.LastIndexByte(before, '`')
+	if open < 0 || strings.Count(before, "`")%2 == 0 {
+		return suggestions
+	}
+	lead := strings.ToLower(before[open : len(before)-len(word)])
+	if len(lead) <= 1 {
+		return suggestions // no separator since the backtick
+	}
+	var kept []prompt.Suggest
+	for _, s := range suggestions {
+		if len(s.Text) > len(lead) && strings.ToLower(s.Text[:len(lead)]) == lead {
+			s.Text = s.Text[len(lead):]
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
 // shouldShowContextSuggestions determines if we should show suggestions even without a typed word
 func (p *PromptExecutor) shouldShowContextSuggestions(line string, ctx *SQLParseResult) bool {
 	if line == "" {
@@ -744,6 +1180,34 @@ func (p *PromptExecutor) shouldShowContextSuggestions(line string, ctx *SQLParse
 		return true
 	}
 
+	// Show the arguments of a function, and the select list of a subquery
+	if (ctx.Context == ContextFunction && strings.HasSuffix(lineUpper, "(")) ||
+		(ctx.IsSubquery && strings.HasSuffix(lineUpper, "SELECT")) {
+		return true
+	}
+
+	// Show what follows SHOW VARIABLES LIKE, SHOW ENGINE and the like
+	if ctx.Context == ContextShowItem && len(ctx.ShowWords) > 0 {
+		return true
+	}
+
+	// In CREATE TABLE show types and attributes after each word of a
+	// column, and table options after the definitions and each "="
+	switch ctx.Context {
+	case ContextColumnType, ContextColumnAttribute:
+		return true
+	case ContextTableElement:
+		return strings.HasSuffix(lineUpper, "(") || strings.HasSuffix(lineUpper, ",")
+	case ContextTableOption:
+		return strings.HasSuffix(lineUpper, ")") || strings.HasSuffix(lineUpper, "=")
+	}
+
+	// Show the columns and values of an INSERT as each one starts
+	if (ctx.Context == ContextInsertColumn || ctx.Context == ContextInsertValue) &&
+		(strings.HasSuffix(lineUpper, "(") || strings.HasSuffix(lineUpper, ",")) {
+		return true
+	}
+
 	// Show after comma in SELECT or FROM clauses
 	if ctx.AfterComma && (ctx.HasFrom || strings.Contains(lineUpper, "SELECT")) {
 		return true
@@ -765,10 +1229,35 @@ func (p *PromptExecutor) buildContextAwareSuggestions(ctx *SQLParseResult) []pro
 		// After FROM, JOIN, UPDATE, INTO - show tables and databases
 		suggestions = p.getTableSuggestions()
 
-	case ContextColumn:
-		// After SELECT, WHERE, ORDER BY, GROUP BY - show columns from query tables
+	case ContextColumn, ContextFunction:
+		// After SELECT, WHERE, ORDER BY, GROUP BY or inside a function call -
+		// show columns from query tables
 		suggestions = p.getColumnSuggestions(ctx)
 
+	case ContextInsertColumn:
+		// In INSERT INTO t (...) - show the columns not listed yet
+		suggestions = p.getInsertColumnSuggestions(ctx)
+
+	case ContextInsertValue:
+		// In VALUES (...) - show what fits the column at this position
+		suggestions = p.getInsertValueSuggestions(ctx)
+
+	case ContextTableElement:
+		// At the start of a CREATE TABLE definition - show index clauses
+		suggestions = tableElements
+
+	case ContextColumnType:
+		// After a column name in CREATE TABLE - show data types
+		suggestions = columnTypes
+
+	case ContextColumnAttribute:
+		// After a column's type - show NOT NULL, DEFAULT and the like
+		suggestions = columnAttributes
+
+	case ContextTableOption:
+		// After CREATE TABLE t (...) - show ENGINE, CHARSET and their values
+		suggestions = p.getTableOptionSuggestions(ctx)
+
 	case ContextJoinOn:
 		// After JOIN ... ON - show columns that are likely join keys
 		suggestions = p.getJoinColumnSuggestions(ctx)
@@ -779,15 +1268,15 @@ func (p *PromptExecutor) buildContextAwareSuggestions(ctx *SQLParseResult) []pro
 
 	case ContextShowItem:
 		// After SHOW - show SHOW options
-		suggestions = p.getShowItemSuggestions()
+		suggestions = p.getShowItemSuggestions(ctx)
 
 	case ContextAlias:
 		// After table name - could be alias or next keyword
 		// Don't suggest anything specific, let user type alias or keyword
 
 	case ContextValue:
-		// After operator - user is typing a value, minimal suggestions
-		// Could suggest NULL, TRUE, FALSE, or common values
+		// After col = ' - show the column's most frequent values
+		suggestions = p.getValueSuggestions(ctx)
 
 	case ContextKeyword:
 		// Start of statement or after AND/OR - show all keywords
@@ -806,32 +1295,13 @@ func (p *PromptExecutor) getColumnsForTable(tableName string, ctx *SQLParseResul
 	var suggestions []prompt.Suggest
 
 	// Resolve alias to actual table name
-	resolvedTable := ctx.ResolveAlias(tableName)
-
-	// Try exact match first
-	if cols, exists := p.columns[resolvedTable]; exists {
-		for _, col := range cols {
-			suggestions = append(suggestions, prompt.Suggest{
-				Text:        col,
-				Description: fmt.Sprintf("Column from %s", resolvedTable),
-			})
-		}
-		return suggestions
-	}
-
-	// Try case-insensitive match
-	for table, cols := range p.columns {
-		if strings.EqualFold(table, resolvedTable) {
-			for _, col := range cols {
-				suggestions = append(suggestions, prompt.Suggest{
-					Text:        col,
-					Description: fmt.Sprintf("Column from %s", table),
-				})
-			}
-			return suggestions
-		}
+	table, cols, _ := p.tableColumns(ctx.ResolveAlias(tableName))
+	for _, col := range cols {
+		suggestions = append(suggestions, prompt.Suggest{
+			Text:        quoteIdentifier(col),
+			Description: fmt.Sprintf("Column from %s", table),
+		})
 	}
-
 	return suggestions
 }
 
@@ -842,7 +1312,7 @@ func (p *PromptExecutor) getTableSuggestions() []prompt.Suggest {
 	// Add tables first (higher priority)
 	for _, table := range p.tables {
 		suggestions = append(suggestions, prompt.Suggest{
-			Text:        table,
+			Text:        quoteIdentifier(table),
 			Description: "Table",
 		})
 	}
@@ -850,7 +1320,7 @@ func (p *PromptExecutor) getTableSuggestions() []prompt.Suggest {
 	// Add databases for schema-qualified references
 	for _, db := range p.databases {
 		suggestions = append(suggestions, prompt.Suggest{
-			Text:        db,
+			Text:        quoteIdentifier(db),
 			Description: "Database",
 		})
 	}
@@ -884,14 +1354,14 @@ func (p *PromptExecutor) getColumnSuggestions(ctx *SQLParseResult) []prompt.Sugg
 				}
 			}
 
-			if cols, exists := p.columns[tableName]; exists {
+			if _, cols, _ := p.tableColumns(tableName); cols != nil {
 				for _, col := range cols {
 					// Avoid duplicate column names
 					if seenColumns[col] {
 						// If duplicate, use qualified name
-						qualifiedName := tableName + "." + col
+						qualifiedName := quoteIdentifier(tableName) + "." + quoteIdentifier(col)
 						if tableAlias != "" {
-							qualifiedName = tableAlias + "." + col
+							qualifiedName = quoteIdentifier(tableAlias) + "." + quoteIdentifier(col)
 						}
 						suggestions = append(suggestions, prompt.Suggest{
 							Text:        qualifiedName,
@@ -900,7 +1370,7 @@ func (p *PromptExecutor) getColumnSuggestions(ctx *SQLParseResult) []prompt.Sugg
 					} else {
 						seenColumns[col] = true
 						suggestions = append(suggestions, prompt.Suggest{
-							Text:        col,
+							Text:        quoteIdentifier(col),
 							Description: fmt.Sprintf("Column from %s", tableName),
 						})
 					}
@@ -924,7 +1394,7 @@ func (p *PromptExecutor) getColumnSuggestions(ctx *SQLParseResult) []prompt.Sugg
 			}
 
 			suggestions = append(suggestions, prompt.Suggest{
-				Text:        prefix + ".",
+				Text:        quoteIdentifier(prefix) + ".",
 				Description: fmt.Sprintf("Columns from %s", tableName),
 			})
 		}
@@ -933,7 +1403,7 @@ func (p *PromptExecutor) getColumnSuggestions(ctx *SQLParseResult) []prompt.Sugg
 		if !ctx.HasFrom {
 			for _, table := range p.tables {
 				suggestions = append(suggestions, prompt.Suggest{
-					Text:        table,
+					Text:        quoteIdentifier(table),
 					Description: "Table (add FROM clause)",
 				})
 			}
@@ -942,7 +1412,7 @@ func (p *PromptExecutor) getColumnSuggestions(ctx *SQLParseResult) []prompt.Sugg
 			for table, cols := range p.columns {
 				for _, col := range cols {
 					suggestions = append(suggestions, prompt.Suggest{
-						Text:        col,
+						Text:        quoteIdentifier(col),
 						Description: fmt.Sprintf("Column from %s", table),
 					})
 				}
@@ -950,6 +1420,21 @@ func (p *PromptExecutor) getColumnSuggestions(ctx *SQLParseResult) []prompt.Sugg
 		}
 	}
 
+	// A subquery may refer to the tables of the queries around it
+	for _, tableName := range ctx.OuterTables {
+		prefix := tableName
+		for _, alias := range ctx.Aliases {
+			if alias.TableName == tableName {
+				prefix = alias.Alias
+				break
+			}
+		}
+		suggestions = append(suggestions, prompt.Suggest{
+			Text:        quoteIdentifier(prefix) + ".",
+			Description: fmt.Sprintf("Columns from outer %s", tableName),
+		})
+	}
+
 	// Add functions in SELECT context
 	if !ctx.HasFrom || ctx.LastKeyword == "SELECT" {
 		suggestions = append(suggestions, p.getFunctionSuggestions()...)
@@ -960,7 +1445,8 @@ func (p *PromptExecutor) getColumnSuggestions(ctx *SQLParseResult) []prompt.Sugg
 
 // getJoinColumnSuggestions returns column suggestions optimized for JOIN ON clauses
 func (p *PromptExecutor) getJoinColumnSuggestions(ctx *SQLParseResult) []prompt.Suggest {
-	var suggestions []prompt.Suggest
+	// Whole conditions along foreign keys come first
+	suggestions := p.getJoinPredicateSuggestions(ctx)
 
 	// In JOIN ON context, prefer columns that might be foreign keys (ending with _id or named id)
 	for _, tableName := range ctx.Tables {
@@ -976,14 +1462,15 @@ func (p *PromptExecutor) getJoinColumnSuggestions(ctx *SQLParseResult) []prompt.
 		if tableAlias != "" {
 			prefix = tableAlias
 		}
+		prefix = quoteIdentifier(prefix) + "."
 
-		if cols, exists := p.columns[tableName]; exists {
+		if _, cols, _ := p.tableColumns(tableName); cols != nil {
 			// First add likely join columns (id, *_id)
 			for _, col := range cols {
 				colLower := strings.ToLower(col)
 				if col == "id" || strings.HasSuffix(colLower, "_id") {
 					suggestions = append(suggestions, prompt.Suggest{
-						Text:        prefix + "." + col,
+						Text:        prefix + quoteIdentifier(col),
 						Description: fmt.Sprintf("Join key from %s", tableName),
 					})
 				}
@@ -994,7 +1481,7 @@ func (p *PromptExecutor) getJoinColumnSuggestions(ctx *SQLParseResult) []prompt.
 				colLower := strings.ToLower(col)
 				if col != "id" && !strings.HasSuffix(colLower, "_id") {
 					suggestions = append(suggestions, prompt.Suggest{
-						Text:        prefix + "." + col,
+						Text:        prefix + quoteIdentifier(col),
 						Description: fmt.Sprintf("Column from %s", tableName),
 					})
 				}
@@ -1010,16 +1497,17 @@ func (p *PromptExecutor) getDatabaseSuggestions() []prompt.Suggest {
 	var suggestions []prompt.Suggest
 	for _, db := range p.databases {
 		suggestions = append(suggestions, prompt.Suggest{
-			Text:        db,
+			Text:        quoteIdentifier(db),
 			Description: "Database",
 		})
 	}
 	return suggestions
 }
 
-// getShowItemSuggestions returns SHOW command options
-func (p *PromptExecutor) getShowItemSuggestions() []prompt.Suggest {
-	return []prompt.Suggest{
+// getShowItemSuggestions returns SHOW command options, or what follows the
+// option already typed
+func (p *PromptExecutor) getShowItemSuggestions(ctx *SQLParseResult) []prompt.Suggest {
+	items := p.serverInfo().filterShowItems([]prompt.Suggest{
 		{Text: "DATABASES", Description: "Show databases"},
 		{Text: "TABLES", Description: "Show tables"},
 		{Text: "COLUMNS FROM", Description: "Show columns from table"},
@@ -1042,6 +1530,11 @@ func (p *PromptExecutor) getShowItemSuggestions() []prompt.Suggest {
 		{Text: "MASTER STATUS", Description: "Show master status"},
 		{Text: "SLAVE STATUS", Description: "Show slave status"},
 		{Text: "REPLICA STATUS", Description: "Show replica status"},
+		{Text: "BINARY LOG STATUS", Description: "Show binary log status"},
+		{Text: "BINLOG STATUS", Description: "Show binary log status"},
+		{Text: "SLAVE HOSTS", Description: "Show replicas of this server"},
+		{Text: "REPLICAS", Description: "Show replicas of this server"},
+		{Text: "REPLICA HOSTS", Description: "Show replicas of this server"},
 		{Text: "BINARY LOGS", Description: "Show binary logs"},
 		{Text: "BINLOG EVENTS", Description: "Show binlog events"},
 		{Text: "WARNINGS", Description: "Show warnings"},
@@ -1056,7 +1549,11 @@ func (p *PromptExecutor) getShowItemSuggestions() []prompt.Suggest {
 		{Text: "PROFILE", Description: "Show profile"},
 		{Text: "COLLATION", Description: "Show collation"},
 		{Text: "CHARACTER SET", Description: "Show character sets"},
+	})
+	if len(ctx.ShowWords) > 0 {
+		return p.showArgumentSuggestions(ctx.ShowWords, items)
 	}
+	return items
 }
 
 // getKeywordSuggestions returns SQL keyword suggestions
@@ -1080,6 +1577,9 @@ func (p *PromptExecutor) getKeywordSuggestions() []prompt.Suggest {
 	}
 	suggestions = append(suggestions, starters...)
 	suggestions = append(suggestions, MySQLKeywords...)
+	info := p.serverInfo()
+	suggestions = append(suggestions, flavorKeywords[info.Flavor]...)
+	suggestions = append(suggestions, flavorKeywords[info.Gateway]...)
 
 	return suggestions
 }
@@ -1161,17 +1661,41 @@ func (p *PromptExecutor) ExitChecker(in string, breakline bool) bool {
 	return false // Not used anymore, we exit directly with os.Exit()
 }
 
-// livePrefix returns the current prompt prefix
+// livePrefix returns the current prompt prefix, or a continuation prompt
+// aligned under it while a statement is unfinished
 func (p *PromptExecutor) livePrefix() (string, bool) {
 	var dbPart string
 	if p.database != "" {
 		dbPart = fmt.Sprintf("(%s)", p.database)
 	}
-	return fmt.Sprintf("MySQL %s@%s:%d%s> ", p.user, p.host, p.port, dbPart), true
+	main := fmt.Sprintf("MySQL %s@%s:%d%s> ", p.user, p.host, p.port, dbPart)
+	if len(p.broadcast) > 0 {
+		names := make([]string, len(p.broadcast))
+		for i, t := range p.broadcast {
+			names[i] = t.Name
+		}
+		main = fmt.Sprintf("MySQL [%s]%s> ", strings.Join(names, ","), dbPart)
+	}
+	if rule, ok := p.promptRule(); ok && rule.Marker != "" {
+		main = rule.Marker + " " + main
+	}
+	p.prefix = main
+	if p.pastingPlan {
+		p.prefix = continuationPrompt("", runewidth.StringWidth(main))
+	} else if strings.TrimSpace(p.buffer) != "" {
+		p.prefix = continuationPrompt(p.buffer, runewidth.StringWidth(main))
+	}
+	return p.prefix, true
 }
 
 // formatMySQLTable formats data in classic MySQL table style
 func formatMySQLTable(columns []string, rows [][]string) string {
+	return formatMySQLTableColored(columns, rows, nil)
+}
+
+// formatMySQLTableColored formats a table like formatMySQLTable, wrapping each
+// padded cell in the ANSI sequence returned by color (nil or "" for none)
+func formatMySQLTableColored(columns []string, rows [][]string, color func(col int, cell string) string) string {
 	if len(rows) == 0 {
 		return ""
 	}
@@ -1212,6 +1736,12 @@ func formatMySQLTable(columns []string, rows [][]string) string {
 	for _, row := range rows {
 		result.WriteString("\n|")
 		for i, cell := range row {
+			if color != nil {
+				if code := color(i, cell); code != "" {
+					result.WriteString(fmt.Sprintf(" %s%-*s%s |", code, colWidths[i], cell, ansiReset))
+					continue
+				}
+			}
 			result.WriteString(fmt.Sprintf(" %-*s |", colWidths[i], cell))
 		}
 	}
@@ -1223,6 +1753,12 @@ func formatMySQLTable(columns []string, rows [][]string) string {
 
 // formatVerticalTable formats data in MySQL vertical format (\G)
 func formatVerticalTable(columns []string, rows [][]string) string {
+	return formatVerticalTableColored(columns, rows, nil)
+}
+
+// formatVerticalTableColored is formatVerticalTable with values wrapped in
+// the ANSI sequence returned by color (nil or "" for none)
+func formatVerticalTableColored(columns []string, rows [][]string, color func(col int, cell string) string) string {
 	if len(rows) == 0 {
 		return ""
 	}
@@ -1237,9 +1773,14 @@ func formatVerticalTable(columns []string, rows [][]string) string {
 			}
 			// Highlight specific column names in neon green
 			colDisplay := col
-			if col == "Table" || col == "Create Table" || col == "Database" || col == "View" || col == "Create View" {
+			if colorEnabled() && (col == "Table" || col == "Create Table" || col == "Database" || col == "View" || col == "Create View") {
 				colDisplay = fmt.Sprintf("\033[92m%s\033[0m", col)
 			}
+			if color != nil {
+				if code := color(j, row[j]); code != "" {
+					value = code + value + ansiReset
+				}
+			}
 			result.WriteString(fmt.Sprintf("%s: %s\n", colDisplay, value))
 		}
 		if i < len(rows)-1 {
@@ -1258,10 +1799,7 @@ func plural(n int) string {
 }
 
 // StartPrompt starts the interactive MySQL prompt
-func StartPrompt(db *sql.DB, user, host string, port int, database string, zstdCompressionLevel int, aiServerURL, aiServerMode, aiCachePath, aiDetailLevel string) error {
-	// Create default config file if it doesn't exist
-	_ = SaveDefaultSyntaxConfig()
-
+func StartPrompt(ctx context.Context, db *sql.DB, user, host string, port int, database string, zstdCompressionLevel int, aiServerURL, aiServerMode, aiCachePath, aiMCPCommand, aiDetailLevel string) error {
 	// Print initial connection info like mycli
 	fmt.Println("go-mycli 0.1.0")
 
@@ -1269,19 +1807,31 @@ func StartPrompt(db *sql.DB, user, host string, port int, database string, zstdC
 	stat, err := os.Stdin.Stat()
 	isTerminal := err == nil && (stat.Mode()&os.ModeCharDevice) != 0
 
+	// Without a config file, the first interactive session asks how to set
+	// it up; scripts use the defaults and leave the file alone
+	if isTerminal && needsSetup() {
+		runSetup()
+	}
+
 	if !isTerminal {
 		// Non-interactive mode: read from stdin line by line
-		return runNonInteractive(db, user, host, port, database, zstdCompressionLevel, aiServerURL, aiServerMode, aiCachePath, aiDetailLevel)
+		return runNonInteractive(ctx, db, user, host, port, database, zstdCompressionLevel, aiServerURL, aiServerMode, aiCachePath, aiMCPCommand, aiDetailLevel)
 	}
 
 	// Use go-prompt for interactive mode with syntax highlighting
-	return startGoPrompt(db, user, host, port, database, zstdCompressionLevel, aiServerURL, aiServerMode, aiCachePath, aiDetailLevel)
+	return startGoPrompt(ctx, db, user, host, port, database, zstdCompressionLevel, aiServerURL, aiServerMode, aiCachePath, aiMCPCommand, aiDetailLevel)
 }
 
 // startGoPrompt starts the go-prompt-based prompt with syntax highlighting
-func startGoPrompt(db *sql.DB, user, host string, port int, database string, zstdCompressionLevel int, aiServerURL, aiServerMode, aiCachePath, aiDetailLevel string) error {
-	// Load syntax config and use it to set suggestion toggle
+func startGoPrompt(ctx context.Context, db *sql.DB, user, host string, port int, database string, zstdCompressionLevel int, aiServerURL, aiServerMode, aiCachePath, aiMCPCommand, aiDetailLevel string) error {
+	// Load syntax config and use it to set suggestion toggle, pointing out
+	// the keys it ignores
 	cfg := LoadSyntaxConfig()
+	if configPath := syntaxConfigPath(); configPath != "" {
+		if problems, err := checkConfigFile(configPath); err == nil && printConfigProblems(configPath, problems) {
+			fmt.Println("These settings are ignored; fix them with \\config edit")
+		}
+	}
 
 	// Create executor
 	if aiServerURL == "" {
@@ -1293,7 +1843,13 @@ func startGoPrompt(db *sql.DB, user, host string, port int, database string, zst
 	if aiCachePath == "" {
 		aiCachePath = cfg.AiCachePath
 	}
+	if aiMCPCommand == "" {
+		aiMCPCommand = cfg.AiMCPCommand
+	}
+	ctx, cancel := context.WithCancel(ctx)
 	executor := &PromptExecutor{
+		ctx:                  ctx,
+		cancel:               cancel,
 		db:                   db,
 		user:                 user,
 		host:                 host,
@@ -1309,27 +1865,95 @@ func startGoPrompt(db *sql.DB, user, host string, port int, database string, zst
 		enableAIAnalysis:     cfg.EnableAIAnalysis,
 		enableJSONExport:     cfg.EnableJSONExport,
 		enableVisualExplain:  cfg.EnableVisualExplain,
+		autoView:             cfg.AutoView,
+		maxFieldWidth:        cfg.MaxFieldWidth,
+		buffers:              resultBuffers{limit: cfg.ResultBuffers},
+		showCost:             cfg.QueryCost,
+		lag:                  lagSettings{Max: cfg.MaxReplicaLag, Replicas: cfg.LagReplicas, Heartbeat: cfg.HeartbeatTable, Wait: cfg.ReplicaLagWait},
+		colorResults:         cfg.ColorResults,
+		liveHighlight:        cfg.LiveHighlight,
+		hooks:                cfg.Hooks,
+		format:               cfg.Format,
+		completionMode:       cfg.CompletionMode,
+		completionMatch:      cfg.CompletionMatch,
+		completionMinScore:   cfg.CompletionMinScore,
+		completionValues:     cfg.CompletionValues,
+		completionLatency:    cfg.CompletionLatency,
+		rowEstimateWarning:   cfg.RowEstimateWarning,
+		syntaxCheck:          cfg.SyntaxCheck,
+		guard:                queryGuard{timer: cfg.LiveTimer, alert: cfg.LongQueryAlert, notify: cfg.LongQueryNotify},
+		aliases:              copyAliases(cfg.Aliases),
+		runbooks:             cfg.Runbooks,
+		promptRules:          cfg.PromptRules,
+		broadcast:            broadcastTargets,
 		zstdCompressionLevel: zstdCompressionLevel,
 		aiServerURL:          aiServerURL,
 		aiServerMode:         aiServerMode,
 		aiCachePath:          aiCachePath,
+		aiMCPCommand:         aiMCPCommand,
 		aiDetailLevel:        aiDetailLevel,
+		input:                newInputParser(),
+		rc:                   cfg,
+		outputFormat:         cfg.OutputFormat,
+		showWarnings:         cfg.ShowWarnings,
+		pager:                cfg.Pager,
+		pagerEnabled:         cfg.EnablePager,
+	}
+	executor.checkLatency()
+	executor.startKeepalive(cfg.Keepalive)
+	if cfg.RankCompletions {
+		executor.usage = openUsageStore(defaultUsagePath())
+	}
+	if resumed != nil {
+		executor.restoreSession(resumed)
+	}
+	executor.runStartupFile(startupFilePath())
+
+	// Create go-prompt instance with syntax highlighting. NO_COLOR options
+	// come after the popup colors so they win.
+	options := append([]prompt.Option{
+		prompt.OptionParser(executor.input),
+		prompt.OptionLivePrefix(executor.livePrefix),
+		prompt.OptionTitle("go-mycli"),
+		prompt.OptionCompletionWordSeparator(completionWordSeparator),
+	}, cfg.Popup.promptOptions()...)
+	options = append(options, promptColorOptions()...)
+	var writer prompt.ConsoleWriter = prompt.NewStdoutWriter()
+	if executor.liveHighlighting() {
+		writer = NewHighlightingWriter(executor.highlighter, func() string { return executor.buffer })
+		options = append(options, prompt.OptionInputTextColor(inputMarkerColor))
+	}
+	if len(executor.promptRules) > 0 {
+		writer = &promptColorWriter{ConsoleWriter: writer, executor: executor}
+	}
+	options = append(options, prompt.OptionWriter(writer))
+	options = append(options, newLineEditor(writer).promptOptions()...)
+	options = append(options, prompt.OptionAddKeyBind(
+		prompt.KeyBind{Key: prompt.Tab, Fn: executor.tabPlaceholder},
+		prompt.KeyBind{Key: prompt.ControlSquareClose, Fn: nextPlaceholder},
+	))
+	completer := executor.Completer
+	if !cfg.Popup.Descriptions {
+		completer = withoutDescriptions(completer)
 	}
-
-	// Create go-prompt instance with syntax highlighting
 	p := prompt.New(
 		executor.Executor,
-		executor.Completer,
-		prompt.OptionLivePrefix(executor.livePrefix),
-		prompt.OptionTitle("go-mycli"),
+		completer,
+		options...,
 	)
 
 	// Run the prompt
 	p.Run()
+	executor.closeBroadcast()
+	executor.disableProfiling()
+	executor.stopKeepalive()
+	executor.stopRecording()
+	executor.closeAIClient()
+	executor.shutdown()
 	return nil
 }
 
-func runNonInteractive(db *sql.DB, user, host string, port int, database string, zstdCompressionLevel int, aiServerURL, aiServerMode, aiCachePath, aiDetailLevel string) error {
+func runNonInteractive(ctx context.Context, db *sql.DB, user, host string, port int, database string, zstdCompressionLevel int, aiServerURL, aiServerMode, aiCachePath, aiMCPCommand, aiDetailLevel string) error {
 	cfg := LoadSyntaxConfig()
 	if aiServerURL == "" {
 		aiServerURL = cfg.AiServerURL
@@ -1340,7 +1964,13 @@ func runNonInteractive(db *sql.DB, user, host string, port int, database string,
 	if aiCachePath == "" {
 		aiCachePath = cfg.AiCachePath
 	}
+	if aiMCPCommand == "" {
+		aiMCPCommand = cfg.AiMCPCommand
+	}
+	ctx, cancel := context.WithCancel(ctx)
 	executor := &PromptExecutor{
+		ctx:                  ctx,
+		cancel:               cancel,
 		db:                   db,
 		user:                 user,
 		host:                 host,
@@ -1356,15 +1986,46 @@ func runNonInteractive(db *sql.DB, user, host string, port int, database string,
 		enableAIAnalysis:     cfg.EnableAIAnalysis,
 		enableJSONExport:     cfg.EnableJSONExport,
 		enableVisualExplain:  cfg.EnableVisualExplain,
+		autoView:             cfg.AutoView,
+		maxFieldWidth:        cfg.MaxFieldWidth,
+		buffers:              resultBuffers{limit: cfg.ResultBuffers},
+		showCost:             cfg.QueryCost,
+		lag:                  lagSettings{Max: cfg.MaxReplicaLag, Replicas: cfg.LagReplicas, Heartbeat: cfg.HeartbeatTable, Wait: cfg.ReplicaLagWait},
+		colorResults:         cfg.ColorResults,
+		liveHighlight:        cfg.LiveHighlight,
+		hooks:                cfg.Hooks,
+		format:               cfg.Format,
+		aliases:              copyAliases(cfg.Aliases),
+		runbooks:             cfg.Runbooks,
+		broadcast:            broadcastTargets,
 		zstdCompressionLevel: zstdCompressionLevel,
 		aiServerURL:          aiServerURL,
 		aiServerMode:         aiServerMode,
 		aiCachePath:          aiCachePath,
+		aiMCPCommand:         aiMCPCommand,
 		aiDetailLevel:        aiDetailLevel,
 		nonInteractive:       true,
 	}
 
-	scanner := bufio.NewScanner(os.Stdin)
+	defer executor.closeAIClient()
+	defer executor.shutdown()
+
+	var input io.Reader = os.Stdin
+	if scriptParallelism > 1 {
+		script, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		if executor.runParallel(string(script), true) {
+			if executor.interrupted {
+				return errCanceled
+			}
+			return nil
+		}
+		input = strings.NewReader(string(script))
+	}
+
+	scanner := bufio.NewScanner(input)
 	for scanner.Scan() {
 		line := scanner.Text()
 
@@ -1372,6 +2033,11 @@ func runNonInteractive(db *sql.DB, user, host string, port int, database string,
 		// This will accumulate multi-line statements in the buffer
 		// and execute them when a terminator (; or \G) is found
 		executor.Executor(line)
+
+		// Like the mysql client in batch mode, Ctrl-C ends the run
+		if executor.interrupted {
+			return errCanceled
+		}
 	}
 
 	// After all input is processed, if there's still content in buffer without terminator,
@@ -1379,7 +2045,9 @@ func runNonInteractive(db *sql.DB, user, host string, port int, database string,
 	if executor.buffer != "" {
 		sql := strings.TrimSpace(executor.buffer)
 		if sql != "" {
-			executor.ExecuteSQL(sql, false)
+			ctx, stop := executor.statementContext()
+			executor.ExecuteSQL(ctx, sql, false)
+			stop()
 			executor.buffer = ""
 		}
 	}
@@ -1407,6 +2075,35 @@ func (p *PromptExecutor) showConfig() {
 	fmt.Printf("AI analysis enabled: %v\n", config.EnableAIAnalysis)
 	fmt.Printf("JSON export enabled: %v\n", config.EnableJSONExport)
 	fmt.Printf("Visual explain enabled: %v\n", config.EnableVisualExplain)
+	fmt.Printf("Auto result viewer: %v\n", config.AutoView)
+	fmt.Printf("Max field width: %d\n", config.MaxFieldWidth)
+	fmt.Printf("Result buffers: %d\n", config.ResultBuffers)
+	fmt.Printf("Query cost: %v\n", config.QueryCost)
+	fmt.Printf("Show warnings: %v\n", config.ShowWarnings)
+	fmt.Printf("Output format: %s\n", config.OutputFormat)
+	fmt.Printf("Pager: %s (enabled: %v)\n", orDefault(config.Pager, "$PAGER or "+defaultPager), config.EnablePager)
+	fmt.Printf("Language: %s (%s)\n", config.Language, language)
+	fmt.Printf("Colored results: %v\n", config.ColorResults)
+	fmt.Printf("Live highlighting: %v\n", config.LiveHighlight)
+	fmt.Printf("Rank completions: %v\n", config.RankCompletions)
+	fmt.Printf("Completion: %s (auto limit %s)\n", config.CompletionMode, config.CompletionLatency)
+	fmt.Printf("Completion match: %s (min score %d)\n", config.CompletionMatch, config.CompletionMinScore)
+	fmt.Printf("Value completion: %v\n", config.CompletionValues)
+	fmt.Printf("Keepalive: %s\n", keepaliveName(config.Keepalive))
+	fmt.Printf("Session: %s\n", sessionSummary(config.Session))
+	fmt.Printf("Row estimate warning: %d\n", config.RowEstimateWarning)
+	fmt.Printf("Live timer: %v\n", config.LiveTimer)
+	fmt.Printf("Syntax check: %v\n", config.SyntaxCheck)
+	fmt.Printf("Max replica lag: %s (wait before writes: %v)\n", config.MaxReplicaLag, config.ReplicaLagWait)
+	fmt.Printf("Long query alert: %s\n", alertName(config.LongQueryAlert, config.LongQueryNotify))
+	fmt.Printf("Aliases: %d\n", len(config.Aliases))
+	fmt.Printf("Prompt rules: %d\n", len(config.PromptRules))
+	if config.Popup.Enabled() {
+		fmt.Printf("Completion popup: max_rows=%d descriptions=%v colors=%d\n", config.Popup.MaxRows, config.Popup.Descriptions, len(config.Popup.colorKeys()))
+	}
+	if config.Format.Enabled() {
+		fmt.Printf("Result format: timezone=%q datetime_format=%q numbers=%q\n", config.Format.Timezone, config.Format.DatetimeFormat, config.Format.Numbers)
+	}
 
 	if len(config.Colors) > 0 {
 		fmt.Println("\nColor Overrides:")
@@ -1415,7 +2112,7 @@ func (p *PromptExecutor) showConfig() {
 		}
 	}
 
-	fmt.Println("\nConfig file: ~/.go-myclirc")
+	fmt.Printf("\nConfig file: %s\n", syntaxConfigPath())
 }
 
 // testSyntaxHighlighting displays a sample query with syntax highlighting
@@ -1474,8 +2171,7 @@ func (p *PromptExecutor) showServerStatus() {
 	// Current user
 	fmt.Printf("Current user:\t\t%s@%s\n", p.user, p.host)
 
-	// Current pager (we don't support pager yet)
-	fmt.Println("Current pager:\t\tstdout")
+	fmt.Printf("Current pager:\t\t%s\n", p.pagerName())
 
 	// Server version
 	var version string
@@ -1491,6 +2187,7 @@ func (p *PromptExecutor) showServerStatus() {
 
 	// Connection type
 	fmt.Printf("Connection:\t\t%s via TCP/IP\n", p.host)
+	p.showGatewayStatus()
 
 	// Server character set
 	var serverCharset string
@@ -1531,61 +2228,19 @@ func (p *PromptExecutor) showServerStatus() {
 	// TCP port
 	fmt.Printf("TCP port:\t\t%d\n", p.port)
 
-	// Uptime
-	var uptime int
-	err = p.db.QueryRow("SELECT @@uptime").Scan(&uptime)
+	// Every counter for the rest of the page in one round trip
+	status, err := globalStatus(p.db, append(summaryStatusCounters, extendedStatusCounters...)...)
 	if err != nil {
 		fmt.Printf("Uptime:\t\t\t<error: %v>\n", err)
-	} else {
-		days := uptime / 86400
-		hours := (uptime % 86400) / 3600
-		minutes := (uptime % 3600) / 60
-		seconds := uptime % 60
-		fmt.Printf("Uptime:\t\t\t%d day%s %02d hour%s %02d min %02d sec\n",
-			days, plural(days), hours, plural(hours), minutes, seconds)
+		status = map[string]string{}
+	} else if uptime, ok := statusNumber(status, "Uptime"); ok {
+		fmt.Printf("Uptime:\t\t\t%s\n", formatUptime(int(uptime)))
 	}
 
 	fmt.Println("--------------")
-
-	// Show connection statistics
-	var threadsConnected, queries, slowQueries, opens, flushTables, openTables, queriesPerSecondAvg float64
-	err = p.db.QueryRow("SHOW GLOBAL STATUS LIKE 'Threads_connected'").Scan(&sql.NullString{}, &threadsConnected)
-	if err == nil {
-		fmt.Printf("Threads: %d  ", int(threadsConnected))
-	}
-
-	err = p.db.QueryRow("SHOW GLOBAL STATUS LIKE 'Queries'").Scan(&sql.NullString{}, &queries)
-	if err == nil {
-		fmt.Printf("Queries: %d  ", int(queries))
-	}
-
-	err = p.db.QueryRow("SHOW GLOBAL STATUS LIKE 'Slow_queries'").Scan(&sql.NullString{}, &slowQueries)
-	if err == nil {
-		fmt.Printf("Slow queries: %d  ", int(slowQueries))
-	}
-
-	err = p.db.QueryRow("SHOW GLOBAL STATUS LIKE 'Opened_tables'").Scan(&sql.NullString{}, &opens)
-	if err == nil {
-		fmt.Printf("Opens: %d  ", int(opens))
-	}
-
-	err = p.db.QueryRow("SHOW GLOBAL STATUS LIKE 'Flush_commands'").Scan(&sql.NullString{}, &flushTables)
-	if err == nil {
-		fmt.Printf("Flush tables: %d  ", int(flushTables))
-	}
-
-	err = p.db.QueryRow("SHOW GLOBAL STATUS LIKE 'Open_tables'").Scan(&sql.NullString{}, &openTables)
-	if err == nil {
-		fmt.Printf("Open tables: %d  ", int(openTables))
-	}
-
-	err = p.db.QueryRow("SHOW GLOBAL STATUS LIKE 'Queries'").Scan(&sql.NullString{}, &queries)
-	if err == nil && uptime > 0 {
-		queriesPerSecondAvg = queries / float64(uptime)
-		fmt.Printf("Queries per second avg: %.6f", queriesPerSecondAvg)
-	}
-
-	fmt.Println()
+	fmt.Println(statusSummary(status))
+	fmt.Println("--------------")
+	p.showExtendedStatus(status)
 }
 
 // switchDatabase switches to a different database
@@ -1593,7 +2248,7 @@ func (p *PromptExecutor) switchDatabase(dbName string) {
 	// Execute USE statement
 	_, err := p.db.Exec("USE " + dbName)
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
+		fmt.Printf(tr("Error: %v\n"), err)
 		return
 	}
 
@@ -1638,6 +2293,15 @@ func (p *PromptExecutor) sourceFile(fileName string) {
 		sqlContent = content
 	}
 
+	if scriptParallelism > 1 && p.runParallel(string(sqlContent), false) {
+		return
+	}
+	p.runScript(string(sqlContent))
+}
+
+// runScript executes script text line by line through the executor, without
+// echoing statements
+func (p *PromptExecutor) runScript(text string) {
 	// Set source file mode to suppress SQL statement printing (like MySQL client)
 	oldSourceFileMode := p.sourceFileMode
 	p.sourceFileMode = true
@@ -1645,19 +2309,28 @@ func (p *PromptExecutor) sourceFile(fileName string) {
 
 	// Process the SQL content line by line through the normal executor
 	// This properly handles multi-line statements, comments, and terminators
-	scanner := bufio.NewScanner(strings.NewReader(string(sqlContent)))
+	scanner := bufio.NewScanner(strings.NewReader(text))
 	for scanner.Scan() {
 		line := scanner.Text()
 		p.Executor(line)
+
+		// Ctrl-C stops the rest of the script along with the statement
+		if p.interrupted {
+			fmt.Println("Script stopped")
+			p.buffer = ""
+			return
+		}
 	}
 
-	// Execute any remaining buffered content
+	// Execute any remaining buffered content, unless it is only comments
 	if p.buffer != "" {
 		sql := strings.TrimSpace(p.buffer)
-		if sql != "" {
-			p.ExecuteSQL(sql, false)
-			p.buffer = ""
+		if stripLeadingComments(sql) != "" {
+			ctx, stop := p.statementContext()
+			p.ExecuteSQL(ctx, sql, false)
+			stop()
 		}
+		p.buffer = ""
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -1665,17 +2338,36 @@ func (p *PromptExecutor) sourceFile(fileName string) {
 	}
 }
 
-// executeSystemCommand executes a system shell command
-func (p *PromptExecutor) executeSystemCommand(cmd string) {
-	// Execute the command
-	output, err := exec.Command("sh", "-c", cmd).CombinedOutput()
-	if err != nil {
+// executeSystemCommand runs a command with the platform shell for \!. The
+// command gets the terminal, so its output shows as it is written and
+// editors and pagers work. It returns the command's exit status; a failure
+// counts as a failed statement, so the startup file reports it.
+func (p *PromptExecutor) executeSystemCommand(command string) int {
+	// The terminal sends Ctrl-C to the command as well; it is the command's
+	// to act on, and must not end the session
+	_, stop := p.statementContext()
+	defer stop()
+
+	cmd := shellCommand(context.Background(), command)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err := cmd.Run()
+	if err == nil {
+		return 0
+	}
+	status := -1
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr) && exitErr.ExitCode() >= 0:
+		status = exitErr.ExitCode()
+		fmt.Printf("Command exited with status %d\n", status)
+	case errors.As(err, &exitErr):
+		// Killed by a signal, such as Ctrl-C
+		fmt.Printf("Command stopped: %v\n", exitErr)
+	default:
 		fmt.Printf("Error executing command: %v\n", err)
-		return
 	}
-
-	// Print the output
-	fmt.Print(string(output))
+	p.statementErrors++
+	return status
 }
 
 // printCurrentCommand prints the current command buffer
@@ -1703,6 +2395,11 @@ func (p *PromptExecutor) maybeSuggestFixedSQL(sql string, err error) {
 	fmt.Printf("  %s\n", suggestion)
 }
 
+// liveHighlighting reports whether the input line is highlighted as it is typed
+func (p *PromptExecutor) liveHighlighting() bool {
+	return p.liveHighlight && p.highlighter != nil && colorEnabled()
+}
+
 // printHighlightedSQL renders the command with syntax highlighting ahead of execution (interactive only)
 func (p *PromptExecutor) printHighlightedSQL(sql string) {
 	if p.highlighter == nil {
@@ -1729,7 +2426,7 @@ func (p *PromptExecutor) reconnect() {
 	}
 
 	mergedConfig := MergeConfig(config, p.user, "", p.host, p.port, "", p.database)
-	dsn := BuildDSN(mergedConfig.User, mergedConfig.Password, mergedConfig.Host, mergedConfig.Port, mergedConfig.Database, mergedConfig.Socket, p.zstdCompressionLevel)
+	dsn := withConnectionParams(BuildDSN(mergedConfig.User, mergedConfig.Password, mergedConfig.Host, mergedConfig.Port, mergedConfig.Database, mergedConfig.Socket, p.zstdCompressionLevel))
 
 	db, err := sql.Open("mysql", dsn)
 	if err != nil {
@@ -1745,18 +2442,25 @@ func (p *PromptExecutor) reconnect() {
 	}
 
 	// Update the database connection
-	p.db = db
+	p.setDB(db)
+	p.server = nil
+	p.showNameCache = nil
+	p.valueCache = nil
+	p.foreignKeyCache = nil
 
 	// Clear caches
 	p.tables = nil
 	p.columns = make(map[string][]string)
+	p.columnDetails = make(map[string]map[string]columnDetail)
 	p.databases = nil
 	p.cacheTime = time.Time{}
 
 	fmt.Println("Connection reestablished")
 }
 
-// extractJSONFromExplainOutput extracts JSON from EXPLAIN FORMAT=JSON output
+// extractJSONFromExplainOutput scrapes the JSON plan out of EXPLAIN
+// FORMAT=JSON output as a client rendered it, for plans pasted from elsewhere.
+// Plans of queries run here are read from their rows instead.
 func (p *PromptExecutor) extractJSONFromExplainOutput(output string) (string, error) {
 	// Look for JSON content in the output
 	// For EXPLAIN FORMAT=JSON, the JSON is typically in the first column of the first row
@@ -1787,6 +2491,9 @@ func (p *PromptExecutor) extractJSONFromExplainOutput(output string) (string, er
 		if strings.HasPrefix(trimmed, "|") && strings.HasSuffix(trimmed, "|") {
 			trimmed = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
 		}
+		// A multi-line cell has the borders on its first and last line only
+		trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, "| "))
+		trimmed = strings.TrimSpace(strings.TrimSuffix(trimmed, " |"))
 
 		// Skip empty lines
 		if len(trimmed) == 0 {
@@ -1935,9 +2642,9 @@ func (p *PromptExecutor) buildVisualTree(result *strings.Builder, node *ExplainN
 	// Add the current line
 	result.WriteString(prefix)
 	if isLast {
-		result.WriteString("└── ")
+		result.WriteString(treeGlyphs.Last)
 	} else {
-		result.WriteString("├── ")
+		result.WriteString(treeGlyphs.Branch)
 	}
 	result.WriteString(nodeInfo.String())
 	result.WriteString("\n")
@@ -1947,7 +2654,7 @@ func (p *PromptExecutor) buildVisualTree(result *strings.Builder, node *ExplainN
 	if isLast {
 		newPrefix += "    "
 	} else {
-		newPrefix += "│   "
+		newPrefix += treeGlyphs.Line
 	}
 
 	// Handle nested structures
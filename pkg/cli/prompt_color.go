@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+)
+
+// PromptRule is one entry of the [prompt] section of ~/.go-myclirc: a host
+// pattern and the color and marker of the prompt on matching connections
+type PromptRule struct {
+	Pattern string // matched against the host, or host/database when it has a /
+	Color   string // a [completion] color name, empty to keep the default
+	Marker  string // shown before the prompt, e.g. [PROD]
+}
+
+// parsePromptRule parses "prod-* = red [PROD]": a color name followed by an
+// optional marker, or a marker of several words alone
+func parsePromptRule(pattern, value string) (PromptRule, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return PromptRule{}, fmt.Errorf("invalid pattern %q", pattern)
+	}
+	rule := PromptRule{Pattern: pattern}
+	value = strings.TrimSpace(value)
+	color, marker, hasMarker := strings.Cut(value, " ")
+	switch _, ok := parsePopupColor(color); {
+	case ok:
+		rule.Color, rule.Marker = color, strings.TrimSpace(marker)
+	case value == "":
+		return PromptRule{}, fmt.Errorf("%s: expected a color and/or a marker", pattern)
+	case !hasMarker:
+		// A single word is taken for a misspelt color rather than a marker
+		return PromptRule{}, fmt.Errorf("%s: unknown color %q", pattern, value)
+	default:
+		rule.Marker = value
+	}
+	return rule, nil
+}
+
+// String returns the rule's value as written in the config file
+func (r PromptRule) String() string {
+	return strings.TrimSpace(r.Color + " " + r.Marker)
+}
+
+// matches reports whether the rule applies to a connection. Patterns use
+// shell globs; one with a / is matched against host/database, so */billing
+// picks out a database on any host.
+func (r PromptRule) matches(host, database string) bool {
+	subject := host
+	if strings.Contains(r.Pattern, "/") {
+		subject = host + "/" + database
+	}
+	ok, _ := path.Match(strings.ToLower(r.Pattern), strings.ToLower(subject))
+	return ok
+}
+
+// matchPromptRule returns the first rule matching the connection
+func matchPromptRule(rules []PromptRule, host, database string) (PromptRule, bool) {
+	for _, r := range rules {
+		if r.matches(host, database) {
+			return r, true
+		}
+	}
+	return PromptRule{}, false
+}
+
+// promptRule returns the rule for the current connection
+func (p *PromptExecutor) promptRule() (PromptRule, bool) {
+	return matchPromptRule(p.promptRules, p.host, p.database)
+}
+
+// promptColorWriter draws the prompt prefix in the color of the matching
+// [prompt] rule. go-prompt only takes one prefix color when it starts, so the
+// color is applied as the prefix is written, after \u or \connect too.
+type promptColorWriter struct {
+	prompt.ConsoleWriter
+	executor *PromptExecutor
+}
+
+// WriteStr writes string with control sequence removal, coloring the prefix
+func (w *promptColorWriter) WriteStr(data string) {
+	if data == w.executor.prefix && colorEnabled() {
+		if rule, ok := w.executor.promptRule(); ok && rule.Color != "" {
+			color, _ := parsePopupColor(rule.Color)
+			w.ConsoleWriter.SetColor(color, prompt.DefaultColor, true)
+		}
+	}
+	w.ConsoleWriter.WriteStr(data)
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParsePromptRule(t *testing.T) {
+	tests := []struct {
+		pattern, value string
+		want           PromptRule
+		wantErr        bool
+	}{
+		{"prod-*", "red [PROD]", PromptRule{Pattern: "prod-*", Color: "red", Marker: "[PROD]"}, false},
+		{"staging*", "yellow", PromptRule{Pattern: "staging*", Color: "yellow"}, false},
+		{"*/billing", "!! billing !!", PromptRule{Pattern: "*/billing", Marker: "!! billing !!"}, false},
+		{"[prod", "red", PromptRule{}, true},
+		{"prod-*", "", PromptRule{}, true},
+		{"prod-*", "crimson", PromptRule{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parsePromptRule(tt.pattern, tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsePromptRule(%q, %q) error = %v, want error %v", tt.pattern, tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parsePromptRule(%q, %q) = %+v, want %+v", tt.pattern, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMatchPromptRule(t *testing.T) {
+	rules := []PromptRule{
+		{Pattern: "*/billing", Color: "fuchsia"},
+		{Pattern: "prod-*", Color: "red"},
+		{Pattern: "*.staging.example.com", Color: "yellow"},
+	}
+	tests := []struct {
+		host, database string
+		want           string
+		wantOK         bool
+	}{
+		{"prod-db1", "sales", "red", true},
+		{"PROD-db1", "", "red", true},
+		{"prod-db1", "billing", "fuchsia", true},
+		{"db2.staging.example.com", "sales", "yellow", true},
+		{"localhost", "sales", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := matchPromptRule(rules, tt.host, tt.database)
+		if ok != tt.wantOK || got.Color != tt.want {
+			t.Errorf("matchPromptRule(%q, %q) = %q, %v; want %q, %v", tt.host, tt.database, got.Color, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestLivePrefixMarker(t *testing.T) {
+	p := &PromptExecutor{user: "root", host: "prod-db1", port: 3306, database: "sales",
+		promptRules: []PromptRule{{Pattern: "prod-*", Color: "red", Marker: "[PROD]"}}}
+	if got, _ := p.livePrefix(); got != "[PROD] MySQL root@prod-db1:3306(sales)> " || p.prefix != got {
+		t.Errorf("livePrefix = %q (prefix %q)", got, p.prefix)
+	}
+	p.host = "localhost"
+	if got, _ := p.livePrefix(); got != "MySQL root@localhost:3306(sales)> " {
+		t.Errorf("livePrefix = %q", got)
+	}
+}
+
+func TestLoadSyntaxConfigPrompt(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), ".go-myclirc")
+	content := "[prompt]\nprod-* = red [PROD]\n*/billing = fuchsia\nstaging* = crimson\n"
+	if err := os.WriteFile(rc, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GO_MYCLI_RC", rc)
+
+	cfg := LoadSyntaxConfig()
+	// The rule with an unknown color is skipped
+	if len(cfg.PromptRules) != 2 {
+		t.Fatalf("PromptRules = %+v, want 2 rules", cfg.PromptRules)
+	}
+	if r := cfg.PromptRules[0]; r.Pattern != "prod-*" || r.Color != "red" || r.Marker != "[PROD]" {
+		t.Errorf("first rule = %+v", r)
+	}
+	if r := cfg.PromptRules[1]; r.Pattern != "*/billing" || r.Color != "fuchsia" {
+		t.Errorf("second rule = %+v", r)
+	}
+}
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExecuteSystemCommand(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	p := &PromptExecutor{}
+
+	if status := p.executeSystemCommand("echo streamed > " + out); status != 0 {
+		t.Errorf("status = %d, want 0", status)
+	}
+	if data, err := os.ReadFile(out); err != nil || strings.TrimSpace(string(data)) != "streamed" {
+		t.Errorf("the command did not run: %q, %v", data, err)
+	}
+	if p.statementErrors != 0 {
+		t.Errorf("a command that succeeded counts as %d failures", p.statementErrors)
+	}
+
+	if status := p.executeSystemCommand("exit 3"); status != 3 {
+		t.Errorf("status = %d, want 3", status)
+	}
+	if p.statementErrors != 1 {
+		t.Errorf("a failing command counts as %d failures, want 1", p.statementErrors)
+	}
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sessionCounters reads the session status values \cost compares: the
+// optimizer's cost of the last query and the handler read counters
+const sessionCounters = `SHOW SESSION STATUS WHERE Variable_name = 'Last_query_cost' OR Variable_name LIKE 'Handler\_read\_%'`
+
+// QueryCost is the optimizer's cost estimate for a SELECT and the rows its
+// handlers read, read only when \cost is on (or query_cost in the config)
+type QueryCost struct {
+	Cost float64 // Last_query_cost, 0 when the optimizer did not compute one (UNION, subqueries)
+	// Handlers are the Handler_read_* counters the statement moved, by name
+	// without the Handler_read_ prefix: rnd_next for rows scanned, key and
+	// next for index lookups and index order reads
+	Handlers map[string]int64
+}
+
+// RowsRead is the number of rows the handlers read for the statement
+func (c *QueryCost) RowsRead() int64 {
+	var n int64
+	for _, v := range c.Handlers {
+		n += v
+	}
+	return n
+}
+
+// costCounters measures the counters around one statement. SHOW SESSION
+// STATUS moves the handler counters itself, so what one reading adds is
+// measured first and left out.
+type costCounters struct {
+	before   map[string]float64
+	overhead map[string]float64
+}
+
+// readSessionCounters returns the values of sessionCounters on conn
+func readSessionCounters(ctx context.Context, conn *sql.Conn) (map[string]float64, error) {
+	rows, err := conn.QueryContext(ctx, sessionCounters)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counters := make(map[string]float64)
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+		counters[name], _ = strconv.ParseFloat(value, 64)
+	}
+	return counters, rows.Err()
+}
+
+// startCostCounters reads the counters before a statement runs on conn
+func startCostCounters(ctx context.Context, conn *sql.Conn) (*costCounters, error) {
+	first, err := readSessionCounters(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	second, err := readSessionCounters(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	overhead := make(map[string]float64, len(second))
+	for name, v := range second {
+		overhead[name] = v - first[name]
+	}
+	return &costCounters{before: second, overhead: overhead}, nil
+}
+
+// finish reads the counters after the statement and returns its cost
+func (c *costCounters) finish(ctx context.Context, conn *sql.Conn) (*QueryCost, error) {
+	after, err := readSessionCounters(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	return c.cost(after), nil
+}
+
+// cost compares the counters read after the statement with those before it
+func (c *costCounters) cost(after map[string]float64) *QueryCost {
+	cost := &QueryCost{Cost: after["Last_query_cost"], Handlers: make(map[string]int64)}
+	for name, v := range after {
+		handler, ok := strings.CutPrefix(name, "Handler_read_")
+		if !ok {
+			continue
+		}
+		if n := int64(v - c.before[name] - c.overhead[name]); n > 0 {
+			cost.Handlers[handler] = n
+		}
+	}
+	return cost
+}
+
+// formatCost is the one-line summary \cost prints after a SELECT: the cost,
+// the rows read by kind and how many were read for each row returned
+func formatCost(c *QueryCost, returned int) string {
+	var b strings.Builder
+	if c.Cost > 0 {
+		fmt.Fprintf(&b, "Query cost %.2f; ", c.Cost)
+	} else {
+		b.WriteString("Query cost n/a; ")
+	}
+	read := c.RowsRead()
+	fmt.Fprintf(&b, "%d row%s read", read, plural(int(read)))
+	if len(c.Handlers) > 0 {
+		names := make([]string, 0, len(c.Handlers))
+		for name := range c.Handlers {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if c.Handlers[names[i]] != c.Handlers[names[j]] {
+				return c.Handlers[names[i]] > c.Handlers[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		parts := make([]string, len(names))
+		for i, name := range names {
+			parts[i] = fmt.Sprintf("%s %d", name, c.Handlers[name])
+		}
+		fmt.Fprintf(&b, " (%s)", strings.Join(parts, ", "))
+	}
+	fmt.Fprintf(&b, " for %d returned", returned)
+	if returned > 0 && read > 0 {
+		fmt.Fprintf(&b, ", %.1f read per row returned", float64(read)/float64(returned))
+	}
+	return b.String() + "\n"
+}
+
+// setCost implements \cost [on|off]
+func (p *PromptExecutor) setCost(args string) {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "":
+		if p.showCost {
+			fmt.Println("Query cost is shown after each SELECT")
+		} else {
+			fmt.Println("Query cost is off")
+		}
+	case "on":
+		p.showCost = true
+		fmt.Println("Query cost on: Last_query_cost and handler reads are shown after each SELECT")
+	case "off":
+		p.showCost = false
+		fmt.Println("Query cost off")
+	default:
+		fmt.Println("Usage: \\cost [on|off]")
+		return
+	}
+	if args = strings.TrimSpace(args); args != "" {
+		saveSetting("query_cost", strconv.FormatBool(p.showCost))
+	}
+}
@@ -0,0 +1,33 @@
+package cli
+
+import "testing"
+
+func TestCostCounters(t *testing.T) {
+	c := &costCounters{
+		before:   map[string]float64{"Last_query_cost": 10, "Handler_read_rnd_next": 100, "Handler_read_key": 5, "Handler_read_next": 0},
+		overhead: map[string]float64{"Last_query_cost": 0, "Handler_read_rnd_next": 40, "Handler_read_key": 0, "Handler_read_next": 0},
+	}
+	cost := c.cost(map[string]float64{"Last_query_cost": 1625.8, "Handler_read_rnd_next": 1140, "Handler_read_key": 5, "Handler_read_next": 0})
+	if cost.Cost != 1625.8 || len(cost.Handlers) != 1 || cost.Handlers["rnd_next"] != 1000 || cost.RowsRead() != 1000 {
+		t.Errorf("cost = %+v", cost)
+	}
+}
+
+func TestFormatCost(t *testing.T) {
+	tests := []struct {
+		cost     QueryCost
+		returned int
+		want     string
+	}{
+		{QueryCost{Cost: 1625.8, Handlers: map[string]int64{"rnd_next": 16045}}, 183,
+			"Query cost 1625.80; 16045 rows read (rnd_next 16045) for 183 returned, 87.7 read per row returned\n"},
+		{QueryCost{Cost: 1.2, Handlers: map[string]int64{"key": 1, "next": 3}}, 3,
+			"Query cost 1.20; 4 rows read (next 3, key 1) for 3 returned, 1.3 read per row returned\n"},
+		{QueryCost{Handlers: map[string]int64{}}, 0, "Query cost n/a; 0 rows read for 0 returned\n"},
+	}
+	for _, test := range tests {
+		if got := formatCost(&test.cost, test.returned); got != test.want {
+			t.Errorf("got %q, expected %q", got, test.want)
+		}
+	}
+}
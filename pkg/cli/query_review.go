@@ -0,0 +1,359 @@
+package cli
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The fingerprint rules of pt-query-digest (QueryRewriter::fingerprint), so
+// that checksums match the rows pt-query-digest writes to the same tables
+var (
+	fpMysqldump    = regexp.MustCompile("^SELECT /\\*!40001 SQL_NO_CACHE \\*/ \\* FROM `")
+	fpToolkit      = regexp.MustCompile(`/\*\w+\.\w+:[0-9]/[0-9]\*/`)
+	fpCall         = regexp.MustCompile(`(?i)^\s*(call\s+\S+)\(`)
+	fpMultiInsert  = regexp.MustCompile(`(?is)^((?:INSERT|REPLACE)(?: IGNORE)?\s+INTO.+?VALUES\s*\(.*?\))\s*,\s*\(`)
+	fpBlockComment = regexp.MustCompile(`(?s)/\*[^!].*?\*/`)
+	fpLineComment  = regexp.MustCompile(`(?m)(?:--|#)[^'"\r\n]*$`)
+	fpUse          = regexp.MustCompile(`(?i)^use \S+$`)
+	fpEscapedQuote = regexp.MustCompile(`(?s)([^\\])\\['"]`)
+	fpDoubleQuoted = regexp.MustCompile(`(?s)([^\\])".*?[^\\]?"`)
+	fpSingleQuoted = regexp.MustCompile(`(?s)([^\\])'.*?[^\\]?'`)
+	fpBoolean      = regexp.MustCompile(`(?i)\bfalse\b|\btrue\b`)
+	fpNumber       = regexp.MustCompile(`[0-9+-][0-9a-f.xb+-]*`)
+	fpLeftover     = regexp.MustCompile(`[xb.+-]\?`)
+	fpSpace        = regexp.MustCompile(`[ \n\t\r\f]+`)
+	fpNull         = regexp.MustCompile(`\bnull\b`)
+	fpList         = regexp.MustCompile(`\b(in|values?)(?:[\s,]*\([\s?,]*\))+`)
+	fpUnion        = regexp.MustCompile(`\s(union(?:\sall)?)\s`)
+	fpLimit        = regexp.MustCompile(`\blimit \?(?:, ?\?| offset \?)?`)
+	fpOrderBy      = regexp.MustCompile(`\border by `)
+	fpAscending    = regexp.MustCompile(`\s+asc`)
+)
+
+// The tables pt-query-digest --review and --history default to
+const (
+	queryReviewTable = "query_review"
+	queryHistoryName = "query_history"
+)
+
+// fingerprint abstracts a statement the way pt-query-digest does: comments
+// removed, literals replaced by ?, IN and VALUES lists collapsed, whitespace
+// collapsed and lower case
+func fingerprint(query string) string {
+	switch {
+	case fpMysqldump.MatchString(query):
+		return "mysqldump"
+	case fpToolkit.MatchString(query):
+		return "percona-toolkit"
+	case strings.HasPrefix(query, "administrator command: "):
+		return query
+	}
+	if m := fpCall.FindStringSubmatch(query); m != nil {
+		return strings.ToLower(m[1])
+	}
+	if m := fpMultiInsert.FindStringSubmatch(query); m != nil {
+		query = m[1]
+	}
+	query = fpBlockComment.ReplaceAllString(query, "")
+	query = fpLineComment.ReplaceAllString(query, "")
+	if fpUse.MatchString(strings.TrimSpace(query)) {
+		return "use ?"
+	}
+
+	query = fpEscapedQuote.ReplaceAllString(query, "$1")
+	query = strings.NewReplacer(`\\`, "", `\'`, "", `\"`, "").Replace(query)
+	query = fpDoubleQuoted.ReplaceAllString(query, "$1?")
+	query = fpSingleQuoted.ReplaceAllString(query, "$1?")
+	query = fpBoolean.ReplaceAllString(query, "?")
+	query = fpNumber.ReplaceAllString(query, "?")
+	query = fpLeftover.ReplaceAllString(query, "?")
+
+	query = strings.TrimSpace(query)
+	query = fpSpace.ReplaceAllString(query, " ")
+	query = strings.ToLower(query)
+	query = fpNull.ReplaceAllString(query, "?")
+	query = fpList.ReplaceAllString(query, "$1(?+)")
+	query = collapseUnion(query)
+	if loc := fpLimit.FindStringIndex(query); loc != nil {
+		query = query[:loc[0]] + "limit ?" + query[loc[1]:]
+	}
+	if loc := fpOrderBy.FindStringIndex(query); loc != nil {
+		query = query[:loc[1]] + fpAscending.ReplaceAllString(query[loc[1]:], "")
+	}
+	return query
+}
+
+// collapseUnion turns repeats of the same SELECT joined by UNION into the
+// first one and a /*repeat union*/ marker
+func collapseUnion(query string) string {
+	start := strings.Index(query, "select ")
+	if start < 0 {
+		return query
+	}
+	seps := fpUnion.FindAllStringSubmatchIndex(query[start:], -1)
+	if len(seps) == 0 {
+		return query
+	}
+	first := query[start : start+seps[0][0]]
+	end, marker := start+len(first), ""
+	for i, sep := range seps {
+		partEnd := len(query) - start
+		if i+1 < len(seps) {
+			partEnd = seps[i+1][0]
+		}
+		if query[start+sep[1]:start+partEnd] != first {
+			break
+		}
+		end, marker = start+partEnd, query[start+sep[2]:start+sep[3]]
+	}
+	if marker == "" {
+		return query
+	}
+	return query[:start] + first + " /*repeat " + marker + "*/" + query[end:]
+}
+
+// queryChecksum is pt-query-digest's id of a fingerprint: the last 16 hex
+// digits of its MD5, in upper case
+func queryChecksum(fingerprint string) string {
+	sum := md5.Sum([]byte(fingerprint))
+	return strings.ToUpper(hex.EncodeToString(sum[:])[16:])
+}
+
+// queryClass is the statements of a session sharing a fingerprint
+type queryClass struct {
+	Checksum    string
+	Fingerprint string
+	Sample      string // the slowest statement, as pt-query-digest keeps it
+	SampleAt    time.Time
+	Database    string // where the sample ran
+	First, Last time.Time
+	slowest     float64
+	times       []float64 // seconds
+}
+
+// queryReview collects the statements of a session by fingerprint for
+// \review-export
+type queryReview struct {
+	classes map[string]*queryClass
+}
+
+// add counts a statement that ran without error
+func (r *queryReview) add(stmt, database string, start time.Time, elapsed time.Duration) {
+	fp := fingerprint(stmt)
+	if r.classes == nil {
+		r.classes = make(map[string]*queryClass)
+	}
+	c := r.classes[fp]
+	if c == nil {
+		c = &queryClass{Checksum: queryChecksum(fp), Fingerprint: fp, First: start}
+		r.classes[fp] = c
+	}
+	seconds := elapsed.Seconds()
+	if len(c.times) == 0 || seconds > c.slowest {
+		c.Sample, c.SampleAt, c.Database, c.slowest = stmt, start, database, seconds
+	}
+	c.times = append(c.times, seconds)
+	c.Last = start
+}
+
+// sorted returns the classes with the most total time first, as
+// pt-query-digest ranks them
+func (r *queryReview) sorted() []*queryClass {
+	classes := make([]*queryClass, 0, len(r.classes))
+	for _, c := range r.classes {
+		classes = append(classes, c)
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		if ti, tj := classes[i].stats().Sum, classes[j].stats().Sum; ti != tj {
+			return ti > tj
+		}
+		return classes[i].Checksum < classes[j].Checksum
+	})
+	return classes
+}
+
+// timeStats are the Query_time figures of pt-query-digest's history table
+type timeStats struct {
+	Count                 int
+	Sum, Min, Max, Avg    float64
+	Pct95, Stddev, Median float64
+}
+
+func (c *queryClass) stats() timeStats {
+	times := append([]float64(nil), c.times...)
+	sort.Float64s(times)
+	s := timeStats{Count: len(times), Min: times[0], Max: times[len(times)-1]}
+	for _, t := range times {
+		s.Sum += t
+	}
+	s.Avg = s.Sum / float64(s.Count)
+	for _, t := range times {
+		s.Stddev += (t - s.Avg) * (t - s.Avg)
+	}
+	s.Stddev = math.Sqrt(s.Stddev / float64(s.Count))
+	s.Pct95 = times[int(math.Ceil(0.95*float64(s.Count)))-1]
+	s.Median = times[(s.Count-1)/2]
+	return s
+}
+
+// exportReview implements \review-export <file>: the session's statements
+// by fingerprint, as SQL for pt-query-digest's query_review and
+// query_history tables (.sql) or in the shape of its JSON output (.json)
+func (p *PromptExecutor) exportReview(args string) {
+	path := strings.TrimSpace(args)
+	if path == "" {
+		fmt.Println("Usage: \\review-export <file.sql|file.json>")
+		return
+	}
+	classes := p.review.sorted()
+	if len(classes) == 0 {
+		fmt.Println("No statements have run yet")
+		return
+	}
+	var out string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".sql":
+		out = reviewSQL(classes)
+	case ".json":
+		data, err := json.MarshalIndent(reviewJSON(classes), "", "  ")
+		if err != nil {
+			fmt.Printf(tr("Error: %v\n"), err)
+			return
+		}
+		out = string(data) + "\n"
+	default:
+		fmt.Println("The file must end in .sql or .json")
+		return
+	}
+	if err := os.WriteFile(expandHome(path), []byte(out), 0o644); err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return
+	}
+	fmt.Printf("Wrote %d fingerprint%s to %s\n", len(classes), plural(len(classes)), path)
+}
+
+// reviewTimestamp is how the tables and the JSON write times
+func reviewTimestamp(t time.Time) string {
+	return t.Format("2006-01-02 15:04:05.000000")
+}
+
+// reviewSQL writes the tables as pt-query-digest --review and --history
+// create them, and rows that merge with what is already there: first and
+// last seen are widened and history rows are replaced
+func reviewSQL(classes []*queryClass) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "-- Query review from a go-mycli session, for pt-query-digest --review %s --history %s\n", queryReviewTable, queryHistoryName)
+	fmt.Fprintf(&b, `CREATE TABLE IF NOT EXISTS %s (
+  checksum     CHAR(32) NOT NULL PRIMARY KEY,
+  fingerprint  TEXT NOT NULL,
+  sample       TEXT NOT NULL,
+  first_seen   DATETIME,
+  last_seen    DATETIME,
+  reviewed_by  VARCHAR(20),
+  reviewed_on  DATETIME,
+  comments     TEXT
+);
+CREATE TABLE IF NOT EXISTS %s (
+  checksum            CHAR(32) NOT NULL,
+  sample              TEXT NOT NULL,
+  ts_min              DATETIME(6),
+  ts_max              DATETIME(6),
+  ts_cnt              FLOAT,
+  Query_time_sum      FLOAT,
+  Query_time_min      FLOAT,
+  Query_time_max      FLOAT,
+  Query_time_pct_95   FLOAT,
+  Query_time_stddev   FLOAT,
+  Query_time_median   FLOAT,
+  PRIMARY KEY(checksum, ts_min, ts_max)
+);
+`, queryReviewTable, queryHistoryName)
+	for _, c := range classes {
+		s := c.stats()
+		fingerprint, sample := c.Fingerprint, c.Sample
+		first, last := reviewTimestamp(c.First), reviewTimestamp(c.Last)
+		fmt.Fprintf(&b, "\nINSERT INTO %s (checksum, fingerprint, sample, first_seen, last_seen)\n"+
+			"  VALUES ('%s', %s, %s, '%s', '%s')\n"+
+			"  ON DUPLICATE KEY UPDATE first_seen = LEAST(COALESCE(first_seen, VALUES(first_seen)), VALUES(first_seen)),\n"+
+			"    last_seen = GREATEST(COALESCE(last_seen, VALUES(last_seen)), VALUES(last_seen));\n",
+			queryReviewTable, c.Checksum, sqlLiteral(&fingerprint), sqlLiteral(&sample), first[:19], last[:19])
+		fmt.Fprintf(&b, "REPLACE INTO %s (checksum, sample, ts_min, ts_max, ts_cnt, Query_time_sum, Query_time_min, Query_time_max,\n"+
+			"    Query_time_pct_95, Query_time_stddev, Query_time_median)\n"+
+			"  VALUES ('%s', %s, '%s', '%s', %d, %s, %s, %s, %s, %s, %s);\n",
+			queryHistoryName, c.Checksum, sqlLiteral(&sample), first, last, s.Count,
+			reviewNumber(s.Sum), reviewNumber(s.Min), reviewNumber(s.Max), reviewNumber(s.Pct95), reviewNumber(s.Stddev), reviewNumber(s.Median))
+	}
+	return b.String()
+}
+
+// reviewNumber writes a time in seconds with microsecond precision
+func reviewNumber(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', 6, 64)
+}
+
+// reviewDocument is pt-query-digest's --output json: a global summary and
+// one entry per query class, numbers written as strings the way it does
+type reviewDocument struct {
+	Global  reviewGlobal       `json:"global"`
+	Classes []reviewClassEntry `json:"classes"`
+}
+
+type reviewGlobal struct {
+	QueryCount       int `json:"query_count"`
+	UniqueQueryCount int `json:"unique_query_count"`
+}
+
+type reviewClassEntry struct {
+	Checksum    string                       `json:"checksum"`
+	Fingerprint string                       `json:"fingerprint"`
+	Attribute   string                       `json:"attribute"`
+	QueryCount  int                          `json:"query_count"`
+	TsMin       string                       `json:"ts_min"`
+	TsMax       string                       `json:"ts_max"`
+	Example     reviewExample                `json:"example"`
+	Metrics     map[string]map[string]string `json:"metrics"`
+}
+
+type reviewExample struct {
+	Query string `json:"query"`
+	Ts    string `json:"ts"`
+	DB    string `json:"db,omitempty"`
+}
+
+func reviewJSON(classes []*queryClass) reviewDocument {
+	doc := reviewDocument{Global: reviewGlobal{UniqueQueryCount: len(classes)}}
+	for _, c := range classes {
+		s := c.stats()
+		doc.Global.QueryCount += s.Count
+		metrics := map[string]map[string]string{
+			"Query_time": {
+				"sum": reviewNumber(s.Sum), "min": reviewNumber(s.Min), "max": reviewNumber(s.Max), "avg": reviewNumber(s.Avg),
+				"pct_95": reviewNumber(s.Pct95), "stddev": reviewNumber(s.Stddev), "median": reviewNumber(s.Median),
+			},
+		}
+		if c.Database != "" {
+			metrics["db"] = map[string]string{"value": c.Database}
+		}
+		doc.Classes = append(doc.Classes, reviewClassEntry{
+			Checksum:    c.Checksum,
+			Fingerprint: c.Fingerprint,
+			Attribute:   "fingerprint",
+			QueryCount:  s.Count,
+			TsMin:       reviewTimestamp(c.First),
+			TsMax:       reviewTimestamp(c.Last),
+			Example:     reviewExample{Query: c.Sample, Ts: reviewTimestamp(c.SampleAt), DB: c.Database},
+			Metrics:     metrics,
+		})
+	}
+	return doc
+}
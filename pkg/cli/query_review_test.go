@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		query, want string
+	}{
+		{"SELECT * FROM t1 WHERE id = 5", "select * from t? where id = ?"},
+		{"select  name\n FROM users WHERE email = 'a@b.c' AND active = TRUE", "select name from users where email = ? and active = ?"},
+		{"SELECT * FROM film WHERE film_id IN (1, 2, 3)", "select * from film where film_id in(?+)"},
+		{"INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y')", "insert into t (a, b) values(?+)"},
+		{"SELECT a FROM t WHERE b = \"x\" LIMIT 10, 20", "select a from t where b = ? limit ?"},
+		{"SELECT * FROM t WHERE a IS NULL ORDER BY a ASC, b", "select * from t where a is ? order by a, b"},
+		{"/* app:42 */ SELECT 1 -- trailing", "select ?"},
+		{"SELECT a FROM t WHERE x = 1 UNION ALL SELECT a FROM t WHERE x = 2", "select a from t where x = ? /*repeat union all*/"},
+		{"SELECT 'it\\'s'", "select ?"},
+		{"CALL refresh_stats(1, 'x')", "call refresh_stats"},
+		{"use sakila", "use ?"},
+	}
+	for _, test := range tests {
+		if got := fingerprint(test.query); got != test.want {
+			t.Errorf("%q: got %q, expected %q", test.query, got, test.want)
+		}
+	}
+}
+
+func TestQueryChecksum(t *testing.T) {
+	got := queryChecksum("select ?")
+	if len(got) != 16 || strings.ToUpper(got) != got || got != queryChecksum("select ?") || got == queryChecksum("select ? from t") {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestQueryReview(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	var r queryReview
+	r.add("SELECT * FROM film WHERE film_id = 1", "sakila", start, 10*time.Millisecond)
+	r.add("SELECT * FROM film WHERE film_id = 2", "sakila", start.Add(time.Minute), 30*time.Millisecond)
+	r.add("SELECT * FROM film WHERE film_id = 3", "sakila", start.Add(2*time.Minute), 20*time.Millisecond)
+	r.add("SHOW TABLES", "sakila", start, 5*time.Millisecond)
+
+	classes := r.sorted()
+	if len(classes) != 2 || classes[0].Fingerprint != "select * from film where film_id = ?" {
+		t.Fatalf("got %d classes", len(classes))
+	}
+	c := classes[0]
+	s := c.stats()
+	if s.Count != 3 || c.Sample != "SELECT * FROM film WHERE film_id = 2" || s.Median != 0.02 || s.Pct95 != 0.03 || s.Min != 0.01 {
+		t.Errorf("got %+v, sample %q", s, c.Sample)
+	}
+
+	out := reviewSQL(classes)
+	for _, want := range []string{
+		"CREATE TABLE IF NOT EXISTS query_review",
+		"VALUES ('" + c.Checksum + "', 'select * from film where film_id = ?', 'SELECT * FROM film WHERE film_id = 2', '2024-05-01 10:00:00', '2024-05-01 10:02:00')",
+		"'2024-05-01 10:00:00.000000', '2024-05-01 10:02:00.000000', 3, 0.060000, 0.010000, 0.030000, 0.030000,",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in\n%s", want, out)
+		}
+	}
+
+	data, err := json.Marshal(reviewJSON(classes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	first := doc["classes"].([]any)[0].(map[string]any)
+	if first["checksum"] != c.Checksum || first["query_count"] != 3.0 ||
+		first["metrics"].(map[string]any)["Query_time"].(map[string]any)["sum"] != "0.060000" {
+		t.Errorf("got %s", data)
+	}
+}
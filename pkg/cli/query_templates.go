@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+)
+
+// queryTemplates are whole statements offered by Tab on an empty line and
+// while typing the first word of a statement. ${...} marks the places to
+// fill in, which Tab and Ctrl-] move between.
+var queryTemplates = []prompt.Suggest{
+	{Text: "SELECT ${columns}, COUNT(*) FROM ${table} GROUP BY ${columns} ORDER BY COUNT(*) DESC", Description: "Template: count per group"},
+	{Text: "SELECT ${columns} FROM ${table} WHERE ${condition} ORDER BY ${column} LIMIT 100", Description: "Template: filtered rows"},
+	{Text: "SELECT ${columns} FROM ${table} a JOIN ${table} b ON ${condition} WHERE ${condition}", Description: "Template: join"},
+	{Text: "SELECT ${columns}, ROW_NUMBER() OVER (PARTITION BY ${column} ORDER BY ${column}) AS n FROM ${table}", Description: "Template: window function"},
+	{Text: "WITH ${name} AS (SELECT ${columns} FROM ${table}) SELECT * FROM ${name}", Description: "Template: common table expression"},
+	{Text: "INSERT INTO ${table} (${columns}) VALUES (${values}) ON DUPLICATE KEY UPDATE ${column} = VALUES(${column})", Description: "Template: insert or update"},
+	{Text: "UPDATE ${table} SET ${column} = ${value} WHERE ${condition}", Description: "Template: update"},
+	{Text: "DELETE FROM ${table} WHERE ${condition} LIMIT 1000", Description: "Template: delete in batches"},
+}
+
+// upsertAlias is the insert or update template for MySQL 8.0.19 and later,
+// where VALUES() in ON DUPLICATE KEY UPDATE is deprecated
+const upsertAlias = "INSERT INTO ${table} (${columns}) VALUES (${values}) AS new ON DUPLICATE KEY UPDATE ${column} = new.${column}"
+
+// templateSuggestions returns the templates, with the insert or update one
+// in the form the server prefers
+func (p *PromptExecutor) templateSuggestions() []prompt.Suggest {
+	templates := make([]prompt.Suggest, len(queryTemplates))
+	copy(templates, queryTemplates)
+	if s := p.serverInfo(); (s.Flavor == flavorMySQL || s.Flavor == flavorPercona) && s.atLeast(8, 0, 19) {
+		for i, t := range templates {
+			if strings.HasPrefix(t.Text, "INSERT") {
+				templates[i].Text = upsertAlias
+			}
+		}
+	}
+	return templates
+}
+
+// tabPlaceholder is bound to Tab. While the popup is open Tab moves
+// through it as usual; otherwise Tab on an empty line opens the templates
+// and on a line with placeholders goes to the next one. go-prompt keeps the
+// popup's selection while a key binding runs, so the line must not change
+// under an open popup.
+func (p *PromptExecutor) tabPlaceholder(buf *prompt.Buffer) {
+	if len(p.Completer(*buf.Document())) > 0 {
+		return
+	}
+	if buf.Text() == "" && p.buffer == "" {
+		p.showTemplates = true
+		return
+	}
+	nextPlaceholder(buf)
+}
+
+// nextPlaceholder, bound to Ctrl-], removes the next placeholder after the
+// cursor, starting over from the beginning of the line, and leaves the
+// cursor in its place. Unlike Tab it also works with the popup open, taking
+// the selected suggestion first as any other key does.
+func nextPlaceholder(buf *prompt.Buffer) {
+	text := buf.Text()
+	before := buf.Document().TextBeforeCursor()
+	start, end, ok := findPlaceholder(text, len(before))
+	if !ok {
+		return
+	}
+	cursor := len([]rune(before))
+	if to := len([]rune(text[:end])); to > cursor {
+		buf.CursorRight(to - cursor)
+	} else {
+		buf.CursorLeft(cursor - to)
+	}
+	buf.DeleteBeforeCursor(len([]rune(text[start:end])))
+}
+
+// findPlaceholder returns the byte range of the first ${...} at or after
+// from, or of the first one in text if none follows
+func findPlaceholder(text string, from int) (int, int, bool) {
+	for _, at := range []int{from, 0} {
+		start := strings.Index(text[at:], "${")
+		if start < 0 {
+			continue
+		}
+		start += at
+		end := strings.IndexByte(text[start:], '}')
+		if end < 0 {
+			continue
+		}
+		return start, start + end + 1, true
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c-bata/go-prompt"
+)
+
+func TestFindPlaceholder(t *testing.T) {
+	text := "SELECT ${columns} FROM ${table}"
+	tests := []struct {
+		from       int
+		start, end int
+		ok         bool
+	}{
+		{0, 7, 17, true},
+		{8, 23, 31, true},
+		{31, 7, 17, true}, // past the last one, start over
+	}
+	for _, tt := range tests {
+		start, end, ok := findPlaceholder(text, tt.from)
+		if start != tt.start || end != tt.end || ok != tt.ok {
+			t.Errorf("findPlaceholder(%d) = %d, %d, %v; want %d, %d, %v", tt.from, start, end, ok, tt.start, tt.end, tt.ok)
+		}
+	}
+	if _, _, ok := findPlaceholder("SELECT 1", 0); ok {
+		t.Error("found a placeholder in SELECT 1")
+	}
+}
+
+func TestTabPlaceholder(t *testing.T) {
+	p := &PromptExecutor{
+		tables:         []string{"users"},
+		columns:        map[string][]string{"users": {"id", "name"}},
+		cacheTime:      time.Now(),
+		completionMode: completionFull,
+	}
+
+	// Tab on an empty line opens the templates
+	buf := prompt.NewBuffer()
+	p.tabPlaceholder(buf)
+	if got := p.Completer(*buf.Document()); len(got) != len(queryTemplates) {
+		t.Fatalf("expected the %d templates, got %v", len(queryTemplates), got)
+	}
+
+	// From the end of the line Tab starts over at the first placeholder
+	buf.InsertText("SELECT ${columns} FROM ${table} WHERE ${condition}", false, true)
+	if got := p.Completer(*buf.Document()); got != nil {
+		t.Errorf("completion at a placeholder: %v", got)
+	}
+	p.tabPlaceholder(buf)
+	if got, want := buf.Document().TextBeforeCursor(), "SELECT "; got != want || buf.Text() != "SELECT  FROM ${table} WHERE ${condition}" {
+		t.Errorf("after Tab: %q with the cursor after %q", buf.Text(), got)
+	}
+
+	// While the popup is open Tab leaves the line alone
+	buf.InsertText("na", false, true)
+	p.tabPlaceholder(buf)
+	if got := buf.Text(); got != "SELECT na FROM ${table} WHERE ${condition}" {
+		t.Errorf("Tab with the popup open changed the line to %q", got)
+	}
+}
+
+func TestNextPlaceholder(t *testing.T) {
+	buf := prompt.NewBuffer()
+	buf.InsertText("SELECT ${columns} FROM ${table} WHERE ${condition}", false, true)
+	steps := []struct{ typed, before, text string }{
+		{"", "SELECT ", "SELECT  FROM ${table} WHERE ${condition}"},
+		{"id", "SELECT id FROM ", "SELECT id FROM  WHERE ${condition}"},
+		{"users", "SELECT id FROM users WHERE ", "SELECT id FROM users WHERE "},
+		{"id = 1", "SELECT id FROM users WHERE id = 1", "SELECT id FROM users WHERE id = 1"},
+	}
+	for _, step := range steps {
+		buf.InsertText(step.typed, false, true)
+		nextPlaceholder(buf)
+		if got := buf.Document().TextBeforeCursor(); got != step.before || buf.Text() != step.text {
+			t.Errorf("after typing %q: %q with the cursor after %q, want %q after %q", step.typed, buf.Text(), got, step.text, step.before)
+		}
+	}
+}
+
+func TestTemplatesAtStatementStart(t *testing.T) {
+	p := &PromptExecutor{
+		tables:         []string{"users"},
+		columns:        map[string][]string{"users": {"id"}},
+		cacheTime:      time.Now(),
+		completionMode: completionFull,
+	}
+	complete := func(line string) []prompt.Suggest {
+		buf := prompt.NewBuffer()
+		buf.InsertText(line, false, true)
+		return p.Completer(*buf.Document())
+	}
+	found := false
+	for _, s := range complete("ins") {
+		found = found || strings.HasPrefix(s.Text, "INSERT INTO ${table}")
+	}
+	if !found {
+		t.Error("no INSERT template for \"ins\"")
+	}
+	for _, s := range complete("SELECT * FROM users WHERE i") {
+		if strings.Contains(s.Text, "${") {
+			t.Errorf("template offered inside a statement: %q", s.Text)
+		}
+	}
+}
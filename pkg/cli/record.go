@@ -0,0 +1,269 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// replaySlowest is how many of the slowest replayed statements are listed,
+// each cut to replayStatementWidth characters
+const (
+	replaySlowest        = 5
+	replayStatementWidth = 80
+)
+
+// recordedStatement is one line of a \record capture: a statement as it was
+// sent to the server, when and in which database it ran, and how it went
+type recordedStatement struct {
+	At       time.Time `json:"at"`
+	Database string    `json:"database,omitempty"`
+	Duration float64   `json:"duration_ms"`
+	SQL      string    `json:"sql"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// workloadRecording is a \record capture in progress
+type workloadRecording struct {
+	path  string
+	file  *os.File
+	enc   *json.Encoder
+	count int
+}
+
+// record implements \record start <file>, \record stop and \record, which
+// shows whether a capture is running
+func (p *PromptExecutor) record(args string) {
+	action, path, _ := strings.Cut(strings.TrimSpace(args), " ")
+	path = strings.TrimSpace(path)
+	switch {
+	case action == "start" && path != "":
+		if p.recording != nil {
+			fmt.Printf("Already recording to %s; \\record stop first\n", p.recording.path)
+			return
+		}
+		path = expandHome(path)
+		f, err := os.Create(path)
+		if err != nil {
+			fmt.Printf(tr("Error: %v\n"), err)
+			return
+		}
+		p.recording = &workloadRecording{path: path, file: f, enc: json.NewEncoder(f)}
+		fmt.Printf("Recording statements to %s\n", path)
+	case action == "stop":
+		if p.recording == nil {
+			fmt.Println("Not recording")
+			return
+		}
+		path, count := p.recording.path, p.recording.count
+		p.stopRecording()
+		fmt.Printf("Recorded %d statement%s to %s; replay with: go-mycli replay %s\n", count, plural(count), path, path)
+	case action == "":
+		if p.recording == nil {
+			fmt.Println("Not recording")
+		} else {
+			fmt.Printf("Recording to %s (%d statement%s so far)\n", p.recording.path, p.recording.count, plural(p.recording.count))
+		}
+	default:
+		fmt.Println("Usage: \\record start <file> | \\record stop")
+	}
+}
+
+// recordStatement adds a statement that was run to the capture, if one is
+// running
+func (p *PromptExecutor) recordStatement(stmt string, start time.Time, elapsed time.Duration, err error) {
+	if p.recording == nil {
+		return
+	}
+	rec := recordedStatement{At: start, Database: p.database, Duration: float64(elapsed.Microseconds()) / 1000, SQL: stmt}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	if err := p.recording.enc.Encode(rec); err != nil {
+		fmt.Printf("Recording stopped: %v\n", err)
+		p.stopRecording()
+		return
+	}
+	p.recording.count++
+}
+
+// stopRecording closes the capture, if one is running
+func (p *PromptExecutor) stopRecording() {
+	if p.recording != nil {
+		p.recording.file.Close()
+		p.recording = nil
+	}
+}
+
+// readRecording reads the statements of a \record capture
+func readRecording(r io.Reader) ([]recordedStatement, error) {
+	var statements []recordedStatement
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var s recordedStatement
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		statements = append(statements, s)
+	}
+	return statements, scanner.Err()
+}
+
+// replayOffset is when, from the start of a replay, statement s is due: its
+// time into the recording divided by speed. A speed of 0 runs every
+// statement as soon as the last one finishes.
+func replayOffset(first, s time.Time, speed float64) time.Duration {
+	if speed <= 0 {
+		return 0
+	}
+	return time.Duration(float64(s.Sub(first)) / speed)
+}
+
+// ReplayOptions configures `go-mycli replay`
+type ReplayOptions struct {
+	File  string
+	Speed float64 // 1 keeps the recorded pace, 2 replays twice as fast, 0 without pauses
+}
+
+// replayedStatement is a statement of a replay with its recorded and
+// replayed durations
+type replayedStatement struct {
+	recordedStatement
+	Replayed time.Duration
+}
+
+// Replay runs the statements of a \record capture against a server, one
+// at a time on a single connection and at the recorded pace scaled by
+// opts.Speed, then reports how long they took compared with the recording.
+// Statements that fail, where they did not when recorded, are reported and
+// the replay goes on.
+func Replay(host string, port int, user, password, database, socket, loginPath, configFile string, zstdCompressionLevel int, opts ReplayOptions) error {
+	if opts.Speed < 0 {
+		return fmt.Errorf("--speed must not be negative")
+	}
+	f, err := os.Open(opts.File)
+	if err != nil {
+		return err
+	}
+	statements, err := readRecording(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %w", opts.File, err)
+	}
+	if len(statements) == 0 {
+		return fmt.Errorf("%s has no statements", opts.File)
+	}
+
+	cfg := LoadSyntaxConfig()
+	SetSessionVariables(cfg.Session)
+	db, _, _, err := connect(host, port, user, password, database, socket, loginPath, configFile, zstdCompressionLevel)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// One connection, so USE and SET carry over like in the recorded
+	// session. Ctrl-C stops the replay and the statement it is running.
+	p := &PromptExecutor{db: db}
+	ctx, stop := p.statementContext()
+	defer stop()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	id, err := connectionID(ctx, conn)
+	if err != nil {
+		return err
+	}
+	defer p.killOnCancel(ctx, id)()
+
+	replayed := make([]replayedStatement, 0, len(statements))
+	failed := 0
+	current := database
+	start := time.Now()
+	for i, s := range statements {
+		if wait := replayOffset(statements[0].At, s.At, opts.Speed) - time.Since(start); wait > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(wait):
+			}
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("replay stopped after %d of %d statements: %w", i, len(statements), errCanceled)
+		}
+		if s.Database != "" && s.Database != current {
+			if _, err := conn.ExecContext(ctx, "USE "+quoteIdentifier(s.Database)); err != nil {
+				fmt.Printf("Statement %d: USE %s: %v\n", i+1, s.Database, err)
+			}
+			current = s.Database
+		}
+		began := time.Now()
+		err := runReplayed(ctx, conn, s.SQL)
+		elapsed := time.Since(began)
+		// Statements that failed when recorded are expected to fail again
+		if err != nil && s.Error == "" {
+			failed++
+			fmt.Printf("Statement %d failed: %v\n  %s\n", i+1, err, truncateQuery(s.SQL, replayStatementWidth))
+		}
+		replayed = append(replayed, replayedStatement{recordedStatement: s, Replayed: elapsed})
+	}
+	total := time.Since(start)
+
+	recorded := statements[len(statements)-1].At.Sub(statements[0].At)
+	fmt.Printf("Replayed %d statement%s in %.1fs (recorded over %.1fs", len(statements), plural(len(statements)), total.Seconds(), recorded.Seconds())
+	if opts.Speed > 0 && opts.Speed != 1 {
+		fmt.Printf(", at %gx", opts.Speed)
+	}
+	fmt.Printf("); %d failed\n", failed)
+	printSlowestReplayed(os.Stdout, replayed)
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d statements failed", failed, len(statements))
+	}
+	return nil
+}
+
+// runReplayed runs one statement and reads its result, if it has one, to
+// the end
+func runReplayed(ctx context.Context, conn *sql.Conn, stmt string) error {
+	rows, err := conn.QueryContext(ctx, stmt)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	return rows.Err()
+}
+
+// printSlowestReplayed lists the statements that took longest to replay,
+// with how long they took when recorded
+func printSlowestReplayed(w io.Writer, replayed []replayedStatement) {
+	sorted := append([]replayedStatement(nil), replayed...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Replayed > sorted[j].Replayed })
+	if len(sorted) > replaySlowest {
+		sorted = sorted[:replaySlowest]
+	}
+	rows := make([][]string, len(sorted))
+	for i, s := range sorted {
+		rows[i] = []string{
+			fmt.Sprintf("%.1f ms", float64(s.Replayed.Microseconds())/1000),
+			fmt.Sprintf("%.1f ms", s.Duration),
+			truncateQuery(s.SQL, replayStatementWidth),
+		}
+	}
+	fmt.Fprintln(w, "\nSlowest statements:")
+	fmt.Fprint(w, formatMySQLTable([]string{"Replayed", "Recorded", "Statement"}, rows))
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordRoundTrip(t *testing.T) {
+	path := t.TempDir() + "/workload.jsonl"
+	p := &PromptExecutor{database: "shop"}
+	p.record("start " + path)
+	if p.recording == nil {
+		t.Fatal("recording did not start")
+	}
+	start := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	p.recordStatement("SELECT * FROM orders WHERE id = 1", start, 1500*time.Microsecond, nil)
+	p.database = "billing"
+	p.recordStatement("UPDATE invoices SET paid = 1", start.Add(2*time.Second), 3*time.Millisecond, errors.New("lock wait timeout"))
+	p.record("stop")
+	if p.recording != nil {
+		t.Fatal("recording did not stop")
+	}
+	p.recordStatement("SELECT 1", start, 0, nil) // not recording any more
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got, err := readRecording(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []recordedStatement{
+		{At: start, Database: "shop", Duration: 1.5, SQL: "SELECT * FROM orders WHERE id = 1"},
+		{At: start.Add(2 * time.Second), Database: "billing", Duration: 3, SQL: "UPDATE invoices SET paid = 1", Error: "lock wait timeout"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v", got)
+	}
+	for i := range want {
+		if !got[i].At.Equal(want[i].At) || got[i].Database != want[i].Database || got[i].Duration != want[i].Duration || got[i].SQL != want[i].SQL || got[i].Error != want[i].Error {
+			t.Errorf("statement %d: got %+v, expected %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestReadRecordingReportsBadLines(t *testing.T) {
+	_, err := readRecording(strings.NewReader("{\"sql\": \"SELECT 1\"}\n\nnot json\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3:") {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestReplayOffset(t *testing.T) {
+	first := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	at := first.Add(10 * time.Second)
+	tests := []struct {
+		speed float64
+		want  time.Duration
+	}{
+		{1, 10 * time.Second},
+		{4, 2500 * time.Millisecond},
+		{0.5, 20 * time.Second},
+		{0, 0},
+	}
+	for _, test := range tests {
+		if got := replayOffset(first, at, test.speed); got != test.want {
+			t.Errorf("speed %g: got %v, expected %v", test.speed, got, test.want)
+		}
+	}
+}
+
+func TestPrintSlowestReplayed(t *testing.T) {
+	var replayed []replayedStatement
+	for i := 1; i <= 7; i++ {
+		replayed = append(replayed, replayedStatement{
+			recordedStatement: recordedStatement{SQL: "SELECT " + strings.Repeat("x", i), Duration: 1},
+			Replayed:          time.Duration(i) * time.Millisecond,
+		})
+	}
+	var buf bytes.Buffer
+	printSlowestReplayed(&buf, replayed)
+	out := buf.String()
+	if !strings.Contains(out, "| 7.0 ms   | 1.0 ms   | SELECT xxxxxxx |") || strings.Contains(out, "SELECT xx ") {
+		t.Errorf("unexpected listing:\n%s", out)
+	}
+}
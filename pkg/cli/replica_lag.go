@@ -0,0 +1,289 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// replicaLagPoll is how often a paused write checks the lag again
+	replicaLagPoll = time.Second
+	// defaultMaxReplicaLag is the lag writes pause at unless max_replica_lag
+	// says otherwise
+	defaultMaxReplicaLag = 5 * time.Second
+)
+
+// heavyWrites are the statements that wait for the replicas while
+// replica_lag_wait (\replica-lag on) is set
+var heavyWrites = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "REPLACE": true, "LOAD": true,
+	"ALTER": true, "CREATE": true, "DROP": true, "TRUNCATE": true, "RENAME": true, "OPTIMIZE": true,
+}
+
+// lagSettings are the replica lag options of the config
+type lagSettings struct {
+	Max       time.Duration // pause writes while a replica is further behind, 0 to never wait
+	Replicas  []string      // option file groups or host[:port]; empty for the replicas SHOW REPLICAS lists
+	Heartbeat string        // pt-heartbeat table to measure lag with instead of Seconds_Behind_Source
+	Wait      bool          // wait before every write typed at the prompt
+}
+
+// lagReplica is a replica whose lag is watched
+type lagReplica struct {
+	Name string
+	DB   *sql.DB
+}
+
+// replicaLag is the lag of one replica, -1 when it is not replicating or
+// the lag cannot be read
+type replicaLag struct {
+	Name string
+	Lag  time.Duration
+	Err  error
+}
+
+// replicaLagChecker pauses heavy writes while a watched replica is further
+// behind than max
+type replicaLagChecker struct {
+	max       time.Duration
+	heartbeat string
+	replicas  []lagReplica
+}
+
+// newReplicaLagChecker connects to the replicas of lag_replicas, or else to
+// those SHOW REPLICAS lists, as \topology does, and says which it watches.
+// Replicas it cannot reach are reported and left out.
+func (p *PromptExecutor) newReplicaLagChecker(ctx context.Context, max time.Duration) *replicaLagChecker {
+	c := &replicaLagChecker{max: max, heartbeat: p.lag.Heartbeat}
+	var skipped []string
+	connect := func(name, host string, port int) {
+		db, err := p.openReplica(ctx, host, port)
+		if err != nil {
+			skipped = append(skipped, name)
+			return
+		}
+		c.replicas = append(c.replicas, lagReplica{Name: name, DB: db})
+	}
+	if len(p.lag.Replicas) > 0 {
+		for _, name := range p.lag.Replicas {
+			host, port := lagReplicaAddress(name)
+			connect(name, host, port)
+		}
+	} else {
+		_, hosts, err := queryFirst(ctx, p.db, "SHOW REPLICAS", "SHOW SLAVE HOSTS")
+		if err != nil {
+			fmt.Printf("Replica lag not checked: %v\n", err)
+			return c
+		}
+		for _, host := range hosts {
+			if len(host) < 3 || host[1] == "" {
+				skipped = append(skipped, "a replica without report_host")
+				continue
+			}
+			port, _ := strconv.Atoi(host[2])
+			connect(host[1]+":"+host[2], host[1], port)
+		}
+	}
+
+	switch {
+	case len(c.replicas) > 0:
+		names := make([]string, len(c.replicas))
+		for i, r := range c.replicas {
+			names[i] = r.Name
+		}
+		fmt.Printf("Pausing while replica lag is over %s on %s\n", max, strings.Join(names, ", "))
+	case len(skipped) == 0:
+		fmt.Println("No replicas connected; replica lag not checked")
+	}
+	if len(skipped) > 0 {
+		fmt.Printf("Lag not checked on %s (cannot connect)\n", strings.Join(skipped, ", "))
+	}
+	return c
+}
+
+// lagReplicaAddress resolves an entry of lag_replicas: an option file group
+// with a host, or host[:port]
+func lagReplicaAddress(name string) (string, int) {
+	host, portText, _ := strings.Cut(name, ":")
+	profile := readServerProfile(host, "")
+	port := profile.Port
+	if p, err := strconv.Atoi(portText); err == nil {
+		port = p
+	}
+	if port == 0 {
+		port = 3306
+	}
+	return profile.Host, port
+}
+
+// lags reads the lag of every watched replica
+func (c *replicaLagChecker) lags(ctx context.Context) []replicaLag {
+	lags := make([]replicaLag, len(c.replicas))
+	for i, r := range c.replicas {
+		lags[i] = replicaLag{Name: r.Name}
+		lags[i].Lag, lags[i].Err = c.lagOf(ctx, r.DB)
+	}
+	return lags
+}
+
+// lagOf measures a replica's lag: the age of the newest pt-heartbeat row
+// when heartbeat_table is set, Seconds_Behind_Source otherwise
+func (c *replicaLagChecker) lagOf(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	if c.heartbeat != "" {
+		database, table := splitTableName(c.heartbeat, "")
+		name := quoteIdentifier(table)
+		if database != "" {
+			name = quoteIdentifier(database) + "." + name
+		}
+		var micros sql.NullInt64
+		if err := db.QueryRowContext(ctx, "SELECT TIMESTAMPDIFF(MICROSECOND, MAX(ts), NOW(6)) FROM "+name).Scan(&micros); err != nil {
+			return -1, err
+		}
+		if !micros.Valid {
+			return -1, fmt.Errorf("%s is empty", c.heartbeat)
+		}
+		return time.Duration(max(micros.Int64, 0)) * time.Microsecond, nil
+	}
+	status, err := replicaStatus(ctx, db)
+	if err != nil {
+		return -1, err
+	}
+	if status == nil {
+		return -1, fmt.Errorf("not a replica")
+	}
+	seconds, err := strconv.Atoi(statusValue(status, "Seconds_Behind_Source", "Seconds_Behind_Master"))
+	if err != nil {
+		return -1, fmt.Errorf("not replicating")
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// worstLag returns the lag that most holds up writes: a replica that is not
+// replicating first, then the one furthest behind
+func worstLag(lags []replicaLag) replicaLag {
+	var worst replicaLag
+	for _, l := range lags {
+		if l.Lag < 0 {
+			return l
+		}
+		if l.Lag > worst.Lag {
+			worst = l
+		}
+	}
+	return worst
+}
+
+// wait returns once every replica is within max, saying what it waits for
+// in the meantime. It returns early with ctx's error.
+func (c *replicaLagChecker) wait(ctx context.Context) error {
+	if c == nil || c.max <= 0 || len(c.replicas) == 0 {
+		return nil
+	}
+	waited := false
+	for {
+		worst := worstLag(c.lags(ctx))
+		if worst.Lag >= 0 && worst.Lag <= c.max {
+			if waited {
+				fmt.Printf("\rReplica lag is back under %s; continuing%s\n", c.max, strings.Repeat(" ", 20))
+			}
+			return nil
+		}
+		state := fmt.Sprintf("%s behind", worst.Lag.Round(time.Millisecond))
+		if worst.Lag < 0 {
+			state = worst.Err.Error()
+		}
+		fmt.Printf("\rWaiting: %s is %s (max %s)   ", worst.Name, state, c.max)
+		waited = true
+		select {
+		case <-time.After(replicaLagPoll):
+		case <-ctx.Done():
+			fmt.Println()
+			return ctx.Err()
+		}
+	}
+}
+
+// close closes the connections to the replicas
+func (c *replicaLagChecker) close() {
+	if c == nil {
+		return
+	}
+	for _, r := range c.replicas {
+		r.DB.Close()
+	}
+}
+
+// waitForReplicas holds a write typed at the prompt while replicas lag, when
+// replica_lag_wait is on. It reports false if the wait was interrupted.
+func (p *PromptExecutor) waitForReplicas(ctx context.Context, stmt string) bool {
+	if !p.lag.Wait || p.lag.Max <= 0 || !heavyWrites[firstWord(statementBody(stmt))] {
+		return true
+	}
+	if p.lagChecker == nil {
+		p.lagChecker = p.newReplicaLagChecker(ctx, p.lag.Max)
+	}
+	return p.lagChecker.wait(ctx) == nil
+}
+
+// replicaLagCommand implements \replica-lag [on|off|<max lag>]: the lag of
+// the watched replicas, whether writes wait for them, and the limit
+func (p *PromptExecutor) replicaLagCommand(args string) {
+	args = strings.ToLower(strings.TrimSpace(args))
+	switch args {
+	case "":
+	case "on":
+		p.lag.Wait = true
+	case "off":
+		p.lag.Wait = false
+		p.lagChecker.close()
+		p.lagChecker = nil
+	default:
+		d, err := time.ParseDuration(args)
+		if err != nil || d < 0 {
+			fmt.Println("Usage: \\replica-lag [on|off|<max lag, e.g. 10s>]")
+			return
+		}
+		p.lag.Max = d
+		if p.lagChecker != nil {
+			p.lagChecker.max = d
+		}
+	}
+
+	wait := "off"
+	if p.lag.Wait {
+		wait = "on"
+	}
+	source := "Seconds_Behind_Source"
+	if p.lag.Heartbeat != "" {
+		source = p.lag.Heartbeat
+	}
+	fmt.Printf("Waiting before writes: %s; max lag %s (%s)\n", wait, p.lag.Max, source)
+	if args != "" {
+		return
+	}
+
+	ctx, stop := p.statementContext()
+	defer stop()
+	checker := p.lagChecker
+	if checker == nil {
+		checker = p.newReplicaLagChecker(ctx, p.lag.Max)
+		defer checker.close()
+	}
+	lags := checker.lags(ctx)
+	if len(lags) == 0 {
+		return
+	}
+	rows := make([][]string, len(lags))
+	for i, l := range lags {
+		lag := l.Lag.Round(time.Millisecond).String()
+		if l.Lag < 0 {
+			lag = l.Err.Error()
+		}
+		rows[i] = []string{l.Name, lag}
+	}
+	fmt.Print(formatMySQLTable([]string{"Replica", "Lag"}, rows))
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWorstLag(t *testing.T) {
+	lags := []replicaLag{
+		{Name: "r1", Lag: 2 * time.Second},
+		{Name: "r2", Lag: 9 * time.Second},
+		{Name: "r3", Lag: time.Second},
+	}
+	if got := worstLag(lags); got.Name != "r2" {
+		t.Errorf("worstLag = %s, want r2", got.Name)
+	}
+	lags = append(lags, replicaLag{Name: "r4", Lag: -1, Err: errors.New("not replicating")})
+	if got := worstLag(lags); got.Name != "r4" {
+		t.Errorf("worstLag with a stopped replica = %s, want r4", got.Name)
+	}
+	if got := worstLag(nil); got.Lag != 0 {
+		t.Errorf("worstLag(nil) = %v, want no lag", got.Lag)
+	}
+}
+
+func TestLagReplicaAddress(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tests := []struct {
+		name string
+		host string
+		port int
+	}{
+		{"replica1", "replica1", 3306},
+		{"10.0.0.12:3307", "10.0.0.12", 3307},
+		{"db2:bad", "db2", 3306},
+	}
+	for _, tt := range tests {
+		host, port := lagReplicaAddress(tt.name)
+		if host != tt.host || port != tt.port {
+			t.Errorf("lagReplicaAddress(%q) = %s, %d, want %s, %d", tt.name, host, port, tt.host, tt.port)
+		}
+	}
+}
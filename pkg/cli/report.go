@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// explainRecord is what was shown about the last EXPLAIN, kept for \report
+type explainRecord struct {
+	Statement string // the EXPLAIN as entered
+	Output    string // the result as printed
+	Plan      string // JSON plan, empty when none could be had
+	Visual    string // the built-in visual explain of Plan
+	Findings  []ExplainFinding
+	// Statistics are the suggested ANALYZE TABLE runs
+	Statistics []StatisticsSuggestion
+	Workload   *DigestStats
+	Analysis   string // AI analysis, when it ran
+	Backend    string
+	Database   string
+	At         time.Time
+}
+
+// tableDefinition is the SHOW CREATE TABLE of a table the query reads
+type tableDefinition struct {
+	Name string
+	DDL  string
+}
+
+// saveReport implements \report <file.md>: it writes the last EXPLAIN with
+// its findings, AI analysis and the definitions of the tables it reads to a
+// Markdown file
+func (p *PromptExecutor) saveReport(path string) {
+	if path == "" {
+		fmt.Println("Usage: \\report <file.md>")
+		return
+	}
+	if p.lastExplain == nil {
+		fmt.Println("No EXPLAIN to report yet; run EXPLAIN <query> first")
+		return
+	}
+	path = expandHome(path)
+
+	var buf bytes.Buffer
+	writeMarkdownReport(&buf, p.lastExplain, p.reportTables(p.lastExplain))
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return
+	}
+	fmt.Printf("Report written to %s\n", path)
+}
+
+// expandHome replaces a leading ~/ in path with the home directory
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
+
+// reportTables returns the definitions of the tables the explained query
+// names. Tables that cannot be shown, such as CTEs, are left out.
+func (p *PromptExecutor) reportTables(rec *explainRecord) []tableDefinition {
+	query, _, _ := extractQueryFromExplain(rec.Statement)
+	var defs []tableDefinition
+	seen := make(map[string]bool)
+	for _, name := range statementTables(query, rec.Database) {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		db, table, _ := strings.Cut(name, ".")
+		var ignored, ddl string
+		if p.db.QueryRow("SHOW CREATE TABLE "+quoteIdentifier(db)+"."+quoteIdentifier(table)).Scan(&ignored, &ddl) == nil {
+			defs = append(defs, tableDefinition{Name: name, DDL: ddl})
+		}
+	}
+	return defs
+}
+
+// writeMarkdownReport renders rec and the table definitions as Markdown
+func writeMarkdownReport(w io.Writer, rec *explainRecord, tables []tableDefinition) {
+	query, _, _ := extractQueryFromExplain(rec.Statement)
+	fmt.Fprintf(w, "# EXPLAIN report\n\n")
+	fmt.Fprintf(w, "Generated by go-mycli on %s", rec.At.Format("2006-01-02 15:04:05 MST"))
+	if rec.Database != "" {
+		fmt.Fprintf(w, " in database `%s`", rec.Database)
+	}
+	fmt.Fprintf(w, ".\n\n## Query\n\n```sql\n%s\n```\n\n", query)
+
+	fmt.Fprintf(w, "## Findings\n\n")
+	if rec.Plan == "" {
+		fmt.Fprintf(w, "No JSON plan could be obtained, so the plan was not scored.\n\n")
+	} else {
+		score := severityScore(rec.Findings)
+		fmt.Fprintf(w, "Score: **%d/100 (%s)**\n\n", score, scoreVerdict(score))
+		if len(rec.Findings) == 0 {
+			fmt.Fprintf(w, "No problems found.\n\n")
+		}
+		for _, f := range rec.Findings {
+			if f.Table != "" {
+				fmt.Fprintf(w, "- **%s** `%s`: %s\n", f.Severity, f.Table, f.Message)
+			} else {
+				fmt.Fprintf(w, "- **%s** %s\n", f.Severity, f.Message)
+			}
+		}
+		if len(rec.Findings) > 0 {
+			fmt.Fprintln(w)
+		}
+		if len(rec.Statistics) > 0 {
+			fmt.Fprintf(w, "Suggested statistics refreshes:\n\n```sql\n")
+			for _, s := range rec.Statistics {
+				fmt.Fprintf(w, "-- %s: %s\n%s;\n", s.Table, s.Reason, s.Statement)
+			}
+			fmt.Fprintf(w, "```\n\n")
+		}
+	}
+
+	fmt.Fprintf(w, "## EXPLAIN output\n\n```\n%s\n```\n\n", strings.TrimRight(rec.Statement+"\n"+rec.Output, "\n"))
+	if rec.Visual != "" {
+		fmt.Fprintf(w, "## Plan tree\n\n```\n%s\n```\n\n", strings.TrimRight(rec.Visual, "\n"))
+	}
+	if rec.Workload != nil {
+		var history bytes.Buffer
+		printDigestStats(&history, rec.Workload)
+		fmt.Fprintf(w, "## Execution history\n\n```\n%s\n```\n\n", strings.TrimSpace(history.String()))
+	}
+	if rec.Analysis != "" {
+		fmt.Fprintf(w, "## AI analysis\n\n_Backend: %s_\n\n%s\n\n", strings.TrimSpace(rec.Backend), strings.TrimSpace(rec.Analysis))
+	}
+	if len(tables) > 0 {
+		fmt.Fprintf(w, "## Schema\n\n")
+		for _, t := range tables {
+			fmt.Fprintf(w, "```sql\n%s;\n```\n\n", t.DDL)
+		}
+	}
+}
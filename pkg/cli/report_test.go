@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteMarkdownReport(t *testing.T) {
+	rec := &explainRecord{
+		Statement: "EXPLAIN FORMAT=JSON SELECT * FROM film WHERE rating = 'PG'",
+		Output:    "+---------+\n| EXPLAIN |\n+---------+",
+		Plan:      `{"query_block": {}}`,
+		Visual:    "Query Execution Plan:\n└── film [ALL]\n",
+		Findings:  []ExplainFinding{{Severity: SeverityWarning, Table: "film", Message: "full table scan (1000 rows examined per scan)"}},
+		Statistics: []StatisticsSuggestion{{Table: "film", Reason: "no histogram on rating",
+			Statement: "ANALYZE TABLE film UPDATE HISTOGRAM ON rating WITH 100 BUCKETS"}},
+		Workload: &DigestStats{Executions: 3, FirstSeen: "2026-10-01 10:00:00", LastSeen: "2026-10-02 10:00:00"},
+		Analysis: "Add an index on rating.\n",
+		Backend:  "mcp_stdio sqlbot",
+		Database: "sakila",
+		At:       time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC),
+	}
+	tables := []tableDefinition{{Name: "sakila.film", DDL: "CREATE TABLE `film` (\n  `film_id` smallint unsigned NOT NULL\n)"}}
+
+	var buf bytes.Buffer
+	writeMarkdownReport(&buf, rec, tables)
+	out := buf.String()
+	for _, want := range []string{
+		"# EXPLAIN report\n\nGenerated by go-mycli on 2026-10-15 09:30:00 UTC in database `sakila`.",
+		"## Query\n\n```sql\nSELECT * FROM film WHERE rating = 'PG'\n```",
+		"Score: **30/100 (warn)**\n\n- **warning** `film`: full table scan (1000 rows examined per scan)\n",
+		"```sql\n-- film: no histogram on rating\nANALYZE TABLE film UPDATE HISTOGRAM ON rating WITH 100 BUCKETS;\n```",
+		"## EXPLAIN output\n\n```\nEXPLAIN FORMAT=JSON SELECT * FROM film WHERE rating = 'PG'\n+---------+",
+		"## Plan tree\n\n```\nQuery Execution Plan:\n└── film [ALL]\n```",
+		"## Execution history\n\n```\nExecution history (performance_schema, 2026-10-01 10:00:00 to 2026-10-02 10:00:00):\n  3 executions",
+		"## AI analysis\n\n_Backend: mcp_stdio sqlbot_\n\nAdd an index on rating.\n",
+		"## Schema\n\n```sql\nCREATE TABLE `film` (\n  `film_id` smallint unsigned NOT NULL\n);\n```",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks %q:\n%s", want, out)
+		}
+	}
+
+	// A plan that could not be had as JSON is not scored, and sections with
+	// nothing to show are left out
+	buf.Reset()
+	writeMarkdownReport(&buf, &explainRecord{Statement: "EXPLAIN SELECT 1", Output: "..."}, nil)
+	out = buf.String()
+	if !strings.Contains(out, "not scored") || strings.Contains(out, "## AI analysis") || strings.Contains(out, "## Schema") {
+		t.Errorf("unexpected report:\n%s", out)
+	}
+}
+
+func TestSaveReportNeedsAnExplain(t *testing.T) {
+	p := &PromptExecutor{}
+	path := t.TempDir() + "/report.md"
+	p.saveReport(path)
+	if _, err := os.Stat(path); err == nil {
+		t.Errorf("no report should be written before an EXPLAIN")
+	}
+}
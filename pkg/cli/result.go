@@ -0,0 +1,345 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result is the outcome of a statement, kept apart from how it is printed so
+// the renderers, \view and \expand and programs embedding the client all
+// work from the same values
+type Result struct {
+	Statement string
+	Columns   []string   // nil for a statement that returns no rows
+	Types     []string   // database type of each column, e.g. "VARCHAR" or "UNSIGNED BIGINT"
+	Rows      [][]string // values as the client displays them, NULL as "NULL"
+	// Values are the values as the server sent them: nil for NULL, int64 or
+	// uint64 for integer columns, float64 for FLOAT and DOUBLE and a string
+	// otherwise, so that DECIMAL keeps its precision
+	Values       [][]any
+	RowsAffected int64
+	LastInsertID int64
+	Duration     time.Duration
+	// Warnings are the server's warnings about the statement, read only when
+	// warnings are shown (\W, or Options.Warnings)
+	Warnings []Warning
+	// Explain is the analysis of an EXPLAIN's plan, nil for other statements
+	Explain *ExplainReport
+	// Cost is the optimizer's cost and the handler reads of a SELECT, read
+	// only when query cost is shown (\cost)
+	Cost *QueryCost
+}
+
+// Warning is one row of SHOW WARNINGS
+type Warning struct {
+	Level   string // Note, Warning or Error
+	Code    int
+	Message string
+}
+
+// runStatement runs one statement and collects its result without printing
+// anything. The statement runs on a connection of its own from the pool so
+// that SHOW WARNINGS, when warnings are shown, sees its warnings. Canceling
+// ctx stops the statement on the server with KILL QUERY on that connection.
+func (p *PromptExecutor) runStatement(ctx context.Context, stmt string) (*Result, error) {
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	id, err := connectionID(ctx, conn)
+	if err != nil {
+		return nil, canceled(ctx, err)
+	}
+	p.guard.watchConnection(id)
+
+	done := p.killOnCancel(ctx, id)
+	defer done()
+
+	var counters *costCounters
+	if p.showCost && isSelectStatement(stmt) {
+		// A failure here leaves the result without its cost
+		counters, _ = startCostCounters(ctx, conn)
+	}
+
+	res := &Result{Statement: stmt}
+	start := time.Now()
+	if returnsRows(stmt) {
+		rows, err := conn.QueryContext(ctx, stmt)
+		if err != nil {
+			return nil, canceled(ctx, err)
+		}
+		err = readResult(rows, res)
+		rows.Close()
+		if err != nil {
+			return nil, canceled(ctx, err)
+		}
+	} else {
+		r, err := conn.ExecContext(ctx, stmt)
+		if err != nil {
+			return nil, canceled(ctx, err)
+		}
+		res.RowsAffected, _ = r.RowsAffected()
+		res.LastInsertID, _ = r.LastInsertId()
+	}
+	res.Duration = time.Since(start)
+
+	if p.showWarnings {
+		// A failure here leaves the result without its warnings
+		res.Warnings, _ = readWarnings(ctx, conn)
+	}
+	if counters != nil {
+		res.Cost, _ = counters.finish(ctx, conn)
+	}
+	return res, nil
+}
+
+// readResult reads a result set into res: the columns with their types, the
+// values for display and the typed values
+func readResult(rows *sql.Rows, res *Result) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	res.Columns = columns
+	res.Types = make([]string, len(columns))
+	if colTypes, err := rows.ColumnTypes(); err == nil {
+		for i, ct := range colTypes {
+			res.Types[i] = ct.DatabaseTypeName()
+		}
+	}
+
+	values := make([]interface{}, len(columns))
+	scanArgs := make([]interface{}, len(values))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return err
+		}
+		row := make([]string, len(columns))
+		typed := make([]any, len(columns))
+		for i, val := range values {
+			switch v := val.(type) {
+			case nil:
+				row[i] = "NULL"
+			case []byte:
+				row[i] = string(v)
+			default:
+				row[i] = fmt.Sprintf("%v", v)
+			}
+			if val != nil {
+				typed[i] = typedValue(res.Types[i], row[i])
+			}
+		}
+		res.Rows = append(res.Rows, row)
+		res.Values = append(res.Values, typed)
+	}
+	return rows.Err()
+}
+
+// typedValue converts the text of a non-NULL value to the Go type of its
+// column. Values that do not parse, such as a BIGINT UNSIGNED past int64
+// read as signed, are kept as text.
+func typedValue(typeName, text string) any {
+	switch strings.TrimPrefix(typeName, "UNSIGNED ") {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT", "YEAR":
+		if strings.HasPrefix(typeName, "UNSIGNED ") {
+			if n, err := strconv.ParseUint(text, 10, 64); err == nil {
+				return n
+			}
+		} else if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n
+		}
+	case "FLOAT", "DOUBLE":
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f
+		}
+	}
+	return text
+}
+
+// readWarnings returns the warnings of the last statement run on conn
+func readWarnings(ctx context.Context, conn *sql.Conn) ([]Warning, error) {
+	rows, err := conn.QueryContext(ctx, "SHOW WARNINGS")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var warnings []Warning
+	for rows.Next() {
+		var w Warning
+		if err := rows.Scan(&w.Level, &w.Code, &w.Message); err != nil {
+			return nil, err
+		}
+		warnings = append(warnings, w)
+	}
+	return warnings, rows.Err()
+}
+
+// printWarnings lists warnings as the mysql client does after a statement
+func printWarnings(w io.Writer, warnings []Warning) {
+	for _, warning := range warnings {
+		fmt.Fprintf(w, "%s (Code %d): %s\n", warning.Level, warning.Code, warning.Message)
+	}
+}
+
+// Renderer writes the rows of a Result in one output format
+type Renderer interface {
+	Render(w io.Writer, res *Result) error
+}
+
+// tableRenderer draws the familiar mysql client box; color, if set, picks
+// the color of each value
+type tableRenderer struct {
+	color func(col int, cell string) string
+}
+
+func (r tableRenderer) Render(w io.Writer, res *Result) error {
+	_, err := io.WriteString(w, formatMySQLTableColored(res.Columns, res.Rows, r.color))
+	return err
+}
+
+// verticalRenderer prints one column per line, as \G does
+type verticalRenderer struct {
+	color func(col int, cell string) string
+}
+
+func (r verticalRenderer) Render(w io.Writer, res *Result) error {
+	_, err := io.WriteString(w, formatVerticalTableColored(res.Columns, res.Rows, r.color))
+	return err
+}
+
+// csvRenderer writes a header line and one line per row of the values as
+// the server sent them. NULL is written as an empty field, as export does.
+type csvRenderer struct{}
+
+func (csvRenderer) Render(w io.Writer, res *Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(res.Columns); err != nil {
+		return err
+	}
+	record := make([]string, len(res.Columns))
+	for _, row := range res.Values {
+		for i, v := range row {
+			record[i] = ""
+			if v != nil {
+				record[i] = fmt.Sprint(v)
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// jsonRenderer writes the rows as a JSON array of objects with the columns
+// in result order, numbers as JSON numbers and NULL as null
+type jsonRenderer struct{}
+
+func (jsonRenderer) Render(w io.Writer, res *Result) error {
+	var b strings.Builder
+	b.WriteString("[")
+	for r, row := range res.Values {
+		if r > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  {")
+		for i, v := range row {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			name, _ := json.Marshal(res.Columns[i])
+			value, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			b.Write(name)
+			b.WriteString(": ")
+			b.Write(value)
+		}
+		b.WriteString("}")
+	}
+	if len(res.Values) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("]\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// renderers are the output formats by name
+var renderers = map[string]Renderer{
+	"table":    tableRenderer{},
+	"vertical": verticalRenderer{},
+	"csv":      csvRenderer{},
+	"json":     jsonRenderer{},
+}
+
+// NewRenderer returns the renderer of an output format: table, vertical, csv
+// or json
+func NewRenderer(format string) (Renderer, error) {
+	r, ok := renderers[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q (use %s)", format, strings.Join(rendererNames(), ", "))
+	}
+	return r, nil
+}
+
+// rendererNames returns the output formats in order
+func rendererNames() []string {
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// setOutput implements \output [table|vertical|csv|json]: the format query
+// results are printed in. \G still prints one result vertically.
+func (p *PromptExecutor) setOutput(args string) {
+	format := strings.ToLower(strings.TrimSpace(args))
+	if format == "" {
+		fmt.Printf("Output format: %s\n", p.outputFormatName())
+		return
+	}
+	if _, err := NewRenderer(format); err != nil {
+		fmt.Println(err)
+		return
+	}
+	p.outputFormat = format
+	fmt.Printf("Output format: %s\n", format)
+	saveSetting("output_format", format)
+}
+
+// outputFormatName is the format set with \output, table by default
+func (p *PromptExecutor) outputFormatName() string {
+	if p.outputFormat == "" {
+		return "table"
+	}
+	return p.outputFormat
+}
+
+// setWarnings implements \W and \w, which show and stop showing the
+// server's warnings after every statement
+func (p *PromptExecutor) setWarnings(on bool) {
+	p.showWarnings = on
+	if on {
+		fmt.Println("Show warnings enabled.")
+	} else {
+		fmt.Println("Show warnings disabled.")
+	}
+	saveSetting("show_warnings", strconv.FormatBool(on))
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ANSI sequences used for result values. Basic colors are used so they read
+// on both dark and light backgrounds.
+const (
+	ansiReset    = "\033[0m"
+	ansiDim      = "\033[2m"
+	ansiNumber   = "\033[35m"
+	ansiPositive = "\033[32m"
+	ansiNegative = "\033[33m"
+	ansiError    = "\033[1;31m"
+)
+
+// resultColorizer returns the cell color function for a result set, or nil
+// when results should stay plain (colors off, or not an interactive session)
+func (p *PromptExecutor) resultColorizer(types []string) func(col int, cell string) string {
+	if !p.colorResults || !colorEnabled() || p.input == nil {
+		return nil
+	}
+	numeric := make([]bool, len(types))
+	for i, typeName := range types {
+		numeric[i] = isNumericType(typeName)
+	}
+	return func(col int, cell string) string {
+		return valueColor(cell, col < len(numeric) && numeric[col])
+	}
+}
+
+// valueColor picks the color for one result value. NULLs are dimmed, numbers
+// are colored, and the Yes/No, ON/OFF and error values common in SHOW and
+// status output stand out.
+func valueColor(cell string, numeric bool) string {
+	if cell == "NULL" {
+		return ansiDim
+	}
+	if numeric {
+		return ansiNumber
+	}
+
+	switch strings.ToUpper(cell) {
+	case "YES", "ON", "TRUE", "ENABLED", "ACTIVE", "ONLINE":
+		return ansiPositive
+	case "NO", "OFF", "FALSE", "DISABLED", "INACTIVE", "OFFLINE":
+		return ansiNegative
+	case "ERROR", "FAILED", "FAILURE", "CRITICAL", "CONNECTING", "DEADLOCK", "KILLED", "CORRUPT":
+		return ansiError
+	}
+
+	// Status tables keep numbers in VARCHAR columns
+	if _, err := strconv.ParseFloat(strings.ReplaceAll(cell, ",", ""), 64); err == nil {
+		return ansiNumber
+	}
+	lower := strings.ToLower(cell)
+	if strings.HasPrefix(lower, "error") || strings.Contains(lower, " error") || strings.Contains(lower, "failed") {
+		return ansiError
+	}
+	return ""
+}
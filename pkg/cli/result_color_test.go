@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValueColor(t *testing.T) {
+	tests := []struct {
+		cell    string
+		numeric bool
+		want    string
+	}{
+		{"NULL", true, ansiDim},
+		{"42", true, ansiNumber},
+		{"1,234", false, ansiNumber},
+		{"Yes", false, ansiPositive},
+		{"ON", false, ansiPositive},
+		{"No", false, ansiNegative},
+		{"Connecting", false, ansiError},
+		{"Error 'Duplicate entry' on query", false, ansiError},
+		{"Slave_IO_Running", false, ""},
+		{"sakila", false, ""},
+	}
+	for _, tt := range tests {
+		if got := valueColor(tt.cell, tt.numeric); got != tt.want {
+			t.Errorf("valueColor(%q, %v) = %q, want %q", tt.cell, tt.numeric, got, tt.want)
+		}
+	}
+}
+
+func TestFormatMySQLTableColoredKeepsAlignment(t *testing.T) {
+	columns := []string{"Variable_name", "Value"}
+	rows := [][]string{{"Threads_connected", "12"}, {"have_ssl", "YES"}}
+
+	plain := formatMySQLTable(columns, rows)
+	colored := formatMySQLTableColored(columns, rows, func(col int, cell string) string {
+		return valueColor(cell, false)
+	})
+
+	stripped := strings.NewReplacer(ansiNumber, "", ansiPositive, "", ansiReset, "").Replace(colored)
+	if stripped != plain {
+		t.Errorf("colored table differs from plain once colors are removed:\n%s\n%s", stripped, plain)
+	}
+	if !strings.Contains(colored, ansiPositive+"YES  "+ansiReset) {
+		t.Errorf("padding should be inside the colored span:\n%q", colored)
+	}
+}
+
+func TestResultColorizerOffOutsideInteractiveSessions(t *testing.T) {
+	p := &PromptExecutor{colorResults: true}
+	if p.resultColorizer(nil) != nil {
+		t.Errorf("results should not be colored without an interactive terminal")
+	}
+}
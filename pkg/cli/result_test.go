@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTypedValue(t *testing.T) {
+	tests := []struct {
+		typeName string
+		text     string
+		want     any
+	}{
+		{"INT", "-42", int64(-42)},
+		{"UNSIGNED BIGINT", "18446744073709551615", uint64(18446744073709551615)},
+		{"DOUBLE", "2.5", 2.5},
+		{"DECIMAL", "12345678901234567890.12", "12345678901234567890.12"},
+		{"VARCHAR", "42", "42"},
+		{"BIGINT", "not a number", "not a number"},
+	}
+	for _, test := range tests {
+		if got := typedValue(test.typeName, test.text); got != test.want {
+			t.Errorf("%s %q: got %#v, expected %#v", test.typeName, test.text, got, test.want)
+		}
+	}
+}
+
+// sampleResult is a result set as readResult fills it
+var sampleResult = &Result{
+	Columns: []string{"id", "name", "price"},
+	Types:   []string{"INT", "VARCHAR", "DECIMAL"},
+	Rows:    [][]string{{"1", "Widget, large", "9.99"}, {"2", "NULL", "NULL"}},
+	Values:  [][]any{{int64(1), "Widget, large", "9.99"}, {int64(2), nil, nil}},
+}
+
+func render(t *testing.T, format string) string {
+	t.Helper()
+	r, err := NewRenderer(format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := r.Render(&b, sampleResult); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestRenderers(t *testing.T) {
+	if got := render(t, "table"); got != formatMySQLTable(sampleResult.Columns, sampleResult.Rows) {
+		t.Errorf("table:\n%s", got)
+	}
+	if got := render(t, "vertical"); !strings.Contains(got, "name: Widget, large") {
+		t.Errorf("vertical:\n%s", got)
+	}
+	if got, want := render(t, "CSV"), "id,name,price\n1,\"Widget, large\",9.99\n2,,\n"; got != want {
+		t.Errorf("csv: got %q, expected %q", got, want)
+	}
+	want := "[\n" +
+		`  {"id": 1, "name": "Widget, large", "price": "9.99"},` + "\n" +
+		`  {"id": 2, "name": null, "price": null}` + "\n]\n"
+	if got := render(t, "json"); got != want {
+		t.Errorf("json: got\n%s\nexpected\n%s", got, want)
+	}
+
+	if _, err := NewRenderer("xml"); err == nil || !strings.Contains(err.Error(), "csv, json, table, vertical") {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestRenderResultTruncatesTables(t *testing.T) {
+	p := &PromptExecutor{maxFieldWidth: 5}
+	res := &Result{Statement: "SELECT name FROM t", Columns: []string{"name"}, Rows: [][]string{{"Widget, large"}}, Values: [][]any{{"Widget, large"}}}
+	out, truncated := p.renderResult(res, false)
+	if !truncated || strings.Contains(out, "Widget, large") || res.Rows[0][0] != "Widget, large" {
+		t.Errorf("truncated %v:\n%s", truncated, out)
+	}
+
+	p.outputFormat = "csv"
+	if out, truncated := p.renderResult(res, false); truncated || out != "name\n\"Widget, large\"\n" {
+		t.Errorf("csv is not truncated, got %q", out)
+	}
+}
@@ -0,0 +1,297 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxSavedHistory is how many history entries \save-session keeps
+const maxSavedHistory = 500
+
+// savedSession is what \save-session writes and --resume reads back
+type savedSession struct {
+	Name      string            `json:"name"`
+	Saved     time.Time         `json:"saved"`
+	User      string            `json:"user"`
+	Host      string            `json:"host"`
+	Port      int               `json:"port"`
+	Database  string            `json:"database"`
+	Variables map[string]string `json:"variables,omitempty"`
+	Toggles   sessionToggles    `json:"toggles"`
+	History   []string          `json:"history,omitempty"`
+}
+
+// sessionToggles are the settings changed with backslash commands
+type sessionToggles struct {
+	Suggestions   bool   `json:"suggestions"`
+	AIAnalysis    bool   `json:"ai_analysis"`
+	JSONExport    bool   `json:"json_export"`
+	VisualExplain bool   `json:"visual_explain"`
+	Completion    string `json:"completion"`
+	Profile       bool   `json:"profile"`
+}
+
+// resumeName is the session named by --resume; resumed is that session once
+// Start has loaded it
+var (
+	resumeName string
+	resumed    *savedSession
+)
+
+// SetResumeSession names a session saved with \save-session to restore on
+// startup (--resume)
+func SetResumeSession(name string) {
+	resumeName = name
+}
+
+var sessionNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// sessionDir is where saved sessions are kept, one JSON file each
+func sessionDir() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "sessions")
+}
+
+func sessionPath(name string) (string, error) {
+	if !sessionNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid session name %q: use letters, digits, '.', '-' and '_'", name)
+	}
+	dir := sessionDir()
+	if dir == "" {
+		return "", fmt.Errorf("cannot find the home directory")
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// loadSession reads a saved session
+func loadSession(name string) (*savedSession, error) {
+	path, err := sessionPath(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no saved session %q (save one with \\save-session %s)", name, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s savedSession
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("session %q: %w", name, err)
+	}
+	return &s, nil
+}
+
+// loadResumedSession loads the --resume session, if any, for Start
+func loadResumedSession() error {
+	if resumeName == "" {
+		return nil
+	}
+	s, err := loadSession(resumeName)
+	if err != nil {
+		return err
+	}
+	resumed = s
+	return nil
+}
+
+// mergeVariables adds the saved session's variables to the [session]
+// settings, the saved ones winning
+func (s *savedSession) mergeVariables(vars map[string]string) map[string]string {
+	merged := make(map[string]string, len(vars)+len(s.Variables))
+	for name, value := range vars {
+		merged[name] = value
+	}
+	for name, value := range s.Variables {
+		merged[name] = value
+	}
+	return merged
+}
+
+// saveSession handles \save-session <name>; without a name it lists the
+// saved sessions
+func (p *PromptExecutor) saveSession(name string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		names := savedSessionNames()
+		if len(names) == 0 {
+			fmt.Println("Usage: \\save-session <name>, then start with --resume <name>")
+			return
+		}
+		fmt.Printf("Saved sessions: %s\n", strings.Join(names, ", "))
+		return
+	}
+	path, err := sessionPath(name)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return
+	}
+
+	s := savedSession{
+		Name:      name,
+		Saved:     time.Now(),
+		User:      p.user,
+		Host:      p.host,
+		Port:      p.port,
+		Database:  p.database,
+		Variables: p.sessionOverrides,
+		Toggles: sessionToggles{
+			Suggestions:   p.enableSuggestions,
+			AIAnalysis:    p.enableAIAnalysis,
+			JSONExport:    p.enableJSONExport,
+			VisualExplain: p.enableVisualExplain,
+			Completion:    p.completionMode,
+			Profile:       p.profile.source != "",
+		},
+	}
+	if p.input != nil {
+		s.History = p.input.History()
+		if len(s.History) > maxSavedHistory {
+			s.History = s.History[len(s.History)-maxSavedHistory:]
+		}
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0o700)
+	}
+	if err == nil {
+		err = os.WriteFile(path, data, 0o600)
+	}
+	if err != nil {
+		fmt.Printf("Error saving session: %v\n", err)
+		return
+	}
+	fmt.Printf("Session saved to %s; resume with: go-mycli --resume %s\n", path, name)
+}
+
+// restoreSession applies a saved session's toggles and history. The database
+// and variables were applied when connecting.
+func (p *PromptExecutor) restoreSession(s *savedSession) {
+	if s.Host != p.host || s.User != p.user {
+		fmt.Printf("Note: session %q was saved for %s@%s\n", s.Name, s.User, s.Host)
+	}
+	p.enableSuggestions = s.Toggles.Suggestions
+	p.enableAIAnalysis = s.Toggles.AIAnalysis
+	p.enableJSONExport = s.Toggles.JSONExport
+	p.enableVisualExplain = s.Toggles.VisualExplain
+	if s.Toggles.Completion != "" {
+		p.completionMode = s.Toggles.Completion
+	}
+	if s.Toggles.Profile {
+		p.setProfile("on")
+	}
+	if len(s.Variables) > 0 {
+		p.sessionOverrides = s.mergeVariables(nil)
+	}
+	if p.input != nil {
+		for _, entry := range s.History {
+			p.input.AddHistory(entry)
+		}
+	}
+	fmt.Printf("Resumed session %q from %s: database %s, %d variable%s, %d history entr%s\n",
+		s.Name, s.Saved.Format("2006-01-02 15:04"), orNone(p.database),
+		len(s.Variables), plural(len(s.Variables)), len(s.History), pluralY(len(s.History)))
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+func pluralY(n int) string {
+	if n == 1 {
+		return "y"
+	}
+	return "ies"
+}
+
+// savedSessionNames lists the sessions in sessionDir
+func savedSessionNames() []string {
+	matches, _ := filepath.Glob(filepath.Join(sessionDir(), "*.json"))
+	names := make([]string, 0, len(matches))
+	for _, m := range matches {
+		names = append(names, strings.TrimSuffix(filepath.Base(m), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+var (
+	sessionSetPrefix = regexp.MustCompile(`(?i)^SET\s+(?:SESSION\s+|LOCAL\s+)?`)
+	sessionSetTarget = regexp.MustCompile(`(?i)^(?:@@(?:SESSION\.|LOCAL\.)?)?([A-Za-z_][A-Za-z0-9_]*)\s*(?:=|:=)\s*(.+)$`)
+	sessionSetSkip   = regexp.MustCompile(`(?i)^SET\s+(?:GLOBAL|PERSIST|PERSIST_ONLY|NAMES|CHARACTER|CHARSET|TRANSACTION|PASSWORD|ROLE|DEFAULT|RESOURCE)\b|@@(?:GLOBAL|PERSIST|PERSIST_ONLY)\.`)
+	sessionLiteral   = regexp.MustCompile(`^(?:'(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*"|[-+]?[0-9.]+|[A-Za-z_][A-Za-z0-9_]*)$`)
+)
+
+// parseSessionSet returns the session variables a SET statement assigns
+// literal values to. User variables, expressions and global settings are
+// left out, since they cannot be replayed as connection parameters.
+func parseSessionSet(stmt string) map[string]string {
+	stmt = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(stmt), ";"))
+	if sessionSetSkip.MatchString(stmt) || !sessionSetPrefix.MatchString(stmt) {
+		return nil
+	}
+	vars := make(map[string]string)
+	for _, assignment := range splitTopLevel(sessionSetPrefix.ReplaceAllString(stmt, "")) {
+		m := sessionSetTarget.FindStringSubmatch(strings.TrimSpace(assignment))
+		if m == nil {
+			continue
+		}
+		if value := strings.TrimSpace(m[2]); sessionLiteral.MatchString(value) {
+			vars[strings.ToLower(m[1])] = value
+		}
+	}
+	return vars
+}
+
+// splitTopLevel splits at commas outside quotes and parentheses
+func splitTopLevel(s string) []string {
+	var parts []string
+	var st sqlState
+	depth, start := 0, 0
+	for i := 0; i < len(s); {
+		if st.quote == 0 && !st.blockComment {
+			switch s[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			case ',':
+				if depth == 0 {
+					parts = append(parts, s[start:i])
+					start = i + 1
+				}
+			}
+		}
+		i, _ = st.advance(s, i)
+	}
+	return append(parts, s[start:])
+}
+
+// trackSessionSet remembers the session variables a successful SET changed,
+// for \save-session
+func (p *PromptExecutor) trackSessionSet(stmt string) {
+	vars := parseSessionSet(stmt)
+	if len(vars) == 0 {
+		return
+	}
+	if p.sessionOverrides == nil {
+		p.sessionOverrides = make(map[string]string)
+	}
+	for name, value := range vars {
+		p.sessionOverrides[name] = value
+	}
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSessionSet(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want map[string]string
+	}{
+		{"SET sql_mode = 'STRICT_TRANS_TABLES'", map[string]string{"sql_mode": "'STRICT_TRANS_TABLES'"}},
+		{"set session max_execution_time=30000;", map[string]string{"max_execution_time": "30000"}},
+		{"SET @@session.autocommit = OFF, @@Time_Zone = '+00:00'", map[string]string{"autocommit": "OFF", "time_zone": "'+00:00'"}},
+		{"SET sql_mode = CONCAT(@@sql_mode, ',ANSI'), wait_timeout = 60", map[string]string{"wait_timeout": "60"}},
+		{"SET GLOBAL max_connections = 500", nil},
+		{"SET @@global.read_only = ON", nil},
+		{"SET NAMES utf8mb4", nil},
+		{"SET @x = 1", map[string]string{}},
+		{"SELECT 1", nil},
+	}
+	for _, tt := range tests {
+		if got := parseSessionSet(tt.stmt); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSessionSet(%q) = %v, want %v", tt.stmt, got, tt.want)
+		}
+	}
+}
+
+func TestSaveAndLoadSession(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	p := &PromptExecutor{
+		user:              "app",
+		host:              "db1",
+		port:              3306,
+		database:          "shop",
+		enableSuggestions: true,
+		completionMode:    completionMetadata,
+		input:             &inputParser{},
+	}
+	p.trackSessionSet("SET sql_mode = 'ANSI'")
+	p.input.AddHistory("SELECT * FROM orders;")
+	p.saveSession("work")
+
+	s, err := loadSession("work")
+	if err != nil {
+		t.Fatalf("loadSession() error = %v", err)
+	}
+	if s.Database != "shop" || s.Host != "db1" || !s.Toggles.Suggestions || s.Toggles.Completion != completionMetadata {
+		t.Errorf("loadSession() = %+v", s)
+	}
+	if !reflect.DeepEqual(s.Variables, map[string]string{"sql_mode": "'ANSI'"}) {
+		t.Errorf("Variables = %v", s.Variables)
+	}
+	if !reflect.DeepEqual(s.History, []string{"SELECT * FROM orders;"}) {
+		t.Errorf("History = %v", s.History)
+	}
+	if got := s.mergeVariables(map[string]string{"sql_mode": "TRADITIONAL", "time_zone": "UTC"}); got["sql_mode"] != "'ANSI'" || got["time_zone"] != "UTC" {
+		t.Errorf("mergeVariables() = %v", got)
+	}
+
+	if _, err := loadSession("missing"); err == nil {
+		t.Error("loadSession() of a missing session succeeded")
+	}
+	if _, err := sessionPath("../escape"); err == nil {
+		t.Error("sessionPath() accepted a path")
+	}
+}
@@ -0,0 +1,291 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// runbookRepeat starts a runbook whose statements run in rounds until a
+// round changes no rows, as archiving in batches does
+const runbookRepeat = "repeat:"
+
+// runbookParam matches a ${name} or ${name=default} parameter of a runbook
+var runbookParam = regexp.MustCompile(`\$\{(\w+)(?:=([^}]*))?\}`)
+
+// builtinRunbooks are the runbooks every session has; [runbooks] in the
+// config adds others and replaces these by name
+var builtinRunbooks = map[string]string{
+	"add-index-online": "ALTER TABLE ${table} ADD INDEX ${index} (${columns}), ALGORITHM=INPLACE, LOCK=NONE",
+	"archive-rows": runbookRepeat + " START TRANSACTION; " +
+		"INSERT INTO ${archive_table} SELECT * FROM ${table} WHERE ${condition} ORDER BY ${key} LIMIT ${batch=1000}; " +
+		"DELETE FROM ${table} WHERE ${condition} ORDER BY ${key} LIMIT ${batch=1000}; COMMIT",
+	"rotate-partition": "ALTER TABLE ${table} DROP PARTITION ${oldest}; " +
+		"ALTER TABLE ${table} REORGANIZE PARTITION ${future=pmax} INTO " +
+		"(PARTITION ${new} VALUES LESS THAN (${bound}), PARTITION ${future=pmax} VALUES LESS THAN MAXVALUE)",
+	"create-user": "CREATE USER '${user}'@'${host=%}' IDENTIFIED BY '${password}'; " +
+		"GRANT ${privileges=SELECT} ON ${database}.* TO '${user}'@'${host=%}'",
+}
+
+// runbookParameter is a parameter of a runbook with its default, if any
+type runbookParameter struct {
+	Name       string
+	Default    string
+	HasDefault bool
+}
+
+// runbook is a parsed statement template
+type runbook struct {
+	Name   string
+	Text   string
+	Repeat bool
+	Params []runbookParameter // in the order they first appear
+}
+
+// parseRunbook reads the parameters of a runbook's text. A default given at
+// any use of a parameter applies to all of them.
+func parseRunbook(name, text string) runbook {
+	rb := runbook{Name: name, Text: strings.TrimSpace(text)}
+	if len(rb.Text) >= len(runbookRepeat) && strings.EqualFold(rb.Text[:len(runbookRepeat)], runbookRepeat) {
+		rb.Repeat = true
+		rb.Text = strings.TrimSpace(rb.Text[len(runbookRepeat):])
+	}
+	index := make(map[string]int)
+	for _, m := range runbookParam.FindAllStringSubmatchIndex(rb.Text, -1) {
+		param := rb.Text[m[2]:m[3]]
+		i, seen := index[param]
+		if !seen {
+			i = len(rb.Params)
+			index[param] = i
+			rb.Params = append(rb.Params, runbookParameter{Name: param})
+		}
+		if m[4] >= 0 && !rb.Params[i].HasDefault {
+			rb.Params[i].Default, rb.Params[i].HasDefault = rb.Text[m[4]:m[5]], true
+		}
+	}
+	return rb
+}
+
+// render fills in the parameters and splits the runbook into statements
+func (rb runbook) render(values map[string]string) ([]string, error) {
+	text := runbookParam.ReplaceAllStringFunc(rb.Text, func(ref string) string {
+		return values[runbookParam.FindStringSubmatch(ref)[1]]
+	})
+	stmts, ok := splitScript(text)
+	if !ok {
+		return nil, fmt.Errorf("runbook %s mixes SQL and backslash commands", rb.Name)
+	}
+	sqls := make([]string, len(stmts))
+	for i, stmt := range stmts {
+		sqls[i] = stmt.SQL
+	}
+	return sqls, nil
+}
+
+// runbookArgs reads the param=value arguments of \runbook <name>. Values
+// may be quoted with ' or " to hold spaces.
+func runbookArgs(args string) (map[string]string, error) {
+	values := make(map[string]string)
+	for rest := strings.TrimSpace(args); rest != ""; rest = strings.TrimSpace(rest) {
+		name, after, ok := strings.Cut(rest, "=")
+		if !ok || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("expected param=value, got %q", strings.Fields(rest)[0])
+		}
+		var value string
+		if after != "" && (after[0] == '\'' || after[0] == '"') {
+			end := strings.IndexByte(after[1:], after[0])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quote in %s", name)
+			}
+			value, rest = after[1:end+1], after[end+2:]
+		} else {
+			value, rest, _ = strings.Cut(after, " ")
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// runbookText returns the text of a runbook, from the config or built in
+func (p *PromptExecutor) runbookText(name string) (string, bool) {
+	if text, ok := p.runbooks[name]; ok {
+		return text, true
+	}
+	text, ok := builtinRunbooks[name]
+	return text, ok
+}
+
+// listRunbooks prints the runbooks with their parameters
+func (p *PromptExecutor) listRunbooks() {
+	names := make([]string, 0, len(builtinRunbooks)+len(p.runbooks))
+	for name := range builtinRunbooks {
+		if _, ok := p.runbooks[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	for name := range p.runbooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	rows := make([][]string, 0, len(names))
+	for _, name := range names {
+		text, _ := p.runbookText(name)
+		rb := parseRunbook(name, text)
+		params := make([]string, len(rb.Params))
+		for i, param := range rb.Params {
+			params[i] = param.Name
+			if param.HasDefault {
+				params[i] += "=" + param.Default
+			}
+		}
+		source := "built-in"
+		if _, ok := p.runbooks[name]; ok {
+			source = "config"
+		}
+		rows = append(rows, []string{name, strings.Join(params, " "), source, truncateQuery(rb.Text, 60)})
+	}
+	fmt.Print(formatMySQLTable([]string{"Runbook", "Parameters", "From", "Statements"}, rows))
+}
+
+// runRunbook implements \runbook [<name> [param=value ...]]: it asks for the
+// parameters not given, shows the statements and runs them on one connection
+// once confirmed
+func (p *PromptExecutor) runRunbook(args string) {
+	name, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	if name == "" {
+		p.listRunbooks()
+		return
+	}
+	text, ok := p.runbookText(name)
+	if !ok {
+		fmt.Printf("No runbook %s (see \\runbook)\n", name)
+		return
+	}
+	values, err := runbookArgs(rest)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	rb := parseRunbook(name, text)
+	for _, param := range rb.Params {
+		if _, ok := values[param.Name]; ok {
+			continue
+		}
+		if !p.canPrompt() {
+			if !param.HasDefault {
+				fmt.Printf("Runbook %s needs %s=...\n", name, param.Name)
+				return
+			}
+			values[param.Name] = param.Default
+			continue
+		}
+		question := param.Name + ": "
+		if param.HasDefault {
+			question = fmt.Sprintf("%s [%s]: ", param.Name, param.Default)
+		}
+		value := askValue(question)
+		if value == "" {
+			value = param.Default
+		}
+		if value == "" {
+			fmt.Printf("No value for %s; runbook not run\n", param.Name)
+			return
+		}
+		values[param.Name] = value
+	}
+	stmts, err := rb.render(values)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for i, stmt := range stmts {
+		fmt.Printf("%d. %s;\n", i+1, stmt)
+	}
+	question := fmt.Sprintf("Run %d statement%s? [y/N] ", len(stmts), plural(len(stmts)))
+	if rb.Repeat {
+		question = fmt.Sprintf("Run %d statement%s, repeated until a round changes no rows? [y/N] ", len(stmts), plural(len(stmts)))
+	}
+	if !p.canPrompt() {
+		fmt.Println("Runbook not run: confirming it needs an interactive session")
+		return
+	}
+	if !askYesNo(question) {
+		fmt.Println("Runbook not run")
+		return
+	}
+	p.executeRunbook(rb, stmts)
+}
+
+// executeRunbook runs the statements on one connection so that a
+// transaction they open spans them, rolling back what is open when one fails
+func (p *PromptExecutor) executeRunbook(rb runbook, stmts []string) {
+	ctx, stop := p.statementContext()
+	defer stop()
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return
+	}
+	defer conn.Close()
+	if p.database != "" {
+		if _, err := conn.ExecContext(ctx, "USE "+quoteIdentifier(p.database)); err != nil {
+			fmt.Printf(tr("Error: %v\n"), err)
+			return
+		}
+	}
+
+	start := time.Now()
+	for round := 1; ; round++ {
+		var changed int64
+		var counts []string
+		for _, stmt := range stmts {
+			if !rb.Repeat {
+				fmt.Printf("%s\n", truncateQuery(stmt, 80))
+			}
+			r, err := runStatementOn(ctx, conn, stmt)
+			if err != nil {
+				// The connection goes back to the pool; nothing may stay open on it
+				_, _ = conn.ExecContext(context.Background(), "ROLLBACK")
+				fmt.Printf(tr("Error: %v\n"), canceled(ctx, err))
+				if rb.Repeat {
+					fmt.Printf("Stopped in round %d; the round was rolled back if it ran in a transaction\n", round)
+				}
+				return
+			}
+			if !rb.Repeat {
+				fmt.Print(p.resultOutput(r, false))
+				continue
+			}
+			changed += r.RowsAffected
+			if verb := firstWord(statementBody(stmt)); r.Columns == nil && verb != "START" && verb != "BEGIN" && verb != "COMMIT" {
+				counts = append(counts, fmt.Sprintf("%s %d", verb, r.RowsAffected))
+			}
+		}
+		if !rb.Repeat {
+			break
+		}
+		fmt.Printf("Round %d: %s\n", round, strings.Join(counts, ", "))
+		if changed == 0 {
+			fmt.Printf("Done after %d round%s (%s)\n", round, plural(round), time.Since(start).Round(time.Millisecond))
+			break
+		}
+		if ctx.Err() != nil {
+			fmt.Printf("Stopped after round %d\n", round)
+			break
+		}
+	}
+	p.cacheTime = time.Time{}
+}
+
+// askValue asks for a line of text on the terminal
+func askValue(question string) string {
+	fmt.Print(question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimSpace(answer)
+}
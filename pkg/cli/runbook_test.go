@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRunbook(t *testing.T) {
+	rb := parseRunbook("archive-rows", builtinRunbooks["archive-rows"])
+	if !rb.Repeat {
+		t.Error("archive-rows should repeat")
+	}
+	var names []string
+	for _, param := range rb.Params {
+		names = append(names, param.Name)
+	}
+	if want := []string{"archive_table", "table", "condition", "key", "batch"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("params = %v, expected %v", names, want)
+	}
+	if batch := rb.Params[4]; !batch.HasDefault || batch.Default != "1000" {
+		t.Errorf("batch = %+v", batch)
+	}
+
+	stmts, err := rb.render(map[string]string{
+		"archive_table": "orders_2019", "table": "orders", "condition": "created < '2020-01-01'", "key": "id", "batch": "500",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"START TRANSACTION",
+		"INSERT INTO orders_2019 SELECT * FROM orders WHERE created < '2020-01-01' ORDER BY id LIMIT 500",
+		"DELETE FROM orders WHERE created < '2020-01-01' ORDER BY id LIMIT 500",
+		"COMMIT",
+	}
+	if !reflect.DeepEqual(stmts, want) {
+		t.Errorf("render = %q", stmts)
+	}
+
+	// A default on a later use applies to earlier ones
+	rb = parseRunbook("x", "SELECT ${a} FROM t WHERE x = ${a=1}")
+	if rb.Repeat || len(rb.Params) != 1 || rb.Params[0].Default != "1" {
+		t.Errorf("got %+v", rb)
+	}
+}
+
+func TestRunbookArgs(t *testing.T) {
+	got, err := runbookArgs(` table=orders condition="created < NOW()" batch=10 empty=`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"table": "orders", "condition": "created < NOW()", "batch": "10", "empty": ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("runbookArgs = %q", got)
+	}
+	for _, bad := range []string{"orders", `c="open`} {
+		if _, err := runbookArgs(bad); err == nil {
+			t.Errorf("runbookArgs(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,613 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// maxPlaceholders is the most parameters MySQL accepts in one statement
+const maxPlaceholders = 65535
+
+// seedSampleRows is how many referenced keys are sampled for a foreign key
+const seedSampleRows = 10000
+
+// SeedOptions holds the settings of the seed subcommand
+type SeedOptions struct {
+	Table     string // [db.]table
+	Rows      int
+	BatchSize int
+	Seed      uint64 // 0 picks a random seed
+}
+
+// seedColumn is a column seed fills, with what generating its values needs
+type seedColumn struct {
+	Name       string
+	DataType   string // lower case, e.g. "varchar"
+	ColumnType string // e.g. "int unsigned" or "enum('a','b')"
+	Nullable   bool
+	MaxLength  int64
+	Precision  int64
+	Scale      int64
+	Unique     bool
+	Next       int64 // first value of a unique integer column
+}
+
+// seedUniqueKey is a unique key of the seeded table. Auto is set when one of
+// its columns is auto-increment, which keeps every row unique already.
+type seedUniqueKey struct {
+	Name    string
+	Columns []string
+	Auto    bool
+}
+
+// seedForeignKey holds sampled values of the columns of one foreign key, so
+// every row references a row that exists
+type seedForeignKey struct {
+	Columns []int // indexes into the seeded columns
+	Values  [][]any
+}
+
+var enumValues = regexp.MustCompile(`'((?:[^']|'')*)'`)
+
+var (
+	seedFirstNames = []string{"James", "Mary", "Robert", "Patricia", "John", "Jennifer", "Michael", "Linda", "David", "Elizabeth",
+		"William", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas", "Sarah", "Carlos", "Karen", "Wei", "Aisha", "Hiroshi", "Priya"}
+	seedLastNames = []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez",
+		"Hernandez", "Lopez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin", "Lee", "Chen", "Patel", "Kim", "Nguyen"}
+	seedCities    = []string{"Springfield", "Riverside", "Franklin", "Greenville", "Bristol", "Clinton", "Fairview", "Salem", "Madison", "Georgetown", "Arlington", "Ashland"}
+	seedCountries = []string{"United States", "Canada", "Mexico", "United Kingdom", "Germany", "France", "Spain", "Italy", "Japan", "India", "Brazil", "Australia"}
+	seedStreets   = []string{"Main St", "Oak Ave", "Pine St", "Maple Ave", "Cedar Ln", "Elm St", "Washington Blvd", "Lake Dr", "Hill Rd", "Park Ave"}
+	seedCompanies = []string{"Acme", "Globex", "Initech", "Umbrella", "Stark", "Wayne", "Wonka", "Hooli", "Vandelay", "Soylent", "Cyberdyne", "Tyrell"}
+	seedDomains   = []string{"example.com", "example.org", "example.net"}
+	seedColors    = []string{"red", "green", "blue", "black", "white", "silver", "orange", "purple", "yellow", "gray"}
+	seedStatuses  = []string{"active", "inactive", "pending", "archived"}
+	seedWords     = []string{"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do", "eiusmod",
+		"tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim", "ad", "minim", "veniam", "quis"}
+)
+
+// Seed fills a table with generated rows. It is the entry point of the seed
+// subcommand.
+func Seed(host string, port int, user, password, database, socket, loginPath, configFile string, zstdCompressionLevel int, opts SeedOptions) error {
+	table, err := parseTableRef(opts.Table, database)
+	if err != nil {
+		return err
+	}
+	if table.Profile != "" {
+		return fmt.Errorf("seed does not take a profile (%s:); select the server with --login-path", table.Profile)
+	}
+	if opts.Rows < 1 {
+		return fmt.Errorf("--rows must be at least 1")
+	}
+	if opts.BatchSize < 1 {
+		opts.BatchSize = 1000
+	}
+	if opts.Seed == 0 {
+		opts.Seed = rand.Uint64()
+	}
+
+	cfg := LoadSyntaxConfig()
+	SetSessionVariables(cfg.Session)
+	db, _, _, err := connect(host, port, user, password, "", socket, loginPath, configFile, zstdCompressionLevel)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	table.DB = db
+
+	ctx := context.Background()
+	columns, skipped, err := seedColumns(ctx, table)
+	if err != nil {
+		return err
+	}
+	keys, err := seedForeignKeys(ctx, table, columns)
+	if err != nil {
+		return err
+	}
+	ignored, err := markUniqueColumns(ctx, table, columns, keys)
+	if err != nil {
+		return err
+	}
+	var existing int64
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table.quoted()).Scan(&existing); err != nil {
+		return err
+	}
+	last := strconv.FormatInt(existing+int64(opts.Rows)-1, 10)
+	for _, c := range columns {
+		if c.Unique && !isIntegerType(c.DataType) && c.MaxLength > 0 && int64(len(last)) > c.MaxLength {
+			return fmt.Errorf("%s is unique and holds %d characters, too few to number %d rows", c.Name, c.MaxLength, opts.Rows)
+		}
+	}
+
+	batch := min(opts.BatchSize, maxPlaceholders/len(columns))
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	fmt.Printf("Seeding %s with %d row%s in batches of %d (seed %d)\n", table, opts.Rows, plural(opts.Rows), batch, opts.Seed)
+	if len(skipped) > 0 {
+		fmt.Printf("Left to the server: %s\n", strings.Join(skipped, ", "))
+	}
+	if len(ignored) > 0 {
+		fmt.Printf("Rows repeating a value of unique key %s are skipped\n", strings.Join(ignored, ", "))
+	}
+
+	r := rand.New(rand.NewPCG(opts.Seed, opts.Seed))
+	progress := term.IsTerminal(int(os.Stdout.Fd()))
+	start := time.Now()
+	generated, inserted := 0, 0
+	for generated < opts.Rows {
+		n := min(batch, opts.Rows-generated)
+		args := make([]any, 0, n*len(columns))
+		for i := range n {
+			args = append(args, seedRow(r, columns, keys, existing+int64(generated+i))...)
+		}
+		res, err := db.ExecContext(ctx, seedInsert(table, names, n, len(ignored) > 0), args...)
+		if err != nil {
+			if progress {
+				fmt.Println()
+			}
+			return fmt.Errorf("insert failed after %d rows: %w", inserted, err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			affected = int64(n)
+		}
+		generated += n
+		inserted += int(affected)
+		if progress {
+			fmt.Printf("\r%d/%d rows", generated, opts.Rows)
+		}
+	}
+	if progress {
+		fmt.Println()
+	}
+	elapsed := time.Since(start)
+	fmt.Printf("Inserted %d row%s into %s in %.2fs (%.0f rows/s)\n", inserted, plural(inserted), table,
+		elapsed.Seconds(), float64(inserted)/elapsed.Seconds())
+	if duplicates := generated - inserted; duplicates > 0 {
+		fmt.Printf("Skipped %d duplicate row%s\n", duplicates, plural(duplicates))
+	}
+	return nil
+}
+
+// seedColumns reads the columns to fill. Auto-increment and generated columns
+// are left to the server and returned as skipped.
+func seedColumns(ctx context.Context, t checksumTable) ([]seedColumn, []string, error) {
+	rows, err := t.DB.QueryContext(ctx, `SELECT COLUMN_NAME, DATA_TYPE, COLUMN_TYPE, IS_NULLABLE = 'YES',
+		COALESCE(CHARACTER_MAXIMUM_LENGTH, 0), COALESCE(NUMERIC_PRECISION, 0), COALESCE(NUMERIC_SCALE, 0), EXTRA
+		FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION`, t.Database, t.Table)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	var columns []seedColumn
+	var skipped []string
+	for rows.Next() {
+		var c seedColumn
+		var extra string
+		if err := rows.Scan(&c.Name, &c.DataType, &c.ColumnType, &c.Nullable, &c.MaxLength, &c.Precision, &c.Scale, &extra); err != nil {
+			return nil, nil, err
+		}
+		extra = strings.ToLower(extra)
+		switch {
+		case strings.Contains(extra, "auto_increment"):
+			skipped = append(skipped, c.Name+" (auto_increment)")
+		case strings.Contains(extra, "virtual generated"), strings.Contains(extra, "stored generated"):
+			skipped = append(skipped, c.Name+" (generated)")
+		default:
+			c.DataType = strings.ToLower(c.DataType)
+			columns = append(columns, c)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(columns) == 0 && len(skipped) == 0 {
+		return nil, nil, fmt.Errorf("table %s does not exist", t)
+	}
+	if len(columns) == 0 {
+		return nil, nil, fmt.Errorf("%s has no columns to fill", t)
+	}
+	return columns, skipped, nil
+}
+
+// markUniqueColumns numbers a column of each unique key, so rows do not
+// repeat its values; integer ones continue from the largest value in the
+// table. It returns the keys it cannot number, whose duplicates the inserts
+// skip.
+func markUniqueColumns(ctx context.Context, t checksumTable, columns []seedColumn, fks []seedForeignKey) ([]string, error) {
+	rows, err := t.DB.QueryContext(ctx, `SELECT s.INDEX_NAME, COALESCE(s.COLUMN_NAME, ''), COALESCE(c.EXTRA LIKE '%auto_increment%', 0)
+		FROM INFORMATION_SCHEMA.STATISTICS s LEFT JOIN INFORMATION_SCHEMA.COLUMNS c
+		ON c.TABLE_SCHEMA = s.TABLE_SCHEMA AND c.TABLE_NAME = s.TABLE_NAME AND c.COLUMN_NAME = s.COLUMN_NAME
+		WHERE s.TABLE_SCHEMA = ? AND s.TABLE_NAME = ? AND s.NON_UNIQUE = 0
+		ORDER BY s.INDEX_NAME = 'PRIMARY' DESC, s.INDEX_NAME, s.SEQ_IN_INDEX`, t.Database, t.Table)
+	if err != nil {
+		return nil, err
+	}
+	var keys []seedUniqueKey
+	for rows.Next() {
+		var name, column string
+		var auto bool
+		if err := rows.Scan(&name, &column, &auto); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if len(keys) == 0 || keys[len(keys)-1].Name != name {
+			keys = append(keys, seedUniqueKey{Name: name})
+		}
+		k := &keys[len(keys)-1]
+		k.Columns = append(k.Columns, column)
+		k.Auto = k.Auto || auto
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ignored := numberUniqueKeys(columns, keys, fks)
+	for i := range columns {
+		c := &columns[i]
+		if c.Unique && isIntegerType(c.DataType) {
+			var largest sql.NullInt64
+			if err := t.DB.QueryRowContext(ctx, fmt.Sprintf("SELECT MAX(%s) FROM %s", quoteIdentifier(c.Name), t.quoted())).Scan(&largest); err != nil {
+				return nil, err
+			}
+			c.Next = largest.Int64 + 1
+		}
+	}
+	return ignored, nil
+}
+
+// numberUniqueKeys marks one column of each unique key Unique: the first
+// integer or string column that is not filled from a foreign key sample. A
+// key with an auto-increment or already numbered column needs none. The
+// names of keys with no such column, like the primary key of a junction
+// table or a unique foreign key, are returned.
+func numberUniqueKeys(columns []seedColumn, keys []seedUniqueKey, fks []seedForeignKey) []string {
+	sampled := make(map[int]bool)
+	for _, fk := range fks {
+		for _, i := range fk.Columns {
+			sampled[i] = true
+		}
+	}
+	var ignored []string
+	for _, key := range keys {
+		if key.Auto {
+			continue
+		}
+		numbered, candidate := false, -1
+		for _, name := range key.Columns {
+			i := slices.IndexFunc(columns, func(c seedColumn) bool { return strings.EqualFold(c.Name, name) })
+			switch {
+			case i < 0:
+			case columns[i].Unique:
+				numbered = true
+			case candidate < 0 && !sampled[i] && (isIntegerType(columns[i].DataType) || isSeedTextType(columns[i].DataType)):
+				candidate = i
+			}
+		}
+		switch {
+		case numbered:
+		case candidate >= 0:
+			columns[candidate].Unique = true
+		default:
+			ignored = append(ignored, key.Name)
+		}
+	}
+	return ignored
+}
+
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// seedForeignKeys samples the referenced keys of each foreign key
+func seedForeignKeys(ctx context.Context, t checksumTable, columns []seedColumn) ([]seedForeignKey, error) {
+	rows, err := t.DB.QueryContext(ctx, `SELECT CONSTRAINT_NAME, COLUMN_NAME, REFERENCED_TABLE_SCHEMA, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME
+		FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND REFERENCED_TABLE_NAME IS NOT NULL
+		ORDER BY CONSTRAINT_NAME, ORDINAL_POSITION`, t.Database, t.Table)
+	if err != nil {
+		return nil, err
+	}
+	type reference struct {
+		columns    []int
+		referenced []string
+		table      checksumTable
+	}
+	var refs []*reference
+	byName := make(map[string]*reference)
+	for rows.Next() {
+		var constraint, column, refSchema, refTable, refColumn string
+		if err := rows.Scan(&constraint, &column, &refSchema, &refTable, &refColumn); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ref, ok := byName[constraint]
+		if !ok {
+			ref = &reference{table: checksumTable{Database: refSchema, Table: refTable, DB: t.DB}}
+			byName[constraint] = ref
+			refs = append(refs, ref)
+		}
+		for i, c := range columns {
+			if strings.EqualFold(c.Name, column) {
+				ref.columns = append(ref.columns, i)
+				ref.referenced = append(ref.referenced, refColumn)
+			}
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var keys []seedForeignKey
+	for _, ref := range refs {
+		if len(ref.columns) == 0 {
+			continue
+		}
+		cols := quoteColumns(ref.referenced)
+		values, err := sampleRows(ctx, t.DB, fmt.Sprintf("SELECT DISTINCT %s FROM %s LIMIT %d", cols, ref.table.quoted(), seedSampleRows), len(ref.columns))
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 0 {
+			for _, i := range ref.columns {
+				if !columns[i].Nullable {
+					return nil, fmt.Errorf("%s references %s, which is empty; seed it first", columns[i].Name, ref.table)
+				}
+			}
+			values = [][]any{make([]any, len(ref.columns))}
+		}
+		keys = append(keys, seedForeignKey{Columns: ref.columns, Values: values})
+	}
+	return keys, nil
+}
+
+func sampleRows(ctx context.Context, db *sql.DB, query string, width int) ([][]any, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var values [][]any
+	for rows.Next() {
+		row := make([]sql.RawBytes, width)
+		dest := make([]any, width)
+		for i := range row {
+			dest[i] = &row[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		copied := make([]any, width)
+		for i, v := range row {
+			if v != nil {
+				copied[i] = string(v)
+			}
+		}
+		values = append(values, copied)
+	}
+	return values, rows.Err()
+}
+
+// seedInsert returns a multi-row INSERT for n rows, an INSERT IGNORE that
+// skips duplicate rows when ignore is set
+func seedInsert(t checksumTable, columns []string, n int, ignore bool) string {
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	values := strings.TrimSuffix(strings.Repeat(row+", ", n), ", ")
+	verb := "INSERT"
+	if ignore {
+		verb = "INSERT IGNORE"
+	}
+	return fmt.Sprintf("%s INTO %s (%s) VALUES %s", verb, t.quoted(), quoteColumns(columns), values)
+}
+
+// seedRow generates the values of one row; seq numbers it, for unique columns
+func seedRow(r *rand.Rand, columns []seedColumn, keys []seedForeignKey, seq int64) []any {
+	row := make([]any, len(columns))
+	for i := range columns {
+		row[i] = columns[i].value(r, seq)
+	}
+	for _, key := range keys {
+		values := key.Values[r.IntN(len(key.Values))]
+		for j, i := range key.Columns {
+			row[i] = values[j]
+		}
+	}
+	return row
+}
+
+// isSeedTextType reports whether value generates text for the type, which
+// a unique column gets a number appended to
+func isSeedTextType(dataType string) bool {
+	switch dataType {
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext":
+		return true
+	}
+	return false
+}
+
+func isIntegerType(dataType string) bool {
+	switch dataType {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+		return true
+	}
+	return false
+}
+
+// value generates a value for the column from its type and, for strings,
+// its name: an email column gets addresses, a city column city names
+func (c *seedColumn) value(r *rand.Rand, seq int64) any {
+	if c.Nullable && !c.Unique && r.IntN(20) == 0 {
+		return nil
+	}
+	name := strings.ToLower(c.Name)
+	now := time.Now()
+	switch c.DataType {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+		if c.Unique {
+			return c.Next + seq
+		}
+		if c.ColumnType == "tinyint(1)" {
+			return r.IntN(2)
+		}
+		switch {
+		case name == "age" || strings.HasSuffix(name, "_age"):
+			return 18 + r.IntN(72)
+		case strings.Contains(name, "qty"), strings.Contains(name, "quantity"):
+			return 1 + r.IntN(20)
+		}
+		limit := int64(1000000)
+		switch c.DataType {
+		case "tinyint":
+			limit = 128
+		case "smallint":
+			limit = 32768
+		}
+		return r.Int64N(limit)
+	case "decimal", "numeric":
+		whole := min(c.Precision-c.Scale, 5)
+		limit := 1.0
+		for range whole {
+			limit *= 10
+		}
+		return strconv.FormatFloat(r.Float64()*limit, 'f', int(c.Scale), 64)
+	case "float", "double", "real":
+		return float64(r.IntN(100000)) / 100
+	case "date":
+		if strings.Contains(name, "birth") || name == "dob" {
+			return now.AddDate(-18-r.IntN(62), 0, -r.IntN(365)).Format(time.DateOnly)
+		}
+		return now.AddDate(0, 0, -r.IntN(5*365)).Format(time.DateOnly)
+	case "datetime", "timestamp":
+		return now.Add(-time.Duration(r.Int64N(2*365*24*3600)) * time.Second).Format(time.DateTime)
+	case "time":
+		return fmt.Sprintf("%02d:%02d:%02d", r.IntN(24), r.IntN(60), r.IntN(60))
+	case "year":
+		return 1990 + r.IntN(now.Year()-1989)
+	case "enum", "set":
+		options := enumValues.FindAllStringSubmatch(c.ColumnType, -1)
+		if len(options) == 0 {
+			return nil
+		}
+		return strings.ReplaceAll(options[r.IntN(len(options))][1], "''", "'")
+	case "json":
+		return fmt.Sprintf(`{"id": %d, "tag": %q}`, seq, pick(r, seedWords))
+	case "bit":
+		return r.Uint64N(1 << min(c.Precision, 63))
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+		b := make([]byte, min(max(c.MaxLength, 1), 16))
+		for i := range b {
+			b[i] = byte(r.IntN(256))
+		}
+		return b
+	}
+
+	text := seedText(r, name, c.MaxLength)
+	if c.Unique {
+		return numberedText(text, strconv.FormatInt(seq, 10), c.MaxLength)
+	}
+	return truncateRunes(text, c.MaxLength)
+}
+
+// numberedText appends suffix to text, into the local part of an address,
+// shortening text to fit maxLength. When no character of text fits, the
+// number is all there is.
+func numberedText(text, suffix string, maxLength int64) string {
+	if local, domain, ok := strings.Cut(text, "@"); ok {
+		switch keep := maxLength - int64(len(suffix)+1+len(domain)); {
+		case maxLength <= 0, keep > 0:
+			return truncateRunes(local, keep) + suffix + "@" + domain
+		case keep == 0:
+			return suffix + "@" + domain
+		}
+	} else if keep := maxLength - int64(len(suffix)+1); maxLength <= 0 || keep > 0 {
+		return truncateRunes(text, keep) + "-" + suffix
+	}
+	return suffix
+}
+
+// seedText picks a realistic string for a column from its name
+func seedText(r *rand.Rand, name string, maxLength int64) string {
+	first, last := pick(r, seedFirstNames), pick(r, seedLastNames)
+	switch {
+	case strings.Contains(name, "email"):
+		return strings.ToLower(first+"."+last) + "@" + pick(r, seedDomains)
+	case strings.Contains(name, "first") || name == "fname" || strings.Contains(name, "given"):
+		return first
+	case strings.Contains(name, "last") || strings.Contains(name, "surname") || strings.Contains(name, "family"):
+		return last
+	case strings.Contains(name, "user") || strings.Contains(name, "login") || strings.Contains(name, "handle"):
+		return strings.ToLower(first[:1]+last) + strconv.Itoa(r.IntN(1000))
+	case strings.Contains(name, "phone") || strings.Contains(name, "mobile") || strings.Contains(name, "tel"):
+		return fmt.Sprintf("+1-555-%03d-%04d", r.IntN(1000), r.IntN(10000))
+	case strings.Contains(name, "city"):
+		return pick(r, seedCities)
+	case strings.Contains(name, "country"):
+		return pick(r, seedCountries)
+	case strings.Contains(name, "address") || strings.Contains(name, "street"):
+		return fmt.Sprintf("%d %s", 1+r.IntN(9999), pick(r, seedStreets))
+	case strings.Contains(name, "zip") || strings.Contains(name, "postal") || strings.Contains(name, "postcode"):
+		return fmt.Sprintf("%05d", r.IntN(100000))
+	case strings.Contains(name, "company") || strings.Contains(name, "organization"):
+		return pick(r, seedCompanies) + " " + pick(r, []string{"Inc", "LLC", "Ltd", "Corp"})
+	case strings.Contains(name, "url") || strings.Contains(name, "website") || strings.Contains(name, "homepage"):
+		return "https://www." + strings.ToLower(pick(r, seedCompanies)) + ".example.com/" + pick(r, seedWords)
+	case strings.Contains(name, "uuid") || strings.Contains(name, "guid") || maxLength == 36 && strings.HasSuffix(name, "id"):
+		return fmt.Sprintf("%08x-%04x-4%03x-%04x-%012x", r.Uint32(), r.IntN(1<<16), r.IntN(1<<12), 0x8000|r.IntN(1<<14), r.Int64N(1<<48))
+	case name == "ip" || strings.HasPrefix(name, "ip_") || strings.HasSuffix(name, "_ip"):
+		return fmt.Sprintf("10.%d.%d.%d", r.IntN(256), r.IntN(256), 1+r.IntN(254))
+	case strings.Contains(name, "colo"):
+		return pick(r, seedColors)
+	case strings.Contains(name, "status") || strings.Contains(name, "state"):
+		return pick(r, seedStatuses)
+	case strings.Contains(name, "name"):
+		return first + " " + last
+	case strings.Contains(name, "title") || strings.Contains(name, "subject"):
+		title := seedSentence(r, 3+r.IntN(3))
+		return strings.ToUpper(title[:1]) + title[1:]
+	case strings.Contains(name, "code") || strings.Contains(name, "sku"):
+		return fmt.Sprintf("%c%c-%05d", 'A'+r.IntN(26), 'A'+r.IntN(26), r.IntN(100000))
+	}
+	if maxLength > 0 && maxLength <= 40 {
+		return seedSentence(r, 1+r.IntN(3))
+	}
+	return seedSentence(r, 5+r.IntN(20))
+}
+
+func seedSentence(r *rand.Rand, words int) string {
+	parts := make([]string, words)
+	for i := range parts {
+		parts[i] = pick(r, seedWords)
+	}
+	return strings.Join(parts, " ")
+}
+
+func pick(r *rand.Rand, values []string) string {
+	return values[r.IntN(len(values))]
+}
+
+// truncateRunes shortens s to at most n characters; n <= 0 means no limit
+func truncateRunes(s string, n int64) string {
+	if n <= 0 || int64(utf8.RuneCountInString(s)) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
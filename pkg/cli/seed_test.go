@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"regexp"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSeedColumnValue(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 1))
+	tests := []struct {
+		column seedColumn
+		match  string
+	}{
+		{seedColumn{Name: "email", DataType: "varchar", MaxLength: 100}, `^[a-z]+\.[a-z]+@example\.(com|org|net)$`},
+		{seedColumn{Name: "email", DataType: "varchar", MaxLength: 100, Unique: true}, `^[a-z]+\.[a-z]+42@example\.(com|org|net)$`},
+		{seedColumn{Name: "first_name", DataType: "varchar", MaxLength: 45}, `^[A-Z][a-z]+$`},
+		{seedColumn{Name: "phone", DataType: "varchar", MaxLength: 20}, `^\+1-555-\d{3}-\d{4}$`},
+		{seedColumn{Name: "rating", DataType: "enum", ColumnType: "enum('G','PG-13','it''s')"}, `^(G|PG-13|it's)$`},
+		{seedColumn{Name: "code", DataType: "char", MaxLength: 4, Unique: true}, `^.{1,2}-42$`},
+		{seedColumn{Name: "amount", DataType: "decimal", Precision: 5, Scale: 2}, `^\d{1,3}\.\d{2}$`},
+		{seedColumn{Name: "rental_date", DataType: "datetime"}, `^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}$`},
+		{seedColumn{Name: "active", DataType: "tinyint", ColumnType: "tinyint(1)"}, `^[01]$`},
+		{seedColumn{Name: "id", DataType: "int", Unique: true, Next: 101}, `^143$`},
+	}
+	for _, tt := range tests {
+		for range 20 {
+			got := fmt.Sprint(tt.column.value(r, 42))
+			if !regexp.MustCompile(tt.match).MatchString(got) {
+				t.Errorf("%s (%s) = %q, want match for %s", tt.column.Name, tt.column.DataType, got, tt.match)
+				break
+			}
+		}
+	}
+}
+
+func TestSeedColumnLength(t *testing.T) {
+	r := rand.New(rand.NewPCG(2, 2))
+	c := seedColumn{Name: "description", DataType: "varchar", MaxLength: 12}
+	for range 50 {
+		if s := c.value(r, 1).(string); utf8.RuneCountInString(s) > 12 {
+			t.Fatalf("value %q is longer than VARCHAR(12)", s)
+		}
+	}
+}
+
+func TestSeedRowForeignKeys(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 3))
+	columns := []seedColumn{
+		{Name: "store_id", DataType: "int"},
+		{Name: "note", DataType: "varchar", MaxLength: 20},
+		{Name: "country_id", DataType: "int"},
+	}
+	keys := []seedForeignKey{{Columns: []int{0, 2}, Values: [][]any{{"1", "10"}, {"2", "20"}}}}
+	for range 20 {
+		row := seedRow(r, columns, keys, 0)
+		pair := [2]any{row[0], row[2]}
+		if pair != [2]any{"1", "10"} && pair != [2]any{"2", "20"} {
+			t.Fatalf("row %v does not use a sampled key tuple", row)
+		}
+	}
+}
+
+func TestSeedDeterministic(t *testing.T) {
+	columns := []seedColumn{{Name: "name", DataType: "varchar", MaxLength: 50}, {Name: "score", DataType: "int"}}
+	a := seedRow(rand.New(rand.NewPCG(7, 7)), columns, nil, 0)
+	b := seedRow(rand.New(rand.NewPCG(7, 7)), columns, nil, 0)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("rows with the same seed differ: %v and %v", a, b)
+	}
+}
+
+func TestSeedInsert(t *testing.T) {
+	got := seedInsert(checksumTable{Database: "sakila", Table: "actor"}, []string{"first_name", "last_name"}, 2, false)
+	want := "INSERT INTO sakila.actor (first_name, last_name) VALUES (?, ?), (?, ?)"
+	if got != want {
+		t.Errorf("seedInsert = %q, want %q", got, want)
+	}
+	got = seedInsert(checksumTable{Database: "sakila", Table: "film_actor"}, []string{"actor_id", "film_id"}, 1, true)
+	want = "INSERT IGNORE INTO sakila.film_actor (actor_id, film_id) VALUES (?, ?)"
+	if got != want {
+		t.Errorf("seedInsert ignoring duplicates = %q, want %q", got, want)
+	}
+}
+
+func TestNumberedTextShortColumns(t *testing.T) {
+	tests := []struct {
+		text, suffix string
+		maxLength    int64
+		want         string
+	}{
+		{"ZX-12345", "7", 8, "ZX-123-7"},
+		{"ZX-12345", "1234567", 8, "1234567"},
+		{"ZX-12345", "12345678", 8, "12345678"},
+		{"anna.lee@example.com", "42", 20, "anna.l42@example.com"},
+		{"anna.lee@example.com", "42", 14, "42@example.com"},
+		{"anna.lee@example.com", "42", 12, "42"},
+		{"anna.lee@example.com", "42", 0, "anna.lee42@example.com"},
+	}
+	for _, tt := range tests {
+		got := numberedText(tt.text, tt.suffix, tt.maxLength)
+		if got != tt.want {
+			t.Errorf("numberedText(%q, %q, %d) = %q, want %q", tt.text, tt.suffix, tt.maxLength, got, tt.want)
+		}
+		if tt.maxLength > 0 && int64(utf8.RuneCountInString(got)) > tt.maxLength {
+			t.Errorf("numberedText(%q, %q, %d) = %q does not fit", tt.text, tt.suffix, tt.maxLength, got)
+		}
+	}
+
+	// A unique VARCHAR(8) email column stays within its length for any row
+	r := rand.New(rand.NewPCG(4, 4))
+	c := seedColumn{Name: "email", DataType: "varchar", MaxLength: 8, Unique: true}
+	for _, seq := range []int64{1, 12345, 1234567} {
+		if s := c.value(r, seq).(string); utf8.RuneCountInString(s) > 8 {
+			t.Errorf("value(%d) = %q is longer than VARCHAR(8)", seq, s)
+		}
+	}
+}
+
+func TestNumberUniqueKeys(t *testing.T) {
+	columns := func() []seedColumn {
+		return []seedColumn{
+			{Name: "actor_id", DataType: "smallint"},
+			{Name: "film_id", DataType: "smallint"},
+			{Name: "position", DataType: "int"},
+			{Name: "last_update", DataType: "timestamp"},
+		}
+	}
+	fks := []seedForeignKey{{Columns: []int{0}}, {Columns: []int{1}}}
+	tests := []struct {
+		name    string
+		keys    []seedUniqueKey
+		unique  []string
+		ignored []string
+	}{
+		{"junction table", []seedUniqueKey{{Name: "PRIMARY", Columns: []string{"actor_id", "film_id"}}}, nil, []string{"PRIMARY"}},
+		{"unique foreign key", []seedUniqueKey{{Name: "uk_actor", Columns: []string{"actor_id"}}}, nil, []string{"uk_actor"}},
+		{"composite with a plain column", []seedUniqueKey{{Name: "uk_film_position", Columns: []string{"film_id", "position"}}}, []string{"position"}, nil},
+		{"auto-increment", []seedUniqueKey{{Name: "PRIMARY", Columns: []string{"id"}, Auto: true}, {Name: "uk", Columns: []string{"Position"}}}, []string{"position"}, nil},
+		{"numbered column covers later keys", []seedUniqueKey{{Name: "a", Columns: []string{"position"}}, {Name: "b", Columns: []string{"actor_id", "position"}}}, []string{"position"}, nil},
+		{"not numberable", []seedUniqueKey{{Name: "uk_time", Columns: []string{"last_update"}}}, nil, []string{"uk_time"}},
+	}
+	for _, tt := range tests {
+		cols := columns()
+		ignored := numberUniqueKeys(cols, tt.keys, fks)
+		var unique []string
+		for _, c := range cols {
+			if c.Unique {
+				unique = append(unique, c.Name)
+			}
+		}
+		if !reflect.DeepEqual(unique, tt.unique) || !reflect.DeepEqual(ignored, tt.ignored) {
+			t.Errorf("%s: numbered %v, ignored %v; want %v, %v", tt.name, unique, ignored, tt.unique, tt.ignored)
+		}
+	}
+}
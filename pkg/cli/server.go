@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"database/sql"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Server flavors reported by parseServerInfo
+const (
+	flavorMySQL   = "MySQL"
+	flavorPercona = "Percona Server"
+	flavorMariaDB = "MariaDB"
+	flavorTiDB    = "TiDB"
+)
+
+// serverInfo describes the server behind a connection
+type serverInfo struct {
+	Version string // VERSION()
+	Comment string // @@version_comment
+	Flavor  string
+	Gateway string // ProxySQL or Vitess when connected through one, see gateway.go
+	Major   int
+	Minor   int
+	Patch   int
+
+	// CaseSensitiveTables is set when lower_case_table_names is 0, so
+	// Users and users are different tables
+	CaseSensitiveTables bool
+}
+
+var versionNumber = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// parseServerInfo works out the flavor and version from VERSION() and
+// @@version_comment. TiDB reports a MySQL-compatible version followed by its
+// own ("8.0.11-TiDB-v7.5.0"), which is the one kept. MariaDB may prefix the
+// version with "5.5.5-" for old replication clients.
+func parseServerInfo(version, comment string) serverInfo {
+	info := serverInfo{Version: version, Comment: comment, Flavor: flavorMySQL}
+	number := version
+	lower := strings.ToLower(version)
+	switch {
+	case strings.Contains(lower, "tidb"):
+		info.Flavor = flavorTiDB
+		if i := strings.Index(lower, "tidb-v"); i >= 0 {
+			number = version[i+len("tidb-v"):]
+		}
+	case strings.Contains(lower, "mariadb") || strings.Contains(strings.ToLower(comment), "mariadb"):
+		info.Flavor = flavorMariaDB
+		number = strings.TrimPrefix(version, "5.5.5-")
+	case strings.Contains(strings.ToLower(comment), "percona"):
+		info.Flavor = flavorPercona
+	}
+	if strings.Contains(lower, "vitess") || strings.Contains(strings.ToLower(comment), "vitess") {
+		info.Gateway = gatewayVitess
+	}
+	if m := versionNumber.FindStringSubmatch(number); m != nil {
+		info.Major, _ = strconv.Atoi(m[1])
+		info.Minor, _ = strconv.Atoi(m[2])
+		info.Patch, _ = strconv.Atoi(m[3])
+	}
+	return info
+}
+
+// queryServerInfo asks the server for its version
+func queryServerInfo(db *sql.DB) (serverInfo, error) {
+	var version string
+	if err := db.QueryRow("SELECT VERSION()").Scan(&version); err != nil {
+		return serverInfo{}, err
+	}
+	// Not every server or proxy has @@version_comment
+	var comment sql.NullString
+	_ = db.QueryRow("SELECT @@version_comment").Scan(&comment)
+	info := parseServerInfo(version, comment.String)
+	var lowerCaseTableNames sql.NullInt64
+	if db.QueryRow("SELECT @@lower_case_table_names").Scan(&lowerCaseTableNames) == nil {
+		info.CaseSensitiveTables = lowerCaseTableNames.Valid && lowerCaseTableNames.Int64 == 0
+	}
+	if info.Gateway == "" && behindProxySQL(db) {
+		info.Gateway = gatewayProxySQL
+	}
+	return info, nil
+}
+
+// String returns e.g. "MariaDB 10.11.6" or "MySQL 8.0.36 via ProxySQL"
+func (s serverInfo) String() string {
+	str := s.Flavor + " " + strconv.Itoa(s.Major) + "." + strconv.Itoa(s.Minor) + "." + strconv.Itoa(s.Patch)
+	if s.Gateway != "" {
+		str += " via " + s.Gateway
+	}
+	return str
+}
+
+// atLeast reports whether the flavor's own version is major.minor.patch or
+// newer
+func (s serverInfo) atLeast(major, minor, patch int) bool {
+	if s.Major != major {
+		return s.Major > major
+	}
+	if s.Minor != minor {
+		return s.Minor > minor
+	}
+	return s.Patch >= patch
+}
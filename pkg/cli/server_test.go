@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseServerInfo(t *testing.T) {
+	tests := []struct {
+		version, comment string
+		flavor           string
+		major, minor     int
+	}{
+		{"8.4.2", "MySQL Community Server - GPL", flavorMySQL, 8, 4},
+		{"8.0.36-28", "Percona Server (GPL), Release 28, Revision 47601f19", flavorPercona, 8, 0},
+		{"10.11.6-MariaDB-1:10.11.6+maria~ubu2204", "mariadb.org binary distribution", flavorMariaDB, 10, 11},
+		{"5.5.5-10.6.16-MariaDB", "", flavorMariaDB, 10, 6},
+		{"8.0.11-TiDB-v7.5.0", "TiDB Server (Apache License 2.0) Community Edition", flavorTiDB, 7, 5},
+		{"5.7.44-log", "", flavorMySQL, 5, 7},
+	}
+	for _, tt := range tests {
+		got := parseServerInfo(tt.version, tt.comment)
+		if got.Flavor != tt.flavor || got.Major != tt.major || got.Minor != tt.minor {
+			t.Errorf("parseServerInfo(%q, %q) = %s %d.%d, want %s %d.%d",
+				tt.version, tt.comment, got.Flavor, got.Major, got.Minor, tt.flavor, tt.major, tt.minor)
+		}
+	}
+}
+
+func TestServerInfoAtLeast(t *testing.T) {
+	info := parseServerInfo("8.4.2", "")
+	if !info.atLeast(8, 4, 0) || !info.atLeast(8, 0, 22) || info.atLeast(8, 4, 3) || info.atLeast(9, 0, 0) {
+		t.Errorf("atLeast wrong for %s", info)
+	}
+}
+
+func TestSQLModeNotes(t *testing.T) {
+	tests := []struct {
+		mode string
+		want int
+	}{
+		{"ONLY_FULL_GROUP_BY,STRICT_TRANS_TABLES,NO_ZERO_IN_DATE,NO_ZERO_DATE,ERROR_FOR_DIVISION_BY_ZERO,NO_ENGINE_SUBSTITUTION", 0},
+		{"", 1},
+		{"NO_ENGINE_SUBSTITUTION", 1},
+		{"STRICT_ALL_TABLES,ANSI_QUOTES", 1},
+		{"STRICT_TRANS_TABLES,NO_BACKSLASH_ESCAPES,PIPES_AS_CONCAT", 2},
+		{"REAL_AS_FLOAT,PIPES_AS_CONCAT,ANSI_QUOTES,IGNORE_SPACE,ONLY_FULL_GROUP_BY,ANSI", 2},
+	}
+	for _, tt := range tests {
+		if got := sqlModeNotes(tt.mode); len(got) != tt.want {
+			t.Errorf("sqlModeNotes(%q) = %q, want %d notes", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestIsLoopbackHost(t *testing.T) {
+	got := []bool{isLoopbackHost("localhost"), isLoopbackHost("127.0.0.2"), isLoopbackHost("::1"), isLoopbackHost("db.example.com")}
+	if want := []bool{true, true, true, false}; !reflect.DeepEqual(got, want) {
+		t.Errorf("isLoopbackHost = %v, want %v", got, want)
+	}
+}
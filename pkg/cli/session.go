@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sessionVariables are the [session] settings of ~/.go-myclirc. They are
+// passed to the driver as DSN parameters, which it SETs on every new
+// connection, so they survive reconnects and apply to every pooled
+// connection.
+var sessionVariables map[string]string
+
+// SetSessionVariables sets the variables applied to every connection. Names
+// that are not plain identifiers, and names of driver options, which would
+// change how the driver connects instead of setting a variable, are reported
+// here, once, and skipped.
+func SetSessionVariables(vars map[string]string) {
+	if vars == nil {
+		sessionVariables = nil
+		return
+	}
+	sessionVariables = make(map[string]string, len(vars))
+	for name, value := range vars {
+		if !sessionVariableName.MatchString(name) {
+			fmt.Printf("Warning: ignoring invalid session variable name %q in [session]\n", name)
+			continue
+		}
+		if driverOptions[strings.ToLower(name)] {
+			fmt.Printf("Warning: ignoring %q in [session]: it is a driver option, not a session variable; put it in the connection string\n", name)
+			continue
+		}
+		sessionVariables[name] = value
+	}
+}
+
+var sessionVariableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// driverOptions are the DSN parameters go-sql-driver reads as its own
+// settings rather than SETting them, lowercased. None is a server variable.
+var driverOptions = map[string]bool{
+	"allowallfiles": true, "allowcleartextpasswords": true, "allowfallbacktoplaintext": true,
+	"allownativepasswords": true, "allowoldpasswords": true, "charset": true, "checkconnliveness": true,
+	"clientfoundrows": true, "collation": true, "columnswithalias": true, "compress": true,
+	"connectionattributes": true, "interpolateparams": true, "loc": true, "maxallowedpacket": true,
+	"multistatements": true, "parsetime": true, "readtimeout": true, "rejectreadonly": true,
+	"serverpubkey": true, "strict": true, "timeout": true, "timetruncate": true, "tls": true,
+	"writetimeout": true,
+}
+
+// sessionParams turns [session] settings, already checked by
+// SetSessionVariables, into driver DSN parameters
+func sessionParams(vars map[string]string) map[string]string {
+	params := make(map[string]string, len(vars))
+	for name, value := range vars {
+		params[name] = quoteSessionValue(value)
+	}
+	return params
+}
+
+// quoteSessionValue quotes a value for SET unless it is a number, ON/OFF or
+// DEFAULT. Quotes around the value in the config file are optional.
+func quoteSessionValue(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '\'' || value[0] == '"') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	} else {
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return value
+		}
+		switch strings.ToUpper(value) {
+		case "ON", "OFF", "TRUE", "FALSE", "DEFAULT":
+			return value
+		}
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// sessionSummary lists the [session] settings for \config
+func sessionSummary(vars map[string]string) string {
+	if len(vars) == 0 {
+		return "(none)"
+	}
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + "=" + quoteSessionValue(vars[name])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQuoteSessionValue(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"30000", "30000"},
+		{"ON", "ON"},
+		{"default", "default"},
+		{"+00:00", "'+00:00'"},
+		{"'+00:00'", "'+00:00'"},
+		{`"UTC"`, "'UTC'"},
+		{"STRICT_TRANS_TABLES,NO_ZERO_DATE", "'STRICT_TRANS_TABLES,NO_ZERO_DATE'"},
+		{"it's", "'it''s'"},
+		{"''", "''"},
+	}
+	for _, tt := range tests {
+		if got := quoteSessionValue(tt.in); got != tt.want {
+			t.Errorf("quoteSessionValue(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSessionVariablesInDSN(t *testing.T) {
+	SetSessionVariables(map[string]string{"time_zone": "+00:00", "max_execution_time": "30000", "bad name": "1",
+		"timeout": "1s", "TLS": "false", "allowCleartextPasswords": "true", "charset": "latin1"})
+	SetConnectionParams(map[string]string{"charset": "utf8mb4"})
+	defer SetSessionVariables(nil)
+	defer SetConnectionParams(nil)
+
+	// Rejected names are dropped when the settings are loaded, so building
+	// DSNs later warns about nothing
+	for _, name := range []string{"bad name", "timeout", "TLS", "allowCleartextPasswords", "charset"} {
+		if _, ok := sessionVariables[name]; ok {
+			t.Errorf("[session] kept %q", name)
+		}
+	}
+
+	got := withConnectionParams("u:p@tcp(h:3306)/db")
+	want := "u:p@tcp(h:3306)/db?charset=utf8mb4&max_execution_time=30000&time_zone=%27%2B00%3A00%27"
+	if got != want {
+		t.Errorf("withConnectionParams = %q, want %q", got, want)
+	}
+}
+
+func TestLoadSyntaxConfigSession(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), ".go-myclirc")
+	content := "[main]\n[session]\nsql_mode = TRADITIONAL\ntime_zone = '+00:00'\n"
+	if err := os.WriteFile(rc, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GO_MYCLI_RC", rc)
+
+	cfg := LoadSyntaxConfig()
+	if got, want := sessionSummary(cfg.Session), "sql_mode='TRADITIONAL', time_zone='+00:00'"; got != want {
+		t.Errorf("session = %s, want %s", got, want)
+	}
+
+	if err := SaveSyntaxConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := quoteSessionValue(LoadSyntaxConfig().Session["time_zone"]); got != "'+00:00'" {
+		t.Errorf("time_zone after save = %s", got)
+	}
+}
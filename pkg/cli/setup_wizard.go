@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// setupThemes are the styles the first-run setup previews, dark ones first
+var setupThemes = []string{"monokai", "dracula", "nord", "gruvbox", "github-dark", "solarized-dark", "light", "github", "solarized-light"}
+
+// setupPreview is the statement each theme is previewed with
+const setupPreview = "SELECT name, COUNT(*) AS orders FROM customers WHERE created_at > '2024-01-01' GROUP BY name; -- top buyers"
+
+// setupAnswers are the choices made in the first-run setup
+type setupAnswers struct {
+	Theme       string
+	AI          bool
+	AIServerURL string // with AIMode copilot_mcp_http
+	AIMode      string // copilot_mcp_http, or mcp_stdio to run sqlbot directly
+	Suggestions bool
+	SafeUpdates bool // SET sql_safe_updates on every connection
+}
+
+// configFile returns the default config file with the answers in it. The
+// theme is used as it is, without the [colors] overrides.
+func (a setupAnswers) configFile() *ini.File {
+	cfg := defaultConfigFile()
+	main := cfg.Section("main")
+	main.Key("syntax_style").SetValue(a.Theme)
+	main.Key("use_custom_colors").SetValue("false")
+	main.Key("suggestions").SetValue(strconv.FormatBool(a.Suggestions))
+	main.Key("ai_analysis").SetValue(strconv.FormatBool(a.AI))
+	if a.AI {
+		main.Key("ai_server_mode").SetValue(a.AIMode)
+		if a.AIServerURL != "" {
+			main.Key("ai_server_url").SetValue(a.AIServerURL)
+		}
+	}
+	if a.SafeUpdates {
+		session, _ := cfg.NewSection("session")
+		session.NewKey("sql_safe_updates", "ON")
+		session.Comment = "# Refuse UPDATE and DELETE without a key in WHERE or a LIMIT (MySQL's --safe-updates)"
+	}
+	return cfg
+}
+
+// needsSetup reports whether go-mycli has no config file yet, which is when
+// the first-run setup is offered
+func needsSetup() bool {
+	path := syntaxConfigPath()
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return os.IsNotExist(err)
+}
+
+// runSetup is the first-run setup: a few questions whose answers are
+// written to the config file. Declining it writes the defaults, with AI
+// analysis off, so it is not offered again.
+func runSetup() {
+	path := syntaxConfigPath()
+	fmt.Printf("\nNo %s yet. A few questions set it up; Enter keeps the [default].\n", path)
+	answers := setupAnswers{Theme: "monokai", AIMode: "copilot_mcp_http", Suggestions: true}
+	if lightBackground("auto") {
+		answers.Theme = "light"
+	}
+	if askConfirm("Set up now? [Y/n] ", true) {
+		answers.Theme = askTheme(answers.Theme)
+		answers.Suggestions = askConfirm("Show completion suggestions as you type? [Y/n] ", true)
+		answers.SafeUpdates = askConfirm("Safe mode: refuse UPDATE and DELETE without a key in WHERE or a LIMIT? [y/N] ", false)
+		fmt.Println("AI analysis explains EXPLAIN plans; it needs an AI backend such as mcp-server (see AI_SETUP.md).")
+		if answers.AI = askConfirm("Turn on AI analysis? [y/N] ", false); answers.AI {
+			url := askDefault(fmt.Sprintf("AI server URL, or stdio to run sqlbot directly [%s] ", defaultAIServerURL), defaultAIServerURL)
+			if strings.EqualFold(url, "stdio") {
+				answers.AIMode = "mcp_stdio"
+			} else if msg := checkURL(url); msg != "" {
+				fmt.Printf("%s; keeping %s\n", msg, defaultAIServerURL)
+			} else {
+				answers.AIServerURL = url
+			}
+		}
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err == nil {
+		err = answers.configFile().SaveTo(path)
+	}
+	if err != nil {
+		fmt.Printf("Could not write %s: %v\n", path, err)
+		return
+	}
+	fmt.Printf("Saved %s; \\config edit changes it later.\n\n", path)
+}
+
+// askTheme previews setupThemes with a sample statement and asks for one
+// by number or name; any chroma style name is accepted
+func askTheme(def string) string {
+	fmt.Println()
+	for i, name := range setupThemes {
+		fmt.Printf("%2d) %-16s %s\n", i+1, name, newSyntaxHighlighter(resolveBaseStyle(name)).HighlightSQL(setupPreview))
+	}
+	for {
+		answer := askDefault(fmt.Sprintf("Theme (number or name) [%s] ", def), def)
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(setupThemes) {
+			return setupThemes[n-1]
+		}
+		if checkStyle(answer) == "" {
+			return strings.ToLower(answer)
+		}
+		fmt.Printf("Unknown theme %q\n", answer)
+	}
+}
+
+// askDefault asks a question, returning def for an empty answer
+func askDefault(question, def string) string {
+	if answer := askValue(question); answer != "" {
+		return answer
+	}
+	return def
+}
+
+// askConfirm asks a yes or no question, returning def for an empty or
+// unclear answer
+func askConfirm(question string, def bool) bool {
+	switch strings.ToLower(askValue(question)) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return def
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetupAnswersConfigFile(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), ".go-myclirc")
+	t.Setenv("GO_MYCLI_RC", rc)
+	if !needsSetup() {
+		t.Fatal("needsSetup = false without a config file")
+	}
+
+	answers := setupAnswers{Theme: "nord", AI: true, AIMode: "mcp_stdio", SafeUpdates: true}
+	if err := answers.configFile().SaveTo(rc); err != nil {
+		t.Fatal(err)
+	}
+	if needsSetup() {
+		t.Error("needsSetup = true once the config file is written")
+	}
+	data, err := os.ReadFile(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if problems := validateConfig(data); len(problems) > 0 {
+		t.Errorf("the written config has problems: %v", problems)
+	}
+
+	cfg := LoadSyntaxConfig()
+	if cfg.Style != "nord" || cfg.UseCustomColors {
+		t.Errorf("style = %s, custom colors %v; want nord without overrides", cfg.Style, cfg.UseCustomColors)
+	}
+	if cfg.EnableSuggestions || !cfg.EnableAIAnalysis || cfg.AiServerMode != "mcp_stdio" {
+		t.Errorf("suggestions %v, AI %v (%s); want off, on (mcp_stdio)", cfg.EnableSuggestions, cfg.EnableAIAnalysis, cfg.AiServerMode)
+	}
+	if cfg.Session["sql_safe_updates"] != "ON" {
+		t.Errorf("[session] = %v, want sql_safe_updates = ON", cfg.Session)
+	}
+}
+
+func TestDefaultConfigFileLeavesAIOff(t *testing.T) {
+	main := defaultConfigFile().Section("main")
+	if ai, _ := main.Key("ai_analysis").Bool(); ai {
+		t.Error("the default config turns AI analysis on")
+	}
+	if got := main.Key("ai_server_url").String(); got != defaultAIServerURL {
+		t.Errorf("ai_server_url = %s, want %s", got, defaultAIServerURL)
+	}
+}
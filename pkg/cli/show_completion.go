@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+)
+
+// Statements whose results name what SHOW ... LIKE and SHOW ENGINE accept
+const (
+	showVariablesQuery = "SHOW GLOBAL VARIABLES"
+	showStatusQuery    = "SHOW GLOBAL STATUS"
+	showEnginesQuery   = "SHOW ENGINES"
+)
+
+// showArgumentSuggestions completes what follows the first words of a SHOW
+// statement: variable and status names after LIKE, engine names after SHOW
+// ENGINE, and the rest of a multi-word item such as VARIABLES after SHOW
+// GLOBAL. items are the first-level SHOW items.
+func (p *PromptExecutor) showArgumentSuggestions(words []string, items []prompt.Suggest) []prompt.Suggest {
+	n := len(words)
+	switch {
+	case n >= 2 && words[n-1] == "LIKE" && words[n-2] == "VARIABLES":
+		return p.showNames(showVariablesQuery)
+	case n >= 2 && words[n-1] == "LIKE" && words[n-2] == "STATUS" && (n < 3 || words[n-3] != "TABLE"):
+		return p.showNames(showStatusQuery)
+	case n == 1 && words[0] == "ENGINE":
+		return p.showNames(showEnginesQuery)
+	case n == 2 && words[0] == "ENGINE":
+		return []prompt.Suggest{
+			{Text: "STATUS", Description: "Show engine status"},
+			{Text: "MUTEX", Description: "Show engine mutexes"},
+		}
+	}
+
+	prefix := strings.Join(words, " ") + " "
+	var rest []prompt.Suggest
+	for _, item := range items {
+		if after, ok := strings.CutPrefix(item.Text, prefix); ok {
+			rest = append(rest, prompt.Suggest{Text: after, Description: item.Description})
+		}
+	}
+	return rest
+}
+
+// showNames returns the names query lists as suggestions, asking the server
+// the first time and keeping them until the connection changes. Variable
+// and status names are quoted for LIKE. A failed query is not retried.
+func (p *PromptExecutor) showNames(query string) []prompt.Suggest {
+	if names, ok := p.showNameCache[query]; ok {
+		return names
+	}
+	if p.db == nil {
+		return nil
+	}
+	_, rows, _ := p.queryStrings(query)
+	var names []prompt.Suggest
+	for _, row := range rows {
+		switch {
+		case query == showEnginesQuery && len(row) >= 3:
+			// Engine, Support, Comment
+			if row[1] != "NO" && row[1] != "DISABLED" {
+				names = append(names, prompt.Suggest{Text: row[0], Description: truncateQuery(row[2], 50)})
+			}
+		case query == showVariablesQuery && len(row) >= 2:
+			names = append(names, prompt.Suggest{Text: "'" + row[0] + "'", Description: truncateQuery("= "+row[1], 40)})
+		case len(row) >= 1:
+			names = append(names, prompt.Suggest{Text: "'" + row[0] + "'", Description: "Status variable"})
+		}
+	}
+	if p.showNameCache == nil {
+		p.showNameCache = make(map[string][]prompt.Suggest)
+	}
+	p.showNameCache[query] = names
+	return names
+}
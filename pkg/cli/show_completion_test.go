@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/c-bata/go-prompt"
+)
+
+func TestParseSQLContext_ShowWords(t *testing.T) {
+	tests := []struct {
+		sql     string
+		want    []string
+		context SQLContext
+	}{
+		{"SHOW ", nil, ContextShowItem},
+		{"SHOW VARI", nil, ContextShowItem},
+		{"SHOW GLOBAL VARIABLES LIKE ", []string{"GLOBAL", "VARIABLES", "LIKE"}, ContextShowItem},
+		{"SHOW STATUS LIKE 'innodb", []string{"STATUS", "LIKE"}, ContextShowItem},
+		{"SHOW ENGINE ", []string{"ENGINE"}, ContextShowItem},
+		{"SHOW INDEX FROM ", []string{"INDEX"}, ContextTable},
+		{"SHOW CREATE TABLE ", []string{"CREATE", "TABLE"}, ContextTable},
+	}
+	for _, tt := range tests {
+		result := ParseSQLContext(tt.sql, len(tt.sql))
+		if !slices.Equal(result.ShowWords, tt.want) || result.Context != tt.context {
+			t.Errorf("%q: ShowWords %v, context %v; want %v, %v", tt.sql, result.ShowWords, result.Context, tt.want, tt.context)
+		}
+	}
+}
+
+func TestShowArgumentSuggestions(t *testing.T) {
+	p := &PromptExecutor{
+		tables:         []string{"users"},
+		columns:        map[string][]string{},
+		cacheTime:      time.Now(),
+		completionMode: completionFull,
+		showNameCache: map[string][]prompt.Suggest{
+			showVariablesQuery: {{Text: "'innodb_buffer_pool_size'", Description: "= 134217728"}, {Text: "'max_connections'", Description: "= 151"}},
+			showStatusQuery:    {{Text: "'Threads_running'", Description: "Status variable"}},
+			showEnginesQuery:   {{Text: "InnoDB", Description: "Supports transactions"}},
+		},
+	}
+	texts := func(line string) []string {
+		buf := prompt.NewBuffer()
+		buf.InsertText(line, false, true)
+		var got []string
+		for _, s := range p.Completer(*buf.Document()) {
+			got = append(got, s.Text)
+		}
+		return got
+	}
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"SHOW VARIABLES LIKE 'max", []string{"'max_connections'"}},
+		{"SHOW GLOBAL STATUS LIKE ", []string{"'Threads_running'"}},
+		{"SHOW ENGINE ", []string{"InnoDB"}},
+		{"SHOW ENGINE InnoDB ", []string{"STATUS", "MUTEX"}},
+		{"SHOW GLOBAL ", []string{"VARIABLES", "STATUS"}},
+		{"SHOW TABLE STATUS LIKE ", nil},
+	}
+	for _, tt := range tests {
+		if got := texts(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
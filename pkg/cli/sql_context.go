@@ -2,6 +2,7 @@ package cli
 
 import (
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -9,18 +10,24 @@ import (
 type SQLContext int
 
 const (
-	ContextUnknown  SQLContext = iota
-	ContextKeyword             // Start of statement or after keywords like AND, OR
-	ContextTable               // After FROM, JOIN, UPDATE, INTO, DESC, DESCRIBE
-	ContextColumn              // After SELECT, WHERE, ORDER BY, GROUP BY, HAVING, SET
-	ContextDatabase            // After USE, or schema qualification
-	ContextFunction            // Inside function call
-	ContextAlias               // After AS or table name (expecting alias)
-	ContextOperator            // After column name (expecting =, >, <, etc.)
-	ContextValue               // After operator (expecting value)
-	ContextJoinOn              // After JOIN ... ON (expecting join condition)
-	ContextShowItem            // After SHOW keyword
-	ContextTableDot            // After table. (expecting column from specific table)
+	ContextUnknown         SQLContext = iota
+	ContextKeyword                    // Start of statement or after keywords like AND, OR
+	ContextTable                      // After FROM, JOIN, UPDATE, INTO, DESC, DESCRIBE
+	ContextColumn                     // After SELECT, WHERE, ORDER BY, GROUP BY, HAVING, SET
+	ContextDatabase                   // After USE, or schema qualification
+	ContextFunction                   // Inside function call
+	ContextAlias                      // After AS or table name (expecting alias)
+	ContextOperator                   // After column name (expecting =, >, <, etc.)
+	ContextValue                      // After operator (expecting value)
+	ContextJoinOn                     // After JOIN ... ON (expecting join condition)
+	ContextShowItem                   // After SHOW keyword
+	ContextTableDot                   // After table. (expecting column from specific table)
+	ContextInsertColumn               // In the column list of INSERT INTO t (...)
+	ContextInsertValue                // In a VALUES (...) tuple of an INSERT
+	ContextTableElement               // At the start of a definition in CREATE TABLE t (...)
+	ContextColumnType                 // After a column name in CREATE TABLE (expecting a data type)
+	ContextColumnAttribute            // After a column's type (NOT NULL, DEFAULT, ...)
+	ContextTableOption                // After CREATE TABLE t (...) (ENGINE, CHARSET, ...)
 )
 
 // TableAlias maps alias names to table names
@@ -33,7 +40,9 @@ type TableAlias struct {
 type SQLParseResult struct {
 	Context        SQLContext
 	CurrentTable   string   // The table being referenced (for table.column)
-	Tables         []string // Tables mentioned in the query
+	Tables         []string // Tables of the query or subquery the cursor is in
+	OuterTables    []string // Tables of the queries enclosing the subquery
+	AllTables      []string // Every table mentioned in the statement
 	Aliases        []TableAlias
 	LastKeyword    string
 	PartialWord    string
@@ -43,9 +52,20 @@ type SQLParseResult struct {
 	HasOrderBy     bool
 	HasGroupBy     bool
 	HasHaving      bool
-	IsSubquery     bool
+	IsSubquery     bool // The cursor is inside a parenthesized SELECT
 	AfterComma     bool // Just typed a comma
 	ExpectingAlias bool
+	InsertTable    string   // Table an INSERT or REPLACE writes to
+	InsertColumns  []string // Columns listed in INSERT INTO t (...)
+	ValueIndex     int      // Position of the cursor in a VALUES tuple
+	ShowWords      []string // Words after SHOW, before the one being typed
+	TableOption    string   // CREATE TABLE option whose value follows, such as ENGINE
+	ValueTable     string   // Qualifier of the column a value is compared with (u in u.status = ')
+	ValueColumn    string   // Column a value is compared with or assigned to
+	JoinTable      string   // Table joined by the JOIN ... ON the cursor is in
+	JoinAlias      string   // Its alias, if it has one
+
+	scopes []sqlScope // parentheses open at the cursor
 }
 
 // ParseSQLContext analyzes the current SQL input and determines the context
@@ -75,8 +95,27 @@ func ParseSQLContext(input string, cursorPos int) *SQLParseResult {
 		return result
 	}
 
+	// The word being typed is completed in the context before it, so
+	// "FROM us" still expects a table
+	partial := ""
+	if !strings.ContainsAny(textToCursor[len(textToCursor)-1:], " \t\r\n(),;.=<>!") {
+		partial = tokens[len(tokens)-1]
+		tokens = tokens[:len(tokens)-1]
+	}
+
 	// Parse tokens to understand context
 	result.parseTokens(tokens)
+	if partial != "" {
+		result.PartialWord = partial
+	}
+
+	// Tables and aliases may be declared after the cursor (SELECT u.| FROM
+	// users u), so parse again on to the end of the cursor's query for them
+	if end := queryEnd(input, cursorPos, result.scopes); end > cursorPos {
+		later := &SQLParseResult{Tables: []string{}, Aliases: []TableAlias{}}
+		later.parseTokens(tokenizeSQL(input[:end]))
+		result.addLaterDeclarations(later)
+	}
 
 	// Check for table.column pattern
 	result.checkTableDotPattern(textToCursor)
@@ -91,8 +130,13 @@ func tokenizeSQL(sql string) []string {
 	inString := false
 	stringChar := rune(0)
 	inBacktick := false
+	skip := false // the second character of an operator like >=
 
 	for i, ch := range sql {
+		if skip {
+			skip = false
+			continue
+		}
 		switch {
 		case ch == '\'' || ch == '"':
 			if !inString {
@@ -133,10 +177,12 @@ func tokenizeSQL(sql string) []string {
 				next := sql[i+1]
 				if (ch == '>' || ch == '<' || ch == '!' || ch == '=') && next == '=' {
 					tokens = append(tokens, string(ch)+string(next))
+					skip = true
 					continue
 				}
 				if ch == '<' && next == '>' {
 					tokens = append(tokens, "<>")
+					skip = true
 					continue
 				}
 			}
@@ -154,6 +200,27 @@ func tokenizeSQL(sql string) []string {
 	return tokens
 }
 
+// sqlScope is the parse state outside an open parenthesis, restored when it
+// closes. A subquery also gets its own tables and clauses.
+type sqlScope struct {
+	context         SQLContext
+	subquery        bool
+	expectingTable  bool
+	expectingColumn bool
+	expectingAlias  bool
+	columnList      bool // the column list of an INSERT
+	valueTuple      bool // a tuple of an INSERT's VALUES
+	definitions     bool // the column and index definitions of a CREATE TABLE
+	valueList       bool // the values of col IN (...)
+	tables          []string
+	aliases         int // aliases defined before the subquery
+	hasFrom         bool
+	hasWhere        bool
+	hasOrderBy      bool
+	hasGroupBy      bool
+	hasHaving       bool
+}
+
 // parseTokens analyzes the token stream to determine context
 func (r *SQLParseResult) parseTokens(tokens []string) {
 	if len(tokens) == 0 {
@@ -163,9 +230,20 @@ func (r *SQLParseResult) parseTokens(tokens []string) {
 	// Track the last significant keyword and state
 	var lastToken string
 	var prevToken string
+	var scopes []sqlScope // one per open parenthesis
 	expectingTableName := false
 	expectingColumnName := false
 	afterAs := false
+	insert := strings.EqualFold(tokens[0], "INSERT") || strings.EqualFold(tokens[0], "REPLACE")
+	inValues := false
+	show := strings.EqualFold(tokens[0], "SHOW")
+	showDone := false // past the words naming what to show
+	createsTable := len(tokens) > 1 && strings.EqualFold(tokens[0], "CREATE") &&
+		(strings.EqualFold(tokens[1], "TABLE") || len(tokens) > 2 && strings.EqualFold(tokens[1], "TEMPORARY") && strings.EqualFold(tokens[2], "TABLE"))
+	createTable := ""        // the table CREATE TABLE creates
+	definitionWords := 0     // words of the current definition, -1 for an index
+	definitionsDone := false // past CREATE TABLE t (...)
+	lastTableAliases := 0    // aliases defined before the last table name
 
 	for i, token := range tokens {
 		upperToken := strings.ToUpper(token)
@@ -190,6 +268,58 @@ func (r *SQLParseResult) parseTokens(tokens []string) {
 			r.HasHaving = true
 		}
 
+		// VALUE is a common column name, so it only starts the tuples of
+		// an INSERT
+		if insert && len(scopes) == 0 && (upperToken == "VALUES" || upperToken == "VALUE") {
+			inValues = true
+			r.Context = ContextValue
+			r.ExpectingAlias = false
+			continue
+		}
+
+		// In CREATE TABLE t (...) the words of each definition say what
+		// comes next; parentheses are tracked as usual
+		if n := len(scopes); n > 0 && scopes[n-1].definitions && token != "(" && token != ")" {
+			switch {
+			case token == ",":
+				r.AfterComma = true
+				definitionWords = 0
+				r.Context = ContextTableElement
+			case definitionWords == 0 && tableElementKeywords[upperToken]:
+				// An index or constraint rather than a column
+				definitionWords = -1
+				r.Context = ContextKeyword
+			case definitionWords >= 0:
+				definitionWords++
+				r.Context = ContextColumnType
+				if definitionWords > 1 {
+					r.Context = ContextColumnAttribute
+				}
+			}
+			continue
+		}
+		if definitionsDone && len(scopes) == 0 {
+			// ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 ...
+			r.TableOption = ""
+			if token == "=" {
+				r.TableOption = strings.ToUpper(prevToken)
+			}
+			r.Context = ContextTableOption
+			continue
+		}
+		if createsTable && upperToken == "IF" && expectingTableName {
+			continue // IF NOT EXISTS
+		}
+
+		if show && i > 0 && !showDone {
+			switch upperToken {
+			case "FROM", "IN", "WHERE":
+				showDone = true
+			default:
+				r.ShowWords = append(r.ShowWords, upperToken)
+			}
+		}
+
 		// State machine for context detection
 		switch upperToken {
 		case "SELECT":
@@ -226,11 +356,13 @@ func (r *SQLParseResult) parseTokens(tokens []string) {
 			r.Context = ContextColumn
 			expectingColumnName = true
 			expectingTableName = false
+			r.ExpectingAlias = false // a clause ends the table reference
 
 		case "SET":
 			r.Context = ContextColumn
 			expectingColumnName = true
 			expectingTableName = false
+			r.ExpectingAlias = false
 
 		case "ORDER", "GROUP":
 			// Wait for BY
@@ -247,6 +379,13 @@ func (r *SQLParseResult) parseTokens(tokens []string) {
 			r.Context = ContextJoinOn
 			expectingColumnName = true
 			expectingTableName = false
+			r.ExpectingAlias = false
+			if len(r.Tables) > 0 {
+				r.JoinTable, r.JoinAlias = r.Tables[len(r.Tables)-1], ""
+				if len(r.Aliases) > lastTableAliases {
+					r.JoinAlias = r.Aliases[len(r.Aliases)-1].Alias
+				}
+			}
 
 		case "AS":
 			afterAs = true
@@ -261,7 +400,15 @@ func (r *SQLParseResult) parseTokens(tokens []string) {
 			// Comma resets to previous context (column or table list)
 			// Reset ExpectingAlias since comma ends that possibility
 			r.ExpectingAlias = false
-			if r.Context == ContextTable || r.Context == ContextAlias {
+			if n := len(scopes); n > 0 && scopes[n-1].columnList {
+				r.Context = ContextInsertColumn
+			} else if n > 0 && scopes[n-1].valueTuple {
+				// The next value of the tuple
+				r.ValueIndex++
+				r.Context = ContextInsertValue
+			} else if n > 0 && scopes[n-1].valueList {
+				r.Context = ContextValue
+			} else if r.Context == ContextTable || r.Context == ContextAlias {
 				// In table list (FROM users, orders)
 				expectingTableName = true
 				expectingColumnName = false
@@ -271,34 +418,109 @@ func (r *SQLParseResult) parseTokens(tokens []string) {
 			}
 
 		case "(":
-			r.InParentheses++
-			// Could be subquery or function call
-			if isFunction(prevToken) {
+			scope := sqlScope{
+				context:         r.Context,
+				expectingTable:  expectingTableName,
+				expectingColumn: expectingColumnName,
+				expectingAlias:  r.ExpectingAlias,
+			}
+			next := ""
+			if i+1 < len(tokens) {
+				next = strings.ToUpper(tokens[i+1])
+			}
+			switch {
+			case next == "SELECT" || next == "WITH":
+				// A subquery sees the enclosing tables but has clauses of its own
+				scope.subquery = true
+				scope.tables, scope.aliases = r.Tables, len(r.Aliases)
+				scope.hasFrom, scope.hasWhere, scope.hasOrderBy, scope.hasGroupBy, scope.hasHaving =
+					r.HasFrom, r.HasWhere, r.HasOrderBy, r.HasGroupBy, r.HasHaving
+				r.OuterTables = append(r.OuterTables, r.Tables...)
+				r.Tables = []string{}
+				r.HasFrom, r.HasWhere, r.HasOrderBy, r.HasGroupBy, r.HasHaving = false, false, false, false, false
+				r.IsSubquery = true
+			case insert && len(scopes) == 0 && !inValues && r.InsertTable != "" && unquoteIdentifier(prevToken) == r.InsertTable:
+				scope.columnList = true
+				r.Context = ContextInsertColumn
+				expectingColumnName, expectingTableName = false, false
+			case createTable != "" && len(scopes) == 0 && !definitionsDone && unquoteIdentifier(prevToken) == createTable:
+				scope.definitions = true
+				r.Context = ContextTableElement
+				definitionWords = 0
+				expectingColumnName, expectingTableName = false, false
+			case inValues && len(scopes) == 0:
+				scope.valueTuple = true
+				r.Context = ContextInsertValue
+				r.ValueIndex = 0
+				expectingColumnName, expectingTableName = false, false
+			case r.Context == ContextValue && strings.EqualFold(prevToken, "IN"):
+				scope.valueList = true
+			case isFunction(prevToken):
+				// Function arguments are mostly columns
 				r.Context = ContextFunction
+				expectingColumnName = true
+				expectingTableName = false
 			}
+			r.ExpectingAlias = false
+			scopes = append(scopes, scope)
 
 		case ")":
-			r.InParentheses--
+			if len(scopes) == 0 {
+				break
+			}
+			scope := scopes[len(scopes)-1]
+			scopes = scopes[:len(scopes)-1]
+			r.Context = scope.context
+			expectingTableName, expectingColumnName, r.ExpectingAlias = scope.expectingTable, scope.expectingColumn, scope.expectingAlias
+			if scope.definitions {
+				definitionsDone = true
+				r.Context, r.ExpectingAlias = ContextTableOption, false
+			}
+			if scope.subquery {
+				r.OuterTables = r.OuterTables[:len(r.OuterTables)-len(scope.tables)]
+				r.Tables = scope.tables
+				r.Aliases = r.Aliases[:scope.aliases]
+				r.HasFrom, r.HasWhere, r.HasOrderBy, r.HasGroupBy, r.HasHaving =
+					scope.hasFrom, scope.hasWhere, scope.hasOrderBy, scope.hasGroupBy, scope.hasHaving
+				r.IsSubquery = slices.ContainsFunc(scopes, func(s sqlScope) bool { return s.subquery })
+				if scope.context == ContextTable {
+					// A derived table, which may be followed by its alias
+					r.Context, expectingTableName = ContextAlias, false
+				}
+			}
 
 		case ".":
 			// table.column pattern - the next token should be a column
 			if !isKeyword(prevToken) && prevToken != "" && prevToken != "," {
-				r.CurrentTable = prevToken
+				r.CurrentTable = unquoteIdentifier(prevToken)
 				r.Context = ContextTableDot
 			}
 
 		case "=", ">", "<", ">=", "<=", "!=", "<>", "LIKE", "IN", "BETWEEN", "IS":
 			r.Context = ContextValue
 			r.ExpectingAlias = false
+			r.ValueTable, r.ValueColumn = "", ""
+			col := i - 1
+			if col > 0 && strings.EqualFold(tokens[col], "NOT") {
+				col-- // NOT IN, NOT LIKE
+			}
+			if col >= 0 && tokens[col] != ")" && !isKeyword(strings.ToUpper(tokens[col])) {
+				r.ValueColumn = unquoteIdentifier(tokens[col])
+				if col >= 2 && tokens[col-1] == "." {
+					r.ValueTable = unquoteIdentifier(tokens[col-2])
+				}
+			}
 
 		default:
 			// It's an identifier (table name, column name, alias, etc.)
-			if afterAs {
+			if n := len(scopes); n > 0 && scopes[n-1].columnList {
+				r.InsertColumns = append(r.InsertColumns, unquoteIdentifier(token))
+			} else if afterAs {
 				// This is an alias (explicit with AS)
 				if len(r.Tables) > 0 {
 					lastTable := r.Tables[len(r.Tables)-1]
 					r.Aliases = append(r.Aliases, TableAlias{
-						Alias:     token,
+						Alias:     unquoteIdentifier(token),
 						TableName: lastTable,
 					})
 				}
@@ -306,8 +528,16 @@ func (r *SQLParseResult) parseTokens(tokens []string) {
 				r.ExpectingAlias = false
 			} else if expectingTableName && !isKeyword(upperToken) && token != "," {
 				// This is a table name
-				tableName := strings.Trim(token, "`")
+				tableName := unquoteIdentifier(token)
 				r.Tables = append(r.Tables, tableName)
+				r.AllTables = append(r.AllTables, tableName)
+				lastTableAliases = len(r.Aliases)
+				if insert && r.InsertTable == "" {
+					r.InsertTable = tableName
+				}
+				if createsTable && createTable == "" {
+					createTable = tableName
+				}
 				// After table name, we might get an alias
 				r.ExpectingAlias = true
 				r.Context = ContextAlias
@@ -318,7 +548,7 @@ func (r *SQLParseResult) parseTokens(tokens []string) {
 				if len(r.Tables) > 0 && !isClauseKeyword(upperToken) {
 					lastTable := r.Tables[len(r.Tables)-1]
 					r.Aliases = append(r.Aliases, TableAlias{
-						Alias:     token,
+						Alias:     unquoteIdentifier(token),
 						TableName: lastTable,
 					})
 				}
@@ -326,6 +556,12 @@ func (r *SQLParseResult) parseTokens(tokens []string) {
 			}
 		}
 
+		// SHOW VARIABLES LIKE and the like complete from the SHOW items;
+		// SHOW CREATE TABLE still completes tables
+		if show && !showDone && r.Context != ContextTable {
+			r.Context = ContextShowItem
+		}
+
 		// Special handling for JOIN variations
 		if upperToken == "INNER" || upperToken == "LEFT" || upperToken == "RIGHT" ||
 			upperToken == "OUTER" || upperToken == "CROSS" || upperToken == "NATURAL" {
@@ -334,6 +570,8 @@ func (r *SQLParseResult) parseTokens(tokens []string) {
 		}
 	}
 
+	r.scopes = scopes
+
 	// Store the last keyword and partial word
 	if len(tokens) > 0 {
 		lastToken := tokens[len(tokens)-1]
@@ -351,6 +589,64 @@ func (r *SQLParseResult) parseTokens(tokens []string) {
 	}
 }
 
+// queryEnd returns where the query around the cursor ends: the parenthesis
+// closing the innermost subquery the cursor is in, or the end of the
+// statement. scopes are the parentheses open at the cursor.
+func queryEnd(input string, cursorPos int, scopes []sqlScope) int {
+	closes := -1 // closing parentheses up to the end of the subquery
+	for i := len(scopes) - 1; i >= 0; i-- {
+		if scopes[i].subquery {
+			closes = len(scopes) - i
+			break
+		}
+	}
+	var st sqlState
+	depth := 0
+	for i := cursorPos; i < len(input); {
+		c := input[i]
+		inText := st.quote != 0 || st.blockComment
+		next, lineComment := st.advance(input, i)
+		if !inText && !lineComment && st.quote == 0 && !st.blockComment {
+			switch c {
+			case '(':
+				depth++
+			case ')':
+				if depth > 0 {
+					depth--
+					break
+				}
+				if closes--; closes == 0 {
+					return i
+				}
+			case ';':
+				return i
+			}
+		}
+		i = next
+	}
+	return len(input)
+}
+
+// addLaterDeclarations adds the tables and aliases that a parse on past the
+// cursor found to the ones declared before it
+func (r *SQLParseResult) addLaterDeclarations(later *SQLParseResult) {
+	for _, table := range later.Tables {
+		if !slices.Contains(r.Tables, table) {
+			r.Tables = append(r.Tables, table)
+		}
+	}
+	for _, alias := range later.Aliases {
+		if !slices.ContainsFunc(r.Aliases, func(a TableAlias) bool { return strings.EqualFold(a.Alias, alias.Alias) }) {
+			r.Aliases = append(r.Aliases, alias)
+		}
+	}
+	for _, col := range later.InsertColumns {
+		if !slices.ContainsFunc(r.InsertColumns, func(c string) bool { return strings.EqualFold(c, col) }) {
+			r.InsertColumns = append(r.InsertColumns, col)
+		}
+	}
+}
+
 // checkTableDotPattern detects if the cursor is right after "table."
 func (r *SQLParseResult) checkTableDotPattern(text string) {
 	// Pattern: identifier followed by dot at the end
@@ -414,6 +710,13 @@ func isKeyword(token string) bool {
 	return keywords[token]
 }
 
+// tableElementKeywords start an index or constraint definition in CREATE
+// TABLE instead of a column
+var tableElementKeywords = map[string]bool{
+	"PRIMARY": true, "KEY": true, "INDEX": true, "UNIQUE": true, "CONSTRAINT": true,
+	"FOREIGN": true, "FULLTEXT": true, "SPATIAL": true, "CHECK": true,
+}
+
 // isClauseKeyword checks if a token starts a new clause
 func isClauseKeyword(token string) bool {
 	clauses := map[string]bool{
@@ -539,6 +842,18 @@ func (r *SQLParseResult) GetContextDescription() string {
 		return "SHOW option expected"
 	case ContextTableDot:
 		return "Column from " + r.CurrentTable + " expected"
+	case ContextInsertColumn:
+		return "Column of " + r.InsertTable + " expected"
+	case ContextInsertValue:
+		return "Value expected"
+	case ContextTableElement:
+		return "Column or index definition expected"
+	case ContextColumnType:
+		return "Data type expected"
+	case ContextColumnAttribute:
+		return "Column attribute expected"
+	case ContextTableOption:
+		return "Table option expected"
 	default:
 		return "Unknown context"
 	}
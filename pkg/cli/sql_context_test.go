@@ -1,7 +1,12 @@
 package cli
 
 import (
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/c-bata/go-prompt"
 )
 
 func TestParseSQLContext_Empty(t *testing.T) {
@@ -171,6 +176,322 @@ func TestParseSQLContext_Describe(t *testing.T) {
 	}
 }
 
+func TestParseSQLContext_SubquerySelect(t *testing.T) {
+	sql := "SELECT * FROM users u WHERE id IN (SELECT "
+	result := ParseSQLContext(sql, len(sql))
+	if result.Context != ContextColumn || !result.IsSubquery {
+		t.Errorf("Expected ContextColumn in a subquery, got %v (subquery %v)", result.Context, result.IsSubquery)
+	}
+	if len(result.Tables) != 0 || result.HasFrom || result.HasWhere {
+		t.Errorf("Expected the subquery to start with no tables or clauses, got %v", result.Tables)
+	}
+	if len(result.OuterTables) != 1 || result.OuterTables[0] != "users" {
+		t.Errorf("Expected outer table users, got %v", result.OuterTables)
+	}
+}
+
+func TestParseSQLContext_SubqueryWhere(t *testing.T) {
+	sql := "SELECT * FROM users u WHERE id IN (SELECT user_id FROM orders o WHERE "
+	result := ParseSQLContext(sql, len(sql))
+	if result.Context != ContextColumn {
+		t.Errorf("Expected ContextColumn, got %v", result.Context)
+	}
+	if len(result.Tables) != 1 || result.Tables[0] != "orders" {
+		t.Errorf("Expected subquery table orders, got %v", result.Tables)
+	}
+	if result.ResolveAlias("u") != "users" || result.ResolveAlias("o") != "orders" {
+		t.Errorf("Expected outer and inner aliases to resolve, got %v", result.Aliases)
+	}
+}
+
+func TestParseSQLContext_AfterSubquery(t *testing.T) {
+	sql := "SELECT * FROM users WHERE id IN (SELECT user_id FROM orders) AND "
+	result := ParseSQLContext(sql, len(sql))
+	if result.Context != ContextColumn || result.IsSubquery {
+		t.Errorf("Expected ContextColumn outside the subquery, got %v (subquery %v)", result.Context, result.IsSubquery)
+	}
+	if len(result.Tables) != 1 || result.Tables[0] != "users" || len(result.OuterTables) != 0 {
+		t.Errorf("Expected tables [users] after the subquery, got %v, outer %v", result.Tables, result.OuterTables)
+	}
+	if len(result.AllTables) != 2 {
+		t.Errorf("Expected both tables in AllTables, got %v", result.AllTables)
+	}
+	if !result.HasWhere {
+		t.Error("Expected the outer WHERE to be restored")
+	}
+}
+
+func TestParseSQLContext_FunctionArgument(t *testing.T) {
+	sql := "SELECT COUNT(DISTINCT "
+	result := ParseSQLContext(sql, len(sql))
+	if result.Context != ContextFunction {
+		t.Errorf("Expected ContextFunction inside COUNT(, got %v", result.Context)
+	}
+
+	sql = "SELECT DATE(created_at), "
+	result = ParseSQLContext(sql, len(sql))
+	if result.Context != ContextColumn {
+		t.Errorf("Expected ContextColumn after the function call, got %v", result.Context)
+	}
+	if result.InParentheses != 0 {
+		t.Errorf("Expected no open parentheses, got %d", result.InParentheses)
+	}
+}
+
+func TestParseSQLContext_DerivedTable(t *testing.T) {
+	sql := "SELECT * FROM (SELECT id FROM orders) AS recent WHERE "
+	result := ParseSQLContext(sql, len(sql))
+	if result.Context != ContextColumn || result.IsSubquery {
+		t.Errorf("Expected ContextColumn after a derived table, got %v", result.Context)
+	}
+	if !result.HasFrom {
+		t.Error("Expected HasFrom after a derived table")
+	}
+}
+
+func TestSubquerySuggestions(t *testing.T) {
+	p := &PromptExecutor{
+		tables:  []string{"users", "orders"},
+		columns: map[string][]string{"users": {"id", "name"}, "orders": {"id", "user_id"}},
+	}
+	sql := "SELECT * FROM users u WHERE id IN (SELECT user_id FROM orders WHERE "
+	texts := map[string]string{}
+	for _, s := range p.buildContextAwareSuggestions(ParseSQLContext(sql, len(sql))) {
+		texts[s.Text] = s.Description
+	}
+	if texts["user_id"] != "Column from orders" {
+		t.Errorf("Expected user_id from orders, got %v", texts)
+	}
+	if _, ok := texts["name"]; ok {
+		t.Errorf("Outer columns should be qualified, got %v", texts)
+	}
+	if texts["u."] != "Columns from outer users" {
+		t.Errorf("Expected the outer alias u., got %v", texts)
+	}
+
+	sql = "SELECT MAX("
+	if got := p.buildContextAwareSuggestions(ParseSQLContext(sql, len(sql))); len(got) == 0 {
+		t.Error("Expected suggestions for a function argument")
+	}
+}
+
+func TestParseSQLContext_AliasDeclaredLater(t *testing.T) {
+	sql := "SELECT u. FROM users u JOIN orders o ON o.user_id = u.id"
+	result := ParseSQLContext(sql, len("SELECT u."))
+	if result.Context != ContextTableDot || result.CurrentTable != "u" {
+		t.Errorf("Expected ContextTableDot for u, got %v (%s)", result.Context, result.CurrentTable)
+	}
+	if result.ResolveAlias("u") != "users" || result.ResolveAlias("o") != "orders" {
+		t.Errorf("Expected aliases declared after the cursor to resolve, got %v", result.Aliases)
+	}
+	if len(result.Tables) != 2 {
+		t.Errorf("Expected the tables after the cursor, got %v", result.Tables)
+	}
+}
+
+func TestParseSQLContext_LaterDeclarationsStayInScope(t *testing.T) {
+	sql := "SELECT * FROM users u WHERE id IN (SELECT o. FROM orders o) AND name IN (SELECT name FROM admins a)"
+	result := ParseSQLContext(sql, len("SELECT * FROM users u WHERE id IN (SELECT o."))
+	if result.ResolveAlias("o") != "orders" {
+		t.Errorf("Expected o to resolve to orders, got %v", result.Aliases)
+	}
+	if result.ResolveAlias("a") != "a" {
+		t.Errorf("Expected the alias of a later subquery not to resolve, got %v", result.Aliases)
+	}
+	if len(result.Tables) != 1 || result.Tables[0] != "orders" {
+		t.Errorf("Expected only the subquery's table, got %v", result.Tables)
+	}
+
+	// A statement after the terminator is not part of the query
+	sql = "SELECT x. ; SELECT * FROM t x"
+	if result := ParseSQLContext(sql, len("SELECT x.")); result.ResolveAlias("x") != "x" {
+		t.Errorf("Expected no alias from the next statement, got %v", result.Aliases)
+	}
+}
+
+func TestParseSQLContext_PartialWord(t *testing.T) {
+	// The word being typed is completed in the context before it
+	sql := "SELECT * FROM us"
+	result := ParseSQLContext(sql, len(sql))
+	if result.Context != ContextTable || result.PartialWord != "us" {
+		t.Errorf("Expected ContextTable for us, got %v (%q)", result.Context, result.PartialWord)
+	}
+
+	sql = "SELECT * FROM users WHERE na"
+	result = ParseSQLContext(sql, len(sql))
+	if result.Context != ContextColumn || result.ResolveAlias("na") != "na" {
+		t.Errorf("Expected ContextColumn and no alias na, got %v, %v", result.Context, result.Aliases)
+	}
+}
+
+func TestQuotedIdentifierCompletion(t *testing.T) {
+	p := &PromptExecutor{
+		tables:         []string{"order items", "key", "Users"},
+		columns:        map[string][]string{"order items": {"id", "unit price"}, "key": {"value"}, "Users": {"id"}},
+		cacheTime:      time.Now(),
+		completionMode: completionFull,
+	}
+	// texts completes line with the cursor after its first "." if it has one
+	texts := func(line string) []string {
+		buf := prompt.NewBuffer()
+		buf.InsertText(line, false, true)
+		if i := strings.Index(line, ". "); i >= 0 {
+			buf.CursorLeft(len(line) - i - 1)
+		}
+		var got []string
+		for _, s := range p.Completer(*buf.Document()) {
+			got = append(got, s.Text)
+		}
+		return got
+	}
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"SELECT * FROM `ord", []string{"`order items`"}},
+		{"SELECT * FROM `order it", []string{"items`"}},
+		{"SELECT * FROM ke", []string{"`key`"}},
+		{"SELECT `o`. FROM `order items` AS `o`", []string{"id", "`unit price`"}},
+		{"SELECT k. FROM `key` k", []string{"value"}},
+	}
+	for _, tt := range tests {
+		if got := texts(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.line, got, tt.want)
+		}
+	}
+
+	// lower_case_table_names=0 makes table names case-sensitive
+	p.server = &serverInfo{CaseSensitiveTables: true}
+	if got := texts("SELECT u. FROM users u"); len(got) != 0 {
+		t.Errorf("users should not match Users, got %v", got)
+	}
+	p.server = &serverInfo{}
+	if got := texts("SELECT u. FROM users u"); !reflect.DeepEqual(got, []string{"id"}) {
+		t.Errorf("users should match Users, got %v", got)
+	}
+}
+
+func TestParseSQLContext_InsertColumnList(t *testing.T) {
+	sql := "INSERT INTO users (id, `name`, "
+	result := ParseSQLContext(sql, len(sql))
+	if result.Context != ContextInsertColumn || result.InsertTable != "users" {
+		t.Errorf("Expected ContextInsertColumn for users, got %v (%s)", result.Context, result.InsertTable)
+	}
+	if len(result.InsertColumns) != 2 || result.InsertColumns[1] != "name" {
+		t.Errorf("Expected the listed columns, got %v", result.InsertColumns)
+	}
+	if len(result.Aliases) != 0 {
+		t.Errorf("Expected no aliases from the column list, got %v", result.Aliases)
+	}
+
+	// Columns listed after the cursor count too
+	sql = "INSERT INTO users (id, , email) VALUES (1, 'a', 'b')"
+	result = ParseSQLContext(sql, len("INSERT INTO users (id, "))
+	if len(result.InsertColumns) != 2 || result.InsertColumns[1] != "email" {
+		t.Errorf("Expected id and email, got %v", result.InsertColumns)
+	}
+}
+
+func TestParseSQLContext_InsertValues(t *testing.T) {
+	tests := []struct {
+		sql       string
+		wantIndex int
+	}{
+		{"INSERT INTO users (id, name) VALUES (", 0},
+		{"INSERT INTO users (id, name) VALUES (1, ", 1},
+		{"INSERT INTO users VALUES (1, NOW(), ", 2},
+		{"REPLACE INTO users VALUES (1, 'a, b'), (2, ", 1},
+		{"INSERT INTO users VALUE (", 0},
+	}
+	for _, tt := range tests {
+		result := ParseSQLContext(tt.sql, len(tt.sql))
+		if result.Context != ContextInsertValue || result.ValueIndex != tt.wantIndex {
+			t.Errorf("%q: got %v at %d, want ContextInsertValue at %d", tt.sql, result.Context, result.ValueIndex, tt.wantIndex)
+		}
+		if result.InsertTable != "users" || len(result.Aliases) != 0 {
+			t.Errorf("%q: table %q, aliases %v", tt.sql, result.InsertTable, result.Aliases)
+		}
+	}
+
+	// VALUE is only a keyword in an INSERT
+	sql := "SELECT value FROM settings WHERE "
+	if result := ParseSQLContext(sql, len(sql)); result.Context != ContextColumn {
+		t.Errorf("Expected ContextColumn, got %v", result.Context)
+	}
+}
+
+func TestInsertSuggestions(t *testing.T) {
+	p := &PromptExecutor{
+		tables:  []string{"users"},
+		columns: map[string][]string{"users": {"id", "name", "status", "created_at", "name_length"}},
+		columnDetails: map[string]map[string]columnDetail{"users": {
+			"id":          {Type: "int", Extra: "auto_increment"},
+			"name":        {Type: "varchar(64)", Nullable: true},
+			"status":      {Type: "enum('new','it''s done')"},
+			"created_at":  {Type: "datetime", Extra: "DEFAULT_GENERATED"},
+			"name_length": {Type: "int", Extra: "VIRTUAL GENERATED"},
+		}},
+		cacheTime:      time.Now(),
+		completionMode: completionFull,
+	}
+	complete := func(line string) []prompt.Suggest {
+		buf := prompt.NewBuffer()
+		buf.InsertText(line, false, true)
+		return p.Completer(*buf.Document())
+	}
+
+	got := complete("INSERT INTO users (id, ")
+	want := []prompt.Suggest{
+		{Text: "name", Description: "varchar(64)"},
+		{Text: "status", Description: "enum('new','it''s done')"},
+		{Text: "created_at", Description: "datetime DEFAULT_GENERATED"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("column list suggestions = %v, want %v", got, want)
+	}
+
+	got = complete("INSERT INTO users (status, name) VALUES (")
+	hint := "status enum('new','it''s done') (1/2)"
+	want = []prompt.Suggest{{Text: "'new'", Description: hint}, {Text: "'it''s done'", Description: hint}, {Text: "DEFAULT", Description: hint}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("first value suggestions = %v, want %v", got, want)
+	}
+
+	got = complete("INSERT INTO users VALUES (1, 'a', 'new', ")
+	if len(got) == 0 || got[0].Text != "NOW()" || got[0].Description != "created_at datetime (4/5)" {
+		t.Errorf("datetime value suggestions = %v", got)
+	}
+
+	got = complete("INSERT INTO users VALUES (1, 'a', 'new', NOW(), ")
+	want = []prompt.Suggest{{Text: "DEFAULT", Description: "name_length int (5/5)"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("generated column value suggestions = %v, want %v", got, want)
+	}
+
+	if got := complete("INSERT INTO users (id) VALUES (1, "); len(got) != 0 {
+		t.Errorf("Expected nothing past the last column, got %v", got)
+	}
+}
+
+func TestCompletionWordAfterSeparator(t *testing.T) {
+	p := &PromptExecutor{
+		tables:         []string{"users"},
+		columns:        map[string][]string{"users": {"id", "name"}},
+		cacheTime:      time.Now(),
+		completionMode: completionFull,
+	}
+	// The word being completed starts after "(", "," or "table."
+	for _, line := range []string{"SELECT u.na", "SELECT id,na", "SELECT MAX(na"} {
+		buf := prompt.NewBuffer()
+		buf.InsertText(line+" FROM users u", false, true)
+		buf.CursorLeft(len(" FROM users u"))
+		got := p.Completer(*buf.Document())
+		if len(got) == 0 || got[0].Text != "name" {
+			t.Errorf("%q: got %v, want name first", line, got)
+		}
+	}
+}
+
 func TestTokenizeSQL_Basic(t *testing.T) {
 	tokens := tokenizeSQL("SELECT * FROM users")
 	expected := []string{"SELECT", "*", "FROM", "users"}
@@ -192,6 +513,14 @@ func TestTokenizeSQL_WithOperators(t *testing.T) {
 	}
 }
 
+func TestTokenizeSQL_WithTwoCharOperators(t *testing.T) {
+	tokens := tokenizeSQL("a >= 1 AND b != 2 AND c <> 3")
+	expected := []string{"a", ">=", "1", "AND", "b", "!=", "2", "AND", "c", "<>", "3"}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("Expected %v, got %v", expected, tokens)
+	}
+}
+
 func TestTokenizeSQL_WithDot(t *testing.T) {
 	tokens := tokenizeSQL("users.id")
 	expected := []string{"users", ".", "id"}
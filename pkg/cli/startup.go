@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// startupFilePath is the script run at the start of every interactive
+// session: $GO_MYCLI_RC_SQL or rc.sql in the data directory (~/.go-mycli)
+func startupFilePath() string {
+	if path := os.Getenv("GO_MYCLI_RC_SQL"); path != "" {
+		return path
+	}
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "rc.sql")
+}
+
+// runStartupFile runs the startup script, if there is one. It may hold SQL
+// and backslash commands; a failing statement is reported and the rest of
+// the script still runs.
+func (p *PromptExecutor) runStartupFile(path string) {
+	if path == "" {
+		return
+	}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		fmt.Printf("Warning: cannot read startup file %s: %v\n", path, err)
+		return
+	}
+
+	fmt.Printf("Running startup file %s\n", path)
+	failed := p.statementErrors
+	p.runScript(string(content))
+	if failed = p.statementErrors - failed; failed > 0 {
+		fmt.Printf("Warning: %d statement%s in %s failed; the session continues\n", failed, plural(failed), path)
+	}
+}
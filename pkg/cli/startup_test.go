@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStartupFilePath(t *testing.T) {
+	t.Setenv("HOME", "/home/test")
+	t.Setenv("GO_MYCLI_RC_SQL", "")
+	if got, want := startupFilePath(), filepath.Join("/home/test", ".go-mycli", "rc.sql"); got != want {
+		t.Errorf("startupFilePath() = %q, want %q", got, want)
+	}
+	t.Setenv("GO_MYCLI_RC_SQL", "/etc/go-mycli/rc.sql")
+	if got := startupFilePath(); got != "/etc/go-mycli/rc.sql" {
+		t.Errorf("startupFilePath() = %q, want $GO_MYCLI_RC_SQL", got)
+	}
+}
+
+func TestRunStartupFile(t *testing.T) {
+	p := &PromptExecutor{}
+	p.runStartupFile(filepath.Join(t.TempDir(), "missing.sql"))
+
+	rc := filepath.Join(t.TempDir(), "rc.sql")
+	if err := os.WriteFile(rc, []byte("-- toggles for every session\n\\json on\n\\visual on\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p.runStartupFile(rc)
+	if !p.enableJSONExport || !p.enableVisualExplain {
+		t.Errorf("startup commands not run: json=%v visual=%v", p.enableJSONExport, p.enableVisualExplain)
+	}
+	if p.sourceFileMode {
+		t.Error("sourceFileMode left on after the startup file")
+	}
+}
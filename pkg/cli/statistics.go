@@ -0,0 +1,292 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// estimateDivergence is how many times the rows read may differ from the
+// optimizer's estimate before the statistics are suspect
+const estimateDivergence = 10
+
+// minDivergentRows keeps estimates of a handful of rows from being reported
+const minDivergentRows = 100
+
+// histogramBuckets is the number of buckets of a suggested histogram
+const histogramBuckets = 100
+
+var (
+	treeEstimate = regexp.MustCompile(`\(cost=\S+ rows=([0-9.e+]+)\)\s*\(actual time=\S+ rows=([0-9.e+]+) loops=`)
+	treeAccess   = regexp.MustCompile(`(?:scan|lookup) on (\S+)`)
+)
+
+// rowEstimate is the optimizer's estimate of the rows a table access reads
+// and the rows EXPLAIN ANALYZE saw it read
+type rowEstimate struct {
+	Table     string
+	Estimated float64
+	Actual    float64
+}
+
+// divergent reports whether the estimate is off by estimateDivergence times
+// or more
+func (e rowEstimate) divergent() bool {
+	hi, lo := max(e.Estimated, e.Actual), min(e.Estimated, e.Actual)
+	return hi >= minDivergentRows && hi >= lo*estimateDivergence
+}
+
+// StatisticsSuggestion is an ANALYZE TABLE that would give the optimizer
+// better statistics for the explained query
+type StatisticsSuggestion struct {
+	Table     string `json:"table"`
+	Reason    string `json:"reason"`
+	Statement string `json:"statement"`
+}
+
+// planEstimates returns the estimated and actual rows of the tables of an
+// analyzed JSON plan: MariaDB's ANALYZE FORMAT=JSON (rows and r_rows) or
+// MySQL's EXPLAIN ANALYZE FORMAT=JSON (estimated_rows and actual_rows)
+func planEstimates(planJSON string) []rowEstimate {
+	var plan interface{}
+	if json.Unmarshal([]byte(planJSON), &plan) != nil {
+		return nil
+	}
+	var estimates []rowEstimate
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			if name, ok := n["table_name"].(string); ok {
+				if actual, ok := n["r_rows"]; ok {
+					estimates = append(estimates, rowEstimate{Table: name, Estimated: planNumber(n["rows"]), Actual: planNumber(actual)})
+				} else if actual, ok := n["actual_rows"]; ok {
+					estimates = append(estimates, rowEstimate{Table: name, Estimated: planNumber(n["estimated_rows"]), Actual: planNumber(actual)})
+				}
+			}
+			keys := make([]string, 0, len(n))
+			for k := range n {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(n[k])
+			}
+		case []interface{}:
+			for _, child := range n {
+				walk(child)
+			}
+		}
+	}
+	walk(plan)
+	return estimates
+}
+
+// treeEstimates returns the estimated and actual rows of the table accesses
+// of an EXPLAIN ANALYZE tree. A Filter counts for the table it filters,
+// which is on the line below it.
+func treeEstimates(tree string) []rowEstimate {
+	var estimates, filters []rowEstimate
+	for _, line := range strings.Split(tree, "\n") {
+		trimmed := strings.TrimPrefix(strings.TrimSpace(line), "-> ")
+		access := treeAccess.FindStringSubmatch(trimmed)
+		if m := treeEstimate.FindStringSubmatch(trimmed); m != nil {
+			e := rowEstimate{Estimated: planNumber(m[1]), Actual: planNumber(m[2])}
+			switch {
+			case access != nil:
+				e.Table = access[1]
+				estimates = append(estimates, e)
+			case strings.HasPrefix(trimmed, "Filter:"):
+				filters = append(filters, e)
+			}
+		}
+		if access != nil && len(filters) > 0 {
+			for _, f := range filters {
+				f.Table = access[1]
+				estimates = append(estimates, f)
+			}
+			filters = nil
+		}
+	}
+	// <temporary> and the like are not tables with statistics
+	named := estimates[:0]
+	for _, e := range estimates {
+		if !strings.HasPrefix(e.Table, "<") {
+			named = append(named, e)
+		}
+	}
+	return named
+}
+
+// estimateFindings reports the estimates that are far off what was read
+func estimateFindings(estimates []rowEstimate) []ExplainFinding {
+	var findings []ExplainFinding
+	for _, e := range estimates {
+		if e.divergent() {
+			findings = append(findings, ExplainFinding{Severity: SeverityWarning, Table: e.Table,
+				Message: fmt.Sprintf("estimated %.0f rows but read %.0f; the statistics may be stale", e.Estimated, e.Actual)})
+		}
+	}
+	return findings
+}
+
+// planTree returns the plan of an EXPLAIN ANALYZE or FORMAT=TREE result,
+// which is one row with the tree in its single cell
+func planTree(columns []string, rows [][]string) string {
+	if len(columns) != 1 || len(rows) != 1 || len(rows[0]) != 1 {
+		return ""
+	}
+	tree := strings.TrimSpace(rows[0][0])
+	if !strings.HasPrefix(tree, "-> ") {
+		return ""
+	}
+	return tree
+}
+
+// filteredTable is a table of a query with the columns its conditions compare
+type filteredTable struct {
+	name     string // as the plan names it
+	table    string // as the query names it
+	columns  []string
+	diverged bool
+}
+
+// statisticsAdvice looks up the statistics of the tables the plan filters
+// or misestimates. Filtered columns that lead no index and have no histogram
+// leave the optimizer guessing, and a misestimated table needs its
+// statistics refreshed, along with any histograms on its filtered columns.
+// tree is the EXPLAIN ANALYZE tree of the query, if there is one.
+func (p *PromptExecutor) statisticsAdvice(query, planJSON, tree string) ([]ExplainFinding, []StatisticsSuggestion) {
+	ctx := ParseSQLContext(query, len(query))
+	var tables []*filteredTable
+	byName := make(map[string]*filteredTable)
+	table := func(name string) *filteredTable {
+		if t := byName[name]; t != nil {
+			return t
+		}
+		t := &filteredTable{name: name, table: ctx.ResolveAlias(name)}
+		byName[name] = t
+		tables = append(tables, t)
+		return t
+	}
+	for _, scan := range planConditions(planJSON) {
+		t := table(scan.table)
+		for _, col := range conditionColumns(scan.table, scan.condition) {
+			if !containsFold(t.columns, col) {
+				t.columns = append(t.columns, col)
+			}
+		}
+	}
+	for _, e := range append(planEstimates(planJSON), treeEstimates(tree)...) {
+		if e.divergent() {
+			table(e.Table).diverged = true
+		}
+	}
+
+	var findings []ExplainFinding
+	var suggestions []StatisticsSuggestion
+	for _, t := range tables {
+		db, name := splitTableName(t.table, p.database)
+		if db == "" {
+			continue
+		}
+		qualified := quoteIdentifier(name)
+		if db != p.database {
+			qualified = quoteIdentifier(db) + "." + qualified
+		}
+		if t.diverged {
+			suggestions = append(suggestions, StatisticsSuggestion{Table: t.table, Reason: fmt.Sprintf("row estimates are off by %d times or more", estimateDivergence),
+				Statement: "ANALYZE TABLE " + qualified})
+		}
+		if len(t.columns) == 0 {
+			continue
+		}
+
+		// Servers without COLUMN_STATISTICS (before MySQL 8.0, MariaDB)
+		// have no histograms to suggest
+		_, histogramRows, err := p.queryStrings(`SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMN_STATISTICS
+			WHERE SCHEMA_NAME = ? AND TABLE_NAME = ?`, db, name)
+		if err != nil {
+			continue
+		}
+		_, indexRows, _ := p.queryStrings(`SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.STATISTICS
+			WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND SEQ_IN_INDEX = 1`, db, name)
+		histograms, indexed := firstColumn(histogramRows), firstColumn(indexRows)
+		var missing, stale []string
+		for _, col := range t.columns {
+			switch {
+			case containsFold(indexed, col):
+			case !containsFold(histograms, col):
+				missing = append(missing, col)
+			case t.diverged:
+				stale = append(stale, col)
+			}
+		}
+		if len(missing) > 0 {
+			findings = append(findings, ExplainFinding{Severity: SeverityInfo, Table: t.name,
+				Message: fmt.Sprintf("no index or histogram on %s; the optimizer guesses how many rows the condition keeps", strings.Join(missing, ", "))})
+		}
+		cols := append(missing, stale...)
+		if len(cols) == 0 {
+			continue
+		}
+		quoted := make([]string, len(cols))
+		for i, col := range cols {
+			quoted[i] = quoteIdentifier(col)
+		}
+		reason := "no histogram on " + strings.Join(missing, ", ")
+		if len(missing) == 0 {
+			reason = "the histograms on " + strings.Join(stale, ", ") + " may be stale"
+		}
+		suggestions = append(suggestions, StatisticsSuggestion{Table: t.table, Reason: reason,
+			Statement: fmt.Sprintf("ANALYZE TABLE %s UPDATE HISTOGRAM ON %s WITH %d BUCKETS", qualified, strings.Join(quoted, ", "), histogramBuckets)})
+	}
+	return findings, suggestions
+}
+
+// firstColumn returns the first value of each row
+func firstColumn(rows [][]string) []string {
+	values := make([]string, 0, len(rows))
+	for _, row := range rows {
+		values = append(values, row[0])
+	}
+	return values
+}
+
+// offerStatistics shows the statistics refreshes the plan calls for and, in
+// an interactive session, asks to run each one. Once one has run the query
+// is EXPLAINed again and the plan before and after compared; the plan as it
+// now stands is returned.
+func (p *PromptExecutor) offerStatistics(explainStmt, planJSON string, suggestions []StatisticsSuggestion) string {
+	ask := p.canPrompt()
+	ran := false
+	for _, s := range suggestions {
+		fmt.Printf("\nStatistics refresh suggested for %s (%s):\n  %s;\n", s.Table, s.Reason, s.Statement)
+		if !ask || !askYesNo("Run it? [y/N] ") {
+			continue
+		}
+		columns, rows, err := p.queryStrings(s.Statement)
+		if err != nil {
+			fmt.Printf(tr("Error: %v\n"), err)
+			continue
+		}
+		fmt.Print(formatMySQLTable(columns, rows))
+		ran = true
+	}
+	if !ran {
+		return planJSON
+	}
+	query, _, err := extractQueryFromExplain(explainStmt)
+	if err != nil {
+		return planJSON
+	}
+	after, err := p.queryJSONPlan(query)
+	if err != nil {
+		fmt.Printf("EXPLAIN after the refresh failed: %v\n", err)
+		return planJSON
+	}
+	fmt.Print(formatPlanDelta(planJSON, after))
+	return after
+}
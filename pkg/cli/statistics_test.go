@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"testing"
+)
+
+const analyzedTree = `-> Nested loop inner join  (cost=460 rows=98) (actual time=0.2..4.1 rows=5120 loops=1)
+    -> Filter: (f.rating = 'PG')  (cost=103 rows=100) (actual time=0.1..1.2 rows=2000 loops=1)
+        -> Table scan on f  (cost=103 rows=1000) (actual time=0.1..1 rows=1000 loops=1)
+    -> Index lookup on i using idx_fk_film_id (film_id=f.film_id)  (cost=2.5 rows=4.8) (actual time=0.01..0.02 rows=2.56 loops=2000)
+    -> Table scan on <temporary>  (cost=2.5 rows=1) (actual time=0.01..0.02 rows=500 loops=1)`
+
+func TestTreeEstimates(t *testing.T) {
+	want := []rowEstimate{
+		{Table: "f", Estimated: 1000, Actual: 1000},
+		{Table: "f", Estimated: 100, Actual: 2000},
+		{Table: "i", Estimated: 4.8, Actual: 2.56},
+	}
+	got := treeEstimates(analyzedTree)
+	if len(got) != len(want) {
+		t.Fatalf("got %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("estimate %d: got %+v, expected %+v", i, got[i], want[i])
+		}
+	}
+
+	findings := estimateFindings(got)
+	if len(findings) != 1 || findings[0] != (ExplainFinding{Severity: SeverityWarning, Table: "f", Message: "estimated 100 rows but read 2000; the statistics may be stale"}) {
+		t.Errorf("got %v", findings)
+	}
+}
+
+func TestRowEstimateDivergent(t *testing.T) {
+	tests := []struct {
+		estimate rowEstimate
+		want     bool
+	}{
+		{rowEstimate{Estimated: 100, Actual: 2000}, true},
+		{rowEstimate{Estimated: 5000, Actual: 10}, true},
+		{rowEstimate{Estimated: 1000, Actual: 200}, false},
+		{rowEstimate{Estimated: 1, Actual: 40}, false}, // too few rows to matter
+		{rowEstimate{Estimated: 0, Actual: 150}, true},
+	}
+	for _, test := range tests {
+		if got := test.estimate.divergent(); got != test.want {
+			t.Errorf("%+v: got %v", test.estimate, got)
+		}
+	}
+}
+
+func TestAnalyzedJSONPlanEstimates(t *testing.T) {
+	// MariaDB's ANALYZE FORMAT=JSON
+	findings, err := analyzePlanHeuristics(`{"query_block": {"table": {"table_name": "film", "access_type": "ref", "key": "idx_rating", "rows": 12, "r_rows": 1940}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Message != "estimated 12 rows but read 1940; the statistics may be stale" {
+		t.Errorf("got %v", findings)
+	}
+
+	// MySQL's EXPLAIN ANALYZE FORMAT=JSON
+	estimates := planEstimates(`{"query": "...", "inputs": [{"table_name": "film", "access_type": "table", "estimated_rows": 1000, "actual_rows": 1000}]}`)
+	if len(estimates) != 1 || estimates[0].divergent() {
+		t.Errorf("got %v", estimates)
+	}
+}
+
+func TestPlanTree(t *testing.T) {
+	if got := planTree([]string{"EXPLAIN"}, [][]string{{analyzedTree}}); got != analyzedTree {
+		t.Errorf("got %q", got)
+	}
+	if got := planTree([]string{"EXPLAIN"}, [][]string{{`{"query_block": {}}`}}); got != "" {
+		t.Errorf("a JSON plan is not a tree, got %q", got)
+	}
+	if got := planTree([]string{"id", "table"}, [][]string{{"1", "film"}}); got != "" {
+		t.Errorf("a traditional plan is not a tree, got %q", got)
+	}
+}
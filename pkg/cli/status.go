@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// globalStatus fetches the named GLOBAL STATUS counters in one round trip.
+// Counters the server does not have are missing from the map.
+func globalStatus(db *sql.DB, names ...string) (map[string]string, error) {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "'" + name + "'"
+	}
+	rows, err := db.Query("SHOW GLOBAL STATUS WHERE Variable_name IN (" + strings.Join(quoted, ", ") + ")")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	status := make(map[string]string, len(names))
+	for rows.Next() {
+		var name, value sql.NullString
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+		// Match the requested spelling; the server may report another case
+		for _, want := range names {
+			if strings.EqualFold(want, name.String) {
+				status[want] = value.String
+			}
+		}
+	}
+	return status, rows.Err()
+}
+
+// statusNumber reads a counter from a globalStatus map
+func statusNumber(status map[string]string, name string) (float64, bool) {
+	value, ok := status[name]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	return n, err == nil
+}
+
+// summaryStatusCounters are the counters of the mysql client's status line
+var summaryStatusCounters = []string{
+	"Uptime",
+	"Threads_connected",
+	"Questions",
+	"Slow_queries",
+	"Opened_tables",
+	"Flush_commands",
+	"Open_tables",
+}
+
+// statusSummary formats the one-line summary of \s like the mysql client:
+// Threads, Questions, Slow queries, Opens, Flush tables, Open tables and the
+// average queries per second since startup. Missing counters are left out.
+func statusSummary(status map[string]string) string {
+	var parts []string
+	for _, c := range []struct{ label, name string }{
+		{"Threads", "Threads_connected"},
+		{"Questions", "Questions"},
+		{"Slow queries", "Slow_queries"},
+		{"Opens", "Opened_tables"},
+		{"Flush tables", "Flush_commands"},
+		{"Open tables", "Open_tables"},
+	} {
+		if n, ok := statusNumber(status, c.name); ok {
+			parts = append(parts, fmt.Sprintf("%s: %d", c.label, int64(n)))
+		}
+	}
+	questions, ok1 := statusNumber(status, "Questions")
+	uptime, ok2 := statusNumber(status, "Uptime")
+	if ok1 && ok2 && uptime > 0 {
+		parts = append(parts, fmt.Sprintf("Queries per second avg: %.3f", questions/uptime))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// formatUptime renders seconds as "1 day 02 hours 03 min 04 sec"
+func formatUptime(uptime int) string {
+	days := uptime / 86400
+	hours := (uptime % 86400) / 3600
+	minutes := (uptime % 3600) / 60
+	seconds := uptime % 60
+	return fmt.Sprintf("%d day%s %02d hour%s %02d min %02d sec", days, plural(days), hours, plural(hours), minutes, seconds)
+}
+
+// extendedStatusCounters are the counters behind the InnoDB lines of \s
+var extendedStatusCounters = []string{
+	"Innodb_buffer_pool_read_requests",
+	"Innodb_buffer_pool_reads",
+	"Innodb_redo_log_current_lsn",    // MySQL 8.0.30+
+	"Innodb_redo_log_checkpoint_lsn", // MySQL 8.0.30+
+	"Innodb_checkpoint_age",          // MariaDB
+	"Innodb_checkpoint_max_age",      // MariaDB
+	"Created_tmp_tables",
+	"Created_tmp_disk_tables",
+}
+
+// showExtendedStatus prints the InnoDB and connection pool part of \s from
+// counters fetched with extendedStatusCounters
+func (p *PromptExecutor) showExtendedStatus(status map[string]string) {
+	if ratio, ok := bufferPoolHitRatio(status); ok {
+		fmt.Printf("Buffer pool hit ratio:\t%.2f%%\n", ratio*100)
+	}
+
+	age, capacity, ok := checkpointAge(status)
+	if !ok {
+		var name, file, text string
+		if p.db.QueryRow("SHOW ENGINE INNODB STATUS").Scan(&name, &file, &text) == nil {
+			age, ok = checkpointAgeFromEngineStatus(text)
+		}
+	}
+	if ok {
+		if capacity == 0 {
+			var v sql.NullInt64
+			if p.db.QueryRow("SELECT @@innodb_redo_log_capacity").Scan(&v) == nil {
+				capacity = v.Int64
+			} else if p.db.QueryRow("SELECT @@innodb_log_file_size * @@innodb_log_files_in_group").Scan(&v) == nil {
+				capacity = v.Int64
+			}
+		}
+		if capacity > 0 {
+			fmt.Printf("Checkpoint age:\t\t%s (%.1f%% of %s redo log)\n",
+				formatBytes(age), float64(age)*100/float64(capacity), formatBytes(capacity))
+		} else {
+			fmt.Printf("Checkpoint age:\t\t%s\n", formatBytes(age))
+		}
+	}
+
+	if ratio, disk, total, ok := tmpDiskRatio(status); ok {
+		fmt.Printf("Temp tables on disk:\t%.1f%% (%d of %d)\n", ratio*100, disk, total)
+	}
+
+	stats := p.db.Stats()
+	fmt.Printf("Connection pool:\t%d open, %d in use, %d idle; %d waits (%s)\n",
+		stats.OpenConnections, stats.InUse, stats.Idle, stats.WaitCount, stats.WaitDuration.Round(time.Millisecond))
+}
+
+// bufferPoolHitRatio is the share of buffer pool page reads served from
+// memory
+func bufferPoolHitRatio(status map[string]string) (float64, bool) {
+	requests, ok1 := statusNumber(status, "Innodb_buffer_pool_read_requests")
+	reads, ok2 := statusNumber(status, "Innodb_buffer_pool_reads")
+	if !ok1 || !ok2 || requests == 0 {
+		return 0, false
+	}
+	return 1 - reads/requests, true
+}
+
+// checkpointAge is how much redo has been written since the last
+// checkpoint, from the MySQL 8.0.30+ LSN counters or MariaDB's own. capacity
+// is 0 when the counters do not say.
+func checkpointAge(status map[string]string) (age, capacity int64, ok bool) {
+	if current, ok1 := statusNumber(status, "Innodb_redo_log_current_lsn"); ok1 {
+		if checkpoint, ok2 := statusNumber(status, "Innodb_redo_log_checkpoint_lsn"); ok2 && current >= checkpoint {
+			return int64(current - checkpoint), 0, true
+		}
+	}
+	if a, ok1 := statusNumber(status, "Innodb_checkpoint_age"); ok1 {
+		maxAge, _ := statusNumber(status, "Innodb_checkpoint_max_age")
+		return int64(a), int64(maxAge), true
+	}
+	return 0, 0, false
+}
+
+var (
+	logSequenceNumber = regexp.MustCompile(`(?m)^Log sequence number\s+(\d+)`)
+	lastCheckpointAt  = regexp.MustCompile(`(?m)^Last checkpoint at\s+(\d+)`)
+)
+
+// checkpointAgeFromEngineStatus reads the checkpoint age from the LOG
+// section of SHOW ENGINE INNODB STATUS, for servers without LSN counters
+func checkpointAgeFromEngineStatus(text string) (int64, bool) {
+	lsn := logSequenceNumber.FindStringSubmatch(text)
+	checkpoint := lastCheckpointAt.FindStringSubmatch(text)
+	if lsn == nil || checkpoint == nil {
+		return 0, false
+	}
+	current, err1 := strconv.ParseInt(lsn[1], 10, 64)
+	last, err2 := strconv.ParseInt(checkpoint[1], 10, 64)
+	if err1 != nil || err2 != nil || current < last {
+		return 0, false
+	}
+	return current - last, true
+}
+
+// tmpDiskRatio is the share of internal temporary tables created on disk
+func tmpDiskRatio(status map[string]string) (ratio float64, disk, total int64, ok bool) {
+	t, ok1 := statusNumber(status, "Created_tmp_tables")
+	d, ok2 := statusNumber(status, "Created_tmp_disk_tables")
+	if !ok1 || !ok2 || t == 0 {
+		return 0, 0, 0, false
+	}
+	return d / t, int64(d), int64(t), true
+}
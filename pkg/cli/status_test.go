@@ -0,0 +1,68 @@
+package cli
+
+import "testing"
+
+func TestBufferPoolHitRatio(t *testing.T) {
+	status := map[string]string{"Innodb_buffer_pool_read_requests": "10000", "Innodb_buffer_pool_reads": "25"}
+	if ratio, ok := bufferPoolHitRatio(status); !ok || ratio != 0.9975 {
+		t.Errorf("bufferPoolHitRatio = %v, %v", ratio, ok)
+	}
+	if _, ok := bufferPoolHitRatio(map[string]string{"Innodb_buffer_pool_read_requests": "0", "Innodb_buffer_pool_reads": "0"}); ok {
+		t.Error("expected no ratio without read requests")
+	}
+}
+
+func TestCheckpointAge(t *testing.T) {
+	tests := []struct {
+		status        map[string]string
+		age, capacity int64
+		ok            bool
+	}{
+		{map[string]string{"Innodb_redo_log_current_lsn": "5000000", "Innodb_redo_log_checkpoint_lsn": "4000000"}, 1000000, 0, true},
+		{map[string]string{"Innodb_checkpoint_age": "2048", "Innodb_checkpoint_max_age": "8192"}, 2048, 8192, true},
+		{map[string]string{"Created_tmp_tables": "1"}, 0, 0, false},
+	}
+	for _, tt := range tests {
+		age, capacity, ok := checkpointAge(tt.status)
+		if age != tt.age || capacity != tt.capacity || ok != tt.ok {
+			t.Errorf("checkpointAge(%v) = %d, %d, %v", tt.status, age, capacity, ok)
+		}
+	}
+}
+
+func TestCheckpointAgeFromEngineStatus(t *testing.T) {
+	text := "---\nLOG\n---\nLog sequence number          123456789\nLog buffer assigned up to    123456789\nLog flushed up to   123456700\nLast checkpoint at  123000000\n"
+	if age, ok := checkpointAgeFromEngineStatus(text); !ok || age != 456789 {
+		t.Errorf("checkpointAgeFromEngineStatus = %d, %v", age, ok)
+	}
+	if _, ok := checkpointAgeFromEngineStatus("no log section"); ok {
+		t.Error("expected no age without a LOG section")
+	}
+}
+
+func TestTmpDiskRatio(t *testing.T) {
+	ratio, disk, total, ok := tmpDiskRatio(map[string]string{"Created_tmp_tables": "200", "Created_tmp_disk_tables": "50"})
+	if !ok || ratio != 0.25 || disk != 50 || total != 200 {
+		t.Errorf("tmpDiskRatio = %v, %d, %d, %v", ratio, disk, total, ok)
+	}
+}
+
+func TestStatusSummary(t *testing.T) {
+	status := map[string]string{
+		"Uptime": "1000", "Threads_connected": "3", "Questions": "2500", "Slow_queries": "1",
+		"Opened_tables": "120", "Flush_commands": "3", "Open_tables": "40",
+	}
+	want := "Threads: 3  Questions: 2500  Slow queries: 1  Opens: 120  Flush tables: 3  Open tables: 40  Queries per second avg: 2.500"
+	if got := statusSummary(status); got != want {
+		t.Errorf("statusSummary = %q, want %q", got, want)
+	}
+	if got := statusSummary(map[string]string{"Threads_connected": "1"}); got != "Threads: 1" {
+		t.Errorf("statusSummary with missing counters = %q", got)
+	}
+}
+
+func TestFormatUptime(t *testing.T) {
+	if got, want := formatUptime(93784), "1 day 02 hours 03 min 04 sec"; got != want {
+		t.Errorf("formatUptime = %q, want %q", got, want)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/styles"
@@ -14,33 +16,104 @@ import (
 // SyntaxConfig holds the syntax highlighting configuration
 type SyntaxConfig struct {
 	Style               string
+	Background          string // terminal background: auto, dark or light
 	UseCustomColors     bool
 	EnableSuggestions   bool
 	EnableAIAnalysis    bool
 	EnableJSONExport    bool
 	EnableVisualExplain bool
+	AutoView            bool          // open the result viewer for results wider than the terminal
+	MaxFieldWidth       int           // truncate result cells longer than this, 0 for no limit
+	ResultBuffers       int           // recent results kept for \show, 0 to keep none
+	QueryCost           bool          // show Last_query_cost and handler reads after each SELECT
+	ShowWarnings        bool          // show the server's warnings after each statement
+	OutputFormat        string        // renderer of interactive results, as set with \output
+	Language            string        // auto, en or es; auto follows LC_ALL, LC_MESSAGES and LANG
+	Pager               string        // command to page results with, "" for $PAGER or less
+	EnablePager         bool          // page interactive results taller than the terminal
+	ColorResults        bool          // color NULLs, numbers and status values in result tables
+	LiveHighlight       bool          // highlight the input line while typing
+	RankCompletions     bool          // rank completions by how often tables and columns are used
+	CompletionMode      string        // auto, full, metadata or off
+	CompletionLatency   time.Duration // auto mode completes keywords only above this round trip
+	CompletionMatch     string        // fuzzy, substring or prefix
+	CompletionMinScore  int           // drop matches scoring lower, 0 to keep all
+	CompletionValues    bool          // complete col = ' from the column's most frequent values
+	Keepalive           time.Duration // ping interval for idle sessions, 0 to disable
+	RowEstimateWarning  int64         // confirm SELECTs expected to examine more rows, 0 to disable
+	LiveTimer           bool          // show the elapsed time while a statement runs
+	LongQueryAlert      time.Duration // announce statements running longer, 0 to disable
+	LongQueryNotify     string        // bell, desktop or both
+	SyntaxCheck         bool          // point out likely syntax mistakes before sending a statement
+	MaxReplicaLag       time.Duration // heavy writes pause while a replica lags more, 0 to never pause
+	LagReplicas         []string      // replicas to watch, option file groups or host[:port]
+	HeartbeatTable      string        // pt-heartbeat table to measure lag with
+	ReplicaLagWait      bool          // wait for the replicas before every write typed at the prompt
 	AiServerURL         string
 	AiServerMode        string
 	AiCachePath         string
+	AiMCPCommand        string
+	Hooks               HookConfig
+	Format              FormatConfig
+	Popup               PopupConfig
+	Session             map[string]string // [session] variables SET on every connection
+	Aliases             map[string]string // [aliases] backslash command shortcuts
+	Runbooks            map[string]string // [runbooks] statement templates for \runbook
+	PromptRules         []PromptRule      // [prompt] colors and markers by host, first match wins
 	Colors              map[string]string
 }
 
+// defaultAIServerURL is the MCP endpoint of mcp-server's default --listen
+const defaultAIServerURL = "http://127.0.0.1:8800/mcp"
+
 // DefaultSyntaxConfig returns the default syntax configuration
 func DefaultSyntaxConfig() *SyntaxConfig {
 	return &SyntaxConfig{
 		Style:               "monokai",
+		Background:          "auto",
 		UseCustomColors:     false,
 		EnableSuggestions:   true,
-		EnableAIAnalysis:    true,
+		EnableAIAnalysis:    false,
 		EnableJSONExport:    false,
 		EnableVisualExplain: false,
-		AiServerURL:         "http://127.0.0.1:8800/mcp",
+		AutoView:            true,
+		ResultBuffers:       defaultResultBuffers,
+		OutputFormat:        "table",
+		Language:            languageAuto,
+		EnablePager:         true,
+		ColorResults:        true,
+		LiveHighlight:       true,
+		RankCompletions:     true,
+		CompletionMode:      completionAuto,
+		CompletionLatency:   defaultCompletionLatency,
+		CompletionMatch:     matchFuzzy,
+		Keepalive:           defaultKeepalive,
+		LiveTimer:           true,
+		MaxReplicaLag:       defaultMaxReplicaLag,
+		LongQueryNotify:     notifyBell,
+		Popup:               PopupConfig{Descriptions: true},
+		AiServerURL:         defaultAIServerURL,
 		AiServerMode:        "copilot_mcp_http",
 		AiCachePath:         "~/.go-mycli/ai_cache.db",
+		AiMCPCommand:        "sqlbot",
 		Colors:              DefaultColors(),
 	}
 }
 
+// DefaultLightColors returns the color scheme used on light terminal backgrounds
+func DefaultLightColors() map[string]string {
+	return map[string]string{
+		"keyword":     "#0550AE", // Blue for SQL keywords
+		"name":        "#116329", // Dark green for table/column names
+		"builtin":     "#8250DF", // Purple for SQL functions
+		"string":      "#0A3069", // Navy for string literals
+		"number":      "#953800", // Brown for numbers
+		"operator":    "#CF222E", // Red for operators
+		"comment":     "#6E7781", // Gray for comments
+		"punctuation": "#24292F", // Near-black for punctuation
+	}
+}
+
 // DefaultColors returns the default color scheme
 func DefaultColors() map[string]string {
 	return map[string]string{
@@ -55,29 +128,30 @@ func DefaultColors() map[string]string {
 	}
 }
 
+// syntaxConfigPath returns the config file LoadSyntaxConfig reads: the
+// GO_MYCLI_RC environment variable, else ./.go-myclirc when it exists, else
+// ~/.go-myclirc (%APPDATA%\go-mycli\go-myclirc on Windows). It is "" when
+// there is no home directory.
+func syntaxConfigPath() string {
+	if envPath := os.Getenv("GO_MYCLI_RC"); envPath != "" {
+		return envPath
+	}
+	if wd, err := os.Getwd(); err == nil {
+		localPath := filepath.Join(wd, ".go-myclirc")
+		if _, err := os.Stat(localPath); err == nil {
+			return localPath
+		}
+	}
+	return homeConfigPath()
+}
+
 // LoadSyntaxConfig loads syntax configuration from ~/.go-myclirc
 func LoadSyntaxConfig() *SyntaxConfig {
 	config := DefaultSyntaxConfig()
 
-	// Check for local config in current directory first (./.go-myclirc), then fallback to $HOME/.go-myclirc
-	// Also allow env var override GO_MYCLI_RC
-	var configPath string
-	if envPath := os.Getenv("GO_MYCLI_RC"); envPath != "" {
-		configPath = envPath
-	} else {
-		if wd, err := os.Getwd(); err == nil {
-			localPath := filepath.Join(wd, ".go-myclirc")
-			if _, err := os.Stat(localPath); err == nil {
-				configPath = localPath
-			}
-		}
-		if configPath == "" {
-			home, err := os.UserHomeDir()
-			if err != nil {
-				return config
-			}
-			configPath = filepath.Join(home, ".go-myclirc")
-		}
+	configPath := syntaxConfigPath()
+	if configPath == "" {
+		return config
 	}
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		// Config file doesn't exist, return default
@@ -98,6 +172,9 @@ func LoadSyntaxConfig() *SyntaxConfig {
 		if main.HasKey("syntax_style") {
 			config.Style = main.Key("syntax_style").String()
 		}
+		if main.HasKey("background") {
+			config.Background = strings.ToLower(main.Key("background").String())
+		}
 		if main.HasKey("use_custom_colors") {
 			if val, err := main.Key("use_custom_colors").Bool(); err == nil {
 				config.UseCustomColors = val
@@ -124,6 +201,141 @@ func LoadSyntaxConfig() *SyntaxConfig {
 				config.EnableVisualExplain = val
 			}
 		}
+		if main.HasKey("max_field_width") {
+			if val, err := main.Key("max_field_width").Int(); err == nil && val >= 0 {
+				config.MaxFieldWidth = val
+			}
+		}
+		if main.HasKey("live_highlighting") {
+			if val, err := main.Key("live_highlighting").Bool(); err == nil {
+				config.LiveHighlight = val
+			}
+		}
+		if main.HasKey("rank_completions") {
+			if val, err := main.Key("rank_completions").Bool(); err == nil {
+				config.RankCompletions = val
+			}
+		}
+		if main.HasKey("completion") {
+			if val, ok := parseCompletionMode(main.Key("completion").String()); ok {
+				config.CompletionMode = val
+			}
+		}
+		if main.HasKey("completion_latency") {
+			if d, err := time.ParseDuration(main.Key("completion_latency").String()); err == nil && d > 0 {
+				config.CompletionLatency = d
+			}
+		}
+		if main.HasKey("completion_match") {
+			if val, ok := parseCompletionMatch(main.Key("completion_match").String()); ok {
+				config.CompletionMatch = val
+			}
+		}
+		if main.HasKey("completion_min_score") {
+			if val, err := main.Key("completion_min_score").Int(); err == nil && val >= 0 {
+				config.CompletionMinScore = val
+			}
+		}
+		if main.HasKey("completion_values") {
+			if val, err := main.Key("completion_values").Bool(); err == nil {
+				config.CompletionValues = val
+			}
+		}
+		if main.HasKey("keepalive") {
+			if d, err := time.ParseDuration(main.Key("keepalive").String()); err == nil && d >= 0 {
+				config.Keepalive = d
+			}
+		}
+		if main.HasKey("row_estimate_warning") {
+			if val, err := main.Key("row_estimate_warning").Int64(); err == nil && val >= 0 {
+				config.RowEstimateWarning = val
+			}
+		}
+		if main.HasKey("live_timer") {
+			if val, err := main.Key("live_timer").Bool(); err == nil {
+				config.LiveTimer = val
+			}
+		}
+		if main.HasKey("long_query_alert") {
+			if d, err := time.ParseDuration(main.Key("long_query_alert").String()); err == nil && d >= 0 {
+				config.LongQueryAlert = d
+			}
+		}
+		if main.HasKey("long_query_notify") {
+			if val, ok := parseNotify(main.Key("long_query_notify").String()); ok {
+				config.LongQueryNotify = val
+			}
+		}
+		if main.HasKey("max_replica_lag") {
+			if d, err := time.ParseDuration(main.Key("max_replica_lag").String()); err == nil && d >= 0 {
+				config.MaxReplicaLag = d
+			}
+		}
+		if main.HasKey("lag_replicas") {
+			config.LagReplicas = nil
+			for _, name := range strings.Split(main.Key("lag_replicas").String(), ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					config.LagReplicas = append(config.LagReplicas, name)
+				}
+			}
+		}
+		if main.HasKey("heartbeat_table") {
+			config.HeartbeatTable = strings.TrimSpace(main.Key("heartbeat_table").String())
+		}
+		if main.HasKey("replica_lag_wait") {
+			if val, err := main.Key("replica_lag_wait").Bool(); err == nil {
+				config.ReplicaLagWait = val
+			}
+		}
+		if main.HasKey("syntax_check") {
+			if val, err := main.Key("syntax_check").Bool(); err == nil {
+				config.SyntaxCheck = val
+			}
+		}
+		if main.HasKey("result_buffers") {
+			if val, err := main.Key("result_buffers").Int(); err == nil && val >= 0 {
+				config.ResultBuffers = val
+			}
+		}
+		if main.HasKey("query_cost") {
+			if val, err := main.Key("query_cost").Bool(); err == nil {
+				config.QueryCost = val
+			}
+		}
+		if main.HasKey("show_warnings") {
+			if val, err := main.Key("show_warnings").Bool(); err == nil {
+				config.ShowWarnings = val
+			}
+		}
+		if main.HasKey("output_format") {
+			format := strings.ToLower(strings.TrimSpace(main.Key("output_format").String()))
+			if _, err := NewRenderer(format); err == nil {
+				config.OutputFormat = format
+			}
+		}
+		if main.HasKey("language") {
+			if lang, ok := oneOf(languageAuto, languageEnglish, languageSpanish)(main.Key("language").String()); ok {
+				config.Language = lang
+			}
+		}
+		if main.HasKey("pager") {
+			config.Pager = strings.TrimSpace(main.Key("pager").String())
+		}
+		if main.HasKey("enable_pager") {
+			if val, err := main.Key("enable_pager").Bool(); err == nil {
+				config.EnablePager = val
+			}
+		}
+		if main.HasKey("color_results") {
+			if val, err := main.Key("color_results").Bool(); err == nil {
+				config.ColorResults = val
+			}
+		}
+		if main.HasKey("auto_view") {
+			if val, err := main.Key("auto_view").Bool(); err == nil {
+				config.AutoView = val
+			}
+		}
 		if main.HasKey("ai_server_url") {
 			config.AiServerURL = main.Key("ai_server_url").String()
 		}
@@ -133,6 +345,83 @@ func LoadSyntaxConfig() *SyntaxConfig {
 		if main.HasKey("ai_cache_path") {
 			config.AiCachePath = main.Key("ai_cache_path").String()
 		}
+		if main.HasKey("ai_mcp_command") {
+			config.AiMCPCommand = main.Key("ai_mcp_command").String()
+		}
+	}
+
+	// Load hooks section
+	if cfg.HasSection("hooks") {
+		hooks := cfg.Section("hooks")
+		config.Hooks.PreQuery = hooks.Key("pre_query").String()
+		config.Hooks.PostQuery = hooks.Key("post_query").String()
+		config.Hooks.WebhookURL = hooks.Key("webhook_url").String()
+		if d, err := time.ParseDuration(hooks.Key("min_duration").String()); err == nil {
+			config.Hooks.MinDuration = d
+		}
+		if d, err := time.ParseDuration(hooks.Key("timeout").String()); err == nil {
+			config.Hooks.Timeout = d
+		}
+	}
+
+	// Load format section
+	if cfg.HasSection("format") {
+		format := cfg.Section("format")
+		config.Format.Timezone = format.Key("timezone").String()
+		config.Format.DatetimeFormat = format.Key("datetime_format").String()
+		config.Format.Numbers = format.Key("numbers").String()
+	}
+
+	// Load completion popup section
+	if cfg.HasSection("completion") {
+		completion := cfg.Section("completion")
+		if val, err := completion.Key("max_rows").Int(); err == nil && val >= 0 {
+			config.Popup.MaxRows = val
+		}
+		if completion.HasKey("descriptions") {
+			if val, err := completion.Key("descriptions").Bool(); err == nil {
+				config.Popup.Descriptions = val
+			}
+		}
+		for _, k := range config.Popup.colorSettings() {
+			*k.value = completion.Key(k.key).String()
+		}
+	}
+
+	// Load session section
+	if cfg.HasSection("session") {
+		config.Session = make(map[string]string)
+		for _, key := range cfg.Section("session").Keys() {
+			config.Session[key.Name()] = key.String()
+		}
+	}
+
+	// Load aliases section
+	if cfg.HasSection("aliases") {
+		config.Aliases = make(map[string]string)
+		for _, key := range cfg.Section("aliases").Keys() {
+			config.Aliases[key.Name()] = key.String()
+		}
+	}
+
+	// Load runbooks section
+	if cfg.HasSection("runbooks") {
+		config.Runbooks = make(map[string]string)
+		for _, key := range cfg.Section("runbooks").Keys() {
+			config.Runbooks[key.Name()] = key.String()
+		}
+	}
+
+	// Load prompt section
+	if cfg.HasSection("prompt") {
+		for _, key := range cfg.Section("prompt").Keys() {
+			rule, err := parsePromptRule(key.Name(), key.String())
+			if err != nil {
+				// Reported with its line by validateConfig
+				continue
+			}
+			config.PromptRules = append(config.PromptRules, rule)
+		}
 	}
 
 	// Load colors section
@@ -160,33 +449,69 @@ func LoadSyntaxConfig() *SyntaxConfig {
 
 // SaveDefaultSyntaxConfig creates a default config file at ~/.go-myclirc
 func SaveDefaultSyntaxConfig() error {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return err
+	configPath := homeConfigPath()
+	if configPath == "" {
+		return fmt.Errorf("no home directory")
 	}
 
-	configPath := filepath.Join(home, ".go-myclirc")
-
 	// Don't overwrite existing config
 	if _, err := os.Stat(configPath); err == nil {
 		return nil
 	}
 
-	// Create default config
+	// Save to file
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+		return err
+	}
+	return defaultConfigFile().SaveTo(configPath)
+}
+
+// defaultConfigFile is the config file SaveDefaultSyntaxConfig writes, with
+// every [main] key and a comment on what it does
+func defaultConfigFile() *ini.File {
 	cfg := ini.Empty()
 
 	// Main section
 	main, _ := cfg.NewSection("main")
 	main.NewKey("syntax_style", "monokai")
+	main.NewKey("background", "auto")
 	main.NewKey("use_custom_colors", "true")
 	main.NewKey("suggestions", "true")
 	main.NewKey("ai_analysis", "false")
 	main.NewKey("json_export", "false")
 	main.NewKey("visual_explain", "false")
-	main.NewKey("ai_server_url", "http://127.0.0.1:44044/mcp")
+	main.NewKey("auto_view", "true")
+	main.NewKey("max_field_width", "0")
+	main.NewKey("result_buffers", fmt.Sprintf("%d", defaultResultBuffers))
+	main.NewKey("query_cost", "false")
+	main.NewKey("show_warnings", "false")
+	main.NewKey("output_format", "table")
+	main.NewKey("language", languageAuto)
+	main.NewKey("pager", "")
+	main.NewKey("enable_pager", "true")
+	main.NewKey("color_results", "true")
+	main.NewKey("live_highlighting", "true")
+	main.NewKey("rank_completions", "true")
+	main.NewKey("completion", completionAuto)
+	main.NewKey("completion_latency", defaultCompletionLatency.String())
+	main.NewKey("completion_match", matchFuzzy)
+	main.NewKey("completion_min_score", "0")
+	main.NewKey("completion_values", "false")
+	main.NewKey("keepalive", defaultKeepalive.String())
+	main.NewKey("row_estimate_warning", "0")
+	main.NewKey("live_timer", "true")
+	main.NewKey("long_query_alert", "0s")
+	main.NewKey("long_query_notify", notifyBell)
+	main.NewKey("syntax_check", "false")
+	main.NewKey("max_replica_lag", defaultMaxReplicaLag.String())
+	main.NewKey("lag_replicas", "")
+	main.NewKey("heartbeat_table", "")
+	main.NewKey("replica_lag_wait", "false")
+	main.NewKey("ai_server_url", defaultAIServerURL)
 	main.NewKey("ai_server_mode", "copilot_mcp_http")
 	main.NewKey("ai_cache_path", "~/.go-mycli/ai_cache.db")
-	main.Comment = "# Syntax highlighting style: monokai, dracula, native, vim, etc.\n# Set use_custom_colors=false to use the style without overrides.\n# Enable AI-powered EXPLAIN analysis (requires OPENAI_API_KEY)\n# Enable JSON export for external tools like pt-visual-explain\n# Enable built-in visual explain tree representation\n# See: https://github.com/alecthomas/chroma#styles"
+	main.NewKey("ai_mcp_command", "sqlbot")
+	main.Comment = "# Syntax highlighting style: monokai, dracula, native, vim, etc.\n# Light terminals: set background=light (or leave auto) for the light style, or pick github, solarized-light, etc.\n# Set use_custom_colors=false to use the style without overrides.\n# Enable AI-powered EXPLAIN analysis (requires OPENAI_API_KEY)\n# Enable JSON export for external tools like pt-visual-explain\n# Enable built-in visual explain tree representation\n# auto_view opens the interactive result viewer for results wider than the terminal\n# max_field_width truncates long values in tables (0 = no limit); \\expand shows them in full\n# result_buffers is how many recent results \\buffers and \\show keep in memory (0 keeps none)\n# query_cost prints Last_query_cost and handler reads after each SELECT, as \\cost on does\n# show_warnings prints the server's warnings after each statement (\\W); output_format is the \\output of interactive results\n# language of messages and help: en, es, or auto for LC_ALL, LC_MESSAGES and LANG\n# enable_pager pipes results taller than the terminal through pager (empty for $PAGER, else less)\n# \\ai, \\json, \\visual, \\suggestions, \\completion, \\cost, \\W, \\output, \\timing and \\pager save their setting here\n# color_results colors NULLs, numbers and Yes/No/error values in interactive results\n# live_highlighting colors the input as you type; when off, statements are echoed highlighted after Enter\n# rank_completions lists the tables and columns you use most first (counts are kept in ~/.go-mycli/metadata.db)\n# completion: full loads every table's columns, metadata only table names, off only keywords;\n# auto is full unless a round trip takes longer than completion_latency\n# completion_match: fuzzy (letters in order), substring or prefix; completion_min_score drops weaker matches (0 keeps all)\n# completion_values completes col = ' with the column's most frequent values, sampled from the table once per session\n# keepalive pings the server this often while idle so firewalls keep the connection (0 disables)\n# row_estimate_warning asks before running a SELECT that EXPLAIN expects to examine more rows (0 disables)\n# live_timer shows the elapsed time while a statement runs; long_query_alert (e.g. 30s, 0s disables)\n# announces a statement running longer with its KILL command, long_query_notify: bell, desktop or both\n# syntax_check points out unbalanced parentheses and quotes and missing clauses before a statement is sent\n# max_replica_lag pauses \\chunked and \\alter-safe while a replica lags more (0s never pauses); lag_replicas lists\n# the replicas to watch (option file groups or host:port, empty for SHOW REPLICAS), heartbeat_table a pt-heartbeat\n# table to measure lag with, and replica_lag_wait makes every write typed at the prompt wait too\n# ai_server_mode=mcp_stdio runs ai_mcp_command (sqlbot) directly instead of using ai_server_url\n# See: https://github.com/alecthomas/chroma#styles"
 
 	// Colors section
 	colors, _ := cfg.NewSection("colors")
@@ -200,8 +525,7 @@ func SaveDefaultSyntaxConfig() error {
 	colors.NewKey("punctuation", "#F8F8F2")
 	colors.Comment = "# Custom colors for SQL tokens (hex format: #RRGGBB)\n# Keywords: SQL commands (SELECT, FROM, WHERE, etc.)\n# Name: Table and column names\n# Builtin: SQL functions (COUNT, SUM, MAX, etc.)\n# String: String literals ('text')\n# Number: Numeric values\n# Operator: Comparison and logical operators (=, >, AND, etc.)\n# Comment: SQL comments\n# Punctuation: Commas, semicolons, parentheses"
 
-	// Save to file
-	return cfg.SaveTo(configPath)
+	return cfg
 }
 
 // CreateStyleFromConfig creates a Chroma style from configuration
@@ -294,32 +618,128 @@ func CreateStyleFromConfig(config *SyntaxConfig) *chroma.Style {
 	return style
 }
 
-// SaveSyntaxConfig writes the provided syntax config back to ~/.go-myclirc
+// SaveSyntaxConfig writes the provided syntax config back to the config
+// file go-mycli reads, ~/.go-myclirc unless overridden. Only the keys it
+// sets are changed; comments and other keys in the file are kept.
 func SaveSyntaxConfig(config *SyntaxConfig) error {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return err
+	configPath := syntaxConfigPath()
+	if configPath == "" {
+		return fmt.Errorf("no home directory to keep ~/.go-myclirc in")
 	}
-	configPath := filepath.Join(home, ".go-myclirc")
 
 	cfg := ini.Empty()
 	main, _ := cfg.NewSection("main")
 	main.NewKey("syntax_style", config.Style)
+	main.NewKey("background", config.Background)
 	main.NewKey("use_custom_colors", fmt.Sprintf("%v", config.UseCustomColors))
 	main.NewKey("suggestions", fmt.Sprintf("%v", config.EnableSuggestions))
 	main.NewKey("ai_analysis", fmt.Sprintf("%v", config.EnableAIAnalysis))
 	main.NewKey("json_export", fmt.Sprintf("%v", config.EnableJSONExport))
 	main.NewKey("visual_explain", fmt.Sprintf("%v", config.EnableVisualExplain))
+	main.NewKey("auto_view", fmt.Sprintf("%v", config.AutoView))
+	main.NewKey("max_field_width", fmt.Sprintf("%d", config.MaxFieldWidth))
+	main.NewKey("result_buffers", fmt.Sprintf("%d", config.ResultBuffers))
+	main.NewKey("query_cost", fmt.Sprintf("%v", config.QueryCost))
+	main.NewKey("show_warnings", fmt.Sprintf("%v", config.ShowWarnings))
+	main.NewKey("output_format", config.OutputFormat)
+	main.NewKey("language", config.Language)
+	main.NewKey("pager", config.Pager)
+	main.NewKey("enable_pager", fmt.Sprintf("%v", config.EnablePager))
+	main.NewKey("color_results", fmt.Sprintf("%v", config.ColorResults))
+	main.NewKey("live_highlighting", fmt.Sprintf("%v", config.LiveHighlight))
+	main.NewKey("rank_completions", fmt.Sprintf("%v", config.RankCompletions))
+	main.NewKey("completion", config.CompletionMode)
+	main.NewKey("completion_latency", config.CompletionLatency.String())
+	main.NewKey("completion_match", config.CompletionMatch)
+	main.NewKey("completion_min_score", fmt.Sprintf("%d", config.CompletionMinScore))
+	main.NewKey("completion_values", fmt.Sprintf("%v", config.CompletionValues))
+	main.NewKey("keepalive", config.Keepalive.String())
+	main.NewKey("row_estimate_warning", fmt.Sprintf("%d", config.RowEstimateWarning))
+	main.NewKey("live_timer", fmt.Sprintf("%v", config.LiveTimer))
+	main.NewKey("long_query_alert", config.LongQueryAlert.String())
+	main.NewKey("long_query_notify", config.LongQueryNotify)
+	main.NewKey("syntax_check", fmt.Sprintf("%v", config.SyntaxCheck))
+	main.NewKey("max_replica_lag", config.MaxReplicaLag.String())
+	main.NewKey("lag_replicas", strings.Join(config.LagReplicas, ", "))
+	main.NewKey("heartbeat_table", config.HeartbeatTable)
+	main.NewKey("replica_lag_wait", fmt.Sprintf("%v", config.ReplicaLagWait))
 	main.NewKey("ai_server_url", config.AiServerURL)
 	main.NewKey("ai_server_mode", config.AiServerMode)
 	main.NewKey("ai_cache_path", config.AiCachePath)
+	main.NewKey("ai_mcp_command", config.AiMCPCommand)
+
+	if config.Hooks.Enabled() {
+		hooks, _ := cfg.NewSection("hooks")
+		hooks.NewKey("pre_query", config.Hooks.PreQuery)
+		hooks.NewKey("post_query", config.Hooks.PostQuery)
+		hooks.NewKey("webhook_url", config.Hooks.WebhookURL)
+		hooks.NewKey("min_duration", config.Hooks.MinDuration.String())
+		if config.Hooks.Timeout > 0 {
+			hooks.NewKey("timeout", config.Hooks.Timeout.String())
+		}
+	}
+
+	if config.Format.Enabled() {
+		format, _ := cfg.NewSection("format")
+		format.NewKey("timezone", config.Format.Timezone)
+		format.NewKey("datetime_format", config.Format.DatetimeFormat)
+		format.NewKey("numbers", config.Format.Numbers)
+	}
+
+	if config.Popup.Enabled() {
+		completion, _ := cfg.NewSection("completion")
+		completion.NewKey("max_rows", fmt.Sprintf("%d", config.Popup.MaxRows))
+		completion.NewKey("descriptions", fmt.Sprintf("%v", config.Popup.Descriptions))
+		for _, k := range config.Popup.colorKeys() {
+			completion.NewKey(k.key, *k.value)
+		}
+	}
+
+	if len(config.Session) > 0 {
+		session, _ := cfg.NewSection("session")
+		names := make([]string, 0, len(config.Session))
+		for name := range config.Session {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			session.NewKey(name, config.Session[name])
+		}
+	}
+
+	if len(config.Aliases) > 0 {
+		aliases, _ := cfg.NewSection("aliases")
+		for _, name := range aliasNames(config.Aliases) {
+			aliases.NewKey(name, config.Aliases[name])
+		}
+	}
+
+	if len(config.Runbooks) > 0 {
+		runbooks, _ := cfg.NewSection("runbooks")
+		for _, name := range aliasNames(config.Runbooks) {
+			runbooks.NewKey(name, config.Runbooks[name])
+		}
+	}
+
+	if len(config.PromptRules) > 0 {
+		promptSection, _ := cfg.NewSection("prompt")
+		for _, rule := range config.PromptRules {
+			promptSection.NewKey(rule.Pattern, rule.String())
+		}
+	}
 
 	colorsSection, _ := cfg.NewSection("colors")
 	for k, v := range config.Colors {
 		colorsSection.NewKey(k, v)
 	}
 
-	return cfg.SaveTo(configPath)
+	var settings []configSetting
+	for _, section := range cfg.Sections() {
+		for _, key := range section.Keys() {
+			settings = append(settings, configSetting{Section: section.Name(), Key: key.Name(), Value: key.Value()})
+		}
+	}
+	return saveSettings(configPath, settings)
 }
 
 func resolveBaseStyle(name string) *chroma.Style {
@@ -329,6 +749,11 @@ func resolveBaseStyle(name string) *chroma.Style {
 				return smooth
 			}
 		}
+		if strings.EqualFold(name, "light") {
+			if light := lightStyle(); light != nil {
+				return light
+			}
+		}
 		if style := styles.Get(name); style != nil {
 			return style
 		}
@@ -368,6 +793,31 @@ func smoothStyle() *chroma.Style {
 	return style
 }
 
+// lightStyle is the built-in style for light terminal backgrounds
+func lightStyle() *chroma.Style {
+	colors := DefaultLightColors()
+	entries := chroma.StyleEntries{
+		chroma.Text:          colors["punctuation"],
+		chroma.Keyword:       colors["keyword"] + " bold",
+		chroma.KeywordType:   colors["keyword"],
+		chroma.Name:          colors["name"],
+		chroma.NameFunction:  colors["builtin"],
+		chroma.NameBuiltin:   colors["builtin"],
+		chroma.LiteralString: colors["string"],
+		chroma.LiteralNumber: colors["number"],
+		chroma.Operator:      colors["operator"],
+		chroma.OperatorWord:  colors["keyword"] + " bold",
+		chroma.Comment:       "italic " + colors["comment"],
+		chroma.Punctuation:   colors["punctuation"],
+	}
+
+	style, err := chroma.NewStyle("go-mycli-light", entries)
+	if err != nil {
+		return styles.Get("github")
+	}
+	return style
+}
+
 func sanitizeColorValue(raw string) string {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
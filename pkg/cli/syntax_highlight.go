@@ -23,6 +23,13 @@ func NewSyntaxHighlighter() *SyntaxHighlighter {
 	// Load user configuration
 	config := LoadSyntaxConfig()
 
+	// Create style from configuration, adjusted for light terminals
+	adaptToBackground(config)
+	return newSyntaxHighlighter(CreateStyleFromConfig(config))
+}
+
+// newSyntaxHighlighter creates a MySQL highlighter with the given style
+func newSyntaxHighlighter(style *chroma.Style) *SyntaxHighlighter {
 	// Use MySQL lexer from Chroma
 	lexer := lexers.Get("mysql")
 	if lexer == nil {
@@ -30,9 +37,6 @@ func NewSyntaxHighlighter() *SyntaxHighlighter {
 		lexer = lexers.Get("sql")
 	}
 
-	// Create style from configuration
-	style := CreateStyleFromConfig(config)
-
 	// Use terminal16m formatter for better ANSI color support
 	formatter := formatters.Get("terminal16m")
 	if formatter == nil {
@@ -52,17 +56,29 @@ func NewSyntaxHighlighter() *SyntaxHighlighter {
 	}
 }
 
-// HighlightingWriter wraps a ConsoleWriter to apply syntax highlighting
+// inputMarkerColor is passed to go-prompt as the input text color so the
+// HighlightingWriter can tell the input buffer apart from the prefix and the
+// completion menu, which go-prompt draws through the same writer
+const inputMarkerColor = prompt.LightGray
+
+// HighlightingWriter wraps a ConsoleWriter to apply syntax highlighting to the
+// line being typed. go-prompt sets the input text color right before writing
+// the buffer, so text written in inputMarkerColor is highlighted.
 type HighlightingWriter struct {
 	inner       prompt.ConsoleWriter
 	highlighter *SyntaxHighlighter
+	context     func() string // statement lines entered before the current one
+	inInput     bool
 }
 
-// NewHighlightingWriter creates a new highlighting writer
-func NewHighlightingWriter(highlighter *SyntaxHighlighter) *HighlightingWriter {
+// NewHighlightingWriter creates a new highlighting writer. context returns the
+// earlier lines of a multi-line statement so the current line is highlighted
+// in context (e.g. inside an unterminated string); it may be nil.
+func NewHighlightingWriter(highlighter *SyntaxHighlighter, context func() string) *HighlightingWriter {
 	return &HighlightingWriter{
 		inner:       prompt.NewStdoutWriter(),
 		highlighter: highlighter,
+		context:     context,
 	}
 }
 
@@ -81,9 +97,19 @@ func (w *HighlightingWriter) WriteRawStr(data string) {
 	w.inner.WriteRawStr(data)
 }
 
-// WriteStr writes string with control sequence removal
+// WriteStr writes string with control sequence removal, highlighting input text
 func (w *HighlightingWriter) WriteStr(data string) {
-	w.inner.WriteStr(data)
+	// The buffer is written raw once highlighted, so leave anything that
+	// already contains escape sequences to the control sequence removal
+	if !w.inInput || strings.ContainsRune(data, '\x1b') {
+		w.inner.WriteStr(data)
+		return
+	}
+	var context string
+	if w.context != nil {
+		context = w.context()
+	}
+	w.inner.WriteRawStr(w.highlighter.HighlightContinuation(context, data))
 }
 
 // Flush flushes the buffer
@@ -191,15 +217,20 @@ func (w *HighlightingWriter) ClearTitle() {
 	w.inner.ClearTitle()
 }
 
-// SetColor sets color
+// SetColor sets color. The input marker color is replaced by the default
+// color; the highlighter supplies the colors of the input itself.
 func (w *HighlightingWriter) SetColor(fg, bg prompt.Color, bold bool) {
+	w.inInput = fg == inputMarkerColor && bg == prompt.DefaultColor
+	if w.inInput {
+		fg = prompt.DefaultColor
+	}
 	w.inner.SetColor(fg, bg, bold)
 }
 
 // HighlightSQL applies syntax highlighting to SQL text
 func (sh *SyntaxHighlighter) HighlightSQL(sql string) string {
-	if sh.lexer == nil {
-		return sql // No highlighting if lexer not available
+	if sh.lexer == nil || !colorEnabled() {
+		return sql // No highlighting if lexer not available or colors are off
 	}
 
 	// Tokenize the SQL
@@ -218,6 +249,20 @@ func (sh *SyntaxHighlighter) HighlightSQL(sql string) string {
 	return buf.String()
 }
 
+// HighlightContinuation highlights text as the end of a statement that began
+// with context, returning only the highlighted lines of text
+func (sh *SyntaxHighlighter) HighlightContinuation(context, text string) string {
+	if context == "" {
+		return sh.HighlightSQL(text)
+	}
+	lines := strings.Split(sh.HighlightSQL(context+text), "\n")
+	n := strings.Count(text, "\n") + 1
+	if n > len(lines) {
+		return sh.HighlightSQL(text)
+	}
+	return strings.Join(lines[len(lines)-n:], "\n")
+}
+
 // HighlightPromptDocument highlights a go-prompt document
 func (sh *SyntaxHighlighter) HighlightPromptDocument(doc *prompt.Document) string {
 	text := doc.Text
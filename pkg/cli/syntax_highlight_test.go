@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/c-bata/go-prompt"
+)
+
+// recordingWriter captures what the HighlightingWriter passes on
+type recordingWriter struct {
+	prompt.ConsoleWriter
+	out    strings.Builder
+	colors []prompt.Color
+}
+
+func (r *recordingWriter) WriteStr(data string)    { r.out.WriteString("[plain]" + data) }
+func (r *recordingWriter) WriteRawStr(data string) { r.out.WriteString("[raw]" + data) }
+func (r *recordingWriter) SetColor(fg, bg prompt.Color, bold bool) {
+	r.colors = append(r.colors, fg)
+}
+
+func TestHighlightingWriterOnlyHighlightsInput(t *testing.T) {
+	saved := noColor
+	defer func() { noColor = saved }()
+	noColor = false
+
+	inner := &recordingWriter{}
+	w := &HighlightingWriter{inner: inner, highlighter: NewSyntaxHighlighter()}
+
+	w.SetColor(prompt.Blue, prompt.DefaultColor, false)
+	w.WriteStr("MySQL> ")
+	w.SetColor(inputMarkerColor, prompt.DefaultColor, false)
+	w.WriteStr("SELECT 1")
+	w.SetColor(prompt.White, prompt.Cyan, false)
+	w.WriteStr(" SELECT ")
+
+	got := inner.out.String()
+	if !strings.HasPrefix(got, "[plain]MySQL> [raw]\x1b[") {
+		t.Errorf("prefix should be plain and input highlighted, got %q", got)
+	}
+	if !strings.HasSuffix(got, "[plain] SELECT ") {
+		t.Errorf("completion text should not be highlighted, got %q", got)
+	}
+	for _, c := range inner.colors {
+		if c == inputMarkerColor {
+			t.Errorf("marker color leaked to the terminal")
+		}
+	}
+}
+
+func TestHighlightContinuation(t *testing.T) {
+	saved := noColor
+	defer func() { noColor = saved }()
+	noColor = false
+
+	sh := NewSyntaxHighlighter()
+	// The second line closes a string opened on the first, so "FROM" must
+	// be highlighted as part of the string rather than as a keyword
+	inString := sh.HighlightContinuation("SELECT 'abc\n", "FROM' AS x")
+	alone := sh.HighlightSQL("FROM' AS x")
+	if inString == alone {
+		t.Errorf("continuation line highlighted without its context: %q", inString)
+	}
+	if strings.Contains(inString, "\n") {
+		t.Errorf("only the continuation line should be returned: %q", inString)
+	}
+	if got := sh.HighlightContinuation("", "SELECT 1"); got != sh.HighlightSQL("SELECT 1") {
+		t.Errorf("empty context should highlight the text alone")
+	}
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// themeSample is the statement \theme list shows each theme with
+const themeSample = "SELECT id, 'text' FROM t WHERE n > 42; -- note"
+
+// themePreviewSQL are the statements \theme preview highlights
+var themePreviewSQL = []string{
+	"SELECT c.name, COUNT(o.id) AS orders, SUM(o.total) FROM customers c\n  JOIN orders o ON o.customer_id = c.id WHERE o.created_at >= '2024-01-01' GROUP BY c.name;",
+	"UPDATE products SET price = price * 1.1 WHERE category IN ('books', 'music') LIMIT 100; -- raise prices",
+	"CREATE TABLE audit (id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY, note TEXT NULL);",
+}
+
+// themeNames lists the themes \theme offers: go-mycli's own smooth and
+// light styles, then chroma's styles by name
+func themeNames() []string {
+	return append([]string{"smooth", "light"}, styles.Names()...)
+}
+
+// currentTheme is the syntax_style of the config file
+func currentTheme() string {
+	return strings.ToLower(LoadSyntaxConfig().Style)
+}
+
+// themeCommand implements \theme [list|preview <name>|set <name>|next|prev]
+func (p *PromptExecutor) themeCommand(args string) {
+	verb, name, _ := strings.Cut(strings.ToLower(strings.TrimSpace(args)), " ")
+	name = strings.TrimSpace(name)
+	switch verb {
+	case "", "list":
+		p.listThemes()
+	case "preview":
+		if name == "" {
+			name = currentTheme()
+		}
+		if msg := checkStyle(name); msg != "" {
+			fmt.Println(msg)
+			return
+		}
+		p.previewTheme(name)
+	case "set":
+		if msg := checkStyle(name); name == "" || msg != "" {
+			fmt.Println("Usage: \\theme set <name>; \\theme list shows the themes")
+			return
+		}
+		p.setTheme(name)
+	case "next", "prev":
+		names := themeNames()
+		i := indexOf(names, currentTheme())
+		if verb == "next" {
+			i = (i + 1) % len(names)
+		} else {
+			i = (i - 1 + len(names)) % len(names)
+		}
+		p.previewTheme(names[i])
+		p.setTheme(names[i])
+	default:
+		fmt.Println("Usage: \\theme [list | preview <name> | set <name> | next | prev]")
+	}
+}
+
+// indexOf returns the position of s in list, -1 when it is not there
+func indexOf(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// listThemes prints every theme with a sample statement in it, marking the
+// one in use
+func (p *PromptExecutor) listThemes() {
+	current := currentTheme()
+	for _, name := range themeNames() {
+		mark := " "
+		if name == current {
+			mark = "*"
+		}
+		fmt.Printf("%s %-20s %s\n", mark, name, newSyntaxHighlighter(resolveBaseStyle(name)).HighlightSQL(themeSample))
+	}
+	if !colorEnabled() {
+		fmt.Println("Colors are off (NO_COLOR or --no-color), so the themes look alike")
+	}
+	fmt.Println("\\theme preview <name> shows more; \\theme set <name> switches to it")
+}
+
+// previewTheme shows sample statements highlighted with a theme, and a
+// result table as this session colors it
+func (p *PromptExecutor) previewTheme(name string) {
+	h := newSyntaxHighlighter(resolveBaseStyle(name))
+	fmt.Printf("Theme %s:\n\n", name)
+	for _, stmt := range themePreviewSQL {
+		fmt.Println(h.HighlightSQL(stmt))
+	}
+	fmt.Println()
+	columns := []string{"name", "orders", "active", "note"}
+	rows := [][]string{
+		{"Ada Lovelace", "42", "YES", "NULL"},
+		{"Grace Hopper", "7", "NO", "first compiler"},
+	}
+	var color func(col int, cell string) string
+	if p.colorResults && colorEnabled() {
+		numeric := []bool{false, true, false, false}
+		color = func(col int, cell string) string { return valueColor(cell, numeric[col]) }
+	}
+	fmt.Print(formatMySQLTableColored(columns, rows, color))
+}
+
+// setTheme switches the session to a theme and saves it as syntax_style.
+// The [colors] overrides are turned off, so the theme looks as previewed.
+func (p *PromptExecutor) setTheme(name string) {
+	saveSetting("syntax_style", name)
+	if LoadSyntaxConfig().UseCustomColors {
+		saveSetting("use_custom_colors", "false")
+		fmt.Println("Custom [colors] turned off (use_custom_colors = false) so the theme shows as previewed")
+	}
+	if p.highlighter != nil {
+		*p.highlighter = *NewSyntaxHighlighter()
+	}
+	fmt.Printf("Theme now %s\n", name)
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestThemeNames(t *testing.T) {
+	names := themeNames()
+	if names[0] != "smooth" || names[1] != "light" {
+		t.Errorf("themeNames starts with %v, want go-mycli's own styles first", names[:2])
+	}
+	if indexOf(names, "monokai") < 0 {
+		t.Error("monokai is not among the themes")
+	}
+	for _, name := range names {
+		if msg := checkStyle(name); msg != "" {
+			t.Errorf("theme %s: %s", name, msg)
+		}
+	}
+}
+
+func TestSetTheme(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), ".go-myclirc")
+	t.Setenv("GO_MYCLI_RC", rc)
+	if err := SaveSyntaxConfig(&SyntaxConfig{Style: "monokai", UseCustomColors: true, Colors: DefaultColors()}); err != nil {
+		t.Fatal(err)
+	}
+	p := &PromptExecutor{highlighter: NewSyntaxHighlighter()}
+	p.themeCommand("set Dracula")
+	cfg := LoadSyntaxConfig()
+	if cfg.Style != "dracula" || cfg.UseCustomColors {
+		t.Errorf("after \\theme set: style %s, custom colors %v; want dracula without overrides", cfg.Style, cfg.UseCustomColors)
+	}
+	if p.highlighter.style.Name != "dracula" {
+		t.Errorf("highlighter style = %s, want dracula", p.highlighter.style.Name)
+	}
+	p.themeCommand("next")
+	if got, want := currentTheme(), themeNames()[indexOf(themeNames(), "dracula")+1]; got != want {
+		t.Errorf("after \\theme next: %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,348 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+const (
+	// topologyDepth is how many levels of replicas \topology follows
+	topologyDepth = 4
+	// topologyConnectTimeout bounds each connection to a replica, which may
+	// not be reachable from the client at all
+	topologyConnectTimeout = 5 * time.Second
+)
+
+// topologyNode is one server of the replication tree
+type topologyNode struct {
+	Name     string // host:port
+	ServerID string
+	GTIDMode string
+	ReadOnly bool
+	// Replication from its source, empty for the top of the tree
+	Lag     string // Seconds_Behind_Source, "NULL" when a thread is stopped
+	IO, SQL string // Yes, No or Connecting
+	Applied string // delay of the last applied transaction behind its original commit
+	Error   string // the last IO or SQL error
+	// Behind counts the source's transactions the replica has not applied,
+	// -1 when GTIDs are off or the replica could not be queried; Errant are
+	// transactions only the replica has
+	Behind   int64
+	Errant   string
+	Upstream string // the source of the top of the tree, if it is a replica
+	Problem  string // why the node could not be queried
+	Children []*topologyNode
+	executed string // gtid_executed
+}
+
+// showTopology implements \topology: the current server, what it replicates
+// from and the tree of replicas below it, found with SHOW REPLICAS and
+// queried over connections opened with the option file profile of each
+// host, or the [client] settings, when they are accepted
+func (p *PromptExecutor) showTopology() {
+	ctx, stop := p.statementContext()
+	defer stop()
+
+	root, err := queryTopologyNode(ctx, p.db)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), canceled(ctx, err))
+		return
+	}
+	if status, err := replicaStatus(ctx, p.db); err == nil && status != nil {
+		root.Upstream = fmt.Sprintf("%s:%s", statusValue(status, "Source_Host", "Master_Host"), statusValue(status, "Source_Port", "Master_Port"))
+		root.applyStatus(status)
+		root.Applied = applierDelay(ctx, p.db)
+	}
+	seen := map[string]bool{root.ServerID: true}
+	p.discoverReplicas(ctx, root, p.db, 1, seen)
+	if ctx.Err() != nil {
+		fmt.Println(errCanceled)
+		return
+	}
+	fmt.Print(renderTopology(root))
+}
+
+// discoverReplicas adds the replicas of node, queried over db, and their
+// replicas in turn
+func (p *PromptExecutor) discoverReplicas(ctx context.Context, node *topologyNode, db *sql.DB, depth int, seen map[string]bool) {
+	_, hosts, err := queryFirst(ctx, db, "SHOW REPLICAS", "SHOW SLAVE HOSTS")
+	if err != nil || ctx.Err() != nil {
+		return
+	}
+	for _, host := range hosts {
+		// Server_id, Host, Port come first in every version
+		if len(host) < 3 || seen[host[0]] {
+			continue
+		}
+		seen[host[0]] = true
+		child := &topologyNode{Name: host[1] + ":" + host[2], ServerID: host[0], Behind: -1}
+		node.Children = append(node.Children, child)
+		if host[1] == "" {
+			child.Name = "server_id " + host[0]
+			child.Problem = "the replica does not set report_host"
+			continue
+		}
+		port, _ := strconv.Atoi(host[2])
+		replicaDB, err := p.openReplica(ctx, host[1], port)
+		if err != nil {
+			child.Problem = err.Error()
+			continue
+		}
+		p.queryReplica(ctx, child, node, replicaDB)
+		if depth < topologyDepth && child.Problem == "" {
+			p.discoverReplicas(ctx, child, replicaDB, depth+1, seen)
+		}
+		replicaDB.Close()
+	}
+}
+
+// queryReplica fills in a replica's own state, its replication from source
+// and how far its transactions are from the source's
+func (p *PromptExecutor) queryReplica(ctx context.Context, node, source *topologyNode, db *sql.DB) {
+	info, err := queryTopologyNode(ctx, db)
+	if err != nil {
+		node.Problem = err.Error()
+		return
+	}
+	node.GTIDMode, node.ReadOnly, node.executed = info.GTIDMode, info.ReadOnly, info.executed
+	if status, err := replicaStatus(ctx, db); err == nil && status != nil {
+		node.applyStatus(status)
+		node.Applied = applierDelay(ctx, db)
+	}
+	if source.executed == "" || node.executed == "" {
+		return
+	}
+	// GTID_SUBTRACT only needs some server to compute the difference
+	var missing, errant string
+	if err := db.QueryRowContext(ctx, "SELECT GTID_SUBTRACT(?, ?), GTID_SUBTRACT(?, ?)",
+		source.executed, node.executed, node.executed, source.executed).Scan(&missing, &errant); err == nil {
+		node.Behind, node.Errant = gtidSetCount(missing), errant
+	}
+}
+
+// openReplica connects to a replica found with SHOW REPLICAS. Nothing is
+// asked on the terminal: a replica the saved settings cannot log in to is
+// reported and skipped.
+func (p *PromptExecutor) openReplica(ctx context.Context, host string, port int) (*sql.DB, error) {
+	config, err := ReadMySQLConfig("", "")
+	if err != nil {
+		config = &MySQLConfig{}
+	}
+	user, password := "", ""
+	if name := profileForHost(host, port); name != "" {
+		profile := readServerProfile(name, "")
+		user, password = profile.User, profile.Password
+	}
+	if user == "" && config.User == "" {
+		user = p.user
+	}
+	merged := MergeConfig(config, user, password, host, port, "", "")
+	db, err := sql.Open("mysql", withConnectionParams(BuildDSN(merged.User, merged.Password, host, port, "", "", 0)))
+	if err != nil {
+		return nil, err
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, topologyConnectTimeout)
+	defer cancel()
+	if err := db.PingContext(pingCtx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("cannot connect as %s: %w", merged.User, err)
+	}
+	return db, nil
+}
+
+// profileForHost returns the option file group for a replica: one named
+// after the host, or one whose host (and port, if set) are the replica's
+func profileForHost(host string, port int) string {
+	found := ""
+	for _, file := range optionFiles("") {
+		cfg, err := ini.Load(file)
+		if err != nil {
+			continue
+		}
+		for _, section := range cfg.Sections() {
+			name := section.Name()
+			if strings.EqualFold(name, host) {
+				return name
+			}
+			sectionPort, err := section.Key("port").Int()
+			if found == "" && strings.EqualFold(StripMatchingQuotes(section.Key("host").String()), host) &&
+				(err != nil || sectionPort == port) {
+				found = name
+			}
+		}
+	}
+	return found
+}
+
+// queryTopologyNode reads what \topology shows about a server itself
+func queryTopologyNode(ctx context.Context, db *sql.DB) (*topologyNode, error) {
+	var host, port, serverID string
+	var readOnly int
+	if err := db.QueryRowContext(ctx, "SELECT @@hostname, @@port, @@server_id, @@read_only").Scan(&host, &port, &serverID, &readOnly); err != nil {
+		return nil, err
+	}
+	node := &topologyNode{Name: host + ":" + port, ServerID: serverID, ReadOnly: readOnly == 1, Behind: -1}
+	// Servers without GTIDs (MariaDB has its own) leave both empty
+	var mode, executed sql.NullString
+	if err := db.QueryRowContext(ctx, "SELECT @@gtid_mode, @@gtid_executed").Scan(&mode, &executed); err == nil {
+		node.GTIDMode, node.executed = mode.String, strings.ReplaceAll(executed.String, "\n", "")
+	}
+	return node, nil
+}
+
+// replicaStatus returns the first channel of SHOW REPLICA STATUS by column,
+// nil when the server is not a replica
+func replicaStatus(ctx context.Context, db *sql.DB) (map[string]string, error) {
+	columns, rows, err := queryFirst(ctx, db, "SHOW REPLICA STATUS", "SHOW SLAVE STATUS")
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	status := make(map[string]string, len(columns))
+	for i, col := range columns {
+		status[col] = rows[0][i]
+	}
+	return status, nil
+}
+
+// applierDelay reads from performance_schema how long after its commit on
+// the original source the last transaction was applied, which unlike
+// Seconds_Behind_Source holds across intermediate replicas. It is "" where
+// the table or its timestamps (MySQL 8.0) are missing.
+func applierDelay(ctx context.Context, db *sql.DB) string {
+	var seconds sql.NullFloat64
+	err := db.QueryRowContext(ctx, `SELECT MAX(TIMESTAMPDIFF(MICROSECOND, LAST_APPLIED_TRANSACTION_ORIGINAL_COMMIT_TIMESTAMP,
+		LAST_APPLIED_TRANSACTION_END_APPLY_TIMESTAMP)) / 1000000 FROM performance_schema.replication_applier_status_by_worker
+		WHERE LAST_APPLIED_TRANSACTION <> ''`).Scan(&seconds)
+	if err != nil || !seconds.Valid {
+		return ""
+	}
+	return time.Duration(seconds.Float64 * float64(time.Second)).Round(time.Millisecond).String()
+}
+
+// queryFirst runs the first statement the server accepts: the current
+// spelling of a replication statement, then the old one
+func queryFirst(ctx context.Context, db *sql.DB, statements ...string) ([]string, [][]string, error) {
+	var err error
+	for _, stmt := range statements {
+		var rows *sql.Rows
+		if rows, err = db.QueryContext(ctx, stmt); err != nil {
+			continue
+		}
+		columns, result, err := readStringRows(rows)
+		rows.Close()
+		return columns, result, err
+	}
+	return nil, nil, err
+}
+
+// statusValue returns the first of the names SHOW REPLICA STATUS has: MySQL
+// 8.0.22 renamed the Master and Slave columns
+func statusValue(status map[string]string, names ...string) string {
+	for _, name := range names {
+		if v, ok := status[name]; ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// applyStatus takes the replication state of a node from its SHOW REPLICA
+// STATUS
+func (n *topologyNode) applyStatus(status map[string]string) {
+	n.Lag = statusValue(status, "Seconds_Behind_Source", "Seconds_Behind_Master")
+	n.IO = statusValue(status, "Replica_IO_Running", "Slave_IO_Running")
+	n.SQL = statusValue(status, "Replica_SQL_Running", "Slave_SQL_Running")
+	for _, name := range []string{"Last_IO_Error", "Last_SQL_Error"} {
+		if e := status[name]; e != "" {
+			n.Error = e
+		}
+	}
+}
+
+// gtidSetCount counts the transactions of a GTID set such as
+// "3E11FA47-...:1-5:11,4D22...:7"
+func gtidSetCount(set string) int64 {
+	var n int64
+	for _, part := range strings.Split(strings.ReplaceAll(set, "\n", ""), ",") {
+		intervals := strings.Split(strings.TrimSpace(part), ":")
+		for _, interval := range intervals[1:] {
+			start, end, isRange := strings.Cut(interval, "-")
+			first, err := strconv.ParseInt(start, 10, 64)
+			if err != nil {
+				// A tag (MySQL 8.3's uuid:tag:1-5) is not an interval
+				continue
+			}
+			last := first
+			if isRange {
+				if last, err = strconv.ParseInt(end, 10, 64); err != nil {
+					continue
+				}
+			}
+			n += last - first + 1
+		}
+	}
+	return n
+}
+
+// renderTopology draws the tree below root with the state of each node
+func renderTopology(root *topologyNode) string {
+	var b strings.Builder
+	if root.Upstream != "" {
+		fmt.Fprintf(&b, "%s (source, not followed)\n%s\n", root.Upstream, strings.TrimRight(treeGlyphs.Line, " "))
+	}
+	writeTopologyNode(&b, root, "", "")
+	return b.String()
+}
+
+func writeTopologyNode(b *strings.Builder, n *topologyNode, first, rest string) {
+	fmt.Fprintf(b, "%s%s%s\n", first, n.Name, n.describe())
+	for i, child := range n.Children {
+		if i == len(n.Children)-1 {
+			writeTopologyNode(b, child, rest+treeGlyphs.Last, rest+"    ")
+		} else {
+			writeTopologyNode(b, child, rest+treeGlyphs.Branch, rest+treeGlyphs.Line)
+		}
+	}
+}
+
+// describe summarizes a node after its name
+func (n *topologyNode) describe() string {
+	parts := []string{"server_id " + n.ServerID}
+	if n.Problem != "" {
+		return fmt.Sprintf(" [%s] not queried: %s", parts[0], n.Problem)
+	}
+	if n.ReadOnly {
+		parts = append(parts, "read_only")
+	}
+	if n.GTIDMode != "" {
+		parts = append(parts, "GTID "+n.GTIDMode)
+	}
+	desc := " [" + strings.Join(parts, ", ") + "]"
+	if n.IO == "" {
+		return desc
+	}
+	lag := "lag " + n.Lag + "s"
+	if n.Lag == "NULL" || n.Lag == "" {
+		lag = "lag unknown"
+	}
+	desc += fmt.Sprintf(" %s, IO %s, SQL %s", lag, n.IO, n.SQL)
+	if n.Behind > 0 {
+		desc += fmt.Sprintf(", %d transaction%s behind", n.Behind, plural(int(n.Behind)))
+	}
+	if n.Errant != "" {
+		desc += ", errant GTIDs " + n.Errant
+	}
+	if n.Applied != "" {
+		desc += ", last transaction applied " + n.Applied + " after its commit"
+	}
+	if n.Error != "" {
+		desc += ", error: " + truncateQuery(n.Error, 80)
+	}
+	return desc
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGTIDSetCount(t *testing.T) {
+	tests := []struct {
+		set  string
+		want int64
+	}{
+		{"", 0},
+		{"3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5", 5},
+		{"3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5:11:20-21,\n4d22fa47-71ca-11e1-9e33-c80aa9429562:7", 9},
+		{"3e11fa47-71ca-11e1-9e33-c80aa9429562:batch:1-3", 3},
+	}
+	for _, test := range tests {
+		if got := gtidSetCount(test.set); got != test.want {
+			t.Errorf("%q: got %d, expected %d", test.set, got, test.want)
+		}
+	}
+}
+
+func TestRenderTopology(t *testing.T) {
+	root := &topologyNode{Name: "db1:3306", ServerID: "1", GTIDMode: "ON", Behind: -1, Upstream: "db0:3306"}
+	root.applyStatus(map[string]string{"Seconds_Behind_Master": "0", "Slave_IO_Running": "Yes", "Slave_SQL_Running": "Yes"})
+	db2 := &topologyNode{Name: "db2:3306", ServerID: "2", ReadOnly: true, Behind: 3, Errant: "abc:1"}
+	db2.applyStatus(map[string]string{"Seconds_Behind_Source": "NULL", "Replica_IO_Running": "Yes", "Replica_SQL_Running": "No",
+		"Last_SQL_Error": "Duplicate entry '1' for key 'PRIMARY'"})
+	db2.Children = []*topologyNode{{Name: "db4:3306", ServerID: "4", Problem: "cannot connect as app"}}
+	root.Children = []*topologyNode{db2, {Name: "server_id 3", ServerID: "3", Problem: "the replica does not set report_host"}}
+
+	want := `db0:3306 (source, not followed)
+│
+db1:3306 [server_id 1, GTID ON] lag 0s, IO Yes, SQL Yes
+├── db2:3306 [server_id 2, read_only] lag unknown, IO Yes, SQL No, 3 transactions behind, errant GTIDs abc:1, error: Duplicate entry '1' for key 'PRIMARY'
+│   └── db4:3306 [server_id 4] not queried: cannot connect as app
+└── server_id 3 [server_id 3] not queried: the replica does not set report_host
+`
+	if got := renderTopology(root); got != want {
+		t.Errorf("got\n%s", got)
+	}
+}
+
+func TestRenderTopologyLegacyConsole(t *testing.T) {
+	glyphs, color := treeGlyphs, noColor
+	defer func() { treeGlyphs, noColor = glyphs, color }()
+	legacyConsole()
+
+	root := &topologyNode{Name: "db1:3306", Upstream: "db0:3306"}
+	root.Children = []*topologyNode{
+		{Name: "db2:3306", Problem: "down", Children: []*topologyNode{{Name: "db4:3306", Problem: "down"}}},
+		{Name: "db3:3306", Problem: "down"},
+	}
+	got := renderTopology(root)
+	for _, want := range []string{"db0:3306 (source, not followed)\n|\n", "\n|-- db2:3306", "\n|   `-- db4:3306", "\n`-- db3:3306"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("got\n%s\nwant it to contain %q", got, want)
+		}
+	}
+}
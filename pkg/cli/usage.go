@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/c-bata/go-prompt"
+	bolt "go.etcd.io/bbolt"
+)
+
+// usageBucket holds completion usage counters in the metadata store
+const usageBucket = "completion_usage"
+
+// usageMaxBoost caps the ranking boost from usage so a frequently used name
+// never outranks a prefix match of the typed word (worth 200, see
+// calculateMatchScore)
+const usageMaxBoost = 150
+
+// usageHalfLife is how quickly old usage stops counting
+const usageHalfLife = 7 * 24 * time.Hour
+
+// usageEntry counts how often a table or column has been used
+type usageEntry struct {
+	Count int       `json:"count"`
+	Last  time.Time `json:"last"`
+}
+
+// weight is the entry's count, decayed by how long ago it was last used
+func (e usageEntry) weight(now time.Time) float64 {
+	age := now.Sub(e.Last)
+	if age < 0 {
+		age = 0
+	}
+	return float64(e.Count) * math.Pow(0.5, float64(age)/float64(usageHalfLife))
+}
+
+// usageStore keeps table and column usage counters in a bolt database, so
+// completion can rank the identifiers you use most across sessions. Entries
+// are kept in memory and written through on every update.
+type usageStore struct {
+	path    string
+	entries map[string]usageEntry
+}
+
+// defaultUsagePath returns ~/.go-mycli/metadata.db
+func defaultUsagePath() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "metadata.db")
+}
+
+// openUsageStore loads the counters from path. A store that cannot be read
+// starts empty; an empty path keeps counters in memory only.
+func openUsageStore(path string) *usageStore {
+	s := &usageStore{path: path, entries: make(map[string]usageEntry)}
+	if path == "" {
+		return s
+	}
+	if _, err := os.Stat(path); err != nil {
+		return s
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second, ReadOnly: true})
+	if err != nil {
+		return s
+	}
+	defer db.Close()
+	_ = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(usageBucket))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var e usageEntry
+			if json.Unmarshal(v, &e) == nil {
+				s.entries[string(k)] = e
+			}
+			return nil
+		})
+	})
+	return s
+}
+
+// usageKey identifies a table ("db/t:table") or column ("db/c:table.column")
+func usageKey(database, kind, name string) string {
+	return database + "/" + kind + ":" + strings.ToLower(name)
+}
+
+// add counts one use of each key, persisting the updated entries
+func (s *usageStore) add(keys []string, now time.Time) {
+	if len(keys) == 0 {
+		return
+	}
+	updated := make(map[string]usageEntry, len(keys))
+	for _, k := range keys {
+		e := s.entries[k]
+		e.Count++
+		e.Last = now
+		s.entries[k] = e
+		updated[k] = e
+	}
+	if s.path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return
+	}
+	db, err := bolt.Open(s.path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return
+	}
+	defer db.Close()
+	_ = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(usageBucket))
+		if err != nil {
+			return err
+		}
+		for k, e := range updated {
+			data, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(k), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// boost returns the ranking boost for key, 0 for names never used
+func (s *usageStore) boost(key string, now time.Time) int {
+	e, ok := s.entries[key]
+	if !ok {
+		return 0
+	}
+	b := int(50 * math.Log2(1+e.weight(now)))
+	if b > usageMaxBoost {
+		return usageMaxBoost
+	}
+	return b
+}
+
+var identifierWord = regexp.MustCompile("`[^`]+`|[A-Za-z_][A-Za-z0-9_$]*")
+
+// recordUsage counts the tables a successful statement used, and the columns
+// of those tables it mentioned
+func (p *PromptExecutor) recordUsage(sql string) {
+	if p.usage == nil {
+		return
+	}
+	ctx := ParseSQLContext(sql, len(sql))
+	if len(ctx.AllTables) == 0 {
+		return
+	}
+
+	seen := make(map[string]bool)
+	var keys []string
+	addKey := func(k string) {
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+
+	words := make(map[string]bool)
+	for _, w := range identifierWord.FindAllString(sql, -1) {
+		words[strings.ToLower(strings.Trim(w, "`"))] = true
+	}
+	for _, table := range ctx.AllTables {
+		table = strings.Trim(table, "`")
+		cols, ok := p.columns[table]
+		if !ok {
+			continue // not a table of the current database
+		}
+		addKey(usageKey(p.database, "t", table))
+		for _, col := range cols {
+			if words[strings.ToLower(col)] {
+				addKey(usageKey(p.database, "c", table+"."+col))
+			}
+		}
+	}
+	p.usage.add(keys, time.Now())
+}
+
+// completionBoost returns a function giving the usage boost of a suggestion.
+// Columns are looked up under the table named in their description, which is
+// one of the tables in the current query, so the same column name ranks
+// differently depending on what you are querying.
+func (p *PromptExecutor) completionBoost() func(prompt.Suggest) int {
+	if p.usage == nil || len(p.usage.entries) == 0 {
+		return nil
+	}
+	now := time.Now()
+	return func(s prompt.Suggest) int {
+		if table, ok := strings.CutPrefix(s.Description, "Column from "); ok {
+			col := unqualified(s.Text)
+			return p.usage.boost(usageKey(p.database, "c", table+"."+col), now)
+		}
+		if s.Description == "Table" {
+			return p.usage.boost(usageKey(p.database, "t", unquoteIdentifier(s.Text)), now)
+		}
+		return 0
+	}
+}
+
+// rankByUsage orders suggestions by usage boost, keeping the original order
+// for names used equally often
+func rankByUsage(suggestions []prompt.Suggest, boost func(prompt.Suggest) int) {
+	if boost == nil {
+		return
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return boost(suggestions[i]) > boost(suggestions[j])
+	})
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/c-bata/go-prompt"
+)
+
+func TestUsageStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.db")
+	now := time.Now()
+
+	s := openUsageStore(path)
+	s.add([]string{usageKey("shop", "t", "orders"), usageKey("shop", "t", "orders")}, now)
+	s.add([]string{usageKey("shop", "t", "Orders")}, now)
+
+	reopened := openUsageStore(path)
+	if got := reopened.entries[usageKey("shop", "t", "orders")].Count; got != 3 {
+		t.Errorf("count after reopening = %d, want 3", got)
+	}
+	if reopened.boost(usageKey("shop", "t", "orders"), now) <= reopened.boost(usageKey("shop", "t", "never"), now) {
+		t.Errorf("a used table should get a boost")
+	}
+}
+
+func TestUsageBoost(t *testing.T) {
+	now := time.Now()
+	s := &usageStore{entries: map[string]usageEntry{
+		"a": {Count: 1, Last: now},
+		"b": {Count: 10, Last: now},
+		"c": {Count: 10, Last: now.Add(-8 * usageHalfLife)},
+		"d": {Count: 100000, Last: now},
+	}}
+	if s.boost("a", now) >= s.boost("b", now) {
+		t.Errorf("frequent use should rank higher")
+	}
+	if s.boost("c", now) >= s.boost("b", now) {
+		t.Errorf("old use should count for less")
+	}
+	if got := s.boost("d", now); got != usageMaxBoost {
+		t.Errorf("boost = %d, want the cap %d", got, usageMaxBoost)
+	}
+}
+
+func TestRecordUsageAndRank(t *testing.T) {
+	p := &PromptExecutor{
+		database: "shop",
+		columns: map[string][]string{
+			"orders":    {"id", "total", "status"},
+			"customers": {"id", "name"},
+		},
+		usage: openUsageStore(""),
+	}
+	p.recordUsage("SELECT o.total FROM orders o WHERE o.status = 'paid'")
+	p.recordUsage("SELECT total FROM orders")
+
+	suggestions := []prompt.Suggest{
+		{Text: "customers", Description: "Table"},
+		{Text: "orders", Description: "Table"},
+		{Text: "id", Description: "Column from orders"},
+		{Text: "total", Description: "Column from orders"},
+		{Text: "status", Description: "Column from orders"},
+	}
+	rankByUsage(suggestions, p.completionBoost())
+	var got []string
+	for _, s := range suggestions {
+		got = append(got, s.Text)
+	}
+	want := []string{"orders", "total", "status", "customers", "id"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ranked = %v, want %v", got, want)
+		}
+	}
+
+	matches := p.findRankedMatches("t", []prompt.Suggest{
+		{Text: "tax", Description: "Column from orders"},
+		{Text: "total", Description: "Column from orders"},
+	}, p.completionBoost())
+	if matches[0].Text != "total" {
+		t.Errorf("used column should rank first, got %v", matches)
+	}
+}
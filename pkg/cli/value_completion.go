@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/c-bata/go-prompt"
+)
+
+const (
+	valueSampleRows  = 1000 // rows read to find a column's common values
+	valueSuggestions = 20   // most frequent values offered
+	valueSampleLimit = 2 * time.Second
+)
+
+// getValueSuggestions returns the most frequent values of the column a
+// string is being compared with, as in status = ' or status IN ('a', '.
+// Enum and set members come from the column's type; other columns are
+// sampled the first time and cached per database.
+func (p *PromptExecutor) getValueSuggestions(ctx *SQLParseResult) []prompt.Suggest {
+	if !p.completionValues || ctx.ValueColumn == "" || !strings.HasPrefix(ctx.PartialWord, "'") {
+		return nil
+	}
+	table, col, detail, ok := p.valueColumn(ctx)
+	if !ok {
+		return nil
+	}
+
+	typ := strings.ToLower(detail.Type)
+	if strings.HasPrefix(typ, "enum(") || strings.HasPrefix(typ, "set(") {
+		var suggestions []prompt.Suggest
+		for _, v := range enumValues.FindAllString(detail.Type, -1) {
+			suggestions = append(suggestions, prompt.Suggest{Text: v, Description: fmt.Sprintf("%s %s", col, detail.Type)})
+		}
+		return suggestions
+	}
+
+	key := p.database + "." + table + "." + col
+	if values, ok := p.valueCache[key]; ok {
+		return values
+	}
+	if p.db == nil {
+		return nil
+	}
+	values := p.sampleValues(table, col)
+	if p.valueCache == nil {
+		p.valueCache = make(map[string][]prompt.Suggest)
+	}
+	p.valueCache[key] = values
+	return values
+}
+
+// valueColumn finds the table of the compared column: the one its qualifier
+// names, or the first table of the query that has it
+func (p *PromptExecutor) valueColumn(ctx *SQLParseResult) (string, string, columnDetail, bool) {
+	tables := ctx.Tables
+	if ctx.ValueTable != "" {
+		tables = []string{ctx.ResolveAlias(ctx.ValueTable)}
+	}
+	for _, t := range tables {
+		table, cols, details := p.tableColumns(t)
+		for _, col := range cols {
+			if strings.EqualFold(col, ctx.ValueColumn) {
+				return table, col, details[col], true
+			}
+		}
+	}
+	return "", "", columnDetail{}, false
+}
+
+// sampleValues reads the first valueSampleRows non-NULL values of a column
+// and returns the most frequent ones as quoted strings. A slow or failed
+// query gives no values rather than holding up typing.
+func (p *PromptExecutor) sampleValues(table, col string) []prompt.Suggest {
+	query := fmt.Sprintf("SELECT v, COUNT(*) AS n FROM (SELECT %s AS v FROM %s WHERE %s IS NOT NULL LIMIT %d) sample GROUP BY v ORDER BY n DESC LIMIT %d",
+		quoteIdentifier(col), quoteIdentifier(table), quoteIdentifier(col), valueSampleRows, valueSuggestions)
+	qctx, cancel := context.WithTimeout(context.Background(), valueSampleLimit)
+	defer cancel()
+	rows, err := p.db.QueryContext(qctx, query)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var values []prompt.Suggest
+	for rows.Next() {
+		var v string
+		var n int
+		if rows.Scan(&v, &n) != nil {
+			continue
+		}
+		values = append(values, prompt.Suggest{
+			Text:        "'" + strings.ReplaceAll(v, "'", "''") + "'",
+			Description: fmt.Sprintf("%s, %d in sample", col, n),
+		})
+	}
+	return values
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/c-bata/go-prompt"
+)
+
+func TestParseSQLContext_ValueColumn(t *testing.T) {
+	tests := []struct {
+		sql          string
+		table, colum string
+		context      SQLContext
+	}{
+		{"SELECT * FROM users WHERE status = '", "", "status", ContextValue},
+		{"SELECT * FROM users u WHERE u.status != 'act", "u", "status", ContextValue},
+		{"SELECT * FROM users WHERE `status` IN ('new', '", "", "status", ContextValue},
+		{"SELECT * FROM users WHERE status NOT LIKE '", "", "status", ContextValue},
+		{"UPDATE users SET status = '", "", "status", ContextValue},
+		{"SELECT * FROM users WHERE COUNT(id) > ", "", "", ContextValue},
+	}
+	for _, tt := range tests {
+		r := ParseSQLContext(tt.sql, len(tt.sql))
+		if r.ValueTable != tt.table || r.ValueColumn != tt.colum || r.Context != tt.context {
+			t.Errorf("%q: got %q.%q in %v, want %q.%q in %v", tt.sql, r.ValueTable, r.ValueColumn, r.Context, tt.table, tt.colum, tt.context)
+		}
+	}
+}
+
+func TestValueSuggestions(t *testing.T) {
+	p := &PromptExecutor{
+		tables:  []string{"users", "orders"},
+		columns: map[string][]string{"users": {"id", "status", "role"}, "orders": {"id", "state"}},
+		columnDetails: map[string]map[string]columnDetail{
+			"users": {"role": {Type: "enum('admin','member')"}},
+		},
+		valueCache: map[string][]prompt.Suggest{
+			".users.status": {{Text: "'active'"}, {Text: "'banned'"}},
+			".orders.state": {{Text: "'paid'"}},
+		},
+		cacheTime:        time.Now(),
+		completionMode:   completionFull,
+		completionValues: true,
+	}
+	texts := func(line string) []string {
+		buf := prompt.NewBuffer()
+		buf.InsertText(line, false, true)
+		var got []string
+		for _, s := range p.Completer(*buf.Document()) {
+			got = append(got, s.Text)
+		}
+		return got
+	}
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"SELECT * FROM users WHERE status = '", []string{"'active'", "'banned'"}},
+		{"SELECT * FROM users WHERE status = 'ban", []string{"'banned'"}},
+		{"SELECT * FROM users u JOIN orders o ON o.id = u.id WHERE o.state IN ('", []string{"'paid'"}},
+		{"SELECT * FROM users WHERE role = '", []string{"'admin'", "'member'"}},
+		{"SELECT * FROM users WHERE id = '", nil},
+	}
+	for _, tt := range tests {
+		if got := texts(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.line, got, tt.want)
+		}
+	}
+
+	p.completionValues = false
+	if got := texts("SELECT * FROM users WHERE status = '"); got != nil {
+		t.Errorf("completion_values off: got %v", got)
+	}
+}
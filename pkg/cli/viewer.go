@@ -0,0 +1,376 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// viewMaxColumnWidth caps a column so one long value cannot fill the screen
+const viewMaxColumnWidth = 60
+
+var (
+	viewHeaderStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "#0550AE", Dark: "#66D9EF"})
+	viewSelectedStyle = lipgloss.NewStyle().Reverse(true)
+	viewMatchStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#953800", Dark: "#E6DB74"}).Bold(true)
+	viewStatusStyle   = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#6E7781", Dark: "#75715E"})
+	viewEditedStyle   = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#1A7F37", Dark: "#A6E22E"}).Underline(true)
+)
+
+// resultViewer is a full-screen, scrollable view of a result set (\view)
+type resultViewer struct {
+	columns []string
+	rows    [][]string
+	widths  []int
+
+	row, col  int // cursor
+	top, left int // first visible row and column
+	sortCol   int // -1 when unsorted
+	sortDesc  bool
+	searching bool
+	search    string
+	status    string
+	help      string // keys listed on the status line
+	width     int
+	height    int
+	// marked, if set, tells which cells to show as changed (\edit-rows)
+	marked func(row []string, col int) bool
+}
+
+func newResultViewer(columns []string, rows [][]string) *resultViewer {
+	v := &resultViewer{
+		columns: columns,
+		rows:    make([][]string, len(rows)),
+		widths:  make([]int, len(columns)),
+		sortCol: -1,
+		help:    "←/→ columns • s sort • / search • q quit",
+		width:   100,
+		height:  30,
+	}
+	// Sorting reorders rows, so work on a copy of the caller's slice
+	copy(v.rows, rows)
+	for i, col := range columns {
+		v.widths[i] = runewidth.StringWidth(col) + 2 // room for the sort marker
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if w := runewidth.StringWidth(cell); w > v.widths[i] {
+				v.widths[i] = w
+			}
+		}
+	}
+	for i := range v.widths {
+		if v.widths[i] > viewMaxColumnWidth {
+			v.widths[i] = viewMaxColumnWidth
+		}
+	}
+	return v
+}
+
+func (v *resultViewer) Init() tea.Cmd {
+	return nil
+}
+
+func (v *resultViewer) bodyHeight() int {
+	// Header, separator and status line
+	if h := v.height - 3; h > 1 {
+		return h
+	}
+	return 1
+}
+
+func (v *resultViewer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width, v.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		if v.searching {
+			v.updateSearch(msg)
+			break
+		}
+		v.status = ""
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return v, tea.Quit
+		case "up", "k":
+			v.moveRow(-1)
+		case "down", "j":
+			v.moveRow(1)
+		case "pgup", "ctrl+b":
+			v.moveRow(-v.bodyHeight())
+		case "pgdown", "ctrl+f", " ":
+			v.moveRow(v.bodyHeight())
+		case "home", "g":
+			v.moveRow(-len(v.rows))
+		case "end", "G":
+			v.moveRow(len(v.rows))
+		case "left", "h":
+			v.moveCol(-1)
+		case "right", "l", "tab":
+			v.moveCol(1)
+		case "s":
+			v.sortBy(v.col)
+		case "/":
+			v.searching, v.search = true, ""
+		case "n":
+			v.findNext(1)
+		case "N":
+			v.findNext(-1)
+		}
+	}
+	return v, nil
+}
+
+// updateSearch handles keys while the search query is being typed
+func (v *resultViewer) updateSearch(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		v.searching, v.search = false, ""
+	case tea.KeyEnter:
+		v.searching = false
+		v.findNext(0)
+	case tea.KeyBackspace:
+		if v.search != "" {
+			r := []rune(v.search)
+			v.search = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		v.search += string(msg.Runes)
+	}
+}
+
+func (v *resultViewer) moveRow(delta int) {
+	v.row += delta
+	if v.row >= len(v.rows) {
+		v.row = len(v.rows) - 1
+	}
+	if v.row < 0 {
+		v.row = 0
+	}
+	if v.row < v.top {
+		v.top = v.row
+	}
+	if v.row >= v.top+v.bodyHeight() {
+		v.top = v.row - v.bodyHeight() + 1
+	}
+}
+
+func (v *resultViewer) moveCol(delta int) {
+	v.col += delta
+	if v.col >= len(v.columns) {
+		v.col = len(v.columns) - 1
+	}
+	if v.col < 0 {
+		v.col = 0
+	}
+	if v.col < v.left {
+		v.left = v.col
+	}
+	for v.left < v.col && v.col >= v.left+len(v.visibleColumns()) {
+		v.left++
+	}
+}
+
+// visibleColumns returns the indexes of the columns that fit from v.left
+func (v *resultViewer) visibleColumns() []int {
+	var cols []int
+	used := 0
+	for i := v.left; i < len(v.columns); i++ {
+		w := v.widths[i] + 3 // " | " separator
+		if len(cols) > 0 && used+w > v.width {
+			break
+		}
+		cols = append(cols, i)
+		used += w
+	}
+	return cols
+}
+
+// sortBy sorts on col, reversing the order when it is already the sort column
+func (v *resultViewer) sortBy(col int) {
+	if v.sortCol == col {
+		v.sortDesc = !v.sortDesc
+	} else {
+		v.sortCol, v.sortDesc = col, false
+	}
+	sortRows(v.rows, col, v.sortDesc)
+	v.row, v.top = 0, 0
+}
+
+// findNext moves to the next row (dir 1), previous row (dir -1) or nearest row
+// from the cursor (dir 0) containing the search text
+func (v *resultViewer) findNext(dir int) {
+	if v.search == "" {
+		return
+	}
+	from := v.row + dir
+	step := dir
+	if step == 0 {
+		step = 1
+	}
+	row := findRow(v.rows, v.search, from, step)
+	if row < 0 {
+		v.status = fmt.Sprintf("Pattern not found: %s", v.search)
+		return
+	}
+	v.moveRow(row - v.row)
+}
+
+func (v *resultViewer) View() string {
+	cols := v.visibleColumns()
+
+	var b strings.Builder
+	for j, c := range cols {
+		if j > 0 {
+			b.WriteString(" | ")
+		}
+		name := v.columns[c]
+		if c == v.sortCol {
+			if v.sortDesc {
+				name += " ▼"
+			} else {
+				name += " ▲"
+			}
+		}
+		cell := runewidth.FillRight(runewidth.Truncate(name, v.widths[c], "…"), v.widths[c])
+		if c == v.col {
+			cell = viewSelectedStyle.Render(cell)
+		}
+		b.WriteString(viewHeaderStyle.Render(cell))
+	}
+	b.WriteString("\n")
+	for j, c := range cols {
+		if j > 0 {
+			b.WriteString("-+-")
+		}
+		b.WriteString(strings.Repeat("-", v.widths[c]))
+	}
+	b.WriteString("\n")
+
+	search := strings.ToLower(v.search)
+	body := v.bodyHeight()
+	for i := v.top; i < len(v.rows) && i < v.top+body; i++ {
+		var line strings.Builder
+		for j, c := range cols {
+			if j > 0 {
+				line.WriteString(" | ")
+			}
+			value := strings.ReplaceAll(v.rows[i][c], "\n", "↵")
+			cell := runewidth.FillRight(runewidth.Truncate(value, v.widths[c], "…"), v.widths[c])
+			if v.marked != nil && v.marked(v.rows[i], c) {
+				cell = viewEditedStyle.Render(cell)
+			} else if search != "" && !v.searching && strings.Contains(strings.ToLower(value), search) {
+				cell = viewMatchStyle.Render(cell)
+			}
+			line.WriteString(cell)
+		}
+		if i == v.row {
+			b.WriteString(viewSelectedStyle.Render(line.String()))
+		} else {
+			b.WriteString(line.String())
+		}
+		b.WriteString("\n")
+	}
+	for i := len(v.rows) - v.top; i < body; i++ {
+		b.WriteString("\n")
+	}
+
+	status := v.status
+	switch {
+	case v.searching:
+		status = "/" + v.search
+	case status == "":
+		status = fmt.Sprintf("row %d/%d  column %d/%d (%s)  %s",
+			v.row+1, len(v.rows), v.col+1, len(v.columns), v.columns[v.col], v.help)
+	}
+	b.WriteString(viewStatusStyle.Render(status))
+	return b.String()
+}
+
+// sortRows stably sorts rows on column col. NULLs sort first, and values that
+// are both numeric compare as numbers.
+func sortRows(rows [][]string, col int, desc bool) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		if desc {
+			return compareCells(rows[j][col], rows[i][col]) < 0
+		}
+		return compareCells(rows[i][col], rows[j][col]) < 0
+	})
+}
+
+func compareCells(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "NULL":
+		return -1
+	case b == "NULL":
+		return 1
+	}
+	// Ignore thousands separators added by numbers = thousands
+	fa, errA := strconv.ParseFloat(strings.ReplaceAll(a, ",", ""), 64)
+	fb, errB := strconv.ParseFloat(strings.ReplaceAll(b, ",", ""), 64)
+	if errA == nil && errB == nil {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+// findRow returns the first row at or after from (stepping by step, wrapping
+// around) with a cell containing text, ignoring case, or -1 if none does
+func findRow(rows [][]string, text string, from, step int) int {
+	n := len(rows)
+	if n == 0 || text == "" {
+		return -1
+	}
+	text = strings.ToLower(text)
+	for k := 0; k < n; k++ {
+		i := ((from+k*step)%n + n) % n
+		for _, cell := range rows[i] {
+			if strings.Contains(strings.ToLower(cell), text) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// shouldAutoView reports whether a formatted table is too wide for the terminal
+// and should be shown in the result viewer instead
+func (p *PromptExecutor) shouldAutoView(table string) bool {
+	if !p.autoView || p.input == nil || table == "" {
+		return false
+	}
+	size := p.input.GetWinSize()
+	if size == nil || size.Col == 0 {
+		return false
+	}
+	firstLine, _, _ := strings.Cut(table, "\n")
+	return len(firstLine) > int(size.Col)
+}
+
+// viewResult shows a result set in the interactive viewer
+func (p *PromptExecutor) viewResult(columns []string, rows [][]string) {
+	if p.input == nil {
+		fmt.Println("\\view requires an interactive terminal")
+		return
+	}
+	if len(rows) == 0 {
+		fmt.Println("No result to view")
+		return
+	}
+	if _, err := tea.NewProgram(newResultViewer(columns, rows), tea.WithAltScreen()).Run(); err != nil {
+		fmt.Printf("Viewer failed: %v\n", err)
+	}
+}
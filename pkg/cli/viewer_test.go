@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortRows(t *testing.T) {
+	rows := [][]string{{"10", "b"}, {"9", "a"}, {"NULL", "c"}, {"100", "a"}}
+
+	sortRows(rows, 0, false)
+	if got := column(rows, 0); !reflect.DeepEqual(got, []string{"NULL", "9", "10", "100"}) {
+		t.Errorf("ascending numeric sort = %v", got)
+	}
+
+	sortRows(rows, 0, true)
+	if got := column(rows, 0); !reflect.DeepEqual(got, []string{"100", "10", "9", "NULL"}) {
+		t.Errorf("descending numeric sort = %v", got)
+	}
+
+	sortRows(rows, 1, false)
+	if got := column(rows, 0); !reflect.DeepEqual(got, []string{"100", "9", "10", "NULL"}) {
+		t.Errorf("stable string sort = %v", got)
+	}
+}
+
+func TestFindRow(t *testing.T) {
+	rows := [][]string{{"1", "ACADEMY DINOSAUR"}, {"2", "ACE GOLDFINGER"}, {"3", "ADAPTATION HOLES"}}
+
+	tests := []struct {
+		text       string
+		from, step int
+		want       int
+	}{
+		{"goldfinger", 0, 1, 1},
+		{"a", 1, 1, 1},
+		{"dinosaur", 1, 1, 0}, // wraps around
+		{"ace", 0, -1, 1},
+		{"missing", 0, 1, -1},
+	}
+	for _, tt := range tests {
+		if got := findRow(rows, tt.text, tt.from, tt.step); got != tt.want {
+			t.Errorf("findRow(%q, %d, %d) = %d, want %d", tt.text, tt.from, tt.step, got, tt.want)
+		}
+	}
+}
+
+func TestResultViewerKeepsCursorColumnVisible(t *testing.T) {
+	columns := []string{"a", "b", "c", "d"}
+	rows := [][]string{{"aaaaaaaaaaaaaaaaaaaa", "bbbbbbbbbbbbbbbbbbbb", "cccccccccccccccccccc", "dddddddddddddddddddd"}}
+	v := newResultViewer(columns, rows)
+	v.width = 50
+
+	v.moveCol(3)
+	visible := v.visibleColumns()
+	if visible[len(visible)-1] != 3 {
+		t.Errorf("column 3 not visible after moving right: %v (left=%d)", visible, v.left)
+	}
+
+	v.moveCol(-3)
+	if v.left != 0 {
+		t.Errorf("left = %d after moving back to the first column", v.left)
+	}
+}
+
+func column(rows [][]string, i int) []string {
+	var out []string
+	for _, row := range rows {
+		out = append(out, row[i])
+	}
+	return out
+}
@@ -0,0 +1,314 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// planOperations are the keys of a JSON plan that hold a step of the plan,
+// in the order the steps are shown, with the label each gets
+var planOperations = []struct {
+	key   string
+	label string
+}{
+	{"query_block", "Query block"},
+	{"union_result", "Union"},
+	{"windowing", "Window"},
+	{"ordering_operation", "Order"},
+	{"grouping_operation", "Group"},
+	{"duplicates_removal", "Remove duplicates"},
+	{"buffer_result", "Buffer result"},
+	{"nested_loop", "Nested loop"},
+	{"table", "Table"},
+	{"query_specifications", "Union members"},
+	{"materialized_from_subquery", "Materialized subquery"},
+	{"attached_subqueries", "Attached subqueries"},
+	{"optimized_away_subqueries", "Optimized away subqueries"},
+	{"select_list_subqueries", "Select list subqueries"},
+	{"update_value_subqueries", "Update value subqueries"},
+	{"having_subqueries", "Having subqueries"},
+	{"order_by_subqueries", "Order by subqueries"},
+	{"group_by_subqueries", "Group by subqueries"},
+}
+
+// planNode is a step of a plan as the exported visualizations draw it
+type planNode struct {
+	Label    string
+	Details  [][2]string // name and value, in plan order
+	Cost     float64     // the step's own cost, for the heat map
+	Heat     float64     // Cost relative to the costliest step, 0 to 1
+	Children []*planNode
+}
+
+// buildPlanNodes turns an EXPLAIN FORMAT=JSON plan into a tree of steps
+// with their details, each step's cost relative to the costliest one
+func buildPlanNodes(planJSON string) (*planNode, error) {
+	var plan map[string]interface{}
+	if err := json.Unmarshal([]byte(planJSON), &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON plan: %v", err)
+	}
+	children := planChildren(plan)
+	if len(children) == 0 {
+		return nil, fmt.Errorf("no query block found in plan")
+	}
+	root := children[0]
+	if len(children) > 1 {
+		root = &planNode{Label: "Plan", Children: children}
+	}
+
+	var maxCost float64
+	var walk func(n *planNode, visit func(*planNode))
+	walk = func(n *planNode, visit func(*planNode)) {
+		visit(n)
+		for _, c := range n.Children {
+			walk(c, visit)
+		}
+	}
+	walk(root, func(n *planNode) { maxCost = max(maxCost, n.Cost) })
+	if maxCost > 0 {
+		walk(root, func(n *planNode) { n.Heat = n.Cost / maxCost })
+	}
+	return root, nil
+}
+
+// planChildren returns the steps held by a plan object
+func planChildren(obj map[string]interface{}) []*planNode {
+	var nodes []*planNode
+	for _, op := range planOperations {
+		switch v := obj[op.key].(type) {
+		case map[string]interface{}:
+			nodes = append(nodes, planOperation(op.key, op.label, v))
+		case []interface{}:
+			group := &planNode{Label: op.label}
+			for _, elem := range v {
+				if m, ok := elem.(map[string]interface{}); ok {
+					group.Children = append(group.Children, planChildren(m)...)
+				}
+			}
+			nodes = append(nodes, group)
+		}
+	}
+	return nodes
+}
+
+// planOperation makes the node of one step: a table access labeled with the
+// table and how it is read, or an operation with its scalar properties
+func planOperation(key, label string, obj map[string]interface{}) *planNode {
+	n := &planNode{Label: label}
+	cost, _ := obj["cost_info"].(map[string]interface{})
+	if key == "table" {
+		n.Label = fmt.Sprint(obj["table_name"])
+		if access, ok := obj["access_type"].(string); ok {
+			n.Label += " (" + access + ")"
+		}
+		// A table's own cost is reading it and evaluating its condition
+		n.Cost = planNumber(cost["read_cost"]) + planNumber(cost["eval_cost"])
+	} else {
+		if id, ok := obj["select_id"]; ok {
+			n.Label += fmt.Sprintf(" #%v", id)
+		}
+		n.Cost = planNumber(cost["sort_cost"])
+	}
+
+	for _, k := range sortedKeys(obj) {
+		switch v := obj[k].(type) {
+		case map[string]interface{}:
+			if k == "cost_info" {
+				for _, ck := range sortedKeys(v) {
+					n.Details = append(n.Details, [2]string{ck, fmt.Sprint(v[ck])})
+				}
+			}
+		case []interface{}:
+			if values := planScalars(v); values != "" {
+				n.Details = append(n.Details, [2]string{k, values})
+			}
+		default:
+			if k != "table_name" && k != "select_id" {
+				n.Details = append(n.Details, [2]string{k, fmt.Sprint(v)})
+			}
+		}
+	}
+	n.Children = planChildren(obj)
+	return n
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// planScalars joins a list of plain values such as possible_keys, or
+// returns "" for a list of objects
+func planScalars(list []interface{}) string {
+	values := make([]string, 0, len(list))
+	for _, v := range list {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			return ""
+		}
+		values = append(values, fmt.Sprint(v))
+	}
+	return strings.Join(values, ", ")
+}
+
+// exportVisual implements \visual export <file>: it draws the last EXPLAIN's
+// plan as a page to open in a browser (.html), or with graphviz as an image
+// (.svg) or its source (.dot)
+func (p *PromptExecutor) exportVisual(path string) {
+	if path == "" {
+		fmt.Println("Usage: \\visual export <file.html|file.svg|file.dot>")
+		return
+	}
+	if p.lastExplain == nil || p.lastExplain.Plan == "" {
+		fmt.Println("No EXPLAIN plan to export yet; run EXPLAIN <query> first")
+		return
+	}
+	root, err := buildPlanNodes(p.lastExplain.Plan)
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return
+	}
+	path = expandHome(path)
+	query, _, _ := extractQueryFromExplain(p.lastExplain.Statement)
+
+	var buf bytes.Buffer
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		err = writePlanHTML(&buf, query, p.lastExplain.Findings, root)
+	case ".dot":
+		writePlanDot(&buf, root)
+	case ".svg":
+		var dot bytes.Buffer
+		writePlanDot(&dot, root)
+		err = renderDot(&buf, &dot)
+	default:
+		fmt.Println("The file must end in .html, .svg or .dot")
+		return
+	}
+	if err == nil {
+		err = os.WriteFile(path, buf.Bytes(), 0o644)
+	}
+	if err != nil {
+		fmt.Printf(tr("Error: %v\n"), err)
+		return
+	}
+	fmt.Printf("Plan written to %s\n", path)
+}
+
+// renderDot runs graphviz's dot to turn a graph into SVG
+func renderDot(w io.Writer, graph io.Reader) error {
+	dot, err := exec.LookPath("dot")
+	if err != nil {
+		return fmt.Errorf("graphviz is needed for SVG (dot is not in PATH); export to .html or .dot instead")
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(dot, "-Tsvg")
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = graph, w, &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("dot: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// heatColor is the fill of a step on the heat map: green for cheap steps
+// through yellow to red for the costliest
+func heatColor(heat float64) string {
+	return fmt.Sprintf("hsl(%.0f, 75%%, 80%%)", 120*(1-heat))
+}
+
+// writePlanDot writes the plan as a graphviz graph, top to bottom, each
+// step filled with its heat map color
+func writePlanDot(w io.Writer, root *planNode) {
+	fmt.Fprintln(w, "digraph plan {")
+	fmt.Fprintln(w, `  node [shape=box, style="rounded,filled", fontname="Helvetica", fontsize=10];`)
+	id := 0
+	var walk func(n *planNode) int
+	walk = func(n *planNode) int {
+		id++
+		self := id
+		label := n.Label
+		for _, d := range n.Details {
+			if d[0] == "key" || d[0] == "rows_examined_per_scan" || d[0] == "prefix_cost" || d[0] == "query_cost" {
+				label += "\n" + d[0] + ": " + d[1]
+			}
+		}
+		// graphviz takes HSV fractions rather than CSS hsl()
+		fmt.Fprintf(w, "  n%d [label=%s, fillcolor=\"%.3f 0.45 1.0\"];\n", self, dotQuote(label), (1-n.Heat)/3)
+		for _, c := range n.Children {
+			fmt.Fprintf(w, "  n%d -> n%d;\n", self, walk(c))
+		}
+		return self
+	}
+	walk(root)
+	fmt.Fprintln(w, "}")
+}
+
+// dotQuote quotes s as a graphviz string with \n line breaks
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + strings.ReplaceAll(s, "\n", `\n`) + `"`
+}
+
+// planPage is what the HTML export is rendered from
+type planPage struct {
+	Query    string
+	Score    int
+	Verdict  string
+	Findings []ExplainFinding
+	Root     *planNode
+}
+
+var planHTML = template.Must(template.New("plan").Funcs(template.FuncMap{
+	"heat": func(n *planNode) template.CSS { return template.CSS("background: " + heatColor(n.Heat)) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>EXPLAIN plan</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, sans-serif; margin: 2em; color: #222; }
+pre { background: #f4f4f4; padding: 1em; overflow-x: auto; }
+details { margin-left: 1.5em; border-left: 1px dotted #999; padding-left: 0.5em; }
+summary { cursor: pointer; padding: 0.2em 0.5em; margin: 0.2em 0; border-radius: 4px; display: inline-block; }
+table { border-collapse: collapse; margin: 0.3em 0 0.3em 1.5em; font-size: 0.9em; }
+td { border: 1px solid #ddd; padding: 0.1em 0.5em; font-family: monospace; }
+.legend span { padding: 0.2em 0.6em; border-radius: 4px; }
+</style>
+</head>
+<body>
+<h1>EXPLAIN plan</h1>
+<pre>{{.Query}}</pre>
+<p>Score: <strong>{{.Score}}/100 ({{.Verdict}})</strong></p>
+{{if .Findings}}<ul>{{range .Findings}}<li><strong>{{.Severity}}</strong> {{if .Table}}<code>{{.Table}}</code>: {{end}}{{.Message}}</li>{{end}}</ul>{{end}}
+<p class="legend">Cost: <span style="background: hsl(120, 75%, 80%)">cheap</span> <span style="background: hsl(60, 75%, 80%)">moderate</span> <span style="background: hsl(0, 75%, 80%)">costliest</span>
+ &nbsp; <button onclick="document.querySelectorAll('details').forEach(d => d.open = true)">Expand all</button>
+ <button onclick="document.querySelectorAll('details').forEach(d => d.open = false)">Collapse all</button></p>
+{{template "node" .Root}}
+</body>
+</html>
+{{define "node"}}<details open>
+<summary style="{{heat .}}">{{.Label}}</summary>
+{{if .Details}}<table>{{range .Details}}<tr><td>{{index . 0}}</td><td>{{index . 1}}</td></tr>{{end}}</table>{{end}}
+{{range .Children}}{{template "node" .}}{{end}}</details>
+{{end}}`))
+
+// writePlanHTML writes the plan as a standalone page: the query, its score
+// and findings, and the steps as collapsible nodes colored by cost
+func writePlanHTML(w io.Writer, query string, findings []ExplainFinding, root *planNode) error {
+	score := severityScore(findings)
+	return planHTML.Execute(w, planPage{Query: query, Score: score, Verdict: scoreVerdict(score), Findings: findings, Root: root})
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+const joinPlan = `{
+  "query_block": {
+    "select_id": 1,
+    "cost_info": {"query_cost": "2810.25"},
+    "ordering_operation": {
+      "using_filesort": true,
+      "nested_loop": [
+        {"table": {"table_name": "f", "access_type": "ALL", "rows_examined_per_scan": 1000,
+          "cost_info": {"read_cost": "93.00", "eval_cost": "10.00", "prefix_cost": "103.00"}}},
+        {"table": {"table_name": "i", "access_type": "ref", "key": "idx_fk_film_id", "possible_keys": ["idx_fk_film_id"],
+          "attached_condition": "(i.store_id < 2)", "cost_info": {"read_cost": "1200.00", "eval_cost": "500.00", "prefix_cost": "2810.25"}}}
+      ]
+    }
+  }
+}`
+
+func TestBuildPlanNodes(t *testing.T) {
+	root, err := buildPlanNodes(joinPlan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	var walk func(n *planNode, depth int)
+	walk = func(n *planNode, depth int) {
+		got = append(got, strings.Repeat("  ", depth)+n.Label)
+		for _, c := range n.Children {
+			walk(c, depth+1)
+		}
+	}
+	walk(root, 0)
+	want := []string{"Query block #1", "  Order", "    Nested loop", "      f (ALL)", "      i (ref)"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s", strings.Join(got, "\n"))
+	}
+
+	// i costs 1700 to read and is the hottest; f costs 103
+	f, i := root.Children[0].Children[0].Children[0], root.Children[0].Children[0].Children[1]
+	if i.Heat != 1 || f.Heat < 0.06 || f.Heat > 0.061 {
+		t.Errorf("heat: f %v, i %v", f.Heat, i.Heat)
+	}
+	if !containsDetail(i, "possible_keys", "idx_fk_film_id") || !containsDetail(i, "prefix_cost", "2810.25") || !containsDetail(root.Children[0], "using_filesort", "true") {
+		t.Errorf("details: %v / %v", i.Details, root.Children[0].Details)
+	}
+
+	if _, err := buildPlanNodes(`{"steps": []}`); err == nil {
+		t.Error("a plan without a query block should fail")
+	}
+}
+
+func containsDetail(n *planNode, name, value string) bool {
+	for _, d := range n.Details {
+		if d[0] == name && d[1] == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWritePlanHTML(t *testing.T) {
+	root, _ := buildPlanNodes(joinPlan)
+	var buf bytes.Buffer
+	findings := []ExplainFinding{{Severity: SeverityWarning, Table: "f", Message: "full table scan (1000 rows examined per scan)"}}
+	if err := writePlanHTML(&buf, "SELECT * FROM film f JOIN inventory i USING (film_id) WHERE i.store_id < 2", findings, root); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"WHERE i.store_id &lt; 2</pre>",
+		"Score: <strong>30/100 (warn)</strong>",
+		"<li><strong>warning</strong> <code>f</code>: full table scan",
+		`<summary style="background: hsl(0, 75%, 80%)">i (ref)</summary>`,
+		"<td>attached_condition</td><td>(i.store_id &lt; 2)</td>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("page lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWritePlanDot(t *testing.T) {
+	root, _ := buildPlanNodes(joinPlan)
+	var buf bytes.Buffer
+	writePlanDot(&buf, root)
+	out := buf.String()
+	for _, want := range []string{
+		"digraph plan {",
+		`n5 [label="i (ref)\nprefix_cost: 2810.25\nkey: idx_fk_film_id", fillcolor="0.000 0.45 1.0"];`,
+		"n3 -> n5;",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("graph lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestExportVisual(t *testing.T) {
+	dir := t.TempDir()
+	p := &PromptExecutor{}
+	p.exportVisual(dir + "/plan.html")
+	if _, err := os.Stat(dir + "/plan.html"); err == nil {
+		t.Error("nothing should be exported before an EXPLAIN")
+	}
+
+	p.lastExplain = &explainRecord{Statement: "EXPLAIN FORMAT=JSON SELECT 1", Plan: joinPlan}
+	p.exportVisual(dir + "/plan.dot")
+	data, err := os.ReadFile(dir + "/plan.dot")
+	if err != nil || !strings.HasPrefix(string(data), "digraph plan {") {
+		t.Errorf("got %q, %v", data, err)
+	}
+	p.exportVisual(dir + "/plan.png")
+	if _, err := os.Stat(dir + "/plan.png"); err == nil {
+		t.Error("only .html, .svg and .dot are exported")
+	}
+}
@@ -0,0 +1,281 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxMessageSize bounds a single newline-delimited message (schemas and plans can be large)
+const maxMessageSize = 16 * 1024 * 1024
+
+// ErrClosed is returned for calls made after the transport has shut down
+var ErrClosed = errors.New("MCP connection closed")
+
+// Client is a JSON-RPC client over a newline-delimited stream, typically the
+// stdin/stdout of a child MCP server. It is safe for concurrent use: responses
+// are matched to requests by ID.
+type Client struct {
+	w       io.WriteCloser
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan Response
+	closed  bool
+	readErr error
+	done    chan struct{}
+
+	cmd *exec.Cmd // set when the client owns a child process
+}
+
+// NewClient starts reading responses from r and writes requests to w
+func NewClient(r io.Reader, w io.WriteCloser) *Client {
+	c := &Client{
+		w:       w,
+		nextID:  1,
+		pending: make(map[int64]chan Response),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop(r)
+	return c
+}
+
+// StartProcess spawns command as an MCP stdio server. Each line the child
+// writes to stderr is passed to onStderr (if non-nil).
+func StartProcess(command string, args []string, onStderr func(line string)) (*Client, error) {
+	cmd := exec.Command(command, args...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			if onStderr != nil {
+				onStderr(scanner.Text())
+			}
+		}
+	}()
+
+	c := NewClient(stdout, stdin)
+	c.cmd = cmd
+	return c, nil
+}
+
+func (c *Client) readLoop(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+
+	for scanner.Scan() {
+		var resp Response
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			continue // not a JSON-RPC message (e.g. stray log output)
+		}
+		id, err := strconv.ParseInt(string(resp.ID), 10, 64)
+		if err != nil {
+			continue // server-initiated message or notification
+		}
+
+		c.mu.Lock()
+		ch, ok := c.pending[id]
+		delete(c.pending, id)
+		c.mu.Unlock()
+		if ok {
+			ch <- resp
+		}
+	}
+
+	c.mu.Lock()
+	c.closed = true
+	c.readErr = scanner.Err()
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+	c.mu.Unlock()
+	close(c.done)
+}
+
+func (c *Client) write(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err = c.w.Write(append(data, '\n'))
+	return err
+}
+
+// Call sends a request and waits for its response or ctx cancellation
+func (c *Client) Call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	var rawParams json.RawMessage
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		rawParams = data
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, ErrClosed
+	}
+	id := c.nextID
+	c.nextID++
+	ch := make(chan Response, 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	req := Request{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(strconv.FormatInt(id, 10)),
+		Method:  method,
+		Params:  rawParams,
+	}
+	if err := c.write(req); err != nil {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return nil, err
+	}
+
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			return nil, ErrClosed
+		}
+		if resp.Error != nil {
+			return nil, resp.Error
+		}
+		return resp.Result, nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// Notify sends a notification, which has no response
+func (c *Client) Notify(method string, params interface{}) error {
+	req := Request{JSONRPC: "2.0", Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = data
+	}
+	return c.write(req)
+}
+
+// Initialize performs the MCP handshake
+func (c *Client) Initialize(ctx context.Context, name, version string) (*InitializeResult, error) {
+	raw, err := c.Call(ctx, "initialize", InitializeParams{
+		ProtocolVersion: ProtocolVersion,
+		Capabilities:    map[string]interface{}{},
+		ClientInfo:      ClientInfo{Name: name, Version: version},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var result InitializeResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("invalid initialize result: %w", err)
+	}
+	_ = c.Notify("notifications/initialized", nil)
+	return &result, nil
+}
+
+// ListTools returns the tools offered by the server
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	raw, err := c.Call(ctx, "tools/list", nil)
+	if err != nil {
+		return nil, err
+	}
+	var result ListToolsResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("invalid tools/list result: %w", err)
+	}
+	return result.Tools, nil
+}
+
+// CallTool invokes a tool and returns its text content
+func (c *Client) CallTool(ctx context.Context, name string, args map[string]interface{}) (string, error) {
+	raw, err := c.Call(ctx, "tools/call", CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		return "", err
+	}
+	var result CallToolResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	if result.IsError {
+		return "", fmt.Errorf("tool %s failed: %s", name, result.Text())
+	}
+	if len(result.Content) == 0 {
+		return "", fmt.Errorf("no response from MCP server")
+	}
+	return result.Text(), nil
+}
+
+// Done is closed when the server side of the stream has gone away
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Shutdown closes the request stream so the server can exit cleanly on EOF.
+// For child processes it waits for exit, killing the child after timeout.
+func (c *Client) Shutdown(timeout time.Duration) error {
+	c.writeMu.Lock()
+	err := c.w.Close()
+	c.writeMu.Unlock()
+
+	if c.cmd == nil {
+		return err
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- c.cmd.Wait()
+	}()
+
+	select {
+	case err := <-exited:
+		return err
+	case <-time.After(timeout):
+		_ = c.cmd.Process.Kill()
+		<-exited
+		return fmt.Errorf("MCP server did not exit within %s and was killed", timeout)
+	}
+}
+
+// Close shuts the client down with a default timeout
+func (c *Client) Close() error {
+	return c.Shutdown(5 * time.Second)
+}
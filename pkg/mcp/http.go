@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ExplainRequest is the plain JSON body accepted by the mcp-server bridge at /mcp.
+// With ComparePlan set it asks which of two queries is preferable instead.
+type ExplainRequest struct {
+	Plan         string `json:"plan"`
+	Query        string `json:"query"`
+	Schema       string `json:"schema"`
+	DetailLevel  string `json:"detail_level,omitempty"`
+	CompareQuery string `json:"compare_query,omitempty"`
+	ComparePlan  string `json:"compare_plan,omitempty"`
+}
+
+// Tool returns the tool that answers the request: compare_mysql for a
+// comparison, explain_mysql otherwise
+func (r ExplainRequest) Tool() string {
+	if r.ComparePlan != "" {
+		return "compare_mysql"
+	}
+	return "explain_mysql"
+}
+
+// Args converts the request into arguments for its Tool
+func (r ExplainRequest) Args() map[string]interface{} {
+	args := map[string]interface{}{
+		"plan":         r.Plan,
+		"query":        r.Query,
+		"schema":       r.Schema,
+		"detail_level": r.DetailLevel,
+	}
+	if r.ComparePlan != "" {
+		args["compare_query"] = r.CompareQuery
+		args["compare_plan"] = r.ComparePlan
+	}
+	return args
+}
+
+// CacheKeyParts returns the query and plan a response cache keys the request
+// on; a comparison covers both queries and both plans
+func (r ExplainRequest) CacheKeyParts() (string, string) {
+	if r.ComparePlan == "" {
+		return r.Query, r.Plan
+	}
+	return r.Query + "\n;;\n" + r.CompareQuery, r.Plan + "\n;;\n" + r.ComparePlan
+}
+
+// ExplainResponse is the bridge's reply to an ExplainRequest
+type ExplainResponse struct {
+	Error   string `json:"error,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// HTTPClient posts ExplainRequests to an mcp-server style HTTP endpoint
+type HTTPClient struct {
+	URL string
+	// Token, when set, is sent as a bearer token
+	Token  string
+	Client *http.Client
+}
+
+// Explain sends req and returns the analysis text. Responses that are not in
+// the bridge format are returned verbatim so other MCP HTTP proxies still work.
+func (c *HTTPClient) Explain(ctx context.Context, req ExplainRequest) (string, error) {
+	buf, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(buf))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var explainResp ExplainResponse
+	if err := json.Unmarshal(body, &explainResp); err != nil {
+		// If not MCP format, return raw response
+		return string(body), nil
+	}
+
+	if explainResp.Error != "" {
+		return "", fmt.Errorf("MCP error: %s", explainResp.Error)
+	}
+
+	return explainResp.Content, nil
+}
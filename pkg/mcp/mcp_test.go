@@ -0,0 +1,176 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// startPair connects a Client to a Server over in-memory pipes
+func startPair(t *testing.T, s *Server) *Client {
+	t.Helper()
+	reqR, reqW := io.Pipe()
+	respR, respW := io.Pipe()
+	go func() {
+		s.Serve(reqR, respW)
+		respW.Close()
+	}()
+	c := NewClient(respR, reqW)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func echoServer() *Server {
+	return &Server{
+		Name:    "test",
+		Version: "0.1",
+		Tools:   []Tool{{Name: "echo", Description: "echo text"}},
+		HandleTool: func(name string, args map[string]interface{}) (string, *Error) {
+			if name != "echo" {
+				return "", NewError(CodeMethodNotFound, "Tool not found")
+			}
+			text, _ := args["text"].(string)
+			return text, nil
+		},
+	}
+}
+
+func TestClientServerRoundTrip(t *testing.T) {
+	c := startPair(t, echoServer())
+	ctx := context.Background()
+
+	info, err := c.Initialize(ctx, "client", "1.0")
+	if err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	if info.ServerInfo.Name != "test" || info.ProtocolVersion != ProtocolVersion {
+		t.Fatalf("unexpected initialize result: %+v", info)
+	}
+
+	tools, err := c.ListTools(ctx)
+	if err != nil || len(tools) != 1 || tools[0].Name != "echo" {
+		t.Fatalf("unexpected tools: %v %v", tools, err)
+	}
+
+	text, err := c.CallTool(ctx, "echo", map[string]interface{}{"text": "hello"})
+	if err != nil || text != "hello" {
+		t.Fatalf("unexpected result: %q %v", text, err)
+	}
+}
+
+func TestClientReturnsToolErrors(t *testing.T) {
+	c := startPair(t, echoServer())
+
+	_, err := c.CallTool(context.Background(), "missing", nil)
+	var rpcErr *Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != CodeMethodNotFound {
+		t.Fatalf("expected method-not-found error, got %v", err)
+	}
+}
+
+func TestClientConcurrentCalls(t *testing.T) {
+	c := startPair(t, echoServer())
+
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		want := strings.Repeat("x", i+1)
+		go func() {
+			got, err := c.CallTool(context.Background(), "echo", map[string]interface{}{"text": want})
+			if err == nil && got != want {
+				err = errors.New("response matched to the wrong request: " + got)
+			}
+			errs <- err
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestServerSkipsNotificationsAndReportsParseErrors(t *testing.T) {
+	in := strings.NewReader(`{"jsonrpc":"2.0","method":"notifications/initialized"}
+not json
+{"jsonrpc":"2.0","id":7,"method":"tools/list"}
+`)
+	var out strings.Builder
+	if err := echoServer().Serve(in, &out); err != nil {
+		t.Fatalf("serve failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 responses, got %d: %q", len(lines), out.String())
+	}
+	var parseErr Response
+	json.Unmarshal([]byte(lines[0]), &parseErr)
+	if parseErr.Error == nil || parseErr.Error.Code != CodeParseError {
+		t.Fatalf("expected parse error, got %s", lines[0])
+	}
+	var list Response
+	json.Unmarshal([]byte(lines[1]), &list)
+	if string(list.ID) != "7" || list.Error != nil {
+		t.Fatalf("unexpected tools/list response: %s", lines[1])
+	}
+}
+
+func TestHTTPClientExplain(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ExplainRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			json.NewEncoder(w).Encode(ExplainResponse{Error: "unauthorized"})
+			return
+		}
+		json.NewEncoder(w).Encode(ExplainResponse{Content: req.Query + "/" + req.DetailLevel})
+	}))
+	defer srv.Close()
+
+	c := &HTTPClient{URL: srv.URL, Token: "secret"}
+	got, err := c.Explain(context.Background(), ExplainRequest{Plan: "{}", Query: "SELECT 1", DetailLevel: "expert"})
+	if err != nil || got != "SELECT 1/expert" {
+		t.Fatalf("unexpected result: %q %v", got, err)
+	}
+
+	c.Token = ""
+	if _, err := c.Explain(context.Background(), ExplainRequest{Plan: "{}"}); err == nil {
+		t.Fatalf("expected error response to surface")
+	}
+}
+
+func TestHTTPClientReturnsNonJSONBodies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("plain analysis"))
+	}))
+	defer srv.Close()
+
+	got, err := (&HTTPClient{URL: srv.URL}).Explain(context.Background(), ExplainRequest{Plan: "{}"})
+	if err != nil || got != "plain analysis" {
+		t.Fatalf("unexpected result: %q %v", got, err)
+	}
+}
+
+func TestExplainRequestCompare(t *testing.T) {
+	req := ExplainRequest{Plan: "{}", Query: "SELECT 1"}
+	if req.Tool() != "explain_mysql" {
+		t.Errorf("unexpected tool %s", req.Tool())
+	}
+	if _, ok := req.Args()["compare_plan"]; ok {
+		t.Errorf("plain request should not carry compare_plan")
+	}
+
+	req.CompareQuery, req.ComparePlan = "SELECT 2", "[]"
+	if req.Tool() != "compare_mysql" || req.Args()["compare_query"] != "SELECT 2" {
+		t.Errorf("unexpected comparison request %s %v", req.Tool(), req.Args())
+	}
+	query, plan := req.CacheKeyParts()
+	if query != "SELECT 1\n;;\nSELECT 2" || plan != "{}\n;;\n[]" {
+		t.Errorf("unexpected cache key parts %q %q", query, plan)
+	}
+}
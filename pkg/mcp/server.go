@@ -0,0 +1,100 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// ToolHandler executes a tool call and returns its text output
+type ToolHandler func(name string, args map[string]interface{}) (string, *Error)
+
+// Server is a stdio MCP server exposing a fixed set of tools
+type Server struct {
+	Name         string
+	Version      string
+	Instructions string
+	Tools        []Tool
+	HandleTool   ToolHandler
+}
+
+// Handle dispatches a single request and returns its result or error
+func (s *Server) Handle(req *Request) (interface{}, *Error) {
+	switch req.Method {
+	case "initialize":
+		return InitializeResult{
+			ProtocolVersion: ProtocolVersion,
+			Capabilities: map[string]interface{}{
+				"tools": map[string]interface{}{},
+			},
+			ServerInfo:   ClientInfo{Name: s.Name, Version: s.Version},
+			Instructions: s.Instructions,
+		}, nil
+	case "ping":
+		return map[string]interface{}{}, nil
+	case "tools/list":
+		return ListToolsResult{Tools: s.Tools}, nil
+	case "tools/call":
+		var params CallToolParams
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.Name == "" {
+			return nil, NewError(CodeInvalidParams, "Invalid params")
+		}
+		if params.Arguments == nil {
+			params.Arguments = make(map[string]interface{})
+		}
+		text, err := s.HandleTool(params.Name, params.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		return TextResult(text), nil
+	default:
+		return nil, NewError(CodeMethodNotFound, "Method not found")
+	}
+}
+
+// Serve reads newline-delimited requests from r and writes responses to w
+// until r is exhausted. Notifications are handled but never answered.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	var writeMu sync.Mutex
+	send := func(resp Response) {
+		data, _ := json.Marshal(resp)
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		w.Write(append(data, '\n'))
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req Request
+		if err := json.Unmarshal(line, &req); err != nil {
+			send(Response{JSONRPC: "2.0", Error: NewError(CodeParseError, "Parse error")})
+			continue
+		}
+
+		result, rpcErr := s.Handle(&req)
+		if req.IsNotification() {
+			continue
+		}
+
+		resp := Response{JSONRPC: "2.0", ID: req.ID}
+		if rpcErr != nil {
+			resp.Error = rpcErr
+		} else {
+			data, err := json.Marshal(result)
+			if err != nil {
+				resp.Error = NewError(CodeServerError, "failed to encode result: %v", err)
+			} else {
+				resp.Result = data
+			}
+		}
+		send(resp)
+	}
+	return scanner.Err()
+}
@@ -1,61 +1,19 @@
 package main
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
+	"sort"
 	"strings"
 
+	"go-mycli/pkg/mcp"
+
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 )
 
-type JSONRPCRequest struct {
-	Jsonrpc string      `json:"jsonrpc"`
-	ID      interface{} `json:"id,omitempty"`
-	Method  string      `json:"method"`
-	Params  interface{} `json:"params,omitempty"`
-}
-
-type JSONRPCResponse struct {
-	Jsonrpc string        `json:"jsonrpc"`
-	ID      interface{}   `json:"id,omitempty"`
-	Result  interface{}   `json:"result,omitempty"`
-	Error   *JSONRPCError `json:"error,omitempty"`
-}
-
-type JSONRPCError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-}
-
-type InitializeResult struct {
-	ProtocolVersion string                 `json:"protocolVersion"`
-	Capabilities    map[string]interface{} `json:"capabilities"`
-	ServerInfo      map[string]interface{} `json:"serverInfo"`
-	Instructions    string                 `json:"instructions,omitempty"`
-}
-
-type Tool struct {
-	Name        string                 `json:"name"`
-	Description string                 `json:"description"`
-	InputSchema map[string]interface{} `json:"inputSchema"`
-}
-
-type ListToolsResult struct {
-	Tools []Tool `json:"tools"`
-}
-
-type CallToolResult struct {
-	Content []Content `json:"content"`
-}
-
-type Content struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
-}
-
 var db *sqlx.DB
 
 func main() {
@@ -68,165 +26,153 @@ func main() {
 	var err error
 	db, err = sqlx.Connect("mysql", dsn)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to connect to DB: %v\n", err)
-		os.Exit(1)
-	}
-	defer db.Close()
-
-	fmt.Fprintf(os.Stderr, "SQLBot MCP server started. Connected to MySQL at %s/%s\n", host, database)
-
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		line := scanner.Text()
-		var req JSONRPCRequest
-		if err := json.Unmarshal([]byte(line), &req); err != nil {
-			sendError(nil, -32700, "Parse error")
-			continue
-		}
-		handleRequest(req)
+		// explain_mysql and compare_mysql work on plans alone, so keep serving when spawned
+		// by go-mycli (mcp_stdio mode) without database credentials
+		fmt.Fprintf(os.Stderr, "Failed to connect to DB: %v (only explain_mysql and compare_mysql are available)\n", err)
+		db = nil
+	} else {
+		defer db.Close()
+		fmt.Fprintf(os.Stderr, "SQLBot MCP server started. Connected to MySQL at %s/%s\n", host, database)
 	}
-}
 
-func handleRequest(req JSONRPCRequest) {
-	switch req.Method {
-	case "initialize":
-		handleInitialize(req)
-	case "tools/list":
-		handleListTools(req)
-	case "tools/call":
-		handleCallTool(req)
-	default:
-		sendError(req.ID, -32601, "Method not found")
-	}
-}
-
-func handleInitialize(req JSONRPCRequest) {
-	result := InitializeResult{
-		ProtocolVersion: "2024-11-05",
-		Capabilities: map[string]interface{}{
-			"tools": map[string]interface{}{},
-		},
-		ServerInfo: map[string]interface{}{
-			"name":    "sqlbot-mcp",
-			"version": "1.0.0",
-		},
+	server := &mcp.Server{
+		Name:         "sqlbot-mcp",
+		Version:      "1.0.0",
 		Instructions: "Use tools to execute SQL queries, get schema information, and analyze EXPLAIN plans for MySQL databases.",
+		Tools:        tools,
+		HandleTool:   handleCallTool,
+	}
+	if err := server.Serve(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed reading requests: %v\n", err)
+		os.Exit(1)
 	}
-	sendResponse(req.ID, result)
 }
 
-func handleListTools(req JSONRPCRequest) {
-	tools := []Tool{
-		{
-			Name:        "execute_sql",
-			Description: "Execute a SQL query on the MySQL database and return the results.",
-			InputSchema: map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"sql": map[string]interface{}{
-						"type":        "string",
-						"description": "The SQL query to execute",
-					},
+var tools = []mcp.Tool{
+	{
+		Name:        "execute_sql",
+		Description: "Execute a SQL query on the MySQL database and return the results.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"sql": map[string]interface{}{
+					"type":        "string",
+					"description": "The SQL query to execute",
 				},
-				"required": []string{"sql"},
 			},
+			"required": []string{"sql"},
 		},
-		{
-			Name:        "get_schema",
-			Description: "Get the complete database schema showing all tables and columns.",
-			InputSchema: map[string]interface{}{
-				"type":       "object",
-				"properties": map[string]interface{}{},
-			},
+	},
+	{
+		Name:        "get_schema",
+		Description: "Get the complete database schema showing all tables and columns.",
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{},
+		},
+	},
+	{
+		Name:        "status",
+		Description: "Check the status of the MCP server and database connection.",
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{},
 		},
-		{
-			Name:        "status",
-			Description: "Check the status of the MCP server and database connection.",
-			InputSchema: map[string]interface{}{
-				"type":       "object",
-				"properties": map[string]interface{}{},
+	},
+	{
+		Name:        "explain_mysql",
+		Description: "Analyze a MySQL EXPLAIN plan (JSON format) and provide insights on query performance.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"plan": map[string]interface{}{
+					"type":        "string",
+					"description": "The EXPLAIN JSON output from MySQL",
+				},
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "The original SQL query",
+				},
 			},
+			"required": []string{"plan"},
 		},
-		{
-			Name:        "explain_mysql",
-			Description: "Analyze a MySQL EXPLAIN plan (JSON format) and provide insights on query performance.",
-			InputSchema: map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"plan": map[string]interface{}{
-						"type":        "string",
-						"description": "The EXPLAIN JSON output from MySQL",
-					},
-					"query": map[string]interface{}{
-						"type":        "string",
-						"description": "The original SQL query",
-					},
+	},
+	{
+		Name:        "compare_mysql",
+		Description: "Compare the MySQL EXPLAIN plans (JSON format) of two queries and say which is preferable and why.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"plan": map[string]interface{}{
+					"type":        "string",
+					"description": "The EXPLAIN JSON output of the first query",
+				},
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "The first SQL query",
+				},
+				"compare_plan": map[string]interface{}{
+					"type":        "string",
+					"description": "The EXPLAIN JSON output of the second query",
+				},
+				"compare_query": map[string]interface{}{
+					"type":        "string",
+					"description": "The second SQL query",
 				},
-				"required": []string{"plan"},
 			},
+			"required": []string{"plan", "compare_plan"},
 		},
-	}
-	sendResponse(req.ID, ListToolsResult{Tools: tools})
+	},
 }
 
-func handleCallTool(req JSONRPCRequest) {
-	params, ok := req.Params.(map[string]interface{})
-	if !ok {
-		sendError(req.ID, -32602, "Invalid params")
-		return
-	}
-
-	name, ok := params["name"].(string)
-	if !ok {
-		sendError(req.ID, -32602, "Invalid params")
-		return
-	}
-
-	args, ok := params["arguments"].(map[string]interface{})
-	if !ok {
-		args = make(map[string]interface{})
+func handleCallTool(name string, args map[string]interface{}) (string, *mcp.Error) {
+	if db == nil && name != "explain_mysql" && name != "compare_mysql" {
+		return "", mcp.NewError(mcp.CodeServerError, "database not connected; set MYSQL_USER, MYSQL_PASS, MYSQL_HOST and MYSQL_DATABASE")
 	}
 
 	switch name {
 	case "execute_sql":
 		sql, ok := args["sql"].(string)
 		if !ok {
-			sendError(req.ID, -32602, "Missing sql argument")
-			return
+			return "", mcp.NewError(mcp.CodeInvalidParams, "Missing sql argument")
 		}
 		result, err := executeSQL(sql)
 		if err != nil {
-			sendError(req.ID, -32000, err.Error())
-			return
+			return "", mcp.NewError(mcp.CodeServerError, "%s", err.Error())
 		}
-		sendResponse(req.ID, CallToolResult{Content: []Content{{Type: "text", Text: result}}})
+		return result, nil
 	case "get_schema":
 		schema, err := getSchema()
 		if err != nil {
-			sendError(req.ID, -32000, err.Error())
-			return
+			return "", mcp.NewError(mcp.CodeServerError, "%s", err.Error())
 		}
-		sendResponse(req.ID, CallToolResult{Content: []Content{{Type: "text", Text: schema}}})
+		return schema, nil
 	case "status":
 		status, err := getStatus()
 		if err != nil {
-			sendError(req.ID, -32000, err.Error())
-			return
+			return "", mcp.NewError(mcp.CodeServerError, "%s", err.Error())
 		}
-		sendResponse(req.ID, CallToolResult{Content: []Content{{Type: "text", Text: status}}})
+		return status, nil
 	case "explain_mysql":
 		plan, _ := args["plan"].(string)
 		query, _ := args["query"].(string)
 		schema, _ := args["schema"].(string)
 		detailLevel, _ := args["detail_level"].(string)
 		if plan == "" {
-			sendError(req.ID, -32602, "Missing plan argument")
-			return
+			return "", mcp.NewError(mcp.CodeInvalidParams, "Missing plan argument")
+		}
+		return analyzeExplainPlan(plan, query, schema, detailLevel), nil
+	case "compare_mysql":
+		planA, _ := args["plan"].(string)
+		queryA, _ := args["query"].(string)
+		planB, _ := args["compare_plan"].(string)
+		queryB, _ := args["compare_query"].(string)
+		if planA == "" || planB == "" {
+			return "", mcp.NewError(mcp.CodeInvalidParams, "Missing plan or compare_plan argument")
 		}
-		explanation := analyzeExplainPlan(plan, query, schema, detailLevel)
-		sendResponse(req.ID, CallToolResult{Content: []Content{{Type: "text", Text: explanation}}})
+		return comparePlans(planA, queryA, planB, queryB), nil
 	default:
-		sendError(req.ID, -32601, "Tool not found")
+		return "", mcp.NewError(mcp.CodeMethodNotFound, "Tool not found")
 	}
 }
 
@@ -1119,30 +1065,141 @@ func generateRecommendations(queryBlock map[string]interface{}) []string {
 	return recs
 }
 
-func parseFloat(s string) float64 {
-	var f float64
-	fmt.Sscanf(s, "%f", &f)
-	return f
+// planSummary is what comparePlans weighs of one EXPLAIN plan
+type planSummary struct {
+	cost         float64
+	rowsExamined float64
+	fullScans    []string
+	filesort     bool
+	temporary    bool
 }
 
-func sendResponse(id interface{}, result interface{}) {
-	resp := JSONRPCResponse{
-		Jsonrpc: "2.0",
-		ID:      id,
-		Result:  result,
+// summarizePlan walks every table access of a plan, including those of
+// subqueries and unions
+func summarizePlan(plan map[string]interface{}) planSummary {
+	var s planSummary
+	if queryBlock, ok := plan["query_block"].(map[string]interface{}); ok {
+		if costInfo, ok := queryBlock["cost_info"].(map[string]interface{}); ok {
+			if cost, ok := costInfo["query_cost"].(string); ok {
+				s.cost = parseFloat(cost)
+			}
+		}
+	}
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			if name, ok := n["table_name"].(string); ok {
+				if rows, ok := n["rows_examined_per_scan"].(float64); ok {
+					s.rowsExamined += rows
+				}
+				if access, _ := n["access_type"].(string); access == "ALL" {
+					s.fullScans = append(s.fullScans, name)
+				}
+			}
+			if v, ok := n["using_filesort"].(bool); ok && v {
+				s.filesort = true
+			}
+			if v, ok := n["using_temporary_table"].(bool); ok && v {
+				s.temporary = true
+			}
+			for _, child := range n {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range n {
+				walk(child)
+			}
+		}
 	}
-	data, _ := json.Marshal(resp)
-	fmt.Println(string(data))
+	walk(plan)
+	sort.Strings(s.fullScans)
+	return s
 }
 
-func sendError(id interface{}, code int, message string) {
-	resp := JSONRPCResponse{
-		Jsonrpc: "2.0",
-		ID:      id,
-		Error:   &JSONRPCError{Code: code, Message: message},
+// comparePlans says which of two queries the optimizer expects to be cheaper
+// and which plan differences account for it
+func comparePlans(planA, queryA, planB, queryB string) string {
+	var a, b map[string]interface{}
+	if err := json.Unmarshal([]byte(planA), &a); err != nil {
+		return fmt.Sprintf("⚠️ Could not parse the EXPLAIN plan of query A: %v", err)
 	}
-	data, _ := json.Marshal(resp)
-	fmt.Println(string(data))
+	if err := json.Unmarshal([]byte(planB), &b); err != nil {
+		return fmt.Sprintf("⚠️ Could not parse the EXPLAIN plan of query B: %v", err)
+	}
+	sa, sb := summarizePlan(a), summarizePlan(b)
+
+	var analysis strings.Builder
+	analysis.WriteString("⚖️ MySQL EXPLAIN Comparison\n")
+	analysis.WriteString("═══════════════════════════\n\n")
+	if queryA != "" {
+		analysis.WriteString(fmt.Sprintf("📝 Query A: %s\n", queryA))
+	}
+	if queryB != "" {
+		analysis.WriteString(fmt.Sprintf("📝 Query B: %s\n", queryB))
+	}
+	analysis.WriteString(fmt.Sprintf("💰 Query Cost: A %.2f, B %.2f\n", sa.cost, sb.cost))
+	analysis.WriteString(fmt.Sprintf("📈 Rows Examined: A %.0f, B %.0f\n\n", sa.rowsExamined, sb.rowsExamined))
+
+	// Costs within 10% of each other are too close to call on cost alone
+	preferred := ""
+	switch {
+	case sa.cost < sb.cost*0.9:
+		preferred = "A"
+	case sb.cost < sa.cost*0.9:
+		preferred = "B"
+	case len(sa.fullScans) != len(sb.fullScans):
+		preferred = pick(len(sa.fullScans) < len(sb.fullScans))
+	case sa.rowsExamined != sb.rowsExamined:
+		preferred = pick(sa.rowsExamined < sb.rowsExamined)
+	}
+	if preferred == "" {
+		analysis.WriteString("🤝 Verdict: the plans are equivalent; choose the query that reads better\n")
+		return analysis.String()
+	}
+	analysis.WriteString(fmt.Sprintf("🏆 Verdict: query %s is preferable\n", preferred))
+
+	analysis.WriteString("\n💡 Why:\n")
+	var reasons []string
+	if sa.cost != sb.cost {
+		reasons = append(reasons, fmt.Sprintf("Query %s has an estimated cost of %.2f against %.2f", pick(sa.cost < sb.cost), min(sa.cost, sb.cost), max(sa.cost, sb.cost)))
+	}
+	for _, q := range []struct {
+		name string
+		s    planSummary
+	}{{"A", sa}, {"B", sb}} {
+		if len(q.s.fullScans) > 0 {
+			reasons = append(reasons, fmt.Sprintf("Query %s scans %s in full", q.name, strings.Join(q.s.fullScans, ", ")))
+		}
+		if q.s.filesort {
+			reasons = append(reasons, fmt.Sprintf("Query %s sorts with a filesort", q.name))
+		}
+		if q.s.temporary {
+			reasons = append(reasons, fmt.Sprintf("Query %s builds a temporary table", q.name))
+		}
+	}
+	if sa.rowsExamined != sb.rowsExamined {
+		reasons = append(reasons, fmt.Sprintf("Query %s examines %.0f rows fewer", pick(sa.rowsExamined < sb.rowsExamined), math.Abs(sa.rowsExamined-sb.rowsExamined)))
+	}
+	for _, r := range reasons {
+		analysis.WriteString(fmt.Sprintf("  • %s\n", r))
+	}
+	analysis.WriteString("\nCosts are the optimizer's estimates; time both queries on real data before switching.\n")
+	return analysis.String()
+}
+
+// pick names query A when aWins, query B otherwise
+func pick(aWins bool) string {
+	if aWins {
+		return "A"
+	}
+	return "B"
+}
+
+func parseFloat(s string) float64 {
+	var f float64
+	fmt.Sscanf(s, "%f", &f)
+	return f
 }
 
 func getEnv(key, defaultValue string) string {
@@ -120,6 +120,44 @@ func TestMergeConfig(t *testing.T) {
 	}
 }
 
+func TestMergeConfigEnv(t *testing.T) {
+	config := &cli.MySQLConfig{
+		User:     "configuser",
+		Host:     "confighost",
+		Port:     3306,
+		Database: "configdb",
+	}
+	t.Setenv("MYSQL_HOST", "mysqlhost")
+	t.Setenv("GO_MYCLI_HOST", "envhost")
+	t.Setenv("MYSQL_TCP_PORT", "3310")
+	t.Setenv("MYSQL_PWD", "envpass")
+	t.Setenv("GO_MYCLI_DATABASE", "envdb")
+
+	// Environment overrides the config file; GO_MYCLI_* wins over MYSQL_*
+	merged := cli.MergeConfig(config, "", "", "", 0, "", "")
+	if merged.Host != "envhost" {
+		t.Errorf("Expected host 'envhost', got '%s'", merged.Host)
+	}
+	if merged.Port != 3310 {
+		t.Errorf("Expected port 3310, got %d", merged.Port)
+	}
+	if merged.Password != "envpass" {
+		t.Errorf("Expected password 'envpass', got '%s'", merged.Password)
+	}
+	if merged.Database != "envdb" {
+		t.Errorf("Expected database 'envdb', got '%s'", merged.Database)
+	}
+	if merged.User != "configuser" {
+		t.Errorf("Expected user 'configuser', got '%s'", merged.User)
+	}
+
+	// Command line arguments override the environment
+	merged = cli.MergeConfig(config, "", "", "clihost", 3307, "", "clidb")
+	if merged.Host != "clihost" || merged.Port != 3307 || merged.Database != "clidb" {
+		t.Errorf("Expected CLI values to win, got %+v", merged)
+	}
+}
+
 func TestStripMatchingQuotes(t *testing.T) {
 	tests := []struct {
 		input    string